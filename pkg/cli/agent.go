@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/cli/config"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/firestore"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/service/llm"
+	mcpservice "github.com/m-mizutani/tamamo/pkg/service/mcp"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func cmdAgent() *cli.Command {
+	return &cli.Command{
+		Name:    "agent",
+		Aliases: []string{"a"},
+		Usage:   "Manage agents",
+		Commands: []*cli.Command{
+			cmdAgentEval(),
+			cmdAgentExport(),
+			cmdAgentImport(),
+		},
+	}
+}
+
+// evalSuite is a self-contained set of an agent and its test cases to evaluate the agent without
+// Firestore, e.g. in CI
+type evalSuite struct {
+	Agent struct {
+		ID       string `yaml:"id"`
+		Name     string `yaml:"name"`
+		Versions []struct {
+			Version      string `yaml:"version"`
+			SystemPrompt string `yaml:"system_prompt"`
+			LLMProvider  string `yaml:"llm_provider"`
+			LLMModel     string `yaml:"llm_model"`
+		} `yaml:"versions"`
+	} `yaml:"agent"`
+	Cases []struct {
+		Name             string   `yaml:"name"`
+		Input            string   `yaml:"input"`
+		ExpectedFacts    []string `yaml:"expected_facts"`
+		ExpectedPatterns []string `yaml:"expected_patterns"`
+		Rubric           string   `yaml:"rubric"`
+	} `yaml:"cases"`
+}
+
+func cmdAgentEval() *cli.Command {
+	var (
+		firestoreCfg config.Firestore
+		llmCfg       config.LLMConfig
+		mcpCfg       config.MCP
+		agentID      string
+		version      string
+		baseVersion  string
+		suitePath    string
+		scriptPath   string
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "agent",
+			Usage:       "Agent ID or UUID to evaluate",
+			Destination: &agentID,
+		},
+		&cli.StringFlag{
+			Name:        "version",
+			Usage:       "Version of the agent to evaluate (default: latest)",
+			Destination: &version,
+		},
+		&cli.StringFlag{
+			Name:        "base-version",
+			Usage:       "Version of the agent to compare the results with side by side",
+			Destination: &baseVersion,
+		},
+		&cli.StringFlag{
+			Name:        "suite",
+			Usage:       "YAML file of the agent and its test cases, evaluated without Firestore",
+			Destination: &suitePath,
+		},
+		&cli.StringFlag{
+			Name:        "llm-script",
+			Usage:       "YAML file of canned LLM responses used instead of LLM providers",
+			Sources:     cli.EnvVars("TAMAMO_LLM_SCRIPT"),
+			Destination: &scriptPath,
+		},
+	}
+	flags = append(flags, firestoreCfg.Flags()...)
+	flags = append(flags, llmCfg.Flags()...)
+	flags = append(flags, mcpCfg.Flags()...)
+
+	return &cli.Command{
+		Name:  "eval",
+		Usage: "Run the test cases of an agent against a version and print the results",
+		Flags: flags,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := firestoreCfg.Validate(); err != nil {
+				return goerr.Wrap(err, "invalid firestore configuration")
+			}
+			if err := mcpCfg.Validate(); err != nil {
+				return goerr.Wrap(err, "invalid MCP configuration")
+			}
+
+			var agentRepo interfaces.AgentRepository
+			var evalRepo interfaces.EvalRepository
+			switch {
+			case suitePath != "" && firestoreCfg.ProjectID != "":
+				return goerr.New("suite can not be used with firestore")
+
+			case suitePath != "":
+				agentRepo = memory.NewAgentMemoryClient()
+				evalRepo = memory.NewEvalRepository()
+				id, err := loadEvalSuite(ctx, suitePath, agentRepo, evalRepo)
+				if err != nil {
+					return err
+				}
+				if agentID == "" {
+					agentID = id
+				}
+
+			case firestoreCfg.ProjectID != "":
+				client, err := firestore.New(ctx, firestoreCfg.ProjectID, firestoreCfg.DatabaseID)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create firestore client")
+				}
+				defer client.Close()
+				agentRepo = client
+				evalRepo = firestore.NewEvalRepository(client.GetClient())
+
+			default:
+				return goerr.New("either suite or firestore-project-id is required")
+			}
+
+			agentObj, err := findAgent(ctx, agentRepo, agentID)
+			if err != nil {
+				return err
+			}
+
+			slackOptions := []usecase.SlackOption{
+				usecase.WithAgentRepository(agentRepo),
+				usecase.WithMCPConnectOptions(mcpservice.WithPolicy(mcpCfg.Policy())),
+			}
+			var judge gollem.LLMClient
+			if scriptPath != "" {
+				script, err := llm.LoadScript(scriptPath)
+				if err != nil {
+					return err
+				}
+				client, err := llm.NewScriptedClient(script)
+				if err != nil {
+					return err
+				}
+				slackOptions = append(slackOptions, usecase.WithLLMClient(client))
+				judge = client
+			} else {
+				providersConfig, err := llmCfg.LoadAndValidate()
+				if err != nil {
+					return goerr.Wrap(err, "failed to load LLM configuration")
+				}
+				llmFactory, err := llmCfg.BuildFactory(ctx, providersConfig)
+				if err != nil {
+					return goerr.Wrap(err, "failed to build LLM factory")
+				}
+				// Versions without LLM provider in suites use the default LLM
+				judge = llmFactory.GetDefaultClient()
+				slackOptions = append(slackOptions, usecase.WithLLMFactory(llmFactory), usecase.WithLLMClient(judge))
+			}
+
+			evalUC := usecase.NewEval(
+				usecase.WithEvalRepository(evalRepo),
+				usecase.WithEvalAgentRepository(agentRepo),
+				usecase.WithEvalAnswerer(usecase.New(slackOptions...)),
+				usecase.WithEvalJudgeClient(judge),
+			)
+
+			var base *eval.Run
+			if baseVersion != "" {
+				base, err = evalUC.RunEval(ctx, agentObj.ID, baseVersion)
+				if err != nil {
+					return goerr.Wrap(err, "failed to run eval of base version")
+				}
+			}
+			target, err := evalUC.RunEval(ctx, agentObj.ID, version)
+			if err != nil {
+				return goerr.Wrap(err, "failed to run eval")
+			}
+
+			if base != nil {
+				printEvalComparison(os.Stdout, base, target)
+			} else {
+				printEvalRun(os.Stdout, target)
+			}
+
+			ctxlog.From(ctx).Info("eval finished",
+				"agent_id", agentObj.AgentID,
+				"eval_run_id", target.ID,
+				"passed", target.Passed,
+				"failed", target.Failed,
+			)
+			if target.Failed > 0 {
+				return goerr.New("eval cases failed", goerr.V("failed", target.Failed), goerr.V("eval_run_id", target.ID))
+			}
+			return nil
+		},
+	}
+}
+
+// loadEvalSuite stores the agent and test cases of the suite file to the repositories, and returns
+// the agent ID
+func loadEvalSuite(ctx context.Context, path string, agentRepo interfaces.AgentRepository, evalRepo interfaces.EvalRepository) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to read eval suite", goerr.V("path", path))
+	}
+	var suite evalSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return "", goerr.Wrap(err, "failed to parse eval suite", goerr.V("path", path))
+	}
+	if suite.Agent.ID == "" || len(suite.Agent.Versions) == 0 {
+		return "", goerr.New("agent ID and at least one version are required in eval suite", goerr.V("path", path))
+	}
+
+	// The last version is the latest one
+	versions := suite.Agent.Versions
+	agentObj := &agent.Agent{
+		ID:      types.NewUUID(ctx),
+		AgentID: suite.Agent.ID,
+		Name:    suite.Agent.Name,
+		Status:  agent.StatusActive,
+		Latest:  versions[len(versions)-1].Version,
+	}
+	if err := agentRepo.CreateAgent(ctx, agentObj); err != nil {
+		return "", goerr.Wrap(err, "failed to create agent of eval suite")
+	}
+	for _, v := range versions {
+		if err := agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    agentObj.ID,
+			Version:      v.Version,
+			SystemPrompt: v.SystemPrompt,
+			LLMProvider:  types.LLMProvider(v.LLMProvider),
+			LLMModel:     v.LLMModel,
+		}); err != nil {
+			return "", goerr.Wrap(err, "failed to create agent version of eval suite", goerr.V("version", v.Version))
+		}
+	}
+
+	for _, c := range suite.Cases {
+		evalCase := eval.NewCase(ctx, agentObj.ID)
+		evalCase.Name = c.Name
+		evalCase.Input = c.Input
+		evalCase.ExpectedFacts = c.ExpectedFacts
+		evalCase.ExpectedPatterns = c.ExpectedPatterns
+		evalCase.Rubric = c.Rubric
+		if err := evalRepo.PutEvalCase(ctx, evalCase); err != nil {
+			return "", goerr.Wrap(err, "invalid case in eval suite", goerr.V("name", c.Name))
+		}
+	}
+
+	return agentObj.AgentID, nil
+}
+
+// findAgent looks up the agent by UUID or agent ID
+func findAgent(ctx context.Context, agentRepo interfaces.AgentRepository, id string) (*agent.Agent, error) {
+	if id == "" {
+		return nil, goerr.New("agent is required")
+	}
+	if uuid := types.UUID(id); uuid.IsValid() {
+		if agentObj, err := agentRepo.GetAgent(ctx, uuid); err == nil {
+			return agentObj, nil
+		}
+	}
+
+	agentObj, err := agentRepo.GetAgentByAgentID(ctx, id)
+	if err != nil {
+		return nil, goerr.Wrap(err, "agent not found", goerr.V("agent", id))
+	}
+	return agentObj, nil
+}
+
+func printEvalRun(w io.Writer, run *eval.Run) {
+	fmt.Fprintf(w, "Agent version %s: %d passed, %d failed\n\n", run.AgentVersion, run.Passed, run.Failed)
+	for _, r := range run.Results {
+		fmt.Fprintf(w, "%s %s (%dms)\n", evalMark(r), r.CaseName, r.DurationMS)
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "    - %s\n", f)
+		}
+	}
+}
+
+func printEvalComparison(w io.Writer, base, target *eval.Run) {
+	fmt.Fprintf(w, "%-6s %-6s  %s\n", base.AgentVersion, target.AgentVersion, "case")
+	for _, c := range eval.Compare(base, target) {
+		var note string
+		switch {
+		case c.Regressed():
+			note = "  REGRESSED"
+		case c.Fixed():
+			note = "  FIXED"
+		}
+		fmt.Fprintf(w, "%-6s %-6s  %s%s\n", evalMark(c.Base), evalMark(c.Target), c.CaseName, note)
+		if c.Regressed() && c.Target != nil {
+			fmt.Fprintf(w, "    %s\n", strings.Join(c.Target.Failures, "; "))
+		}
+	}
+	fmt.Fprintf(w, "\n%s: %d passed, %d failed\n", base.AgentVersion, base.Passed, base.Failed)
+	fmt.Fprintf(w, "%s: %d passed, %d failed\n", target.AgentVersion, target.Passed, target.Failed)
+}
+
+func evalMark(r *eval.CaseResult) string {
+	switch {
+	case r == nil:
+		return "-"
+	case r.Passed:
+		return "PASS"
+	default:
+		return "FAIL"
+	}
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/cli/config"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/firestore"
+	"github.com/m-mizutani/tamamo/pkg/service/image"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/urfave/cli/v3"
+)
+
+func cmdAgentExport() *cli.Command {
+	var (
+		firestoreCfg   config.Firestore
+		storageCfg     config.Storage
+		versions       []string
+		outputPath     string
+		includeSecrets bool
+	)
+
+	flags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "version",
+			Usage:       "Version to export. Can be specified multiple times (default: all versions)",
+			Destination: &versions,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "File to write the bundle (default: stdout)",
+			Destination: &outputPath,
+		},
+		&cli.BoolFlag{
+			Name:        "include-secrets",
+			Usage:       "Export values of env and headers of MCP servers. Only their keys are exported by default",
+			Destination: &includeSecrets,
+		},
+	}
+	flags = append(flags, firestoreCfg.Flags()...)
+	flags = append(flags, storageCfg.Flags()...)
+
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Export an agent as a YAML bundle. The avatar image is exported if storage is configured",
+		ArgsUsage: "<agent-id>",
+		Flags:     flags,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			agentID := cmd.Args().First()
+			if agentID == "" {
+				return goerr.New("agent ID is required")
+			}
+
+			bundleUC, cleanup, err := newAgentBundleUseCase(ctx, &firestoreCfg, &storageCfg)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			bundle, err := bundleUC.ExportAgent(ctx, &interfaces.ExportAgentRequest{
+				AgentID:        agentID,
+				Versions:       versions,
+				IncludeSecrets: includeSecrets,
+			})
+			if err != nil {
+				return goerr.Wrap(err, "failed to export agent")
+			}
+			data, err := bundle.Encode()
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if outputPath != "" {
+				f, err := os.Create(outputPath) // #nosec G304 - path is given by the operator
+				if err != nil {
+					return goerr.Wrap(err, "failed to create output file", goerr.V("path", outputPath))
+				}
+				defer f.Close()
+				w = f
+			}
+			if _, err := w.Write(data); err != nil {
+				return goerr.Wrap(err, "failed to write bundle")
+			}
+
+			ctxlog.From(ctx).Info("exported agent",
+				"agent_id", bundle.Agent.ID,
+				"versions", len(bundle.Versions),
+			)
+			return nil
+		},
+	}
+}
+
+func cmdAgentImport() *cli.Command {
+	var (
+		firestoreCfg config.Firestore
+		storageCfg   config.Storage
+		mcpCfg       config.MCP
+		onConflict   string
+		dryRun       bool
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "on-conflict",
+			Usage:       "What to do if the agent ID already exists: skip, overwrite or new_version",
+			Value:       string(agent.ImportConflictSkip),
+			Destination: &onConflict,
+		},
+		&cli.BoolFlag{
+			Name:        "dry-run",
+			Usage:       "Only validate the bundle",
+			Destination: &dryRun,
+		},
+	}
+	flags = append(flags, firestoreCfg.Flags()...)
+	flags = append(flags, storageCfg.Flags()...)
+	flags = append(flags, mcpCfg.Flags()...)
+
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Import an agent from a YAML bundle. The avatar image is imported if storage is configured",
+		ArgsUsage: "<file>",
+		Flags:     flags,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			path := cmd.Args().First()
+			if path == "" {
+				return goerr.New("bundle file is required")
+			}
+			mode := agent.ImportConflictMode(onConflict)
+			if !mode.IsValid() {
+				return goerr.New("invalid on-conflict", goerr.V("on_conflict", onConflict))
+			}
+			if err := mcpCfg.Validate(); err != nil {
+				return goerr.Wrap(err, "invalid MCP configuration")
+			}
+
+			data, err := os.ReadFile(path) // #nosec G304 - path is given by the operator
+			if err != nil {
+				return goerr.Wrap(err, "failed to read bundle", goerr.V("path", path))
+			}
+			bundle, err := agent.ParseBundle(data, agent.WithMCPPolicy(mcpCfg.Policy()))
+			if err != nil {
+				return goerr.Wrap(err, "invalid bundle", goerr.V("path", path))
+			}
+			if dryRun {
+				fmt.Fprintf(os.Stdout, "Bundle of agent %s is valid: %d versions, latest %s\n",
+					bundle.Agent.ID, len(bundle.Versions), bundle.Agent.Latest)
+				return nil
+			}
+
+			bundleUC, cleanup, err := newAgentBundleUseCase(ctx, &firestoreCfg, &storageCfg,
+				usecase.WithBundleMCPPolicy(mcpCfg.Policy()))
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			result, err := bundleUC.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+				Bundle:     bundle,
+				OnConflict: mode,
+			})
+			if err != nil {
+				return goerr.Wrap(err, "failed to import agent")
+			}
+
+			fmt.Fprintf(os.Stdout, "Agent %s (%s): %s %v\n",
+				result.Agent.AgentID, result.Agent.ID, result.Action, result.Versions)
+			return nil
+		},
+	}
+}
+
+// newAgentBundleUseCase creates the use case of bundles with Firestore. Images are handled only if
+// storage is configured.
+func newAgentBundleUseCase(ctx context.Context, firestoreCfg *config.Firestore, storageCfg *config.Storage, extraOpts ...usecase.AgentBundleOption) (*usecase.AgentBundle, func(), error) {
+	if err := firestoreCfg.Validate(); err != nil {
+		return nil, nil, goerr.Wrap(err, "invalid firestore configuration")
+	}
+	if firestoreCfg.ProjectID == "" {
+		return nil, nil, goerr.New("firestore-project-id is required")
+	}
+
+	client, err := firestore.New(ctx, firestoreCfg.ProjectID, firestoreCfg.DatabaseID)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to create firestore client")
+	}
+	cleanups := []func(){func() { _ = client.Close() }}
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	opts := []usecase.AgentBundleOption{
+		usecase.WithBundleAgentRepository(client),
+		usecase.WithBundleSearchConfigRepositories(
+			firestore.NewSlackSearchConfigRepository(client.GetClient()),
+			firestore.NewJiraSearchConfigRepository(client.GetClient()),
+			firestore.NewNotionSearchConfigRepository(client.GetClient()),
+		),
+	}
+
+	if storageCfg.Validate() == nil {
+		imageStorage, storageCleanup, err := storageCfg.CreateImageAdapter(ctx)
+		if err != nil {
+			cleanup()
+			return nil, nil, goerr.Wrap(err, "failed to create storage adapter for images")
+		}
+		if storageCleanup != nil {
+			cleanups = append(cleanups, storageCleanup)
+		}
+
+		imageRepo := client.NewAgentImageRepository()
+		processor := image.NewProcessor(image.NewValidator(), imageStorage, imageRepo, client, image.DefaultProcessorConfig())
+		opts = append(opts, usecase.WithBundleImage(imageRepo, processor))
+	} else {
+		ctxlog.From(ctx).Warn("storage is not configured, avatar image is skipped")
+	}
+
+	return usecase.NewAgentBundle(append(opts, extraOpts...)...), cleanup, nil
+}
@@ -28,6 +28,7 @@ func Run(ctx context.Context, args []string) error {
 		},
 		Commands: []*cli.Command{
 			cmdServe(),
+			cmdAgent(),
 			cmdTool(),
 		},
 	}
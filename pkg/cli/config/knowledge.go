@@ -0,0 +1,74 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/repository/vector"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	// KnowledgeIndexMemory keeps the vector index in memory. It is lost on restart.
+	KnowledgeIndexMemory = "memory"
+	// KnowledgeIndexFile persists the vector index to a file
+	KnowledgeIndexFile = "file"
+)
+
+// Knowledge contains configuration for the vector index of knowledge bases
+type Knowledge struct {
+	Index     string
+	IndexPath string
+}
+
+// Flags returns CLI flags for Knowledge configuration
+func (k *Knowledge) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "knowledge-index",
+			Sources:     cli.EnvVars("TAMAMO_KNOWLEDGE_INDEX"),
+			Usage:       "Vector index of knowledge bases (memory, file)",
+			Value:       KnowledgeIndexFile,
+			Destination: &k.Index,
+		},
+		&cli.StringFlag{
+			Name:        "knowledge-index-path",
+			Sources:     cli.EnvVars("TAMAMO_KNOWLEDGE_INDEX_PATH"),
+			Usage:       "Path of the file vector index (default: knowledge/index.json.gz under --file-storage-path)",
+			Destination: &k.IndexPath,
+		},
+	}
+}
+
+// Validate validates the Knowledge configuration
+func (k *Knowledge) Validate() error {
+	switch k.Index {
+	case KnowledgeIndexMemory, KnowledgeIndexFile:
+		return nil
+	default:
+		return goerr.New("invalid knowledge index, must be memory or file", goerr.V("index", k.Index))
+	}
+}
+
+// CreateIndex creates the vector index. The file index is placed under fsPath if the index
+// path is not set, and the memory index is used if neither is set.
+func (k *Knowledge) CreateIndex(fsPath string) (interfaces.VectorIndex, string, error) {
+	if k.Index == KnowledgeIndexMemory {
+		return vector.NewMemory(), "", nil
+	}
+
+	path := k.IndexPath
+	if path == "" && fsPath != "" {
+		path = filepath.Join(fsPath, "knowledge", "index.json.gz")
+	}
+	if path == "" {
+		return vector.NewMemory(), "", nil
+	}
+
+	index, err := vector.NewFile(path)
+	if err != nil {
+		return nil, "", goerr.Wrap(err, "failed to create file vector index", goerr.V("path", path))
+	}
+	return index, path, nil
+}
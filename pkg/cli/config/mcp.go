@@ -0,0 +1,54 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/urfave/cli/v3"
+)
+
+// MCP is the configuration of MCP servers attached to agents
+type MCP struct {
+	AllowedCommands     []string
+	AllowPrivateNetwork bool
+}
+
+// Flags returns CLI flags for MCP configuration
+func (x *MCP) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "mcp-allowed-command",
+			Usage:       "Command line that stdio MCP servers can launch: absolute path and arguments separated by spaces (repeatable). Stdio MCP servers are rejected if not set",
+			Sources:     cli.EnvVars("TAMAMO_MCP_ALLOWED_COMMANDS"),
+			Destination: &x.AllowedCommands,
+		},
+		&cli.BoolFlag{
+			Name:        "mcp-allow-private-network",
+			Usage:       "Allow HTTP MCP servers on loopback and private network addresses",
+			Sources:     cli.EnvVars("TAMAMO_MCP_ALLOW_PRIVATE_NETWORK"),
+			Destination: &x.AllowPrivateNetwork,
+		},
+	}
+}
+
+// Validate validates the MCP configuration. Commands must be absolute paths so that PATH of the
+// server cannot change what is launched.
+func (x *MCP) Validate() error {
+	for _, command := range x.AllowedCommands {
+		fields := strings.Fields(command)
+		if len(fields) == 0 || !filepath.IsAbs(fields[0]) {
+			return goerr.New("allowed MCP command must start with an absolute path", goerr.V("command", command))
+		}
+	}
+	return nil
+}
+
+// Policy returns the policy of MCP servers configured by the operator
+func (x *MCP) Policy() agent.MCPPolicy {
+	return agent.MCPPolicy{
+		AllowedCommands:     x.AllowedCommands,
+		AllowPrivateNetwork: x.AllowPrivateNetwork,
+	}
+}
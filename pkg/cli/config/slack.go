@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	slackSvc "github.com/m-mizutani/tamamo/pkg/service/slack"
@@ -10,6 +12,9 @@ import (
 type Slack struct {
 	OAuthToken    string `masq:"secret"`
 	SigningSecret string `masq:"secret"`
+
+	Streaming            bool
+	StreamUpdateInterval time.Duration
 }
 
 func (x *Slack) Flags() []cli.Flag {
@@ -28,6 +33,19 @@ func (x *Slack) Flags() []cli.Flag {
 			Destination: &x.SigningSecret,
 			Required:    true,
 		},
+		&cli.BoolFlag{
+			Name:        "slack-streaming",
+			Usage:       "Stream LLM responses by updating a placeholder message progressively",
+			Sources:     cli.EnvVars("TAMAMO_SLACK_STREAMING"),
+			Destination: &x.Streaming,
+		},
+		&cli.DurationFlag{
+			Name:        "slack-stream-update-interval",
+			Usage:       "Minimum interval between message updates while streaming (chat.update is rate limited)",
+			Sources:     cli.EnvVars("TAMAMO_SLACK_STREAM_UPDATE_INTERVAL"),
+			Value:       1500 * time.Millisecond,
+			Destination: &x.StreamUpdateInterval,
+		},
 	}
 }
 
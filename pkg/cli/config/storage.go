@@ -98,3 +98,20 @@ func (s *Storage) CreateAdapter(ctx context.Context) (interfaces.StorageAdapter,
 		return nil, nil, goerr.New("no storage backend configured")
 	}
 }
+
+// CreateImageAdapter creates storage adapter for agent images. Cloud Storage is shared with other
+// data, and file system storage uses the images subdirectory.
+func (s *Storage) CreateImageAdapter(ctx context.Context) (interfaces.StorageAdapter, func(), error) {
+	if s.HasCloudStorage() {
+		return s.CreateAdapter(ctx)
+	}
+	if s.FSPath == "" {
+		return nil, nil, goerr.New("no storage backend configured")
+	}
+
+	fsClient, err := fs.New(&fs.Config{BaseDirectory: s.FSPath + "/images"})
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to create file system storage adapter for images")
+	}
+	return fsClient, nil, nil
+}
@@ -12,12 +12,11 @@ import (
 
 	"github.com/m-mizutani/ctxlog"
 	"github.com/m-mizutani/goerr/v2"
-	"github.com/m-mizutani/tamamo/pkg/adapters/cs"
-	"github.com/m-mizutani/tamamo/pkg/adapters/fs"
 	"github.com/m-mizutani/tamamo/pkg/cli/config"
 	auth_controller "github.com/m-mizutani/tamamo/pkg/controller/auth"
 	graphql_controller "github.com/m-mizutani/tamamo/pkg/controller/graphql"
 	server "github.com/m-mizutani/tamamo/pkg/controller/http"
+	"github.com/m-mizutani/tamamo/pkg/controller/scheduler"
 	slack_controller "github.com/m-mizutani/tamamo/pkg/controller/slack"
 	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
 	"github.com/m-mizutani/tamamo/pkg/repository/database/firestore"
@@ -25,9 +24,11 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/repository/storage"
 	"github.com/m-mizutani/tamamo/pkg/service/image"
 	"github.com/m-mizutani/tamamo/pkg/service/jira"
+	mcpservice "github.com/m-mizutani/tamamo/pkg/service/mcp"
 	"github.com/m-mizutani/tamamo/pkg/service/notion"
 	"github.com/m-mizutani/tamamo/pkg/service/slack"
 	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/m-mizutani/tamamo/pkg/utils/errors"
 	"github.com/urfave/cli/v3"
 )
 
@@ -42,6 +43,8 @@ func cmdServe() *cli.Command {
 		storageCfg     config.Storage
 		jiraCfg        config.Jira
 		notionCfg      config.Notion
+		knowledgeCfg   config.Knowledge
+		mcpCfg         config.MCP
 		enableGraphiQL bool
 	)
 
@@ -69,6 +72,8 @@ func cmdServe() *cli.Command {
 	flags = append(flags, storageCfg.Flags()...)
 	flags = append(flags, jiraCfg.Flags()...)
 	flags = append(flags, notionCfg.Flags()...)
+	flags = append(flags, knowledgeCfg.Flags()...)
+	flags = append(flags, mcpCfg.Flags()...)
 
 	return &cli.Command{
 		Name:    "serve",
@@ -93,6 +98,16 @@ func cmdServe() *cli.Command {
 				return goerr.Wrap(err, "invalid storage configuration")
 			}
 
+			// Validate knowledge configuration
+			if err := knowledgeCfg.Validate(); err != nil {
+				return goerr.Wrap(err, "invalid knowledge configuration")
+			}
+
+			// Validate MCP configuration
+			if err := mcpCfg.Validate(); err != nil {
+				return goerr.Wrap(err, "invalid MCP configuration")
+			}
+
 			// Load and validate LLM configuration
 			providersConfig, err := llmCfg.LoadAndValidate()
 			if err != nil {
@@ -156,6 +171,12 @@ func cmdServe() *cli.Command {
 			var slackSearchConfigRepo interfaces.SlackSearchConfigRepository
 			var jiraSearchConfigRepo interfaces.JiraSearchConfigRepository
 			var notionSearchConfigRepo interfaces.NotionSearchConfigRepository
+			var knowledgeRepo interfaces.KnowledgeRepository
+			var scheduleRepo interfaces.ScheduleRepository
+			var webhookRepo interfaces.WebhookRepository
+			var apiTokenRepo interfaces.APITokenRepository
+			var evalRepo interfaces.EvalRepository
+			var agentMetricsRepo interfaces.AgentMetricsRepository
 			firestoreCfg.SetDefaults()
 
 			// Validate Firestore configuration
@@ -195,6 +216,12 @@ func cmdServe() *cli.Command {
 				slackSearchConfigRepo = firestore.NewSlackSearchConfigRepository(client.GetClient())
 				jiraSearchConfigRepo = firestore.NewJiraSearchConfigRepository(client.GetClient())
 				notionSearchConfigRepo = firestore.NewNotionSearchConfigRepository(client.GetClient())
+				knowledgeRepo = firestore.NewKnowledgeRepository(client.GetClient())
+				scheduleRepo = firestore.NewScheduleRepository(client.GetClient())
+				webhookRepo = firestore.NewWebhookRepository(client.GetClient())
+				apiTokenRepo = firestore.NewAPITokenRepository(client.GetClient())
+				evalRepo = firestore.NewEvalRepository(client.GetClient())
+				agentMetricsRepo = firestore.NewAgentMetricsRepository(client.GetClient())
 			} else {
 				// Use memory repository as fallback
 				logger.Warn("using in-memory repository (data will be lost on restart)")
@@ -208,6 +235,12 @@ func cmdServe() *cli.Command {
 				slackSearchConfigRepo = memory.NewSlackSearchConfigRepository()
 				jiraSearchConfigRepo = memory.NewJiraSearchConfigRepository()
 				notionSearchConfigRepo = memory.NewNotionSearchConfigRepository()
+				knowledgeRepo = memory.NewKnowledgeRepository()
+				scheduleRepo = memory.NewScheduleRepository()
+				webhookRepo = memory.NewWebhookRepository()
+				apiTokenRepo = memory.NewAPITokenRepository()
+				evalRepo = memory.NewEvalRepository()
+				agentMetricsRepo = memory.NewAgentMetricsRepository()
 			}
 
 			logger.Info("starting server",
@@ -229,33 +262,20 @@ func cmdServe() *cli.Command {
 
 			// Configure image storage adapter (use same storage config but with images subdirectory)
 			logger.Info("configuring image storage adapter")
-			var imageStorageAdapter interfaces.StorageAdapter
+			imageStorageAdapter, imageStorageCleanup, err := storageCfg.CreateImageAdapter(ctx)
+			if err != nil {
+				return goerr.Wrap(err, "failed to create storage adapter for images")
+			}
+			if imageStorageCleanup != nil {
+				defer imageStorageCleanup()
+			}
 			if storageCfg.HasCloudStorage() {
-				// Use Cloud Storage for images
-				opts := []cs.Option{}
-				if storageCfg.Prefix != "" {
-					opts = append(opts, cs.WithPrefix(storageCfg.Prefix))
-				}
-
-				csClient, err := cs.New(ctx, storageCfg.Bucket, opts...)
-				if err != nil {
-					return goerr.Wrap(err, "failed to create Cloud Storage client for images")
-				}
-				defer csClient.Close()
-				imageStorageAdapter = csClient
 				logger.Info("using Cloud Storage for images",
 					"bucket", storageCfg.Bucket,
 					"prefix", storageCfg.Prefix,
 				)
 			} else {
-				// Use file system storage for images (use same base path with images subdirectory)
-				imagePath := storageCfg.FSPath + "/images"
-				fsClient, err := fs.New(&fs.Config{BaseDirectory: imagePath})
-				if err != nil {
-					return goerr.Wrap(err, "failed to create file system storage adapter for images")
-				}
-				imageStorageAdapter = fsClient
-				logger.Info("using file system storage for images", "path", imagePath)
+				logger.Info("using file system storage for images", "path", storageCfg.FSPath+"/images")
 			}
 
 			// Create image processor
@@ -284,33 +304,6 @@ func cmdServe() *cli.Command {
 				logger.Warn("authentication disabled - running in anonymous mode")
 			}
 
-			// Create usecase with LLM integration
-			// Use FRONTEND_URL as base for agent image URLs (public access)
-			serverBaseURL := os.Getenv("FRONTEND_URL")
-			if serverBaseURL == "" {
-				// Fallback to public URL or listen address
-				serverBaseURL = os.Getenv("TAMAMO_PUBLIC_URL")
-				if serverBaseURL == "" {
-					serverBaseURL = "http://" + addr
-				}
-			}
-			uc := usecase.New(
-				usecase.WithSlackClient(slackSvc),
-				usecase.WithRepository(repo),
-				usecase.WithAgentRepository(agentRepo),
-				usecase.WithAgentImageRepository(agentImageRepo),
-				usecase.WithSlackMessageLogRepository(slackMessageLogRepo),
-				usecase.WithStorageRepository(storageRepo),
-				usecase.WithLLMFactory(llmFactory),
-				usecase.WithServerBaseURL(serverBaseURL),
-			)
-
-			// Create controllers
-			slackCtrl := slack_controller.New(uc, slackSvc)
-
-			// Create agent use case
-			agentUseCase := usecase.NewAgentUseCases(agentRepo)
-
 			// Create search config use cases
 			slackSearchConfigUseCases := usecase.NewSlackSearchConfig(
 				usecase.WithSlackSearchConfigRepository(slackSearchConfigRepo),
@@ -325,6 +318,9 @@ func cmdServe() *cli.Command {
 				usecase.WithNotionSearchConfigAgentRepository(agentRepo),
 			)
 
+			// Create agent use case
+			agentUseCase := usecase.NewAgentUseCases(agentRepo, usecase.WithMCPPolicy(mcpCfg.Policy()))
+
 			// Create Jira integration components (if configured)
 			var jiraUseCases usecase.JiraIntegrationUseCases
 			var jiraAuthController *server.JiraAuthController
@@ -367,7 +363,163 @@ func cmdServe() *cli.Command {
 				logger.Info("Notion integration disabled (missing configuration)")
 			}
 
-			graphqlCtrl := graphql_controller.NewResolver(repo, agentUseCase, userUseCase, llmFactory, imageProcessor, agentImageRepo, jiraUseCases, notionUseCases, slackSearchConfigUseCases, jiraSearchConfigUseCases, notionSearchConfigUseCases)
+			// Create knowledge base use cases (if configured)
+			var knowledgeUseCases interfaces.KnowledgeUseCases
+			if providersConfig.Knowledge.Enabled {
+				embeddingClient, err := llmFactory.GetEmbeddingClient(ctx)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create knowledge embedding client")
+				}
+
+				index, indexPath, err := knowledgeCfg.CreateIndex(storageCfg.FSPath)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create knowledge vector index")
+				}
+				if indexPath == "" {
+					logger.Warn("using in-memory knowledge index (stored documents are embedded again at every startup)")
+				}
+
+				knowledgeUC := usecase.NewKnowledge(
+					usecase.WithKnowledgeRepository(knowledgeRepo),
+					usecase.WithKnowledgeVectorIndex(index),
+					usecase.WithKnowledgeStorage(storageAdapter),
+					usecase.WithKnowledgeAgentRepository(agentRepo),
+					usecase.WithKnowledgeEmbedder(embeddingClient,
+						providersConfig.Knowledge.Provider+":"+providersConfig.Knowledge.Model,
+						providersConfig.Knowledge.Dimension),
+				)
+				knowledgeUseCases = knowledgeUC
+
+				// The index may have lost documents kept in the repository, e.g. the memory
+				// index after restart, so they are indexed again in background
+				go func() {
+					if err := knowledgeUC.ReindexKnowledge(ctx); err != nil {
+						errors.Handle(ctx, err)
+					}
+				}()
+
+				logger.Info("knowledge base enabled",
+					"provider", providersConfig.Knowledge.Provider,
+					"model", providersConfig.Knowledge.Model,
+					"index_path", indexPath,
+				)
+			} else {
+				logger.Info("knowledge base disabled (knowledge is not enabled in LLM configuration)")
+			}
+
+			// Create usecase with LLM integration
+			// Use FRONTEND_URL as base for agent image URLs (public access)
+			serverBaseURL := os.Getenv("FRONTEND_URL")
+			if serverBaseURL == "" {
+				// Fallback to public URL or listen address
+				serverBaseURL = os.Getenv("TAMAMO_PUBLIC_URL")
+				if serverBaseURL == "" {
+					serverBaseURL = "http://" + addr
+				}
+			}
+
+			// Workspace URL is used for permalinks in Slack search tool results, and team ID for
+			// threads started by schedules and webhooks. They always run in serve, and their
+			// threads can not be found by later mentions without the team ID, so fail early.
+			authTestInfo, err := slackSvc.GetAuthTestInfo()
+			if err != nil {
+				return goerr.Wrap(err, "failed to resolve Slack team ID, which is required by schedules and webhooks")
+			}
+			if authTestInfo.TeamID == "" {
+				return goerr.New("Slack team ID is empty, which is required by schedules and webhooks")
+			}
+			slackWorkspaceURL := authTestInfo.URL
+			slackTeamID := authTestInfo.TeamID
+
+			slackOptions := []usecase.SlackOption{
+				usecase.WithSlackClient(slackSvc),
+				usecase.WithRepository(repo),
+				usecase.WithAgentRepository(agentRepo),
+				usecase.WithAgentImageRepository(agentImageRepo),
+				usecase.WithSlackMessageLogRepository(slackMessageLogRepo),
+				usecase.WithStorageRepository(storageRepo),
+				usecase.WithLLMFactory(llmFactory),
+				usecase.WithServerBaseURL(serverBaseURL),
+				usecase.WithSlackSearchConfigUseCases(slackSearchConfigUseCases),
+				usecase.WithSlackWorkspaceURL(slackWorkspaceURL),
+				usecase.WithSlackTeamID(slackTeamID),
+				usecase.WithJiraSearchConfigUseCases(jiraSearchConfigUseCases),
+				usecase.WithJiraIntegrationUseCases(jiraUseCases),
+				usecase.WithNotionSearchConfigUseCases(notionSearchConfigUseCases),
+				usecase.WithNotionIntegrationUseCases(notionUseCases),
+				usecase.WithUserRepository(userRepo),
+				usecase.WithStreamResponse(slackCfg.Streaming),
+				usecase.WithStreamUpdateInterval(slackCfg.StreamUpdateInterval),
+				usecase.WithKnowledgeUseCases(knowledgeUseCases),
+				usecase.WithAgentMetricsRepository(agentMetricsRepo),
+				usecase.WithMCPConnectOptions(mcpservice.WithPolicy(mcpCfg.Policy())),
+			}
+
+			// Route mentions without agent ID to an agent if the router is configured
+			if providersConfig.Routing.Enabled {
+				routerClient, err := llmFactory.GetRoutingClient(ctx)
+				if err != nil {
+					return goerr.Wrap(err, "failed to create agent router client")
+				}
+				slackOptions = append(slackOptions, usecase.WithAgentRouter(routerClient, providersConfig.Routing.Threshold))
+				logger.Info("agent routing enabled",
+					"provider", providersConfig.Routing.Provider,
+					"model", providersConfig.Routing.Model,
+					"threshold", providersConfig.Routing.Threshold,
+				)
+			}
+
+			uc := usecase.New(slackOptions...)
+
+			// Run schedules and webhooks of agents through the same session as mentions
+			scheduleUseCases := usecase.NewSchedule(
+				usecase.WithScheduleRepository(scheduleRepo),
+				usecase.WithScheduleAgentRepository(agentRepo),
+				usecase.WithScheduleRunner(uc),
+			)
+			webhookUseCases := usecase.NewWebhook(
+				usecase.WithWebhookRepository(webhookRepo),
+				usecase.WithWebhookAgentRepository(agentRepo),
+				usecase.WithWebhookRunner(uc),
+			)
+			apiTokenUseCases := usecase.NewAPIToken(apiTokenRepo)
+
+			// Evaluate agents with the same session as mentions, and judge rubrics with the default LLM
+			evalUseCases := usecase.NewEval(
+				usecase.WithEvalRepository(evalRepo),
+				usecase.WithEvalAgentRepository(agentRepo),
+				usecase.WithEvalAnswerer(uc),
+				usecase.WithEvalJudgeClient(llmFactory.GetDefaultClient()),
+			)
+			rolloutUseCases := usecase.NewRollout(
+				usecase.WithRolloutAgentRepository(agentRepo),
+				usecase.WithRolloutMetricsRepository(agentMetricsRepo),
+			)
+			bundleUseCases := usecase.NewAgentBundle(
+				usecase.WithBundleAgentRepository(agentRepo),
+				usecase.WithBundleSearchConfigRepositories(slackSearchConfigRepo, jiraSearchConfigRepo, notionSearchConfigRepo),
+				usecase.WithBundleImage(agentImageRepo, imageProcessor),
+				usecase.WithBundleMCPPolicy(mcpCfg.Policy()),
+			)
+
+			// Create controllers
+			slackCtrl := slack_controller.New(uc, slackSvc)
+			slackInteractionCtrl := slack_controller.NewInteractionController(usecase.NewSlackInteraction(
+				usecase.WithShortcutHandler(usecase.RegenerateShortcutCallbackID, uc.HandleRegenerateShortcut),
+				usecase.WithShortcutHandler(usecase.PositiveFeedbackShortcutCallbackID, uc.HandlePositiveFeedbackShortcut),
+				usecase.WithShortcutHandler(usecase.NegativeFeedbackShortcutCallbackID, uc.HandleNegativeFeedbackShortcut),
+			))
+			slackCommandCtrl := slack_controller.NewCommandController(uc)
+
+			graphqlCtrl := graphql_controller.NewResolver(repo, agentUseCase, userUseCase, llmFactory, imageProcessor, agentImageRepo, jiraUseCases, notionUseCases, slackSearchConfigUseCases, jiraSearchConfigUseCases, notionSearchConfigUseCases,
+				graphql_controller.WithKnowledgeUseCases(knowledgeUseCases),
+				graphql_controller.WithScheduleUseCases(scheduleUseCases),
+				graphql_controller.WithWebhookUseCases(webhookUseCases),
+				graphql_controller.WithAPITokenUseCases(apiTokenUseCases),
+				graphql_controller.WithEvalUseCases(evalUseCases),
+				graphql_controller.WithRolloutUseCases(rolloutUseCases),
+				graphql_controller.WithBundleUseCases(bundleUseCases),
+			)
 
 			// Create user controller
 			userCtrl := server.NewUserController(userUseCase)
@@ -376,12 +528,20 @@ func cmdServe() *cli.Command {
 			imageUseCase := usecase.NewImageUseCases(imageProcessor, agentImageRepo, agentUseCase)
 			imageCtrl := server.NewImageController(imageUseCase)
 
+			webhookCtrl := server.NewWebhookController(webhookUseCases)
+			conversationCtrl := server.NewConversationController(uc)
+
 			// Build HTTP server options
 			serverOptions := []server.Options{
 				server.WithSlackController(slackCtrl),
+				server.WithSlackInteractionController(slackInteractionCtrl),
+				server.WithSlackCommandController(slackCommandCtrl),
 				server.WithGraphQLController(graphqlCtrl),
 				server.WithUserController(userCtrl),
 				server.WithImageController(imageCtrl),
+				server.WithWebhookController(webhookCtrl),
+				server.WithConversationController(conversationCtrl),
+				server.WithAPITokenUseCase(apiTokenUseCases),
 				server.WithGraphiQL(enableGraphiQL),
 				server.WithSlackVerifier(slackCfg.Verifier()),
 				server.WithNoAuth(authCfg.NoAuthentication),
@@ -415,6 +575,10 @@ func cmdServe() *cli.Command {
 				},
 			}
 
+			schedulerCtx, stopScheduler := context.WithCancel(ctx)
+			defer stopScheduler()
+			go scheduler.New(scheduleUseCases).Run(schedulerCtx)
+
 			errCh := make(chan error, 1)
 			go func() {
 				defer close(errCh)
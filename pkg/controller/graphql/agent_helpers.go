@@ -3,6 +3,8 @@ package graphql
 import (
 	"context"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
 	agentmodel "github.com/m-mizutani/tamamo/pkg/domain/model/agent"
@@ -89,6 +91,14 @@ func convertAgentToGraphQL(ctx context.Context, a *agentmodel.Agent, latestVersi
 		result.LatestVersion = convertAgentVersionToGraphQL(latestVersion)
 	}
 
+	if a.Rollout != nil {
+		result.Rollout = &graphql1.AgentRollout{
+			CandidateVersion: a.Rollout.CandidateVersion,
+			Percentage:       a.Rollout.Percentage,
+			StartedAt:        a.Rollout.StartedAt,
+		}
+	}
+
 	return result
 }
 
@@ -110,17 +120,149 @@ func convertAgentVersionToGraphQL(v *agentmodel.AgentVersion) *graphql1.AgentVer
 		llmModel = &v.LLMModel
 	}
 
+	var changelog *string
+	if v.Changelog != "" {
+		changelog = &v.Changelog
+	}
+
+	var authorID *string
+	if v.Author != "" {
+		id := v.Author.String()
+		authorID = &id
+	}
+
 	return &graphql1.AgentVersion{
-		AgentUUID:    v.AgentUUID.String(),
-		Version:      v.Version,
-		SystemPrompt: v.SystemPrompt,
-		LlmProvider:  llmProvider,
-		LlmModel:     llmModel,
-		CreatedAt:    v.CreatedAt,
-		UpdatedAt:    v.UpdatedAt,
+		AgentUUID:       v.AgentUUID.String(),
+		Version:         v.Version,
+		SystemPrompt:    v.SystemPrompt,
+		PromptTemplate:  v.PromptTemplate,
+		PromptVariables: convertKeyValuesToGraphQL(v.PromptVariables),
+		LlmProvider:     llmProvider,
+		LlmModel:        llmModel,
+		McpServers:      convertMCPServersToGraphQL(v.MCPServers),
+		Delegation:      convertDelegationToGraphQL(v.Delegation),
+		Draft:           v.IsDraft(),
+		AuthorID:        authorID,
+		PublishedAt:     v.PublishedAt,
+		Changelog:       changelog,
+		CreatedAt:       v.CreatedAt,
+		UpdatedAt:       v.UpdatedAt,
 	}
 }
 
+// convertMCPServersToGraphQL converts domain MCPServers to GraphQL MCPServers.
+// Values of env and headers are not exposed because they may contain secrets.
+func convertMCPServersToGraphQL(servers []*agentmodel.MCPServer) []*graphql1.MCPServer {
+	result := make([]*graphql1.MCPServer, 0, len(servers))
+	for _, s := range servers {
+		server := &graphql1.MCPServer{
+			Name:           s.Name,
+			Transport:      graphql1.MCPTransportStdio,
+			Args:           s.Args,
+			EnvKeys:        slices.Sorted(maps.Keys(s.Env)),
+			HeaderKeys:     slices.Sorted(maps.Keys(s.Headers)),
+			AllowedTools:   s.AllowedTools,
+			TimeoutSeconds: int(s.Timeout().Seconds()),
+		}
+		if s.Transport == agentmodel.MCPTransportHTTP {
+			server.Transport = graphql1.MCPTransportHTTP
+		}
+		if s.Command != "" {
+			server.Command = &s.Command
+		}
+		if s.URL != "" {
+			server.URL = &s.URL
+		}
+
+		// Non-null lists must not be null in the response
+		if server.Args == nil {
+			server.Args = []string{}
+		}
+		if server.EnvKeys == nil {
+			server.EnvKeys = []string{}
+		}
+		if server.HeaderKeys == nil {
+			server.HeaderKeys = []string{}
+		}
+		if server.AllowedTools == nil {
+			server.AllowedTools = []string{}
+		}
+
+		result = append(result, server)
+	}
+	return result
+}
+
+// convertMCPServerInputToDomain converts GraphQL MCPServerInput to domain MCPServer.
+// Env and Headers are left nil when omitted so that existing values are kept.
+func convertMCPServerInputToDomain(input graphql1.MCPServerInput) *agentmodel.MCPServer {
+	server := &agentmodel.MCPServer{
+		Name:         input.Name,
+		Transport:    agentmodel.MCPTransportStdio,
+		Args:         input.Args,
+		AllowedTools: input.AllowedTools,
+	}
+	if input.Transport == graphql1.MCPTransportHTTP {
+		server.Transport = agentmodel.MCPTransportHTTP
+	}
+	if input.Command != nil {
+		server.Command = *input.Command
+	}
+	if input.URL != nil {
+		server.URL = *input.URL
+	}
+	if input.TimeoutSeconds != nil {
+		server.TimeoutSeconds = *input.TimeoutSeconds
+	}
+	if input.Env != nil {
+		server.Env = convertKeyValueInputs(input.Env)
+	}
+	if input.Headers != nil {
+		server.Headers = convertKeyValueInputs(input.Headers)
+	}
+	return server
+}
+
+// convertDelegationToGraphQL converts domain Delegation to GraphQL Delegation
+func convertDelegationToGraphQL(d *agentmodel.Delegation) *graphql1.Delegation {
+	if d == nil {
+		return nil
+	}
+	agentIDs := d.AgentIDs
+	if agentIDs == nil {
+		agentIDs = []string{}
+	}
+	return &graphql1.Delegation{
+		Enabled:  d.Enabled,
+		AgentIds: agentIDs,
+	}
+}
+
+// convertDelegationInputToDomain converts GraphQL DelegationInput to domain Delegation
+func convertDelegationInputToDomain(input graphql1.DelegationInput) *agentmodel.Delegation {
+	return &agentmodel.Delegation{
+		Enabled:  input.Enabled,
+		AgentIDs: input.AgentIds,
+	}
+}
+
+func convertKeyValueInputs(inputs []*graphql1.KeyValueInput) map[string]string {
+	result := make(map[string]string, len(inputs))
+	for _, kv := range inputs {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
+
+// convertKeyValuesToGraphQL converts a map to GraphQL KeyValues sorted by key
+func convertKeyValuesToGraphQL(values map[string]string) []*graphql1.KeyValue {
+	result := make([]*graphql1.KeyValue, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		result = append(result, &graphql1.KeyValue{Key: key, Value: values[key]})
+	}
+	return result
+}
+
 // convertAgentStatusToGraphQL converts domain Agent Status to GraphQL AgentStatus
 func convertAgentStatusToGraphQL(s agentmodel.Status) graphql1.AgentStatus {
 	switch s {
@@ -223,13 +365,20 @@ func convertUpdateAgentInputToRequest(input graphql1.UpdateAgentInput) *interfac
 
 // convertCreateAgentVersionInputToRequest converts GraphQL input to use case request
 func convertCreateAgentVersionInputToRequest(input graphql1.CreateAgentVersionInput) *interfaces.CreateVersionRequest {
-	return &interfaces.CreateVersionRequest{
-		AgentUUID:    types.UUID(input.AgentUUID),
-		Version:      input.Version,
-		SystemPrompt: input.SystemPrompt,
-		LLMProvider:  convertGraphQLLLMProviderToDomain(input.LlmProvider),
-		LLMModel:     input.LlmModel,
+	req := &interfaces.CreateVersionRequest{
+		AgentUUID:       types.UUID(input.AgentUUID),
+		Version:         input.Version,
+		SystemPrompt:    input.SystemPrompt,
+		PromptTemplate:  input.PromptTemplate != nil && *input.PromptTemplate,
+		PromptVariables: convertKeyValueInputs(input.PromptVariables),
+		LLMProvider:     convertGraphQLLLMProviderToDomain(input.LlmProvider),
+		LLMModel:        input.LlmModel,
+		Draft:           input.Draft != nil && *input.Draft,
+	}
+	if input.Changelog != nil {
+		req.Changelog = *input.Changelog
 	}
+	return req
 }
 
 // convertAgentImageToGraphQL converts domain AgentImage to GraphQL AgentImage
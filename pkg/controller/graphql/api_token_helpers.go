@@ -0,0 +1,32 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/controller/auth"
+	auth_model "github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// requireSession returns the session of the current user. API tokens belong to users, so they
+// are not available without authentication.
+func requireSession(ctx context.Context) (*auth_model.Session, error) {
+	session, ok := auth.UserFromContext(ctx)
+	if !ok || session == nil {
+		return nil, goerr.New("authentication required", goerr.T(apperr.ErrTagUnauthorized))
+	}
+	return session, nil
+}
+
+// convertAPITokenToGraphQL converts domain APIToken to GraphQL ApiToken. The token hash is not
+// exposed.
+func convertAPITokenToGraphQL(t *auth_model.APIToken) *graphql1.APIToken {
+	return &graphql1.APIToken{
+		ID:         t.ID.String(),
+		Name:       t.Name,
+		CreatedAt:  t.CreatedAt,
+		LastUsedAt: t.LastUsedAt,
+	}
+}
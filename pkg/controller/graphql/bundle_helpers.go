@@ -0,0 +1,36 @@
+package graphql
+
+import (
+	agentmodel "github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+)
+
+// convertImportConflictToDomain converts GraphQL ImportConflict to domain ImportConflictMode.
+// SKIP is used if not specified.
+func convertImportConflictToDomain(conflict *graphql1.ImportConflict) agentmodel.ImportConflictMode {
+	if conflict == nil {
+		return agentmodel.ImportConflictSkip
+	}
+	switch *conflict {
+	case graphql1.ImportConflictOverwrite:
+		return agentmodel.ImportConflictOverwrite
+	case graphql1.ImportConflictNewVersion:
+		return agentmodel.ImportConflictNewVersion
+	default:
+		return agentmodel.ImportConflictSkip
+	}
+}
+
+// convertImportActionToGraphQL converts domain ImportAction to GraphQL ImportAgentAction
+func convertImportActionToGraphQL(action agentmodel.ImportAction) graphql1.ImportAgentAction {
+	switch action {
+	case agentmodel.ImportActionCreated:
+		return graphql1.ImportAgentActionCreated
+	case agentmodel.ImportActionOverwritten:
+		return graphql1.ImportAgentActionOverwritten
+	case agentmodel.ImportActionVersionAdded:
+		return graphql1.ImportAgentActionVersionAdded
+	default:
+		return graphql1.ImportAgentActionSkipped
+	}
+}
@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+// convertEvalCaseInput converts GraphQL EvalCaseInput to the request of the use case
+func convertEvalCaseInput(input graphql1.EvalCaseInput) *interfaces.EvalCaseRequest {
+	req := &interfaces.EvalCaseRequest{
+		AgentUUID:        types.UUID(input.AgentUUID),
+		Name:             input.Name,
+		Input:            input.Input,
+		ExpectedFacts:    input.ExpectedFacts,
+		ExpectedPatterns: input.ExpectedPatterns,
+	}
+	if input.Rubric != nil {
+		req.Rubric = *input.Rubric
+	}
+	return req
+}
+
+// convertEvalCaseToGraphQL converts domain eval Case to GraphQL EvalCase
+func convertEvalCaseToGraphQL(c *eval.Case) *graphql1.EvalCase {
+	result := &graphql1.EvalCase{
+		ID:               c.ID.String(),
+		AgentUUID:        c.AgentUUID.String(),
+		Name:             c.Name,
+		Input:            c.Input,
+		ExpectedFacts:    c.ExpectedFacts,
+		ExpectedPatterns: c.ExpectedPatterns,
+		CreatedAt:        c.CreatedAt,
+		UpdatedAt:        c.UpdatedAt,
+	}
+	if c.Rubric != "" {
+		result.Rubric = &c.Rubric
+	}
+	return result
+}
+
+// convertEvalRunToGraphQL converts domain eval Run to GraphQL EvalRun
+func convertEvalRunToGraphQL(run *eval.Run) *graphql1.EvalRun {
+	result := &graphql1.EvalRun{
+		ID:           run.ID.String(),
+		AgentUUID:    run.AgentUUID.String(),
+		AgentVersion: run.AgentVersion,
+		Status:       string(run.Status),
+		Passed:       run.Passed,
+		Failed:       run.Failed,
+		Results:      make([]*graphql1.EvalCaseResult, len(run.Results)),
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+	}
+	for i, r := range run.Results {
+		result.Results[i] = convertEvalCaseResultToGraphQL(r)
+	}
+	if run.Error != "" {
+		result.Error = &run.Error
+	}
+	return result
+}
+
+// convertEvalCaseResultToGraphQL converts domain eval CaseResult to GraphQL EvalCaseResult
+func convertEvalCaseResultToGraphQL(r *eval.CaseResult) *graphql1.EvalCaseResult {
+	if r == nil {
+		return nil
+	}
+
+	result := &graphql1.EvalCaseResult{
+		CaseID:     r.CaseID.String(),
+		CaseName:   r.CaseName,
+		Input:      r.Input,
+		Output:     r.Output,
+		Passed:     r.Passed,
+		Failures:   r.Failures,
+		DurationMs: int(r.DurationMS),
+	}
+	if r.JudgeReason != "" {
+		result.JudgeReason = &r.JudgeReason
+	}
+	if r.Error != "" {
+		result.Error = &r.Error
+	}
+	return result
+}
+
+// convertEvalCaseComparisonToGraphQL converts domain eval CaseComparison to GraphQL EvalCaseComparison
+func convertEvalCaseComparisonToGraphQL(c *eval.CaseComparison) *graphql1.EvalCaseComparison {
+	return &graphql1.EvalCaseComparison{
+		CaseID:    c.CaseID.String(),
+		CaseName:  c.CaseName,
+		Base:      convertEvalCaseResultToGraphQL(c.Base),
+		Target:    convertEvalCaseResultToGraphQL(c.Target),
+		Regressed: c.Regressed(),
+		Fixed:     c.Fixed(),
+	}
+}
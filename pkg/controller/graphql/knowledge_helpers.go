@@ -0,0 +1,21 @@
+package graphql
+
+import (
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+)
+
+// convertKnowledgeDocumentToGraphQL converts domain knowledge Document to GraphQL KnowledgeDocument
+func convertKnowledgeDocumentToGraphQL(doc *knowledge.Document) *graphql1.KnowledgeDocument {
+	return &graphql1.KnowledgeDocument{
+		ID:             doc.ID.String(),
+		AgentUUID:      doc.AgentUUID.String(),
+		Name:           doc.Name,
+		ContentType:    doc.ContentType,
+		Size:           int(doc.Size),
+		ChunkCount:     doc.ChunkCount,
+		EmbeddingModel: doc.EmbeddingModel,
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+	}
+}
@@ -23,6 +23,66 @@ type Resolver struct {
 	slackSearchConfigUseCases  interfaces.SlackSearchConfigUseCases
 	jiraSearchConfigUseCases   interfaces.JiraSearchConfigUseCases
 	notionSearchConfigUseCases interfaces.NotionSearchConfigUseCases
+	knowledgeUseCases          interfaces.KnowledgeUseCases
+	scheduleUseCases           interfaces.ScheduleUseCases
+	webhookUseCases            interfaces.WebhookUseCases
+	apiTokenUseCases           interfaces.APITokenUseCases
+	evalUseCases               interfaces.EvalUseCases
+	rolloutUseCases            interfaces.AgentRolloutUseCases
+	bundleUseCases             interfaces.AgentBundleUseCases
+}
+
+// ResolverOption is a functional option for optional features of Resolver. Resolvers of the
+// features return an error if they are not set.
+type ResolverOption func(*Resolver)
+
+// WithKnowledgeUseCases sets use cases of the knowledge base
+func WithKnowledgeUseCases(uc interfaces.KnowledgeUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.knowledgeUseCases = uc
+	}
+}
+
+// WithScheduleUseCases sets use cases of scheduled runs of agents
+func WithScheduleUseCases(uc interfaces.ScheduleUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.scheduleUseCases = uc
+	}
+}
+
+// WithWebhookUseCases sets use cases of webhook triggers of agents
+func WithWebhookUseCases(uc interfaces.WebhookUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.webhookUseCases = uc
+	}
+}
+
+// WithAPITokenUseCases sets use cases of API tokens
+func WithAPITokenUseCases(uc interfaces.APITokenUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.apiTokenUseCases = uc
+	}
+}
+
+// WithEvalUseCases sets use cases of agent evaluations
+func WithEvalUseCases(uc interfaces.EvalUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.evalUseCases = uc
+	}
+}
+
+// WithRolloutUseCases sets use cases of gradual rollouts of agent versions
+func WithRolloutUseCases(uc interfaces.AgentRolloutUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.rolloutUseCases = uc
+	}
+}
+
+// WithBundleUseCases sets use cases of exports and imports of agents
+func WithBundleUseCases(uc interfaces.AgentBundleUseCases) ResolverOption {
+	return func(r *Resolver) {
+		r.bundleUseCases = uc
+	}
 }
 
 // NewResolver creates a new resolver instance
@@ -38,8 +98,9 @@ func NewResolver(
 	slackSearchConfigUseCases interfaces.SlackSearchConfigUseCases,
 	jiraSearchConfigUseCases interfaces.JiraSearchConfigUseCases,
 	notionSearchConfigUseCases interfaces.NotionSearchConfigUseCases,
+	opts ...ResolverOption,
 ) *Resolver {
-	return &Resolver{
+	r := &Resolver{
 		threadRepo:                 threadRepo,
 		agentUseCase:               agentUseCase,
 		userUseCase:                userUseCase,
@@ -52,4 +113,8 @@ func NewResolver(
 		jiraSearchConfigUseCases:   jiraSearchConfigUseCases,
 		notionSearchConfigUseCases: notionSearchConfigUseCases,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	agentmodel "github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+	"github.com/m-mizutani/tamamo/pkg/utils/logging"
+)
+
+// convertUpdatedAgentToGraphQL converts the agent updated by a release or import mutation to
+// GraphQL Agent with its latest version, which is changed by the mutation
+func convertUpdatedAgentToGraphQL(ctx context.Context, a *agentmodel.Agent, agentUseCase interfaces.AgentUseCases, userUseCase interfaces.UserUseCases) *graphql1.Agent {
+	var latestVersion *agentmodel.AgentVersion
+	if agentUseCase != nil {
+		agentWithVersion, err := agentUseCase.GetAgent(ctx, a.ID)
+		if err != nil {
+			// Log the error but don't fail the mutation that has been done
+			logging.Default().Warn("Failed to fetch latest version of updated agent",
+				slog.String("agent_id", a.ID.String()),
+				slog.String("error", err.Error()))
+		} else {
+			latestVersion = agentWithVersion.LatestVersion
+		}
+	}
+
+	return convertAgentToGraphQL(ctx, a, latestVersion, userUseCase)
+}
+
+// convertAgentVersionMetricsToGraphQL converts domain VersionMetrics to GraphQL AgentVersionMetrics
+func convertAgentVersionMetricsToGraphQL(m *agentmodel.VersionMetrics) *graphql1.AgentVersionMetrics {
+	return &graphql1.AgentVersionMetrics{
+		Version:          m.Version,
+		Threads:          int(m.Threads),
+		Responses:        int(m.Responses),
+		Errors:           int(m.Errors),
+		AverageLatencyMs: int(m.AverageLatencyMS()),
+		PositiveFeedback: int(m.PositiveFeedback),
+		NegativeFeedback: int(m.NegativeFeedback),
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
+
+// convertAgentVersionDiffToGraphQL converts domain VersionDiff to GraphQL AgentVersionDiff
+func convertAgentVersionDiffToGraphQL(agentUUID string, d *agentmodel.VersionDiff) *graphql1.AgentVersionDiff {
+	result := &graphql1.AgentVersionDiff{
+		AgentUUID:    agentUUID,
+		From:         d.From,
+		To:           d.To,
+		SystemPrompt: make([]*graphql1.DiffLine, 0, len(d.SystemPrompt)),
+		Changes:      make([]*graphql1.FieldChange, 0, len(d.Changes)),
+	}
+	if d.Changelog != "" {
+		result.Changelog = &d.Changelog
+	}
+
+	for _, line := range d.SystemPrompt {
+		op := graphql1.DiffOpEqual
+		switch line.Op {
+		case agentmodel.DiffOpInsert:
+			op = graphql1.DiffOpInsert
+		case agentmodel.DiffOpDelete:
+			op = graphql1.DiffOpDelete
+		}
+		result.SystemPrompt = append(result.SystemPrompt, &graphql1.DiffLine{Op: op, Text: line.Text})
+	}
+	for _, c := range d.Changes {
+		result.Changes = append(result.Changes, &graphql1.FieldChange{Field: c.Field, From: c.From, To: c.To})
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+)
+
+// convertScheduleToGraphQL converts domain Schedule to GraphQL Schedule
+func convertScheduleToGraphQL(s *schedule.Schedule) *graphql1.Schedule {
+	result := &graphql1.Schedule{
+		ID:        s.ID.String(),
+		AgentUUID: s.AgentUUID.String(),
+		Cron:      s.Cron,
+		TimeZone:  s.TimeZone,
+		ChannelID: s.ChannelID,
+		Prompt:    s.Prompt,
+		Enabled:   s.Enabled,
+		LastRunAt: s.LastRunAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+	if s.AgentVersion != "" {
+		result.AgentVersion = &s.AgentVersion
+	}
+	if !s.NextRunAt.IsZero() {
+		result.NextRunAt = &s.NextRunAt
+	}
+	if s.LastError != "" {
+		result.LastError = &s.LastError
+	}
+	return result
+}
+
+// convertScheduleRunToGraphQL converts domain schedule Run to GraphQL ScheduleRun
+func convertScheduleRunToGraphQL(run *schedule.Run) *graphql1.ScheduleRun {
+	result := &graphql1.ScheduleRun{
+		ID:           run.ID,
+		ScheduleID:   run.ScheduleID.String(),
+		ScheduledAt:  run.ScheduledAt,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+		Status:       string(run.Status),
+		AgentVersion: run.AgentVersion,
+	}
+	if run.ThreadTS != "" {
+		result.ThreadTs = &run.ThreadTS
+	}
+	if run.Error != "" {
+		result.Error = &run.Error
+	}
+	return result
+}
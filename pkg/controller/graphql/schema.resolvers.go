@@ -12,6 +12,7 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 	goerr "github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/tamamo/pkg/controller/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
 	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
@@ -19,6 +20,35 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
 )
 
+// ID is the resolver for the id field.
+func (r *historyResolver) ID(ctx context.Context, obj *slack.History) (string, error) {
+	return string(obj.ID), nil
+}
+
+// AgentUUID is the resolver for the agentUuid field.
+func (r *historyResolver) AgentUUID(ctx context.Context, obj *slack.History) (*string, error) {
+	if obj.AgentUUID == nil {
+		return nil, nil
+	}
+	agentUUID := obj.AgentUUID.String()
+	return &agentUUID, nil
+}
+
+// ParentID is the resolver for the parentId field.
+func (r *historyResolver) ParentID(ctx context.Context, obj *slack.History) (*string, error) {
+	return optionalHistoryID(obj.ParentID), nil
+}
+
+// CompactedFrom is the resolver for the compactedFrom field.
+func (r *historyResolver) CompactedFrom(ctx context.Context, obj *slack.History) (*string, error) {
+	return optionalHistoryID(obj.CompactedFrom), nil
+}
+
+// RegeneratedFrom is the resolver for the regeneratedFrom field.
+func (r *historyResolver) RegeneratedFrom(ctx context.Context, obj *slack.History) (*string, error) {
+	return optionalHistoryID(obj.RegeneratedFrom), nil
+}
+
 // CreateAgent is the resolver for the createAgent field.
 func (r *mutationResolver) CreateAgent(ctx context.Context, input graphql1.CreateAgentInput) (*graphql1.Agent, error) {
 	// Validate LLM provider and model if factory is available
@@ -152,6 +182,51 @@ func (r *mutationResolver) CreateAgentVersion(ctx context.Context, input graphql
 	return convertAgentVersionToGraphQL(version), nil
 }
 
+// SetMCPServer is the resolver for the setMCPServer field.
+func (r *mutationResolver) SetMCPServer(ctx context.Context, agentUUID string, version string, input graphql1.MCPServerInput) (*graphql1.AgentVersion, error) {
+	uuid := types.UUID(agentUUID)
+	if !uuid.IsValid() {
+		return nil, goerr.New("invalid agent ID")
+	}
+
+	agentVersion, err := r.agentUseCase.SetMCPServer(ctx, uuid, version, convertMCPServerInputToDomain(input))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to set MCP server")
+	}
+
+	return convertAgentVersionToGraphQL(agentVersion), nil
+}
+
+// DeleteMCPServer is the resolver for the deleteMCPServer field.
+func (r *mutationResolver) DeleteMCPServer(ctx context.Context, agentUUID string, version string, name string) (*graphql1.AgentVersion, error) {
+	uuid := types.UUID(agentUUID)
+	if !uuid.IsValid() {
+		return nil, goerr.New("invalid agent ID")
+	}
+
+	agentVersion, err := r.agentUseCase.DeleteMCPServer(ctx, uuid, version, name)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to delete MCP server")
+	}
+
+	return convertAgentVersionToGraphQL(agentVersion), nil
+}
+
+// SetDelegation is the resolver for the setDelegation field.
+func (r *mutationResolver) SetDelegation(ctx context.Context, agentUUID string, version string, input graphql1.DelegationInput) (*graphql1.AgentVersion, error) {
+	uuid := types.UUID(agentUUID)
+	if !uuid.IsValid() {
+		return nil, goerr.New("invalid agent ID")
+	}
+
+	agentVersion, err := r.agentUseCase.SetDelegation(ctx, uuid, version, convertDelegationInputToDomain(input))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to set delegation")
+	}
+
+	return convertAgentVersionToGraphQL(agentVersion), nil
+}
+
 // UploadAgentImage is the resolver for the uploadAgentImage field.
 func (r *mutationResolver) UploadAgentImage(ctx context.Context, agentID string, file graphql.Upload) (*graphql1.Agent, error) {
 	// Validate agent ID
@@ -361,6 +436,360 @@ func (r *mutationResolver) DeleteNotionSearchConfig(ctx context.Context, id stri
 	return true, nil
 }
 
+// UploadKnowledgeDocument is the resolver for the uploadKnowledgeDocument field.
+func (r *mutationResolver) UploadKnowledgeDocument(ctx context.Context, agentUUID string, file graphql.Upload) (*graphql1.KnowledgeDocument, error) {
+	if r.knowledgeUseCases == nil {
+		return nil, goerr.New("knowledge base is not enabled")
+	}
+
+	doc, err := r.knowledgeUseCases.UploadKnowledgeDocument(ctx, &interfaces.UploadKnowledgeDocumentRequest{
+		AgentUUID:   types.UUID(agentUUID),
+		Name:        file.Filename,
+		ContentType: file.ContentType,
+		FileReader:  file.File,
+		FileSize:    file.Size,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to upload knowledge document")
+	}
+	return convertKnowledgeDocumentToGraphQL(doc), nil
+}
+
+// DeleteKnowledgeDocument is the resolver for the deleteKnowledgeDocument field.
+func (r *mutationResolver) DeleteKnowledgeDocument(ctx context.Context, id string) (bool, error) {
+	if r.knowledgeUseCases == nil {
+		return false, goerr.New("knowledge base is not enabled")
+	}
+
+	if err := r.knowledgeUseCases.DeleteKnowledgeDocument(ctx, types.UUID(id)); err != nil {
+		return false, goerr.Wrap(err, "failed to delete knowledge document")
+	}
+	return true, nil
+}
+
+// CreateSchedule is the resolver for the createSchedule field.
+func (r *mutationResolver) CreateSchedule(ctx context.Context, input graphql1.CreateScheduleInput) (*graphql1.Schedule, error) {
+	if r.scheduleUseCases == nil {
+		return nil, goerr.New("scheduled runs are not enabled")
+	}
+
+	req := &interfaces.CreateScheduleRequest{
+		AgentUUID: types.UUID(input.AgentUUID),
+		Cron:      input.Cron,
+		ChannelID: input.ChannelID,
+		Prompt:    input.Prompt,
+		Enabled:   true,
+	}
+	if input.AgentVersion != nil {
+		req.AgentVersion = *input.AgentVersion
+	}
+	if input.TimeZone != nil {
+		req.TimeZone = *input.TimeZone
+	}
+	if input.Enabled != nil {
+		req.Enabled = *input.Enabled
+	}
+
+	s, err := r.scheduleUseCases.CreateSchedule(ctx, req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create schedule")
+	}
+	return convertScheduleToGraphQL(s), nil
+}
+
+// UpdateSchedule is the resolver for the updateSchedule field.
+func (r *mutationResolver) UpdateSchedule(ctx context.Context, id string, input graphql1.UpdateScheduleInput) (*graphql1.Schedule, error) {
+	if r.scheduleUseCases == nil {
+		return nil, goerr.New("scheduled runs are not enabled")
+	}
+
+	s, err := r.scheduleUseCases.UpdateSchedule(ctx, types.UUID(id), &interfaces.UpdateScheduleRequest{
+		AgentVersion: input.AgentVersion,
+		Cron:         input.Cron,
+		TimeZone:     input.TimeZone,
+		ChannelID:    input.ChannelID,
+		Prompt:       input.Prompt,
+		Enabled:      input.Enabled,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to update schedule")
+	}
+	return convertScheduleToGraphQL(s), nil
+}
+
+// DeleteSchedule is the resolver for the deleteSchedule field.
+func (r *mutationResolver) DeleteSchedule(ctx context.Context, id string) (bool, error) {
+	if r.scheduleUseCases == nil {
+		return false, goerr.New("scheduled runs are not enabled")
+	}
+
+	if err := r.scheduleUseCases.DeleteSchedule(ctx, types.UUID(id)); err != nil {
+		return false, goerr.Wrap(err, "failed to delete schedule")
+	}
+	return true, nil
+}
+
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, input graphql1.CreateWebhookInput) (*graphql1.WebhookToken, error) {
+	if r.webhookUseCases == nil {
+		return nil, goerr.New("webhooks are not enabled")
+	}
+
+	req := &interfaces.CreateWebhookRequest{
+		AgentUUID: types.UUID(input.AgentUUID),
+		Name:      input.Name,
+		ChannelID: input.ChannelID,
+		Enabled:   true,
+	}
+	if input.AgentVersion != nil {
+		req.AgentVersion = *input.AgentVersion
+	}
+	if input.PromptTemplate != nil {
+		req.PromptTemplate = *input.PromptTemplate
+	}
+	if input.ThreadTs != nil {
+		req.ThreadTS = *input.ThreadTs
+	}
+	if input.SigningSecret != nil {
+		req.SigningSecret = *input.SigningSecret
+	}
+	if input.Enabled != nil {
+		req.Enabled = *input.Enabled
+	}
+
+	w, token, err := r.webhookUseCases.CreateWebhook(ctx, req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create webhook")
+	}
+	return convertWebhookTokenToGraphQL(w, token), nil
+}
+
+// UpdateWebhook is the resolver for the updateWebhook field.
+func (r *mutationResolver) UpdateWebhook(ctx context.Context, id string, input graphql1.UpdateWebhookInput) (*graphql1.Webhook, error) {
+	if r.webhookUseCases == nil {
+		return nil, goerr.New("webhooks are not enabled")
+	}
+
+	w, err := r.webhookUseCases.UpdateWebhook(ctx, types.UUID(id), &interfaces.UpdateWebhookRequest{
+		AgentVersion:   input.AgentVersion,
+		Name:           input.Name,
+		PromptTemplate: input.PromptTemplate,
+		ChannelID:      input.ChannelID,
+		ThreadTS:       input.ThreadTs,
+		SigningSecret:  input.SigningSecret,
+		Enabled:        input.Enabled,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to update webhook")
+	}
+	return convertWebhookToGraphQL(w), nil
+}
+
+// RotateWebhookToken is the resolver for the rotateWebhookToken field.
+func (r *mutationResolver) RotateWebhookToken(ctx context.Context, id string) (*graphql1.WebhookToken, error) {
+	if r.webhookUseCases == nil {
+		return nil, goerr.New("webhooks are not enabled")
+	}
+
+	w, token, err := r.webhookUseCases.RotateWebhookToken(ctx, types.UUID(id))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to rotate webhook token")
+	}
+	return convertWebhookTokenToGraphQL(w, token), nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	if r.webhookUseCases == nil {
+		return false, goerr.New("webhooks are not enabled")
+	}
+
+	if err := r.webhookUseCases.DeleteWebhook(ctx, types.UUID(id)); err != nil {
+		return false, goerr.Wrap(err, "failed to delete webhook")
+	}
+	return true, nil
+}
+
+// CreateAPIToken is the resolver for the createApiToken field.
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, name string) (*graphql1.APITokenWithSecret, error) {
+	if r.apiTokenUseCases == nil {
+		return nil, goerr.New("API tokens are not enabled")
+	}
+
+	session, err := requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken, token, err := r.apiTokenUseCases.CreateAPIToken(ctx, session, name)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create API token")
+	}
+	return &graphql1.APITokenWithSecret{
+		APIToken: convertAPITokenToGraphQL(apiToken),
+		Token:    token,
+	}, nil
+}
+
+// RevokeAPIToken is the resolver for the revokeApiToken field.
+func (r *mutationResolver) RevokeAPIToken(ctx context.Context, id string) (bool, error) {
+	if r.apiTokenUseCases == nil {
+		return false, goerr.New("API tokens are not enabled")
+	}
+
+	session, err := requireSession(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.apiTokenUseCases.RevokeAPIToken(ctx, session.UserID, types.UUID(id)); err != nil {
+		return false, goerr.Wrap(err, "failed to revoke API token")
+	}
+	return true, nil
+}
+
+// CreateEvalCase is the resolver for the createEvalCase field.
+func (r *mutationResolver) CreateEvalCase(ctx context.Context, input graphql1.EvalCaseInput) (*graphql1.EvalCase, error) {
+	if r.evalUseCases == nil {
+		return nil, goerr.New("agent evaluation is not enabled")
+	}
+
+	c, err := r.evalUseCases.CreateEvalCase(ctx, convertEvalCaseInput(input))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create eval case")
+	}
+	return convertEvalCaseToGraphQL(c), nil
+}
+
+// UpdateEvalCase is the resolver for the updateEvalCase field.
+func (r *mutationResolver) UpdateEvalCase(ctx context.Context, id string, input graphql1.EvalCaseInput) (*graphql1.EvalCase, error) {
+	if r.evalUseCases == nil {
+		return nil, goerr.New("agent evaluation is not enabled")
+	}
+
+	c, err := r.evalUseCases.UpdateEvalCase(ctx, types.UUID(id), convertEvalCaseInput(input))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to update eval case")
+	}
+	return convertEvalCaseToGraphQL(c), nil
+}
+
+// DeleteEvalCase is the resolver for the deleteEvalCase field.
+func (r *mutationResolver) DeleteEvalCase(ctx context.Context, id string) (bool, error) {
+	if r.evalUseCases == nil {
+		return false, goerr.New("agent evaluation is not enabled")
+	}
+
+	if err := r.evalUseCases.DeleteEvalCase(ctx, types.UUID(id)); err != nil {
+		return false, goerr.Wrap(err, "failed to delete eval case")
+	}
+	return true, nil
+}
+
+// RunEval is the resolver for the runEval field.
+func (r *mutationResolver) RunEval(ctx context.Context, agentUUID string, version *string) (*graphql1.EvalRun, error) {
+	if r.evalUseCases == nil {
+		return nil, goerr.New("agent evaluation is not enabled")
+	}
+
+	var v string
+	if version != nil {
+		v = *version
+	}
+
+	// Cases take minutes to run, so the run is returned while running. Clients poll evalRun
+	// for the results.
+	run, err := r.evalUseCases.StartEval(ctx, types.UUID(agentUUID), v)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to start eval")
+	}
+	return convertEvalRunToGraphQL(run), nil
+}
+
+// PublishAgentVersion is the resolver for the publishAgentVersion field.
+func (r *mutationResolver) PublishAgentVersion(ctx context.Context, agentUUID string, version string) (*graphql1.Agent, error) {
+	agentObj, err := r.agentUseCase.PublishAgentVersion(ctx, types.UUID(agentUUID), version)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to publish agent version")
+	}
+	return convertUpdatedAgentToGraphQL(ctx, agentObj, r.agentUseCase, r.userUseCase), nil
+}
+
+// RollbackAgent is the resolver for the rollbackAgent field.
+func (r *mutationResolver) RollbackAgent(ctx context.Context, id string, version string) (*graphql1.Agent, error) {
+	agentObj, err := r.agentUseCase.RollbackAgent(ctx, types.UUID(id), version)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to rollback agent")
+	}
+	return convertUpdatedAgentToGraphQL(ctx, agentObj, r.agentUseCase, r.userUseCase), nil
+}
+
+// StartRollout is the resolver for the startRollout field.
+func (r *mutationResolver) StartRollout(ctx context.Context, agentUUID string, version string, percentage int) (*graphql1.Agent, error) {
+	if r.rolloutUseCases == nil {
+		return nil, goerr.New("gradual rollouts are not enabled")
+	}
+
+	agentObj, err := r.rolloutUseCases.StartRollout(ctx, types.UUID(agentUUID), version, percentage)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to start rollout")
+	}
+	return convertUpdatedAgentToGraphQL(ctx, agentObj, r.agentUseCase, r.userUseCase), nil
+}
+
+// PromoteRollout is the resolver for the promoteRollout field.
+func (r *mutationResolver) PromoteRollout(ctx context.Context, agentUUID string) (*graphql1.Agent, error) {
+	if r.rolloutUseCases == nil {
+		return nil, goerr.New("gradual rollouts are not enabled")
+	}
+
+	agentObj, err := r.rolloutUseCases.PromoteRollout(ctx, types.UUID(agentUUID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to promote rollout")
+	}
+	return convertUpdatedAgentToGraphQL(ctx, agentObj, r.agentUseCase, r.userUseCase), nil
+}
+
+// AbortRollout is the resolver for the abortRollout field.
+func (r *mutationResolver) AbortRollout(ctx context.Context, agentUUID string) (*graphql1.Agent, error) {
+	if r.rolloutUseCases == nil {
+		return nil, goerr.New("gradual rollouts are not enabled")
+	}
+
+	agentObj, err := r.rolloutUseCases.AbortRollout(ctx, types.UUID(agentUUID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to abort rollout")
+	}
+	return convertUpdatedAgentToGraphQL(ctx, agentObj, r.agentUseCase, r.userUseCase), nil
+}
+
+// ImportAgent is the resolver for the importAgent field.
+func (r *mutationResolver) ImportAgent(ctx context.Context, bundle string, onConflict *graphql1.ImportConflict) (*graphql1.ImportAgentResult, error) {
+	if r.bundleUseCases == nil {
+		return nil, goerr.New("agent bundles are not enabled")
+	}
+
+	// The bundle is validated on import with MCP commands allowed by the operator
+	parsed, err := agent.DecodeBundle([]byte(bundle))
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid agent bundle")
+	}
+
+	result, err := r.bundleUseCases.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+		Bundle:     parsed,
+		OnConflict: convertImportConflictToDomain(onConflict),
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to import agent")
+	}
+
+	return &graphql1.ImportAgentResult{
+		Agent:    convertUpdatedAgentToGraphQL(ctx, result.Agent, r.agentUseCase, r.userUseCase),
+		Action:   convertImportActionToGraphQL(result.Action),
+		Versions: result.Versions,
+	}, nil
+}
+
 // Thread is the resolver for the thread field.
 func (r *queryResolver) Thread(ctx context.Context, id string) (*slack.Thread, error) {
 	threadID := types.ThreadID(id)
@@ -572,6 +1001,45 @@ func (r *queryResolver) CheckAgentIDAvailability(ctx context.Context, agentID st
 	}, nil
 }
 
+// RenderSystemPrompt is the resolver for the renderSystemPrompt field.
+func (r *queryResolver) RenderSystemPrompt(ctx context.Context, systemPrompt string, agentUUID *string, promptVariables []*graphql1.KeyValueInput) (string, error) {
+	agentID, name, version := "sample-agent", "Sample Agent", "1.0.0"
+	var vars map[string]string
+	if promptVariables != nil {
+		vars = convertKeyValueInputs(promptVariables)
+	}
+
+	// Agent fields and custom variables default to the ones of the latest version of the agent
+	if agentUUID != nil {
+		uuid := types.UUID(*agentUUID)
+		if !uuid.IsValid() {
+			return "", goerr.New("invalid agent UUID", goerr.V("agent_uuid", *agentUUID))
+		}
+
+		agentWithVersion, err := r.agentUseCase.GetAgent(ctx, uuid)
+		if err != nil {
+			return "", goerr.Wrap(err, "failed to get agent", goerr.V("agent_uuid", *agentUUID))
+		}
+		agentID, name = agentWithVersion.Agent.AgentID, agentWithVersion.Agent.Name
+		if agentWithVersion.LatestVersion != nil {
+			version = agentWithVersion.LatestVersion.Version
+			if vars == nil {
+				vars = agentWithVersion.LatestVersion.PromptVariables
+			}
+		}
+	}
+
+	if err := agent.ValidatePromptVariables(vars); err != nil {
+		return "", goerr.Wrap(err, "invalid prompt variables")
+	}
+
+	prompt, err := agent.RenderSystemPrompt(systemPrompt, agent.SamplePromptContext(agentID, name, version, vars))
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to render system prompt")
+	}
+	return prompt, nil
+}
+
 // AgentImage is the resolver for the agentImage field.
 func (r *queryResolver) AgentImage(ctx context.Context, id string) (*graphql1.AgentImage, error) {
 	imageID := types.UUID(id)
@@ -745,16 +1213,295 @@ func (r *queryResolver) AgentNotionSearchConfigs(ctx context.Context, agentID st
 	return convertNotionSearchConfigsToGraphQL(configs), nil
 }
 
+// KnowledgeDocuments is the resolver for the knowledgeDocuments field.
+func (r *queryResolver) KnowledgeDocuments(ctx context.Context, agentUUID string) ([]*graphql1.KnowledgeDocument, error) {
+	if r.knowledgeUseCases == nil {
+		return []*graphql1.KnowledgeDocument{}, nil
+	}
+
+	docs, err := r.knowledgeUseCases.ListKnowledgeDocuments(ctx, types.UUID(agentUUID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list knowledge documents")
+	}
+
+	result := make([]*graphql1.KnowledgeDocument, 0, len(docs))
+	for _, doc := range docs {
+		result = append(result, convertKnowledgeDocumentToGraphQL(doc))
+	}
+	return result, nil
+}
+
+// Schedules is the resolver for the schedules field.
+func (r *queryResolver) Schedules(ctx context.Context, agentUUID *string) ([]*graphql1.Schedule, error) {
+	if r.scheduleUseCases == nil {
+		return []*graphql1.Schedule{}, nil
+	}
+
+	var uuid *types.UUID
+	if agentUUID != nil {
+		id := types.UUID(*agentUUID)
+		uuid = &id
+	}
+
+	schedules, err := r.scheduleUseCases.ListSchedules(ctx, uuid)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list schedules")
+	}
+
+	result := make([]*graphql1.Schedule, 0, len(schedules))
+	for _, s := range schedules {
+		result = append(result, convertScheduleToGraphQL(s))
+	}
+	return result, nil
+}
+
+// Schedule is the resolver for the schedule field.
+func (r *queryResolver) Schedule(ctx context.Context, id string) (*graphql1.Schedule, error) {
+	if r.scheduleUseCases == nil {
+		return nil, nil
+	}
+
+	s, err := r.scheduleUseCases.GetSchedule(ctx, types.UUID(id))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get schedule")
+	}
+	return convertScheduleToGraphQL(s), nil
+}
+
+// ScheduleRuns is the resolver for the scheduleRuns field.
+func (r *queryResolver) ScheduleRuns(ctx context.Context, scheduleID string, limit *int) ([]*graphql1.ScheduleRun, error) {
+	if r.scheduleUseCases == nil {
+		return []*graphql1.ScheduleRun{}, nil
+	}
+
+	var n int
+	if limit != nil {
+		n = *limit
+	}
+
+	runs, err := r.scheduleUseCases.ListScheduleRuns(ctx, types.UUID(scheduleID), n)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list schedule runs")
+	}
+
+	result := make([]*graphql1.ScheduleRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, convertScheduleRunToGraphQL(run))
+	}
+	return result, nil
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context, agentUUID string) ([]*graphql1.Webhook, error) {
+	if r.webhookUseCases == nil {
+		return []*graphql1.Webhook{}, nil
+	}
+
+	webhooks, err := r.webhookUseCases.ListWebhooks(ctx, types.UUID(agentUUID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list webhooks")
+	}
+
+	result := make([]*graphql1.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		result = append(result, convertWebhookToGraphQL(w))
+	}
+	return result, nil
+}
+
+// APITokens is the resolver for the apiTokens field.
+func (r *queryResolver) APITokens(ctx context.Context) ([]*graphql1.APIToken, error) {
+	if r.apiTokenUseCases == nil {
+		return []*graphql1.APIToken{}, nil
+	}
+
+	session, err := requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := r.apiTokenUseCases.ListAPITokens(ctx, session.UserID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list API tokens")
+	}
+
+	result := make([]*graphql1.APIToken, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, convertAPITokenToGraphQL(t))
+	}
+	return result, nil
+}
+
+// EvalCases is the resolver for the evalCases field.
+func (r *queryResolver) EvalCases(ctx context.Context, agentUUID string) ([]*graphql1.EvalCase, error) {
+	if r.evalUseCases == nil {
+		return []*graphql1.EvalCase{}, nil
+	}
+
+	cases, err := r.evalUseCases.ListEvalCases(ctx, types.UUID(agentUUID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list eval cases")
+	}
+
+	result := make([]*graphql1.EvalCase, 0, len(cases))
+	for _, c := range cases {
+		result = append(result, convertEvalCaseToGraphQL(c))
+	}
+	return result, nil
+}
+
+// EvalRuns is the resolver for the evalRuns field.
+func (r *queryResolver) EvalRuns(ctx context.Context, agentUUID string, limit *int) ([]*graphql1.EvalRun, error) {
+	if r.evalUseCases == nil {
+		return []*graphql1.EvalRun{}, nil
+	}
+
+	var n int
+	if limit != nil {
+		n = *limit
+	}
+
+	runs, err := r.evalUseCases.ListEvalRuns(ctx, types.UUID(agentUUID), n)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list eval runs")
+	}
+
+	result := make([]*graphql1.EvalRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, convertEvalRunToGraphQL(run))
+	}
+	return result, nil
+}
+
+// EvalRun is the resolver for the evalRun field.
+func (r *queryResolver) EvalRun(ctx context.Context, id string) (*graphql1.EvalRun, error) {
+	if r.evalUseCases == nil {
+		return nil, nil
+	}
+
+	run, err := r.evalUseCases.GetEvalRun(ctx, types.UUID(id))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get eval run")
+	}
+	return convertEvalRunToGraphQL(run), nil
+}
+
+// CompareEvalRuns is the resolver for the compareEvalRuns field.
+func (r *queryResolver) CompareEvalRuns(ctx context.Context, baseRunID string, targetRunID string) ([]*graphql1.EvalCaseComparison, error) {
+	if r.evalUseCases == nil {
+		return []*graphql1.EvalCaseComparison{}, nil
+	}
+
+	comparisons, err := r.evalUseCases.CompareEvalRuns(ctx, types.UUID(baseRunID), types.UUID(targetRunID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to compare eval runs")
+	}
+
+	result := make([]*graphql1.EvalCaseComparison, 0, len(comparisons))
+	for _, c := range comparisons {
+		result = append(result, convertEvalCaseComparisonToGraphQL(c))
+	}
+	return result, nil
+}
+
+// AgentVersionMetrics is the resolver for the agentVersionMetrics field.
+func (r *queryResolver) AgentVersionMetrics(ctx context.Context, agentUUID string) ([]*graphql1.AgentVersionMetrics, error) {
+	if r.rolloutUseCases == nil {
+		return []*graphql1.AgentVersionMetrics{}, nil
+	}
+
+	metrics, err := r.rolloutUseCases.ListAgentVersionMetrics(ctx, types.UUID(agentUUID))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list agent version metrics")
+	}
+
+	result := make([]*graphql1.AgentVersionMetrics, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, convertAgentVersionMetricsToGraphQL(m))
+	}
+	return result, nil
+}
+
+// AgentVersionDiff is the resolver for the agentVersionDiff field.
+func (r *queryResolver) AgentVersionDiff(ctx context.Context, agentUUID string, from string, to string) (*graphql1.AgentVersionDiff, error) {
+	if r.rolloutUseCases == nil {
+		return nil, nil
+	}
+
+	diff, err := r.rolloutUseCases.DiffAgentVersions(ctx, types.UUID(agentUUID), from, to)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to diff agent versions")
+	}
+	return convertAgentVersionDiffToGraphQL(agentUUID, diff), nil
+}
+
+// ExportAgent is the resolver for the exportAgent field.
+func (r *queryResolver) ExportAgent(ctx context.Context, agentID string, versions []string) (*string, error) {
+	if r.bundleUseCases == nil {
+		return nil, nil
+	}
+
+	bundle, err := r.bundleUseCases.ExportAgent(ctx, &interfaces.ExportAgentRequest{
+		AgentID:  agentID,
+		Versions: versions,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to export agent")
+	}
+
+	data, err := bundle.Encode()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to encode agent bundle")
+	}
+	result := string(data)
+	return &result, nil
+}
+
 // ID is the resolver for the id field.
 func (r *threadResolver) ID(ctx context.Context, obj *slack.Thread) (string, error) {
 	return string(obj.ID), nil
 }
 
+// AgentUUID is the resolver for the agentUuid field.
+func (r *threadResolver) AgentUUID(ctx context.Context, obj *slack.Thread) (*string, error) {
+	if obj.AgentUUID == nil {
+		return nil, nil
+	}
+	agentUUID := obj.AgentUUID.String()
+	return &agentUUID, nil
+}
+
+// Source is the resolver for the source field.
+func (r *threadResolver) Source(ctx context.Context, obj *slack.Thread) (string, error) {
+	return string(obj.GetSource()), nil
+}
+
+// UserID is the resolver for the userId field.
+func (r *threadResolver) UserID(ctx context.Context, obj *slack.Thread) (*string, error) {
+	if obj.UserID == "" {
+		return nil, nil
+	}
+	userID := obj.UserID.String()
+	return &userID, nil
+}
+
+// Histories is the resolver for the histories field.
+func (r *threadResolver) Histories(ctx context.Context, obj *slack.Thread) ([]*slack.History, error) {
+	histories, err := r.threadRepo.ListHistories(ctx, obj.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list histories", goerr.V("thread_id", obj.ID))
+	}
+	return histories, nil
+}
+
 // ID is the resolver for the id field.
 func (r *userResolver) ID(ctx context.Context, obj *user.User) (string, error) {
 	return obj.ID.String(), nil
 }
 
+// History returns HistoryResolver implementation.
+func (r *Resolver) History() HistoryResolver { return &historyResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
@@ -767,6 +1514,7 @@ func (r *Resolver) Thread() ThreadResolver { return &threadResolver{r} }
 // User returns UserResolver implementation.
 func (r *Resolver) User() UserResolver { return &userResolver{r} }
 
+type historyResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type threadResolver struct{ *Resolver }
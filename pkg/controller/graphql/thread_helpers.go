@@ -0,0 +1,12 @@
+package graphql
+
+import "github.com/m-mizutani/tamamo/pkg/domain/types"
+
+// optionalHistoryID converts a history ID to a nullable GraphQL ID
+func optionalHistoryID(id types.HistoryID) *string {
+	if id == "" {
+		return nil
+	}
+	s := string(id)
+	return &s
+}
@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	graphql1 "github.com/m-mizutani/tamamo/pkg/domain/model/graphql"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+)
+
+// convertWebhookToGraphQL converts domain Webhook to GraphQL Webhook. The signing secret is not
+// exposed.
+func convertWebhookToGraphQL(w *webhook.Webhook) *graphql1.Webhook {
+	result := &graphql1.Webhook{
+		ID:               w.ID.String(),
+		AgentUUID:        w.AgentUUID.String(),
+		Name:             w.Name,
+		PromptTemplate:   w.PromptTemplate,
+		ChannelID:        w.ChannelID,
+		HasSigningSecret: w.SigningSecret != "",
+		Enabled:          w.Enabled,
+		CreatedAt:        w.CreatedAt,
+		UpdatedAt:        w.UpdatedAt,
+	}
+	if w.AgentVersion != "" {
+		result.AgentVersion = &w.AgentVersion
+	}
+	if w.ThreadTS != "" {
+		result.ThreadTs = &w.ThreadTS
+	}
+	return result
+}
+
+// convertWebhookTokenToGraphQL converts the webhook and its token to GraphQL WebhookToken
+func convertWebhookTokenToGraphQL(w *webhook.Webhook, token string) *graphql1.WebhookToken {
+	return &graphql1.WebhookToken{
+		Webhook: convertWebhookToGraphQL(w),
+		Token:   token,
+		Path:    webhook.Path(w.AgentUUID, token),
+	}
+}
@@ -0,0 +1,274 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/ctxlog"
+	auth_controller "github.com/m-mizutani/tamamo/pkg/controller/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+// maxConversationRequestSize limits the size of request bodies of the chat API
+const maxConversationRequestSize = 64 * 1024
+
+// ConversationController handles conversations with agents through the chat API
+type ConversationController struct {
+	conversationUseCase interfaces.ConversationUseCases
+}
+
+// NewConversationController creates a new conversation controller
+func NewConversationController(conversationUseCase interfaces.ConversationUseCases) *ConversationController {
+	return &ConversationController{
+		conversationUseCase: conversationUseCase,
+	}
+}
+
+// ConversationResponse represents a conversation of the chat API
+type ConversationResponse struct {
+	ID           string                 `json:"id"`
+	AgentUUID    string                 `json:"agent_uuid"`
+	AgentVersion string                 `json:"agent_version"`
+	CreatedAt    time.Time              `json:"created_at"`
+	Messages     []*ConversationMessage `json:"messages,omitempty"`
+}
+
+// ConversationMessage represents a message of a conversation
+type ConversationMessage struct {
+	Role      string    `json:"role"` // "user" or "agent"
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createConversationRequest struct {
+	AgentVersion string `json:"agent_version"` // Latest version if empty
+}
+
+type postConversationMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// HandleCreateConversation starts a conversation of the user with the agent
+func (c *ConversationController) HandleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agentUUID, ok := agentUUIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var body createConversationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxConversationRequestSize)).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	session := conversationSession(r)
+	thread, err := c.conversationUseCase.CreateConversation(ctx, &interfaces.CreateConversationRequest{
+		AgentUUID:    agentUUID,
+		AgentVersion: body.AgentVersion,
+		TeamID:       session.TeamID,
+		UserID:       session.UserID,
+	})
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to create conversation",
+			"agent_uuid", agentUUID,
+			"error", err,
+		)
+		handleHTTPError(w, err)
+		return
+	}
+
+	writeConversationJSON(w, http.StatusCreated, toConversationResponse(thread, nil))
+}
+
+// HandleGetConversation returns the conversation with its messages
+func (c *ConversationController) HandleGetConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agentUUID, ok := agentUUIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	session := conversationSession(r)
+	thread, messages, err := c.conversationUseCase.GetConversation(ctx, agentUUID, types.ThreadID(chi.URLParam(r, "conversationID")), session.UserID)
+	if err != nil {
+		handleHTTPError(w, err)
+		return
+	}
+
+	writeConversationJSON(w, http.StatusOK, toConversationResponse(thread, messages))
+}
+
+// HandlePostMessage posts a message to the conversation and streams the reply of the agent as
+// Server-Sent Events: "delta" events with chunks of the text, "tool" events with names of the tools
+// the agent runs, and finally a "done" event with the complete reply or an "error" event. The reply
+// is returned as a JSON object instead if the client accepts only application/json.
+func (c *ConversationController) HandlePostMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := ctxlog.From(ctx)
+
+	agentUUID, ok := agentUUIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var body postConversationMessageRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxConversationRequestSize)).Decode(&body); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	session := conversationSession(r)
+	req := &interfaces.ConversationMessageRequest{
+		AgentUUID:      agentUUID,
+		ConversationID: types.ThreadID(chi.URLParam(r, "conversationID")),
+		UserID:         session.UserID,
+		Message:        body.Message,
+	}
+
+	if r.Header.Get("Accept") == "application/json" {
+		reply, err := c.conversationUseCase.PostConversationMessage(ctx, req, nil)
+		if err != nil {
+			logger.Warn("failed to reply to conversation", "conversation_id", req.ConversationID, "error", err)
+			handleHTTPError(w, err)
+			return
+		}
+		writeConversationJSON(w, http.StatusOK, reply)
+		return
+	}
+
+	stream := newSSEStream(w)
+	reply, err := c.conversationUseCase.PostConversationMessage(ctx, req, stream)
+	if err != nil {
+		logger.Warn("failed to reply to conversation", "conversation_id", req.ConversationID, "error", err)
+		// The status code can be changed only until the first event is sent
+		if !stream.started {
+			handleHTTPError(w, err)
+			return
+		}
+		stream.send("error", &ErrorResponse{Error: "error", Message: err.Error()})
+		return
+	}
+	stream.send("done", reply)
+}
+
+// conversationSession returns the session of the user. The anonymous user is used if
+// authentication is disabled, because routes of the chat API require authentication otherwise.
+func conversationSession(r *http.Request) *auth.Session {
+	if session, ok := auth_controller.UserFromContext(r.Context()); ok && session != nil {
+		return session
+	}
+	return &auth.Session{
+		UserID:   types.AnonymousUserID,
+		UserName: string(types.AnonymousUserID),
+		TeamID:   string(types.AnonymousUserID),
+	}
+}
+
+// agentUUIDParam returns the agent UUID in the path, or writes 400 if it is invalid
+func agentUUIDParam(w http.ResponseWriter, r *http.Request) (types.UUID, bool) {
+	agentUUID := types.UUID(chi.URLParam(r, "agentID"))
+	if !agentUUID.IsValid() {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return "", false
+	}
+	return agentUUID, true
+}
+
+func toConversationResponse(thread *slack.Thread, messages []*slack.Message) *ConversationResponse {
+	resp := &ConversationResponse{
+		ID:           thread.ID.String(),
+		AgentVersion: thread.AgentVersion,
+		CreatedAt:    thread.CreatedAt,
+	}
+	if thread.AgentUUID != nil {
+		resp.AgentUUID = thread.AgentUUID.String()
+	}
+	for _, msg := range messages {
+		role := "user"
+		if msg.BotID != "" {
+			role = "agent"
+		}
+		resp.Messages = append(resp.Messages, &ConversationMessage{
+			Role:      role,
+			Text:      msg.Text,
+			CreatedAt: msg.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func writeConversationJSON(w http.ResponseWriter, statusCode int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		// Response headers are already sent, so the status code can not be changed
+		return
+	}
+}
+
+// sseStream writes the reply of the agent as Server-Sent Events. Headers are sent with the first
+// event, so that errors before it are returned with their status codes.
+type sseStream struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+// Ensure sseStream implements interfaces.ConversationStream
+var _ interfaces.ConversationStream = (*sseStream)(nil)
+
+func newSSEStream(w http.ResponseWriter) *sseStream {
+	return &sseStream{w: w}
+}
+
+// WriteText sends a chunk of the text of the reply
+func (s *sseStream) WriteText(_ context.Context, delta string) {
+	s.send("delta", map[string]string{"text": delta})
+}
+
+// WriteToolCalls sends names of the tools the agent runs
+func (s *sseStream) WriteToolCalls(_ context.Context, names []string) {
+	s.send("tool", map[string][]string{"tools": names})
+}
+
+func (s *sseStream) send(event string, data any) {
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.Header().Set("Connection", "keep-alive")
+		s.w.Header().Set("X-Accel-Buffering", "no") // Disable buffering of reverse proxies
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		raw = []byte(`{}`)
+	}
+	// JSON has no raw newlines, so the data fits in a single data field
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, strings.TrimSpace(string(raw))); err != nil {
+		// The client has gone. Generation stops when the request context is canceled.
+		return
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,136 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gt"
+	auth_controller "github.com/m-mizutani/tamamo/pkg/controller/auth"
+	httpCtrl "github.com/m-mizutani/tamamo/pkg/controller/http"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+// fakeConversationUseCases streams a fixed reply and records the user of requests
+type fakeConversationUseCases struct {
+	userIDs []types.UserID
+}
+
+func (x *fakeConversationUseCases) CreateConversation(ctx context.Context, req *interfaces.CreateConversationRequest) (*slack.Thread, error) {
+	x.userIDs = append(x.userIDs, req.UserID)
+	return slack.NewConversation(ctx, req.TeamID, req.UserID, req.AgentUUID, "1.0.0"), nil
+}
+
+func (x *fakeConversationUseCases) GetConversation(ctx context.Context, agentUUID types.UUID, id types.ThreadID, userID types.UserID) (*slack.Thread, []*slack.Message, error) {
+	return nil, nil, goerr.New("conversation not found", goerr.T(apperr.ErrTagThreadNotFound))
+}
+
+func (x *fakeConversationUseCases) PostConversationMessage(ctx context.Context, req *interfaces.ConversationMessageRequest, stream interfaces.ConversationStream) (*interfaces.ConversationReply, error) {
+	x.userIDs = append(x.userIDs, req.UserID)
+	if req.Message == "" {
+		return nil, goerr.New("message is required", goerr.T(apperr.ErrTagValidation))
+	}
+	if stream != nil {
+		stream.WriteToolCalls(ctx, []string{"slack_search"})
+		stream.WriteText(ctx, "web-1 is ")
+		stream.WriteText(ctx, "healthy.")
+	}
+	return &interfaces.ConversationReply{AgentVersion: "1.0.0", Response: "web-1 is healthy."}, nil
+}
+
+func TestConversationController(t *testing.T) {
+	ctx := context.Background()
+
+	convUC := &fakeConversationUseCases{}
+	apiTokenUC := usecase.NewAPIToken(memory.NewAPITokenRepository())
+	session := auth.NewSession(ctx, types.NewUserID(ctx), "alice", "alice@example.com", "T12345", "Example")
+	_, token, err := apiTokenUC.CreateAPIToken(ctx, session, "ci")
+	gt.NoError(t, err)
+
+	srv := httpCtrl.New(
+		httpCtrl.WithConversationController(httpCtrl.NewConversationController(convUC)),
+		httpCtrl.WithAPITokenUseCase(apiTokenUC),
+		httpCtrl.WithAuthController(auth_controller.NewController(nil, nil, "")),
+	)
+
+	agentUUID := types.NewUUID(ctx)
+	conversationID := types.NewThreadID(ctx)
+	basePath := "/api/agents/" + agentUUID.String() + "/conversations"
+
+	do := func(method, path, body, bearer, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("conversation is created as the owner of the API token", func(t *testing.T) {
+		w := do(http.MethodPost, basePath, "", token, "")
+		gt.Equal(t, w.Code, http.StatusCreated)
+
+		var resp httpCtrl.ConversationResponse
+		gt.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		gt.Equal(t, resp.AgentUUID, agentUUID.String())
+		gt.Equal(t, resp.AgentVersion, "1.0.0")
+		gt.Equal(t, convUC.userIDs[len(convUC.userIDs)-1], session.UserID)
+	})
+
+	t.Run("reply is streamed as events", func(t *testing.T) {
+		w := do(http.MethodPost, basePath+"/"+conversationID.String()+"/messages", `{"message":"Is web-1 healthy?"}`, token, "")
+		gt.Equal(t, w.Code, http.StatusOK)
+		gt.Equal(t, w.Header().Get("Content-Type"), "text/event-stream")
+
+		body := w.Body.String()
+		gt.True(t, strings.Contains(body, "event: tool\ndata: {\"tools\":[\"slack_search\"]}\n\n"))
+		gt.True(t, strings.Contains(body, "event: delta\ndata: {\"text\":\"web-1 is \"}\n\n"))
+		gt.True(t, strings.Contains(body, "event: done\n"))
+		gt.True(t, strings.Index(body, "event: delta") < strings.Index(body, "event: done"))
+	})
+
+	t.Run("reply is returned as JSON", func(t *testing.T) {
+		w := do(http.MethodPost, basePath+"/"+conversationID.String()+"/messages", `{"message":"Is web-1 healthy?"}`, token, "application/json")
+		gt.Equal(t, w.Code, http.StatusOK)
+
+		var reply interfaces.ConversationReply
+		gt.NoError(t, json.NewDecoder(w.Body).Decode(&reply))
+		gt.Equal(t, reply.Response, "web-1 is healthy.")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		// Status codes are returned for errors before the reply is streamed
+		gt.Equal(t, do(http.MethodPost, basePath+"/"+conversationID.String()+"/messages", `{"message":""}`, token, "").Code, http.StatusBadRequest)
+		gt.Equal(t, do(http.MethodGet, basePath+"/"+conversationID.String(), "", token, "").Code, http.StatusNotFound)
+		gt.Equal(t, do(http.MethodPost, "/api/agents/not-a-uuid/conversations", "", token, "").Code, http.StatusBadRequest)
+
+		// Authentication is required
+		gt.Equal(t, do(http.MethodPost, basePath, "", "", "").Code, http.StatusUnauthorized)
+		gt.Equal(t, do(http.MethodPost, basePath, "", "tmm_unknown", "").Code, http.StatusUnauthorized)
+	})
+
+	t.Run("revoked token is rejected", func(t *testing.T) {
+		tokens, err := apiTokenUC.ListAPITokens(ctx, session.UserID)
+		gt.NoError(t, err)
+		gt.A(t, tokens).Length(1)
+		gt.NoError(t, apiTokenUC.RevokeAPIToken(ctx, session.UserID, tokens[0].ID))
+
+		gt.Equal(t, do(http.MethodPost, basePath, "", token, "").Code, http.StatusUnauthorized)
+	})
+}
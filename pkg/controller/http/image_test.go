@@ -62,6 +62,21 @@ func (m *mockAgentUseCase) UnarchiveAgent(ctx context.Context, id types.UUID) (*
 func (m *mockAgentUseCase) CreateAgentVersion(ctx context.Context, req *interfaces.CreateVersionRequest) (*agent.AgentVersion, error) {
 	return nil, nil
 }
+func (m *mockAgentUseCase) SetMCPServer(ctx context.Context, agentUUID types.UUID, version string, server *agent.MCPServer) (*agent.AgentVersion, error) {
+	return nil, nil
+}
+func (m *mockAgentUseCase) DeleteMCPServer(ctx context.Context, agentUUID types.UUID, version string, name string) (*agent.AgentVersion, error) {
+	return nil, nil
+}
+func (m *mockAgentUseCase) SetDelegation(ctx context.Context, agentUUID types.UUID, version string, delegation *agent.Delegation) (*agent.AgentVersion, error) {
+	return nil, nil
+}
+func (m *mockAgentUseCase) PublishAgentVersion(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+	return nil, nil
+}
+func (m *mockAgentUseCase) RollbackAgent(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+	return nil, nil
+}
 func (m *mockAgentUseCase) ListAgents(ctx context.Context, offset, limit int) (*interfaces.AgentListResponse, error) {
 	return nil, nil
 }
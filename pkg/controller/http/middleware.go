@@ -6,12 +6,15 @@ import (
 	"io"
 	"net/http"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/m-mizutani/ctxlog"
 	"github.com/m-mizutani/goerr/v2"
+	auth_controller "github.com/m-mizutani/tamamo/pkg/controller/auth"
 	graphql_controller "github.com/m-mizutani/tamamo/pkg/controller/graphql"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/utils/errors"
 )
@@ -99,6 +102,35 @@ func verifySlackSignature(verifier slack.PayloadVerifier) func(http.Handler) htt
 	}
 }
 
+// apiTokenAuth authenticates requests with API tokens in the Authorization header. Requests without
+// API tokens are authenticated by fallback, e.g. by session cookies, if it is not nil.
+func apiTokenAuth(useCase interfaces.APITokenUseCases, fallback func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		var fallbackHandler http.Handler = next
+		if fallback != nil {
+			fallbackHandler = fallback(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || useCase == nil {
+				fallbackHandler.ServeHTTP(w, r)
+				return
+			}
+
+			session, err := useCase.AuthenticateAPIToken(r.Context(), strings.TrimSpace(token))
+			if err != nil {
+				ctxlog.From(r.Context()).Debug("API token authentication failed", "error", err)
+				handleHTTPError(w, err)
+				return
+			}
+
+			ctx := auth_controller.ContextWithUser(r.Context(), session)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -121,6 +153,13 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Flush implements http.Flusher for streaming responses such as Server-Sent Events
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // graphQLLoggingMiddleware logs GraphQL request details for debugging
 func graphQLLoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -106,13 +106,18 @@ func handleHTTPError(w http.ResponseWriter, err error) {
 type Server struct {
 	router         *chi.Mux
 	slackCtrl      *slack_controller.Controller
+	slackIntCtrl   *slack_controller.InteractionController
+	slackCmdCtrl   *slack_controller.CommandController
 	graphqlCtrl    *graphql_controller.Resolver
 	authCtrl       *auth_controller.Controller
 	userCtrl       *UserController
 	imageCtrl      *ImageController
 	jiraAuthCtrl   *JiraAuthController
 	notionAuthCtrl *NotionAuthController
+	webhookCtrl    *WebhookController
+	convCtrl       *ConversationController
 	authUseCase    interfaces.AuthUseCases
+	apiTokenUC     interfaces.APITokenUseCases
 	enableGraphiQL bool
 	slackVerifier  slack.PayloadVerifier
 	noAuth         bool
@@ -128,6 +133,20 @@ func WithSlackController(ctrl *slack_controller.Controller) Options {
 	}
 }
 
+// WithSlackInteractionController sets the Slack interaction controller
+func WithSlackInteractionController(ctrl *slack_controller.InteractionController) Options {
+	return func(s *Server) {
+		s.slackIntCtrl = ctrl
+	}
+}
+
+// WithSlackCommandController sets the Slack slash command controller
+func WithSlackCommandController(ctrl *slack_controller.CommandController) Options {
+	return func(s *Server) {
+		s.slackCmdCtrl = ctrl
+	}
+}
+
 // WithSlackVerifier sets the Slack payload verifier
 func WithSlackVerifier(verifier slack.PayloadVerifier) Options {
 	return func(s *Server) {
@@ -177,6 +196,27 @@ func WithNotionAuthController(ctrl *NotionAuthController) Options {
 	}
 }
 
+// WithWebhookController sets the webhook controller
+func WithWebhookController(ctrl *WebhookController) Options {
+	return func(s *Server) {
+		s.webhookCtrl = ctrl
+	}
+}
+
+// WithConversationController sets the controller of the chat API
+func WithConversationController(ctrl *ConversationController) Options {
+	return func(s *Server) {
+		s.convCtrl = ctrl
+	}
+}
+
+// WithAPITokenUseCase sets the use case that authenticates API tokens of the chat API
+func WithAPITokenUseCase(useCase interfaces.APITokenUseCases) Options {
+	return func(s *Server) {
+		s.apiTokenUC = useCase
+	}
+}
+
 // WithAuthController sets the authentication controller
 func WithAuthController(ctrl *auth_controller.Controller) Options {
 	return func(s *Server) {
@@ -232,8 +272,14 @@ func New(opts ...Options) *Server {
 				r.Use(verifySlackSignature(s.slackVerifier))
 			}
 			r.Post("/event", slackEventHandler(s.slackCtrl))
-			// Future: r.Post("/interaction", slackInteractionHandler(s.slackCtrl))
+			r.Post("/interaction", slackInteractionHandler(s.slackIntCtrl))
+			r.Post("/command", slackCommandHandler(s.slackCmdCtrl))
 		})
+
+		// Inbound webhooks of agents are authenticated by the token in the path
+		if s.webhookCtrl != nil {
+			r.Post("/agents/{agentID}/{token}", s.webhookCtrl.HandleTriggerWebhook)
+		}
 	})
 
 	// API routes
@@ -296,22 +342,36 @@ func New(opts ...Options) *Server {
 		})
 	}
 
-	// Agent Image API endpoints
-	if s.imageCtrl != nil {
+	// Agent API endpoints
+	if s.imageCtrl != nil || s.convCtrl != nil {
 		r.Route("/api/agents", func(r chi.Router) {
-			// Public endpoints (for image serving)
-			r.Get("/{agentID}/image", s.imageCtrl.HandleGetAgentImage)
-			r.Get("/{agentID}/image/info", s.imageCtrl.HandleGetAgentImageInfo)
+			if s.imageCtrl != nil {
+				// Public endpoints (for image serving)
+				r.Get("/{agentID}/image", s.imageCtrl.HandleGetAgentImage)
+				r.Get("/{agentID}/image/info", s.imageCtrl.HandleGetAgentImageInfo)
+
+				// Protected endpoints (require authentication)
+				if s.authCtrl != nil && !s.noAuth {
+					r.Group(func(r chi.Router) {
+						r.Use(s.authCtrl.RequiredAuth())
+						r.Post("/{agentID}/image", s.imageCtrl.HandleUploadAgentImage)
+					})
+				} else {
+					// If no auth, allow image upload
+					r.Post("/{agentID}/image", s.imageCtrl.HandleUploadAgentImage)
+				}
+			}
 
-			// Protected endpoints (require authentication)
-			if s.authCtrl != nil && !s.noAuth {
+			// Chat API accepts API tokens in addition to sessions
+			if s.convCtrl != nil {
 				r.Group(func(r chi.Router) {
-					r.Use(s.authCtrl.RequiredAuth())
-					r.Post("/{agentID}/image", s.imageCtrl.HandleUploadAgentImage)
+					if s.authCtrl != nil && !s.noAuth {
+						r.Use(apiTokenAuth(s.apiTokenUC, s.authCtrl.RequiredAuth()))
+					}
+					r.Post("/{agentID}/conversations", s.convCtrl.HandleCreateConversation)
+					r.Get("/{agentID}/conversations/{conversationID}", s.convCtrl.HandleGetConversation)
+					r.Post("/{agentID}/conversations/{conversationID}/messages", s.convCtrl.HandlePostMessage)
 				})
-			} else {
-				// If no auth, allow image upload
-				r.Post("/{agentID}/image", s.imageCtrl.HandleUploadAgentImage)
 			}
 		})
 	}
@@ -9,8 +9,10 @@ import (
 	"github.com/m-mizutani/ctxlog"
 	"github.com/m-mizutani/goerr/v2"
 	slack_ctrl "github.com/m-mizutani/tamamo/pkg/controller/slack"
+	slack_model "github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/utils/async"
 	"github.com/m-mizutani/tamamo/pkg/utils/errors"
+	slackapi "github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
@@ -62,11 +64,17 @@ func slackEventHandler(ctrl *slack_ctrl.Controller) http.HandlerFunc {
 
 			switch ev := innerEvent.Data.(type) {
 			case *slackevents.AppMentionEvent:
+				// Files are not included in AppMentionEvent, so they are parsed from the raw payload
+				files, err := slack_model.ParseEventFiles(body)
+				if err != nil {
+					ctxlog.From(r.Context()).Warn("failed to parse files of app mention, ignore them", "error", err)
+				}
+
 				// Process app mention asynchronously
 				eventsCopy := eventsAPIEvent
 				evCopy := *ev
 				async.Dispatch(r.Context(), func(ctx context.Context) error {
-					return ctrl.HandleSlackAppMention(ctx, &eventsCopy, &evCopy)
+					return ctrl.HandleSlackAppMention(ctx, &eventsCopy, &evCopy, files)
 				})
 
 			case *slackevents.MessageEvent:
@@ -90,3 +98,99 @@ func slackEventHandler(ctrl *slack_ctrl.Controller) http.HandlerFunc {
 		}
 	}
 }
+
+func slackInteractionHandler(ctrl *slack_ctrl.InteractionController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Check if controller is nil
+		if ctrl == nil {
+			err := goerr.New("slack interaction controller is nil")
+			errors.Handle(r.Context(), err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		// Interaction payload is sent as form value "payload" in JSON
+		callback, err := slackapi.InteractionCallbackParse(r)
+		if err != nil {
+			err = goerr.Wrap(err, "failed to parse slack interaction payload")
+			errors.Handle(r.Context(), err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		switch callback.Type {
+		case slackapi.InteractionTypeViewSubmission:
+			// View submission must be responded synchronously to close or update the modal
+			resp, err := ctrl.HandleViewSubmission(r.Context(), &callback)
+			if err != nil {
+				errors.Handle(r.Context(), err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if resp == nil {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				errors.Handle(r.Context(), goerr.Wrap(err, "failed to write view submission response"))
+			}
+
+		case slackapi.InteractionTypeBlockActions:
+			// Process actions asynchronously because Slack requires a response within 3 seconds
+			callbackCopy := callback
+			async.Dispatch(r.Context(), func(ctx context.Context) error {
+				return ctrl.HandleBlockActions(ctx, &callbackCopy)
+			})
+			w.WriteHeader(http.StatusOK)
+
+		case slackapi.InteractionTypeShortcut, slackapi.InteractionTypeMessageAction:
+			callbackCopy := callback
+			async.Dispatch(r.Context(), func(ctx context.Context) error {
+				return ctrl.HandleShortcut(ctx, &callbackCopy)
+			})
+			w.WriteHeader(http.StatusOK)
+
+		default:
+			ctxlog.From(r.Context()).Warn("unknown slack interaction type", "type", callback.Type)
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+}
+
+func slackCommandHandler(ctrl *slack_ctrl.CommandController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Check if controller is nil
+		if ctrl == nil {
+			err := goerr.New("slack command controller is nil")
+			errors.Handle(r.Context(), err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		cmd, err := slackapi.SlashCommandParse(r)
+		if err != nil {
+			err = goerr.Wrap(err, "failed to parse slack slash command")
+			errors.Handle(r.Context(), err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		// Slash command must be responded within 3 seconds
+		msg, err := ctrl.HandleSlashCommand(r.Context(), &cmd)
+		if err != nil {
+			errors.Handle(r.Context(), err)
+			// Respond with 200 to show the error to the user instead of Slack's generic failure
+			msg = &slackapi.Msg{
+				ResponseType: slackapi.ResponseTypeEphemeral,
+				Text:         ":x: Failed to process the command. Please try again later.",
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(msg); err != nil {
+			errors.Handle(r.Context(), goerr.Wrap(err, "failed to write slash command response"))
+		}
+	}
+}
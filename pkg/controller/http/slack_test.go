@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/usecase"
 	"github.com/m-mizutani/tamamo/pkg/utils/async"
+	slackapi "github.com/slack-go/slack"
 )
 
 func TestSlackEventHandler(t *testing.T) {
@@ -308,3 +311,184 @@ func TestSlackEventHandler(t *testing.T) {
 		gt.Equal(t, rec.Code, http.StatusOK)
 	})
 }
+
+func TestSlackInteractionHandler(t *testing.T) {
+	signingSecret := "test-signing-secret"
+
+	newRequest := func(t *testing.T, payload map[string]any) *http.Request {
+		t.Helper()
+		raw, err := json.Marshal(payload)
+		gt.NoError(t, err)
+		body := url.Values{"payload": {string(raw)}}.Encode()
+
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		h := hmac.New(sha256.New, []byte(signingSecret))
+		h.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+
+		req := httptest.NewRequest("POST", "/hooks/slack/interaction", strings.NewReader(body))
+		req = req.WithContext(async.WithSyncMode(req.Context()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(h.Sum(nil)))
+		return req
+	}
+
+	var actions []string
+	var shortcuts []string
+	uc := usecase.NewSlackInteraction(
+		usecase.WithBlockActionHandler("approve", func(ctx context.Context, callback *slackapi.InteractionCallback, action *slackapi.BlockAction) error {
+			actions = append(actions, action.ActionID+":"+action.Value)
+			return nil
+		}),
+		usecase.WithViewSubmissionHandler("feedback", func(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error) {
+			return slackapi.NewErrorsViewSubmissionResponse(map[string]string{"comment": "required"}), nil
+		}),
+		usecase.WithShortcutHandler("summarize", func(ctx context.Context, callback *slackapi.InteractionCallback) error {
+			shortcuts = append(shortcuts, string(callback.Type))
+			return nil
+		}),
+	)
+	srv := server.New(
+		server.WithSlackInteractionController(slack_ctrl.NewInteractionController(uc)),
+		server.WithSlackVerifier(slack.NewVerifier(signingSecret)),
+	)
+
+	t.Run("dispatches block actions", func(t *testing.T) {
+		actions = nil
+		req := newRequest(t, map[string]any{
+			"type":    "block_actions",
+			"user":    map[string]any{"id": "U67890USER"},
+			"channel": map[string]any{"id": "C11111"},
+			"actions": []map[string]any{
+				{"action_id": "approve", "block_id": "b1", "type": "button", "value": "yes"},
+				{"action_id": "unknown", "block_id": "b2", "type": "button", "value": "no"},
+			},
+		})
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		gt.Equal(t, rec.Code, http.StatusOK)
+		gt.Equal(t, actions, []string{"approve:yes"})
+	})
+
+	t.Run("responds view submission synchronously", func(t *testing.T) {
+		req := newRequest(t, map[string]any{
+			"type": "view_submission",
+			"user": map[string]any{"id": "U67890USER"},
+			"view": map[string]any{"id": "V1", "callback_id": "feedback"},
+		})
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		gt.Equal(t, rec.Code, http.StatusOK)
+		var resp map[string]any
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		gt.Equal(t, resp["response_action"], "errors")
+	})
+
+	t.Run("closes modal without handler", func(t *testing.T) {
+		req := newRequest(t, map[string]any{
+			"type": "view_submission",
+			"user": map[string]any{"id": "U67890USER"},
+			"view": map[string]any{"id": "V1", "callback_id": "unknown"},
+		})
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		gt.Equal(t, rec.Code, http.StatusOK)
+		gt.Equal(t, rec.Body.Len(), 0)
+	})
+
+	t.Run("dispatches shortcuts", func(t *testing.T) {
+		shortcuts = nil
+		for _, typ := range []string{"shortcut", "message_action"} {
+			req := newRequest(t, map[string]any{
+				"type":        typ,
+				"callback_id": "summarize",
+				"user":        map[string]any{"id": "U67890USER"},
+			})
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			gt.Equal(t, rec.Code, http.StatusOK)
+		}
+		gt.Equal(t, shortcuts, []string{"shortcut", "message_action"})
+	})
+
+	t.Run("rejects invalid payload", func(t *testing.T) {
+		body := url.Values{"payload": {"not json"}}.Encode()
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		h := hmac.New(sha256.New, []byte(signingSecret))
+		h.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+
+		req := httptest.NewRequest("POST", "/hooks/slack/interaction", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(h.Sum(nil)))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		gt.Equal(t, rec.Code, http.StatusBadRequest)
+	})
+
+	t.Run("rejects invalid signature", func(t *testing.T) {
+		req := newRequest(t, map[string]any{"type": "block_actions"})
+		req.Header.Set("X-Slack-Signature", "v0=invalid")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		gt.Equal(t, rec.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestSlackCommandHandler(t *testing.T) {
+	signingSecret := "test-signing-secret"
+
+	uc := usecase.New()
+	srv := server.New(
+		server.WithSlackCommandController(slack_ctrl.NewCommandController(uc)),
+		server.WithSlackVerifier(slack.NewVerifier(signingSecret)),
+	)
+
+	send := func(text string) *httptest.ResponseRecorder {
+		body := url.Values{
+			"command":    {"/tamamo"},
+			"text":       {text},
+			"team_id":    {"T12345"},
+			"channel_id": {"C11111"},
+			"user_id":    {"U67890USER"},
+		}.Encode()
+
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		h := hmac.New(sha256.New, []byte(signingSecret))
+		h.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+
+		req := httptest.NewRequest("POST", "/hooks/slack/command", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(h.Sum(nil)))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("responds ephemeral message", func(t *testing.T) {
+		rec := send("help")
+		gt.Equal(t, rec.Code, http.StatusOK)
+
+		var resp map[string]any
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		gt.Equal(t, resp["response_type"], "ephemeral")
+		gt.S(t, rec.Body.String()).Contains("/tamamo list")
+	})
+
+	t.Run("shows error as ephemeral message", func(t *testing.T) {
+		// Agent repository is not configured
+		rec := send("list")
+		gt.Equal(t, rec.Code, http.StatusOK)
+
+		var resp map[string]any
+		gt.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		gt.Equal(t, resp["response_type"], "ephemeral")
+		gt.S(t, rec.Body.String()).Contains("Failed to process the command")
+	})
+}
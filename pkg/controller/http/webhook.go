@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+// WebhookController handles payloads posted to inbound webhooks of agents
+type WebhookController struct {
+	webhookUseCase interfaces.WebhookUseCases
+}
+
+// NewWebhookController creates a new webhook controller
+func NewWebhookController(webhookUseCase interfaces.WebhookUseCases) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// WebhookResponse represents the response to a payload posted to a webhook
+type WebhookResponse struct {
+	Status       string `json:"status"`
+	AgentVersion string `json:"agent_version,omitempty"`
+	ThreadTS     string `json:"thread_ts,omitempty"`
+	Response     string `json:"response,omitempty"`
+}
+
+// HandleTriggerWebhook runs the agent with the posted JSON payload. The response of the agent is
+// returned if the wait query parameter is true. Otherwise 202 is returned after the payload is
+// verified, and the agent runs in background.
+func (c *WebhookController) HandleTriggerWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agentUUID := types.UUID(chi.URLParam(r, "agentID"))
+	if !agentUUID.IsValid() {
+		http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+
+	wait := false
+	if v := r.URL.Query().Get("wait"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid wait parameter", http.StatusBadRequest)
+			return
+		}
+		wait = parsed
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhook.MaxPayloadSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.webhookUseCase.TriggerWebhook(ctx, &interfaces.TriggerWebhookRequest{
+		AgentUUID: agentUUID,
+		Token:     chi.URLParam(r, "token"),
+		Body:      body,
+		Timestamp: r.Header.Get(webhook.TimestampHeader),
+		Signature: r.Header.Get(webhook.SignatureHeader),
+		Wait:      wait,
+	})
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to trigger webhook",
+			"agent_uuid", agentUUID,
+			"error", err,
+		)
+		handleHTTPError(w, err)
+		return
+	}
+
+	statusCode := http.StatusAccepted
+	response := &WebhookResponse{Status: "accepted"}
+	if result != nil {
+		statusCode = http.StatusOK
+		response = &WebhookResponse{
+			Status:       "completed",
+			AgentVersion: result.AgentVersion,
+			ThreadTS:     result.ThreadTS,
+			Response:     result.Response,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		// Response headers are already sent, so the status code can not be changed
+		return
+	}
+}
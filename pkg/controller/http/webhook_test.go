@@ -0,0 +1,115 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	httpCtrl "github.com/m-mizutani/tamamo/pkg/controller/http"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/m-mizutani/tamamo/pkg/utils/async"
+)
+
+// fakeAgentRunner records prompts to run agents
+type fakeAgentRunner struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (x *fakeAgentRunner) RunAgent(ctx context.Context, req *interfaces.AgentRunRequest) (*interfaces.AgentRunResult, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.prompts = append(x.prompts, req.Prompt)
+	return &interfaces.AgentRunResult{
+		AgentVersion: "1.0.0",
+		ThreadTS:     "1700000000.000100",
+		Response:     "Restart web-1",
+	}, nil
+}
+
+func TestWebhookController(t *testing.T) {
+	ctx := context.Background()
+
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := &agent.Agent{
+		ID:      types.NewUUID(ctx),
+		AgentID: "sre-helper",
+		Name:    "SRE Helper",
+		Status:  agent.StatusActive,
+		Latest:  "1.0.0",
+	}
+	gt.NoError(t, agentRepo.CreateAgent(ctx, agentObj))
+
+	runner := &fakeAgentRunner{}
+	webhookUC := usecase.NewWebhook(
+		usecase.WithWebhookRepository(memory.NewWebhookRepository()),
+		usecase.WithWebhookAgentRepository(agentRepo),
+		usecase.WithWebhookRunner(runner),
+	)
+	_, token, err := webhookUC.CreateWebhook(ctx, &interfaces.CreateWebhookRequest{
+		AgentUUID:      agentObj.ID,
+		Name:           "Datadog alerts",
+		PromptTemplate: "Investigate {{.Payload.title}}",
+		ChannelID:      "C12345",
+		SigningSecret:  "s3cret",
+		Enabled:        true,
+	})
+	gt.NoError(t, err)
+
+	srv := httpCtrl.New(httpCtrl.WithWebhookController(httpCtrl.NewWebhookController(webhookUC)))
+	body := []byte(`{"title":"CPU high"}`)
+
+	post := func(path string, body []byte, signed bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req = req.WithContext(async.WithSyncMode(req.Context()))
+		req.Header.Set("Content-Type", "application/json")
+		if signed {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req.Header.Set(webhook.TimestampHeader, timestamp)
+			req.Header.Set(webhook.SignatureHeader, webhook.Sign("s3cret", timestamp, body))
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("accepted and run in background", func(t *testing.T) {
+		w := post(webhook.Path(agentObj.ID, token), body, true)
+		gt.Equal(t, w.Code, http.StatusAccepted)
+		gt.Equal(t, runner.prompts[len(runner.prompts)-1], "Investigate CPU high")
+	})
+
+	t.Run("response is returned with wait", func(t *testing.T) {
+		w := post(webhook.Path(agentObj.ID, token)+"?wait=true", body, true)
+		gt.Equal(t, w.Code, http.StatusOK)
+
+		var resp httpCtrl.WebhookResponse
+		gt.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		gt.Equal(t, resp.Status, "completed")
+		gt.Equal(t, resp.Response, "Restart web-1")
+		gt.Equal(t, resp.ThreadTS, "1700000000.000100")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		gt.Equal(t, post(webhook.Path(agentObj.ID, "unknown"), body, true).Code, http.StatusNotFound)
+		gt.Equal(t, post(webhook.Path(agentObj.ID, token), body, false).Code, http.StatusUnauthorized)
+		gt.Equal(t, post(webhook.Path(agentObj.ID, token)+"?wait=maybe", body, true).Code, http.StatusBadRequest)
+		gt.Equal(t, post("/hooks/agents/not-a-uuid/"+token, body, true).Code, http.StatusBadRequest)
+
+		large := []byte(`{"title":"` + strings.Repeat("a", webhook.MaxPayloadSize) + `"}`)
+		gt.Equal(t, post(webhook.Path(agentObj.ID, token), large, true).Code, http.StatusRequestEntityTooLarge)
+	})
+}
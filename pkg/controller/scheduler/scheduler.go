@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	pkgErrors "github.com/m-mizutani/tamamo/pkg/utils/errors"
+)
+
+// DefaultInterval is the interval to check schedules whose next tick has come
+const DefaultInterval = 30 * time.Second
+
+// Scheduler runs schedules of agents periodically
+type Scheduler struct {
+	schedules interfaces.ScheduleUseCases
+	interval  time.Duration
+}
+
+// Option is a functional option for Scheduler
+type Option func(*Scheduler)
+
+// WithInterval sets the interval to check schedules
+func WithInterval(interval time.Duration) Option {
+	return func(x *Scheduler) {
+		x.interval = interval
+	}
+}
+
+// New creates a new Scheduler
+func New(schedules interfaces.ScheduleUseCases, opts ...Option) *Scheduler {
+	x := &Scheduler{
+		schedules: schedules,
+		interval:  DefaultInterval,
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x
+}
+
+// Run checks schedules at every interval until ctx is canceled. Each check runs in the
+// background, so that a long run does not delay ticks of other schedules. Checks overlapping
+// each other do not run the same tick twice because the tick is locked by the use case.
+func (x *Scheduler) Run(ctx context.Context) {
+	ctxlog.From(ctx).Info("scheduler started", "interval", x.interval)
+
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctxlog.From(ctx).Info("scheduler stopped")
+			return
+
+		case <-ticker.C:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := x.schedules.RunDueSchedules(ctx); err != nil {
+					pkgErrors.Handle(ctx, err)
+				}
+			}()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/controller/scheduler"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+)
+
+type fakeScheduleUseCases struct {
+	interfaces.ScheduleUseCases
+	calls atomic.Int32
+}
+
+func (x *fakeScheduleUseCases) RunDueSchedules(ctx context.Context) error {
+	x.calls.Add(1)
+	return nil
+}
+
+func TestSchedulerRun(t *testing.T) {
+	uc := &fakeScheduleUseCases{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.New(uc, scheduler.WithInterval(10*time.Millisecond)).Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after the context was canceled")
+	}
+	gt.True(t, uc.calls.Load() >= 2)
+}
@@ -0,0 +1,32 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	slackapi "github.com/slack-go/slack"
+)
+
+// CommandController handles Slack slash commands
+type CommandController struct {
+	command interfaces.SlackCommandUseCases
+}
+
+// NewCommandController creates a new Slack slash command controller
+func NewCommandController(command interfaces.SlackCommandUseCases) *CommandController {
+	return &CommandController{
+		command: command,
+	}
+}
+
+// HandleSlashCommand handles a slash command and returns the response message
+func (x *CommandController) HandleSlashCommand(ctx context.Context, cmd *slackapi.SlashCommand) (*slackapi.Msg, error) {
+	ctxlog.From(ctx).Debug("handling slack slash command",
+		"command", cmd.Command,
+		"user", cmd.UserID,
+		"channel", cmd.ChannelID,
+	)
+
+	return x.command.HandleSlashCommand(ctx, cmd)
+}
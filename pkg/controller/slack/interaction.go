@@ -0,0 +1,53 @@
+package slack
+
+import (
+	"context"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	slackapi "github.com/slack-go/slack"
+)
+
+// InteractionController handles Slack interactivity payloads
+type InteractionController struct {
+	interaction interfaces.SlackInteractionUseCases
+}
+
+// NewInteractionController creates a new Slack interaction controller
+func NewInteractionController(interaction interfaces.SlackInteractionUseCases) *InteractionController {
+	return &InteractionController{
+		interaction: interaction,
+	}
+}
+
+// HandleBlockActions handles block_actions payloads from buttons, menus and other Block Kit elements
+func (x *InteractionController) HandleBlockActions(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	ctxlog.From(ctx).Debug("handling slack block actions",
+		"user", callback.User.ID,
+		"channel", callback.Channel.ID,
+		"actions", len(callback.ActionCallback.BlockActions),
+	)
+
+	return x.interaction.HandleBlockActions(ctx, callback)
+}
+
+// HandleViewSubmission handles view_submission payloads from modals
+func (x *InteractionController) HandleViewSubmission(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error) {
+	ctxlog.From(ctx).Debug("handling slack view submission",
+		"user", callback.User.ID,
+		"callback_id", callback.View.CallbackID,
+	)
+
+	return x.interaction.HandleViewSubmission(ctx, callback)
+}
+
+// HandleShortcut handles global and message shortcut payloads
+func (x *InteractionController) HandleShortcut(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	ctxlog.From(ctx).Debug("handling slack shortcut",
+		"user", callback.User.ID,
+		"type", callback.Type,
+		"callback_id", callback.CallbackID,
+	)
+
+	return x.interaction.HandleShortcut(ctx, callback)
+}
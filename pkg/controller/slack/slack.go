@@ -85,8 +85,8 @@ func (x *Controller) enrichMessageWithUserInfo(ctx context.Context, slackMsg *sl
 	return nil
 }
 
-// HandleSlackAppMention handles Slack app mention events
-func (x *Controller) HandleSlackAppMention(ctx context.Context, apiEvent *slackevents.EventsAPIEvent, event *slackevents.AppMentionEvent) error {
+// HandleSlackAppMention handles Slack app mention events. files are attached to the mentioned message.
+func (x *Controller) HandleSlackAppMention(ctx context.Context, apiEvent *slackevents.EventsAPIEvent, event *slackevents.AppMentionEvent, files []slack_model.File) error {
 	ctxlog.From(ctx).Debug("handling slack app mention",
 		"event_ts", event.EventTimeStamp,
 		"channel", event.Channel,
@@ -96,6 +96,7 @@ func (x *Controller) HandleSlackAppMention(ctx context.Context, apiEvent *slacke
 	if slackMsg == nil {
 		return nil
 	}
+	slackMsg.Files = files
 
 	// Fetch user display name if needed
 	if err := x.enrichMessageWithUserInfo(ctx, slackMsg); err != nil {
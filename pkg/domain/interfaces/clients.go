@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"io"
 
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 )
@@ -12,6 +13,7 @@ type SlackUserProfile struct {
 	Name        string `json:"name"`
 	DisplayName string `json:"display_name"`
 	Email       string `json:"email"`
+	TimeZone    string `json:"tz"` // IANA time zone, e.g. Asia/Tokyo
 	Profile     struct {
 		Image24   string `json:"image_24"`
 		Image32   string `json:"image_32"`
@@ -47,12 +49,20 @@ type SlackMessageOptions struct {
 type SlackClient interface {
 	PostMessage(ctx context.Context, channelID, threadTS, text string) error
 	PostMessageWithOptions(ctx context.Context, channelID, threadTS, text string, options *SlackMessageOptions) error
+	// PostMessageForUpdate posts a message and returns its timestamp to update or delete it later
+	PostMessageForUpdate(ctx context.Context, channelID, threadTS, text string, options *SlackMessageOptions) (string, error)
+	UpdateMessage(ctx context.Context, channelID, timestamp, text string) error
+	DeleteMessage(ctx context.Context, channelID, timestamp string) error
 	IsBotUser(userID string) bool
 	GetUserProfile(ctx context.Context, userID string) (*SlackUserProfile, error)
 	GetUserInfo(ctx context.Context, userID string) (*SlackUserInfo, error)
 	GetBotInfo(ctx context.Context, botID string) (*SlackBotInfo, error)
 	GetChannelInfo(ctx context.Context, channelID string) (*slack.ChannelInfo, error)
 	IsWorkspaceMember(ctx context.Context, email string) (bool, error)
+	// DownloadFile downloads a file attached to a message from its private download URL
+	DownloadFile(ctx context.Context, downloadURL string, w io.Writer) error
+	// GetThreadReplies retrieves up to limit messages of the thread including the parent message, oldest first
+	GetThreadReplies(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error)
 }
 
 // UserAvatarService manages user avatar data retrieval
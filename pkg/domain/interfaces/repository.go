@@ -6,10 +6,14 @@ import (
 
 	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/image"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/integration"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/user"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
 )
 
@@ -21,6 +25,9 @@ type ThreadRepository interface {
 	GetOrPutThread(ctx context.Context, teamID, channelID, threadTS string) (*slack.Thread, error)
 	GetOrPutThreadWithAgent(ctx context.Context, teamID, channelID, threadTS string, agentUUID *types.UUID, agentVersion string) (*slack.Thread, error)
 	ListThreads(ctx context.Context, offset, limit int) ([]*slack.Thread, int, error)
+	// PutThread saves the thread as is, e.g. a conversation started through the chat API
+	PutThread(ctx context.Context, thread *slack.Thread) error
+	UpdateThreadAgent(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) error
 
 	// Message operations
 	PutThreadMessage(ctx context.Context, threadID types.ThreadID, message *slack.Message) error
@@ -29,6 +36,7 @@ type ThreadRepository interface {
 	// History operations
 	PutHistory(ctx context.Context, history *slack.History) error
 	GetLatestHistory(ctx context.Context, threadID types.ThreadID) (*slack.History, error)
+	ListHistories(ctx context.Context, threadID types.ThreadID) ([]*slack.History, error)
 	GetHistoryByID(ctx context.Context, id types.HistoryID) (*slack.History, error)
 }
 
@@ -83,6 +91,16 @@ type SessionRepository interface {
 	CleanupExpiredSessions(ctx context.Context) error
 }
 
+// APITokenRepository manages API tokens of users
+type APITokenRepository interface {
+	PutAPIToken(ctx context.Context, token *auth.APIToken) error
+	// GetAPITokenByHash returns the API token that has the hash. It returns
+	// auth.ErrAPITokenNotFound if no API token has it.
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*auth.APIToken, error)
+	ListAPITokensByUser(ctx context.Context, userID types.UserID) ([]*auth.APIToken, error)
+	DeleteAPIToken(ctx context.Context, id types.UUID) error
+}
+
 // OAuthStateRepository manages OAuth state for CSRF protection
 type OAuthStateRepository interface {
 	SaveState(ctx context.Context, state *auth.OAuthState) error
@@ -128,3 +146,79 @@ type SlackMessageLogRepository interface {
 	// GetSlackMessageLogs retrieves message logs with filtering (primarily for channel and time period)
 	GetSlackMessageLogs(ctx context.Context, channel string, from *time.Time, to *time.Time, limit int, offset int) ([]*slack.SlackMessageLog, error)
 }
+
+// KnowledgeRepository manages metadata of knowledge documents of agents
+type KnowledgeRepository interface {
+	PutKnowledgeDocument(ctx context.Context, doc *knowledge.Document) error
+	GetKnowledgeDocument(ctx context.Context, id types.UUID) (*knowledge.Document, error)
+	GetKnowledgeDocumentByName(ctx context.Context, agentUUID types.UUID, name string) (*knowledge.Document, error)
+	ListKnowledgeDocuments(ctx context.Context, agentUUID types.UUID) ([]*knowledge.Document, error)
+	ListAllKnowledgeDocuments(ctx context.Context) ([]*knowledge.Document, error)
+	DeleteKnowledgeDocument(ctx context.Context, id types.UUID) error
+}
+
+// VectorIndex stores embeddings of knowledge chunks and searches them by similarity
+type VectorIndex interface {
+	// PutChunks replaces all chunks of the document with the given chunks
+	PutChunks(ctx context.Context, documentID types.UUID, chunks []*knowledge.Chunk) error
+
+	// DeleteChunks deletes all chunks of the document
+	DeleteChunks(ctx context.Context, documentID types.UUID) error
+
+	// CountChunks returns the number of chunks of the document in the index
+	CountChunks(ctx context.Context, documentID types.UUID) (int, error)
+
+	// Search returns chunks of the agent most similar to the vector, in descending order of score
+	Search(ctx context.Context, agentUUID types.UUID, vector []float64, limit int) ([]*knowledge.SearchResult, error)
+}
+
+// ScheduleRepository manages schedules of agent runs and their execution history
+type ScheduleRepository interface {
+	PutSchedule(ctx context.Context, s *schedule.Schedule) error
+	GetSchedule(ctx context.Context, id types.UUID) (*schedule.Schedule, error)
+	ListSchedules(ctx context.Context) ([]*schedule.Schedule, error)
+	DeleteSchedule(ctx context.Context, id types.UUID) error
+
+	// UpdateScheduleState updates only the result of the last run and the next tick, so that
+	// changes of the schedule made during the run are kept
+	UpdateScheduleState(ctx context.Context, id types.UUID, lastRunAt time.Time, lastError string, nextRunAt time.Time) error
+
+	// CreateScheduleRun creates the run record. It returns schedule.ErrRunAlreadyExists if the
+	// run of the same tick exists, and works as a lock so that only one instance runs a tick.
+	CreateScheduleRun(ctx context.Context, run *schedule.Run) error
+	GetScheduleRun(ctx context.Context, scheduleID types.UUID, id string) (*schedule.Run, error)
+	PutScheduleRun(ctx context.Context, run *schedule.Run) error
+	// ListScheduleRuns returns the latest runs of the schedule, newest first
+	ListScheduleRuns(ctx context.Context, scheduleID types.UUID, limit int) ([]*schedule.Run, error)
+}
+
+// WebhookRepository manages inbound webhooks of agents
+type WebhookRepository interface {
+	PutWebhook(ctx context.Context, w *webhook.Webhook) error
+	GetWebhook(ctx context.Context, id types.UUID) (*webhook.Webhook, error)
+	// GetWebhookByTokenHash returns the webhook that has the token. It returns
+	// webhook.ErrWebhookNotFound if no webhook has it.
+	GetWebhookByTokenHash(ctx context.Context, tokenHash string) (*webhook.Webhook, error)
+	ListWebhooksByAgent(ctx context.Context, agentUUID types.UUID) ([]*webhook.Webhook, error)
+	DeleteWebhook(ctx context.Context, id types.UUID) error
+}
+
+// EvalRepository manages golden test cases of agents and results of their eval runs
+type EvalRepository interface {
+	PutEvalCase(ctx context.Context, c *eval.Case) error
+	GetEvalCase(ctx context.Context, id types.UUID) (*eval.Case, error)
+	ListEvalCases(ctx context.Context, agentUUID types.UUID) ([]*eval.Case, error)
+	DeleteEvalCase(ctx context.Context, id types.UUID) error
+
+	PutEvalRun(ctx context.Context, run *eval.Run) error
+	GetEvalRun(ctx context.Context, id types.UUID) (*eval.Run, error)
+	// ListEvalRuns returns the latest runs of the agent, newest first
+	ListEvalRuns(ctx context.Context, agentUUID types.UUID, limit int) ([]*eval.Run, error)
+}
+
+// AgentMetricsRepository manages counters of agent versions
+type AgentMetricsRepository interface {
+	// AddAgentVersionMetrics adds the counters of delta to the metrics of the version atomically
+	AddAgentVersionMetrics(ctx context.Context, delta *agent.VersionMetrics) error
+	ListAgentVersionMetrics(ctx context.Context, agentUUID types.UUID) ([]*agent.VersionMetrics, error)
+}
@@ -6,10 +6,15 @@ import (
 
 	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/image"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/user"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	slackapi "github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
@@ -20,6 +25,18 @@ type SlackEventUseCases interface {
 	LogSlackMessage(ctx context.Context, event *slackevents.MessageEvent, teamID string) error
 }
 
+// SlackCommandUseCases handles Slack slash commands
+type SlackCommandUseCases interface {
+	HandleSlashCommand(ctx context.Context, cmd *slackapi.SlashCommand) (*slackapi.Msg, error)
+}
+
+// SlackInteractionUseCases handles interactivity payloads such as buttons, menus, modals and shortcuts
+type SlackInteractionUseCases interface {
+	HandleBlockActions(ctx context.Context, callback *slackapi.InteractionCallback) error
+	HandleViewSubmission(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error)
+	HandleShortcut(ctx context.Context, callback *slackapi.InteractionCallback) error
+}
+
 // Agent use case request/response types
 type CreateAgentRequest struct {
 	AgentID      string            `json:"agent_id"`
@@ -41,11 +58,17 @@ type UpdateAgentRequest struct {
 }
 
 type CreateVersionRequest struct {
-	AgentUUID    types.UUID        `json:"agent_uuid"`
-	Version      string            `json:"version"`
-	SystemPrompt *string           `json:"system_prompt,omitempty"`
-	LLMProvider  types.LLMProvider `json:"llm_provider"`
-	LLMModel     string            `json:"llm_model"`
+	AgentUUID       types.UUID         `json:"agent_uuid"`
+	Version         string             `json:"version"`
+	SystemPrompt    *string            `json:"system_prompt,omitempty"`
+	PromptTemplate  bool               `json:"prompt_template,omitempty"` // Render the system prompt as a template
+	PromptVariables map[string]string  `json:"prompt_variables,omitempty"`
+	LLMProvider     types.LLMProvider  `json:"llm_provider"`
+	LLMModel        string             `json:"llm_model"`
+	MCPServers      []*agent.MCPServer `json:"mcp_servers,omitempty"`
+	Delegation      *agent.Delegation  `json:"delegation,omitempty"`
+	Draft           bool               `json:"draft,omitempty"` // Create as a draft without making it the latest version
+	Changelog       string             `json:"changelog,omitempty"`
 }
 
 type AgentWithVersion struct {
@@ -81,6 +104,19 @@ type AgentUseCases interface {
 	CreateAgentVersion(ctx context.Context, req *CreateVersionRequest) (*agent.AgentVersion, error)
 	GetAgentVersions(ctx context.Context, agentUUID types.UUID) ([]*agent.AgentVersion, error)
 
+	// MCP server management of a version
+	SetMCPServer(ctx context.Context, agentUUID types.UUID, version string, server *agent.MCPServer) (*agent.AgentVersion, error)
+	DeleteMCPServer(ctx context.Context, agentUUID types.UUID, version string, name string) (*agent.AgentVersion, error)
+
+	// Delegation management of a version
+	SetDelegation(ctx context.Context, agentUUID types.UUID, version string, delegation *agent.Delegation) (*agent.AgentVersion, error)
+
+	// Release management. PublishAgentVersion publishes the draft version and makes it the latest
+	// version, and RollbackAgent points the latest version back to the published version without
+	// copying it.
+	PublishAgentVersion(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error)
+	RollbackAgent(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error)
+
 	// Validation (independent execution)
 	CheckAgentIDAvailability(ctx context.Context, agentID string) (*AgentIDAvailability, error)
 	ValidateAgentID(agentID string) error
@@ -198,3 +234,277 @@ type NotionSearchConfigUseCases interface {
 	// Get enabled Notion search configurations for an agent
 	GetEnabledNotionSearchConfigs(ctx context.Context, agentID string) ([]*agent.NotionSearchConfig, error)
 }
+
+// UploadKnowledgeDocumentRequest represents a request to upload a document to the knowledge base of an agent
+type UploadKnowledgeDocumentRequest struct {
+	AgentUUID   types.UUID `json:"agent_uuid"`
+	Name        string     `json:"name"`
+	ContentType string     `json:"content_type"`
+	FileReader  io.Reader  `json:"-"`
+	FileSize    int64      `json:"file_size"`
+}
+
+// KnowledgeUseCases handles knowledge bases of agents
+type KnowledgeUseCases interface {
+	// Upload a document. A document with the same name of the agent is replaced and re-embedded.
+	UploadKnowledgeDocument(ctx context.Context, req *UploadKnowledgeDocumentRequest) (*knowledge.Document, error)
+
+	// List documents of an agent
+	ListKnowledgeDocuments(ctx context.Context, agentUUID types.UUID) ([]*knowledge.Document, error)
+
+	// Delete a document and its chunks
+	DeleteKnowledgeDocument(ctx context.Context, id types.UUID) error
+
+	// Search chunks of documents of an agent similar to the query
+	SearchKnowledge(ctx context.Context, agentUUID types.UUID, query string, limit int) ([]*knowledge.SearchResult, error)
+}
+
+// AgentRunRequest represents a request to run an agent without a mention. The response is posted
+// to the channel as a new thread bound to the agent, or to the thread if ThreadTS is set.
+type AgentRunRequest struct {
+	AgentUUID    types.UUID `json:"agent_uuid"`
+	AgentVersion string     `json:"agent_version"` // Latest version if empty
+	ChannelID    string     `json:"channel_id"`
+	ThreadTS     string     `json:"thread_ts"` // New thread if empty
+	Prompt       string     `json:"prompt"`
+}
+
+// AgentRunResult is the result of an agent run
+type AgentRunResult struct {
+	AgentVersion string `json:"agent_version"`
+	ThreadTS     string `json:"thread_ts"`
+	Response     string `json:"response"`
+}
+
+// AgentRunUseCases runs agents outside of Slack events
+type AgentRunUseCases interface {
+	RunAgent(ctx context.Context, req *AgentRunRequest) (*AgentRunResult, error)
+}
+
+// AgentAnswerUseCases answers prompts with agents without posting to Slack, e.g. to evaluate them.
+// ChannelID and ThreadTS of the request are ignored.
+type AgentAnswerUseCases interface {
+	AnswerWithAgent(ctx context.Context, req *AgentRunRequest) (*AgentRunResult, error)
+}
+
+// CreateScheduleRequest represents a request to create a schedule of an agent
+type CreateScheduleRequest struct {
+	AgentUUID    types.UUID `json:"agent_uuid"`
+	AgentVersion string     `json:"agent_version"` // Latest version if empty
+	Cron         string     `json:"cron"`
+	TimeZone     string     `json:"time_zone"` // UTC if empty
+	ChannelID    string     `json:"channel_id"`
+	Prompt       string     `json:"prompt"`
+	Enabled      bool       `json:"enabled"`
+}
+
+// UpdateScheduleRequest represents a request to update a schedule. Nil fields are not changed.
+type UpdateScheduleRequest struct {
+	AgentVersion *string `json:"agent_version,omitempty"`
+	Cron         *string `json:"cron,omitempty"`
+	TimeZone     *string `json:"time_zone,omitempty"`
+	ChannelID    *string `json:"channel_id,omitempty"`
+	Prompt       *string `json:"prompt,omitempty"`
+	Enabled      *bool   `json:"enabled,omitempty"`
+}
+
+// ScheduleUseCases handles scheduled runs of agents
+type ScheduleUseCases interface {
+	CreateSchedule(ctx context.Context, req *CreateScheduleRequest) (*schedule.Schedule, error)
+	GetSchedule(ctx context.Context, id types.UUID) (*schedule.Schedule, error)
+	UpdateSchedule(ctx context.Context, id types.UUID, req *UpdateScheduleRequest) (*schedule.Schedule, error)
+	DeleteSchedule(ctx context.Context, id types.UUID) error
+
+	// List schedules. All schedules are listed if agentUUID is nil.
+	ListSchedules(ctx context.Context, agentUUID *types.UUID) ([]*schedule.Schedule, error)
+
+	// List the latest runs of a schedule, newest first
+	ListScheduleRuns(ctx context.Context, scheduleID types.UUID, limit int) ([]*schedule.Run, error)
+
+	// Run schedules whose next tick has come
+	RunDueSchedules(ctx context.Context) error
+}
+
+// CreateWebhookRequest represents a request to create an inbound webhook of an agent
+type CreateWebhookRequest struct {
+	AgentUUID      types.UUID `json:"agent_uuid"`
+	AgentVersion   string     `json:"agent_version"` // Latest version if empty
+	Name           string     `json:"name"`
+	PromptTemplate string     `json:"prompt_template"` // Default template if empty
+	ChannelID      string     `json:"channel_id"`
+	ThreadTS       string     `json:"thread_ts"`      // New thread for each payload if empty
+	SigningSecret  string     `json:"signing_secret"` // Signatures are not verified if empty
+	Enabled        bool       `json:"enabled"`
+}
+
+// UpdateWebhookRequest represents a request to update a webhook. Nil fields are not changed.
+type UpdateWebhookRequest struct {
+	AgentVersion   *string `json:"agent_version,omitempty"`
+	Name           *string `json:"name,omitempty"`
+	PromptTemplate *string `json:"prompt_template,omitempty"`
+	ChannelID      *string `json:"channel_id,omitempty"`
+	ThreadTS       *string `json:"thread_ts,omitempty"`
+	SigningSecret  *string `json:"signing_secret,omitempty"`
+	Enabled        *bool   `json:"enabled,omitempty"`
+}
+
+// TriggerWebhookRequest represents a payload posted to a webhook
+type TriggerWebhookRequest struct {
+	AgentUUID types.UUID
+	Token     string
+	Body      []byte
+	Timestamp string
+	Signature string
+	// Wait makes the trigger return the response of the agent. Otherwise the agent runs in
+	// background after the payload is accepted.
+	Wait bool
+}
+
+// WebhookUseCases handles inbound webhooks that run agents
+type WebhookUseCases interface {
+	// CreateWebhook creates a webhook and returns it with its token. The token can not be
+	// retrieved later.
+	CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*webhook.Webhook, string, error)
+	GetWebhook(ctx context.Context, id types.UUID) (*webhook.Webhook, error)
+	ListWebhooks(ctx context.Context, agentUUID types.UUID) ([]*webhook.Webhook, error)
+	UpdateWebhook(ctx context.Context, id types.UUID, req *UpdateWebhookRequest) (*webhook.Webhook, error)
+	DeleteWebhook(ctx context.Context, id types.UUID) error
+
+	// RotateWebhookToken issues a new token of the webhook. The old token is revoked.
+	RotateWebhookToken(ctx context.Context, id types.UUID) (*webhook.Webhook, string, error)
+
+	// TriggerWebhook verifies the payload and runs the agent with it. The result is nil unless
+	// req.Wait is set.
+	TriggerWebhook(ctx context.Context, req *TriggerWebhookRequest) (*AgentRunResult, error)
+}
+
+// CreateConversationRequest represents a request to start a conversation with an agent through
+// the chat API
+type CreateConversationRequest struct {
+	AgentUUID    types.UUID   `json:"agent_uuid"`
+	AgentVersion string       `json:"agent_version"` // Latest version if empty
+	TeamID       string       `json:"team_id"`
+	UserID       types.UserID `json:"user_id"`
+}
+
+// ConversationMessageRequest represents a message posted to a conversation by its user
+type ConversationMessageRequest struct {
+	AgentUUID      types.UUID     `json:"agent_uuid"`
+	ConversationID types.ThreadID `json:"conversation_id"`
+	UserID         types.UserID   `json:"user_id"`
+	Message        string         `json:"message"`
+}
+
+// ConversationReply is the reply of the agent to a message of a conversation
+type ConversationReply struct {
+	AgentVersion string          `json:"agent_version"`
+	HistoryID    types.HistoryID `json:"history_id,omitempty"`
+	Response     string          `json:"response"`
+}
+
+// ConversationStream receives the reply of the agent while it is generated
+type ConversationStream interface {
+	// WriteText receives a chunk of the text of the reply
+	WriteText(ctx context.Context, delta string)
+	// WriteToolCalls receives names of the tools the agent runs before it continues the reply
+	WriteToolCalls(ctx context.Context, names []string)
+}
+
+// ConversationUseCases handles conversations with agents outside of Slack. Conversations are
+// stored as threads, so they share the history with Slack threads.
+type ConversationUseCases interface {
+	CreateConversation(ctx context.Context, req *CreateConversationRequest) (*slack.Thread, error)
+	// GetConversation returns the conversation of the agent started by the user, with its messages
+	GetConversation(ctx context.Context, agentUUID types.UUID, id types.ThreadID, userID types.UserID) (*slack.Thread, []*slack.Message, error)
+	// PostConversationMessage generates the reply of the agent to the message. The reply is
+	// passed to stream while it is generated if stream is not nil.
+	PostConversationMessage(ctx context.Context, req *ConversationMessageRequest, stream ConversationStream) (*ConversationReply, error)
+}
+
+// APITokenUseCases manages API tokens that authenticate users of the chat API
+type APITokenUseCases interface {
+	// CreateAPIToken creates an API token of the user of the session and returns it with the
+	// token. The token can not be retrieved later.
+	CreateAPIToken(ctx context.Context, session *auth.Session, name string) (*auth.APIToken, string, error)
+	ListAPITokens(ctx context.Context, userID types.UserID) ([]*auth.APIToken, error)
+	RevokeAPIToken(ctx context.Context, userID types.UserID, id types.UUID) error
+
+	// AuthenticateAPIToken returns the session of the user of the token
+	AuthenticateAPIToken(ctx context.Context, token string) (*auth.Session, error)
+}
+
+// EvalCaseRequest represents a request to create or replace a test case of an agent
+type EvalCaseRequest struct {
+	AgentUUID        types.UUID `json:"agent_uuid"`
+	Name             string     `json:"name"`
+	Input            string     `json:"input"`
+	ExpectedFacts    []string   `json:"expected_facts"`
+	ExpectedPatterns []string   `json:"expected_patterns"`
+	Rubric           string     `json:"rubric"`
+}
+
+// EvalUseCases handles golden test cases of agents and their eval runs
+type EvalUseCases interface {
+	CreateEvalCase(ctx context.Context, req *EvalCaseRequest) (*eval.Case, error)
+	// UpdateEvalCase replaces the case. The agent of the case is not changed.
+	UpdateEvalCase(ctx context.Context, id types.UUID, req *EvalCaseRequest) (*eval.Case, error)
+	DeleteEvalCase(ctx context.Context, id types.UUID) error
+	ListEvalCases(ctx context.Context, agentUUID types.UUID) ([]*eval.Case, error)
+
+	// Run the cases of the agent against the version of the agent, and store the results. The
+	// latest version is used if version is empty.
+	RunEval(ctx context.Context, agentUUID types.UUID, version string) (*eval.Run, error)
+	// StartEval is RunEval in background. It returns the running run, and the results are
+	// stored to the run.
+	StartEval(ctx context.Context, agentUUID types.UUID, version string) (*eval.Run, error)
+	GetEvalRun(ctx context.Context, id types.UUID) (*eval.Run, error)
+	// List the latest runs of the agent, newest first
+	ListEvalRuns(ctx context.Context, agentUUID types.UUID, limit int) ([]*eval.Run, error)
+
+	// Compare results of cases in two runs of the same agent side by side
+	CompareEvalRuns(ctx context.Context, baseRunID, targetRunID types.UUID) ([]*eval.CaseComparison, error)
+}
+
+// AgentRolloutUseCases handles gradual rollouts of published candidate versions, metrics of versions
+// and diffs between versions
+type AgentRolloutUseCases interface {
+	// StartRollout routes the percentage of new threads to the published version. The percentage of
+	// a running rollout is changed if the version is the same, and the rollout is replaced otherwise.
+	StartRollout(ctx context.Context, agentUUID types.UUID, version string, percentage int) (*agent.Agent, error)
+	// PromoteRollout makes the candidate version the latest version and ends the rollout
+	PromoteRollout(ctx context.Context, agentUUID types.UUID) (*agent.Agent, error)
+	// AbortRollout ends the rollout. Threads started with the candidate version keep it.
+	AbortRollout(ctx context.Context, agentUUID types.UUID) (*agent.Agent, error)
+
+	ListAgentVersionMetrics(ctx context.Context, agentUUID types.UUID) ([]*agent.VersionMetrics, error)
+
+	// DiffAgentVersions compares two versions of the agent for reviews before releasing the to version
+	DiffAgentVersions(ctx context.Context, agentUUID types.UUID, from, to string) (*agent.VersionDiff, error)
+}
+
+// ExportAgentRequest selects what to export of the agent
+type ExportAgentRequest struct {
+	AgentID string `json:"agent_id"`
+	// Versions to export. All versions are exported if empty.
+	Versions []string `json:"versions,omitempty"`
+	// IncludeSecrets exports values of env and headers of MCP servers
+	IncludeSecrets bool `json:"include_secrets,omitempty"`
+}
+
+type ImportAgentRequest struct {
+	Bundle     *agent.Bundle            `json:"bundle"`
+	OnConflict agent.ImportConflictMode `json:"on_conflict"` // Default is skip
+}
+
+type ImportAgentResult struct {
+	Agent  *agent.Agent       `json:"agent"`
+	Action agent.ImportAction `json:"action"`
+	// Versions created or updated by the import
+	Versions []string `json:"versions"`
+}
+
+// AgentBundleUseCases exports and imports agents as YAML bundles to move them between workspaces
+type AgentBundleUseCases interface {
+	ExportAgent(ctx context.Context, req *ExportAgentRequest) (*agent.Bundle, error)
+	ImportAgent(ctx context.Context, req *ImportAgentRequest) (*ImportAgentResult, error)
+}
@@ -36,6 +36,9 @@ var _ interfaces.AgentUseCases = &AgentUseCasesMock{}
 //			DeleteAgentFunc: func(ctx context.Context, id types.UUID) error {
 //				panic("mock out the DeleteAgent method")
 //			},
+//			DeleteMCPServerFunc: func(ctx context.Context, agentUUID types.UUID, version string, name string) (*agent.AgentVersion, error) {
+//				panic("mock out the DeleteMCPServer method")
+//			},
 //			GetAgentFunc: func(ctx context.Context, id types.UUID) (*interfaces.AgentWithVersion, error) {
 //				panic("mock out the GetAgent method")
 //			},
@@ -51,6 +54,18 @@ var _ interfaces.AgentUseCases = &AgentUseCasesMock{}
 //			ListAllAgentsFunc: func(ctx context.Context, offset int, limit int) (*interfaces.AgentListResponse, error) {
 //				panic("mock out the ListAllAgents method")
 //			},
+//			PublishAgentVersionFunc: func(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+//				panic("mock out the PublishAgentVersion method")
+//			},
+//			RollbackAgentFunc: func(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+//				panic("mock out the RollbackAgent method")
+//			},
+//			SetDelegationFunc: func(ctx context.Context, agentUUID types.UUID, version string, delegation *agent.Delegation) (*agent.AgentVersion, error) {
+//				panic("mock out the SetDelegation method")
+//			},
+//			SetMCPServerFunc: func(ctx context.Context, agentUUID types.UUID, version string, server *agent.MCPServer) (*agent.AgentVersion, error) {
+//				panic("mock out the SetMCPServer method")
+//			},
 //			UnarchiveAgentFunc: func(ctx context.Context, id types.UUID) (*interfaces.AgentWithVersion, error) {
 //				panic("mock out the UnarchiveAgent method")
 //			},
@@ -85,6 +100,9 @@ type AgentUseCasesMock struct {
 	// DeleteAgentFunc mocks the DeleteAgent method.
 	DeleteAgentFunc func(ctx context.Context, id types.UUID) error
 
+	// DeleteMCPServerFunc mocks the DeleteMCPServer method.
+	DeleteMCPServerFunc func(ctx context.Context, agentUUID types.UUID, version string, name string) (*agent.AgentVersion, error)
+
 	// GetAgentFunc mocks the GetAgent method.
 	GetAgentFunc func(ctx context.Context, id types.UUID) (*interfaces.AgentWithVersion, error)
 
@@ -100,6 +118,18 @@ type AgentUseCasesMock struct {
 	// ListAllAgentsFunc mocks the ListAllAgents method.
 	ListAllAgentsFunc func(ctx context.Context, offset int, limit int) (*interfaces.AgentListResponse, error)
 
+	// PublishAgentVersionFunc mocks the PublishAgentVersion method.
+	PublishAgentVersionFunc func(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error)
+
+	// RollbackAgentFunc mocks the RollbackAgent method.
+	RollbackAgentFunc func(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error)
+
+	// SetDelegationFunc mocks the SetDelegation method.
+	SetDelegationFunc func(ctx context.Context, agentUUID types.UUID, version string, delegation *agent.Delegation) (*agent.AgentVersion, error)
+
+	// SetMCPServerFunc mocks the SetMCPServer method.
+	SetMCPServerFunc func(ctx context.Context, agentUUID types.UUID, version string, server *agent.MCPServer) (*agent.AgentVersion, error)
+
 	// UnarchiveAgentFunc mocks the UnarchiveAgent method.
 	UnarchiveAgentFunc func(ctx context.Context, id types.UUID) (*interfaces.AgentWithVersion, error)
 
@@ -149,6 +179,17 @@ type AgentUseCasesMock struct {
 			// ID is the id argument value.
 			ID types.UUID
 		}
+		// DeleteMCPServer holds details about calls to the DeleteMCPServer method.
+		DeleteMCPServer []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AgentUUID is the agentUUID argument value.
+			AgentUUID types.UUID
+			// Version is the version argument value.
+			Version string
+			// Name is the name argument value.
+			Name string
+		}
 		// GetAgent holds details about calls to the GetAgent method.
 		GetAgent []struct {
 			// Ctx is the ctx argument value.
@@ -192,6 +233,46 @@ type AgentUseCasesMock struct {
 			// Limit is the limit argument value.
 			Limit int
 		}
+		// PublishAgentVersion holds details about calls to the PublishAgentVersion method.
+		PublishAgentVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AgentUUID is the agentUUID argument value.
+			AgentUUID types.UUID
+			// Version is the version argument value.
+			Version string
+		}
+		// RollbackAgent holds details about calls to the RollbackAgent method.
+		RollbackAgent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AgentUUID is the agentUUID argument value.
+			AgentUUID types.UUID
+			// Version is the version argument value.
+			Version string
+		}
+		// SetDelegation holds details about calls to the SetDelegation method.
+		SetDelegation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AgentUUID is the agentUUID argument value.
+			AgentUUID types.UUID
+			// Version is the version argument value.
+			Version string
+			// Delegation is the delegation argument value.
+			Delegation *agent.Delegation
+		}
+		// SetMCPServer holds details about calls to the SetMCPServer method.
+		SetMCPServer []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AgentUUID is the agentUUID argument value.
+			AgentUUID types.UUID
+			// Version is the version argument value.
+			Version string
+			// Server is the server argument value.
+			Server *agent.MCPServer
+		}
 		// UnarchiveAgent holds details about calls to the UnarchiveAgent method.
 		UnarchiveAgent []struct {
 			// Ctx is the ctx argument value.
@@ -224,11 +305,16 @@ type AgentUseCasesMock struct {
 	lockCreateAgent              sync.RWMutex
 	lockCreateAgentVersion       sync.RWMutex
 	lockDeleteAgent              sync.RWMutex
+	lockDeleteMCPServer          sync.RWMutex
 	lockGetAgent                 sync.RWMutex
 	lockGetAgentVersions         sync.RWMutex
 	lockListAgents               sync.RWMutex
 	lockListAgentsByStatus       sync.RWMutex
 	lockListAllAgents            sync.RWMutex
+	lockPublishAgentVersion      sync.RWMutex
+	lockRollbackAgent            sync.RWMutex
+	lockSetDelegation            sync.RWMutex
+	lockSetMCPServer             sync.RWMutex
 	lockUnarchiveAgent           sync.RWMutex
 	lockUpdateAgent              sync.RWMutex
 	lockValidateAgentID          sync.RWMutex
@@ -415,6 +501,50 @@ func (mock *AgentUseCasesMock) DeleteAgentCalls() []struct {
 	return calls
 }
 
+// DeleteMCPServer calls DeleteMCPServerFunc.
+func (mock *AgentUseCasesMock) DeleteMCPServer(ctx context.Context, agentUUID types.UUID, version string, name string) (*agent.AgentVersion, error) {
+	if mock.DeleteMCPServerFunc == nil {
+		panic("AgentUseCasesMock.DeleteMCPServerFunc: method is nil but AgentUseCases.DeleteMCPServer was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+		Name      string
+	}{
+		Ctx:       ctx,
+		AgentUUID: agentUUID,
+		Version:   version,
+		Name:      name,
+	}
+	mock.lockDeleteMCPServer.Lock()
+	mock.calls.DeleteMCPServer = append(mock.calls.DeleteMCPServer, callInfo)
+	mock.lockDeleteMCPServer.Unlock()
+	return mock.DeleteMCPServerFunc(ctx, agentUUID, version, name)
+}
+
+// DeleteMCPServerCalls gets all the calls that were made to DeleteMCPServer.
+// Check the length with:
+//
+//	len(mockedAgentUseCases.DeleteMCPServerCalls())
+func (mock *AgentUseCasesMock) DeleteMCPServerCalls() []struct {
+	Ctx       context.Context
+	AgentUUID types.UUID
+	Version   string
+	Name      string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+		Name      string
+	}
+	mock.lockDeleteMCPServer.RLock()
+	calls = mock.calls.DeleteMCPServer
+	mock.lockDeleteMCPServer.RUnlock()
+	return calls
+}
+
 // GetAgent calls GetAgentFunc.
 func (mock *AgentUseCasesMock) GetAgent(ctx context.Context, id types.UUID) (*interfaces.AgentWithVersion, error) {
 	if mock.GetAgentFunc == nil {
@@ -611,6 +741,174 @@ func (mock *AgentUseCasesMock) ListAllAgentsCalls() []struct {
 	return calls
 }
 
+// PublishAgentVersion calls PublishAgentVersionFunc.
+func (mock *AgentUseCasesMock) PublishAgentVersion(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+	if mock.PublishAgentVersionFunc == nil {
+		panic("AgentUseCasesMock.PublishAgentVersionFunc: method is nil but AgentUseCases.PublishAgentVersion was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+	}{
+		Ctx:       ctx,
+		AgentUUID: agentUUID,
+		Version:   version,
+	}
+	mock.lockPublishAgentVersion.Lock()
+	mock.calls.PublishAgentVersion = append(mock.calls.PublishAgentVersion, callInfo)
+	mock.lockPublishAgentVersion.Unlock()
+	return mock.PublishAgentVersionFunc(ctx, agentUUID, version)
+}
+
+// PublishAgentVersionCalls gets all the calls that were made to PublishAgentVersion.
+// Check the length with:
+//
+//	len(mockedAgentUseCases.PublishAgentVersionCalls())
+func (mock *AgentUseCasesMock) PublishAgentVersionCalls() []struct {
+	Ctx       context.Context
+	AgentUUID types.UUID
+	Version   string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+	}
+	mock.lockPublishAgentVersion.RLock()
+	calls = mock.calls.PublishAgentVersion
+	mock.lockPublishAgentVersion.RUnlock()
+	return calls
+}
+
+// RollbackAgent calls RollbackAgentFunc.
+func (mock *AgentUseCasesMock) RollbackAgent(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+	if mock.RollbackAgentFunc == nil {
+		panic("AgentUseCasesMock.RollbackAgentFunc: method is nil but AgentUseCases.RollbackAgent was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+	}{
+		Ctx:       ctx,
+		AgentUUID: agentUUID,
+		Version:   version,
+	}
+	mock.lockRollbackAgent.Lock()
+	mock.calls.RollbackAgent = append(mock.calls.RollbackAgent, callInfo)
+	mock.lockRollbackAgent.Unlock()
+	return mock.RollbackAgentFunc(ctx, agentUUID, version)
+}
+
+// RollbackAgentCalls gets all the calls that were made to RollbackAgent.
+// Check the length with:
+//
+//	len(mockedAgentUseCases.RollbackAgentCalls())
+func (mock *AgentUseCasesMock) RollbackAgentCalls() []struct {
+	Ctx       context.Context
+	AgentUUID types.UUID
+	Version   string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+	}
+	mock.lockRollbackAgent.RLock()
+	calls = mock.calls.RollbackAgent
+	mock.lockRollbackAgent.RUnlock()
+	return calls
+}
+
+// SetDelegation calls SetDelegationFunc.
+func (mock *AgentUseCasesMock) SetDelegation(ctx context.Context, agentUUID types.UUID, version string, delegation *agent.Delegation) (*agent.AgentVersion, error) {
+	if mock.SetDelegationFunc == nil {
+		panic("AgentUseCasesMock.SetDelegationFunc: method is nil but AgentUseCases.SetDelegation was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		AgentUUID  types.UUID
+		Version    string
+		Delegation *agent.Delegation
+	}{
+		Ctx:        ctx,
+		AgentUUID:  agentUUID,
+		Version:    version,
+		Delegation: delegation,
+	}
+	mock.lockSetDelegation.Lock()
+	mock.calls.SetDelegation = append(mock.calls.SetDelegation, callInfo)
+	mock.lockSetDelegation.Unlock()
+	return mock.SetDelegationFunc(ctx, agentUUID, version, delegation)
+}
+
+// SetDelegationCalls gets all the calls that were made to SetDelegation.
+// Check the length with:
+//
+//	len(mockedAgentUseCases.SetDelegationCalls())
+func (mock *AgentUseCasesMock) SetDelegationCalls() []struct {
+	Ctx        context.Context
+	AgentUUID  types.UUID
+	Version    string
+	Delegation *agent.Delegation
+} {
+	var calls []struct {
+		Ctx        context.Context
+		AgentUUID  types.UUID
+		Version    string
+		Delegation *agent.Delegation
+	}
+	mock.lockSetDelegation.RLock()
+	calls = mock.calls.SetDelegation
+	mock.lockSetDelegation.RUnlock()
+	return calls
+}
+
+// SetMCPServer calls SetMCPServerFunc.
+func (mock *AgentUseCasesMock) SetMCPServer(ctx context.Context, agentUUID types.UUID, version string, server *agent.MCPServer) (*agent.AgentVersion, error) {
+	if mock.SetMCPServerFunc == nil {
+		panic("AgentUseCasesMock.SetMCPServerFunc: method is nil but AgentUseCases.SetMCPServer was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+		Server    *agent.MCPServer
+	}{
+		Ctx:       ctx,
+		AgentUUID: agentUUID,
+		Version:   version,
+		Server:    server,
+	}
+	mock.lockSetMCPServer.Lock()
+	mock.calls.SetMCPServer = append(mock.calls.SetMCPServer, callInfo)
+	mock.lockSetMCPServer.Unlock()
+	return mock.SetMCPServerFunc(ctx, agentUUID, version, server)
+}
+
+// SetMCPServerCalls gets all the calls that were made to SetMCPServer.
+// Check the length with:
+//
+//	len(mockedAgentUseCases.SetMCPServerCalls())
+func (mock *AgentUseCasesMock) SetMCPServerCalls() []struct {
+	Ctx       context.Context
+	AgentUUID types.UUID
+	Version   string
+	Server    *agent.MCPServer
+} {
+	var calls []struct {
+		Ctx       context.Context
+		AgentUUID types.UUID
+		Version   string
+		Server    *agent.MCPServer
+	}
+	mock.lockSetMCPServer.RLock()
+	calls = mock.calls.SetMCPServer
+	mock.lockSetMCPServer.RUnlock()
+	return calls
+}
+
 // UnarchiveAgent calls UnarchiveAgentFunc.
 func (mock *AgentUseCasesMock) UnarchiveAgent(ctx context.Context, id types.UUID) (*interfaces.AgentWithVersion, error) {
 	if mock.UnarchiveAgentFunc == nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"io"
 	"sync"
 )
 
@@ -21,12 +22,21 @@ var _ interfaces.SlackClient = &SlackClientMock{}
 //
 //		// make and configure a mocked interfaces.SlackClient
 //		mockedSlackClient := &SlackClientMock{
+//			DeleteMessageFunc: func(ctx context.Context, channelID string, timestamp string) error {
+//				panic("mock out the DeleteMessage method")
+//			},
+//			DownloadFileFunc: func(ctx context.Context, downloadURL string, w io.Writer) error {
+//				panic("mock out the DownloadFile method")
+//			},
 //			GetBotInfoFunc: func(ctx context.Context, botID string) (*interfaces.SlackBotInfo, error) {
 //				panic("mock out the GetBotInfo method")
 //			},
 //			GetChannelInfoFunc: func(ctx context.Context, channelID string) (*slack.ChannelInfo, error) {
 //				panic("mock out the GetChannelInfo method")
 //			},
+//			GetThreadRepliesFunc: func(ctx context.Context, channelID string, threadTS string, limit int) ([]*slack.Message, error) {
+//				panic("mock out the GetThreadReplies method")
+//			},
 //			GetUserInfoFunc: func(ctx context.Context, userID string) (*interfaces.SlackUserInfo, error) {
 //				panic("mock out the GetUserInfo method")
 //			},
@@ -42,9 +52,15 @@ var _ interfaces.SlackClient = &SlackClientMock{}
 //			PostMessageFunc: func(ctx context.Context, channelID string, threadTS string, text string) error {
 //				panic("mock out the PostMessage method")
 //			},
+//			PostMessageForUpdateFunc: func(ctx context.Context, channelID string, threadTS string, text string, options *interfaces.SlackMessageOptions) (string, error) {
+//				panic("mock out the PostMessageForUpdate method")
+//			},
 //			PostMessageWithOptionsFunc: func(ctx context.Context, channelID string, threadTS string, text string, options *interfaces.SlackMessageOptions) error {
 //				panic("mock out the PostMessageWithOptions method")
 //			},
+//			UpdateMessageFunc: func(ctx context.Context, channelID string, timestamp string, text string) error {
+//				panic("mock out the UpdateMessage method")
+//			},
 //		}
 //
 //		// use mockedSlackClient in code that requires interfaces.SlackClient
@@ -52,12 +68,21 @@ var _ interfaces.SlackClient = &SlackClientMock{}
 //
 //	}
 type SlackClientMock struct {
+	// DeleteMessageFunc mocks the DeleteMessage method.
+	DeleteMessageFunc func(ctx context.Context, channelID string, timestamp string) error
+
+	// DownloadFileFunc mocks the DownloadFile method.
+	DownloadFileFunc func(ctx context.Context, downloadURL string, w io.Writer) error
+
 	// GetBotInfoFunc mocks the GetBotInfo method.
 	GetBotInfoFunc func(ctx context.Context, botID string) (*interfaces.SlackBotInfo, error)
 
 	// GetChannelInfoFunc mocks the GetChannelInfo method.
 	GetChannelInfoFunc func(ctx context.Context, channelID string) (*slack.ChannelInfo, error)
 
+	// GetThreadRepliesFunc mocks the GetThreadReplies method.
+	GetThreadRepliesFunc func(ctx context.Context, channelID string, threadTS string, limit int) ([]*slack.Message, error)
+
 	// GetUserInfoFunc mocks the GetUserInfo method.
 	GetUserInfoFunc func(ctx context.Context, userID string) (*interfaces.SlackUserInfo, error)
 
@@ -73,11 +98,35 @@ type SlackClientMock struct {
 	// PostMessageFunc mocks the PostMessage method.
 	PostMessageFunc func(ctx context.Context, channelID string, threadTS string, text string) error
 
+	// PostMessageForUpdateFunc mocks the PostMessageForUpdate method.
+	PostMessageForUpdateFunc func(ctx context.Context, channelID string, threadTS string, text string, options *interfaces.SlackMessageOptions) (string, error)
+
 	// PostMessageWithOptionsFunc mocks the PostMessageWithOptions method.
 	PostMessageWithOptionsFunc func(ctx context.Context, channelID string, threadTS string, text string, options *interfaces.SlackMessageOptions) error
 
+	// UpdateMessageFunc mocks the UpdateMessage method.
+	UpdateMessageFunc func(ctx context.Context, channelID string, timestamp string, text string) error
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// DeleteMessage holds details about calls to the DeleteMessage method.
+		DeleteMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ChannelID is the channelID argument value.
+			ChannelID string
+			// Timestamp is the timestamp argument value.
+			Timestamp string
+		}
+		// DownloadFile holds details about calls to the DownloadFile method.
+		DownloadFile []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// DownloadURL is the downloadURL argument value.
+			DownloadURL string
+			// W is the w argument value.
+			W io.Writer
+		}
 		// GetBotInfo holds details about calls to the GetBotInfo method.
 		GetBotInfo []struct {
 			// Ctx is the ctx argument value.
@@ -92,6 +141,17 @@ type SlackClientMock struct {
 			// ChannelID is the channelID argument value.
 			ChannelID string
 		}
+		// GetThreadReplies holds details about calls to the GetThreadReplies method.
+		GetThreadReplies []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ChannelID is the channelID argument value.
+			ChannelID string
+			// ThreadTS is the threadTS argument value.
+			ThreadTS string
+			// Limit is the limit argument value.
+			Limit int
+		}
 		// GetUserInfo holds details about calls to the GetUserInfo method.
 		GetUserInfo []struct {
 			// Ctx is the ctx argument value.
@@ -129,6 +189,19 @@ type SlackClientMock struct {
 			// Text is the text argument value.
 			Text string
 		}
+		// PostMessageForUpdate holds details about calls to the PostMessageForUpdate method.
+		PostMessageForUpdate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ChannelID is the channelID argument value.
+			ChannelID string
+			// ThreadTS is the threadTS argument value.
+			ThreadTS string
+			// Text is the text argument value.
+			Text string
+			// Options is the options argument value.
+			Options *interfaces.SlackMessageOptions
+		}
 		// PostMessageWithOptions holds details about calls to the PostMessageWithOptions method.
 		PostMessageWithOptions []struct {
 			// Ctx is the ctx argument value.
@@ -142,15 +215,111 @@ type SlackClientMock struct {
 			// Options is the options argument value.
 			Options *interfaces.SlackMessageOptions
 		}
+		// UpdateMessage holds details about calls to the UpdateMessage method.
+		UpdateMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ChannelID is the channelID argument value.
+			ChannelID string
+			// Timestamp is the timestamp argument value.
+			Timestamp string
+			// Text is the text argument value.
+			Text string
+		}
 	}
+	lockDeleteMessage          sync.RWMutex
+	lockDownloadFile           sync.RWMutex
 	lockGetBotInfo             sync.RWMutex
 	lockGetChannelInfo         sync.RWMutex
+	lockGetThreadReplies       sync.RWMutex
 	lockGetUserInfo            sync.RWMutex
 	lockGetUserProfile         sync.RWMutex
 	lockIsBotUser              sync.RWMutex
 	lockIsWorkspaceMember      sync.RWMutex
 	lockPostMessage            sync.RWMutex
+	lockPostMessageForUpdate   sync.RWMutex
 	lockPostMessageWithOptions sync.RWMutex
+	lockUpdateMessage          sync.RWMutex
+}
+
+// DeleteMessage calls DeleteMessageFunc.
+func (mock *SlackClientMock) DeleteMessage(ctx context.Context, channelID string, timestamp string) error {
+	if mock.DeleteMessageFunc == nil {
+		panic("SlackClientMock.DeleteMessageFunc: method is nil but SlackClient.DeleteMessage was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ChannelID string
+		Timestamp string
+	}{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		Timestamp: timestamp,
+	}
+	mock.lockDeleteMessage.Lock()
+	mock.calls.DeleteMessage = append(mock.calls.DeleteMessage, callInfo)
+	mock.lockDeleteMessage.Unlock()
+	return mock.DeleteMessageFunc(ctx, channelID, timestamp)
+}
+
+// DeleteMessageCalls gets all the calls that were made to DeleteMessage.
+// Check the length with:
+//
+//	len(mockedSlackClient.DeleteMessageCalls())
+func (mock *SlackClientMock) DeleteMessageCalls() []struct {
+	Ctx       context.Context
+	ChannelID string
+	Timestamp string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ChannelID string
+		Timestamp string
+	}
+	mock.lockDeleteMessage.RLock()
+	calls = mock.calls.DeleteMessage
+	mock.lockDeleteMessage.RUnlock()
+	return calls
+}
+
+// DownloadFile calls DownloadFileFunc.
+func (mock *SlackClientMock) DownloadFile(ctx context.Context, downloadURL string, w io.Writer) error {
+	if mock.DownloadFileFunc == nil {
+		panic("SlackClientMock.DownloadFileFunc: method is nil but SlackClient.DownloadFile was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		DownloadURL string
+		W           io.Writer
+	}{
+		Ctx:         ctx,
+		DownloadURL: downloadURL,
+		W:           w,
+	}
+	mock.lockDownloadFile.Lock()
+	mock.calls.DownloadFile = append(mock.calls.DownloadFile, callInfo)
+	mock.lockDownloadFile.Unlock()
+	return mock.DownloadFileFunc(ctx, downloadURL, w)
+}
+
+// DownloadFileCalls gets all the calls that were made to DownloadFile.
+// Check the length with:
+//
+//	len(mockedSlackClient.DownloadFileCalls())
+func (mock *SlackClientMock) DownloadFileCalls() []struct {
+	Ctx         context.Context
+	DownloadURL string
+	W           io.Writer
+} {
+	var calls []struct {
+		Ctx         context.Context
+		DownloadURL string
+		W           io.Writer
+	}
+	mock.lockDownloadFile.RLock()
+	calls = mock.calls.DownloadFile
+	mock.lockDownloadFile.RUnlock()
+	return calls
 }
 
 // GetBotInfo calls GetBotInfoFunc.
@@ -225,6 +394,50 @@ func (mock *SlackClientMock) GetChannelInfoCalls() []struct {
 	return calls
 }
 
+// GetThreadReplies calls GetThreadRepliesFunc.
+func (mock *SlackClientMock) GetThreadReplies(ctx context.Context, channelID string, threadTS string, limit int) ([]*slack.Message, error) {
+	if mock.GetThreadRepliesFunc == nil {
+		panic("SlackClientMock.GetThreadRepliesFunc: method is nil but SlackClient.GetThreadReplies was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ChannelID string
+		ThreadTS  string
+		Limit     int
+	}{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		Limit:     limit,
+	}
+	mock.lockGetThreadReplies.Lock()
+	mock.calls.GetThreadReplies = append(mock.calls.GetThreadReplies, callInfo)
+	mock.lockGetThreadReplies.Unlock()
+	return mock.GetThreadRepliesFunc(ctx, channelID, threadTS, limit)
+}
+
+// GetThreadRepliesCalls gets all the calls that were made to GetThreadReplies.
+// Check the length with:
+//
+//	len(mockedSlackClient.GetThreadRepliesCalls())
+func (mock *SlackClientMock) GetThreadRepliesCalls() []struct {
+	Ctx       context.Context
+	ChannelID string
+	ThreadTS  string
+	Limit     int
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ChannelID string
+		ThreadTS  string
+		Limit     int
+	}
+	mock.lockGetThreadReplies.RLock()
+	calls = mock.calls.GetThreadReplies
+	mock.lockGetThreadReplies.RUnlock()
+	return calls
+}
+
 // GetUserInfo calls GetUserInfoFunc.
 func (mock *SlackClientMock) GetUserInfo(ctx context.Context, userID string) (*interfaces.SlackUserInfo, error) {
 	if mock.GetUserInfoFunc == nil {
@@ -409,6 +622,54 @@ func (mock *SlackClientMock) PostMessageCalls() []struct {
 	return calls
 }
 
+// PostMessageForUpdate calls PostMessageForUpdateFunc.
+func (mock *SlackClientMock) PostMessageForUpdate(ctx context.Context, channelID string, threadTS string, text string, options *interfaces.SlackMessageOptions) (string, error) {
+	if mock.PostMessageForUpdateFunc == nil {
+		panic("SlackClientMock.PostMessageForUpdateFunc: method is nil but SlackClient.PostMessageForUpdate was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ChannelID string
+		ThreadTS  string
+		Text      string
+		Options   *interfaces.SlackMessageOptions
+	}{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		Text:      text,
+		Options:   options,
+	}
+	mock.lockPostMessageForUpdate.Lock()
+	mock.calls.PostMessageForUpdate = append(mock.calls.PostMessageForUpdate, callInfo)
+	mock.lockPostMessageForUpdate.Unlock()
+	return mock.PostMessageForUpdateFunc(ctx, channelID, threadTS, text, options)
+}
+
+// PostMessageForUpdateCalls gets all the calls that were made to PostMessageForUpdate.
+// Check the length with:
+//
+//	len(mockedSlackClient.PostMessageForUpdateCalls())
+func (mock *SlackClientMock) PostMessageForUpdateCalls() []struct {
+	Ctx       context.Context
+	ChannelID string
+	ThreadTS  string
+	Text      string
+	Options   *interfaces.SlackMessageOptions
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ChannelID string
+		ThreadTS  string
+		Text      string
+		Options   *interfaces.SlackMessageOptions
+	}
+	mock.lockPostMessageForUpdate.RLock()
+	calls = mock.calls.PostMessageForUpdate
+	mock.lockPostMessageForUpdate.RUnlock()
+	return calls
+}
+
 // PostMessageWithOptions calls PostMessageWithOptionsFunc.
 func (mock *SlackClientMock) PostMessageWithOptions(ctx context.Context, channelID string, threadTS string, text string, options *interfaces.SlackMessageOptions) error {
 	if mock.PostMessageWithOptionsFunc == nil {
@@ -457,6 +718,50 @@ func (mock *SlackClientMock) PostMessageWithOptionsCalls() []struct {
 	return calls
 }
 
+// UpdateMessage calls UpdateMessageFunc.
+func (mock *SlackClientMock) UpdateMessage(ctx context.Context, channelID string, timestamp string, text string) error {
+	if mock.UpdateMessageFunc == nil {
+		panic("SlackClientMock.UpdateMessageFunc: method is nil but SlackClient.UpdateMessage was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ChannelID string
+		Timestamp string
+		Text      string
+	}{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		Timestamp: timestamp,
+		Text:      text,
+	}
+	mock.lockUpdateMessage.Lock()
+	mock.calls.UpdateMessage = append(mock.calls.UpdateMessage, callInfo)
+	mock.lockUpdateMessage.Unlock()
+	return mock.UpdateMessageFunc(ctx, channelID, timestamp, text)
+}
+
+// UpdateMessageCalls gets all the calls that were made to UpdateMessage.
+// Check the length with:
+//
+//	len(mockedSlackClient.UpdateMessageCalls())
+func (mock *SlackClientMock) UpdateMessageCalls() []struct {
+	Ctx       context.Context
+	ChannelID string
+	Timestamp string
+	Text      string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ChannelID string
+		Timestamp string
+		Text      string
+	}
+	mock.lockUpdateMessage.RLock()
+	calls = mock.calls.UpdateMessage
+	mock.lockUpdateMessage.RUnlock()
+	return calls
+}
+
 // Ensure, that ThreadRepositoryMock does implement interfaces.ThreadRepository.
 // If this is not the case, regenerate this file with moq.
 var _ interfaces.ThreadRepository = &ThreadRepositoryMock{}
@@ -488,15 +793,24 @@ var _ interfaces.ThreadRepository = &ThreadRepositoryMock{}
 //			GetThreadMessagesFunc: func(ctx context.Context, threadID types.ThreadID) ([]*slack.Message, error) {
 //				panic("mock out the GetThreadMessages method")
 //			},
+//			ListHistoriesFunc: func(ctx context.Context, threadID types.ThreadID) ([]*slack.History, error) {
+//				panic("mock out the ListHistories method")
+//			},
 //			ListThreadsFunc: func(ctx context.Context, offset int, limit int) ([]*slack.Thread, int, error) {
 //				panic("mock out the ListThreads method")
 //			},
 //			PutHistoryFunc: func(ctx context.Context, history *slack.History) error {
 //				panic("mock out the PutHistory method")
 //			},
+//			PutThreadFunc: func(ctx context.Context, thread *slack.Thread) error {
+//				panic("mock out the PutThread method")
+//			},
 //			PutThreadMessageFunc: func(ctx context.Context, threadID types.ThreadID, message *slack.Message) error {
 //				panic("mock out the PutThreadMessage method")
 //			},
+//			UpdateThreadAgentFunc: func(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) error {
+//				panic("mock out the UpdateThreadAgent method")
+//			},
 //		}
 //
 //		// use mockedThreadRepository in code that requires interfaces.ThreadRepository
@@ -525,15 +839,24 @@ type ThreadRepositoryMock struct {
 	// GetThreadMessagesFunc mocks the GetThreadMessages method.
 	GetThreadMessagesFunc func(ctx context.Context, threadID types.ThreadID) ([]*slack.Message, error)
 
+	// ListHistoriesFunc mocks the ListHistories method.
+	ListHistoriesFunc func(ctx context.Context, threadID types.ThreadID) ([]*slack.History, error)
+
 	// ListThreadsFunc mocks the ListThreads method.
 	ListThreadsFunc func(ctx context.Context, offset int, limit int) ([]*slack.Thread, int, error)
 
 	// PutHistoryFunc mocks the PutHistory method.
 	PutHistoryFunc func(ctx context.Context, history *slack.History) error
 
+	// PutThreadFunc mocks the PutThread method.
+	PutThreadFunc func(ctx context.Context, thread *slack.Thread) error
+
 	// PutThreadMessageFunc mocks the PutThreadMessage method.
 	PutThreadMessageFunc func(ctx context.Context, threadID types.ThreadID, message *slack.Message) error
 
+	// UpdateThreadAgentFunc mocks the UpdateThreadAgent method.
+	UpdateThreadAgentFunc func(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// GetHistoryByID holds details about calls to the GetHistoryByID method.
@@ -599,6 +922,13 @@ type ThreadRepositoryMock struct {
 			// ThreadID is the threadID argument value.
 			ThreadID types.ThreadID
 		}
+		// ListHistories holds details about calls to the ListHistories method.
+		ListHistories []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ThreadID is the threadID argument value.
+			ThreadID types.ThreadID
+		}
 		// ListThreads holds details about calls to the ListThreads method.
 		ListThreads []struct {
 			// Ctx is the ctx argument value.
@@ -615,6 +945,13 @@ type ThreadRepositoryMock struct {
 			// History is the history argument value.
 			History *slack.History
 		}
+		// PutThread holds details about calls to the PutThread method.
+		PutThread []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Thread is the thread argument value.
+			Thread *slack.Thread
+		}
 		// PutThreadMessage holds details about calls to the PutThreadMessage method.
 		PutThreadMessage []struct {
 			// Ctx is the ctx argument value.
@@ -624,6 +961,17 @@ type ThreadRepositoryMock struct {
 			// Message is the message argument value.
 			Message *slack.Message
 		}
+		// UpdateThreadAgent holds details about calls to the UpdateThreadAgent method.
+		UpdateThreadAgent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ThreadID is the threadID argument value.
+			ThreadID types.ThreadID
+			// AgentUUID is the agentUUID argument value.
+			AgentUUID *types.UUID
+			// AgentVersion is the agentVersion argument value.
+			AgentVersion string
+		}
 	}
 	lockGetHistoryByID          sync.RWMutex
 	lockGetLatestHistory        sync.RWMutex
@@ -632,9 +980,12 @@ type ThreadRepositoryMock struct {
 	lockGetThread               sync.RWMutex
 	lockGetThreadByTS           sync.RWMutex
 	lockGetThreadMessages       sync.RWMutex
+	lockListHistories           sync.RWMutex
 	lockListThreads             sync.RWMutex
 	lockPutHistory              sync.RWMutex
+	lockPutThread               sync.RWMutex
 	lockPutThreadMessage        sync.RWMutex
+	lockUpdateThreadAgent       sync.RWMutex
 }
 
 // GetHistoryByID calls GetHistoryByIDFunc.
@@ -917,6 +1268,42 @@ func (mock *ThreadRepositoryMock) GetThreadMessagesCalls() []struct {
 	return calls
 }
 
+// ListHistories calls ListHistoriesFunc.
+func (mock *ThreadRepositoryMock) ListHistories(ctx context.Context, threadID types.ThreadID) ([]*slack.History, error) {
+	if mock.ListHistoriesFunc == nil {
+		panic("ThreadRepositoryMock.ListHistoriesFunc: method is nil but ThreadRepository.ListHistories was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		ThreadID types.ThreadID
+	}{
+		Ctx:      ctx,
+		ThreadID: threadID,
+	}
+	mock.lockListHistories.Lock()
+	mock.calls.ListHistories = append(mock.calls.ListHistories, callInfo)
+	mock.lockListHistories.Unlock()
+	return mock.ListHistoriesFunc(ctx, threadID)
+}
+
+// ListHistoriesCalls gets all the calls that were made to ListHistories.
+// Check the length with:
+//
+//	len(mockedThreadRepository.ListHistoriesCalls())
+func (mock *ThreadRepositoryMock) ListHistoriesCalls() []struct {
+	Ctx      context.Context
+	ThreadID types.ThreadID
+} {
+	var calls []struct {
+		Ctx      context.Context
+		ThreadID types.ThreadID
+	}
+	mock.lockListHistories.RLock()
+	calls = mock.calls.ListHistories
+	mock.lockListHistories.RUnlock()
+	return calls
+}
+
 // ListThreads calls ListThreadsFunc.
 func (mock *ThreadRepositoryMock) ListThreads(ctx context.Context, offset int, limit int) ([]*slack.Thread, int, error) {
 	if mock.ListThreadsFunc == nil {
@@ -993,6 +1380,42 @@ func (mock *ThreadRepositoryMock) PutHistoryCalls() []struct {
 	return calls
 }
 
+// PutThread calls PutThreadFunc.
+func (mock *ThreadRepositoryMock) PutThread(ctx context.Context, thread *slack.Thread) error {
+	if mock.PutThreadFunc == nil {
+		panic("ThreadRepositoryMock.PutThreadFunc: method is nil but ThreadRepository.PutThread was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Thread *slack.Thread
+	}{
+		Ctx:    ctx,
+		Thread: thread,
+	}
+	mock.lockPutThread.Lock()
+	mock.calls.PutThread = append(mock.calls.PutThread, callInfo)
+	mock.lockPutThread.Unlock()
+	return mock.PutThreadFunc(ctx, thread)
+}
+
+// PutThreadCalls gets all the calls that were made to PutThread.
+// Check the length with:
+//
+//	len(mockedThreadRepository.PutThreadCalls())
+func (mock *ThreadRepositoryMock) PutThreadCalls() []struct {
+	Ctx    context.Context
+	Thread *slack.Thread
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Thread *slack.Thread
+	}
+	mock.lockPutThread.RLock()
+	calls = mock.calls.PutThread
+	mock.lockPutThread.RUnlock()
+	return calls
+}
+
 // PutThreadMessage calls PutThreadMessageFunc.
 func (mock *ThreadRepositoryMock) PutThreadMessage(ctx context.Context, threadID types.ThreadID, message *slack.Message) error {
 	if mock.PutThreadMessageFunc == nil {
@@ -1033,6 +1456,50 @@ func (mock *ThreadRepositoryMock) PutThreadMessageCalls() []struct {
 	return calls
 }
 
+// UpdateThreadAgent calls UpdateThreadAgentFunc.
+func (mock *ThreadRepositoryMock) UpdateThreadAgent(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) error {
+	if mock.UpdateThreadAgentFunc == nil {
+		panic("ThreadRepositoryMock.UpdateThreadAgentFunc: method is nil but ThreadRepository.UpdateThreadAgent was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		ThreadID     types.ThreadID
+		AgentUUID    *types.UUID
+		AgentVersion string
+	}{
+		Ctx:          ctx,
+		ThreadID:     threadID,
+		AgentUUID:    agentUUID,
+		AgentVersion: agentVersion,
+	}
+	mock.lockUpdateThreadAgent.Lock()
+	mock.calls.UpdateThreadAgent = append(mock.calls.UpdateThreadAgent, callInfo)
+	mock.lockUpdateThreadAgent.Unlock()
+	return mock.UpdateThreadAgentFunc(ctx, threadID, agentUUID, agentVersion)
+}
+
+// UpdateThreadAgentCalls gets all the calls that were made to UpdateThreadAgent.
+// Check the length with:
+//
+//	len(mockedThreadRepository.UpdateThreadAgentCalls())
+func (mock *ThreadRepositoryMock) UpdateThreadAgentCalls() []struct {
+	Ctx          context.Context
+	ThreadID     types.ThreadID
+	AgentUUID    *types.UUID
+	AgentVersion string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		ThreadID     types.ThreadID
+		AgentUUID    *types.UUID
+		AgentVersion string
+	}
+	mock.lockUpdateThreadAgent.RLock()
+	calls = mock.calls.UpdateThreadAgent
+	mock.lockUpdateThreadAgent.RUnlock()
+	return calls
+}
+
 // Ensure, that HistoryRepositoryMock does implement interfaces.HistoryRepository.
 // If this is not the case, regenerate this file with moq.
 var _ interfaces.HistoryRepository = &HistoryRepositoryMock{}
@@ -15,6 +15,7 @@ type Agent struct {
 	Status      Status       `json:"status"`
 	Latest      string       `json:"latest"`
 	ImageID     *types.UUID  `json:"image_id,omitempty"`
+	Rollout     *Rollout     `json:"rollout,omitempty"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 }
@@ -0,0 +1,337 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"maps"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"gopkg.in/yaml.v3"
+)
+
+// BundleAPIVersion is the format version of agent bundles
+const BundleAPIVersion = "tamamo/v1"
+
+// ImportConflictMode decides how to import a bundle of an agent whose agent ID already exists
+type ImportConflictMode string
+
+const (
+	// ImportConflictSkip leaves the existing agent as it is
+	ImportConflictSkip ImportConflictMode = "skip"
+	// ImportConflictOverwrite replaces the metadata, drafts in the bundle, search configs and
+	// image of the existing agent. A published version that differs from the bundle is kept, and
+	// the version of the bundle is added with the next free patch version. Versions that are not
+	// in the bundle are kept.
+	ImportConflictOverwrite ImportConflictMode = "overwrite"
+	// ImportConflictNewVersion adds the latest version in the bundle to the existing agent as a
+	// new latest version
+	ImportConflictNewVersion ImportConflictMode = "new_version"
+)
+
+// IsValid reports whether the mode is known
+func (m ImportConflictMode) IsValid() bool {
+	switch m {
+	case ImportConflictSkip, ImportConflictOverwrite, ImportConflictNewVersion:
+		return true
+	}
+	return false
+}
+
+// ImportAction is what an import did to the agent
+type ImportAction string
+
+const (
+	ImportActionCreated      ImportAction = "created"
+	ImportActionSkipped      ImportAction = "skipped"
+	ImportActionOverwritten  ImportAction = "overwritten"
+	ImportActionVersionAdded ImportAction = "version_added"
+)
+
+// Bundle is a declarative YAML document of an agent to move it between workspaces and keep it in
+// git. IDs of the workspace, e.g. agent UUID and authors, are not included.
+type Bundle struct {
+	APIVersion          string                      `yaml:"api_version"`
+	Agent               BundleAgent                 `yaml:"agent"`
+	Versions            []*BundleVersion            `yaml:"versions"`
+	SlackSearchConfigs  []*BundleSlackSearchConfig  `yaml:"slack_search_configs,omitempty"`
+	JiraSearchConfigs   []*BundleJiraSearchConfig   `yaml:"jira_search_configs,omitempty"`
+	NotionSearchConfigs []*BundleNotionSearchConfig `yaml:"notion_search_configs,omitempty"`
+	Image               *BundleImage                `yaml:"image,omitempty"`
+}
+
+// BundleAgent is the metadata of the agent in a bundle
+type BundleAgent struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Latest      string `yaml:"latest"`
+}
+
+// BundleVersion is a version of the agent in a bundle
+type BundleVersion struct {
+	Version         string            `yaml:"version"`
+	SystemPrompt    string            `yaml:"system_prompt"`
+	PromptTemplate  bool              `yaml:"prompt_template,omitempty"`
+	PromptVariables map[string]string `yaml:"prompt_variables,omitempty"`
+	LLMProvider     types.LLMProvider `yaml:"llm_provider,omitempty"`
+	LLMModel        string            `yaml:"llm_model,omitempty"`
+	MCPServers      []*MCPServer      `yaml:"mcp_servers,omitempty"`
+	Delegation      *Delegation       `yaml:"delegation,omitempty"`
+	Draft           bool              `yaml:"draft,omitempty"`
+	Changelog       string            `yaml:"changelog,omitempty"`
+}
+
+type BundleSlackSearchConfig struct {
+	ChannelID   string  `yaml:"channel_id"`
+	ChannelName string  `yaml:"channel_name"`
+	Description *string `yaml:"description,omitempty"`
+	Enabled     bool    `yaml:"enabled"`
+}
+
+type BundleJiraSearchConfig struct {
+	ProjectKey  string  `yaml:"project_key"`
+	ProjectName string  `yaml:"project_name"`
+	BoardID     *string `yaml:"board_id,omitempty"`
+	BoardName   *string `yaml:"board_name,omitempty"`
+	Description *string `yaml:"description,omitempty"`
+	Enabled     bool    `yaml:"enabled"`
+}
+
+type BundleNotionSearchConfig struct {
+	DatabaseID   string  `yaml:"database_id"`
+	DatabaseName string  `yaml:"database_name"`
+	WorkspaceID  string  `yaml:"workspace_id"`
+	Description  *string `yaml:"description,omitempty"`
+	Enabled      bool    `yaml:"enabled"`
+}
+
+// BundleImage is the avatar image of the agent. Data is encoded in base64.
+type BundleImage struct {
+	ContentType string `yaml:"content_type"`
+	Data        string `yaml:"data"`
+}
+
+// ParseBundle decodes and validates a YAML bundle. Unknown fields are rejected to catch typos.
+func ParseBundle(data []byte, opts ...ValidateOption) (*Bundle, error) {
+	bundle, err := DecodeBundle(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.Validate(opts...); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// DecodeBundle decodes a YAML bundle without validation. It is for callers that validate the
+// bundle later, e.g. on import. Unknown fields are rejected to catch typos.
+func DecodeBundle(data []byte) (*Bundle, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var bundle Bundle
+	if err := decoder.Decode(&bundle); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse agent bundle")
+	}
+	return &bundle, nil
+}
+
+// Encode encodes the bundle to YAML
+func (b *Bundle) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(b); err != nil {
+		return nil, goerr.Wrap(err, "failed to encode agent bundle")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to encode agent bundle")
+	}
+	return buf.Bytes(), nil
+}
+
+// Validate validates the bundle with the same rules as agents, versions and search configs created
+// in the web UI
+func (b *Bundle) Validate(opts ...ValidateOption) error {
+	if b.APIVersion != BundleAPIVersion {
+		return goerr.New("unsupported bundle API version",
+			goerr.V("api_version", b.APIVersion),
+			goerr.V("supported", BundleAPIVersion))
+	}
+
+	if err := ValidateAgent(&Agent{
+		AgentID:     b.Agent.ID,
+		Name:        b.Agent.Name,
+		Description: b.Agent.Description,
+		Status:      StatusActive,
+		Latest:      b.Agent.Latest,
+	}); err != nil {
+		return goerr.Wrap(err, "invalid agent in bundle")
+	}
+
+	if len(b.Versions) == 0 {
+		return goerr.New("bundle has no version", goerr.V("agent_id", b.Agent.ID))
+	}
+	versions := make(map[string]*BundleVersion, len(b.Versions))
+	for _, v := range b.Versions {
+		if v == nil {
+			return goerr.New("version in bundle cannot be empty")
+		}
+		if _, exists := versions[v.Version]; exists {
+			return goerr.New("version is duplicated in bundle", goerr.V("version", v.Version))
+		}
+		versions[v.Version] = v
+
+		if err := ValidateAgentVersion(v.ToAgentVersion("", "", time.Time{}), opts...); err != nil {
+			return goerr.Wrap(err, "invalid version in bundle", goerr.V("version", v.Version))
+		}
+	}
+	latest, ok := versions[b.Agent.Latest]
+	if !ok {
+		return goerr.New("latest version is not in bundle", goerr.V("latest", b.Agent.Latest))
+	}
+	if latest.Draft {
+		return goerr.New("latest version cannot be a draft", goerr.V("latest", b.Agent.Latest))
+	}
+
+	// Search configs are validated with the agent ID instead of the UUID that is given on import
+	for _, c := range b.SlackSearchConfigs {
+		if err := c.ToConfig(b.Agent.ID).Validate(); err != nil {
+			return goerr.Wrap(err, "invalid slack search config in bundle")
+		}
+	}
+	for _, c := range b.JiraSearchConfigs {
+		if err := c.ToConfig(b.Agent.ID).Validate(); err != nil {
+			return goerr.Wrap(err, "invalid jira search config in bundle")
+		}
+	}
+	for _, c := range b.NotionSearchConfigs {
+		if err := c.ToConfig(b.Agent.ID).Validate(); err != nil {
+			return goerr.Wrap(err, "invalid notion search config in bundle")
+		}
+	}
+
+	if b.Image != nil {
+		if b.Image.ContentType == "" {
+			return goerr.New("content type of image is required")
+		}
+		if _, err := b.Image.Decode(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindVersion returns the version in the bundle, or nil if not found
+func (b *Bundle) FindVersion(version string) *BundleVersion {
+	for _, v := range b.Versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// NewBundleVersion converts the version to a bundle version. Values of env and headers of MCP
+// servers are cleared unless includeSecrets is true, and only their keys are kept.
+func NewBundleVersion(v *AgentVersion, includeSecrets bool) *BundleVersion {
+	bv := &BundleVersion{
+		Version:         v.Version,
+		SystemPrompt:    v.SystemPrompt,
+		PromptTemplate:  v.PromptTemplate,
+		PromptVariables: v.PromptVariables,
+		LLMProvider:     v.LLMProvider,
+		LLMModel:        v.LLMModel,
+		Delegation:      v.Delegation,
+		Draft:           v.IsDraft(),
+		Changelog:       v.Changelog,
+	}
+
+	for _, s := range v.MCPServers {
+		server := *s
+		if !includeSecrets {
+			server.Env = clearValues(s.Env)
+			server.Headers = clearValues(s.Headers)
+		}
+		bv.MCPServers = append(bv.MCPServers, &server)
+	}
+	return bv
+}
+
+func clearValues(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	cleared := make(map[string]string, len(m))
+	for key := range m {
+		cleared[key] = ""
+	}
+	return cleared
+}
+
+// ToAgentVersion converts the bundle version to an agent version of the agent. Drafts belong to
+// author because authors in the source workspace do not exist in the destination.
+func (v *BundleVersion) ToAgentVersion(agentUUID types.UUID, author types.UserID, now time.Time) *AgentVersion {
+	version := &AgentVersion{
+		AgentUUID:       agentUUID,
+		Version:         v.Version,
+		SystemPrompt:    v.SystemPrompt,
+		PromptTemplate:  v.PromptTemplate,
+		PromptVariables: maps.Clone(v.PromptVariables),
+		LLMProvider:     v.LLMProvider,
+		LLMModel:        v.LLMModel,
+		Delegation:      v.Delegation,
+		Status:          VersionStatusPublished,
+		Author:          author,
+		PublishedAt:     &now,
+		Changelog:       v.Changelog,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if v.Draft {
+		version.Status = VersionStatusDraft
+		version.PublishedAt = nil
+	}
+	for _, s := range v.MCPServers {
+		server := *s
+		server.Env = maps.Clone(s.Env)
+		server.Headers = maps.Clone(s.Headers)
+		version.MCPServers = append(version.MCPServers, &server)
+	}
+	return version
+}
+
+// ToConfig converts the bundle config to a search config of the agent
+func (c *BundleSlackSearchConfig) ToConfig(agentID string) *SlackSearchConfig {
+	return NewSlackSearchConfig(agentID, c.ChannelID, c.ChannelName, c.Description, c.Enabled)
+}
+
+// ToConfig converts the bundle config to a search config of the agent
+func (c *BundleJiraSearchConfig) ToConfig(agentID string) *JiraSearchConfig {
+	return NewJiraSearchConfig(agentID, c.ProjectKey, c.ProjectName, c.BoardID, c.BoardName, c.Description, c.Enabled)
+}
+
+// ToConfig converts the bundle config to a search config of the agent
+func (c *BundleNotionSearchConfig) ToConfig(agentID string) *NotionSearchConfig {
+	return NewNotionSearchConfig(agentID, c.DatabaseID, c.DatabaseName, c.WorkspaceID, c.Description, c.Enabled)
+}
+
+// NewBundleImage encodes the image data
+func NewBundleImage(contentType string, data []byte) *BundleImage {
+	return &BundleImage{
+		ContentType: contentType,
+		Data:        base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// Decode returns the image data
+func (i *BundleImage) Decode() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(i.Data)
+	if err != nil {
+		return nil, goerr.Wrap(err, "image data in bundle is not base64")
+	}
+	return data, nil
+}
@@ -0,0 +1,145 @@
+package agent_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const testBundle = `api_version: tamamo/v1
+agent:
+  id: sre-helper
+  name: SRE Helper
+  latest: 1.1.0
+versions:
+  - version: 1.0.0
+    system_prompt: You are an SRE helper.
+  - version: 1.1.0
+    system_prompt: You are an SRE helper for {{.Vars.team}}.
+    prompt_template: true
+    prompt_variables:
+      team: platform
+    llm_provider: openai
+    llm_model: gpt-4o
+    mcp_servers:
+      - name: github
+        transport: http
+        url: https://mcp.example.com/github
+        headers:
+          Authorization: ""
+    changelog: Add the team name
+  - version: 1.2.0
+    system_prompt: You are a terse SRE helper.
+    draft: true
+slack_search_configs:
+  - channel_id: C12345
+    channel_name: incidents
+    enabled: true
+`
+
+func TestParseBundle(t *testing.T) {
+	bundle, err := agent.ParseBundle([]byte(testBundle))
+	gt.NoError(t, err)
+	gt.Equal(t, bundle.Agent.ID, "sre-helper")
+	gt.Equal(t, bundle.Agent.Latest, "1.1.0")
+	gt.A(t, bundle.Versions).Length(3)
+	gt.A(t, bundle.SlackSearchConfigs).Length(1)
+
+	v := bundle.FindVersion("1.1.0")
+	gt.NotNil(t, v)
+	gt.Equal(t, v.LLMProvider, types.LLMProviderOpenAI)
+	gt.True(t, v.PromptTemplate)
+	gt.Equal(t, v.PromptVariables, map[string]string{"team": "platform"})
+	gt.False(t, bundle.FindVersion("1.0.0").PromptTemplate)
+	gt.Equal(t, v.MCPServers[0].Headers, map[string]string{"Authorization": ""})
+	gt.True(t, bundle.FindVersion("1.2.0").Draft)
+	gt.Nil(t, bundle.FindVersion("2.0.0"))
+
+	t.Run("round trip", func(t *testing.T) {
+		data, err := bundle.Encode()
+		gt.NoError(t, err)
+		decoded, err := agent.ParseBundle(data)
+		gt.NoError(t, err)
+		gt.Equal(t, decoded, bundle)
+	})
+}
+
+func TestParseBundleInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		replace [2]string
+	}{
+		{name: "unsupported api version", replace: [2]string{"tamamo/v1", "tamamo/v2"}},
+		{name: "unknown field", replace: [2]string{"llm_model:", "model:"}},
+		{name: "invalid agent ID", replace: [2]string{"id: sre-helper", "id: SRE Helper"}},
+		{name: "latest not in bundle", replace: [2]string{"latest: 1.1.0", "latest: 2.0.0"}},
+		{name: "draft latest", replace: [2]string{"latest: 1.1.0", "latest: 1.2.0"}},
+		{name: "duplicated version", replace: [2]string{"version: 1.2.0", "version: 1.0.0"}},
+		{name: "broken prompt template", replace: [2]string{"{{.Vars.team}}", "{{.Vars.team"}},
+		{name: "invalid search config", replace: [2]string{"channel_name: incidents", "channel_name: \"\""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := strings.Replace(testBundle, tc.replace[0], tc.replace[1], 1)
+			_, err := agent.ParseBundle([]byte(data))
+			gt.Error(t, err)
+		})
+	}
+}
+
+func TestParseBundleStdioMCPServer(t *testing.T) {
+	data := strings.Replace(testBundle, `      - name: github
+        transport: http
+        url: https://mcp.example.com/github
+        headers:
+          Authorization: ""`, `      - name: local
+        transport: stdio
+        command: /usr/local/bin/mcp-server`, 1)
+
+	// Stdio MCP servers are rejected unless their commands are allowed
+	_, err := agent.ParseBundle([]byte(data))
+	gt.Error(t, err)
+	_, err = agent.ParseBundle([]byte(data), agent.WithMCPPolicy(agent.MCPPolicy{AllowedCommands: []string{"/usr/bin/other"}}))
+	gt.Error(t, err)
+
+	bundle, err := agent.ParseBundle([]byte(data), agent.WithMCPPolicy(agent.MCPPolicy{AllowedCommands: []string{"/usr/local/bin/mcp-server"}}))
+	gt.NoError(t, err)
+	gt.Equal(t, bundle.FindVersion("1.1.0").MCPServers[0].Command, "/usr/local/bin/mcp-server")
+
+	// Decoding does not validate the bundle
+	_, err = agent.DecodeBundle([]byte(data))
+	gt.NoError(t, err)
+}
+
+func TestNewBundleVersion(t *testing.T) {
+	version := &agent.AgentVersion{
+		Version:      "1.0.0",
+		SystemPrompt: "You are an SRE helper.",
+		Status:       agent.VersionStatusPublished,
+		MCPServers: []*agent.MCPServer{
+			{
+				Name:      "local",
+				Transport: agent.MCPTransportStdio,
+				Command:   "npx",
+				Env:       map[string]string{"API_KEY": "secret"},
+			},
+		},
+	}
+
+	t.Run("without secrets", func(t *testing.T) {
+		bv := agent.NewBundleVersion(version, false)
+		gt.Equal(t, bv.MCPServers[0].Env, map[string]string{"API_KEY": ""})
+		gt.False(t, bv.Draft)
+		// The original version is not modified
+		gt.Equal(t, version.MCPServers[0].Env["API_KEY"], "secret")
+	})
+
+	t.Run("with secrets", func(t *testing.T) {
+		bv := agent.NewBundleVersion(version, true)
+		gt.Equal(t, bv.MCPServers[0].Env, map[string]string{"API_KEY": "secret"})
+	})
+}
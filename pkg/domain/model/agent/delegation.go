@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"slices"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// maxDelegationAgents caps the number of agents listed in Delegation.AgentIDs
+const maxDelegationAgents = 50
+
+// Delegation configures consultation between agents. An agent can consult another agent only if
+// delegation is enabled on the latest versions of both agents.
+type Delegation struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// AgentIDs limits agents that can be consulted. All agents that enabled delegation can be consulted if empty.
+	AgentIDs []string `json:"agent_ids,omitempty" yaml:"agent_ids,omitempty"`
+}
+
+// CanConsult reports whether the agent can consult the agent with agentID
+func (d *Delegation) CanConsult(agentID string) bool {
+	if d == nil || !d.Enabled {
+		return false
+	}
+	return len(d.AgentIDs) == 0 || slices.Contains(d.AgentIDs, agentID)
+}
+
+// IsEnabled reports whether delegation is enabled. It is safe to call on nil.
+func (d *Delegation) IsEnabled() bool {
+	return d != nil && d.Enabled
+}
+
+// Validate validates the Delegation
+func (d *Delegation) Validate() error {
+	if len(d.AgentIDs) > maxDelegationAgents {
+		return goerr.New("too many agents to delegate to",
+			goerr.V("count", len(d.AgentIDs)),
+			goerr.V("max", maxDelegationAgents))
+	}
+
+	seen := make(map[string]struct{}, len(d.AgentIDs))
+	for _, agentID := range d.AgentIDs {
+		if err := ValidateAgentID(agentID); err != nil {
+			return goerr.Wrap(err, "invalid agent ID to delegate to")
+		}
+		if _, exists := seen[agentID]; exists {
+			return goerr.New("agent ID to delegate to is duplicated", goerr.V("agent_id", agentID))
+		}
+		seen[agentID] = struct{}{}
+	}
+	return nil
+}
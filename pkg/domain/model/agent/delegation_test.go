@@ -0,0 +1,57 @@
+package agent_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+)
+
+func TestDelegationCanConsult(t *testing.T) {
+	var nilDelegation *agent.Delegation
+	gt.False(t, nilDelegation.CanConsult("sql-helper"))
+	gt.False(t, (&agent.Delegation{Enabled: false}).CanConsult("sql-helper"))
+	gt.True(t, (&agent.Delegation{Enabled: true}).CanConsult("sql-helper"))
+
+	limited := &agent.Delegation{Enabled: true, AgentIDs: []string{"sql-helper"}}
+	gt.True(t, limited.CanConsult("sql-helper"))
+	gt.False(t, limited.CanConsult("security-reviewer"))
+}
+
+func TestDelegationValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		delegation *agent.Delegation
+		shouldErr  bool
+	}{
+		{
+			name:       "all agents",
+			delegation: &agent.Delegation{Enabled: true},
+		},
+		{
+			name:       "listed agents",
+			delegation: &agent.Delegation{Enabled: true, AgentIDs: []string{"sql-helper", "security-reviewer"}},
+		},
+		{
+			name:       "invalid agent ID",
+			delegation: &agent.Delegation{Enabled: true, AgentIDs: []string{"-invalid"}},
+			shouldErr:  true,
+		},
+		{
+			name:       "duplicated agent ID",
+			delegation: &agent.Delegation{Enabled: true, AgentIDs: []string{"sql-helper", "sql-helper"}},
+			shouldErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.delegation.Validate()
+			if tc.shouldErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+		})
+	}
+}
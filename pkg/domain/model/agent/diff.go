@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DiffOp is an operation of a line in a line diff
+type DiffOp string
+
+const (
+	DiffOpEqual  DiffOp = "equal"
+	DiffOpInsert DiffOp = "insert"
+	DiffOpDelete DiffOp = "delete"
+)
+
+// maskedValue replaces values of env and headers of MCP servers in diffs because they may contain secrets
+const maskedValue = "********"
+
+// DiffLine is a line of a line diff
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// FieldChange is a change of a setting between two versions. From or To is empty if the setting
+// is added or removed.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// VersionDiff is the difference between two versions of an agent
+type VersionDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// SystemPrompt is the line diff of the system prompt from From to To
+	SystemPrompt []DiffLine `json:"system_prompt"`
+	// Changes are field-level changes of LLM and tool settings
+	Changes []FieldChange `json:"changes"`
+	// Changelog is the note of the To version
+	Changelog string `json:"changelog,omitempty"`
+}
+
+// DiffVersions compares the system prompt, LLM settings and tool settings of two versions
+func DiffVersions(from, to *AgentVersion) *VersionDiff {
+	diff := &VersionDiff{
+		From:         from.Version,
+		To:           to.Version,
+		SystemPrompt: DiffLines(from.SystemPrompt, to.SystemPrompt),
+		Changes:      []FieldChange{},
+		Changelog:    to.Changelog,
+	}
+
+	diff.addChange("llm_provider", from.LLMProvider.String(), to.LLMProvider.String())
+	diff.addChange("llm_model", from.LLMModel, to.LLMModel)
+	diff.addChange("prompt_template", strconv.FormatBool(from.PromptTemplate), strconv.FormatBool(to.PromptTemplate))
+	diff.addMapChanges("prompt_variables", from.PromptVariables, to.PromptVariables, false)
+	diff.addMCPServerChanges(from.MCPServers, to.MCPServers)
+	diff.addDelegationChanges(from.Delegation, to.Delegation)
+
+	return diff
+}
+
+// HasChanges reports whether the system prompt or any setting differs between the two versions.
+// The changelog is a note of the version, so it is not compared.
+func (d *VersionDiff) HasChanges() bool {
+	if len(d.Changes) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(d.SystemPrompt, func(line DiffLine) bool { return line.Op != DiffOpEqual })
+}
+
+func (d *VersionDiff) addChange(field, from, to string) {
+	if from != to {
+		d.Changes = append(d.Changes, FieldChange{Field: field, From: from, To: to})
+	}
+}
+
+func (d *VersionDiff) addMapChanges(field string, from, to map[string]string, masked bool) {
+	keys := slices.Sorted(maps.Keys(from))
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		fromValue, fromOK := from[key]
+		toValue, toOK := to[key]
+		if fromOK == toOK && fromValue == toValue {
+			continue
+		}
+		if masked {
+			fromValue, toValue = maskValue(fromOK), maskValue(toOK)
+			if fromOK && toOK {
+				// Show that the value is changed without exposing it
+				toValue = maskedValue + " (changed)"
+			}
+		}
+		d.Changes = append(d.Changes, FieldChange{Field: field + "." + key, From: fromValue, To: toValue})
+	}
+}
+
+func maskValue(exists bool) string {
+	if exists {
+		return maskedValue
+	}
+	return ""
+}
+
+func (d *VersionDiff) addMCPServerChanges(from, to []*MCPServer) {
+	fromServers := make(map[string]*MCPServer, len(from))
+	for _, s := range from {
+		fromServers[s.Name] = s
+	}
+	toServers := make(map[string]*MCPServer, len(to))
+	for _, s := range to {
+		toServers[s.Name] = s
+	}
+
+	names := slices.Sorted(maps.Keys(fromServers))
+	for name := range toServers {
+		if _, ok := fromServers[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		field := "mcp_servers." + name
+		fromServer, toServer := fromServers[name], toServers[name]
+		switch {
+		case fromServer == nil:
+			d.addChange(field, "", toServer.summary())
+		case toServer == nil:
+			d.addChange(field, fromServer.summary(), "")
+		default:
+			d.addChange(field+".transport", string(fromServer.Transport), string(toServer.Transport))
+			d.addChange(field+".command", fromServer.Command, toServer.Command)
+			d.addChange(field+".args", strings.Join(fromServer.Args, " "), strings.Join(toServer.Args, " "))
+			d.addChange(field+".url", fromServer.URL, toServer.URL)
+			d.addChange(field+".allowed_tools", strings.Join(fromServer.AllowedTools, ", "), strings.Join(toServer.AllowedTools, ", "))
+			d.addChange(field+".timeout", fromServer.Timeout().String(), toServer.Timeout().String())
+			d.addMapChanges(field+".env", fromServer.Env, toServer.Env, true)
+			d.addMapChanges(field+".headers", fromServer.Headers, toServer.Headers, true)
+		}
+	}
+}
+
+// summary describes the MCP server in a line without env and headers
+func (s *MCPServer) summary() string {
+	target := s.URL
+	if s.Transport == MCPTransportStdio {
+		target = strings.TrimSpace(s.Command + " " + strings.Join(s.Args, " "))
+	}
+	summary := string(s.Transport) + " " + target
+	if len(s.AllowedTools) > 0 {
+		summary += " (tools: " + strings.Join(s.AllowedTools, ", ") + ")"
+	}
+	return summary
+}
+
+func (d *VersionDiff) addDelegationChanges(from, to *Delegation) {
+	var fromAgents, toAgents []string
+	if from != nil {
+		fromAgents = from.AgentIDs
+	}
+	if to != nil {
+		toAgents = to.AgentIDs
+	}
+
+	d.addChange("delegation.enabled", strconv.FormatBool(from.IsEnabled()), strconv.FormatBool(to.IsEnabled()))
+	d.addChange("delegation.agent_ids", strings.Join(fromAgents, ", "), strings.Join(toAgents, ", "))
+}
+
+// DiffLines returns the line diff from a to b computed with the longest common subsequence of lines
+func DiffLines(a, b string) []DiffLine {
+	aLines, bLines := splitLines(a), splitLines(b)
+
+	// Common prefix and suffix are trimmed to keep the table small for typical edits
+	prefix := 0
+	for prefix < len(aLines) && prefix < len(bLines) && aLines[prefix] == bLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(aLines)-prefix && suffix < len(bLines)-prefix &&
+		aLines[len(aLines)-1-suffix] == bLines[len(bLines)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0, len(aLines)+len(bLines))
+	for _, line := range aLines[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffOpEqual, Text: line})
+	}
+
+	x, y := aLines[prefix:len(aLines)-suffix], bLines[prefix:len(bLines)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			diff = append(diff, DiffLine{Op: DiffOpEqual, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffOpDelete, Text: x[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffOpInsert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		diff = append(diff, DiffLine{Op: DiffOpDelete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		diff = append(diff, DiffLine{Op: DiffOpInsert, Text: y[j]})
+	}
+
+	for _, line := range aLines[len(aLines)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffOpEqual, Text: line})
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,115 @@
+package agent_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		expected []agent.DiffLine
+	}{
+		{
+			name: "same text",
+			a:    "line1\nline2\n",
+			b:    "line1\nline2",
+			expected: []agent.DiffLine{
+				{Op: agent.DiffOpEqual, Text: "line1"},
+				{Op: agent.DiffOpEqual, Text: "line2"},
+			},
+		},
+		{
+			name: "changed line in the middle",
+			a:    "You are an SRE helper.\nBe polite.\nAnswer in English.",
+			b:    "You are an SRE helper.\nBe terse.\nAnswer in English.",
+			expected: []agent.DiffLine{
+				{Op: agent.DiffOpEqual, Text: "You are an SRE helper."},
+				{Op: agent.DiffOpDelete, Text: "Be polite."},
+				{Op: agent.DiffOpInsert, Text: "Be terse."},
+				{Op: agent.DiffOpEqual, Text: "Answer in English."},
+			},
+		},
+		{
+			name: "moved line",
+			a:    "a\nb\nc",
+			b:    "b\nc\na",
+			expected: []agent.DiffLine{
+				{Op: agent.DiffOpDelete, Text: "a"},
+				{Op: agent.DiffOpEqual, Text: "b"},
+				{Op: agent.DiffOpEqual, Text: "c"},
+				{Op: agent.DiffOpInsert, Text: "a"},
+			},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "new prompt",
+			expected: []agent.DiffLine{
+				{Op: agent.DiffOpInsert, Text: "new prompt"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gt.Equal(t, agent.DiffLines(tc.a, tc.b), tc.expected)
+		})
+	}
+}
+
+func TestDiffVersions(t *testing.T) {
+	from := &agent.AgentVersion{
+		Version:      "1.0.0",
+		SystemPrompt: "You are an SRE helper.",
+		LLMProvider:  types.LLMProviderOpenAI,
+		LLMModel:     "gpt-4o",
+		MCPServers: []*agent.MCPServer{
+			{Name: "github", Transport: agent.MCPTransportHTTP, URL: "https://mcp.example.com/github", Headers: map[string]string{"Authorization": "Bearer old"}},
+			{Name: "local", Transport: agent.MCPTransportStdio, Command: "npx", Args: []string{"server"}},
+		},
+	}
+	to := &agent.AgentVersion{
+		Version:      "1.1.0",
+		SystemPrompt: "You are an SRE helper.",
+		LLMProvider:  types.LLMProviderOpenAI,
+		LLMModel:     "gpt-4.1",
+		MCPServers: []*agent.MCPServer{
+			{Name: "github", Transport: agent.MCPTransportHTTP, URL: "https://mcp.example.com/github", Headers: map[string]string{"Authorization": "Bearer new"}, AllowedTools: []string{"search_issues"}},
+		},
+		Delegation: &agent.Delegation{Enabled: true},
+		Changelog:  "Use the newer model and limit GitHub tools",
+	}
+
+	diff := agent.DiffVersions(from, to)
+	gt.Equal(t, diff.From, "1.0.0")
+	gt.Equal(t, diff.To, "1.1.0")
+	gt.Equal(t, diff.Changelog, "Use the newer model and limit GitHub tools")
+	gt.Equal(t, diff.SystemPrompt, []agent.DiffLine{{Op: agent.DiffOpEqual, Text: "You are an SRE helper."}})
+	gt.Equal(t, diff.Changes, []agent.FieldChange{
+		{Field: "llm_model", From: "gpt-4o", To: "gpt-4.1"},
+		{Field: "mcp_servers.github.allowed_tools", From: "", To: "search_issues"},
+		{Field: "mcp_servers.github.headers.Authorization", From: "********", To: "******** (changed)"},
+		{Field: "mcp_servers.local", From: "stdio npx server", To: ""},
+		{Field: "delegation.enabled", From: "false", To: "true"},
+	})
+
+	gt.True(t, diff.HasChanges())
+
+	// Secrets in headers are never exposed
+	for _, c := range diff.Changes {
+		gt.S(t, c.From).NotContains("Bearer")
+		gt.S(t, c.To).NotContains("Bearer")
+	}
+
+	// Only the changelog differs
+	same := *from
+	same.Version = "1.0.1"
+	same.Changelog = "Same settings"
+	gt.False(t, agent.DiffVersions(from, &same).HasChanges())
+}
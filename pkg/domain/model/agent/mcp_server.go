@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// MCPTransport is the transport used to connect to an MCP server
+type MCPTransport string
+
+const (
+	// MCPTransportStdio launches the server as a local command and talks over stdin/stdout
+	MCPTransportStdio MCPTransport = "stdio"
+	// MCPTransportHTTP connects to the server with Streamable HTTP transport
+	MCPTransportHTTP MCPTransport = "http"
+)
+
+const (
+	// DefaultMCPTimeout is used when MCPServer.TimeoutSeconds is not set
+	DefaultMCPTimeout = 30 * time.Second
+	// maxMCPTimeoutSeconds caps the timeout of a single MCP request
+	maxMCPTimeoutSeconds = 600
+)
+
+// MCP server name is used as tool name prefix, so only characters accepted by LLM providers are allowed
+var mcpServerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// MCPServer is an MCP (Model Context Protocol) server attached to an agent version.
+// Tools provided by the server are registered to the LLM session.
+type MCPServer struct {
+	Name      string       `json:"name" yaml:"name"`
+	Transport MCPTransport `json:"transport" yaml:"transport"`
+
+	// Stdio transport
+	Command string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// HTTP transport
+	URL     string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// AllowedTools limits tools exposed to LLM. All tools are allowed if empty.
+	AllowedTools []string `json:"allowed_tools,omitempty" yaml:"allowed_tools,omitempty"`
+	// TimeoutSeconds is the timeout of connection and each tool call
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" yaml:"timeout_seconds,omitempty"`
+}
+
+// Timeout returns the timeout of connection and each tool call
+func (s *MCPServer) Timeout() time.Duration {
+	if s.TimeoutSeconds <= 0 {
+		return DefaultMCPTimeout
+	}
+	return time.Duration(s.TimeoutSeconds) * time.Second
+}
+
+// IsToolAllowed reports whether the tool can be exposed to LLM
+func (s *MCPServer) IsToolAllowed(name string) bool {
+	return len(s.AllowedTools) == 0 || slices.Contains(s.AllowedTools, name)
+}
+
+// MCPPolicy is the operator policy of MCP servers that agent versions can attach. Anyone who
+// can edit an agent can configure its MCP servers, so stdio servers and HTTP servers in private
+// networks are restricted by the operator.
+type MCPPolicy struct {
+	// AllowedCommands are command lines that stdio MCP servers can launch: an absolute path of
+	// the command followed by arguments separated by spaces. A stdio server is accepted only if
+	// its command and args exactly match one of them, so stdio servers are rejected if empty.
+	AllowedCommands []string
+	// AllowPrivateNetwork allows HTTP MCP servers on loopback and private addresses. Link-local
+	// addresses such as cloud metadata endpoints are never allowed.
+	AllowPrivateNetwork bool
+}
+
+// allowsCommand reports whether the command line is allowed
+func (p MCPPolicy) allowsCommand(command string, args []string) bool {
+	cmdLine := append([]string{command}, args...)
+	for _, allowed := range p.AllowedCommands {
+		if slices.Equal(strings.Fields(allowed), cmdLine) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAddress reports whether an HTTP MCP server at the IP address can be connected
+func (p MCPPolicy) AllowsAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	switch {
+	case addr.IsUnspecified(), addr.IsMulticast(), addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast():
+		return false
+	case addr.IsLoopback(), addr.IsPrivate(), sharedAddressSpace.Contains(addr):
+		return p.AllowPrivateNetwork
+	}
+	return true
+}
+
+// allowsHost reports whether the host of an HTTP MCP server URL is allowed. Only IP addresses
+// and localhost are checked here because other names are resolved when connecting.
+func (p MCPPolicy) allowsHost(host string) bool {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.AllowsAddress(addr)
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return p.AllowPrivateNetwork
+	}
+	return true
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598) that is not covered by IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+var mcpEnvKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// deniedMCPEnvKeys are environment variables that stdio MCP servers cannot set because they
+// change which program runs or make loaders and interpreters run other code
+var deniedMCPEnvKeys = []string{
+	"PATH", "HOME", "TMPDIR", "LANG", "IFS", "SHELL", "SHELLOPTS", "BASHOPTS", "BASH_ENV", "ENV", "PS4",
+	"NODE_OPTIONS", "NODE_PATH", "PYTHONPATH", "PYTHONHOME", "PYTHONSTARTUP", "PYTHONINSPECT",
+	"PERL5LIB", "PERL5OPT", "PERLLIB", "RUBYLIB", "RUBYOPT", "CLASSPATH", "JAVA_TOOL_OPTIONS",
+	"JDK_JAVA_OPTIONS", "_JAVA_OPTIONS", "GCONV_PATH", "LOCPATH", "NLSPATH", "HOSTALIASES",
+}
+
+// deniedMCPEnvPrefixes are prefixes of environment variables of dynamic loaders and package
+// managers that stdio MCP servers cannot set
+var deniedMCPEnvPrefixes = []string{"LD_", "DYLD_", "NPM_CONFIG_", "PIP_", "UV_"}
+
+// IsMCPEnvKeyAllowed reports whether a stdio MCP server can set the environment variable
+func IsMCPEnvKeyAllowed(key string) bool {
+	if !mcpEnvKeyRegex.MatchString(key) {
+		return false
+	}
+	upper := strings.ToUpper(key)
+	if slices.Contains(deniedMCPEnvKeys, upper) {
+		return false
+	}
+	for _, prefix := range deniedMCPEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateOption is an option of validation of agent versions and MCP servers
+type ValidateOption func(*validateConfig)
+
+type validateConfig struct {
+	mcpPolicy MCPPolicy
+}
+
+// WithMCPPolicy sets the operator policy of MCP servers. Without it, stdio servers and HTTP
+// servers in private networks are rejected.
+func WithMCPPolicy(policy MCPPolicy) ValidateOption {
+	return func(cfg *validateConfig) {
+		cfg.mcpPolicy = policy
+	}
+}
+
+func newValidateConfig(opts []ValidateOption) *validateConfig {
+	cfg := &validateConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Validate validates the MCPServer
+func (s *MCPServer) Validate(opts ...ValidateOption) error {
+	cfg := newValidateConfig(opts)
+
+	if !mcpServerNameRegex.MatchString(s.Name) {
+		return goerr.New("MCP server name format is invalid",
+			goerr.V("format", "1-32 alphanumeric characters, '_' or '-'"),
+			goerr.V("name", s.Name))
+	}
+
+	switch s.Transport {
+	case MCPTransportStdio:
+		if s.Command == "" {
+			return goerr.New("command is required for stdio MCP server", goerr.V("name", s.Name))
+		}
+		if !cfg.mcpPolicy.allowsCommand(s.Command, s.Args) {
+			return goerr.New("command of stdio MCP server is not allowed",
+				goerr.V("name", s.Name),
+				goerr.V("command", s.Command),
+				goerr.V("args", s.Args),
+				goerr.V("allowed", cfg.mcpPolicy.AllowedCommands))
+		}
+		for key := range s.Env {
+			if !IsMCPEnvKeyAllowed(key) {
+				return goerr.New("environment variable of stdio MCP server is not allowed",
+					goerr.V("name", s.Name),
+					goerr.V("key", key))
+			}
+		}
+	case MCPTransportHTTP:
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return goerr.New("valid http(s) URL is required for http MCP server",
+				goerr.V("name", s.Name),
+				goerr.V("url", s.URL))
+		}
+		if !cfg.mcpPolicy.allowsHost(u.Hostname()) {
+			return goerr.New("host of http MCP server is not allowed",
+				goerr.V("name", s.Name),
+				goerr.V("host", u.Hostname()))
+		}
+	default:
+		return goerr.New("invalid MCP transport",
+			goerr.V("name", s.Name),
+			goerr.V("transport", s.Transport))
+	}
+
+	if s.TimeoutSeconds < 0 || s.TimeoutSeconds > maxMCPTimeoutSeconds {
+		return goerr.New("MCP timeout is out of range",
+			goerr.V("name", s.Name),
+			goerr.V("timeout_seconds", s.TimeoutSeconds),
+			goerr.V("max", maxMCPTimeoutSeconds))
+	}
+
+	return nil
+}
+
+// ValidateMCPServers validates MCP servers and ensures their names are unique
+func ValidateMCPServers(servers []*MCPServer, opts ...ValidateOption) error {
+	names := make(map[string]struct{}, len(servers))
+	for _, server := range servers {
+		if server == nil {
+			return goerr.New("MCP server cannot be nil")
+		}
+		if err := server.Validate(opts...); err != nil {
+			return err
+		}
+		if _, exists := names[server.Name]; exists {
+			return goerr.New("MCP server name is duplicated", goerr.V("name", server.Name))
+		}
+		names[server.Name] = struct{}{}
+	}
+	return nil
+}
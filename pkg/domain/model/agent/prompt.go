@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+const (
+	// maxPromptVariables caps the number of custom variables of an agent version
+	maxPromptVariables = 50
+
+	// maxPromptVariableValueLength caps the length of a value of a custom variable
+	maxPromptVariableValueLength = 5000
+)
+
+// promptVariableKeyRegex allows keys that can be referred as {{.Vars.key}} in templates
+var promptVariableKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// PromptContext is the runtime context to render the system prompt of an agent version. The
+// system prompt is a Go template, and fields are referred as {{.User.Name}} or {{.Vars.team}}.
+// The user and the channel are looked up only when the template refers to them.
+type PromptContext struct {
+	Agent PromptAgent
+	Vars  map[string]string // Custom variables of the agent version
+
+	now         time.Time
+	user        *PromptUser
+	loadUser    func() PromptUser
+	channel     *PromptChannel
+	loadChannel func() PromptChannel
+}
+
+// PromptUser is the user who mentioned the agent
+type PromptUser struct {
+	ID       string
+	Name     string
+	Email    string
+	TimeZone string // IANA time zone of the user, e.g. Asia/Tokyo
+}
+
+// PromptChannel is the Slack channel where the agent was mentioned
+type PromptChannel struct {
+	ID   string
+	Name string
+	Type string // public, private, im or mpim
+}
+
+// PromptAgent is the agent that answers
+type PromptAgent struct {
+	ID      string
+	Name    string
+	Version string
+}
+
+// NewPromptContext creates a PromptContext at the time
+func NewPromptContext(now time.Time) *PromptContext {
+	return &PromptContext{
+		now:  now,
+		Vars: map[string]string{},
+	}
+}
+
+// SamplePromptContext returns a PromptContext with sample values to preview and validate templates
+func SamplePromptContext(agentID, name, version string, vars map[string]string) *PromptContext {
+	pc := NewPromptContext(time.Now())
+	pc.SetUser(PromptUser{ID: "U0123456789", Name: "Sample User", Email: "sample.user@example.com"})
+	pc.SetChannel(PromptChannel{ID: "C0123456789", Name: "general", Type: "public"})
+	pc.Agent = PromptAgent{ID: agentID, Name: name, Version: version}
+	if vars != nil {
+		pc.Vars = vars
+	}
+	return pc
+}
+
+// SetUser sets the user who mentioned the agent
+func (pc *PromptContext) SetUser(user PromptUser) {
+	pc.user = &user
+}
+
+// SetUserLoader sets the function to look up the user when the template refers to the user or
+// the time, which is in the time zone of the user
+func (pc *PromptContext) SetUserLoader(load func() PromptUser) {
+	pc.user = nil
+	pc.loadUser = load
+}
+
+// SetChannel sets the channel where the agent was mentioned
+func (pc *PromptContext) SetChannel(channel PromptChannel) {
+	pc.channel = &channel
+}
+
+// SetChannelLoader sets the function to look up the channel when the template refers to it
+func (pc *PromptContext) SetChannelLoader(load func() PromptChannel) {
+	pc.channel = nil
+	pc.loadChannel = load
+}
+
+// User returns the user who mentioned the agent, as {{.User}}
+func (pc *PromptContext) User() PromptUser {
+	if pc.user == nil {
+		var user PromptUser
+		if pc.loadUser != nil {
+			user = pc.loadUser()
+		}
+		pc.user = &user
+	}
+	return *pc.user
+}
+
+// Channel returns the channel where the agent was mentioned, as {{.Channel}}
+func (pc *PromptContext) Channel() PromptChannel {
+	if pc.channel == nil {
+		var channel PromptChannel
+		if pc.loadChannel != nil {
+			channel = pc.loadChannel()
+		}
+		pc.channel = &channel
+	}
+	return *pc.channel
+}
+
+// Now returns the current time in the time zone of the user, as {{.Now}}. The time zone of the
+// server is used if the user has no valid time zone.
+func (pc *PromptContext) Now() time.Time {
+	if tz := pc.User().TimeZone; tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return pc.now.In(loc)
+		}
+	}
+	return pc.now
+}
+
+// Date returns the current date in YYYY-MM-DD, as {{.Date}}
+func (pc *PromptContext) Date() string {
+	return pc.Now().Format("2006-01-02")
+}
+
+// Time returns the current time in HH:MM, as {{.Time}}
+func (pc *PromptContext) Time() string {
+	return pc.Now().Format("15:04")
+}
+
+// TimeZone returns the name of the time zone of {{.Now}}, as {{.TimeZone}}
+func (pc *PromptContext) TimeZone() string {
+	now := pc.Now()
+	if name := now.Location().String(); name != "" && name != "Local" {
+		return name
+	}
+	zone, _ := now.Zone()
+	return zone
+}
+
+// RenderSystemPrompt renders the system prompt template with the context. Undefined custom
+// variables are rendered as empty strings.
+func RenderSystemPrompt(prompt string, pc *PromptContext) (string, error) {
+	tmpl, err := template.New("system_prompt").Option("missingkey=zero").Parse(prompt)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to parse system prompt template")
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, pc); err != nil {
+		return "", goerr.Wrap(err, "failed to render system prompt template")
+	}
+	return b.String(), nil
+}
+
+// ValidateSystemPrompt validates the system prompt template by rendering it with a sample context
+func ValidateSystemPrompt(prompt string, vars map[string]string) error {
+	if _, err := RenderSystemPrompt(prompt, SamplePromptContext("sample-agent", "Sample Agent", "1.0.0", vars)); err != nil {
+		return goerr.Wrap(err, "invalid system prompt template")
+	}
+	return nil
+}
+
+// ValidatePromptVariables validates custom variables of an agent version
+func ValidatePromptVariables(vars map[string]string) error {
+	if len(vars) > maxPromptVariables {
+		return goerr.New("too many prompt variables",
+			goerr.V("count", len(vars)),
+			goerr.V("max", maxPromptVariables))
+	}
+
+	for key, value := range vars {
+		if !promptVariableKeyRegex.MatchString(key) {
+			return goerr.New("prompt variable key format is invalid",
+				goerr.V("format", "alphanumeric characters and '_' not starting with a digit"),
+				goerr.V("key", key))
+		}
+		if len(value) > maxPromptVariableValueLength {
+			return goerr.New("prompt variable value is too long",
+				goerr.V("key", key),
+				goerr.V("max", maxPromptVariableValueLength))
+		}
+	}
+	return nil
+}
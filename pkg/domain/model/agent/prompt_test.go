@@ -0,0 +1,184 @@
+package agent_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+)
+
+func TestRenderSystemPrompt(t *testing.T) {
+	now := time.Date(2025, 4, 1, 9, 30, 0, 0, time.UTC)
+	pc := agent.NewPromptContext(now)
+	pc.SetUser(agent.PromptUser{ID: "U001", Name: "Alice", Email: "alice@example.com"})
+	pc.SetChannel(agent.PromptChannel{ID: "C001", Name: "sre", Type: "private"})
+	pc.Agent = agent.PromptAgent{ID: "sre-helper", Name: "SRE Helper", Version: "1.2.0"}
+	pc.Vars = map[string]string{"team": "platform"}
+
+	testCases := []struct {
+		name      string
+		prompt    string
+		expected  string
+		shouldErr bool
+	}{
+		{
+			name:     "plain prompt is returned as is",
+			prompt:   "You are a helpful assistant.",
+			expected: "You are a helpful assistant.",
+		},
+		{
+			name:     "date and time",
+			prompt:   "Today is {{.Date}} {{.Time}} ({{.TimeZone}}).",
+			expected: "Today is 2025-04-01 09:30 (UTC).",
+		},
+		{
+			name:     "user, channel and agent",
+			prompt:   "{{.Agent.Name}} v{{.Agent.Version}} talks with {{.User.Name}} <{{.User.Email}}> in #{{.Channel.Name}} ({{.Channel.Type}}).",
+			expected: "SRE Helper v1.2.0 talks with Alice <alice@example.com> in #sre (private).",
+		},
+		{
+			name:     "user in with block",
+			prompt:   "{{with .User}}Hello {{.Name}}.{{end}}",
+			expected: "Hello Alice.",
+		},
+		{
+			name:     "custom variables",
+			prompt:   "Team: {{.Vars.team}}",
+			expected: "Team: platform",
+		},
+		{
+			name:     "undefined custom variable is empty",
+			prompt:   "Owner: {{.Vars.owner}}",
+			expected: "Owner: ",
+		},
+		{
+			name:      "syntax error",
+			prompt:    "Hello {{.User.Name",
+			shouldErr: true,
+		},
+		{
+			name:      "unknown field",
+			prompt:    "Hello {{.Unknown}}",
+			shouldErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := agent.RenderSystemPrompt(tc.prompt, pc)
+			if tc.shouldErr {
+				gt.Error(t, err)
+				return
+			}
+			gt.NoError(t, err)
+			gt.Equal(t, result, tc.expected)
+		})
+	}
+}
+
+func TestPromptContextLoaders(t *testing.T) {
+	now := time.Date(2025, 4, 1, 9, 30, 0, 0, time.UTC)
+
+	newContext := func() (*agent.PromptContext, *int, *int) {
+		var userCalls, channelCalls int
+		pc := agent.NewPromptContext(now)
+		pc.SetUserLoader(func() agent.PromptUser {
+			userCalls++
+			return agent.PromptUser{ID: "U001", Name: "Alice", TimeZone: "Asia/Tokyo"}
+		})
+		pc.SetChannelLoader(func() agent.PromptChannel {
+			channelCalls++
+			return agent.PromptChannel{ID: "C001", Name: "sre"}
+		})
+		return pc, &userCalls, &channelCalls
+	}
+
+	t.Run("not loaded without reference", func(t *testing.T) {
+		pc, userCalls, channelCalls := newContext()
+		result, err := agent.RenderSystemPrompt("You are {{.Agent.Name}}.", pc)
+		gt.NoError(t, err)
+		gt.Equal(t, result, "You are .")
+		gt.Equal(t, *userCalls, 0)
+		gt.Equal(t, *channelCalls, 0)
+	})
+
+	t.Run("loaded once", func(t *testing.T) {
+		pc, userCalls, channelCalls := newContext()
+		result, err := agent.RenderSystemPrompt("{{.User.Name}} ({{.User.ID}}) in #{{.Channel.Name}}", pc)
+		gt.NoError(t, err)
+		gt.Equal(t, result, "Alice (U001) in #sre")
+		gt.Equal(t, *userCalls, 1)
+		gt.Equal(t, *channelCalls, 1)
+	})
+
+	t.Run("time in time zone of user", func(t *testing.T) {
+		pc, _, channelCalls := newContext()
+		result, err := agent.RenderSystemPrompt("{{.Date}} {{.Time}} {{.TimeZone}}", pc)
+		gt.NoError(t, err)
+		gt.Equal(t, result, "2025-04-01 18:30 Asia/Tokyo")
+		gt.Equal(t, *channelCalls, 0)
+	})
+
+	t.Run("server time zone for unknown time zone", func(t *testing.T) {
+		pc := agent.NewPromptContext(now)
+		pc.SetUser(agent.PromptUser{ID: "U001", TimeZone: "Nowhere/Unknown"})
+		result, err := agent.RenderSystemPrompt("{{.Time}} {{.TimeZone}}", pc)
+		gt.NoError(t, err)
+		gt.Equal(t, result, "09:30 UTC")
+	})
+}
+
+func TestValidatePromptVariables(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := range 51 {
+		tooMany["key_"+strings.Repeat("x", i)] = "v"
+	}
+
+	testCases := []struct {
+		name      string
+		vars      map[string]string
+		shouldErr bool
+	}{
+		{name: "nil", vars: nil},
+		{name: "valid keys", vars: map[string]string{"team": "sre", "_internal": "x", "oncall2": "bob"}},
+		{name: "key starting with digit", vars: map[string]string{"2team": "sre"}, shouldErr: true},
+		{name: "key with hyphen", vars: map[string]string{"on-call": "bob"}, shouldErr: true},
+		{name: "too long value", vars: map[string]string{"doc": strings.Repeat("a", 5001)}, shouldErr: true},
+		{name: "too many variables", vars: tooMany, shouldErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := agent.ValidatePromptVariables(tc.vars)
+			if tc.shouldErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateSystemPrompt(t *testing.T) {
+	gt.NoError(t, agent.ValidateSystemPrompt("You help {{.User.Name}} in {{.Vars.team}}.", map[string]string{"team": "sre"}))
+	gt.Error(t, agent.ValidateSystemPrompt("You help {{.User.Nickname}}.", nil))
+	gt.Error(t, agent.ValidateSystemPrompt("You help {{if .User.Name}}.", nil))
+}
+
+func TestValidateAgentVersionPromptTemplate(t *testing.T) {
+	version := &agent.AgentVersion{
+		AgentUUID:    "550e8400-e29b-41d4-a716-446655440000",
+		Version:      "1.0.0",
+		SystemPrompt: "Answer in JSON like {{\"status\": \"ok\"}}.",
+		LLMProvider:  "openai",
+		LLMModel:     "gpt-4o",
+	}
+
+	// Braces in prompts are text unless templates are enabled
+	gt.NoError(t, agent.ValidateAgentVersion(version))
+
+	version.PromptTemplate = true
+	gt.Error(t, agent.ValidateAgentVersion(version))
+}
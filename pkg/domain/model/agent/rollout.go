@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+// Rollout routes a percentage of new threads to a candidate version instead of the latest version
+// of the agent, e.g. to try a prompt change on a busy channel. Threads keep the version selected
+// when they started.
+type Rollout struct {
+	CandidateVersion string    `json:"candidate_version"`
+	Percentage       int       `json:"percentage"`
+	StartedAt        time.Time `json:"started_at"`
+}
+
+// Validate validates the Rollout
+func (r *Rollout) Validate() error {
+	if err := ValidateVersion(r.CandidateVersion); err != nil {
+		return goerr.Wrap(err, "invalid candidate version")
+	}
+	if r.Percentage < 1 || r.Percentage > 100 {
+		return goerr.New("rollout percentage must be between 1 and 100", goerr.V("percentage", r.Percentage))
+	}
+	return nil
+}
+
+// SelectVersion returns the version of the agent for a new thread identified by key, the candidate
+// version for Percentage percent of keys and latest for others. The same key always gets the same
+// version. The agent and the candidate version are hashed with the key, so that the same threads
+// are not always the first to get candidates of all agents. It returns latest if the rollout is nil.
+func (r *Rollout) SelectVersion(agentUUID types.UUID, latest, key string) string {
+	if r == nil || r.CandidateVersion == "" {
+		return latest
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(agentUUID.String() + "/" + r.CandidateVersion + "/" + key))
+	if int(h.Sum32()%100) < r.Percentage {
+		return r.CandidateVersion
+	}
+	return latest
+}
+
+// VersionMetrics is the counters of a version of an agent to compare versions during a rollout
+type VersionMetrics struct {
+	AgentUUID types.UUID `json:"agent_uuid"`
+	Version   string     `json:"version"`
+
+	// Threads is the number of new threads started with the version
+	Threads int64 `json:"threads"`
+	// Responses and Errors are the numbers of turns answered or failed by the version
+	Responses int64 `json:"responses"`
+	Errors    int64 `json:"errors"`
+	// TotalLatencyMS is the sum of time to answer turns, including failed ones
+	TotalLatencyMS int64 `json:"total_latency_ms"`
+
+	PositiveFeedback int64 `json:"positive_feedback"`
+	NegativeFeedback int64 `json:"negative_feedback"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AverageLatencyMS returns the average time to answer a turn, or 0 if there is no turn
+func (m *VersionMetrics) AverageLatencyMS() int64 {
+	turns := m.Responses + m.Errors
+	if turns == 0 {
+		return 0
+	}
+	return m.TotalLatencyMS / turns
+}
+
+// Add adds the counters of delta
+func (m *VersionMetrics) Add(delta *VersionMetrics) {
+	m.Threads += delta.Threads
+	m.Responses += delta.Responses
+	m.Errors += delta.Errors
+	m.TotalLatencyMS += delta.TotalLatencyMS
+	m.PositiveFeedback += delta.PositiveFeedback
+	m.NegativeFeedback += delta.NegativeFeedback
+}
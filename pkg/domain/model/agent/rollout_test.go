@@ -0,0 +1,111 @@
+package agent_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+func TestRolloutSelectVersion(t *testing.T) {
+	agentUUID := types.UUID("3f1c8a52-7d9e-4b6a-9c21-5e8f0a7b4d13")
+
+	var nilRollout *agent.Rollout
+	gt.Equal(t, nilRollout.SelectVersion(agentUUID, "1.0.0", "C123/1700000000.000100"), "1.0.0")
+
+	full := &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 100}
+	gt.Equal(t, full.SelectVersion(agentUUID, "1.0.0", "C123/1700000000.000100"), "1.1.0")
+
+	rollout := &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 20}
+	candidates := 0
+	for i := range 1000 {
+		key := fmt.Sprintf("C123/1700000000.%06d", i)
+		selected := rollout.SelectVersion(agentUUID, "1.0.0", key)
+		// The same thread always gets the same version
+		gt.Equal(t, rollout.SelectVersion(agentUUID, "1.0.0", key), selected)
+		if selected == "1.1.0" {
+			candidates++
+		}
+	}
+	gt.True(t, candidates > 100 && candidates < 300)
+}
+
+func TestRolloutSelectVersionVariesByAgentAndCandidate(t *testing.T) {
+	agentA := types.UUID("3f1c8a52-7d9e-4b6a-9c21-5e8f0a7b4d13")
+	agentB := types.UUID("a9d27e64-0b3f-4c58-8e71-2f6c9d1a5b80")
+	rollout := &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 20}
+	nextRollout := &agent.Rollout{CandidateVersion: "1.2.0", Percentage: 20}
+
+	// Threads getting the candidate differ among agents and candidate versions
+	diffAgents, diffCandidates := 0, 0
+	for i := range 1000 {
+		key := fmt.Sprintf("C123/1700000000.%06d", i)
+		selected := rollout.SelectVersion(agentA, "1.0.0", key) == "1.1.0"
+		if selected != (rollout.SelectVersion(agentB, "1.0.0", key) == "1.1.0") {
+			diffAgents++
+		}
+		if selected != (nextRollout.SelectVersion(agentA, "1.0.0", key) == "1.2.0") {
+			diffCandidates++
+		}
+	}
+	gt.True(t, diffAgents > 0)
+	gt.True(t, diffCandidates > 0)
+}
+
+func TestRolloutValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rollout   *agent.Rollout
+		shouldErr bool
+	}{
+		{
+			name:    "valid",
+			rollout: &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 10},
+		},
+		{
+			name:    "all threads",
+			rollout: &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 100},
+		},
+		{
+			name:      "zero percent",
+			rollout:   &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 0},
+			shouldErr: true,
+		},
+		{
+			name:      "over 100 percent",
+			rollout:   &agent.Rollout{CandidateVersion: "1.1.0", Percentage: 101},
+			shouldErr: true,
+		},
+		{
+			name:      "invalid version",
+			rollout:   &agent.Rollout{CandidateVersion: "latest", Percentage: 10},
+			shouldErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rollout.Validate()
+			if tc.shouldErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVersionMetricsAverageLatency(t *testing.T) {
+	metrics := &agent.VersionMetrics{}
+	gt.Equal(t, metrics.AverageLatencyMS(), int64(0))
+
+	metrics.Add(&agent.VersionMetrics{Threads: 1, Responses: 1, TotalLatencyMS: 1200})
+	metrics.Add(&agent.VersionMetrics{Errors: 1, TotalLatencyMS: 300, NegativeFeedback: 1})
+	gt.Equal(t, metrics.Threads, int64(1))
+	gt.Equal(t, metrics.Responses, int64(1))
+	gt.Equal(t, metrics.Errors, int64(1))
+	gt.Equal(t, metrics.NegativeFeedback, int64(1))
+	gt.Equal(t, metrics.AverageLatencyMS(), int64(750))
+}
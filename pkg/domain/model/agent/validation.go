@@ -80,7 +80,7 @@ func ValidateAgent(agent *Agent) error {
 }
 
 // ValidateAgentVersion validates the AgentVersion struct
-func ValidateAgentVersion(version *AgentVersion) error {
+func ValidateAgentVersion(version *AgentVersion, opts ...ValidateOption) error {
 	if err := ValidateVersion(version.Version); err != nil {
 		return goerr.Wrap(err, "invalid version")
 	}
@@ -100,5 +100,35 @@ func ValidateAgentVersion(version *AgentVersion) error {
 		return goerr.New("system prompt cannot be longer than 50000 characters")
 	}
 
+	if len(version.Changelog) > 5000 {
+		return goerr.New("changelog cannot be longer than 5000 characters")
+	}
+
+	if err := ValidatePromptVariables(version.PromptVariables); err != nil {
+		return goerr.Wrap(err, "invalid prompt variables")
+	}
+
+	if version.PromptTemplate {
+		if err := ValidateSystemPrompt(version.SystemPrompt, version.PromptVariables); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateMCPServers(version.MCPServers, opts...); err != nil {
+		return goerr.Wrap(err, "invalid MCP servers")
+	}
+
+	if version.Delegation != nil {
+		if err := version.Delegation.Validate(); err != nil {
+			return goerr.Wrap(err, "invalid delegation")
+		}
+	}
+
+	switch version.Status {
+	case "", VersionStatusDraft, VersionStatusPublished:
+	default:
+		return goerr.New("invalid version status", goerr.V("status", version.Status))
+	}
+
 	return nil
 }
@@ -6,12 +6,44 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
 )
 
+// VersionStatus represents whether a version of an agent is in production
+type VersionStatus string
+
+const (
+	// VersionStatusDraft is a version that is usable only by its author until it is published
+	VersionStatusDraft VersionStatus = "draft"
+	// VersionStatusPublished is a version that can be the latest version. Versions created
+	// before drafts were introduced have empty status and are treated as published.
+	VersionStatusPublished VersionStatus = "published"
+)
+
 type AgentVersion struct {
-	AgentUUID    types.UUID        `json:"agent_uuid"`
-	Version      string            `json:"version"`
-	SystemPrompt string            `json:"system_prompt"`
-	LLMProvider  types.LLMProvider `json:"llm_provider"`
-	LLMModel     string            `json:"llm_model"`
-	CreatedAt    time.Time         `json:"created_at"`
-	UpdatedAt    time.Time         `json:"updated_at"`
+	AgentUUID    types.UUID `json:"agent_uuid"`
+	Version      string     `json:"version"`
+	SystemPrompt string     `json:"system_prompt"`
+	// PromptTemplate renders SystemPrompt as a Go template with PromptContext. Otherwise the
+	// system prompt is used as is, as by versions created before templates were supported.
+	PromptTemplate  bool              `json:"prompt_template,omitempty"`
+	PromptVariables map[string]string `json:"prompt_variables,omitempty"`
+	LLMProvider     types.LLMProvider `json:"llm_provider"`
+	LLMModel        string            `json:"llm_model"`
+	MCPServers      []*MCPServer      `json:"mcp_servers,omitempty"`
+	Delegation      *Delegation       `json:"delegation,omitempty"`
+	Status          VersionStatus     `json:"status,omitempty"`
+	Author          types.UserID      `json:"author,omitempty"` // User who created the version
+	PublishedAt     *time.Time        `json:"published_at,omitempty"`
+	Changelog       string            `json:"changelog,omitempty"` // Note of what changed and why
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// IsDraft reports whether the version is a draft
+func (v *AgentVersion) IsDraft() bool {
+	return v.Status == VersionStatusDraft
+}
+
+// IsUsableBy reports whether the user can talk to the version. Drafts are usable only by their
+// author, and published versions by anyone.
+func (v *AgentVersion) IsUsableBy(userID types.UserID) bool {
+	return !v.IsDraft() || (v.Author != "" && v.Author == userID)
 }
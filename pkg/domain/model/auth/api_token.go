@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// APITokenPrefix makes API tokens recognizable, e.g. by secret scanners
+	APITokenPrefix = "tmm_"
+
+	// MaxAPITokenNameLength is the maximum length of the name of an API token
+	MaxAPITokenNameLength = 100
+
+	// apiTokenSessionTTL is the lifetime of the session built from an API token for a request
+	apiTokenSessionTTL = time.Hour
+)
+
+// ErrAPITokenNotFound is returned when no API token matches
+var ErrAPITokenNotFound = errors.New("API token not found")
+
+// APIToken is a long-lived credential of a user for the chat API. Only the hash of the token is
+// stored, and the token itself is shown once when it is created.
+type APIToken struct {
+	ID         types.UUID   `json:"id" firestore:"id"`
+	Name       string       `json:"name" firestore:"name"`
+	TokenHash  string       `json:"-" firestore:"token_hash"`
+	UserID     types.UserID `json:"user_id" firestore:"user_id"`
+	UserName   string       `json:"user_name" firestore:"user_name"`
+	Email      string       `json:"email" firestore:"email"`
+	TeamID     string       `json:"team_id" firestore:"team_id"`
+	TeamName   string       `json:"team_name" firestore:"team_name"`
+	CreatedAt  time.Time    `json:"created_at" firestore:"created_at"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" firestore:"last_used_at"`
+}
+
+// NewAPIToken creates an API token of the user of the session and returns it with the token
+func NewAPIToken(ctx context.Context, session *Session, name string) (*APIToken, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", goerr.Wrap(err, "failed to generate API token")
+	}
+	token := APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	return &APIToken{
+		ID:        types.NewUUID(ctx),
+		Name:      name,
+		TokenHash: HashAPIToken(token),
+		UserID:    session.UserID,
+		UserName:  session.UserName,
+		Email:     session.Email,
+		TeamID:    session.TeamID,
+		TeamName:  session.TeamName,
+		CreatedAt: time.Now(),
+	}, token, nil
+}
+
+// HashAPIToken returns the hash of the token to look up the API token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken returns true if the value looks like an API token
+func IsAPIToken(value string) bool {
+	return strings.HasPrefix(value, APITokenPrefix)
+}
+
+// Validate checks if the API token has valid fields
+func (t *APIToken) Validate() error {
+	if !t.ID.IsValid() {
+		return goerr.New("invalid API token ID", goerr.V("id", t.ID))
+	}
+	if t.Name == "" {
+		return goerr.New("API token name is required")
+	}
+	if len(t.Name) > MaxAPITokenNameLength {
+		return goerr.New("API token name is too long", goerr.V("length", len(t.Name)), goerr.V("max", MaxAPITokenNameLength))
+	}
+	if t.TokenHash == "" {
+		return goerr.New("API token hash is required", goerr.V("id", t.ID))
+	}
+	if !t.UserID.IsValid() || t.TeamID == "" {
+		return goerr.New("user of API token is required", goerr.V("id", t.ID))
+	}
+	return nil
+}
+
+// VerifyToken checks the token against the stored hash in constant time
+func (t *APIToken) VerifyToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIToken(token)), []byte(t.TokenHash)) == 1
+}
+
+// Session builds the session of the user of the API token for a request
+func (t *APIToken) Session() *Session {
+	now := time.Now()
+	return &Session{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		UserName:  t.UserName,
+		Email:     t.Email,
+		TeamID:    t.TeamID,
+		TeamName:  t.TeamName,
+		ExpiresAt: now.Add(apiTokenSessionTTL),
+		CreatedAt: now,
+	}
+}
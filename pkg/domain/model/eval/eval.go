@@ -0,0 +1,270 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// MaxInputLength is the maximum length of the input message of a case in characters
+	MaxInputLength = 8000
+
+	// MaxRubricLength is the maximum length of the rubric of a case in characters
+	MaxRubricLength = 4000
+
+	// MaxExpectations is the maximum number of expected facts or patterns of a case
+	MaxExpectations = 20
+
+	// MaxOutputLength is the maximum length of the output of the agent stored in a result. Longer
+	// outputs are checked as is and truncated when they are stored.
+	MaxOutputLength = 16000
+)
+
+var (
+	// ErrCaseNotFound is returned when the test case does not exist
+	ErrCaseNotFound = errors.New("eval case not found")
+
+	// ErrRunNotFound is returned when the eval run does not exist
+	ErrRunNotFound = errors.New("eval run not found")
+)
+
+// Case is a golden test case of an agent. The output of the agent for Input passes if it contains
+// all ExpectedFacts, matches all ExpectedPatterns, and satisfies Rubric judged by LLM.
+type Case struct {
+	ID        types.UUID `json:"id"`
+	AgentUUID types.UUID `json:"agent_uuid"`
+	Name      string     `json:"name"`
+	Input     string     `json:"input"`
+	// ExpectedFacts are phrases the output must contain, case-insensitively
+	ExpectedFacts []string `json:"expected_facts"`
+	// ExpectedPatterns are regular expressions the output must match
+	ExpectedPatterns []string `json:"expected_patterns"`
+	// Rubric is the criteria an LLM judges the output with. It is not judged if empty.
+	Rubric    string    `json:"rubric"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCase creates a new Case of the agent
+func NewCase(ctx context.Context, agentUUID types.UUID) *Case {
+	now := time.Now()
+	return &Case{
+		ID:        types.NewUUID(ctx),
+		AgentUUID: agentUUID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate validates the case
+func (c *Case) Validate() error {
+	if !c.ID.IsValid() {
+		return goerr.New("invalid eval case ID", goerr.V("id", c.ID))
+	}
+	if !c.AgentUUID.IsValid() {
+		return goerr.New("invalid agent UUID of eval case", goerr.V("agent_uuid", c.AgentUUID))
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return goerr.New("name of eval case is required")
+	}
+	if strings.TrimSpace(c.Input) == "" {
+		return goerr.New("input of eval case is required")
+	}
+	if utf8.RuneCountInString(c.Input) > MaxInputLength {
+		return goerr.New("input of eval case is too long",
+			goerr.V("length", utf8.RuneCountInString(c.Input)),
+			goerr.V("max", MaxInputLength))
+	}
+	if utf8.RuneCountInString(c.Rubric) > MaxRubricLength {
+		return goerr.New("rubric of eval case is too long",
+			goerr.V("length", utf8.RuneCountInString(c.Rubric)),
+			goerr.V("max", MaxRubricLength))
+	}
+	if len(c.ExpectedFacts) > MaxExpectations || len(c.ExpectedPatterns) > MaxExpectations {
+		return goerr.New("too many expectations of eval case", goerr.V("max", MaxExpectations))
+	}
+	if len(c.ExpectedFacts) == 0 && len(c.ExpectedPatterns) == 0 && strings.TrimSpace(c.Rubric) == "" {
+		return goerr.New("eval case requires expected facts, patterns or rubric")
+	}
+	for _, fact := range c.ExpectedFacts {
+		if strings.TrimSpace(fact) == "" {
+			return goerr.New("expected fact of eval case is empty")
+		}
+	}
+	for _, pattern := range c.ExpectedPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return goerr.Wrap(err, "invalid expected pattern of eval case", goerr.V("pattern", pattern))
+		}
+	}
+	return nil
+}
+
+// Check returns the reasons the output fails the expected facts and patterns of the case. The
+// rubric is not checked because it requires LLM.
+func (c *Case) Check(output string) []string {
+	var failures []string
+
+	lower := strings.ToLower(output)
+	for _, fact := range c.ExpectedFacts {
+		if !strings.Contains(lower, strings.ToLower(fact)) {
+			failures = append(failures, fmt.Sprintf("missing fact: %q", fact))
+		}
+	}
+
+	for _, pattern := range c.ExpectedPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid pattern: %q", pattern))
+			continue
+		}
+		if !re.MatchString(output) {
+			failures = append(failures, fmt.Sprintf("pattern not matched: %q", pattern))
+		}
+	}
+
+	return failures
+}
+
+// RunStatus is the status of an eval run
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusCompleted RunStatus = "completed"
+	// RunStatusFailed means the suite could not be run, not that cases failed
+	RunStatusFailed RunStatus = "failed"
+)
+
+// Run is an execution of the test cases of an agent against a version of the agent
+type Run struct {
+	ID           types.UUID    `json:"id"`
+	AgentUUID    types.UUID    `json:"agent_uuid"`
+	AgentVersion string        `json:"agent_version"`
+	Status       RunStatus     `json:"status"`
+	Passed       int           `json:"passed"`
+	Failed       int           `json:"failed"`
+	Results      []*CaseResult `json:"results"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   *time.Time    `json:"finished_at,omitempty"`
+	Error        string        `json:"error"`
+}
+
+// CaseResult is the result of a case in a run. Name and input are copied from the case, so that
+// the result is kept as it was after the case is changed.
+type CaseResult struct {
+	CaseID   types.UUID `json:"case_id"`
+	CaseName string     `json:"case_name"`
+	Input    string     `json:"input"`
+	Output   string     `json:"output"`
+	Passed   bool       `json:"passed"`
+	// Failures are the reasons the case failed
+	Failures []string `json:"failures"`
+	// JudgeReason is the explanation of the LLM judge if the case has a rubric
+	JudgeReason string `json:"judge_reason"`
+	// Error is set if the agent failed to answer
+	Error      string `json:"error"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// NewRun creates a new running Run of the version of the agent
+func NewRun(ctx context.Context, agentUUID types.UUID, agentVersion string) *Run {
+	return &Run{
+		ID:           types.NewUUID(ctx),
+		AgentUUID:    agentUUID,
+		AgentVersion: agentVersion,
+		Status:       RunStatusRunning,
+		StartedAt:    time.Now(),
+	}
+}
+
+// AddResult adds the result of a case to the run
+func (r *Run) AddResult(result *CaseResult) {
+	if utf8.RuneCountInString(result.Output) > MaxOutputLength {
+		result.Output = string([]rune(result.Output)[:MaxOutputLength])
+	}
+
+	r.Results = append(r.Results, result)
+	if result.Passed {
+		r.Passed++
+	} else {
+		r.Failed++
+	}
+}
+
+// Finish sets the result of the run. The run fails if err is not nil.
+func (r *Run) Finish(err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Status = RunStatusCompleted
+	if err != nil {
+		r.Status = RunStatusFailed
+		r.Error = err.Error()
+	}
+}
+
+// Result returns the result of the case in the run, or nil if the case was not run
+func (r *Run) Result(caseID types.UUID) *CaseResult {
+	for _, result := range r.Results {
+		if result.CaseID == caseID {
+			return result
+		}
+	}
+	return nil
+}
+
+// CaseComparison is the results of a case in two runs side by side. Base or Target is nil if the
+// case was not run in the run.
+type CaseComparison struct {
+	CaseID   types.UUID  `json:"case_id"`
+	CaseName string      `json:"case_name"`
+	Base     *CaseResult `json:"base,omitempty"`
+	Target   *CaseResult `json:"target,omitempty"`
+}
+
+// Regressed returns true if the case passed in the base run and does not pass in the target run
+func (c *CaseComparison) Regressed() bool {
+	return c.Base != nil && c.Base.Passed && (c.Target == nil || !c.Target.Passed)
+}
+
+// Fixed returns true if the case did not pass in the base run and passes in the target run
+func (c *CaseComparison) Fixed() bool {
+	return (c.Base == nil || !c.Base.Passed) && c.Target != nil && c.Target.Passed
+}
+
+// Compare puts results of cases in the base and target runs side by side. Cases are ordered as in
+// the base run, followed by cases only in the target run.
+func Compare(base, target *Run) []*CaseComparison {
+	comparisons := make([]*CaseComparison, 0, len(base.Results))
+	seen := make(map[types.UUID]bool, len(base.Results))
+
+	for _, result := range base.Results {
+		seen[result.CaseID] = true
+		comparisons = append(comparisons, &CaseComparison{
+			CaseID:   result.CaseID,
+			CaseName: result.CaseName,
+			Base:     result,
+			Target:   target.Result(result.CaseID),
+		})
+	}
+
+	for _, result := range target.Results {
+		if seen[result.CaseID] {
+			continue
+		}
+		comparisons = append(comparisons, &CaseComparison{
+			CaseID:   result.CaseID,
+			CaseName: result.CaseName,
+			Target:   result,
+		})
+	}
+
+	return comparisons
+}
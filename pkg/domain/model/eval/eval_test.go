@@ -0,0 +1,119 @@
+package eval_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+func newTestCase() *eval.Case {
+	c := eval.NewCase(context.Background(), types.NewUUID(context.Background()))
+	c.Name = "disk alert"
+	c.Input = "Disk usage of web-1 is 95%. What should I do?"
+	c.ExpectedFacts = []string{"web-1", "log rotation"}
+	c.ExpectedPatterns = []string{`(?i)df\s+-h`}
+	return c
+}
+
+func TestCaseValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(c *eval.Case)
+		shouldErr bool
+	}{
+		{name: "valid", modify: func(c *eval.Case) {}},
+		{
+			name: "rubric only",
+			modify: func(c *eval.Case) {
+				c.ExpectedFacts = nil
+				c.ExpectedPatterns = nil
+				c.Rubric = "Suggests checking large files before deleting anything"
+			},
+		},
+		{name: "no name", modify: func(c *eval.Case) { c.Name = " " }, shouldErr: true},
+		{name: "no input", modify: func(c *eval.Case) { c.Input = "" }, shouldErr: true},
+		{
+			name: "no expectation",
+			modify: func(c *eval.Case) {
+				c.ExpectedFacts = nil
+				c.ExpectedPatterns = nil
+			},
+			shouldErr: true,
+		},
+		{name: "empty fact", modify: func(c *eval.Case) { c.ExpectedFacts = []string{""} }, shouldErr: true},
+		{name: "broken pattern", modify: func(c *eval.Case) { c.ExpectedPatterns = []string{"(web"} }, shouldErr: true},
+		{
+			name:      "too long input",
+			modify:    func(c *eval.Case) { c.Input = strings.Repeat("a", eval.MaxInputLength+1) },
+			shouldErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestCase()
+			tc.modify(c)
+			err := c.Validate()
+			if tc.shouldErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCaseCheck(t *testing.T) {
+	c := newTestCase()
+
+	gt.A(t, c.Check("Run `DF -h` on WEB-1 and enable log rotation.")).Length(0)
+
+	failures := c.Check("Run du on web-1.")
+	gt.A(t, failures).Length(2)
+	gt.Equal(t, failures[0], `missing fact: "log rotation"`)
+	gt.Equal(t, failures[1], `pattern not matched: "(?i)df\\s+-h"`)
+}
+
+func TestCompare(t *testing.T) {
+	ctx := context.Background()
+	agentUUID := types.NewUUID(ctx)
+	caseA, caseB, caseC := types.NewUUID(ctx), types.NewUUID(ctx), types.NewUUID(ctx)
+
+	base := eval.NewRun(ctx, agentUUID, "1.0.0")
+	base.AddResult(&eval.CaseResult{CaseID: caseA, CaseName: "a", Passed: true})
+	base.AddResult(&eval.CaseResult{CaseID: caseB, CaseName: "b", Passed: false})
+	base.Finish(nil)
+	gt.Equal(t, base.Passed, 1)
+	gt.Equal(t, base.Failed, 1)
+	gt.Equal(t, base.Status, eval.RunStatusCompleted)
+
+	target := eval.NewRun(ctx, agentUUID, "1.1.0")
+	target.AddResult(&eval.CaseResult{CaseID: caseB, CaseName: "b", Passed: true})
+	target.AddResult(&eval.CaseResult{CaseID: caseA, CaseName: "a", Passed: false})
+	target.AddResult(&eval.CaseResult{CaseID: caseC, CaseName: "c", Passed: true})
+
+	comparisons := eval.Compare(base, target)
+	gt.A(t, comparisons).Length(3)
+
+	gt.Equal(t, comparisons[0].CaseID, caseA)
+	gt.True(t, comparisons[0].Regressed())
+	gt.False(t, comparisons[0].Fixed())
+
+	gt.Equal(t, comparisons[1].CaseID, caseB)
+	gt.True(t, comparisons[1].Fixed())
+
+	// Cases only in the target run follow
+	gt.Equal(t, comparisons[2].CaseID, caseC)
+	gt.Nil(t, comparisons[2].Base)
+	gt.True(t, comparisons[2].Fixed())
+}
+
+func TestRunTruncatesOutput(t *testing.T) {
+	run := eval.NewRun(context.Background(), types.NewUUID(context.Background()), "1.0.0")
+	run.AddResult(&eval.CaseResult{Output: strings.Repeat("あ", eval.MaxOutputLength+10)})
+	gt.Equal(t, len([]rune(run.Results[0].Output)), eval.MaxOutputLength)
+}
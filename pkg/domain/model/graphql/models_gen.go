@@ -26,6 +26,7 @@ type Agent struct {
 	LatestVersion *AgentVersion `json:"latestVersion,omitempty"`
 	Image         *AgentImage   `json:"image,omitempty"`
 	ImageURL      *string       `json:"imageUrl,omitempty"`
+	Rollout       *AgentRollout `json:"rollout,omitempty"`
 }
 
 type AgentIDAvailability struct {
@@ -76,6 +77,12 @@ type AgentNotionSearchConfig struct {
 	UpdatedAt    string  `json:"updatedAt"`
 }
 
+type AgentRollout struct {
+	CandidateVersion string    `json:"candidateVersion"`
+	Percentage       int       `json:"percentage"`
+	StartedAt        time.Time `json:"startedAt"`
+}
+
 type AgentSlackSearchConfig struct {
 	ID          string  `json:"id"`
 	AgentID     string  `json:"agentId"`
@@ -88,13 +95,53 @@ type AgentSlackSearchConfig struct {
 }
 
 type AgentVersion struct {
-	AgentUUID    string       `json:"agentUuid"`
-	Version      string       `json:"version"`
-	SystemPrompt string       `json:"systemPrompt"`
-	LlmProvider  *LLMProvider `json:"llmProvider,omitempty"`
-	LlmModel     *string      `json:"llmModel,omitempty"`
-	CreatedAt    time.Time    `json:"createdAt"`
-	UpdatedAt    time.Time    `json:"updatedAt"`
+	AgentUUID       string       `json:"agentUuid"`
+	Version         string       `json:"version"`
+	SystemPrompt    string       `json:"systemPrompt"`
+	PromptTemplate  bool         `json:"promptTemplate"`
+	PromptVariables []*KeyValue  `json:"promptVariables"`
+	LlmProvider     *LLMProvider `json:"llmProvider,omitempty"`
+	LlmModel        *string      `json:"llmModel,omitempty"`
+	McpServers      []*MCPServer `json:"mcpServers"`
+	Delegation      *Delegation  `json:"delegation,omitempty"`
+	Draft           bool         `json:"draft"`
+	AuthorID        *string      `json:"authorId,omitempty"`
+	PublishedAt     *time.Time   `json:"publishedAt,omitempty"`
+	Changelog       *string      `json:"changelog,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+}
+
+type AgentVersionDiff struct {
+	AgentUUID    string         `json:"agentUuid"`
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	SystemPrompt []*DiffLine    `json:"systemPrompt"`
+	Changes      []*FieldChange `json:"changes"`
+	Changelog    *string        `json:"changelog,omitempty"`
+}
+
+type AgentVersionMetrics struct {
+	Version          string    `json:"version"`
+	Threads          int       `json:"threads"`
+	Responses        int       `json:"responses"`
+	Errors           int       `json:"errors"`
+	AverageLatencyMs int       `json:"averageLatencyMs"`
+	PositiveFeedback int       `json:"positiveFeedback"`
+	NegativeFeedback int       `json:"negativeFeedback"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+type APITokenWithSecret struct {
+	APIToken *APIToken `json:"apiToken"`
+	Token    string    `json:"token"`
 }
 
 type CreateAgentInput struct {
@@ -108,11 +155,15 @@ type CreateAgentInput struct {
 }
 
 type CreateAgentVersionInput struct {
-	AgentUUID    string      `json:"agentUuid"`
-	Version      string      `json:"version"`
-	SystemPrompt *string     `json:"systemPrompt,omitempty"`
-	LlmProvider  LLMProvider `json:"llmProvider"`
-	LlmModel     string      `json:"llmModel"`
+	AgentUUID       string           `json:"agentUuid"`
+	Version         string           `json:"version"`
+	SystemPrompt    *string          `json:"systemPrompt,omitempty"`
+	PromptTemplate  *bool            `json:"promptTemplate,omitempty"`
+	PromptVariables []*KeyValueInput `json:"promptVariables,omitempty"`
+	LlmProvider     LLMProvider      `json:"llmProvider"`
+	LlmModel        string           `json:"llmModel"`
+	Draft           *bool            `json:"draft,omitempty"`
+	Changelog       *string          `json:"changelog,omitempty"`
 }
 
 type CreateJiraSearchConfigInput struct {
@@ -134,6 +185,16 @@ type CreateNotionSearchConfigInput struct {
 	Enabled      bool    `json:"enabled"`
 }
 
+type CreateScheduleInput struct {
+	AgentUUID    string  `json:"agentUuid"`
+	AgentVersion *string `json:"agentVersion,omitempty"`
+	Cron         string  `json:"cron"`
+	TimeZone     *string `json:"timeZone,omitempty"`
+	ChannelID    string  `json:"channelId"`
+	Prompt       string  `json:"prompt"`
+	Enabled      *bool   `json:"enabled,omitempty"`
+}
+
 type CreateSlackSearchConfigInput struct {
 	AgentID     string  `json:"agentId"`
 	ChannelID   string  `json:"channelId"`
@@ -142,6 +203,99 @@ type CreateSlackSearchConfigInput struct {
 	Enabled     bool    `json:"enabled"`
 }
 
+type CreateWebhookInput struct {
+	AgentUUID      string  `json:"agentUuid"`
+	AgentVersion   *string `json:"agentVersion,omitempty"`
+	Name           string  `json:"name"`
+	PromptTemplate *string `json:"promptTemplate,omitempty"`
+	ChannelID      string  `json:"channelId"`
+	ThreadTs       *string `json:"threadTs,omitempty"`
+	SigningSecret  *string `json:"signingSecret,omitempty"`
+	Enabled        *bool   `json:"enabled,omitempty"`
+}
+
+type Delegation struct {
+	Enabled  bool     `json:"enabled"`
+	AgentIds []string `json:"agentIds"`
+}
+
+type DelegationInput struct {
+	Enabled  bool     `json:"enabled"`
+	AgentIds []string `json:"agentIds,omitempty"`
+}
+
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+type EvalCase struct {
+	ID               string    `json:"id"`
+	AgentUUID        string    `json:"agentUuid"`
+	Name             string    `json:"name"`
+	Input            string    `json:"input"`
+	ExpectedFacts    []string  `json:"expectedFacts"`
+	ExpectedPatterns []string  `json:"expectedPatterns"`
+	Rubric           *string   `json:"rubric,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type EvalCaseComparison struct {
+	CaseID    string          `json:"caseId"`
+	CaseName  string          `json:"caseName"`
+	Base      *EvalCaseResult `json:"base,omitempty"`
+	Target    *EvalCaseResult `json:"target,omitempty"`
+	Regressed bool            `json:"regressed"`
+	Fixed     bool            `json:"fixed"`
+}
+
+type EvalCaseInput struct {
+	AgentUUID        string   `json:"agentUuid"`
+	Name             string   `json:"name"`
+	Input            string   `json:"input"`
+	ExpectedFacts    []string `json:"expectedFacts,omitempty"`
+	ExpectedPatterns []string `json:"expectedPatterns,omitempty"`
+	Rubric           *string  `json:"rubric,omitempty"`
+}
+
+type EvalCaseResult struct {
+	CaseID      string   `json:"caseId"`
+	CaseName    string   `json:"caseName"`
+	Input       string   `json:"input"`
+	Output      string   `json:"output"`
+	Passed      bool     `json:"passed"`
+	Failures    []string `json:"failures"`
+	JudgeReason *string  `json:"judgeReason,omitempty"`
+	Error       *string  `json:"error,omitempty"`
+	DurationMs  int      `json:"durationMs"`
+}
+
+type EvalRun struct {
+	ID           string            `json:"id"`
+	AgentUUID    string            `json:"agentUuid"`
+	AgentVersion string            `json:"agentVersion"`
+	Status       string            `json:"status"`
+	Passed       int               `json:"passed"`
+	Failed       int               `json:"failed"`
+	Results      []*EvalCaseResult `json:"results"`
+	StartedAt    time.Time         `json:"startedAt"`
+	FinishedAt   *time.Time        `json:"finishedAt,omitempty"`
+	Error        *string           `json:"error,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type ImportAgentResult struct {
+	Agent    *Agent            `json:"agent"`
+	Action   ImportAgentAction `json:"action"`
+	Versions []string          `json:"versions"`
+}
+
 type JiraIntegration struct {
 	ID          string     `json:"id"`
 	Connected   bool       `json:"connected"`
@@ -153,6 +307,28 @@ type JiraOAuthURL struct {
 	URL string `json:"url"`
 }
 
+type KeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type KeyValueInput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type KnowledgeDocument struct {
+	ID             string    `json:"id"`
+	AgentUUID      string    `json:"agentUuid"`
+	Name           string    `json:"name"`
+	ContentType    string    `json:"contentType"`
+	Size           int       `json:"size"`
+	ChunkCount     int       `json:"chunkCount"`
+	EmbeddingModel string    `json:"embeddingModel"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
 type LLMConfig struct {
 	Providers        []*LLMProviderInfo `json:"providers"`
 	DefaultProvider  string             `json:"defaultProvider"`
@@ -174,6 +350,30 @@ type LLMProviderInfo struct {
 	Models      []*LLMModel `json:"models"`
 }
 
+type MCPServer struct {
+	Name           string       `json:"name"`
+	Transport      MCPTransport `json:"transport"`
+	Command        *string      `json:"command,omitempty"`
+	Args           []string     `json:"args"`
+	EnvKeys        []string     `json:"envKeys"`
+	URL            *string      `json:"url,omitempty"`
+	HeaderKeys     []string     `json:"headerKeys"`
+	AllowedTools   []string     `json:"allowedTools"`
+	TimeoutSeconds int          `json:"timeoutSeconds"`
+}
+
+type MCPServerInput struct {
+	Name           string           `json:"name"`
+	Transport      MCPTransport     `json:"transport"`
+	Command        *string          `json:"command,omitempty"`
+	Args           []string         `json:"args,omitempty"`
+	Env            []*KeyValueInput `json:"env,omitempty"`
+	URL            *string          `json:"url,omitempty"`
+	Headers        []*KeyValueInput `json:"headers,omitempty"`
+	AllowedTools   []string         `json:"allowedTools,omitempty"`
+	TimeoutSeconds *int             `json:"timeoutSeconds,omitempty"`
+}
+
 type Mutation struct {
 }
 
@@ -192,6 +392,34 @@ type NotionOAuthURL struct {
 type Query struct {
 }
 
+type Schedule struct {
+	ID           string     `json:"id"`
+	AgentUUID    string     `json:"agentUuid"`
+	AgentVersion *string    `json:"agentVersion,omitempty"`
+	Cron         string     `json:"cron"`
+	TimeZone     string     `json:"timeZone"`
+	ChannelID    string     `json:"channelId"`
+	Prompt       string     `json:"prompt"`
+	Enabled      bool       `json:"enabled"`
+	NextRunAt    *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt    *time.Time `json:"lastRunAt,omitempty"`
+	LastError    *string    `json:"lastError,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+type ScheduleRun struct {
+	ID           string     `json:"id"`
+	ScheduleID   string     `json:"scheduleId"`
+	ScheduledAt  time.Time  `json:"scheduledAt"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+	Status       string     `json:"status"`
+	AgentVersion string     `json:"agentVersion"`
+	ThreadTs     *string    `json:"threadTs,omitempty"`
+	Error        *string    `json:"error,omitempty"`
+}
+
 type ThreadsResponse struct {
 	Threads    []*slack.Thread `json:"threads"`
 	TotalCount int             `json:"totalCount"`
@@ -226,12 +454,51 @@ type UpdateNotionSearchConfigInput struct {
 	Enabled      bool    `json:"enabled"`
 }
 
+type UpdateScheduleInput struct {
+	AgentVersion *string `json:"agentVersion,omitempty"`
+	Cron         *string `json:"cron,omitempty"`
+	TimeZone     *string `json:"timeZone,omitempty"`
+	ChannelID    *string `json:"channelId,omitempty"`
+	Prompt       *string `json:"prompt,omitempty"`
+	Enabled      *bool   `json:"enabled,omitempty"`
+}
+
 type UpdateSlackSearchConfigInput struct {
 	ChannelName string  `json:"channelName"`
 	Description *string `json:"description,omitempty"`
 	Enabled     bool    `json:"enabled"`
 }
 
+type UpdateWebhookInput struct {
+	AgentVersion   *string `json:"agentVersion,omitempty"`
+	Name           *string `json:"name,omitempty"`
+	PromptTemplate *string `json:"promptTemplate,omitempty"`
+	ChannelID      *string `json:"channelId,omitempty"`
+	ThreadTs       *string `json:"threadTs,omitempty"`
+	SigningSecret  *string `json:"signingSecret,omitempty"`
+	Enabled        *bool   `json:"enabled,omitempty"`
+}
+
+type Webhook struct {
+	ID               string    `json:"id"`
+	AgentUUID        string    `json:"agentUuid"`
+	AgentVersion     *string   `json:"agentVersion,omitempty"`
+	Name             string    `json:"name"`
+	PromptTemplate   string    `json:"promptTemplate"`
+	ChannelID        string    `json:"channelId"`
+	ThreadTs         *string   `json:"threadTs,omitempty"`
+	HasSigningSecret bool      `json:"hasSigningSecret"`
+	Enabled          bool      `json:"enabled"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type WebhookToken struct {
+	Webhook *Webhook `json:"webhook"`
+	Token   string   `json:"token"`
+	Path    string   `json:"path"`
+}
+
 type AgentStatus string
 
 const (
@@ -287,6 +554,179 @@ func (e AgentStatus) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type DiffOp string
+
+const (
+	DiffOpEqual  DiffOp = "EQUAL"
+	DiffOpInsert DiffOp = "INSERT"
+	DiffOpDelete DiffOp = "DELETE"
+)
+
+var AllDiffOp = []DiffOp{
+	DiffOpEqual,
+	DiffOpInsert,
+	DiffOpDelete,
+}
+
+func (e DiffOp) IsValid() bool {
+	switch e {
+	case DiffOpEqual, DiffOpInsert, DiffOpDelete:
+		return true
+	}
+	return false
+}
+
+func (e DiffOp) String() string {
+	return string(e)
+}
+
+func (e *DiffOp) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DiffOp(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DiffOp", str)
+	}
+	return nil
+}
+
+func (e DiffOp) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DiffOp) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DiffOp) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ImportAgentAction string
+
+const (
+	ImportAgentActionCreated      ImportAgentAction = "CREATED"
+	ImportAgentActionSkipped      ImportAgentAction = "SKIPPED"
+	ImportAgentActionOverwritten  ImportAgentAction = "OVERWRITTEN"
+	ImportAgentActionVersionAdded ImportAgentAction = "VERSION_ADDED"
+)
+
+var AllImportAgentAction = []ImportAgentAction{
+	ImportAgentActionCreated,
+	ImportAgentActionSkipped,
+	ImportAgentActionOverwritten,
+	ImportAgentActionVersionAdded,
+}
+
+func (e ImportAgentAction) IsValid() bool {
+	switch e {
+	case ImportAgentActionCreated, ImportAgentActionSkipped, ImportAgentActionOverwritten, ImportAgentActionVersionAdded:
+		return true
+	}
+	return false
+}
+
+func (e ImportAgentAction) String() string {
+	return string(e)
+}
+
+func (e *ImportAgentAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportAgentAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportAgentAction", str)
+	}
+	return nil
+}
+
+func (e ImportAgentAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ImportAgentAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ImportAgentAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ImportConflict string
+
+const (
+	ImportConflictSkip       ImportConflict = "SKIP"
+	ImportConflictOverwrite  ImportConflict = "OVERWRITE"
+	ImportConflictNewVersion ImportConflict = "NEW_VERSION"
+)
+
+var AllImportConflict = []ImportConflict{
+	ImportConflictSkip,
+	ImportConflictOverwrite,
+	ImportConflictNewVersion,
+}
+
+func (e ImportConflict) IsValid() bool {
+	switch e {
+	case ImportConflictSkip, ImportConflictOverwrite, ImportConflictNewVersion:
+		return true
+	}
+	return false
+}
+
+func (e ImportConflict) String() string {
+	return string(e)
+}
+
+func (e *ImportConflict) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportConflict(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportConflict", str)
+	}
+	return nil
+}
+
+func (e ImportConflict) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ImportConflict) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ImportConflict) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type LLMProvider string
 
 const (
@@ -343,3 +783,58 @@ func (e LLMProvider) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type MCPTransport string
+
+const (
+	MCPTransportStdio MCPTransport = "STDIO"
+	MCPTransportHTTP  MCPTransport = "HTTP"
+)
+
+var AllMCPTransport = []MCPTransport{
+	MCPTransportStdio,
+	MCPTransportHTTP,
+}
+
+func (e MCPTransport) IsValid() bool {
+	switch e {
+	case MCPTransportStdio, MCPTransportHTTP:
+		return true
+	}
+	return false
+}
+
+func (e MCPTransport) String() string {
+	return string(e)
+}
+
+func (e *MCPTransport) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MCPTransport(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MCPTransport", str)
+	}
+	return nil
+}
+
+func (e MCPTransport) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MCPTransport) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MCPTransport) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
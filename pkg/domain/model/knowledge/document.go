@@ -0,0 +1,93 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// MaxDocumentSize is the maximum size of an uploaded document in bytes
+	MaxDocumentSize = 10 * 1024 * 1024
+
+	// maxDocumentNameLength is the maximum length of a document name in characters
+	maxDocumentNameLength = 255
+)
+
+// ErrDocumentNotFound is returned when the knowledge document does not exist
+var ErrDocumentNotFound = errors.New("knowledge document not found")
+
+// Document is a document uploaded to the knowledge base of an agent. The original file is kept
+// in storage, and its text is split into chunks indexed with their embeddings.
+type Document struct {
+	ID          types.UUID `json:"id"`
+	AgentUUID   types.UUID `json:"agent_uuid"`
+	Name        string     `json:"name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	StorageKey  string     `json:"storage_key"`
+	ChunkCount  int        `json:"chunk_count"`
+	// EmbeddingModel is the model that embedded the chunks, as "provider:model"
+	EmbeddingModel string    `json:"embedding_model"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NewDocument creates a new Document of the agent
+func NewDocument(ctx context.Context, agentUUID types.UUID, name, contentType string, size int64) *Document {
+	now := time.Now()
+	return &Document{
+		ID:          types.NewUUID(ctx),
+		AgentUUID:   agentUUID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Validate validates the document
+func (d *Document) Validate() error {
+	if !d.ID.IsValid() {
+		return goerr.New("invalid knowledge document ID", goerr.V("id", d.ID))
+	}
+	if !d.AgentUUID.IsValid() {
+		return goerr.New("invalid agent UUID of knowledge document", goerr.V("agent_uuid", d.AgentUUID))
+	}
+	if d.Name == "" {
+		return goerr.New("knowledge document name is required")
+	}
+	if utf8.RuneCountInString(d.Name) > maxDocumentNameLength {
+		return goerr.New("knowledge document name is too long",
+			goerr.V("name", d.Name),
+			goerr.V("max", maxDocumentNameLength))
+	}
+	if d.Size < 0 || d.Size > MaxDocumentSize {
+		return goerr.New("knowledge document size is out of range",
+			goerr.V("size", d.Size),
+			goerr.V("max", MaxDocumentSize))
+	}
+	return nil
+}
+
+// Chunk is a part of the text of a document with its embedding
+type Chunk struct {
+	DocumentID   types.UUID `json:"document_id"`
+	AgentUUID    types.UUID `json:"agent_uuid"`
+	DocumentName string     `json:"document_name"`
+	Index        int        `json:"index"`
+	Text         string     `json:"text"`
+	Embedding    []float64  `json:"embedding"`
+}
+
+// SearchResult is a chunk matched by similarity search
+type SearchResult struct {
+	Chunk *Chunk
+	// Score is the cosine similarity between the query and the chunk, from -1.0 to 1.0
+	Score float64
+}
@@ -12,6 +12,8 @@ type Model struct {
 	ID          string `yaml:"id" json:"id"`
 	DisplayName string `yaml:"display_name" json:"display_name"`
 	Description string `yaml:"description" json:"description"`
+	// ContextWindow is the maximum number of input tokens of the model. 0 means unknown.
+	ContextWindow int `yaml:"context_window" json:"context_window"`
 }
 
 // ProvidersConfig represents the complete LLM providers configuration
@@ -19,6 +21,8 @@ type ProvidersConfig struct {
 	Providers map[string]Provider `yaml:"providers"`
 	Defaults  DefaultConfig       `yaml:"defaults"`
 	Fallback  FallbackConfig      `yaml:"fallback"`
+	Routing   RoutingConfig       `yaml:"routing"`
+	Knowledge KnowledgeConfig     `yaml:"knowledge"`
 }
 
 // DefaultConfig represents default provider and model settings
@@ -34,6 +38,26 @@ type FallbackConfig struct {
 	Model    string `yaml:"model"`
 }
 
+// RoutingConfig represents settings of the agent router that chooses an agent for mentions
+// without agent ID. A cheap model is enough because it only classifies the request.
+type RoutingConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+	// Threshold is the minimum confidence (0.0-1.0) to route to an agent. General mode is kept below it.
+	Threshold float64 `yaml:"threshold"`
+}
+
+// KnowledgeConfig represents settings of the embedding model for knowledge bases of agents.
+// The model is an embedding model of the provider, not one of the models listed in providers.
+type KnowledgeConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+	// Dimension is the number of dimensions of embeddings
+	Dimension int `yaml:"dimension"`
+}
+
 // ValidateProviderModel checks if a provider and model combination is valid
 func (c *ProvidersConfig) ValidateProviderModel(provider, model string) bool {
 	if provider == "" || model == "" {
@@ -54,6 +78,20 @@ func (c *ProvidersConfig) ValidateProviderModel(provider, model string) bool {
 	return false
 }
 
+// ContextWindow returns the context window of the model in tokens, or 0 if it is unknown. The
+// default model is used if provider and model are empty.
+func (c *ProvidersConfig) ContextWindow(provider, model string) int {
+	if provider == "" && model == "" {
+		provider, model = c.Defaults.Provider, c.Defaults.Model
+	}
+
+	m, ok := c.GetModel(provider, model)
+	if !ok {
+		return 0
+	}
+	return m.ContextWindow
+}
+
 // GetProvider returns a provider by ID
 func (c *ProvidersConfig) GetProvider(id string) (*Provider, bool) {
 	p, exists := c.Providers[id]
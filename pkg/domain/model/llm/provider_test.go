@@ -183,3 +183,25 @@ func TestFallbackConfig(t *testing.T) {
 		gt.Value(t, config.Model).Equal("")
 	})
 }
+
+func TestProvidersConfig_ContextWindow(t *testing.T) {
+	config := &llm.ProvidersConfig{
+		Providers: map[string]llm.Provider{
+			"gemini": {
+				Models: []llm.Model{
+					{ID: "gemini-2.5-flash", ContextWindow: 1048576},
+					{ID: "gemini-2.0-flash"},
+				},
+			},
+		},
+		Defaults: llm.DefaultConfig{
+			Provider: "gemini",
+			Model:    "gemini-2.5-flash",
+		},
+	}
+
+	gt.Value(t, config.ContextWindow("gemini", "gemini-2.5-flash")).Equal(1048576)
+	gt.Value(t, config.ContextWindow("gemini", "gemini-2.0-flash")).Equal(0)
+	gt.Value(t, config.ContextWindow("gemini", "unknown")).Equal(0)
+	gt.Value(t, config.ContextWindow("", "")).Equal(1048576)
+}
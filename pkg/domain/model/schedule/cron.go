@@ -0,0 +1,221 @@
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// Cron is a parsed cron expression with five fields: minute, hour, day of month, month and
+// day of week. Each field accepts *, numbers, ranges (1-5), steps (*/15, 1-30/5) and lists
+// (1,15). Month and day of week also accept names such as JAN and MON, and macros such as
+// @daily and @weekly are expanded.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar are set when the day fields are not restricted. If both day fields
+	// are restricted, a day matching either of them matches, as in standard cron.
+	domStar, dowStar bool
+}
+
+// cronField describes the range and names of a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// Day of week accepts 7 as Sunday in addition to 0
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSearchYears limits how far Next looks for a matching time, for expressions such as
+// "0 0 30 2 *" that never match
+const cronSearchYears = 5
+
+// ParseCron parses a cron expression
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, goerr.New("cron expression must have 5 fields: minute, hour, day of month, month and day of week",
+			goerr.V("expr", expr))
+	}
+
+	var c Cron
+	var err error
+	if c.minute, _, err = parseCronField(fields[0], cronMinute); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if c.hour, _, err = parseCronField(fields[1], cronHour); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if c.dom, c.domStar, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if c.month, _, err = parseCronField(fields[3], cronMonth); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+	if c.dow, c.dowStar, err = parseCronField(fields[4], cronDow); err != nil {
+		return nil, goerr.Wrap(err, "invalid cron expression", goerr.V("expr", expr))
+	}
+
+	// Sunday can be written as 7
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+
+	return &c, nil
+}
+
+// parseCronField parses a field into a bit set of matching values. star reports whether the
+// field is * without a step.
+func parseCronField(field string, spec cronField) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, false, goerr.New("invalid step", goerr.V("field", spec.name), goerr.V("value", part))
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*":
+			low, high = spec.min, spec.max
+			if spec.name == cronDow.name {
+				high = 6 // 7 is an alias of 0
+			}
+			star = star || !hasStep
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			if low, err = parseCronValue(lowPart, spec); err != nil {
+				return 0, false, err
+			}
+			if high, err = parseCronValue(highPart, spec); err != nil {
+				return 0, false, err
+			}
+			if low > high {
+				return 0, false, goerr.New("invalid range", goerr.V("field", spec.name), goerr.V("value", part))
+			}
+		default:
+			if low, err = parseCronValue(rangePart, spec); err != nil {
+				return 0, false, err
+			}
+			high = low
+			if hasStep {
+				high = spec.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, star, nil
+}
+
+// parseCronValue parses a number or a name of a field
+func parseCronValue(value string, spec cronField) (int, error) {
+	if v, ok := spec.names[strings.ToUpper(value)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, goerr.New("invalid value", goerr.V("field", spec.name), goerr.V("value", value))
+	}
+	if v < spec.min || v > spec.max {
+		return 0, goerr.New("value out of range",
+			goerr.V("field", spec.name),
+			goerr.V("value", v),
+			goerr.V("min", spec.min),
+			goerr.V("max", spec.max))
+	}
+	return v, nil
+}
+
+// Next returns the earliest time after t that matches the expression, in the location of t.
+// It returns the zero time if no time matches within a few years.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	// Start from the next whole minute
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Year() + cronSearchYears
+
+WRAP:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for c.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !c.matchDay(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for c.hour&(1<<uint(t.Hour())) == 0 {
+		t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for c.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// matchDay reports whether the day of t matches the day of month and day of week fields
+func (c *Cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,128 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+)
+
+func TestParseCron(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expr      string
+		shouldErr bool
+	}{
+		{name: "every minute", expr: "* * * * *"},
+		{name: "weekdays at 9:00", expr: "0 9 * * 1-5"},
+		{name: "names", expr: "30 8 * JAN-MAR MON,WED,FRI"},
+		{name: "steps and lists", expr: "*/15 0-12/3 1,15 * *"},
+		{name: "sunday as 7", expr: "0 0 * * 7"},
+		{name: "macro", expr: "@daily"},
+		{name: "too few fields", expr: "0 9 * *", shouldErr: true},
+		{name: "too many fields", expr: "0 0 9 * * 1", shouldErr: true},
+		{name: "minute out of range", expr: "60 * * * *", shouldErr: true},
+		{name: "day of month out of range", expr: "0 0 0 * *", shouldErr: true},
+		{name: "invalid name", expr: "0 0 * * MOON", shouldErr: true},
+		{name: "reversed range", expr: "0 0 * * 5-1", shouldErr: true},
+		{name: "zero step", expr: "*/0 * * * *", shouldErr: true},
+		{name: "empty", expr: "", shouldErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := schedule.ParseCron(tc.expr)
+			if tc.shouldErr {
+				gt.Error(t, err)
+			} else {
+				gt.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	gt.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	gt.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "next minute",
+			expr: "* * * * *",
+			from: time.Date(2025, 1, 6, 9, 0, 30, 0, time.UTC),
+			want: time.Date(2025, 1, 6, 9, 1, 0, 0, time.UTC),
+		},
+		{
+			name: "exact tick is not included",
+			expr: "0 9 * * *",
+			from: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekday skips weekend",
+			expr: "0 9 * * 1-5",
+			from: time.Date(2025, 1, 10, 10, 0, 0, 0, tokyo), // Friday
+			want: time.Date(2025, 1, 13, 9, 0, 0, 0, tokyo),  // Monday
+		},
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			from: time.Date(2025, 1, 6, 9, 16, 0, 0, time.UTC),
+			want: time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "end of year",
+			expr: "0 0 1 * *",
+			from: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap day",
+			expr: "0 12 29 2 *",
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 13 * 5",
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), // Friday before the 13th
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 0 * * 7",
+			from: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), // Monday
+			want: time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "skipped hour of daylight saving time",
+			expr: "30 2 * * *",
+			from: time.Date(2025, 3, 8, 12, 0, 0, 0, newYork),
+			want: time.Date(2025, 3, 10, 2, 30, 0, 0, newYork),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cron, err := schedule.ParseCron(tc.expr)
+			gt.NoError(t, err)
+			got := cron.Next(tc.from)
+			gt.True(t, got.Equal(tc.want))
+			gt.Equal(t, got.Location(), tc.want.Location())
+		})
+	}
+
+	t.Run("never matches", func(t *testing.T) {
+		cron, err := schedule.ParseCron("0 0 30 2 *")
+		gt.NoError(t, err)
+		gt.True(t, cron.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero())
+	})
+}
@@ -0,0 +1,177 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// MaxPromptLength is the maximum length of the prompt of a schedule in characters
+	MaxPromptLength = 4000
+
+	// DefaultTimeZone is used if the time zone of a schedule is not set
+	DefaultTimeZone = "UTC"
+)
+
+var (
+	// ErrScheduleNotFound is returned when the schedule does not exist
+	ErrScheduleNotFound = errors.New("schedule not found")
+
+	// ErrRunAlreadyExists is returned when the run of the same tick of the schedule already
+	// exists, which means another instance has taken the tick
+	ErrRunAlreadyExists = errors.New("schedule run already exists")
+
+	// ErrRunNotFound is returned when the run of the schedule does not exist
+	ErrRunNotFound = errors.New("schedule run not found")
+)
+
+// Schedule runs an agent on a cron schedule and posts the response to a channel as a new thread
+type Schedule struct {
+	ID        types.UUID `json:"id"`
+	AgentUUID types.UUID `json:"agent_uuid"`
+	// AgentVersion is the version of the agent to run. The latest version is used if empty.
+	AgentVersion string `json:"agent_version"`
+	Cron         string `json:"cron"`
+	TimeZone     string `json:"time_zone"`
+	ChannelID    string `json:"channel_id"`
+	Prompt       string `json:"prompt"`
+	Enabled      bool   `json:"enabled"`
+
+	// NextRunAt is the next tick of the schedule. It is zero if the schedule is disabled.
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	// LastError is the error of the last run. It is empty if the last run succeeded.
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewSchedule creates a new Schedule of the agent
+func NewSchedule(ctx context.Context, agentUUID types.UUID) *Schedule {
+	now := time.Now()
+	return &Schedule{
+		ID:        types.NewUUID(ctx),
+		AgentUUID: agentUUID,
+		TimeZone:  DefaultTimeZone,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate validates the schedule
+func (s *Schedule) Validate() error {
+	if !s.ID.IsValid() {
+		return goerr.New("invalid schedule ID", goerr.V("id", s.ID))
+	}
+	if !s.AgentUUID.IsValid() {
+		return goerr.New("invalid agent UUID of schedule", goerr.V("agent_uuid", s.AgentUUID))
+	}
+	if _, err := ParseCron(s.Cron); err != nil {
+		return err
+	}
+	if _, err := s.Location(); err != nil {
+		return err
+	}
+	if s.ChannelID == "" {
+		return goerr.New("channel ID of schedule is required")
+	}
+	if s.Prompt == "" {
+		return goerr.New("prompt of schedule is required")
+	}
+	if utf8.RuneCountInString(s.Prompt) > MaxPromptLength {
+		return goerr.New("prompt of schedule is too long",
+			goerr.V("length", utf8.RuneCountInString(s.Prompt)),
+			goerr.V("max", MaxPromptLength))
+	}
+	return nil
+}
+
+// Location returns the time zone of the schedule
+func (s *Schedule) Location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid time zone of schedule", goerr.V("time_zone", s.TimeZone))
+	}
+	return loc, nil
+}
+
+// Next returns the first tick of the schedule after t in its time zone
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, goerr.New("cron expression of schedule never matches", goerr.V("cron", s.Cron))
+	}
+	return next, nil
+}
+
+// RunStatus is the status of a run of a schedule
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+	// RunStatusSkipped is a tick missed for too long, e.g. while no instance was running
+	RunStatusSkipped RunStatus = "skipped"
+)
+
+// Run is an execution of a schedule at a tick
+type Run struct {
+	// ID is derived from the schedule and the tick, so that a tick is run only once
+	ID           string     `json:"id"`
+	ScheduleID   types.UUID `json:"schedule_id"`
+	ScheduledAt  time.Time  `json:"scheduled_at"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Status       RunStatus  `json:"status"`
+	AgentVersion string     `json:"agent_version"`
+	// ThreadTS is the timestamp of the message posted by the run
+	ThreadTS string `json:"thread_ts"`
+	Error    string `json:"error"`
+}
+
+// NewRun creates a new running Run of the schedule at the tick
+func NewRun(scheduleID types.UUID, scheduledAt time.Time) *Run {
+	return &Run{
+		ID:          RunID(scheduleID, scheduledAt),
+		ScheduleID:  scheduleID,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+		Status:      RunStatusRunning,
+	}
+}
+
+// RunID returns the ID of the run of the schedule at the tick
+func RunID(scheduleID types.UUID, scheduledAt time.Time) string {
+	return fmt.Sprintf("%s-%d", scheduleID, scheduledAt.Unix())
+}
+
+// Finish sets the result of the run. The run fails if err is not nil.
+func (r *Run) Finish(status RunStatus, err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Status = status
+	if err != nil {
+		r.Status = RunStatusFailed
+		r.Error = err.Error()
+	}
+}
@@ -0,0 +1,88 @@
+package schedule_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+func newTestSchedule() *schedule.Schedule {
+	s := schedule.NewSchedule(context.Background(), types.NewUUID(context.Background()))
+	s.Cron = "0 9 * * 1-5"
+	s.TimeZone = "Asia/Tokyo"
+	s.ChannelID = "C12345"
+	s.Prompt = "Summarize yesterday's discussion in #team"
+	return s
+}
+
+func TestScheduleValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(s *schedule.Schedule)
+		shouldErr bool
+	}{
+		{name: "valid", modify: func(s *schedule.Schedule) {}},
+		{name: "empty time zone is UTC", modify: func(s *schedule.Schedule) { s.TimeZone = "" }},
+		{name: "invalid cron", modify: func(s *schedule.Schedule) { s.Cron = "every day" }, shouldErr: true},
+		{name: "invalid time zone", modify: func(s *schedule.Schedule) { s.TimeZone = "Mars/Olympus" }, shouldErr: true},
+		{name: "no channel", modify: func(s *schedule.Schedule) { s.ChannelID = "" }, shouldErr: true},
+		{name: "no prompt", modify: func(s *schedule.Schedule) { s.Prompt = "" }, shouldErr: true},
+		{
+			name:      "too long prompt",
+			modify:    func(s *schedule.Schedule) { s.Prompt = strings.Repeat("a", schedule.MaxPromptLength+1) },
+			shouldErr: true,
+		},
+		{name: "invalid agent UUID", modify: func(s *schedule.Schedule) { s.AgentUUID = "" }, shouldErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestSchedule()
+			tc.modify(s)
+			if tc.shouldErr {
+				gt.Error(t, s.Validate())
+			} else {
+				gt.NoError(t, s.Validate())
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	s := newTestSchedule()
+
+	// Friday 10:00 in Tokyo is 01:00 UTC
+	next, err := s.Next(time.Date(2025, 1, 10, 1, 0, 0, 0, time.UTC))
+	gt.NoError(t, err)
+	gt.Equal(t, next.Location().String(), "Asia/Tokyo")
+	gt.True(t, next.Equal(time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC))) // Monday 9:00 in Tokyo
+
+	s.Cron = "0 0 30 2 *"
+	_, err = s.Next(time.Now())
+	gt.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	scheduleID := types.NewUUID(context.Background())
+	tick := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	run := schedule.NewRun(scheduleID, tick)
+	gt.Equal(t, run.ID, schedule.RunID(scheduleID, tick))
+	gt.Equal(t, run.Status, schedule.RunStatusRunning)
+	gt.NotEqual(t, run.ID, schedule.RunID(scheduleID, tick.Add(time.Minute)))
+
+	run.Finish(schedule.RunStatusSucceeded, nil)
+	gt.Equal(t, run.Status, schedule.RunStatusSucceeded)
+	gt.NotNil(t, run.FinishedAt)
+
+	failed := schedule.NewRun(scheduleID, tick)
+	failed.Finish(schedule.RunStatusSucceeded, errors.New("agent is archived"))
+	gt.Equal(t, failed.Status, schedule.RunStatusFailed)
+	gt.Equal(t, failed.Error, "agent is archived")
+}
@@ -9,6 +9,7 @@ var (
 	ErrEmptyChannelID   = errors.New("channel ID is empty")
 	ErrEmptyThreadTS    = errors.New("thread timestamp is empty")
 	ErrInvalidAgentUUID = errors.New("invalid agent UUID")
+	ErrInvalidThreadURL = errors.New("invalid thread URL")
 
 	// Message errors
 	ErrInvalidMessageID = errors.New("invalid message ID")
@@ -26,5 +27,7 @@ var (
 	ErrLLMUnavailable   = errors.New("LLM service is unavailable")
 
 	// Agent errors
-	ErrAgentNotFound = errors.New("agent not found")
+	ErrAgentNotFound   = errors.New("agent not found")
+	ErrDraftNotFound   = errors.New("draft version of agent not found")
+	ErrDraftNotAllowed = errors.New("draft version of agent is usable only by its author")
 )
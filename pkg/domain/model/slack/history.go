@@ -7,11 +7,29 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
 )
 
-// History represents a conversation history storage record
+// History represents a conversation history storage record.
+// A record is created for each turn and keeps the agent that answered the turn.
+// A compacted record is also created when older turns are summarized, and the record
+// it was compacted from is kept so that the original history stays recoverable.
+// Records of turns keep the history they continued from, the input and the response, so that a
+// turn can be regenerated.
+// A record with an empty history and the transcript of the thread is created when the thread is
+// handed over to another agent.
 type History struct {
-	ID        types.HistoryID `json:"id"`
-	ThreadID  types.ThreadID  `json:"thread_id"`
-	CreatedAt time.Time       `json:"created_at"`
+	ID              types.HistoryID `json:"id"`
+	ThreadID        types.ThreadID  `json:"thread_id"`
+	AgentUUID       *types.UUID     `json:"agent_uuid,omitempty"`       // Agent that answered the turn (nil for legacy records)
+	AgentVersion    string          `json:"agent_version,omitempty"`    // Agent version that answered the turn
+	Summary         string          `json:"summary,omitempty"`          // Summary of turns dropped from the history by compaction
+	CompactedFrom   types.HistoryID `json:"compacted_from,omitempty"`   // History the record was compacted from (empty if not compacted)
+	ParentID        types.HistoryID `json:"parent_id,omitempty"`        // History the turn continued from (empty for the first turn)
+	MessageTS       string          `json:"message_ts,omitempty"`       // Timestamp of the mention that started the turn
+	Input           string          `json:"input,omitempty"`            // Message of the user in the turn
+	InputParts      []string        `json:"input_parts,omitempty"`      // All text given to the agent in the turn (Input is used if empty)
+	ResponseTS      string          `json:"response_ts,omitempty"`      // Timestamp of the response of the agent to the turn
+	RegeneratedFrom types.HistoryID `json:"regenerated_from,omitempty"` // Turn that the record regenerated (empty if not regenerated)
+	Handover        string          `json:"handover,omitempty"`         // Transcript for the agent the thread was handed over to (empty if not handed over)
+	CreatedAt       time.Time       `json:"created_at"`
 }
 
 // NewHistory creates a new History instance
@@ -23,6 +41,20 @@ func NewHistory(ctx context.Context, threadID types.ThreadID) *History {
 	}
 }
 
+// NewHistoryWithAgent creates a new History instance with the agent that answered the turn
+func NewHistoryWithAgent(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) *History {
+	h := NewHistory(ctx, threadID)
+	h.AgentUUID = agentUUID
+	h.AgentVersion = agentVersion
+	return h
+}
+
+// IsTurn reports whether the record is a history after a turn of the agent. Compacted records
+// and legacy records without the mention of the turn are not turns.
+func (h *History) IsTurn() bool {
+	return h.MessageTS != "" && h.CompactedFrom == ""
+}
+
 // Validate checks if the history has valid fields
 func (h *History) Validate() error {
 	if !h.ID.IsValid() {
@@ -1,6 +1,7 @@
 package slack
 
 import (
+	"strings"
 	"time"
 
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
@@ -64,6 +65,22 @@ type SlackMessageLog struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Permalink builds a link to the message in Slack. workspaceURL is the team URL
+// returned by auth.test (e.g. "https://example.slack.com/"). If it is empty,
+// the generic slack.com domain is used and Slack redirects to the workspace.
+func (x *SlackMessageLog) Permalink(workspaceURL string) string {
+	base := strings.TrimSuffix(workspaceURL, "/")
+	if base == "" {
+		base = "https://slack.com"
+	}
+
+	link := base + "/archives/" + x.ChannelID + "/p" + strings.ReplaceAll(x.Timestamp, ".", "")
+	if x.ThreadTS != "" && x.ThreadTS != x.Timestamp {
+		link += "?thread_ts=" + x.ThreadTS + "&cid=" + x.ChannelID
+	}
+	return link
+}
+
 // ChannelInfo represents cached channel information from Slack API
 type ChannelInfo struct {
 	ID        string      `json:"id"`
@@ -332,3 +332,43 @@ func TestSlackMessageLog_ComplexScenarios(t *testing.T) {
 		gt.True(t, messageLog.ChannelID[0] == 'D') // DM channels start with D
 	})
 }
+
+func TestSlackMessageLog_Permalink(t *testing.T) {
+	t.Run("top level message with workspace URL", func(t *testing.T) {
+		msg := &slack.SlackMessageLog{
+			ChannelID: "C12345",
+			Timestamp: "1234567890.123456",
+		}
+		gt.Equal(t, msg.Permalink("https://example.slack.com/"),
+			"https://example.slack.com/archives/C12345/p1234567890123456")
+	})
+
+	t.Run("thread reply includes thread_ts", func(t *testing.T) {
+		msg := &slack.SlackMessageLog{
+			ChannelID: "C12345",
+			Timestamp: "1234567890.223456",
+			ThreadTS:  "1234567890.123456",
+		}
+		gt.Equal(t, msg.Permalink("https://example.slack.com"),
+			"https://example.slack.com/archives/C12345/p1234567890223456?thread_ts=1234567890.123456&cid=C12345")
+	})
+
+	t.Run("thread parent does not include thread_ts", func(t *testing.T) {
+		msg := &slack.SlackMessageLog{
+			ChannelID: "C12345",
+			Timestamp: "1234567890.123456",
+			ThreadTS:  "1234567890.123456",
+		}
+		gt.Equal(t, msg.Permalink("https://example.slack.com"),
+			"https://example.slack.com/archives/C12345/p1234567890123456")
+	})
+
+	t.Run("falls back to slack.com without workspace URL", func(t *testing.T) {
+		msg := &slack.SlackMessageLog{
+			ChannelID: "C12345",
+			Timestamp: "1234567890.123456",
+		}
+		gt.Equal(t, msg.Permalink(""),
+			"https://slack.com/archives/C12345/p1234567890123456")
+	})
+}
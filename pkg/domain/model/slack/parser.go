@@ -1,16 +1,25 @@
 package slack
 
 import (
+	"net/url"
 	"regexp"
 	"strings"
 )
 
 var mentionPattern = regexp.MustCompile(`<@([A-Z0-9]+)>`)
 
+// messagePathPattern matches the path of a message permalink, e.g. /archives/C123ABC/p1700000000123456
+var messagePathPattern = regexp.MustCompile(`^/archives/([A-Z0-9]+)/p([0-9]{10})([0-9]{6})$`)
+
+// DraftSuffix is the suffix of an agent ID in a mention to talk to the draft version of the agent,
+// e.g. @tamamo sre-helper@draft
+const DraftSuffix = "@draft"
+
 // AgentMention represents an agent mention with agent ID
 type AgentMention struct {
 	UserID  string
 	AgentID string // Agent ID (empty for general mode)
+	Draft   bool   // The agent ID has DraftSuffix
 	Message string
 }
 
@@ -69,12 +78,17 @@ func ParseAgentMention(text string) []AgentMention {
 
 		// Parse agent ID from the beginning of the message
 		agentID := ""
+		draft := false
 		if message != "" {
 			parts := strings.Fields(message)
 			if len(parts) > 0 {
 				// Check if first word looks like an agent ID
 				// Agent IDs should contain dashes or be specifically formatted alphanumeric
 				firstWord := parts[0]
+				if trimmed, ok := strings.CutSuffix(firstWord, DraftSuffix); ok && isValidAgentID(trimmed) {
+					firstWord = trimmed
+					draft = true
+				}
 				if isValidAgentID(firstWord) {
 					agentID = firstWord
 					// Remove agent ID from message
@@ -90,6 +104,7 @@ func ParseAgentMention(text string) []AgentMention {
 		mentions = append(mentions, AgentMention{
 			UserID:  userID,
 			AgentID: agentID,
+			Draft:   draft,
 			Message: message,
 		})
 	}
@@ -132,3 +147,33 @@ func isAlphanumeric(s string) bool {
 	}
 	return true
 }
+
+// ParseThreadURL extracts channel ID and thread timestamp from a Slack message permalink.
+// If the link points to a reply, the timestamp of the parent message is returned.
+// Links escaped by Slack such as <https://...|label> are also accepted.
+func ParseThreadURL(link string) (channelID, threadTS string, err error) {
+	link = strings.TrimSpace(link)
+	link = strings.TrimPrefix(link, "<")
+	link = strings.TrimSuffix(link, ">")
+	if idx := strings.Index(link, "|"); idx >= 0 {
+		link = link[:idx]
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return "", "", ErrInvalidThreadURL
+	}
+
+	match := messagePathPattern.FindStringSubmatch(u.Path)
+	if match == nil {
+		return "", "", ErrInvalidThreadURL
+	}
+
+	channelID = match[1]
+	threadTS = match[2] + "." + match[3]
+	if ts := u.Query().Get("thread_ts"); ts != "" {
+		threadTS = ts
+	}
+
+	return channelID, threadTS, nil
+}
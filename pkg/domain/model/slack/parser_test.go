@@ -46,6 +46,29 @@ func TestParseAgentMention(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "draft of agent",
+			text: "<@U123456> code-helper@draft please help me debug this",
+			expected: []slack.AgentMention{
+				{
+					UserID:  "U123456",
+					AgentID: "code-helper",
+					Draft:   true,
+					Message: "please help me debug this",
+				},
+			},
+		},
+		{
+			name: "draft suffix without agent ID",
+			text: "<@U123456> @draft please help me",
+			expected: []slack.AgentMention{
+				{
+					UserID:  "U123456",
+					AgentID: "",
+					Message: "@draft please help me",
+				},
+			},
+		},
 		{
 			name: "2+ character word (valid agent ID)",
 			text: "<@U123456> hello how are you?",
@@ -220,6 +243,7 @@ func TestParseAgentMention(t *testing.T) {
 			for i, expected := range tt.expected {
 				gt.V(t, result[i].UserID).Equal(expected.UserID)
 				gt.V(t, result[i].AgentID).Equal(expected.AgentID)
+				gt.V(t, result[i].Draft).Equal(expected.Draft)
 				gt.V(t, result[i].Message).Equal(expected.Message)
 			}
 		})
@@ -293,3 +317,55 @@ func TestIsValidAgentID(t *testing.T) {
 		})
 	}
 }
+
+func TestParseThreadURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		link      string
+		channelID string
+		threadTS  string
+		wantErr   bool
+	}{
+		{
+			name:      "parent message",
+			link:      "https://example.slack.com/archives/C0123ABCD/p1700000000123456",
+			channelID: "C0123ABCD",
+			threadTS:  "1700000000.123456",
+		},
+		{
+			name:      "reply in thread",
+			link:      "https://example.slack.com/archives/C0123ABCD/p1700000100000001?thread_ts=1700000000.123456&cid=C0123ABCD",
+			channelID: "C0123ABCD",
+			threadTS:  "1700000000.123456",
+		},
+		{
+			name:      "escaped by slack",
+			link:      "<https://example.slack.com/archives/C0123ABCD/p1700000000123456|link>",
+			channelID: "C0123ABCD",
+			threadTS:  "1700000000.123456",
+		},
+		{
+			name:    "not a message link",
+			link:    "https://example.slack.com/archives/C0123ABCD",
+			wantErr: true,
+		},
+		{
+			name:    "not a URL",
+			link:    "code-helper",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channelID, threadTS, err := slack.ParseThreadURL(tt.link)
+			if tt.wantErr {
+				gt.Error(t, err)
+				return
+			}
+			gt.NoError(t, err)
+			gt.Equal(t, channelID, tt.channelID)
+			gt.Equal(t, threadTS, tt.threadTS)
+		})
+	}
+}
@@ -2,10 +2,15 @@ package slack
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	slackapi "github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
@@ -40,6 +45,74 @@ type Message struct {
 	Channel  string    `json:"channel,omitempty"`   // From Slack events
 	TeamID   string    `json:"team_id,omitempty"`   // From Slack events
 	Mentions []Mention `json:"mentions,omitempty"`  // From Slack events
+	Files    []File    `json:"files,omitempty"`     // From Slack events
+}
+
+// File represents a file attached to a Slack message
+type File struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Mimetype    string `json:"mimetype"`
+	Size        int    `json:"size"`
+	DownloadURL string `json:"download_url"`
+}
+
+// IsImage reports whether the file is an image
+func (f File) IsImage() bool {
+	return strings.HasPrefix(f.Mimetype, "image/")
+}
+
+// newFiles converts files of Slack API to File
+func newFiles(files []slackapi.File) []File {
+	if len(files) == 0 {
+		return nil
+	}
+
+	result := make([]File, 0, len(files))
+	for _, f := range files {
+		downloadURL := f.URLPrivateDownload
+		if downloadURL == "" {
+			downloadURL = f.URLPrivate
+		}
+		result = append(result, File{
+			ID:          f.ID,
+			Name:        f.Name,
+			Mimetype:    f.Mimetype,
+			Size:        f.Size,
+			DownloadURL: downloadURL,
+		})
+	}
+	return result
+}
+
+// ParseEventFiles extracts files attached to the message of an Events API payload.
+// slackevents.AppMentionEvent does not have files, so they are read from the raw payload.
+func ParseEventFiles(body []byte) ([]File, error) {
+	var payload struct {
+		Event struct {
+			Files []slackapi.File `json:"files"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse files of slack event")
+	}
+	return newFiles(payload.Event.Files), nil
+}
+
+// NewConversationMessage creates a message of a conversation through the chat API. The timestamp
+// is formatted like Slack timestamps, so that messages are ordered in the same way.
+func NewConversationMessage(ctx context.Context, thread *Thread, text string) *Message {
+	now := time.Now()
+	return &Message{
+		ID:        types.NewMessageID(ctx),
+		Text:      text,
+		Timestamp: fmt.Sprintf("%d.%06d", now.Unix(), now.Nanosecond()/1000),
+		CreatedAt: now,
+		ThreadID:  thread.ID,
+		ThreadTS:  thread.ThreadTS,
+		Channel:   thread.ChannelID,
+		TeamID:    thread.TeamID,
+	}
 }
 
 // GetThreadTS returns the thread timestamp for this message
@@ -114,6 +187,7 @@ func NewMessage(ctx context.Context, ev *slackevents.EventsAPIEvent) *Message {
 			Text:      inEv.Text,
 			Timestamp: inEv.TimeStamp,
 			Mentions:  ParseMention(inEv.Text),
+			Files:     messageEventFiles(inEv),
 			CreatedAt: time.Now(),
 		}
 
@@ -123,6 +197,32 @@ func NewMessage(ctx context.Context, ev *slackevents.EventsAPIEvent) *Message {
 	}
 }
 
+// NewReplyMessage creates a Message from a reply fetched with conversations.replies API
+func NewReplyMessage(ctx context.Context, teamID, channelID string, msg slackapi.Message) *Message {
+	return &Message{
+		ID:        types.NewMessageID(ctx),
+		ThreadTS:  msg.ThreadTimestamp,
+		Channel:   channelID,
+		TeamID:    teamID,
+		UserID:    msg.User,
+		BotID:     msg.BotID,
+		UserName:  getUserDisplayName(msg.User, msg.BotID),
+		Text:      msg.Text,
+		Timestamp: msg.Timestamp,
+		Mentions:  ParseMention(msg.Text),
+		Files:     newFiles(msg.Files),
+		CreatedAt: time.Now(),
+	}
+}
+
+// messageEventFiles returns files attached to the message event
+func messageEventFiles(ev *slackevents.MessageEvent) []File {
+	if ev.Message == nil {
+		return nil
+	}
+	return newFiles(ev.Message.Files)
+}
+
 // getUserDisplayName returns a display name for the user or bot
 // For now, returns the ID as placeholder - TODO: implement proper Slack API integration
 func getUserDisplayName(userID, botID string) string {
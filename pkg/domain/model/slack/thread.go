@@ -7,14 +7,30 @@ import (
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
 )
 
+// ThreadSource is where the conversation of a thread takes place
+type ThreadSource string
+
+const (
+	// ThreadSourceSlack is a thread in a Slack channel
+	ThreadSourceSlack ThreadSource = "slack"
+	// ThreadSourceAPI is a conversation started through the chat API
+	ThreadSourceAPI ThreadSource = "api"
+)
+
+// ConversationChannelID is the channel ID of conversations started through the chat API. They
+// are not in any Slack channel.
+const ConversationChannelID = "api"
+
 // Thread represents a Slack conversation thread
 type Thread struct {
 	ID           types.ThreadID
 	TeamID       string
 	ChannelID    string
 	ThreadTS     string
-	AgentUUID    *types.UUID // Agent UUID (nullable, special UUID for general mode)
-	AgentVersion string      // Agent version
+	AgentUUID    *types.UUID  // Agent UUID (nullable, special UUID for general mode)
+	AgentVersion string       // Agent version
+	Source       ThreadSource // Empty for threads saved before the chat API was added
+	UserID       types.UserID // User who started the conversation through the chat API
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
@@ -47,6 +63,29 @@ func NewThreadWithAgent(ctx context.Context, teamID, channelID, threadTS string,
 	}
 }
 
+// NewConversation creates a new Thread of a conversation with the agent through the chat API.
+// The thread ID is used as the thread timestamp because there is no Slack message.
+func NewConversation(ctx context.Context, teamID string, userID types.UserID, agentUUID types.UUID, agentVersion string) *Thread {
+	t := NewThreadWithAgent(ctx, teamID, ConversationChannelID, "", &agentUUID, agentVersion)
+	t.ThreadTS = t.ID.String()
+	t.Source = ThreadSourceAPI
+	t.UserID = userID
+	return t
+}
+
+// GetSource returns where the conversation of the thread takes place
+func (t *Thread) GetSource() ThreadSource {
+	if t.Source == "" {
+		return ThreadSourceSlack
+	}
+	return t.Source
+}
+
+// IsConversation returns true if the thread is a conversation through the chat API
+func (t *Thread) IsConversation() bool {
+	return t.GetSource() == ThreadSourceAPI
+}
+
 // Validate checks if the thread has valid fields
 func (t *Thread) Validate() error {
 	if !t.ID.IsValid() {
@@ -62,6 +101,9 @@ func (t *Thread) Validate() error {
 	if t.AgentUUID != nil && !t.AgentUUID.IsValid() {
 		return ErrInvalidAgentUUID
 	}
+	if t.IsConversation() && !t.UserID.IsValid() {
+		return ErrEmptyUserID
+	}
 	// ThreadTS can be empty for new threads starting from channel-level messages
 	return nil
 }
@@ -256,3 +256,24 @@ func TestThreadCompatibility(t *testing.T) {
 		gt.V(t, thread.AgentVersion).Equal("v1.0.0")
 	})
 }
+
+func TestNewConversation(t *testing.T) {
+	ctx := context.Background()
+	agentUUID := types.NewUUID(ctx)
+	userID := types.NewUserID(ctx)
+
+	thread := slack.NewConversation(ctx, "T123456", userID, agentUUID, "1.0.0")
+	gt.V(t, thread.Validate()).Equal(nil)
+	gt.V(t, thread.IsConversation()).Equal(true)
+	gt.V(t, thread.UserID).Equal(userID)
+	gt.V(t, thread.ChannelID).Equal(slack.ConversationChannelID)
+	gt.V(t, thread.ThreadTS).Equal(thread.ID.String())
+
+	// Threads saved before the source was introduced are threads in Slack
+	legacy := slack.NewThread(ctx, "T123456", "C123456", "1234567890.123456")
+	gt.V(t, legacy.GetSource()).Equal(slack.ThreadSourceSlack)
+	gt.V(t, legacy.IsConversation()).Equal(false)
+
+	thread.UserID = ""
+	gt.V(t, errors.Is(thread.Validate(), slack.ErrEmptyUserID)).Equal(true)
+}
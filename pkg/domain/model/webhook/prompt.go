@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// DefaultPromptTemplate passes the whole payload to the agent
+const DefaultPromptTemplate = "Webhook \"{{.Name}}\" received the following payload.\n\n```json\n{{json .Payload}}\n```"
+
+// PromptContext is the context to render the prompt template of a webhook. Fields of the
+// payload are referred as {{.Payload.alert.title}}, and {{json .Payload.alert}} renders a
+// value as JSON.
+type PromptContext struct {
+	Name    string
+	Payload any
+	Now     time.Time
+}
+
+var promptFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		raw, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	},
+}
+
+func parsePromptTemplate(prompt string) (*template.Template, error) {
+	if prompt == "" {
+		prompt = DefaultPromptTemplate
+	}
+	tmpl, err := template.New("webhook_prompt").Funcs(promptFuncs).Option("missingkey=zero").Parse(prompt)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to parse prompt template of webhook")
+	}
+	return tmpl, nil
+}
+
+// ParsePayload parses the JSON payload posted to a webhook. Numbers are kept as written.
+func ParsePayload(body []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, goerr.Wrap(err, "webhook payload is not valid JSON")
+	}
+	if decoder.More() {
+		return nil, goerr.New("webhook payload has data after JSON")
+	}
+	return payload, nil
+}
+
+// RenderPrompt renders the prompt template of the webhook with the payload
+func (w *Webhook) RenderPrompt(payload any) (string, error) {
+	tmpl, err := parsePromptTemplate(w.PromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, &PromptContext{Name: w.Name, Payload: payload, Now: time.Now()}); err != nil {
+		return "", goerr.Wrap(err, "failed to render prompt template of webhook")
+	}
+
+	prompt := strings.TrimSpace(b.String())
+	if prompt == "" {
+		return "", goerr.New("rendered prompt of webhook is empty")
+	}
+	if len(prompt) > MaxPromptLength {
+		return "", goerr.New("rendered prompt of webhook is too long",
+			goerr.V("length", len(prompt)),
+			goerr.V("max", MaxPromptLength))
+	}
+	return prompt, nil
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// MaxPayloadSize is the maximum size of a payload posted to a webhook in bytes
+	MaxPayloadSize = 1 << 20
+
+	// MaxPromptTemplateLength is the maximum length of the prompt template of a webhook
+	MaxPromptTemplateLength = 8000
+
+	// MaxPromptLength is the maximum length of a prompt rendered from a payload
+	MaxPromptLength = 32 * 1024
+
+	// SignatureHeader is the HTTP header of the HMAC signature of a payload
+	SignatureHeader = "X-Tamamo-Signature"
+
+	// TimestampHeader is the HTTP header of the time the payload was signed at, in Unix seconds
+	TimestampHeader = "X-Tamamo-Timestamp"
+
+	// SignatureTolerance is how far the signed time may be from now, to reject replayed payloads
+	SignatureTolerance = 5 * time.Minute
+
+	// signaturePrefix is the prefix of the signature, in the same format as GitHub webhooks
+	signaturePrefix = "sha256="
+
+	// tokenBytes is the number of random bytes of a token
+	tokenBytes = 32
+)
+
+// ErrWebhookNotFound is returned when the webhook does not exist
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// Webhook runs an agent with a prompt rendered from a JSON payload posted to
+// /hooks/agents/{agent UUID}/{token}, and posts the response to a channel or a thread
+type Webhook struct {
+	ID        types.UUID `json:"id"`
+	AgentUUID types.UUID `json:"agent_uuid"`
+	// AgentVersion is the version of the agent to run. The latest version is used if empty.
+	AgentVersion string `json:"agent_version"`
+	Name         string `json:"name"`
+	// PromptTemplate is a Go template rendered with the payload as {{.Payload}}.
+	// DefaultPromptTemplate is used if empty.
+	PromptTemplate string `json:"prompt_template"`
+	ChannelID      string `json:"channel_id"`
+	// ThreadTS is the thread to reply to. A new thread is started if empty.
+	ThreadTS string `json:"thread_ts"`
+	// TokenHash is the SHA-256 hash of the token. The token itself is not stored.
+	TokenHash string `json:"token_hash"`
+	// SigningSecret is the secret to verify HMAC signatures of payloads. Signatures are not
+	// verified if empty.
+	SigningSecret string    `json:"signing_secret"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewWebhook creates a new Webhook of the agent
+func NewWebhook(ctx context.Context, agentUUID types.UUID) *Webhook {
+	now := time.Now()
+	return &Webhook{
+		ID:        types.NewUUID(ctx),
+		AgentUUID: agentUUID,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate validates the webhook
+func (w *Webhook) Validate() error {
+	if !w.ID.IsValid() {
+		return goerr.New("invalid webhook ID", goerr.V("id", w.ID))
+	}
+	if !w.AgentUUID.IsValid() {
+		return goerr.New("invalid agent UUID of webhook", goerr.V("agent_uuid", w.AgentUUID))
+	}
+	if strings.TrimSpace(w.Name) == "" {
+		return goerr.New("name of webhook is required")
+	}
+	if w.ChannelID == "" {
+		return goerr.New("channel ID of webhook is required")
+	}
+	if w.TokenHash == "" {
+		return goerr.New("token of webhook is not set")
+	}
+	if len(w.PromptTemplate) > MaxPromptTemplateLength {
+		return goerr.New("prompt template of webhook is too long",
+			goerr.V("length", len(w.PromptTemplate)),
+			goerr.V("max", MaxPromptTemplateLength))
+	}
+	if _, err := parsePromptTemplate(w.PromptTemplate); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RotateToken generates a new token of the webhook and returns it. Only the hash of the token is
+// kept, so the token must be passed to the owner of the webhook now.
+func (w *Webhook) RotateToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", goerr.Wrap(err, "failed to generate webhook token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	w.TokenHash = HashToken(token)
+	return token, nil
+}
+
+// VerifyToken reports whether the token is the token of the webhook
+func (w *Webhook) VerifyToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(w.TokenHash)) == 1
+}
+
+// VerifySignature verifies the HMAC signature of the payload signed at the timestamp. Payloads
+// signed more than SignatureTolerance away from now are rejected, so that a captured request can
+// not be replayed. It always succeeds if the webhook has no signing secret.
+func (w *Webhook) VerifySignature(body []byte, timestamp, signature string) error {
+	if w.SigningSecret == "" {
+		return nil
+	}
+	if signature == "" {
+		return goerr.New("signature of webhook payload is required")
+	}
+	if timestamp == "" {
+		return goerr.New("timestamp of webhook payload is required")
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return goerr.Wrap(err, "invalid timestamp of webhook payload", goerr.V("timestamp", timestamp))
+	}
+	if diff := time.Since(time.Unix(signedAt, 0)); diff > SignatureTolerance || diff < -SignatureTolerance {
+		return goerr.New("timestamp of webhook payload is out of tolerance",
+			goerr.V("timestamp", timestamp),
+			goerr.V("tolerance", SignatureTolerance))
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(w.SigningSecret, timestamp, body))) {
+		return goerr.New("signature of webhook payload is invalid")
+	}
+	return nil
+}
+
+// HashToken returns the hash of the token to be stored and looked up
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign returns the HMAC-SHA256 signature of "<timestamp>.<payload>" as "sha256=<hex>"
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Path returns the path to post payloads to the webhook
+func Path(agentUUID types.UUID, token string) string {
+	return "/hooks/agents/" + agentUUID.String() + "/" + token
+}
@@ -0,0 +1,131 @@
+package webhook_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+func newTestWebhook(t *testing.T) (*webhook.Webhook, string) {
+	t.Helper()
+	w := webhook.NewWebhook(context.Background(), types.NewUUID(context.Background()))
+	w.Name = "Datadog alerts"
+	w.ChannelID = "C12345"
+	token, err := w.RotateToken()
+	gt.NoError(t, err)
+	return w, token
+}
+
+func TestWebhookValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(w *webhook.Webhook)
+		shouldErr bool
+	}{
+		{name: "valid", modify: func(w *webhook.Webhook) {}},
+		{name: "custom template", modify: func(w *webhook.Webhook) { w.PromptTemplate = "Investigate {{.Payload.title}}" }},
+		{name: "no name", modify: func(w *webhook.Webhook) { w.Name = " " }, shouldErr: true},
+		{name: "no channel", modify: func(w *webhook.Webhook) { w.ChannelID = "" }, shouldErr: true},
+		{name: "no token", modify: func(w *webhook.Webhook) { w.TokenHash = "" }, shouldErr: true},
+		{name: "broken template", modify: func(w *webhook.Webhook) { w.PromptTemplate = "{{.Payload" }, shouldErr: true},
+		{
+			name:      "too long template",
+			modify:    func(w *webhook.Webhook) { w.PromptTemplate = strings.Repeat("a", webhook.MaxPromptTemplateLength+1) },
+			shouldErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, _ := newTestWebhook(t)
+			tc.modify(w)
+			if tc.shouldErr {
+				gt.Error(t, w.Validate())
+			} else {
+				gt.NoError(t, w.Validate())
+			}
+		})
+	}
+}
+
+func TestWebhookToken(t *testing.T) {
+	w, token := newTestWebhook(t)
+	gt.True(t, w.VerifyToken(token))
+	gt.False(t, w.VerifyToken(token+"x"))
+	gt.NotEqual(t, w.TokenHash, token)
+
+	rotated, err := w.RotateToken()
+	gt.NoError(t, err)
+	gt.NotEqual(t, rotated, token)
+	gt.True(t, w.VerifyToken(rotated))
+	gt.False(t, w.VerifyToken(token))
+}
+
+func TestWebhookVerifySignature(t *testing.T) {
+	w, _ := newTestWebhook(t)
+	body := []byte(`{"title":"CPU high"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	// Not verified without a signing secret
+	gt.NoError(t, w.VerifySignature(body, "", ""))
+
+	w.SigningSecret = "s3cret"
+	gt.NoError(t, w.VerifySignature(body, now, webhook.Sign("s3cret", now, body)))
+	gt.Error(t, w.VerifySignature(body, now, ""))
+	gt.Error(t, w.VerifySignature(body, "", webhook.Sign("s3cret", "", body)))
+	gt.Error(t, w.VerifySignature(body, now, webhook.Sign("other", now, body)))
+	gt.Error(t, w.VerifySignature([]byte(`{"title":"CPU low"}`), now, webhook.Sign("s3cret", now, body)))
+	gt.Error(t, w.VerifySignature(body, "yesterday", webhook.Sign("s3cret", "yesterday", body)))
+
+	t.Run("timestamp is a part of the signature", func(t *testing.T) {
+		other := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+		gt.Error(t, w.VerifySignature(body, other, webhook.Sign("s3cret", now, body)))
+	})
+
+	t.Run("timestamp out of tolerance", func(t *testing.T) {
+		for _, d := range []time.Duration{-webhook.SignatureTolerance - time.Minute, webhook.SignatureTolerance + time.Minute} {
+			ts := strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+			gt.Error(t, w.VerifySignature(body, ts, webhook.Sign("s3cret", ts, body)))
+		}
+	})
+}
+
+func TestWebhookRenderPrompt(t *testing.T) {
+	w, _ := newTestWebhook(t)
+	payload, err := webhook.ParsePayload([]byte(`{"title":"CPU high","host":{"name":"web-1"},"value":1234567}`))
+	gt.NoError(t, err)
+
+	t.Run("default template includes the payload", func(t *testing.T) {
+		prompt, err := w.RenderPrompt(payload)
+		gt.NoError(t, err)
+		gt.S(t, prompt).Contains(`Webhook "Datadog alerts"`)
+		gt.S(t, prompt).Contains(`"title": "CPU high"`)
+		gt.S(t, prompt).Contains(`"value": 1234567`)
+	})
+
+	t.Run("fields of payload", func(t *testing.T) {
+		w.PromptTemplate = "Investigate {{.Payload.title}} on {{.Payload.host.name}} ({{.Payload.value}})"
+		prompt, err := w.RenderPrompt(payload)
+		gt.NoError(t, err)
+		gt.Equal(t, prompt, "Investigate CPU high on web-1 (1234567)")
+	})
+
+	t.Run("empty prompt is rejected", func(t *testing.T) {
+		w.PromptTemplate = "{{if .Payload.missing}}never{{end}}"
+		_, err := w.RenderPrompt(payload)
+		gt.Error(t, err)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		_, err := webhook.ParsePayload([]byte(`{"title":`))
+		gt.Error(t, err)
+		_, err = webhook.ParsePayload([]byte(`{} {}`))
+		gt.Error(t, err)
+	})
+}
@@ -205,6 +205,48 @@ func testThreadRepository(t *testing.T, repo interfaces.ThreadRepository) {
 		// Agent information behavior on existing threads may vary by implementation
 		// Some implementations might update, others might preserve existing
 	})
+
+	t.Run("UpdateThreadAgent", func(t *testing.T) {
+		oldAgent := types.NewUUID(ctx)
+		th, err := repo.GetOrPutThreadWithAgent(ctx, "team-update", "channel-update", "ts-update", &oldAgent, "v1")
+		gt.NoError(t, err)
+
+		newAgent := types.NewUUID(ctx)
+		gt.NoError(t, repo.UpdateThreadAgent(ctx, th.ID, &newAgent, "v3"))
+
+		updated, err := repo.GetThread(ctx, th.ID)
+		gt.NoError(t, err)
+		gt.NotNil(t, updated.AgentUUID)
+		gt.Equal(t, newAgent, *updated.AgentUUID)
+		gt.Equal(t, "v3", updated.AgentVersion)
+	})
+
+	t.Run("ListHistories", func(t *testing.T) {
+		th, err := repo.GetOrPutThread(ctx, "team-histories", "channel-histories", "ts-histories")
+		gt.NoError(t, err)
+
+		agent1 := types.NewUUID(ctx)
+		agent2 := types.NewUUID(ctx)
+		h1 := slack.NewHistoryWithAgent(ctx, th.ID, &agent1, "v1")
+		h2 := slack.NewHistoryWithAgent(ctx, th.ID, &agent2, "v2")
+		h2.CreatedAt = h1.CreatedAt.Add(time.Second)
+		gt.NoError(t, repo.PutHistory(ctx, h2))
+		gt.NoError(t, repo.PutHistory(ctx, h1))
+
+		histories, err := repo.ListHistories(ctx, th.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(2)
+		gt.Equal(t, histories[0].ID, h1.ID)
+		gt.Equal(t, *histories[0].AgentUUID, agent1)
+		gt.Equal(t, histories[1].ID, h2.ID)
+		gt.Equal(t, histories[1].AgentVersion, "v2")
+	})
+
+	t.Run("UpdateThreadAgent_NotFound", func(t *testing.T) {
+		agentUUID := types.NewUUID(ctx)
+		err := repo.UpdateThreadAgent(ctx, types.NewThreadID(ctx), &agentUUID, "v1")
+		gt.Error(t, err)
+	})
 }
 
 func TestMemoryRepository(t *testing.T) {
@@ -40,16 +40,23 @@ const (
 
 // Agent Firestore document structure
 type agentDoc struct {
-	ID          string    `firestore:"id"`
-	AgentID     string    `firestore:"agent_id"`
-	Name        string    `firestore:"name"`
-	Description string    `firestore:"description"`
-	Author      string    `firestore:"author"`
-	Status      string    `firestore:"status"`
-	Latest      string    `firestore:"latest"`
-	ImageID     *string   `firestore:"image_id,omitempty"`
-	CreatedAt   time.Time `firestore:"created_at"`
-	UpdatedAt   time.Time `firestore:"updated_at"`
+	ID          string      `firestore:"id"`
+	AgentID     string      `firestore:"agent_id"`
+	Name        string      `firestore:"name"`
+	Description string      `firestore:"description"`
+	Author      string      `firestore:"author"`
+	Status      string      `firestore:"status"`
+	Latest      string      `firestore:"latest"`
+	ImageID     *string     `firestore:"image_id,omitempty"`
+	Rollout     *rolloutDoc `firestore:"rollout,omitempty"`
+	CreatedAt   time.Time   `firestore:"created_at"`
+	UpdatedAt   time.Time   `firestore:"updated_at"`
+}
+
+type rolloutDoc struct {
+	CandidateVersion string    `firestore:"candidate_version"`
+	Percentage       int       `firestore:"percentage"`
+	StartedAt        time.Time `firestore:"started_at"`
 }
 
 // toAgent converts agentDoc to domain Agent
@@ -69,7 +76,7 @@ func (d *agentDoc) toAgent() *agent.Agent {
 		}
 	}
 
-	return &agent.Agent{
+	agentObj := &agent.Agent{
 		ID:          types.UUID(d.ID),
 		AgentID:     d.AgentID,
 		Name:        d.Name,
@@ -81,6 +88,14 @@ func (d *agentDoc) toAgent() *agent.Agent {
 		CreatedAt:   d.CreatedAt,
 		UpdatedAt:   d.UpdatedAt,
 	}
+	if d.Rollout != nil {
+		agentObj.Rollout = &agent.Rollout{
+			CandidateVersion: d.Rollout.CandidateVersion,
+			Percentage:       d.Rollout.Percentage,
+			StartedAt:        d.Rollout.StartedAt,
+		}
+	}
+	return agentObj
 }
 
 // toAgentDoc converts domain Agent to agentDoc for Firestore storage
@@ -92,7 +107,7 @@ func toAgentDoc(agentObj *agent.Agent) *agentDoc {
 		imageID = &idStr
 	}
 
-	return &agentDoc{
+	doc := &agentDoc{
 		ID:          agentObj.ID.String(),
 		AgentID:     agentObj.AgentID,
 		Name:        agentObj.Name,
@@ -104,17 +119,138 @@ func toAgentDoc(agentObj *agent.Agent) *agentDoc {
 		CreatedAt:   agentObj.CreatedAt,
 		UpdatedAt:   agentObj.UpdatedAt,
 	}
+	if agentObj.Rollout != nil {
+		doc.Rollout = &rolloutDoc{
+			CandidateVersion: agentObj.Rollout.CandidateVersion,
+			Percentage:       agentObj.Rollout.Percentage,
+			StartedAt:        agentObj.Rollout.StartedAt,
+		}
+	}
+	return doc
 }
 
 // AgentVersion Firestore document structure
 type agentVersionDoc struct {
-	AgentUUID    string    `firestore:"agent_uuid"`
-	Version      string    `firestore:"version"`
-	SystemPrompt string    `firestore:"system_prompt"`
-	LLMProvider  string    `firestore:"llm_provider"`
-	LLMModel     string    `firestore:"llm_model"`
-	CreatedAt    time.Time `firestore:"created_at"`
-	UpdatedAt    time.Time `firestore:"updated_at"`
+	AgentUUID       string            `firestore:"agent_uuid"`
+	Version         string            `firestore:"version"`
+	SystemPrompt    string            `firestore:"system_prompt"`
+	PromptTemplate  bool              `firestore:"prompt_template,omitempty"`
+	PromptVariables map[string]string `firestore:"prompt_variables,omitempty"`
+	LLMProvider     string            `firestore:"llm_provider"`
+	LLMModel        string            `firestore:"llm_model"`
+	MCPServers      []*mcpServerDoc   `firestore:"mcp_servers,omitempty"`
+	Delegation      *delegationDoc    `firestore:"delegation,omitempty"`
+	Status          string            `firestore:"status,omitempty"`
+	Author          string            `firestore:"author,omitempty"`
+	PublishedAt     *time.Time        `firestore:"published_at,omitempty"`
+	Changelog       string            `firestore:"changelog,omitempty"`
+	CreatedAt       time.Time         `firestore:"created_at"`
+	UpdatedAt       time.Time         `firestore:"updated_at"`
+}
+
+type mcpServerDoc struct {
+	Name           string            `firestore:"name"`
+	Transport      string            `firestore:"transport"`
+	Command        string            `firestore:"command,omitempty"`
+	Args           []string          `firestore:"args,omitempty"`
+	Env            map[string]string `firestore:"env,omitempty"`
+	URL            string            `firestore:"url,omitempty"`
+	Headers        map[string]string `firestore:"headers,omitempty"`
+	AllowedTools   []string          `firestore:"allowed_tools,omitempty"`
+	TimeoutSeconds int               `firestore:"timeout_seconds,omitempty"`
+}
+
+type delegationDoc struct {
+	Enabled  bool     `firestore:"enabled"`
+	AgentIDs []string `firestore:"agent_ids,omitempty"`
+}
+
+// newAgentVersionDoc converts an agent version to Firestore document
+func newAgentVersionDoc(version *agent.AgentVersion) *agentVersionDoc {
+	// Ensure provider is normalized before saving
+	normalizedProvider := types.LLMProviderFromString(string(version.LLMProvider))
+
+	doc := &agentVersionDoc{
+		AgentUUID:       version.AgentUUID.String(),
+		Version:         version.Version,
+		SystemPrompt:    version.SystemPrompt,
+		PromptTemplate:  version.PromptTemplate,
+		PromptVariables: version.PromptVariables,
+		LLMProvider:     normalizedProvider.String(),
+		LLMModel:        version.LLMModel,
+		Status:          string(version.Status),
+		Author:          version.Author.String(),
+		PublishedAt:     version.PublishedAt,
+		Changelog:       version.Changelog,
+		CreatedAt:       version.CreatedAt,
+		UpdatedAt:       version.UpdatedAt,
+	}
+
+	for _, server := range version.MCPServers {
+		doc.MCPServers = append(doc.MCPServers, &mcpServerDoc{
+			Name:           server.Name,
+			Transport:      string(server.Transport),
+			Command:        server.Command,
+			Args:           server.Args,
+			Env:            server.Env,
+			URL:            server.URL,
+			Headers:        server.Headers,
+			AllowedTools:   server.AllowedTools,
+			TimeoutSeconds: server.TimeoutSeconds,
+		})
+	}
+
+	if version.Delegation != nil {
+		doc.Delegation = &delegationDoc{
+			Enabled:  version.Delegation.Enabled,
+			AgentIDs: version.Delegation.AgentIDs,
+		}
+	}
+
+	return doc
+}
+
+// toAgentVersion converts Firestore document to agent version
+func (d *agentVersionDoc) toAgentVersion() *agent.AgentVersion {
+	version := &agent.AgentVersion{
+		AgentUUID:       types.UUID(d.AgentUUID),
+		Version:         d.Version,
+		SystemPrompt:    d.SystemPrompt,
+		PromptTemplate:  d.PromptTemplate,
+		PromptVariables: d.PromptVariables,
+		// Normalize provider to ensure lowercase format
+		LLMProvider: types.LLMProviderFromString(d.LLMProvider),
+		LLMModel:    d.LLMModel,
+		Status:      agent.VersionStatus(d.Status),
+		Author:      types.UserID(d.Author),
+		PublishedAt: d.PublishedAt,
+		Changelog:   d.Changelog,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+
+	for _, server := range d.MCPServers {
+		version.MCPServers = append(version.MCPServers, &agent.MCPServer{
+			Name:           server.Name,
+			Transport:      agent.MCPTransport(server.Transport),
+			Command:        server.Command,
+			Args:           server.Args,
+			Env:            server.Env,
+			URL:            server.URL,
+			Headers:        server.Headers,
+			AllowedTools:   server.AllowedTools,
+			TimeoutSeconds: server.TimeoutSeconds,
+		})
+	}
+
+	if d.Delegation != nil {
+		version.Delegation = &agent.Delegation{
+			Enabled:  d.Delegation.Enabled,
+			AgentIDs: d.Delegation.AgentIDs,
+		}
+	}
+
+	return version
 }
 
 // CreateAgent creates a new agent
@@ -328,18 +464,7 @@ func (c *Client) CreateAgentVersion(ctx context.Context, version *agent.AgentVer
 	}
 	version.UpdatedAt = now
 
-	// Ensure provider is normalized before saving
-	normalizedProvider := types.LLMProviderFromString(string(version.LLMProvider))
-
-	doc := &agentVersionDoc{
-		AgentUUID:    version.AgentUUID.String(),
-		Version:      version.Version,
-		SystemPrompt: version.SystemPrompt,
-		LLMProvider:  normalizedProvider.String(),
-		LLMModel:     version.LLMModel,
-		CreatedAt:    version.CreatedAt,
-		UpdatedAt:    version.UpdatedAt,
-	}
+	doc := newAgentVersionDoc(version)
 
 	_, err := c.client.Collection(collectionAgents).Doc(version.AgentUUID.String()).Collection(subCollectionVersions).Doc(version.Version).Set(ctx, doc)
 	if err != nil {
@@ -364,7 +489,7 @@ func (c *Client) GetAgentVersion(ctx context.Context, agentUUID types.UUID, vers
 	doc, err := c.client.Collection(collectionAgents).Doc(agentUUID.String()).Collection(subCollectionVersions).Doc(version).Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return nil, goerr.New("agent version not found",
+			return nil, goerr.Wrap(agent.ErrAgentVersionNotFound, "agent version not found",
 				goerr.V("agent_uuid", agentUUID.String()),
 				goerr.V("version", version))
 		}
@@ -380,18 +505,7 @@ func (c *Client) GetAgentVersion(ctx context.Context, agentUUID types.UUID, vers
 			goerr.V("version", version))
 	}
 
-	// Normalize provider to ensure lowercase format
-	provider := types.LLMProviderFromString(versionDoc.LLMProvider)
-
-	return &agent.AgentVersion{
-		AgentUUID:    types.UUID(versionDoc.AgentUUID),
-		Version:      versionDoc.Version,
-		SystemPrompt: versionDoc.SystemPrompt,
-		LLMProvider:  provider,
-		LLMModel:     versionDoc.LLMModel,
-		CreatedAt:    versionDoc.CreatedAt,
-		UpdatedAt:    versionDoc.UpdatedAt,
-	}, nil
+	return versionDoc.toAgentVersion(), nil
 }
 
 // GetLatestAgentVersion retrieves the latest version of an agent
@@ -431,15 +545,7 @@ func (c *Client) ListAgentVersions(ctx context.Context, agentUUID types.UUID) ([
 				goerr.V("agent_uuid", agentUUID.String()))
 		}
 
-		versions = append(versions, &agent.AgentVersion{
-			AgentUUID:    types.UUID(versionDoc.AgentUUID),
-			Version:      versionDoc.Version,
-			SystemPrompt: versionDoc.SystemPrompt,
-			LLMProvider:  types.LLMProviderFromString(versionDoc.LLMProvider),
-			LLMModel:     versionDoc.LLMModel,
-			CreatedAt:    versionDoc.CreatedAt,
-			UpdatedAt:    versionDoc.UpdatedAt,
-		})
+		versions = append(versions, versionDoc.toAgentVersion())
 	}
 
 	return versions, nil
@@ -461,18 +567,7 @@ func (c *Client) UpdateAgentVersion(ctx context.Context, version *agent.AgentVer
 
 	version.UpdatedAt = time.Now()
 
-	// Ensure provider is normalized before saving
-	normalizedProvider := types.LLMProviderFromString(string(version.LLMProvider))
-
-	doc := &agentVersionDoc{
-		AgentUUID:    version.AgentUUID.String(),
-		Version:      version.Version,
-		SystemPrompt: version.SystemPrompt,
-		LLMProvider:  normalizedProvider.String(),
-		LLMModel:     version.LLMModel,
-		CreatedAt:    version.CreatedAt,
-		UpdatedAt:    version.UpdatedAt,
-	}
+	doc := newAgentVersionDoc(version)
 
 	_, err := c.client.Collection(collectionAgents).Doc(version.AgentUUID.String()).Collection(subCollectionVersions).Doc(version.Version).Set(ctx, doc)
 	if err != nil {
@@ -575,15 +670,7 @@ func (c *Client) ListAgentsWithLatestVersions(ctx context.Context, offset, limit
 				goerr.V("version", agentObj.Latest))
 		}
 
-		versions = append(versions, &agent.AgentVersion{
-			AgentUUID:    types.UUID(versionDocData.AgentUUID),
-			Version:      versionDocData.Version,
-			SystemPrompt: versionDocData.SystemPrompt,
-			LLMProvider:  types.LLMProviderFromString(versionDocData.LLMProvider),
-			LLMModel:     versionDocData.LLMModel,
-			CreatedAt:    versionDocData.CreatedAt,
-			UpdatedAt:    versionDocData.UpdatedAt,
-		})
+		versions = append(versions, versionDocData.toAgentVersion())
 	}
 
 	return agents, versions, totalCount, nil
@@ -672,15 +759,7 @@ func (c *Client) ListActiveAgentsWithLatestVersions(ctx context.Context, offset,
 				goerr.V("version", agentObj.Latest))
 		}
 
-		versions = append(versions, &agent.AgentVersion{
-			AgentUUID:    types.UUID(versionDocData.AgentUUID),
-			Version:      versionDocData.Version,
-			SystemPrompt: versionDocData.SystemPrompt,
-			LLMProvider:  types.LLMProviderFromString(versionDocData.LLMProvider),
-			LLMModel:     versionDocData.LLMModel,
-			CreatedAt:    versionDocData.CreatedAt,
-			UpdatedAt:    versionDocData.UpdatedAt,
-		})
+		versions = append(versions, versionDocData.toAgentVersion())
 	}
 
 	return agents, versions, totalCount, nil
@@ -772,15 +851,7 @@ func (c *Client) ListAgentsByStatusWithLatestVersions(ctx context.Context, agent
 				goerr.V("version", agentObj.Latest))
 		}
 
-		versions = append(versions, &agent.AgentVersion{
-			AgentUUID:    types.UUID(versionDocData.AgentUUID),
-			Version:      versionDocData.Version,
-			SystemPrompt: versionDocData.SystemPrompt,
-			LLMProvider:  types.LLMProviderFromString(versionDocData.LLMProvider),
-			LLMModel:     versionDocData.LLMModel,
-			CreatedAt:    versionDocData.CreatedAt,
-			UpdatedAt:    versionDocData.UpdatedAt,
-		})
+		versions = append(versions, versionDocData.toAgentVersion())
 	}
 
 	return agents, versions, totalCount, nil
@@ -0,0 +1,102 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"google.golang.org/api/iterator"
+)
+
+const agentMetricsCollection = "agent_version_metrics"
+
+// agentMetricsDoc is the Firestore document structure of the metrics of an agent version
+type agentMetricsDoc struct {
+	AgentUUID        string    `firestore:"agent_uuid"`
+	Version          string    `firestore:"version"`
+	Threads          int64     `firestore:"threads"`
+	Responses        int64     `firestore:"responses"`
+	Errors           int64     `firestore:"errors"`
+	TotalLatencyMS   int64     `firestore:"total_latency_ms"`
+	PositiveFeedback int64     `firestore:"positive_feedback"`
+	NegativeFeedback int64     `firestore:"negative_feedback"`
+	UpdatedAt        time.Time `firestore:"updated_at"`
+}
+
+type agentMetricsRepository struct {
+	client *firestore.Client
+}
+
+// NewAgentMetricsRepository creates a new agent metrics repository
+func NewAgentMetricsRepository(client *firestore.Client) interfaces.AgentMetricsRepository {
+	return &agentMetricsRepository{
+		client: client,
+	}
+}
+
+func (r *agentMetricsRepository) AddAgentVersionMetrics(ctx context.Context, delta *agent.VersionMetrics) error {
+	// Counters are incremented on the server so that concurrent turns are not lost
+	docID := delta.AgentUUID.String() + "_" + delta.Version
+	_, err := r.client.Collection(agentMetricsCollection).Doc(docID).Set(ctx, map[string]any{
+		"agent_uuid":        delta.AgentUUID.String(),
+		"version":           delta.Version,
+		"threads":           firestore.Increment(delta.Threads),
+		"responses":         firestore.Increment(delta.Responses),
+		"errors":            firestore.Increment(delta.Errors),
+		"total_latency_ms":  firestore.Increment(delta.TotalLatencyMS),
+		"positive_feedback": firestore.Increment(delta.PositiveFeedback),
+		"negative_feedback": firestore.Increment(delta.NegativeFeedback),
+		"updated_at":        time.Now(),
+	}, firestore.MergeAll)
+	if err != nil {
+		return goerr.Wrap(err, "failed to add agent version metrics",
+			goerr.V("agent_uuid", delta.AgentUUID),
+			goerr.V("version", delta.Version))
+	}
+
+	return nil
+}
+
+func (r *agentMetricsRepository) ListAgentVersionMetrics(ctx context.Context, agentUUID types.UUID) ([]*agent.VersionMetrics, error) {
+	iter := r.client.Collection(agentMetricsCollection).
+		Where("agent_uuid", "==", agentUUID.String()).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var result []*agent.VersionMetrics
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate agent version metrics", goerr.V("agent_uuid", agentUUID))
+		}
+
+		var doc agentMetricsDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal agent version metrics", goerr.V("id", snapshot.Ref.ID))
+		}
+		result = append(result, &agent.VersionMetrics{
+			AgentUUID:        types.UUID(doc.AgentUUID),
+			Version:          doc.Version,
+			Threads:          doc.Threads,
+			Responses:        doc.Responses,
+			Errors:           doc.Errors,
+			TotalLatencyMS:   doc.TotalLatencyMS,
+			PositiveFeedback: doc.PositiveFeedback,
+			NegativeFeedback: doc.NegativeFeedback,
+			UpdatedAt:        doc.UpdatedAt,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const apiTokenCollection = "api_tokens"
+
+type apiTokenRepository struct {
+	client *firestore.Client
+}
+
+// NewAPITokenRepository creates a new Firestore API token repository
+func NewAPITokenRepository(client *firestore.Client) interfaces.APITokenRepository {
+	return &apiTokenRepository{
+		client: client,
+	}
+}
+
+func (r *apiTokenRepository) PutAPIToken(ctx context.Context, token *auth.APIToken) error {
+	if err := token.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid API token")
+	}
+
+	_, err := r.client.Collection(apiTokenCollection).Doc(token.ID.String()).Set(ctx, token)
+	if err != nil {
+		return goerr.Wrap(err, "failed to put API token", goerr.V("id", token.ID))
+	}
+
+	return nil
+}
+
+func (r *apiTokenRepository) GetAPITokenByHash(ctx context.Context, tokenHash string) (*auth.APIToken, error) {
+	iter := r.client.Collection(apiTokenCollection).
+		Where("token_hash", "==", tokenHash).
+		Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+
+	snapshot, err := iter.Next()
+	if err == iterator.Done {
+		return nil, goerr.Wrap(auth.ErrAPITokenNotFound, "failed to get API token by hash")
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get API token by hash")
+	}
+
+	var token auth.APIToken
+	if err := snapshot.DataTo(&token); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal API token", goerr.V("id", snapshot.Ref.ID))
+	}
+
+	return &token, nil
+}
+
+func (r *apiTokenRepository) ListAPITokensByUser(ctx context.Context, userID types.UserID) ([]*auth.APIToken, error) {
+	iter := r.client.Collection(apiTokenCollection).
+		Where("user_id", "==", userID.String()).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var tokens []*auth.APIToken
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate API tokens", goerr.V("user_id", userID))
+		}
+
+		var token auth.APIToken
+		if err := snapshot.DataTo(&token); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal API token", goerr.V("id", snapshot.Ref.ID))
+		}
+		tokens = append(tokens, &token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) DeleteAPIToken(ctx context.Context, id types.UUID) error {
+	_, err := r.client.Collection(apiTokenCollection).Doc(id.String()).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(auth.ErrAPITokenNotFound, "failed to delete API token", goerr.V("id", id))
+		}
+		return goerr.Wrap(err, "failed to delete API token", goerr.V("id", id))
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package firestore
 
 import (
 	"context"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/m-mizutani/goerr/v2"
@@ -175,6 +176,42 @@ func (c *Client) GetThreadByTS(ctx context.Context, channelID, threadTS string)
 	return &t, nil
 }
 
+// PutThread saves the thread to Firestore as is
+func (c *Client) PutThread(ctx context.Context, thread *slack.Thread) error {
+	if err := thread.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid thread", goerr.V("thread_id", thread.ID))
+	}
+
+	if _, err := c.client.Collection(collectionThreads).Doc(thread.ID.String()).Set(ctx, thread); err != nil {
+		return goerr.Wrap(err, "failed to put thread",
+			goerr.V("thread_id", thread.ID),
+			goerr.V("repository", "firestore"))
+	}
+
+	return nil
+}
+
+// UpdateThreadAgent changes the agent bound to the thread in Firestore
+func (c *Client) UpdateThreadAgent(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) error {
+	_, err := c.client.Collection(collectionThreads).Doc(threadID.String()).Update(ctx, []firestore.Update{
+		{Path: "AgentUUID", Value: agentUUID},
+		{Path: "AgentVersion", Value: agentVersion},
+		{Path: "UpdatedAt", Value: time.Now()},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(slack.ErrThreadNotFound, "thread not found",
+				goerr.V("thread_id", threadID),
+				goerr.V("repository", "firestore"))
+		}
+		return goerr.Wrap(err, "failed to update thread agent",
+			goerr.V("thread_id", threadID),
+			goerr.V("repository", "firestore"))
+	}
+
+	return nil
+}
+
 // ListThreads retrieves a paginated list of threads sorted by creation time (newest first)
 func (c *Client) ListThreads(ctx context.Context, offset, limit int) ([]*slack.Thread, int, error) {
 	// Validate parameters
@@ -365,6 +402,39 @@ func (c *Client) GetLatestHistory(ctx context.Context, threadID types.ThreadID)
 	return &h, nil
 }
 
+// ListHistories retrieves all history records of a thread sorted by creation time (oldest first)
+func (c *Client) ListHistories(ctx context.Context, threadID types.ThreadID) ([]*slack.History, error) {
+	// Check if thread exists
+	_, err := c.GetThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := c.client.Collection(collectionThreads).Doc(threadID.String()).
+		Collection(collectionHistories).
+		OrderBy("CreatedAt", firestore.Asc).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list histories",
+			goerr.V("thread_id", threadID),
+			goerr.V("repository", "firestore"))
+	}
+
+	histories := make([]*slack.History, 0, len(docs))
+	for _, doc := range docs {
+		var h slack.History
+		if err := doc.DataTo(&h); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal history",
+				goerr.V("thread_id", threadID),
+				goerr.V("history_id", doc.Ref.ID),
+				goerr.V("repository", "firestore"))
+		}
+		histories = append(histories, &h)
+	}
+
+	return histories, nil
+}
+
 // GetHistoryByID retrieves a specific history record by ID
 // Uses collection group query to search across all histories subcollections under threads
 func (c *Client) GetHistoryByID(ctx context.Context, id types.HistoryID) (*slack.History, error) {
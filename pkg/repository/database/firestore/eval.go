@@ -0,0 +1,288 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	evalCaseCollection = "agent_eval_cases"
+	evalRunCollection  = "agent_eval_runs"
+)
+
+// evalCaseDoc is the Firestore document structure of a test case
+type evalCaseDoc struct {
+	ID               string    `firestore:"id"`
+	AgentUUID        string    `firestore:"agent_uuid"`
+	Name             string    `firestore:"name"`
+	Input            string    `firestore:"input"`
+	ExpectedFacts    []string  `firestore:"expected_facts"`
+	ExpectedPatterns []string  `firestore:"expected_patterns"`
+	Rubric           string    `firestore:"rubric"`
+	CreatedAt        time.Time `firestore:"created_at"`
+	UpdatedAt        time.Time `firestore:"updated_at"`
+}
+
+// evalRunDoc is the Firestore document structure of an eval run. Results of cases are embedded
+// because they are always read together.
+type evalRunDoc struct {
+	ID           string              `firestore:"id"`
+	AgentUUID    string              `firestore:"agent_uuid"`
+	AgentVersion string              `firestore:"agent_version"`
+	Status       string              `firestore:"status"`
+	Passed       int                 `firestore:"passed"`
+	Failed       int                 `firestore:"failed"`
+	Results      []evalCaseResultDoc `firestore:"results"`
+	StartedAt    time.Time           `firestore:"started_at"`
+	FinishedAt   *time.Time          `firestore:"finished_at"`
+	Error        string              `firestore:"error"`
+}
+
+type evalCaseResultDoc struct {
+	CaseID      string   `firestore:"case_id"`
+	CaseName    string   `firestore:"case_name"`
+	Input       string   `firestore:"input"`
+	Output      string   `firestore:"output"`
+	Passed      bool     `firestore:"passed"`
+	Failures    []string `firestore:"failures"`
+	JudgeReason string   `firestore:"judge_reason"`
+	Error       string   `firestore:"error"`
+	DurationMS  int64    `firestore:"duration_ms"`
+}
+
+type evalRepository struct {
+	client *firestore.Client
+}
+
+// NewEvalRepository creates a new eval repository
+func NewEvalRepository(client *firestore.Client) interfaces.EvalRepository {
+	return &evalRepository{
+		client: client,
+	}
+}
+
+func (r *evalRepository) PutEvalCase(ctx context.Context, c *eval.Case) error {
+	if err := c.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid eval case")
+	}
+
+	_, err := r.client.Collection(evalCaseCollection).Doc(c.ID.String()).Set(ctx, evalCaseToDoc(c))
+	if err != nil {
+		return goerr.Wrap(err, "failed to put eval case", goerr.V("id", c.ID))
+	}
+
+	return nil
+}
+
+func (r *evalRepository) GetEvalCase(ctx context.Context, id types.UUID) (*eval.Case, error) {
+	snapshot, err := r.client.Collection(evalCaseCollection).Doc(id.String()).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(eval.ErrCaseNotFound, "failed to get eval case", goerr.V("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get eval case", goerr.V("id", id))
+	}
+
+	var doc evalCaseDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal eval case", goerr.V("id", id))
+	}
+
+	return docToEvalCase(&doc), nil
+}
+
+func (r *evalRepository) ListEvalCases(ctx context.Context, agentUUID types.UUID) ([]*eval.Case, error) {
+	iter := r.client.Collection(evalCaseCollection).
+		Where("agent_uuid", "==", agentUUID.String()).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var cases []*eval.Case
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate eval cases", goerr.V("agent_uuid", agentUUID))
+		}
+
+		var doc evalCaseDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal eval case", goerr.V("id", snapshot.Ref.ID))
+		}
+		cases = append(cases, docToEvalCase(&doc))
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].CreatedAt.Before(cases[j].CreatedAt)
+	})
+	return cases, nil
+}
+
+func (r *evalRepository) DeleteEvalCase(ctx context.Context, id types.UUID) error {
+	_, err := r.client.Collection(evalCaseCollection).Doc(id.String()).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(eval.ErrCaseNotFound, "failed to delete eval case", goerr.V("id", id))
+		}
+		return goerr.Wrap(err, "failed to delete eval case", goerr.V("id", id))
+	}
+
+	return nil
+}
+
+func (r *evalRepository) PutEvalRun(ctx context.Context, run *eval.Run) error {
+	_, err := r.client.Collection(evalRunCollection).Doc(run.ID.String()).Set(ctx, evalRunToDoc(run))
+	if err != nil {
+		return goerr.Wrap(err, "failed to put eval run", goerr.V("id", run.ID))
+	}
+
+	return nil
+}
+
+func (r *evalRepository) GetEvalRun(ctx context.Context, id types.UUID) (*eval.Run, error) {
+	snapshot, err := r.client.Collection(evalRunCollection).Doc(id.String()).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(eval.ErrRunNotFound, "failed to get eval run", goerr.V("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get eval run", goerr.V("id", id))
+	}
+
+	var doc evalRunDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal eval run", goerr.V("id", id))
+	}
+
+	return docToEvalRun(&doc), nil
+}
+
+func (r *evalRepository) ListEvalRuns(ctx context.Context, agentUUID types.UUID, limit int) ([]*eval.Run, error) {
+	// Runs are sorted in memory to avoid a composite index
+	iter := r.client.Collection(evalRunCollection).
+		Where("agent_uuid", "==", agentUUID.String()).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var runs []*eval.Run
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate eval runs", goerr.V("agent_uuid", agentUUID))
+		}
+
+		var doc evalRunDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal eval run", goerr.V("id", snapshot.Ref.ID))
+		}
+		runs = append(runs, docToEvalRun(&doc))
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func evalCaseToDoc(c *eval.Case) *evalCaseDoc {
+	return &evalCaseDoc{
+		ID:               c.ID.String(),
+		AgentUUID:        c.AgentUUID.String(),
+		Name:             c.Name,
+		Input:            c.Input,
+		ExpectedFacts:    c.ExpectedFacts,
+		ExpectedPatterns: c.ExpectedPatterns,
+		Rubric:           c.Rubric,
+		CreatedAt:        c.CreatedAt,
+		UpdatedAt:        c.UpdatedAt,
+	}
+}
+
+func docToEvalCase(doc *evalCaseDoc) *eval.Case {
+	return &eval.Case{
+		ID:               types.UUID(doc.ID),
+		AgentUUID:        types.UUID(doc.AgentUUID),
+		Name:             doc.Name,
+		Input:            doc.Input,
+		ExpectedFacts:    doc.ExpectedFacts,
+		ExpectedPatterns: doc.ExpectedPatterns,
+		Rubric:           doc.Rubric,
+		CreatedAt:        doc.CreatedAt,
+		UpdatedAt:        doc.UpdatedAt,
+	}
+}
+
+func evalRunToDoc(run *eval.Run) *evalRunDoc {
+	doc := &evalRunDoc{
+		ID:           run.ID.String(),
+		AgentUUID:    run.AgentUUID.String(),
+		AgentVersion: run.AgentVersion,
+		Status:       string(run.Status),
+		Passed:       run.Passed,
+		Failed:       run.Failed,
+		Results:      make([]evalCaseResultDoc, 0, len(run.Results)),
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+		Error:        run.Error,
+	}
+	for _, result := range run.Results {
+		doc.Results = append(doc.Results, evalCaseResultDoc{
+			CaseID:      result.CaseID.String(),
+			CaseName:    result.CaseName,
+			Input:       result.Input,
+			Output:      result.Output,
+			Passed:      result.Passed,
+			Failures:    result.Failures,
+			JudgeReason: result.JudgeReason,
+			Error:       result.Error,
+			DurationMS:  result.DurationMS,
+		})
+	}
+	return doc
+}
+
+func docToEvalRun(doc *evalRunDoc) *eval.Run {
+	run := &eval.Run{
+		ID:           types.UUID(doc.ID),
+		AgentUUID:    types.UUID(doc.AgentUUID),
+		AgentVersion: doc.AgentVersion,
+		Status:       eval.RunStatus(doc.Status),
+		Passed:       doc.Passed,
+		Failed:       doc.Failed,
+		Results:      make([]*eval.CaseResult, 0, len(doc.Results)),
+		StartedAt:    doc.StartedAt,
+		FinishedAt:   doc.FinishedAt,
+		Error:        doc.Error,
+	}
+	for _, result := range doc.Results {
+		run.Results = append(run.Results, &eval.CaseResult{
+			CaseID:      types.UUID(result.CaseID),
+			CaseName:    result.CaseName,
+			Input:       result.Input,
+			Output:      result.Output,
+			Passed:      result.Passed,
+			Failures:    result.Failures,
+			JudgeReason: result.JudgeReason,
+			Error:       result.Error,
+			DurationMS:  result.DurationMS,
+		})
+	}
+	return run
+}
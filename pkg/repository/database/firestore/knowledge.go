@@ -0,0 +1,196 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const knowledgeDocumentCollection = "agent_knowledge_documents"
+
+// knowledgeDocumentDoc is the Firestore document structure of a knowledge document
+type knowledgeDocumentDoc struct {
+	ID             string    `firestore:"id"`
+	AgentUUID      string    `firestore:"agent_uuid"`
+	Name           string    `firestore:"name"`
+	ContentType    string    `firestore:"content_type"`
+	Size           int64     `firestore:"size"`
+	StorageKey     string    `firestore:"storage_key"`
+	ChunkCount     int       `firestore:"chunk_count"`
+	EmbeddingModel string    `firestore:"embedding_model"`
+	CreatedAt      time.Time `firestore:"created_at"`
+	UpdatedAt      time.Time `firestore:"updated_at"`
+}
+
+type knowledgeRepository struct {
+	client *firestore.Client
+}
+
+// NewKnowledgeRepository creates a new knowledge document repository
+func NewKnowledgeRepository(client *firestore.Client) interfaces.KnowledgeRepository {
+	return &knowledgeRepository{
+		client: client,
+	}
+}
+
+func (r *knowledgeRepository) PutKnowledgeDocument(ctx context.Context, doc *knowledge.Document) error {
+	if err := doc.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid knowledge document")
+	}
+
+	_, err := r.client.Collection(knowledgeDocumentCollection).Doc(doc.ID.String()).Set(ctx, knowledgeDocumentToDoc(doc))
+	if err != nil {
+		return goerr.Wrap(err, "failed to put knowledge document", goerr.V("id", doc.ID))
+	}
+
+	return nil
+}
+
+func (r *knowledgeRepository) GetKnowledgeDocument(ctx context.Context, id types.UUID) (*knowledge.Document, error) {
+	snapshot, err := r.client.Collection(knowledgeDocumentCollection).Doc(id.String()).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(knowledge.ErrDocumentNotFound, "failed to get knowledge document", goerr.V("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get knowledge document", goerr.V("id", id))
+	}
+
+	var doc knowledgeDocumentDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal knowledge document", goerr.V("id", id))
+	}
+
+	return docToKnowledgeDocument(&doc), nil
+}
+
+func (r *knowledgeRepository) GetKnowledgeDocumentByName(ctx context.Context, agentUUID types.UUID, name string) (*knowledge.Document, error) {
+	iter := r.client.Collection(knowledgeDocumentCollection).
+		Where("agent_uuid", "==", agentUUID.String()).
+		Where("name", "==", name).
+		Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+
+	snapshot, err := iter.Next()
+	if err == iterator.Done {
+		return nil, goerr.Wrap(knowledge.ErrDocumentNotFound, "failed to get knowledge document by name",
+			goerr.V("agent_uuid", agentUUID),
+			goerr.V("name", name))
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get knowledge document by name",
+			goerr.V("agent_uuid", agentUUID),
+			goerr.V("name", name))
+	}
+
+	var doc knowledgeDocumentDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal knowledge document", goerr.V("id", snapshot.Ref.ID))
+	}
+
+	return docToKnowledgeDocument(&doc), nil
+}
+
+func (r *knowledgeRepository) ListKnowledgeDocuments(ctx context.Context, agentUUID types.UUID) ([]*knowledge.Document, error) {
+	iter := r.client.Collection(knowledgeDocumentCollection).
+		Where("agent_uuid", "==", agentUUID.String()).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var docs []*knowledge.Document
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate knowledge documents", goerr.V("agent_uuid", agentUUID))
+		}
+
+		var doc knowledgeDocumentDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal knowledge document", goerr.V("id", snapshot.Ref.ID))
+		}
+		docs = append(docs, docToKnowledgeDocument(&doc))
+	}
+
+	// Sorted here to avoid a composite index
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Name < docs[j].Name
+	})
+	return docs, nil
+}
+
+func (r *knowledgeRepository) ListAllKnowledgeDocuments(ctx context.Context) ([]*knowledge.Document, error) {
+	iter := r.client.Collection(knowledgeDocumentCollection).OrderBy("created_at", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	var docs []*knowledge.Document
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate knowledge documents")
+		}
+
+		var doc knowledgeDocumentDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal knowledge document", goerr.V("id", snapshot.Ref.ID))
+		}
+		docs = append(docs, docToKnowledgeDocument(&doc))
+	}
+	return docs, nil
+}
+
+func (r *knowledgeRepository) DeleteKnowledgeDocument(ctx context.Context, id types.UUID) error {
+	_, err := r.client.Collection(knowledgeDocumentCollection).Doc(id.String()).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(knowledge.ErrDocumentNotFound, "failed to delete knowledge document", goerr.V("id", id))
+		}
+		return goerr.Wrap(err, "failed to delete knowledge document", goerr.V("id", id))
+	}
+
+	return nil
+}
+
+func knowledgeDocumentToDoc(doc *knowledge.Document) *knowledgeDocumentDoc {
+	return &knowledgeDocumentDoc{
+		ID:             doc.ID.String(),
+		AgentUUID:      doc.AgentUUID.String(),
+		Name:           doc.Name,
+		ContentType:    doc.ContentType,
+		Size:           doc.Size,
+		StorageKey:     doc.StorageKey,
+		ChunkCount:     doc.ChunkCount,
+		EmbeddingModel: doc.EmbeddingModel,
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+	}
+}
+
+func docToKnowledgeDocument(doc *knowledgeDocumentDoc) *knowledge.Document {
+	return &knowledge.Document{
+		ID:             types.UUID(doc.ID),
+		AgentUUID:      types.UUID(doc.AgentUUID),
+		Name:           doc.Name,
+		ContentType:    doc.ContentType,
+		Size:           doc.Size,
+		StorageKey:     doc.StorageKey,
+		ChunkCount:     doc.ChunkCount,
+		EmbeddingModel: doc.EmbeddingModel,
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+	}
+}
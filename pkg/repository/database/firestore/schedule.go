@@ -0,0 +1,303 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	scheduleCollection = "agent_schedules"
+	// Runs are stored as a subcollection of the schedule
+	scheduleRunCollection = "runs"
+)
+
+// scheduleDoc is the Firestore document structure of a schedule
+type scheduleDoc struct {
+	ID           string     `firestore:"id"`
+	AgentUUID    string     `firestore:"agent_uuid"`
+	AgentVersion string     `firestore:"agent_version"`
+	Cron         string     `firestore:"cron"`
+	TimeZone     string     `firestore:"time_zone"`
+	ChannelID    string     `firestore:"channel_id"`
+	Prompt       string     `firestore:"prompt"`
+	Enabled      bool       `firestore:"enabled"`
+	NextRunAt    time.Time  `firestore:"next_run_at"`
+	LastRunAt    *time.Time `firestore:"last_run_at"`
+	LastError    string     `firestore:"last_error"`
+	CreatedAt    time.Time  `firestore:"created_at"`
+	UpdatedAt    time.Time  `firestore:"updated_at"`
+}
+
+// scheduleRunDoc is the Firestore document structure of a run of a schedule
+type scheduleRunDoc struct {
+	ID           string     `firestore:"id"`
+	ScheduleID   string     `firestore:"schedule_id"`
+	ScheduledAt  time.Time  `firestore:"scheduled_at"`
+	StartedAt    time.Time  `firestore:"started_at"`
+	FinishedAt   *time.Time `firestore:"finished_at"`
+	Status       string     `firestore:"status"`
+	AgentVersion string     `firestore:"agent_version"`
+	ThreadTS     string     `firestore:"thread_ts"`
+	Error        string     `firestore:"error"`
+}
+
+type scheduleRepository struct {
+	client *firestore.Client
+}
+
+// NewScheduleRepository creates a new schedule repository
+func NewScheduleRepository(client *firestore.Client) interfaces.ScheduleRepository {
+	return &scheduleRepository{
+		client: client,
+	}
+}
+
+func (r *scheduleRepository) runs(scheduleID types.UUID) *firestore.CollectionRef {
+	return r.client.Collection(scheduleCollection).Doc(scheduleID.String()).Collection(scheduleRunCollection)
+}
+
+func (r *scheduleRepository) PutSchedule(ctx context.Context, s *schedule.Schedule) error {
+	if err := s.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid schedule")
+	}
+
+	_, err := r.client.Collection(scheduleCollection).Doc(s.ID.String()).Set(ctx, scheduleToDoc(s))
+	if err != nil {
+		return goerr.Wrap(err, "failed to put schedule", goerr.V("id", s.ID))
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) GetSchedule(ctx context.Context, id types.UUID) (*schedule.Schedule, error) {
+	snapshot, err := r.client.Collection(scheduleCollection).Doc(id.String()).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(schedule.ErrScheduleNotFound, "failed to get schedule", goerr.V("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get schedule", goerr.V("id", id))
+	}
+
+	var doc scheduleDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal schedule", goerr.V("id", id))
+	}
+
+	return docToSchedule(&doc), nil
+}
+
+func (r *scheduleRepository) ListSchedules(ctx context.Context) ([]*schedule.Schedule, error) {
+	iter := r.client.Collection(scheduleCollection).Documents(ctx)
+	defer iter.Stop()
+
+	var schedules []*schedule.Schedule
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate schedules")
+		}
+
+		var doc scheduleDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal schedule", goerr.V("id", snapshot.Ref.ID))
+		}
+		schedules = append(schedules, docToSchedule(&doc))
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, nil
+}
+
+func (r *scheduleRepository) DeleteSchedule(ctx context.Context, id types.UUID) error {
+	_, err := r.client.Collection(scheduleCollection).Doc(id.String()).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(schedule.ErrScheduleNotFound, "failed to delete schedule", goerr.V("id", id))
+		}
+		return goerr.Wrap(err, "failed to delete schedule", goerr.V("id", id))
+	}
+
+	// Subcollection is not deleted with the parent document
+	iter := r.runs(id).Documents(ctx)
+	defer iter.Stop()
+
+	bulkWriter := r.client.BulkWriter(ctx)
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return goerr.Wrap(err, "failed to iterate runs of deleted schedule", goerr.V("id", id))
+		}
+		if _, err := bulkWriter.Delete(snapshot.Ref); err != nil {
+			return goerr.Wrap(err, "failed to add delete to bulk writer", goerr.V("id", id))
+		}
+	}
+	bulkWriter.End()
+
+	return nil
+}
+
+func (r *scheduleRepository) UpdateScheduleState(ctx context.Context, id types.UUID, lastRunAt time.Time, lastError string, nextRunAt time.Time) error {
+	_, err := r.client.Collection(scheduleCollection).Doc(id.String()).Update(ctx, []firestore.Update{
+		{Path: "last_run_at", Value: lastRunAt},
+		{Path: "last_error", Value: lastError},
+		{Path: "next_run_at", Value: nextRunAt},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(schedule.ErrScheduleNotFound, "failed to update schedule state", goerr.V("id", id))
+		}
+		return goerr.Wrap(err, "failed to update schedule state", goerr.V("id", id))
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) CreateScheduleRun(ctx context.Context, run *schedule.Run) error {
+	// Create fails if the document exists, so only one instance takes the tick
+	_, err := r.runs(run.ScheduleID).Doc(run.ID).Create(ctx, scheduleRunToDoc(run))
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return goerr.Wrap(schedule.ErrRunAlreadyExists, "failed to create schedule run", goerr.V("id", run.ID))
+		}
+		return goerr.Wrap(err, "failed to create schedule run", goerr.V("id", run.ID))
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) GetScheduleRun(ctx context.Context, scheduleID types.UUID, id string) (*schedule.Run, error) {
+	snapshot, err := r.runs(scheduleID).Doc(id).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(schedule.ErrRunNotFound, "failed to get schedule run", goerr.V("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get schedule run", goerr.V("id", id))
+	}
+
+	var doc scheduleRunDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal schedule run", goerr.V("id", id))
+	}
+
+	return docToScheduleRun(&doc), nil
+}
+
+func (r *scheduleRepository) PutScheduleRun(ctx context.Context, run *schedule.Run) error {
+	_, err := r.runs(run.ScheduleID).Doc(run.ID).Set(ctx, scheduleRunToDoc(run))
+	if err != nil {
+		return goerr.Wrap(err, "failed to put schedule run", goerr.V("id", run.ID))
+	}
+
+	return nil
+}
+
+func (r *scheduleRepository) ListScheduleRuns(ctx context.Context, scheduleID types.UUID, limit int) ([]*schedule.Run, error) {
+	query := r.runs(scheduleID).OrderBy("scheduled_at", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var runs []*schedule.Run
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate schedule runs", goerr.V("schedule_id", scheduleID))
+		}
+
+		var doc scheduleRunDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal schedule run", goerr.V("id", snapshot.Ref.ID))
+		}
+		runs = append(runs, docToScheduleRun(&doc))
+	}
+
+	return runs, nil
+}
+
+func scheduleToDoc(s *schedule.Schedule) *scheduleDoc {
+	return &scheduleDoc{
+		ID:           s.ID.String(),
+		AgentUUID:    s.AgentUUID.String(),
+		AgentVersion: s.AgentVersion,
+		Cron:         s.Cron,
+		TimeZone:     s.TimeZone,
+		ChannelID:    s.ChannelID,
+		Prompt:       s.Prompt,
+		Enabled:      s.Enabled,
+		NextRunAt:    s.NextRunAt,
+		LastRunAt:    s.LastRunAt,
+		LastError:    s.LastError,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+}
+
+func docToSchedule(doc *scheduleDoc) *schedule.Schedule {
+	return &schedule.Schedule{
+		ID:           types.UUID(doc.ID),
+		AgentUUID:    types.UUID(doc.AgentUUID),
+		AgentVersion: doc.AgentVersion,
+		Cron:         doc.Cron,
+		TimeZone:     doc.TimeZone,
+		ChannelID:    doc.ChannelID,
+		Prompt:       doc.Prompt,
+		Enabled:      doc.Enabled,
+		NextRunAt:    doc.NextRunAt,
+		LastRunAt:    doc.LastRunAt,
+		LastError:    doc.LastError,
+		CreatedAt:    doc.CreatedAt,
+		UpdatedAt:    doc.UpdatedAt,
+	}
+}
+
+func scheduleRunToDoc(run *schedule.Run) *scheduleRunDoc {
+	return &scheduleRunDoc{
+		ID:           run.ID,
+		ScheduleID:   run.ScheduleID.String(),
+		ScheduledAt:  run.ScheduledAt,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+		Status:       string(run.Status),
+		AgentVersion: run.AgentVersion,
+		ThreadTS:     run.ThreadTS,
+		Error:        run.Error,
+	}
+}
+
+func docToScheduleRun(doc *scheduleRunDoc) *schedule.Run {
+	return &schedule.Run{
+		ID:           doc.ID,
+		ScheduleID:   types.UUID(doc.ScheduleID),
+		ScheduledAt:  doc.ScheduledAt,
+		StartedAt:    doc.StartedAt,
+		FinishedAt:   doc.FinishedAt,
+		Status:       schedule.RunStatus(doc.Status),
+		AgentVersion: doc.AgentVersion,
+		ThreadTS:     doc.ThreadTS,
+		Error:        doc.Error,
+	}
+}
@@ -0,0 +1,173 @@
+package firestore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const webhookCollection = "agent_webhooks"
+
+// webhookDoc is the Firestore document structure of a webhook
+type webhookDoc struct {
+	ID             string    `firestore:"id"`
+	AgentUUID      string    `firestore:"agent_uuid"`
+	AgentVersion   string    `firestore:"agent_version"`
+	Name           string    `firestore:"name"`
+	PromptTemplate string    `firestore:"prompt_template"`
+	ChannelID      string    `firestore:"channel_id"`
+	ThreadTS       string    `firestore:"thread_ts"`
+	TokenHash      string    `firestore:"token_hash"`
+	SigningSecret  string    `firestore:"signing_secret"`
+	Enabled        bool      `firestore:"enabled"`
+	CreatedAt      time.Time `firestore:"created_at"`
+	UpdatedAt      time.Time `firestore:"updated_at"`
+}
+
+type webhookRepository struct {
+	client *firestore.Client
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(client *firestore.Client) interfaces.WebhookRepository {
+	return &webhookRepository{
+		client: client,
+	}
+}
+
+func (r *webhookRepository) PutWebhook(ctx context.Context, w *webhook.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid webhook")
+	}
+
+	_, err := r.client.Collection(webhookCollection).Doc(w.ID.String()).Set(ctx, webhookToDoc(w))
+	if err != nil {
+		return goerr.Wrap(err, "failed to put webhook", goerr.V("id", w.ID))
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, id types.UUID) (*webhook.Webhook, error) {
+	snapshot, err := r.client.Collection(webhookCollection).Doc(id.String()).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, goerr.Wrap(webhook.ErrWebhookNotFound, "failed to get webhook", goerr.V("id", id))
+		}
+		return nil, goerr.Wrap(err, "failed to get webhook", goerr.V("id", id))
+	}
+
+	var doc webhookDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal webhook", goerr.V("id", id))
+	}
+
+	return docToWebhook(&doc), nil
+}
+
+func (r *webhookRepository) GetWebhookByTokenHash(ctx context.Context, tokenHash string) (*webhook.Webhook, error) {
+	iter := r.client.Collection(webhookCollection).
+		Where("token_hash", "==", tokenHash).
+		Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+
+	snapshot, err := iter.Next()
+	if err == iterator.Done {
+		return nil, goerr.Wrap(webhook.ErrWebhookNotFound, "failed to get webhook by token")
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get webhook by token")
+	}
+
+	var doc webhookDoc
+	if err := snapshot.DataTo(&doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal webhook", goerr.V("id", snapshot.Ref.ID))
+	}
+
+	return docToWebhook(&doc), nil
+}
+
+func (r *webhookRepository) ListWebhooksByAgent(ctx context.Context, agentUUID types.UUID) ([]*webhook.Webhook, error) {
+	iter := r.client.Collection(webhookCollection).
+		Where("agent_uuid", "==", agentUUID.String()).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var webhooks []*webhook.Webhook
+	for {
+		snapshot, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to iterate webhooks", goerr.V("agent_uuid", agentUUID))
+		}
+
+		var doc webhookDoc
+		if err := snapshot.DataTo(&doc); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal webhook", goerr.V("id", snapshot.Ref.ID))
+		}
+		webhooks = append(webhooks, docToWebhook(&doc))
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, id types.UUID) error {
+	_, err := r.client.Collection(webhookCollection).Doc(id.String()).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return goerr.Wrap(webhook.ErrWebhookNotFound, "failed to delete webhook", goerr.V("id", id))
+		}
+		return goerr.Wrap(err, "failed to delete webhook", goerr.V("id", id))
+	}
+
+	return nil
+}
+
+func webhookToDoc(w *webhook.Webhook) *webhookDoc {
+	return &webhookDoc{
+		ID:             w.ID.String(),
+		AgentUUID:      w.AgentUUID.String(),
+		AgentVersion:   w.AgentVersion,
+		Name:           w.Name,
+		PromptTemplate: w.PromptTemplate,
+		ChannelID:      w.ChannelID,
+		ThreadTS:       w.ThreadTS,
+		TokenHash:      w.TokenHash,
+		SigningSecret:  w.SigningSecret,
+		Enabled:        w.Enabled,
+		CreatedAt:      w.CreatedAt,
+		UpdatedAt:      w.UpdatedAt,
+	}
+}
+
+func docToWebhook(doc *webhookDoc) *webhook.Webhook {
+	return &webhook.Webhook{
+		ID:             types.UUID(doc.ID),
+		AgentUUID:      types.UUID(doc.AgentUUID),
+		AgentVersion:   doc.AgentVersion,
+		Name:           doc.Name,
+		PromptTemplate: doc.PromptTemplate,
+		ChannelID:      doc.ChannelID,
+		ThreadTS:       doc.ThreadTS,
+		TokenHash:      doc.TokenHash,
+		SigningSecret:  doc.SigningSecret,
+		Enabled:        doc.Enabled,
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+	}
+}
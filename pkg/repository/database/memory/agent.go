@@ -38,6 +38,10 @@ func deepCopyAgent(src *agent.Agent) *agent.Agent {
 		imageIDCopy := *src.ImageID
 		agentCopy.ImageID = &imageIDCopy
 	}
+	if src.Rollout != nil {
+		rolloutCopy := *src.Rollout
+		agentCopy.Rollout = &rolloutCopy
+	}
 	return &agentCopy
 }
 
@@ -272,14 +276,9 @@ func (c *AgentMemoryClient) GetAgentVersion(ctx context.Context, agentUUID types
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	agentVersions, exists := c.versions[agentUUID]
-	if !exists {
-		return nil, goerr.New("agent not found", goerr.V("agent_uuid", agentUUID.String()))
-	}
-
-	agentVersion, exists := agentVersions[version]
+	agentVersion, exists := c.versions[agentUUID][version]
 	if !exists {
-		return nil, goerr.New("agent version not found",
+		return nil, goerr.Wrap(agent.ErrAgentVersionNotFound, "agent version not found",
 			goerr.V("agent_uuid", agentUUID.String()),
 			goerr.V("version", version))
 	}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+type agentMetricsMemoryRepository struct {
+	mu      sync.RWMutex
+	metrics map[types.UUID]map[string]*agent.VersionMetrics
+}
+
+// NewAgentMetricsRepository creates a new memory-based agent metrics repository
+func NewAgentMetricsRepository() interfaces.AgentMetricsRepository {
+	return &agentMetricsMemoryRepository{
+		metrics: make(map[types.UUID]map[string]*agent.VersionMetrics),
+	}
+}
+
+func (r *agentMetricsMemoryRepository) AddAgentVersionMetrics(ctx context.Context, delta *agent.VersionMetrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, exists := r.metrics[delta.AgentUUID]
+	if !exists {
+		versions = make(map[string]*agent.VersionMetrics)
+		r.metrics[delta.AgentUUID] = versions
+	}
+	m, exists := versions[delta.Version]
+	if !exists {
+		m = &agent.VersionMetrics{AgentUUID: delta.AgentUUID, Version: delta.Version}
+		versions[delta.Version] = m
+	}
+
+	m.Add(delta)
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *agentMetricsMemoryRepository) ListAgentVersionMetrics(ctx context.Context, agentUUID types.UUID) ([]*agent.VersionMetrics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*agent.VersionMetrics, 0, len(r.metrics[agentUUID]))
+	for _, m := range r.metrics[agentUUID] {
+		mCopy := *m
+		result = append(result, &mCopy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
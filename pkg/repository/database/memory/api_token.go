@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+type apiTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[types.UUID]*auth.APIToken
+}
+
+// NewAPITokenRepository creates a new in-memory API token repository
+func NewAPITokenRepository() interfaces.APITokenRepository {
+	return &apiTokenRepository{
+		tokens: make(map[types.UUID]*auth.APIToken),
+	}
+}
+
+func (r *apiTokenRepository) PutAPIToken(ctx context.Context, token *auth.APIToken) error {
+	if err := token.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid API token")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokenCopy := *token
+	r.tokens[token.ID] = &tokenCopy
+	return nil
+}
+
+func (r *apiTokenRepository) GetAPITokenByHash(ctx context.Context, tokenHash string) (*auth.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			tokenCopy := *token
+			return &tokenCopy, nil
+		}
+	}
+
+	return nil, goerr.Wrap(auth.ErrAPITokenNotFound, "failed to get API token by hash")
+}
+
+func (r *apiTokenRepository) ListAPITokensByUser(ctx context.Context, userID types.UserID) ([]*auth.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*auth.APIToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokenCopy := *token
+			result = append(result, &tokenCopy)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *apiTokenRepository) DeleteAPIToken(ctx context.Context, id types.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[id]; !exists {
+		return goerr.Wrap(auth.ErrAPITokenNotFound, "failed to delete API token", goerr.V("id", id))
+	}
+
+	delete(r.tokens, id)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
@@ -105,6 +106,42 @@ func (c *Client) GetThreadByTS(ctx context.Context, channelID, threadTS string)
 		goerr.V("thread_ts", threadTS))
 }
 
+// PutThread saves the thread as is
+func (c *Client) PutThread(ctx context.Context, thread *slack.Thread) error {
+	if thread == nil {
+		return ErrNilPointer
+	}
+	if err := thread.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid thread", goerr.V("thread_id", thread.ID))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Deep copy to avoid external modifications
+	threadCopy := *thread
+	c.threads[thread.ID] = &threadCopy
+
+	return nil
+}
+
+// UpdateThreadAgent changes the agent bound to the thread
+func (c *Client) UpdateThreadAgent(ctx context.Context, threadID types.ThreadID, agentUUID *types.UUID, agentVersion string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, exists := c.threads[threadID]
+	if !exists {
+		return goerr.Wrap(slack.ErrThreadNotFound, "thread not found", goerr.V("thread_id", threadID))
+	}
+
+	t.AgentUUID = agentUUID
+	t.AgentVersion = agentVersion
+	t.UpdatedAt = time.Now()
+
+	return nil
+}
+
 // ListThreads retrieves a paginated list of threads sorted by creation time (newest first)
 func (c *Client) ListThreads(ctx context.Context, offset, limit int) ([]*slack.Thread, int, error) {
 	c.mu.RLock()
@@ -242,6 +279,32 @@ func (c *Client) GetLatestHistory(ctx context.Context, threadID types.ThreadID)
 	return &historyCopy, nil
 }
 
+// ListHistories retrieves all history records of a thread sorted by creation time (oldest first)
+func (c *Client) ListHistories(ctx context.Context, threadID types.ThreadID) ([]*slack.History, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// Check if thread exists
+	if _, exists := c.threads[threadID]; !exists {
+		return nil, goerr.Wrap(slack.ErrThreadNotFound, "thread not found", goerr.V("thread_id", threadID))
+	}
+
+	histories := make([]*slack.History, 0)
+	for _, h := range c.histories {
+		if h.ThreadID == threadID {
+			// Return a copy to avoid external modifications
+			historyCopy := *h
+			histories = append(histories, &historyCopy)
+		}
+	}
+
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].CreatedAt.Before(histories[j].CreatedAt)
+	})
+
+	return histories, nil
+}
+
 // GetHistoryByID retrieves a specific history record by ID
 func (c *Client) GetHistoryByID(ctx context.Context, id types.HistoryID) (*slack.History, error) {
 	c.mu.RLock()
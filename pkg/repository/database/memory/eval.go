@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+type evalMemoryRepository struct {
+	mu    sync.RWMutex
+	cases map[types.UUID]*eval.Case
+	runs  map[types.UUID]*eval.Run
+}
+
+// NewEvalRepository creates a new memory-based eval repository
+func NewEvalRepository() interfaces.EvalRepository {
+	return &evalMemoryRepository{
+		cases: make(map[types.UUID]*eval.Case),
+		runs:  make(map[types.UUID]*eval.Run),
+	}
+}
+
+func (r *evalMemoryRepository) PutEvalCase(ctx context.Context, c *eval.Case) error {
+	if err := c.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid eval case")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cases[c.ID] = copyEvalCase(c)
+	return nil
+}
+
+func (r *evalMemoryRepository) GetEvalCase(ctx context.Context, id types.UUID) (*eval.Case, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, exists := r.cases[id]
+	if !exists {
+		return nil, goerr.Wrap(eval.ErrCaseNotFound, "failed to get eval case", goerr.V("id", id))
+	}
+
+	return copyEvalCase(c), nil
+}
+
+func (r *evalMemoryRepository) ListEvalCases(ctx context.Context, agentUUID types.UUID) ([]*eval.Case, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*eval.Case
+	for _, c := range r.cases {
+		if c.AgentUUID == agentUUID {
+			result = append(result, copyEvalCase(c))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *evalMemoryRepository) DeleteEvalCase(ctx context.Context, id types.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.cases[id]; !exists {
+		return goerr.Wrap(eval.ErrCaseNotFound, "failed to delete eval case", goerr.V("id", id))
+	}
+
+	delete(r.cases, id)
+	return nil
+}
+
+func (r *evalMemoryRepository) PutEvalRun(ctx context.Context, run *eval.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs[run.ID] = copyEvalRun(run)
+	return nil
+}
+
+func (r *evalMemoryRepository) GetEvalRun(ctx context.Context, id types.UUID) (*eval.Run, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	run, exists := r.runs[id]
+	if !exists {
+		return nil, goerr.Wrap(eval.ErrRunNotFound, "failed to get eval run", goerr.V("id", id))
+	}
+
+	return copyEvalRun(run), nil
+}
+
+func (r *evalMemoryRepository) ListEvalRuns(ctx context.Context, agentUUID types.UUID, limit int) ([]*eval.Run, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*eval.Run
+	for _, run := range r.runs {
+		if run.AgentUUID == agentUUID {
+			result = append(result, copyEvalRun(run))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func copyEvalCase(c *eval.Case) *eval.Case {
+	cCopy := *c
+	cCopy.ExpectedFacts = append([]string(nil), c.ExpectedFacts...)
+	cCopy.ExpectedPatterns = append([]string(nil), c.ExpectedPatterns...)
+	return &cCopy
+}
+
+func copyEvalRun(run *eval.Run) *eval.Run {
+	runCopy := *run
+	if run.FinishedAt != nil {
+		finishedAt := *run.FinishedAt
+		runCopy.FinishedAt = &finishedAt
+	}
+	runCopy.Results = make([]*eval.CaseResult, 0, len(run.Results))
+	for _, result := range run.Results {
+		resultCopy := *result
+		resultCopy.Failures = append([]string(nil), result.Failures...)
+		runCopy.Results = append(runCopy.Results, &resultCopy)
+	}
+	return &runCopy
+}
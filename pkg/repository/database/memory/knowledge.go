@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+type knowledgeMemoryRepository struct {
+	mu        sync.RWMutex
+	documents map[types.UUID]*knowledge.Document
+}
+
+// NewKnowledgeRepository creates a new memory-based knowledge document repository
+func NewKnowledgeRepository() interfaces.KnowledgeRepository {
+	return &knowledgeMemoryRepository{
+		documents: make(map[types.UUID]*knowledge.Document),
+	}
+}
+
+func (r *knowledgeMemoryRepository) PutKnowledgeDocument(ctx context.Context, doc *knowledge.Document) error {
+	if err := doc.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid knowledge document")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docCopy := *doc
+	r.documents[doc.ID] = &docCopy
+	return nil
+}
+
+func (r *knowledgeMemoryRepository) GetKnowledgeDocument(ctx context.Context, id types.UUID) (*knowledge.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	doc, exists := r.documents[id]
+	if !exists {
+		return nil, goerr.Wrap(knowledge.ErrDocumentNotFound, "failed to get knowledge document", goerr.V("id", id))
+	}
+
+	docCopy := *doc
+	return &docCopy, nil
+}
+
+func (r *knowledgeMemoryRepository) GetKnowledgeDocumentByName(ctx context.Context, agentUUID types.UUID, name string) (*knowledge.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, doc := range r.documents {
+		if doc.AgentUUID == agentUUID && doc.Name == name {
+			docCopy := *doc
+			return &docCopy, nil
+		}
+	}
+
+	return nil, goerr.Wrap(knowledge.ErrDocumentNotFound, "failed to get knowledge document by name",
+		goerr.V("agent_uuid", agentUUID),
+		goerr.V("name", name))
+}
+
+func (r *knowledgeMemoryRepository) ListKnowledgeDocuments(ctx context.Context, agentUUID types.UUID) ([]*knowledge.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*knowledge.Document
+	for _, doc := range r.documents {
+		if doc.AgentUUID == agentUUID {
+			docCopy := *doc
+			result = append(result, &docCopy)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (r *knowledgeMemoryRepository) ListAllKnowledgeDocuments(ctx context.Context) ([]*knowledge.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*knowledge.Document, 0, len(r.documents))
+	for _, doc := range r.documents {
+		docCopy := *doc
+		result = append(result, &docCopy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *knowledgeMemoryRepository) DeleteKnowledgeDocument(ctx context.Context, id types.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.documents[id]; !exists {
+		return goerr.Wrap(knowledge.ErrDocumentNotFound, "failed to delete knowledge document", goerr.V("id", id))
+	}
+
+	delete(r.documents, id)
+	return nil
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+type scheduleMemoryRepository struct {
+	mu        sync.RWMutex
+	schedules map[types.UUID]*schedule.Schedule
+	runs      map[types.UUID]map[string]*schedule.Run
+}
+
+// NewScheduleRepository creates a new memory-based schedule repository
+func NewScheduleRepository() interfaces.ScheduleRepository {
+	return &scheduleMemoryRepository{
+		schedules: make(map[types.UUID]*schedule.Schedule),
+		runs:      make(map[types.UUID]map[string]*schedule.Run),
+	}
+}
+
+func (r *scheduleMemoryRepository) PutSchedule(ctx context.Context, s *schedule.Schedule) error {
+	if err := s.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid schedule")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schedules[s.ID] = copySchedule(s)
+	return nil
+}
+
+func (r *scheduleMemoryRepository) GetSchedule(ctx context.Context, id types.UUID) (*schedule.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, exists := r.schedules[id]
+	if !exists {
+		return nil, goerr.Wrap(schedule.ErrScheduleNotFound, "failed to get schedule", goerr.V("id", id))
+	}
+
+	return copySchedule(s), nil
+}
+
+func (r *scheduleMemoryRepository) ListSchedules(ctx context.Context) ([]*schedule.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*schedule.Schedule, 0, len(r.schedules))
+	for _, s := range r.schedules {
+		result = append(result, copySchedule(s))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *scheduleMemoryRepository) DeleteSchedule(ctx context.Context, id types.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.schedules[id]; !exists {
+		return goerr.Wrap(schedule.ErrScheduleNotFound, "failed to delete schedule", goerr.V("id", id))
+	}
+
+	delete(r.schedules, id)
+	delete(r.runs, id)
+	return nil
+}
+
+func (r *scheduleMemoryRepository) UpdateScheduleState(ctx context.Context, id types.UUID, lastRunAt time.Time, lastError string, nextRunAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, exists := r.schedules[id]
+	if !exists {
+		return goerr.Wrap(schedule.ErrScheduleNotFound, "failed to update schedule state", goerr.V("id", id))
+	}
+
+	s.LastRunAt = &lastRunAt
+	s.LastError = lastError
+	s.NextRunAt = nextRunAt
+	return nil
+}
+
+func (r *scheduleMemoryRepository) CreateScheduleRun(ctx context.Context, run *schedule.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, exists := r.runs[run.ScheduleID]
+	if !exists {
+		runs = make(map[string]*schedule.Run)
+		r.runs[run.ScheduleID] = runs
+	}
+	if _, exists := runs[run.ID]; exists {
+		return goerr.Wrap(schedule.ErrRunAlreadyExists, "failed to create schedule run", goerr.V("id", run.ID))
+	}
+
+	runCopy := *run
+	runs[run.ID] = &runCopy
+	return nil
+}
+
+func (r *scheduleMemoryRepository) GetScheduleRun(ctx context.Context, scheduleID types.UUID, id string) (*schedule.Run, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	run, exists := r.runs[scheduleID][id]
+	if !exists {
+		return nil, goerr.Wrap(schedule.ErrRunNotFound, "failed to get schedule run", goerr.V("id", id))
+	}
+
+	runCopy := *run
+	return &runCopy, nil
+}
+
+func (r *scheduleMemoryRepository) PutScheduleRun(ctx context.Context, run *schedule.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs, exists := r.runs[run.ScheduleID]
+	if !exists {
+		runs = make(map[string]*schedule.Run)
+		r.runs[run.ScheduleID] = runs
+	}
+
+	runCopy := *run
+	runs[run.ID] = &runCopy
+	return nil
+}
+
+func (r *scheduleMemoryRepository) ListScheduleRuns(ctx context.Context, scheduleID types.UUID, limit int) ([]*schedule.Run, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*schedule.Run, 0, len(r.runs[scheduleID]))
+	for _, run := range r.runs[scheduleID] {
+		runCopy := *run
+		result = append(result, &runCopy)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScheduledAt.After(result[j].ScheduledAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func copySchedule(s *schedule.Schedule) *schedule.Schedule {
+	sCopy := *s
+	if s.LastRunAt != nil {
+		lastRunAt := *s.LastRunAt
+		sCopy.LastRunAt = &lastRunAt
+	}
+	return &sCopy
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+type webhookMemoryRepository struct {
+	mu       sync.RWMutex
+	webhooks map[types.UUID]*webhook.Webhook
+}
+
+// NewWebhookRepository creates a new memory-based webhook repository
+func NewWebhookRepository() interfaces.WebhookRepository {
+	return &webhookMemoryRepository{
+		webhooks: make(map[types.UUID]*webhook.Webhook),
+	}
+}
+
+func (r *webhookMemoryRepository) PutWebhook(ctx context.Context, w *webhook.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid webhook")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wCopy := *w
+	r.webhooks[w.ID] = &wCopy
+	return nil
+}
+
+func (r *webhookMemoryRepository) GetWebhook(ctx context.Context, id types.UUID) (*webhook.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, exists := r.webhooks[id]
+	if !exists {
+		return nil, goerr.Wrap(webhook.ErrWebhookNotFound, "failed to get webhook", goerr.V("id", id))
+	}
+
+	wCopy := *w
+	return &wCopy, nil
+}
+
+func (r *webhookMemoryRepository) GetWebhookByTokenHash(ctx context.Context, tokenHash string) (*webhook.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, w := range r.webhooks {
+		if w.TokenHash == tokenHash {
+			wCopy := *w
+			return &wCopy, nil
+		}
+	}
+
+	return nil, goerr.Wrap(webhook.ErrWebhookNotFound, "failed to get webhook by token")
+}
+
+func (r *webhookMemoryRepository) ListWebhooksByAgent(ctx context.Context, agentUUID types.UUID) ([]*webhook.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*webhook.Webhook
+	for _, w := range r.webhooks {
+		if w.AgentUUID == agentUUID {
+			wCopy := *w
+			result = append(result, &wCopy)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *webhookMemoryRepository) DeleteWebhook(ctx context.Context, id types.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.webhooks[id]; !exists {
+		return goerr.Wrap(webhook.ErrWebhookNotFound, "failed to delete webhook", goerr.V("id", id))
+	}
+
+	delete(r.webhooks, id)
+	return nil
+}
@@ -0,0 +1,133 @@
+package vector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+// File is a vector index persisted to a gzip compressed JSON file. Chunks are searched in
+// memory, and the whole index is written to the file on every change.
+type File struct {
+	*Memory
+	path string
+}
+
+// Ensure File implements interfaces.VectorIndex
+var _ interfaces.VectorIndex = (*File)(nil)
+
+// NewFile creates a vector index persisted to the file at path, loading chunks from the file
+// if it exists
+func NewFile(path string) (*File, error) {
+	f := &File{
+		Memory: NewMemory(),
+		path:   path,
+	}
+
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read vector index file", goerr.V("path", path))
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create gzip reader of vector index", goerr.V("path", path))
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to decompress vector index", goerr.V("path", path))
+	}
+
+	var chunks []*knowledge.Chunk
+	if err := json.Unmarshal(data, &chunks); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal vector index", goerr.V("path", path))
+	}
+	for _, chunk := range chunks {
+		f.chunks[chunk.DocumentID] = append(f.chunks[chunk.DocumentID], chunk)
+	}
+
+	return f, nil
+}
+
+// PutChunks replaces all chunks of the document with the given chunks and saves the index
+func (f *File) PutChunks(ctx context.Context, documentID types.UUID, chunks []*knowledge.Chunk) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previous := f.chunks[documentID]
+	f.put(documentID, chunks)
+	if err := f.save(); err != nil {
+		f.put(documentID, previous)
+		return err
+	}
+	return nil
+}
+
+// DeleteChunks deletes all chunks of the document and saves the index
+func (f *File) DeleteChunks(ctx context.Context, documentID types.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previous, exists := f.chunks[documentID]
+	if !exists {
+		return nil
+	}
+	delete(f.chunks, documentID)
+	if err := f.save(); err != nil {
+		f.chunks[documentID] = previous
+		return err
+	}
+	return nil
+}
+
+// save writes all chunks to a temporary file and renames it to the index file, so that the
+// index file is not broken if the process stops while writing
+func (f *File) save() error {
+	var chunks []*knowledge.Chunk
+	for _, docChunks := range f.chunks {
+		chunks = append(chunks, docChunks...)
+	}
+
+	data, err := json.Marshal(chunks)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal vector index")
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return goerr.Wrap(err, "failed to compress vector index")
+	}
+	if err := writer.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close gzip writer of vector index")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0750); err != nil {
+		return goerr.Wrap(err, "failed to create directory of vector index", goerr.V("path", f.path))
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return goerr.Wrap(err, "failed to write vector index", goerr.V("path", tmp))
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return goerr.Wrap(err, "failed to replace vector index file", goerr.V("path", f.path))
+	}
+	return nil
+}
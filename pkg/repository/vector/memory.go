@@ -0,0 +1,112 @@
+package vector
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+// Memory is an in-memory vector index that searches chunks by brute force cosine similarity.
+// It is enough for knowledge bases of thousands of chunks per agent.
+type Memory struct {
+	mu     sync.RWMutex
+	chunks map[types.UUID][]*knowledge.Chunk // document ID -> chunks
+}
+
+// Ensure Memory implements interfaces.VectorIndex
+var _ interfaces.VectorIndex = (*Memory)(nil)
+
+// NewMemory creates a new in-memory vector index
+func NewMemory() *Memory {
+	return &Memory{
+		chunks: make(map[types.UUID][]*knowledge.Chunk),
+	}
+}
+
+// PutChunks replaces all chunks of the document with the given chunks
+func (m *Memory) PutChunks(ctx context.Context, documentID types.UUID, chunks []*knowledge.Chunk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(documentID, chunks)
+	return nil
+}
+
+// DeleteChunks deletes all chunks of the document
+func (m *Memory) DeleteChunks(ctx context.Context, documentID types.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.chunks, documentID)
+	return nil
+}
+
+// CountChunks returns the number of chunks of the document in the index
+func (m *Memory) CountChunks(ctx context.Context, documentID types.UUID) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.chunks[documentID]), nil
+}
+
+// Search returns chunks of the agent most similar to the vector, in descending order of score
+func (m *Memory) Search(ctx context.Context, agentUUID types.UUID, vector []float64, limit int) ([]*knowledge.SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []*knowledge.SearchResult
+	for _, chunks := range m.chunks {
+		for _, chunk := range chunks {
+			if chunk.AgentUUID != agentUUID || len(chunk.Embedding) != len(vector) {
+				continue
+			}
+			chunkCopy := *chunk
+			results = append(results, &knowledge.SearchResult{
+				Chunk: &chunkCopy,
+				Score: cosineSimilarity(vector, chunk.Embedding),
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (m *Memory) put(documentID types.UUID, chunks []*knowledge.Chunk) {
+	if len(chunks) == 0 {
+		delete(m.chunks, documentID)
+		return
+	}
+
+	copied := make([]*knowledge.Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		chunkCopy := *chunk
+		chunkCopy.DocumentID = documentID
+		copied = append(copied, &chunkCopy)
+	}
+	m.chunks[documentID] = copied
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors of the same dimension
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,101 @@
+package vector_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/vector"
+)
+
+func testVectorIndex(t *testing.T, index interfaces.VectorIndex) {
+	ctx := context.Background()
+	agentA := types.NewUUID(ctx)
+	agentB := types.NewUUID(ctx)
+	docA := types.NewUUID(ctx)
+	docB := types.NewUUID(ctx)
+
+	gt.NoError(t, index.PutChunks(ctx, docA, []*knowledge.Chunk{
+		{AgentUUID: agentA, DocumentName: "runbook.md", Index: 0, Text: "restart", Embedding: []float64{1, 0, 0}},
+		{AgentUUID: agentA, DocumentName: "runbook.md", Index: 1, Text: "rollback", Embedding: []float64{0, 1, 0}},
+	}))
+	gt.NoError(t, index.PutChunks(ctx, docB, []*knowledge.Chunk{
+		{AgentUUID: agentB, DocumentName: "other.md", Index: 0, Text: "other agent", Embedding: []float64{1, 0, 0}},
+	}))
+
+	t.Run("search returns chunks of the agent by similarity", func(t *testing.T) {
+		results, err := index.Search(ctx, agentA, []float64{0.9, 0.1, 0}, 10)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(2)
+		gt.Equal(t, results[0].Chunk.Text, "restart")
+		gt.Equal(t, results[0].Chunk.DocumentID, docA)
+		gt.Equal(t, results[1].Chunk.Text, "rollback")
+		gt.True(t, results[0].Score > results[1].Score)
+	})
+
+	t.Run("search is limited", func(t *testing.T) {
+		results, err := index.Search(ctx, agentA, []float64{0, 1, 0}, 1)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(1)
+		gt.Equal(t, results[0].Chunk.Text, "rollback")
+	})
+
+	t.Run("put replaces chunks of the document", func(t *testing.T) {
+		gt.NoError(t, index.PutChunks(ctx, docA, []*knowledge.Chunk{
+			{AgentUUID: agentA, DocumentName: "runbook.md", Index: 0, Text: "new", Embedding: []float64{0, 0, 1}},
+		}))
+		results, err := index.Search(ctx, agentA, []float64{1, 0, 0}, 10)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(1)
+		gt.Equal(t, results[0].Chunk.Text, "new")
+	})
+
+	t.Run("count chunks of the document", func(t *testing.T) {
+		count, err := index.CountChunks(ctx, docA)
+		gt.NoError(t, err)
+		gt.Equal(t, count, 1)
+
+		count, err = index.CountChunks(ctx, types.NewUUID(ctx))
+		gt.NoError(t, err)
+		gt.Equal(t, count, 0)
+	})
+
+	t.Run("delete removes chunks of the document", func(t *testing.T) {
+		gt.NoError(t, index.DeleteChunks(ctx, docB))
+		results, err := index.Search(ctx, agentB, []float64{1, 0, 0}, 10)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(0)
+	})
+}
+
+func TestMemory(t *testing.T) {
+	testVectorIndex(t, vector.NewMemory())
+}
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "knowledge", "index.json.gz")
+
+	index, err := vector.NewFile(path)
+	gt.NoError(t, err)
+	testVectorIndex(t, index)
+
+	// Chunks are loaded from the file
+	agentUUID := types.NewUUID(ctx)
+	docID := types.NewUUID(ctx)
+	gt.NoError(t, index.PutChunks(ctx, docID, []*knowledge.Chunk{
+		{AgentUUID: agentUUID, DocumentName: "faq.md", Text: "persisted", Embedding: []float64{1, 1}},
+	}))
+
+	reloaded, err := vector.NewFile(path)
+	gt.NoError(t, err)
+	results, err := reloaded.Search(ctx, agentUUID, []float64{1, 1}, 10)
+	gt.NoError(t, err)
+	gt.A(t, results).Length(1)
+	gt.Equal(t, results[0].Chunk.Text, "persisted")
+	gt.Equal(t, results[0].Chunk.DocumentID, docID)
+}
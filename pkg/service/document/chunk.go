@@ -0,0 +1,60 @@
+package document
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk splits text into chunks of at most size bytes. Text is split at line breaks where
+// possible, and a line longer than size is split at a rune boundary.
+func Chunk(text string, size int) []string {
+	if text == "" || size <= 0 {
+		return nil
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if current.Len()+len(line) <= size {
+			current.WriteString(line)
+			continue
+		}
+		flush()
+
+		for len(line) > size {
+			cut := size
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = size
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	return chunks
+}
+
+// Truncate returns leading chunks whose total size fits in budget bytes, and whether any
+// chunk is dropped
+func Truncate(chunks []string, budget int) ([]string, bool) {
+	total := 0
+	for i, chunk := range chunks {
+		if total+len(chunk) > budget {
+			return chunks[:i], true
+		}
+		total += len(chunk)
+	}
+	return chunks, false
+}
@@ -0,0 +1,85 @@
+package document_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/service/document"
+)
+
+func TestIsSupported(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		mimetype string
+		expected bool
+	}{
+		{name: "plain text", file: "app.log", mimetype: "text/plain", expected: true},
+		{name: "CSV", file: "users.csv", mimetype: "text/csv", expected: true},
+		{name: "JSON", file: "event.json", mimetype: "application/json", expected: true},
+		{name: "source code as binary", file: "main.go", mimetype: "application/octet-stream", expected: true},
+		{name: "PDF", file: "report.pdf", mimetype: "application/pdf", expected: true},
+		{name: "archive", file: "logs.zip", mimetype: "application/zip", expected: false},
+		{name: "spreadsheet", file: "data.xlsx", mimetype: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gt.Equal(t, document.IsSupported(tc.file, tc.mimetype), tc.expected)
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Run("text is returned as is", func(t *testing.T) {
+		text, err := document.Extract("users.csv", "text/csv", []byte("id,name\n1,blue\n"))
+		gt.NoError(t, err)
+		gt.Equal(t, text, "id,name\n1,blue\n")
+	})
+
+	t.Run("invalid UTF-8 is replaced", func(t *testing.T) {
+		text, err := document.Extract("app.log", "text/plain", []byte("ok\xff"))
+		gt.NoError(t, err)
+		gt.Equal(t, text, "ok�")
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := document.Extract("logs.zip", "application/zip", []byte("PK"))
+		gt.True(t, errors.Is(err, document.ErrUnsupportedType))
+	})
+
+	t.Run("broken PDF", func(t *testing.T) {
+		_, err := document.Extract("report.pdf", "application/pdf", []byte("not a pdf"))
+		gt.Error(t, err)
+	})
+}
+
+func TestChunk(t *testing.T) {
+	t.Run("split at line breaks", func(t *testing.T) {
+		chunks := document.Chunk("aaa\nbbb\nccc\n", 8)
+		gt.Equal(t, chunks, []string{"aaa\nbbb\n", "ccc\n"})
+	})
+
+	t.Run("long line is split at rune boundary", func(t *testing.T) {
+		chunks := document.Chunk(strings.Repeat("あ", 3), 4)
+		gt.Equal(t, chunks, []string{"あ", "あ", "あ"})
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		gt.A(t, document.Chunk("", 8)).Length(0)
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	chunks := []string{"aaaa", "bbbb", "cccc"}
+
+	kept, truncated := document.Truncate(chunks, 9)
+	gt.Equal(t, kept, []string{"aaaa", "bbbb"})
+	gt.True(t, truncated)
+
+	kept, truncated = document.Truncate(chunks, 12)
+	gt.A(t, kept).Length(3)
+	gt.False(t, truncated)
+}
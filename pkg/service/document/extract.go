@@ -0,0 +1,97 @@
+package document
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// ErrUnsupportedType is returned when text can not be extracted from the file type
+var ErrUnsupportedType = errors.New("unsupported document type")
+
+// textMimeTypes are non text/* MIME types that are read as plain text
+var textMimeTypes = []string{
+	"application/json",
+	"application/x-ndjson",
+	"application/xml",
+	"application/yaml",
+	"application/x-yaml",
+	"application/toml",
+	"application/javascript",
+	"application/x-sh",
+	"application/sql",
+}
+
+// textExtensions are read as plain text regardless of the MIME type, because Slack often reports
+// source code and logs as application/octet-stream
+var textExtensions = []string{
+	".txt", ".log", ".md", ".markdown", ".csv", ".tsv", ".json", ".jsonl", ".ndjson",
+	".yaml", ".yml", ".toml", ".ini", ".conf", ".xml", ".html", ".css", ".sql", ".graphql",
+	".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java", ".kt", ".scala", ".rb", ".rs",
+	".c", ".h", ".cc", ".cpp", ".hpp", ".cs", ".php", ".swift", ".sh", ".bash", ".zsh",
+	".ps1", ".tf", ".hcl", ".rego", ".lua", ".pl", ".r", ".dockerfile",
+}
+
+// IsSupported reports whether text can be extracted from the file
+func IsSupported(name, mimetype string) bool {
+	return isPDF(name, mimetype) || isText(name, mimetype)
+}
+
+// Extract returns text of the file. PDF text is extracted page by page, and other supported
+// files are read as UTF-8 text.
+func Extract(name, mimetype string, data []byte) (string, error) {
+	switch {
+	case isPDF(name, mimetype):
+		return extractPDF(data)
+	case isText(name, mimetype):
+		return strings.ToValidUTF8(string(data), string(utf8.RuneError)), nil
+	default:
+		return "", goerr.Wrap(ErrUnsupportedType, "can not extract text",
+			goerr.V("name", name),
+			goerr.V("mimetype", mimetype))
+	}
+}
+
+func isPDF(name, mimetype string) bool {
+	return mimetype == "application/pdf" || strings.EqualFold(filepath.Ext(name), ".pdf")
+}
+
+func isText(name, mimetype string) bool {
+	mediaType, _, _ := strings.Cut(mimetype, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	if strings.HasPrefix(mediaType, "text/") || slices.Contains(textMimeTypes, mediaType) {
+		return true
+	}
+	return slices.Contains(textExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+func extractPDF(data []byte) (text string, err error) {
+	// The PDF parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = goerr.New("failed to parse PDF", goerr.V("panic", r))
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to open PDF")
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to extract text from PDF")
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(plain); err != nil {
+		return "", goerr.Wrap(err, "failed to read text of PDF")
+	}
+	return buf.String(), nil
+}
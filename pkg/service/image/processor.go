@@ -66,6 +66,14 @@ func NewProcessor(
 	}
 }
 
+// Validate checks the image file with the rules of ProcessAndStore without storing it
+func (p *Processor) Validate(file io.ReadSeeker, contentType string, fileSize int64) error {
+	if _, err := p.validator.ValidateFile(file, contentType, fileSize); err != nil {
+		return goerr.Wrap(err, "image validation failed")
+	}
+	return nil
+}
+
 // ProcessAndStore processes an image file and stores it with thumbnails
 func (p *Processor) ProcessAndStore(ctx context.Context, agentID types.UUID, file io.ReadSeeker, contentType string, fileSize int64) (*imageModel.AgentImage, error) {
 	slog.Debug("Starting image processing",
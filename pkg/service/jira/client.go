@@ -0,0 +1,181 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+// defaultAPIBaseURL is the Atlassian API gateway for OAuth 2.0 (3LO) apps
+const defaultAPIBaseURL = "https://api.atlassian.com/ex/jira/"
+
+// Client is a Jira Cloud REST API client authorized by a user's OAuth access token
+type Client struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// ClientOption is a functional option for Client
+type ClientOption func(*Client)
+
+// WithAPIBaseURL overrides the API base URL. The cloud ID is not appended to the given URL.
+func WithAPIBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a new Jira REST API client for the given cloud ID (site)
+func NewClient(cloudID, accessToken string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:     defaultAPIBaseURL + url.PathEscape(cloudID),
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Issue represents a Jira issue returned by search
+type Issue struct {
+	Key       string
+	Summary   string
+	Status    string
+	IssueType string
+	Assignee  string
+	Updated   string
+}
+
+type searchRequest struct {
+	JQL        string   `json:"jql"`
+	MaxResults int      `json:"maxResults"`
+	Fields     []string `json:"fields"`
+}
+
+type searchResponse struct {
+	Issues []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  *struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			IssueType *struct {
+				Name string `json:"name"`
+			} `json:"issuetype"`
+			Assignee *struct {
+				DisplayName string `json:"displayName"`
+			} `json:"assignee"`
+			Updated string `json:"updated"`
+		} `json:"fields"`
+	} `json:"issues"`
+}
+
+// SearchIssues runs a JQL query and returns up to maxResults issues
+func (c *Client) SearchIssues(ctx context.Context, jql string, maxResults int) ([]*Issue, error) {
+	body, err := json.Marshal(searchRequest{
+		JQL:        jql,
+		MaxResults: maxResults,
+		Fields:     []string{"summary", "status", "issuetype", "assignee", "updated"},
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal search request")
+	}
+
+	var resp searchResponse
+	if err := c.do(ctx, http.MethodPost, "/rest/api/3/search/jql", bytes.NewReader(body), &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to search Jira issues", goerr.V("jql", jql))
+	}
+
+	issues := make([]*Issue, 0, len(resp.Issues))
+	for _, raw := range resp.Issues {
+		issue := &Issue{
+			Key:     raw.Key,
+			Summary: raw.Fields.Summary,
+			Updated: raw.Fields.Updated,
+		}
+		if raw.Fields.Status != nil {
+			issue.Status = raw.Fields.Status.Name
+		}
+		if raw.Fields.IssueType != nil {
+			issue.IssueType = raw.Fields.IssueType.Name
+		}
+		if raw.Fields.Assignee != nil {
+			issue.Assignee = raw.Fields.Assignee.DisplayName
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+type boardConfigurationResponse struct {
+	Filter struct {
+		ID string `json:"id"`
+	} `json:"filter"`
+}
+
+// GetBoardFilterID returns the ID of the saved filter that defines the board's issues
+func (c *Client) GetBoardFilterID(ctx context.Context, boardID string) (string, error) {
+	var resp boardConfigurationResponse
+	path := "/rest/agile/1.0/board/" + url.PathEscape(boardID) + "/configuration"
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return "", goerr.Wrap(err, "failed to get board configuration", goerr.V("board_id", boardID))
+	}
+
+	if resp.Filter.ID == "" {
+		return "", goerr.New("board has no filter", goerr.V("board_id", boardID))
+	}
+
+	return resp.Filter.ID, nil
+}
+
+// do sends an API request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create request")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send request", goerr.V("path", path))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return goerr.New("Jira API request failed",
+			goerr.V("path", path),
+			goerr.V("status", resp.StatusCode),
+			goerr.V("response", string(respBody)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return goerr.Wrap(err, "failed to decode response", goerr.V("path", path))
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package jira_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/service/jira"
+)
+
+func TestClient_SearchIssues(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Method, http.MethodPost)
+		gt.Equal(t, r.URL.Path, "/rest/api/3/search/jql")
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"issues": [
+				{
+					"key": "OPS-1",
+					"fields": {
+						"summary": "Database is slow",
+						"status": {"name": "In Progress"},
+						"issuetype": {"name": "Bug"},
+						"assignee": {"displayName": "Alice"},
+						"updated": "2025-01-15T12:00:00.000+0000"
+					}
+				},
+				{
+					"key": "OPS-2",
+					"fields": {"summary": "Unassigned task", "status": {"name": "To Do"}}
+				}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	client := jira.NewClient("cloud-id", "test-token", jira.WithAPIBaseURL(server.URL))
+	issues, err := client.SearchIssues(context.Background(), `project = "OPS"`, 10)
+	gt.NoError(t, err)
+	gt.A(t, issues).Length(2)
+
+	gt.Equal(t, received["jql"], `project = "OPS"`)
+	gt.Equal(t, received["maxResults"], float64(10))
+
+	gt.Equal(t, issues[0].Key, "OPS-1")
+	gt.Equal(t, issues[0].Summary, "Database is slow")
+	gt.Equal(t, issues[0].Status, "In Progress")
+	gt.Equal(t, issues[0].IssueType, "Bug")
+	gt.Equal(t, issues[0].Assignee, "Alice")
+	gt.Equal(t, issues[1].Assignee, "")
+}
+
+func TestClient_ErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":["invalid jql"]}`))
+	}))
+	defer server.Close()
+
+	client := jira.NewClient("cloud-id", "test-token", jira.WithAPIBaseURL(server.URL))
+	_, err := client.SearchIssues(context.Background(), "invalid", 10)
+	gt.Error(t, err)
+}
+
+func TestClient_GetBoardFilterID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.URL.Path, "/rest/agile/1.0/board/42/configuration")
+		_, _ = w.Write([]byte(`{"id": 42, "filter": {"id": "10001"}}`))
+	}))
+	defer server.Close()
+
+	client := jira.NewClient("cloud-id", "test-token", jira.WithAPIBaseURL(server.URL))
+	filterID, err := client.GetBoardFilterID(context.Background(), "42")
+	gt.NoError(t, err)
+	gt.Equal(t, filterID, "10001")
+}
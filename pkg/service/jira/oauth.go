@@ -67,7 +67,7 @@ func (s *OAuthService) GenerateOAuthURL() (string, string, error) {
 	params := url.Values{
 		"audience":      {"api.atlassian.com"},
 		"client_id":     {s.config.ClientID},
-		"scope":         {"read:jira-work read:jira-user"},
+		"scope":         {"read:jira-work read:jira-user read:board-scope:jira-software"},
 		"redirect_uri":  {s.config.RedirectURI},
 		"state":         {state},
 		"response_type": {"code"},
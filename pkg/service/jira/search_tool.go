@@ -0,0 +1,235 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/utils/toolarg"
+)
+
+const (
+	// SearchToolName is the name of the Jira issue search tool
+	SearchToolName = "search_jira_issues"
+	// defaultSearchLimit is the number of issues returned when the LLM does not specify a limit
+	defaultSearchLimit = 20
+	// maxSearchLimit caps the number of issues returned by a single tool call
+	maxSearchLimit = 50
+)
+
+// SearchTool is a gollem tool that searches Jira issues within the projects and boards
+// configured by agent.JiraSearchConfig
+type SearchTool struct {
+	client  *Client
+	configs []*agent.JiraSearchConfig
+	siteURL string
+
+	// boardFilters caches board ID to filter ID resolution
+	boardFilters map[string]string
+}
+
+// NewSearchTool creates a new Jira issue search tool. siteURL is used to build issue links.
+func NewSearchTool(client *Client, configs []*agent.JiraSearchConfig, siteURL string) *SearchTool {
+	return &SearchTool{
+		client:       client,
+		configs:      configs,
+		siteURL:      strings.TrimSuffix(siteURL, "/"),
+		boardFilters: make(map[string]string),
+	}
+}
+
+// Ensure SearchTool implements gollem.Tool interface
+var _ gollem.Tool = (*SearchTool)(nil)
+
+// Spec returns the tool specification
+func (t *SearchTool) Spec() gollem.ToolSpec {
+	var b strings.Builder
+	b.WriteString("Search Jira issues with JQL. The search is always limited to the following projects:")
+	for _, config := range t.configs {
+		fmt.Fprintf(&b, "\n- %s (%s)", config.ProjectKey, config.ProjectName)
+		if config.BoardName != nil && *config.BoardName != "" {
+			fmt.Fprintf(&b, ", board: %s", *config.BoardName)
+		}
+		if config.Description != nil && *config.Description != "" {
+			fmt.Fprintf(&b, ": %s", *config.Description)
+		}
+	}
+	b.WriteString("\nReturns issue keys, summaries, statuses and links.")
+
+	return gollem.ToolSpec{
+		Name:        SearchToolName,
+		Description: b.String(),
+		Parameters: map[string]*gollem.Parameter{
+			"jql": {
+				Type:        gollem.TypeString,
+				Description: `Additional JQL conditions such as 'status = "In Progress" AND assignee = currentUser()'. ORDER BY is allowed. Project restriction is applied automatically.`,
+			},
+			"keyword": {
+				Type:        gollem.TypeString,
+				Description: "Full text search keyword for summary, description and comments",
+			},
+			"project": {
+				Type:        gollem.TypeString,
+				Description: "Project key to narrow the search to one of the configured projects",
+			},
+			"limit": {
+				Type:        gollem.TypeInteger,
+				Description: fmt.Sprintf("Maximum number of issues to return (default %d, max %d)", defaultSearchLimit, maxSearchLimit),
+			},
+		},
+	}
+}
+
+// Run searches Jira issues with the given arguments
+func (t *SearchTool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	jql, err := t.buildJQL(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := toolarg.Int(args, "limit", defaultSearchLimit)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	issues, err := t.client.SearchIssues(ctx, jql, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, 0, len(issues))
+	for _, issue := range issues {
+		results = append(results, map[string]any{
+			"key":        issue.Key,
+			"summary":    issue.Summary,
+			"status":     issue.Status,
+			"issue_type": issue.IssueType,
+			"assignee":   issue.Assignee,
+			"updated":    issue.Updated,
+			"link":       t.siteURL + "/browse/" + issue.Key,
+		})
+	}
+
+	return map[string]any{
+		"jql":    jql,
+		"count":  len(results),
+		"issues": results,
+	}, nil
+}
+
+// buildJQL combines the configured project/board scope with conditions given by the LLM
+func (t *SearchTool) buildJQL(ctx context.Context, args map[string]any) (string, error) {
+	project := strings.ToUpper(toolarg.String(args, "project"))
+
+	var scopes []string
+	for _, config := range t.configs {
+		if project != "" && !strings.EqualFold(config.ProjectKey, project) {
+			continue
+		}
+		scopes = append(scopes, t.scopeClause(ctx, config))
+	}
+	if len(scopes) == 0 {
+		return "", goerr.New("project is not configured for this agent", goerr.V("project", project))
+	}
+
+	clauses := []string{"(" + strings.Join(scopes, " OR ") + ")"}
+
+	userJQL, orderBy := splitOrderBy(toolarg.String(args, "jql"))
+	if userJQL != "" {
+		if !balancedParentheses(userJQL) {
+			return "", goerr.New("jql has unbalanced parentheses or quotes", goerr.V("jql", userJQL))
+		}
+		clauses = append(clauses, "("+userJQL+")")
+	}
+
+	if keyword := toolarg.String(args, "keyword"); keyword != "" {
+		clauses = append(clauses, "text ~ "+quoteJQL(keyword))
+	}
+
+	if orderBy == "" {
+		orderBy = "updated DESC"
+	}
+
+	return strings.Join(clauses, " AND ") + " ORDER BY " + orderBy, nil
+}
+
+// scopeClause returns the JQL restricting issues to the project (and board if configured)
+func (t *SearchTool) scopeClause(ctx context.Context, config *agent.JiraSearchConfig) string {
+	clause := "project = " + quoteJQL(config.ProjectKey)
+	if config.BoardID == nil || *config.BoardID == "" {
+		return clause
+	}
+
+	filterID, ok := t.boardFilters[*config.BoardID]
+	if !ok {
+		id, err := t.client.GetBoardFilterID(ctx, *config.BoardID)
+		if err != nil {
+			// Board access may require additional scopes. Project restriction still applies.
+			ctxlog.From(ctx).Warn("failed to resolve board filter, search whole project instead",
+				"error", err,
+				"project_key", config.ProjectKey,
+				"board_id", *config.BoardID,
+			)
+		}
+		filterID = id
+		t.boardFilters[*config.BoardID] = filterID
+	}
+
+	if filterID == "" {
+		return clause
+	}
+	return "(" + clause + " AND filter = " + filterID + ")"
+}
+
+// splitOrderBy separates the ORDER BY clause from a JQL query
+func splitOrderBy(jql string) (string, string) {
+	idx := strings.LastIndex(strings.ToUpper(jql), "ORDER BY")
+	if idx < 0 {
+		return jql, ""
+	}
+	return strings.TrimSpace(jql[:idx]), strings.TrimSpace(jql[idx+len("ORDER BY"):])
+}
+
+// balancedParentheses reports whether parentheses outside of quoted strings are balanced
+// and all quotes are closed, so that the query can not escape the project restriction
+func balancedParentheses(jql string) bool {
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range jql {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0 && quote == 0
+}
+
+// quoteJQL quotes a string literal for JQL
+func quoteJQL(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
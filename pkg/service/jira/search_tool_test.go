@@ -0,0 +1,98 @@
+package jira_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/service/jira"
+)
+
+func newTestJiraServer(t *testing.T, jqls *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/agile/1.0/board/7/configuration":
+			_, _ = w.Write([]byte(`{"filter": {"id": "10001"}}`))
+		case "/rest/api/3/search/jql":
+			var req struct {
+				JQL string `json:"jql"`
+			}
+			gt.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			*jqls = append(*jqls, req.JQL)
+			_, _ = w.Write([]byte(`{"issues": [{"key": "OPS-1", "fields": {"summary": "Database is slow", "status": {"name": "Done"}}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSearchTool_Spec(t *testing.T) {
+	boardName := "Ops board"
+	configs := []*agent.JiraSearchConfig{
+		agent.NewJiraSearchConfig("agent-uuid", "OPS", "Operations", nil, &boardName, nil, true),
+	}
+	tool := jira.NewSearchTool(jira.NewClient("cloud-id", "token"), configs, "https://example.atlassian.net")
+
+	spec := tool.Spec()
+	gt.Equal(t, spec.Name, jira.SearchToolName)
+	gt.S(t, spec.Description).Contains("OPS (Operations)")
+	gt.S(t, spec.Description).Contains("Ops board")
+	gt.M(t, spec.Parameters).HasKey("jql")
+	gt.M(t, spec.Parameters).HasKey("keyword")
+	gt.M(t, spec.Parameters).HasKey("project")
+	gt.M(t, spec.Parameters).HasKey("limit")
+}
+
+func TestSearchTool_Run(t *testing.T) {
+	ctx := context.Background()
+	var jqls []string
+	server := newTestJiraServer(t, &jqls)
+	defer server.Close()
+
+	boardID := "7"
+	configs := []*agent.JiraSearchConfig{
+		agent.NewJiraSearchConfig("agent-uuid", "OPS", "Operations", nil, nil, nil, true),
+		agent.NewJiraSearchConfig("agent-uuid", "DEV", "Development", &boardID, nil, nil, true),
+	}
+	client := jira.NewClient("cloud-id", "token", jira.WithAPIBaseURL(server.URL))
+	tool := jira.NewSearchTool(client, configs, "https://example.atlassian.net/")
+
+	t.Run("restricts search to configured projects and boards", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{
+			"jql":     `status = "In Progress" ORDER BY created ASC`,
+			"keyword": "database",
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 1)
+
+		gt.Equal(t, jqls[len(jqls)-1],
+			`(project = "OPS" OR (project = "DEV" AND filter = 10001)) AND (status = "In Progress") AND text ~ "database" ORDER BY created ASC`)
+
+		issues := result["issues"].([]map[string]any)
+		gt.Equal(t, issues[0]["key"], "OPS-1")
+		gt.Equal(t, issues[0]["summary"], "Database is slow")
+		gt.Equal(t, issues[0]["status"], "Done")
+		gt.Equal(t, issues[0]["link"], "https://example.atlassian.net/browse/OPS-1")
+	})
+
+	t.Run("narrows to a single project", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"project": "ops"})
+		gt.NoError(t, err)
+		gt.Equal(t, jqls[len(jqls)-1], `(project = "OPS") ORDER BY updated DESC`)
+	})
+
+	t.Run("rejects project not configured", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"project": "SEC"})
+		gt.Error(t, err)
+	})
+
+	t.Run("rejects jql escaping project restriction", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"jql": `status = Done) OR (project = SEC`})
+		gt.Error(t, err)
+	})
+}
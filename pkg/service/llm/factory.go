@@ -189,6 +189,62 @@ func (f *Factory) GetFallbackClient(ctx context.Context) (gollem.LLMClient, erro
 	return f.CreateClient(ctx, f.config.Fallback.Provider, f.config.Fallback.Model)
 }
 
+// GetRoutingClient returns the LLM client for the agent router if enabled
+func (f *Factory) GetRoutingClient(ctx context.Context) (gollem.LLMClient, error) {
+	if !f.config.Routing.Enabled {
+		return nil, goerr.New("routing is not enabled")
+	}
+
+	if f.config.Routing.Provider == "" || f.config.Routing.Model == "" {
+		return nil, goerr.New("routing provider/model not configured")
+	}
+
+	return f.CreateClient(ctx, f.config.Routing.Provider, f.config.Routing.Model)
+}
+
+// GetEmbeddingClient returns the LLM client to embed knowledge documents if enabled. The client
+// is not cached because it is created once at startup.
+func (f *Factory) GetEmbeddingClient(ctx context.Context) (gollem.LLMClient, error) {
+	cfg := f.config.Knowledge
+	if !cfg.Enabled {
+		return nil, goerr.New("knowledge is not enabled")
+	}
+	if cfg.Provider == "" || cfg.Model == "" {
+		return nil, goerr.New("knowledge embedding provider/model not configured")
+	}
+
+	providerType := types.LLMProviderFromString(cfg.Provider)
+	cred, exists := f.credentials[providerType]
+	if !exists {
+		return nil, goerr.New("no credentials configured for embedding provider", goerr.V("provider", cfg.Provider))
+	}
+
+	switch providerType {
+	case types.LLMProviderGemini:
+		if cred.ProjectID == "" {
+			return nil, goerr.New("Gemini requires project ID")
+		}
+		client, err := gemini.New(ctx, cred.ProjectID, cred.Location, gemini.WithEmbeddingModel(cfg.Model))
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create Gemini embedding client")
+		}
+		return client, nil
+
+	case types.LLMProviderOpenAI:
+		if cred.APIKey == "" {
+			return nil, goerr.New("OpenAI requires API key")
+		}
+		client, err := openai.New(ctx, cred.APIKey, openai.WithEmbeddingModel(cfg.Model))
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create OpenAI embedding client")
+		}
+		return client, nil
+
+	default:
+		return nil, goerr.New("provider does not support embeddings", goerr.V("provider", cfg.Provider))
+	}
+}
+
 // GetConfig returns the providers configuration
 func (f *Factory) GetConfig() *llm.ProvidersConfig {
 	return f.config
@@ -242,6 +242,67 @@ func TestFactory_GetFallbackClient(t *testing.T) {
 	})
 }
 
+func TestFactory_GetRoutingClient(t *testing.T) {
+	t.Run("Routing enabled", func(t *testing.T) {
+		config := &domainLLM.ProvidersConfig{
+			Providers: map[string]domainLLM.Provider{
+				"openai": {
+					ID:          "openai",
+					DisplayName: "OpenAI",
+					Models: []domainLLM.Model{
+						{ID: "gpt-5-nano-2025-08-07", DisplayName: "GPT-5 Nano"},
+					},
+				},
+			},
+			Routing: domainLLM.RoutingConfig{
+				Enabled:   true,
+				Provider:  "openai",
+				Model:     "gpt-5-nano-2025-08-07",
+				Threshold: 0.7,
+			},
+		}
+
+		credentials := map[types.LLMProvider]llm.Credential{
+			types.LLMProviderOpenAI: {APIKey: "test-api-key"},
+		}
+
+		factory, err := llm.NewFactory(config, credentials)
+		gt.Value(t, err).Equal(nil)
+
+		ctx := context.Background()
+		client, err := factory.GetRoutingClient(ctx)
+		gt.Value(t, err).Equal(nil)
+		gt.Value(t, client).NotEqual(nil)
+	})
+
+	t.Run("Routing disabled", func(t *testing.T) {
+		config := &domainLLM.ProvidersConfig{
+			Providers: map[string]domainLLM.Provider{
+				"openai": {
+					ID:          "openai",
+					DisplayName: "OpenAI",
+					Models: []domainLLM.Model{
+						{ID: "gpt-5-nano-2025-08-07", DisplayName: "GPT-5 Nano"},
+					},
+				},
+			},
+		}
+
+		credentials := map[types.LLMProvider]llm.Credential{
+			types.LLMProviderOpenAI: {APIKey: "test-api-key"},
+		}
+
+		factory, err := llm.NewFactory(config, credentials)
+		gt.Value(t, err).Equal(nil)
+
+		ctx := context.Background()
+		client, err := factory.GetRoutingClient(ctx)
+		gt.Value(t, err).NotEqual(nil)
+		gt.S(t, err.Error()).Contains("routing is not enabled")
+		gt.Value(t, client).Equal(nil)
+	})
+}
+
 func TestFactory_GetConfig(t *testing.T) {
 	config := &domainLLM.ProvidersConfig{
 		Providers: map[string]domainLLM.Provider{
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"gopkg.in/yaml.v3"
+)
+
+// Script is a set of canned responses of ScriptedClient. It is written in YAML as:
+//
+//	responses:
+//	  - match: "(?i)disk usage"
+//	    text: "Run df -h on the host and enable log rotation."
+//	  - match: "## Rubric"
+//	    text: '{"pass": true, "reason": "scripted"}'
+//	default: "I don't know."
+type Script struct {
+	Responses []*ScriptedResponse `yaml:"responses"`
+	// Default is returned if no response matches the input. An error is returned if it is empty.
+	Default string `yaml:"default"`
+}
+
+// ScriptedResponse is a canned response to inputs that match the pattern
+type ScriptedResponse struct {
+	// Match is a regular expression matched against the text of the input. It matches any input if
+	// empty.
+	Match string `yaml:"match"`
+	Text  string `yaml:"text"`
+
+	re *regexp.Regexp
+}
+
+// ScriptedClient is an LLM client that returns canned responses instead of calling LLM APIs, so
+// that agents can be evaluated without API keys, e.g. in CI. The first response whose pattern
+// matches the input is returned. It never calls tools, and its sessions do not keep history.
+type ScriptedClient struct {
+	script *Script
+}
+
+// Ensure ScriptedClient implements gollem.LLMClient
+var _ gollem.LLMClient = (*ScriptedClient)(nil)
+
+// LoadScript reads the script of ScriptedClient from the YAML file
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read LLM script", goerr.V("path", path))
+	}
+
+	var script Script
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse LLM script", goerr.V("path", path))
+	}
+	return &script, nil
+}
+
+// NewScriptedClient creates a new ScriptedClient with the script
+func NewScriptedClient(script *Script) (*ScriptedClient, error) {
+	for i, resp := range script.Responses {
+		re, err := regexp.Compile(resp.Match)
+		if err != nil {
+			return nil, goerr.Wrap(err, "invalid pattern of scripted response", goerr.V("index", i), goerr.V("match", resp.Match))
+		}
+		resp.re = re
+	}
+
+	return &ScriptedClient{script: script}, nil
+}
+
+// NewSession creates a new session. Options are ignored.
+func (c *ScriptedClient) NewSession(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+	return &scriptedSession{script: c.script}, nil
+}
+
+// GenerateEmbedding is not supported by ScriptedClient
+func (c *ScriptedClient) GenerateEmbedding(ctx context.Context, dimension int, input []string) ([][]float64, error) {
+	return nil, goerr.New("embedding is not supported by scripted LLM client")
+}
+
+// CountTokens is not supported by ScriptedClient. History compaction falls back to estimating
+// the number of tokens from the size of the history.
+func (c *ScriptedClient) CountTokens(ctx context.Context, history *gollem.History) (int, error) {
+	return 0, goerr.New("token counting is not supported by scripted LLM client")
+}
+
+// IsCompatibleHistory accepts any history because sessions of ScriptedClient ignore history
+func (c *ScriptedClient) IsCompatibleHistory(ctx context.Context, history *gollem.History) error {
+	return nil
+}
+
+type scriptedSession struct {
+	script *Script
+}
+
+func (s *scriptedSession) GenerateContent(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+	var texts []string
+	for _, in := range input {
+		if text, ok := in.(gollem.Text); ok {
+			texts = append(texts, string(text))
+		}
+	}
+	prompt := strings.Join(texts, "\n")
+
+	for _, resp := range s.script.Responses {
+		if resp.re.MatchString(prompt) {
+			return &gollem.Response{Texts: []string{resp.Text}}, nil
+		}
+	}
+	if s.script.Default != "" {
+		return &gollem.Response{Texts: []string{s.script.Default}}, nil
+	}
+
+	return nil, goerr.New("no scripted response matches input", goerr.V("input", prompt))
+}
+
+func (s *scriptedSession) GenerateStream(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error) {
+	resp, err := s.GenerateContent(ctx, input...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *gollem.Response, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func (s *scriptedSession) History() *gollem.History {
+	return nil
+}
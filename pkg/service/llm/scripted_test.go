@@ -0,0 +1,67 @@
+package llm_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/service/llm"
+)
+
+func TestScriptedClient(t *testing.T) {
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	gt.NoError(t, os.WriteFile(path, []byte(`
+responses:
+  - match: "(?i)disk usage"
+    text: "Run df -h and enable log rotation."
+  - match: "## Rubric"
+    text: '{"pass": true, "reason": "scripted"}'
+default: "I don't know."
+`), 0600))
+
+	script, err := llm.LoadScript(path)
+	gt.NoError(t, err)
+	client, err := llm.NewScriptedClient(script)
+	gt.NoError(t, err)
+
+	session, err := client.NewSession(ctx, gollem.WithSessionSystemPrompt("You are an SRE."))
+	gt.NoError(t, err)
+
+	resp, err := session.GenerateContent(ctx, gollem.Text("Disk usage of web-1 is 95%"))
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Texts, []string{"Run df -h and enable log rotation."})
+
+	resp, err = session.GenerateContent(ctx, gollem.Text("Who are you?"))
+	gt.NoError(t, err)
+	gt.Equal(t, resp.Texts, []string{"I don't know."})
+
+	stream, err := session.GenerateStream(ctx, gollem.Text("## Rubric\nMentions df"))
+	gt.NoError(t, err)
+	chunk := <-stream
+	gt.Equal(t, chunk.Texts, []string{`{"pass": true, "reason": "scripted"}`})
+
+	t.Run("no default", func(t *testing.T) {
+		client, err := llm.NewScriptedClient(&llm.Script{})
+		gt.NoError(t, err)
+		session, err := client.NewSession(ctx)
+		gt.NoError(t, err)
+		_, err = session.GenerateContent(ctx, gollem.Text("hello"))
+		gt.Error(t, err)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		gt.NoError(t, client.IsCompatibleHistory(ctx, &gollem.History{}))
+		_, err := client.CountTokens(ctx, &gollem.History{})
+		gt.Error(t, err)
+	})
+
+	t.Run("broken pattern", func(t *testing.T) {
+		_, err := llm.NewScriptedClient(&llm.Script{Responses: []*llm.ScriptedResponse{{Match: "(disk"}}})
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,267 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+)
+
+// protocolVersion is the MCP protocol version requested by the client
+const protocolVersion = "2025-03-26"
+
+// Client is a client of an MCP server attached to an agent version
+type Client struct {
+	server    *agent.MCPServer
+	transport transport
+	nextID    atomic.Int64
+}
+
+type connectConfig struct {
+	httpClient    *http.Client
+	clientVersion string
+	policy        agent.MCPPolicy
+}
+
+// Option is a functional option for Connect
+type Option func(*connectConfig)
+
+// WithHTTPClient sets the HTTP client used for HTTP transport. The client is used as it is, so
+// addresses of the policy are not checked when connecting.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *connectConfig) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithClientVersion sets the client version reported to MCP servers
+func WithClientVersion(version string) Option {
+	return func(c *connectConfig) {
+		c.clientVersion = version
+	}
+}
+
+// WithPolicy sets the operator policy of MCP servers. Without it, stdio servers and HTTP
+// servers in private networks are rejected, including ones stored before the policy was changed.
+func WithPolicy(policy agent.MCPPolicy) Option {
+	return func(c *connectConfig) {
+		c.policy = policy
+	}
+}
+
+// Connect starts (stdio) or connects to (http) the MCP server and initializes the session.
+// The connection must be closed by Close.
+func Connect(ctx context.Context, server *agent.MCPServer, opts ...Option) (*Client, error) {
+	cfg := &connectConfig{
+		clientVersion: "dev",
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.httpClient == nil {
+		cfg.httpClient = newGuardedHTTPClient(cfg.policy)
+	}
+
+	if err := server.Validate(agent.WithMCPPolicy(cfg.policy)); err != nil {
+		return nil, goerr.Wrap(err, "invalid MCP server configuration")
+	}
+
+	client := &Client{server: server}
+
+	switch server.Transport {
+	case agent.MCPTransportStdio:
+		t, err := newStdioTransport(server.Command, server.Args, server.Env)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to start MCP server", goerr.V("name", server.Name))
+		}
+		client.transport = t
+	case agent.MCPTransportHTTP:
+		client.transport = newHTTPTransport(server.URL, server.Headers, cfg.httpClient)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, server.Timeout())
+	defer cancel()
+
+	if err := client.initialize(ctx, cfg.clientVersion); err != nil {
+		_ = client.Close()
+		return nil, goerr.Wrap(err, "failed to initialize MCP session", goerr.V("name", server.Name))
+	}
+
+	return client, nil
+}
+
+// Name returns the name of the MCP server
+func (c *Client) Name() string {
+	return c.server.Name
+}
+
+// Close terminates the session and the server process
+func (c *Client) Close() error {
+	return c.transport.close()
+}
+
+func (c *Client) initialize(ctx context.Context, clientVersion string) error {
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo": map[string]any{
+			"name":    "tamamo",
+			"version": clientVersion,
+		},
+	}, &result)
+	if err != nil {
+		return err
+	}
+
+	if t, ok := c.transport.(*httpTransport); ok && result.ProtocolVersion != "" {
+		t.setProtocolVersion(result.ProtocolVersion)
+	}
+
+	return c.transport.notify(ctx, &jsonrpcRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/initialized",
+	})
+}
+
+// call sends a JSON-RPC request and decodes the result into out
+func (c *Client) call(ctx context.Context, method string, params any, out any) error {
+	id := c.nextID.Add(1)
+	resp, err := c.transport.call(ctx, &jsonrpcRequest{
+		JSONRPC: "2.0",
+		ID:      &id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return goerr.New("MCP server returned error",
+			goerr.V("method", method),
+			goerr.V("code", resp.Error.Code),
+			goerr.V("message", resp.Error.Message))
+	}
+
+	if out != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, out); err != nil {
+			return goerr.Wrap(err, "failed to decode MCP result", goerr.V("method", method))
+		}
+	}
+	return nil
+}
+
+// ToolDefinition is a tool provided by an MCP server
+type ToolDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// ListTools returns tools provided by the server and allowed by the configuration
+func (c *Client) ListTools(ctx context.Context) ([]*ToolDefinition, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.server.Timeout())
+	defer cancel()
+
+	var tools []*ToolDefinition
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		var result struct {
+			Tools      []*ToolDefinition `json:"tools"`
+			NextCursor string            `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &result); err != nil {
+			return nil, goerr.Wrap(err, "failed to list MCP tools", goerr.V("name", c.server.Name))
+		}
+
+		for _, tool := range result.Tools {
+			if c.server.IsToolAllowed(tool.Name) {
+				tools = append(tools, tool)
+			}
+		}
+
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	return tools, nil
+}
+
+// CallTool calls a tool of the server and returns the result
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (map[string]any, error) {
+	if !c.server.IsToolAllowed(name) {
+		return nil, goerr.New("MCP tool is not allowed", goerr.V("server", c.server.Name), goerr.V("tool", name))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.server.Timeout())
+	defer cancel()
+
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	var result struct {
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+		StructuredContent map[string]any `json:"structuredContent"`
+		IsError           bool           `json:"isError"`
+	}
+	if err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		return nil, goerr.Wrap(err, "failed to call MCP tool", goerr.V("server", c.server.Name), goerr.V("tool", name))
+	}
+
+	var texts []string
+	for _, content := range result.Content {
+		switch content.Type {
+		case "text":
+			texts = append(texts, content.Text)
+		default:
+			// Binary contents such as images are not passed to LLM as tool results
+			texts = append(texts, "["+content.Type+" content omitted]")
+		}
+	}
+
+	if result.IsError {
+		return nil, goerr.New("MCP tool returned error",
+			goerr.V("server", c.server.Name),
+			goerr.V("tool", name),
+			goerr.V("content", texts))
+	}
+
+	output := map[string]any{"content": texts}
+	if result.StructuredContent != nil {
+		output["structured_content"] = result.StructuredContent
+	}
+	return output, nil
+}
+
+// Tools returns gollem tools wrapping the allowed tools of the server
+func (c *Client) Tools(ctx context.Context) ([]gollem.Tool, error) {
+	definitions, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make([]gollem.Tool, 0, len(definitions))
+	for _, def := range definitions {
+		tools = append(tools, newTool(c, def))
+	}
+	return tools, nil
+}
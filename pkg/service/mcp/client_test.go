@@ -0,0 +1,317 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/service/mcp"
+)
+
+// stubEnvKey makes the test binary behave as a stdio MCP server
+const stubEnvKey = "TAMAMO_TEST_MCP_STUB"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubEnvKey) == "1" {
+		runStdioStub()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type stubRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// handleStubRequest implements a tiny MCP server that has "echo" and "fail" tools
+func handleStubRequest(req *stubRequest) map[string]any {
+	if req.ID == nil {
+		return nil // notification
+	}
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": *req.ID}
+	switch req.Method {
+	case "initialize":
+		resp["result"] = map[string]any{
+			"protocolVersion": "2025-03-26",
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "stub", "version": "1.0.0"},
+		}
+	case "tools/list":
+		resp["result"] = map[string]any{
+			"tools": []map[string]any{
+				{
+					"name":        "echo",
+					"description": "Echo the message",
+					"inputSchema": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"message": map[string]any{"type": "string", "description": "message to echo"},
+							"count":   map[string]any{"type": "integer"},
+							"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						},
+						"required": []string{"message"},
+					},
+				},
+				{
+					"name":        "fail",
+					"description": "Always fails",
+					"inputSchema": map[string]any{"type": "object"},
+				},
+			},
+		}
+	case "tools/call":
+		var params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+
+		switch params.Name {
+		case "echo":
+			resp["result"] = map[string]any{
+				"content": []map[string]any{
+					{"type": "text", "text": fmt.Sprintf("echo: %v", params.Arguments["message"])},
+				},
+			}
+		case "fail":
+			resp["result"] = map[string]any{
+				"content": []map[string]any{{"type": "text", "text": "something wrong"}},
+				"isError": true,
+			}
+		default:
+			resp["error"] = map[string]any{"code": -32602, "message": "unknown tool"}
+		}
+	default:
+		resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+	}
+	return resp
+}
+
+func runStdioStub() {
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req stubRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+		if resp := handleStubRequest(&req); resp != nil {
+			_ = encoder.Encode(resp)
+		}
+	}
+}
+
+func newHTTPStub(t *testing.T, useSSE bool) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var req stubRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if req.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else if r.Header.Get("Mcp-Session-Id") != "session-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		resp := handleStubRequest(&req)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		data, _ := json.Marshal(resp)
+		if useSSE {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func testClient(t *testing.T, server *agent.MCPServer) {
+	t.Helper()
+	ctx := context.Background()
+
+	client, err := mcp.Connect(ctx, server, mcp.WithPolicy(agent.MCPPolicy{
+		AllowedCommands: []string{os.Args[0]},
+		// The HTTP stub listens on loopback
+		AllowPrivateNetwork: true,
+	}))
+	gt.NoError(t, err)
+	defer func() {
+		gt.NoError(t, client.Close())
+	}()
+
+	t.Run("list tools", func(t *testing.T) {
+		tools, err := client.ListTools(ctx)
+		gt.NoError(t, err)
+		gt.A(t, tools).Length(2)
+	})
+
+	t.Run("call tool", func(t *testing.T) {
+		result, err := client.CallTool(ctx, "echo", map[string]any{"message": "hello"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["content"].([]string), []string{"echo: hello"})
+	})
+
+	t.Run("tool returns error", func(t *testing.T) {
+		_, err := client.CallTool(ctx, "fail", nil)
+		gt.Error(t, err)
+	})
+
+	t.Run("gollem tools", func(t *testing.T) {
+		tools, err := client.Tools(ctx)
+		gt.NoError(t, err)
+		gt.A(t, tools).Length(2)
+
+		var echo gollem.Tool
+		for _, tool := range tools {
+			if tool.Spec().Name == server.Name+"__echo" {
+				echo = tool
+			}
+		}
+		gt.V(t, echo).NotNil()
+
+		spec := echo.Spec()
+		gt.Equal(t, spec.Required, []string{"message"})
+		gt.Equal(t, spec.Parameters["message"].Type, gollem.TypeString)
+		gt.Equal(t, spec.Parameters["count"].Type, gollem.TypeInteger)
+		gt.Equal(t, spec.Parameters["tags"].Type, gollem.TypeArray)
+		gt.Equal(t, spec.Parameters["tags"].Items.Type, gollem.TypeString)
+
+		result, err := echo.Run(ctx, map[string]any{"message": "from gollem"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["content"].([]string), []string{"echo: from gollem"})
+	})
+}
+
+func TestClientStdio(t *testing.T) {
+	testClient(t, &agent.MCPServer{
+		Name:      "stdio_stub",
+		Transport: agent.MCPTransportStdio,
+		Command:   os.Args[0],
+		Env:       map[string]string{stubEnvKey: "1"},
+	})
+}
+
+func TestClientStdioNotAllowed(t *testing.T) {
+	ctx := context.Background()
+	server := &agent.MCPServer{
+		Name:      "stdio_stub",
+		Transport: agent.MCPTransportStdio,
+		Command:   os.Args[0],
+		Env:       map[string]string{stubEnvKey: "1"},
+	}
+
+	_, err := mcp.Connect(ctx, server)
+	gt.Error(t, err)
+
+	_, err = mcp.Connect(ctx, server, mcp.WithPolicy(agent.MCPPolicy{AllowedCommands: []string{"mcp-server"}}))
+	gt.Error(t, err)
+}
+
+func TestClientHTTP(t *testing.T) {
+	srv := newHTTPStub(t, false)
+	testClient(t, &agent.MCPServer{
+		Name:      "http_stub",
+		Transport: agent.MCPTransportHTTP,
+		URL:       srv.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+	})
+}
+
+func TestClientHTTPWithSSE(t *testing.T) {
+	srv := newHTTPStub(t, true)
+	testClient(t, &agent.MCPServer{
+		Name:      "sse_stub",
+		Transport: agent.MCPTransportHTTP,
+		URL:       srv.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+	})
+}
+
+func TestClientAllowedTools(t *testing.T) {
+	ctx := context.Background()
+	srv := newHTTPStub(t, false)
+
+	client, err := mcp.Connect(ctx, &agent.MCPServer{
+		Name:         "restricted",
+		Transport:    agent.MCPTransportHTTP,
+		URL:          srv.URL,
+		Headers:      map[string]string{"Authorization": "Bearer token"},
+		AllowedTools: []string{"echo"},
+	}, mcp.WithPolicy(agent.MCPPolicy{AllowPrivateNetwork: true}))
+	gt.NoError(t, err)
+	defer client.Close()
+
+	tools, err := client.ListTools(ctx)
+	gt.NoError(t, err)
+	gt.A(t, tools).Length(1)
+	gt.Equal(t, tools[0].Name, "echo")
+
+	// Tools not in allow list cannot be called even if LLM requests it
+	_, err = client.CallTool(ctx, "fail", nil)
+	gt.Error(t, err)
+}
+
+func TestConnectFailure(t *testing.T) {
+	ctx := context.Background()
+	srv := newHTTPStub(t, false)
+
+	// Missing Authorization header is rejected by the stub
+	_, err := mcp.Connect(ctx, &agent.MCPServer{
+		Name:      "unauthorized",
+		Transport: agent.MCPTransportHTTP,
+		URL:       srv.URL,
+	}, mcp.WithPolicy(agent.MCPPolicy{AllowPrivateNetwork: true}))
+	gt.Error(t, err)
+}
+
+func TestConnectPrivateNetwork(t *testing.T) {
+	ctx := context.Background()
+	srv := newHTTPStub(t, false)
+	port := srv.URL[strings.LastIndex(srv.URL, ":")+1:]
+
+	// The stub listens on loopback, which is refused unless private network is allowed
+	for _, url := range []string{srv.URL, "http://localhost:" + port} {
+		_, err := mcp.Connect(ctx, &agent.MCPServer{
+			Name:      "internal",
+			Transport: agent.MCPTransportHTTP,
+			URL:       url,
+			Headers:   map[string]string{"Authorization": "Bearer token"},
+		})
+		gt.Error(t, err)
+	}
+}
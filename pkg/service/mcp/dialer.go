@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+)
+
+// newGuardedHTTPClient returns an HTTP client that refuses to connect to addresses not allowed
+// by the policy. Addresses are checked after name resolution, so host names resolving to
+// internal addresses (including DNS rebinding) are also refused.
+func newGuardedHTTPClient(policy agent.MCPPolicy) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return goerr.Wrap(err, "failed to parse address of MCP server", goerr.V("address", address))
+			}
+			if !policy.AllowsAddress(addrPort.Addr()) {
+				return goerr.New("address of MCP server is not allowed", goerr.V("address", address))
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be checked instead of the server, so MCP servers are connected directly
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
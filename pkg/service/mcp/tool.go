@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/m-mizutani/gollem"
+)
+
+// maxToolNameLength is the maximum tool name length accepted by LLM providers
+const maxToolNameLength = 64
+
+var toolNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Tool is a gollem tool that calls a tool of an MCP server
+type Tool struct {
+	client *Client
+	def    *ToolDefinition
+}
+
+func newTool(client *Client, def *ToolDefinition) *Tool {
+	return &Tool{
+		client: client,
+		def:    def,
+	}
+}
+
+// Ensure Tool implements gollem.Tool interface
+var _ gollem.Tool = (*Tool)(nil)
+
+// Name returns the tool name exposed to LLM. It is prefixed with the server name
+// to avoid conflicts between servers and built-in tools.
+func (t *Tool) Name() string {
+	name := t.client.Name() + "__" + toolNameInvalidChars.ReplaceAllString(t.def.Name, "_")
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+// Spec returns the tool specification converted from the MCP tool definition
+func (t *Tool) Spec() gollem.ToolSpec {
+	schema := parseSchema(t.def.InputSchema)
+
+	spec := gollem.ToolSpec{
+		Name:        t.Name(),
+		Description: t.def.Description,
+		Parameters:  make(map[string]*gollem.Parameter, len(schema.Properties)),
+		Required:    schema.Required,
+	}
+	for name, prop := range schema.Properties {
+		spec.Parameters[name] = prop.toParameter()
+	}
+	return spec
+}
+
+// Run calls the MCP tool with the arguments given by LLM
+func (t *Tool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	return t.client.CallTool(ctx, t.def.Name, args)
+}
+
+// jsonSchema is the subset of JSON Schema used by MCP tool input schemas
+type jsonSchema struct {
+	Type        any                    `json:"type"`
+	Description string                 `json:"description"`
+	Properties  map[string]*jsonSchema `json:"properties"`
+	Items       *jsonSchema            `json:"items"`
+	Required    []string               `json:"required"`
+	Enum        []any                  `json:"enum"`
+}
+
+func parseSchema(data json.RawMessage) *jsonSchema {
+	var schema jsonSchema
+	if len(data) > 0 {
+		// Tools with broken schema are still usable without parameters
+		_ = json.Unmarshal(data, &schema)
+	}
+	return &schema
+}
+
+// schemaType returns the primary type. JSON Schema allows a list such as ["string", "null"].
+func (s *jsonSchema) schemaType() string {
+	switch v := s.Type.(type) {
+	case string:
+		return v
+	case []any:
+		for _, t := range v {
+			if str, ok := t.(string); ok && str != "null" {
+				return str
+			}
+		}
+	}
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	return "string"
+}
+
+func (s *jsonSchema) toParameter() *gollem.Parameter {
+	param := &gollem.Parameter{
+		Description: s.Description,
+	}
+
+	switch s.schemaType() {
+	case "integer":
+		param.Type = gollem.TypeInteger
+	case "number":
+		param.Type = gollem.TypeNumber
+	case "boolean":
+		param.Type = gollem.TypeBoolean
+	case "array":
+		param.Type = gollem.TypeArray
+		if s.Items != nil {
+			param.Items = s.Items.toParameter()
+		} else {
+			// Some providers require item type of array
+			param.Items = &gollem.Parameter{Type: gollem.TypeString}
+		}
+	case "object":
+		param.Type = gollem.TypeObject
+		if len(s.Properties) > 0 {
+			param.Properties = make(map[string]*gollem.Parameter, len(s.Properties))
+			for name, prop := range s.Properties {
+				param.Properties[name] = prop.toParameter()
+			}
+		}
+	default:
+		param.Type = gollem.TypeString
+	}
+
+	for _, v := range s.Enum {
+		if str, ok := v.(string); ok {
+			param.Enum = append(param.Enum, str)
+		}
+	}
+
+	return param
+}
@@ -0,0 +1,391 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+)
+
+// maxMessageSize caps the size of a single JSON-RPC message read from a server
+const maxMessageSize = 16 * 1024 * 1024
+
+type jsonrpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+// transport sends JSON-RPC messages to an MCP server
+type transport interface {
+	// call sends a request and waits for the response with the same ID
+	call(ctx context.Context, req *jsonrpcRequest) (*jsonrpcMessage, error)
+	// notify sends a notification that has no response
+	notify(ctx context.Context, req *jsonrpcRequest) error
+	close() error
+}
+
+// stdioTransport runs an MCP server as a subprocess and exchanges newline delimited JSON-RPC messages
+type stdioTransport struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	writeM sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan *jsonrpcMessage
+	readErr error
+	done    chan struct{}
+}
+
+// inheritedEnvKeys are environment variables passed to MCP server commands in addition to the
+// configured ones. Other variables of tamamo such as API keys are not passed on purpose.
+var inheritedEnvKeys = []string{"PATH", "HOME", "TMPDIR", "LANG"}
+
+func newStdioTransport(command string, args []string, env map[string]string) (*stdioTransport, error) {
+	cmd := exec.Command(command, args...)
+
+	for _, key := range inheritedEnvKeys {
+		if v, ok := os.LookupEnv(key); ok {
+			cmd.Env = append(cmd.Env, key+"="+v)
+		}
+	}
+	for key, value := range env {
+		// Validation rejects these keys, but do not override what the command runs with anyway
+		if !agent.IsMCPEnvKeyAllowed(key) {
+			continue
+		}
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to open stdin of MCP server")
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to open stdout of MCP server")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, goerr.Wrap(err, "failed to start MCP server", goerr.V("command", command))
+	}
+
+	t := &stdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan *jsonrpcMessage),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop(stdout)
+
+	return t, nil
+}
+
+func (t *stdioTransport) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg jsonrpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			// Servers may write logs to stdout by mistake, ignore them
+			continue
+		}
+
+		if msg.Method != "" {
+			t.handleServerMessage(&msg)
+			continue
+		}
+		if msg.ID == nil {
+			continue
+		}
+
+		t.mu.Lock()
+		ch, ok := t.pending[*msg.ID]
+		delete(t.pending, *msg.ID)
+		t.mu.Unlock()
+		if ok {
+			ch <- &msg
+		}
+	}
+
+	t.mu.Lock()
+	t.readErr = scanner.Err()
+	if t.readErr == nil {
+		t.readErr = goerr.New("MCP server closed stdout")
+	}
+	t.mu.Unlock()
+	close(t.done)
+}
+
+// handleServerMessage responds to requests from the server. Only ping is supported.
+func (t *stdioTransport) handleServerMessage(msg *jsonrpcMessage) {
+	if msg.ID == nil {
+		return // notification
+	}
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": *msg.ID}
+	if msg.Method == "ping" {
+		resp["result"] = map[string]any{}
+	} else {
+		resp["error"] = jsonrpcError{Code: -32601, Message: "method not found"}
+	}
+	_ = t.write(resp)
+}
+
+func (t *stdioTransport) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal JSON-RPC message")
+	}
+
+	t.writeM.Lock()
+	defer t.writeM.Unlock()
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return goerr.Wrap(err, "failed to write to MCP server")
+	}
+	return nil
+}
+
+func (t *stdioTransport) call(ctx context.Context, req *jsonrpcRequest) (*jsonrpcMessage, error) {
+	ch := make(chan *jsonrpcMessage, 1)
+	t.mu.Lock()
+	t.pending[*req.ID] = ch
+	t.mu.Unlock()
+
+	cleanup := func() {
+		t.mu.Lock()
+		delete(t.pending, *req.ID)
+		t.mu.Unlock()
+	}
+
+	if err := t.write(req); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-t.done:
+		cleanup()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return nil, goerr.Wrap(t.readErr, "MCP server terminated")
+	case <-ctx.Done():
+		cleanup()
+		return nil, goerr.Wrap(ctx.Err(), "MCP request canceled", goerr.V("method", req.Method))
+	}
+}
+
+func (t *stdioTransport) notify(ctx context.Context, req *jsonrpcRequest) error {
+	return t.write(req)
+}
+
+func (t *stdioTransport) close() error {
+	_ = t.stdin.Close()
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+	}
+	_ = t.cmd.Wait()
+	return nil
+}
+
+// httpTransport talks to an MCP server with Streamable HTTP transport
+type httpTransport struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+	protocol  string
+}
+
+func newHTTPTransport(url string, headers map[string]string, httpClient *http.Client) *httpTransport {
+	return &httpTransport{
+		url:        url,
+		headers:    headers,
+		httpClient: httpClient,
+	}
+}
+
+func (t *httpTransport) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.url, body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create MCP request")
+	}
+
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	t.mu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	if t.protocol != "" {
+		req.Header.Set("Mcp-Protocol-Version", t.protocol)
+	}
+	t.mu.Unlock()
+
+	return req, nil
+}
+
+func (t *httpTransport) post(ctx context.Context, msg *jsonrpcRequest) (*http.Response, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal JSON-RPC message")
+	}
+
+	req, err := t.newRequest(ctx, http.MethodPost, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send MCP request", goerr.V("method", msg.Method))
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, goerr.New("MCP server returned error status",
+			goerr.V("method", msg.Method),
+			goerr.V("status", resp.StatusCode),
+			goerr.V("response", string(body)))
+	}
+
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+func (t *httpTransport) call(ctx context.Context, req *jsonrpcRequest) (*jsonrpcMessage, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return readSSEResponse(resp.Body, *req.ID)
+	}
+
+	var msg jsonrpcMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMessageSize)).Decode(&msg); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode MCP response", goerr.V("method", req.Method))
+	}
+	return &msg, nil
+}
+
+// readSSEResponse reads server-sent events until the response with the ID arrives
+func readSSEResponse(r io.Reader, id int64) (*jsonrpcMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case line == "":
+			// End of an event
+			if data.Len() == 0 {
+				continue
+			}
+			var msg jsonrpcMessage
+			err := json.Unmarshal([]byte(data.String()), &msg)
+			data.Reset()
+			if err == nil && msg.Method == "" && msg.ID != nil && *msg.ID == id {
+				return &msg, nil
+			}
+		}
+	}
+
+	if data.Len() > 0 {
+		var msg jsonrpcMessage
+		if err := json.Unmarshal([]byte(data.String()), &msg); err == nil && msg.ID != nil && *msg.ID == id {
+			return &msg, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, goerr.Wrap(err, "failed to read MCP event stream")
+	}
+	return nil, goerr.New("MCP event stream closed without response", goerr.V("id", id))
+}
+
+func (t *httpTransport) notify(ctx context.Context, req *jsonrpcRequest) error {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+func (t *httpTransport) setProtocolVersion(version string) {
+	t.mu.Lock()
+	t.protocol = version
+	t.mu.Unlock()
+}
+
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	// Terminate the session explicitly. Servers may not support it, so ignore errors.
+	req, err := t.newRequest(context.Background(), http.MethodDelete, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,452 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+)
+
+const (
+	// defaultAPIBaseURL is the Notion public API endpoint
+	defaultAPIBaseURL = "https://api.notion.com/v1"
+	// apiVersion is the Notion API version sent with every request
+	apiVersion = "2022-06-28"
+	// maxPageSize is the maximum page size accepted by Notion API
+	maxPageSize = 100
+)
+
+// Client is a Notion API client authorized by a user's OAuth access token
+type Client struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// ClientOption is a functional option for Client
+type ClientOption func(*Client)
+
+// WithAPIBaseURL overrides the API base URL
+func WithAPIBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a new Notion API client
+func NewClient(accessToken string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:     defaultAPIBaseURL,
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Page represents a Notion page with its properties flattened to text
+type Page struct {
+	ID               string
+	URL              string
+	Title            string
+	ParentDatabaseID string
+	LastEditedTime   string
+	Properties       map[string]string
+}
+
+// Block represents a Notion block with its text content
+type Block struct {
+	ID       string
+	Type     string
+	Text     string
+	Checked  bool
+	Language string
+	Children []*Block
+}
+
+type richText struct {
+	PlainText string `json:"plain_text"`
+}
+
+type namedValue struct {
+	Name string `json:"name"`
+}
+
+type rawProperty struct {
+	Type        string       `json:"type"`
+	Title       []richText   `json:"title"`
+	RichText    []richText   `json:"rich_text"`
+	Select      *namedValue  `json:"select"`
+	MultiSelect []namedValue `json:"multi_select"`
+	Status      *namedValue  `json:"status"`
+	People      []namedValue `json:"people"`
+	Number      *float64     `json:"number"`
+	Checkbox    *bool        `json:"checkbox"`
+	URL         *string      `json:"url"`
+	Email       *string      `json:"email"`
+	Date        *struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"date"`
+}
+
+type rawPage struct {
+	Object         string `json:"object"`
+	ID             string `json:"id"`
+	URL            string `json:"url"`
+	LastEditedTime string `json:"last_edited_time"`
+	Parent         struct {
+		Type       string `json:"type"`
+		DatabaseID string `json:"database_id"`
+	} `json:"parent"`
+	Properties map[string]rawProperty `json:"properties"`
+}
+
+type listResponse struct {
+	Results    []json.RawMessage `json:"results"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+// QueryDatabase queries pages in a database. filter is a Notion filter object and may be nil.
+// Pages are sorted by last edited time, newest first.
+func (c *Client) QueryDatabase(ctx context.Context, databaseID string, filter json.RawMessage, pageSize int) ([]*Page, error) {
+	body := map[string]any{
+		"page_size": clampPageSize(pageSize),
+		"sorts": []map[string]string{
+			{"timestamp": "last_edited_time", "direction": "descending"},
+		},
+	}
+	if len(filter) > 0 {
+		body["filter"] = filter
+	}
+
+	var resp listResponse
+	path := "/databases/" + url.PathEscape(databaseID) + "/query"
+	if err := c.do(ctx, http.MethodPost, path, body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to query Notion database", goerr.V("database_id", databaseID))
+	}
+
+	return decodePages(resp.Results)
+}
+
+// Search runs Notion search for pages matching query. It returns the next cursor if more results exist.
+func (c *Client) Search(ctx context.Context, query, cursor string, pageSize int) ([]*Page, string, error) {
+	body := map[string]any{
+		"query":     query,
+		"page_size": clampPageSize(pageSize),
+		"filter":    map[string]string{"property": "object", "value": "page"},
+	}
+	if cursor != "" {
+		body["start_cursor"] = cursor
+	}
+
+	var resp listResponse
+	if err := c.do(ctx, http.MethodPost, "/search", body, &resp); err != nil {
+		return nil, "", goerr.Wrap(err, "failed to search Notion", goerr.V("query", query))
+	}
+
+	pages, err := decodePages(resp.Results)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if resp.HasMore && resp.NextCursor != nil {
+		next = *resp.NextCursor
+	}
+	return pages, next, nil
+}
+
+// GetPage retrieves a page and its properties
+func (c *Client) GetPage(ctx context.Context, pageID string) (*Page, error) {
+	var raw rawPage
+	if err := c.do(ctx, http.MethodGet, "/pages/"+url.PathEscape(pageID), nil, &raw); err != nil {
+		return nil, goerr.Wrap(err, "failed to get Notion page", goerr.V("page_id", pageID))
+	}
+	return toPage(&raw), nil
+}
+
+// GetBlocks retrieves child blocks of a block (or page) recursively up to maxDepth levels.
+// Retrieval stops when maxBlocks blocks have been collected.
+func (c *Client) GetBlocks(ctx context.Context, blockID string, maxDepth, maxBlocks int) ([]*Block, error) {
+	count := 0
+	return c.getBlocks(ctx, blockID, maxDepth, maxBlocks, &count)
+}
+
+type rawBlock struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	HasChildren bool   `json:"has_children"`
+}
+
+type rawBlockContent struct {
+	RichText []richText   `json:"rich_text"`
+	Checked  bool         `json:"checked"`
+	Language string       `json:"language"`
+	Title    string       `json:"title"`
+	URL      string       `json:"url"`
+	Cells    [][]richText `json:"cells"`
+}
+
+func (c *Client) getBlocks(ctx context.Context, blockID string, depth, maxBlocks int, count *int) ([]*Block, error) {
+	var blocks []*Block
+	cursor := ""
+
+	for *count < maxBlocks {
+		path := "/blocks/" + url.PathEscape(blockID) + "/children?page_size=" + strconv.Itoa(maxPageSize)
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+
+		var resp listResponse
+		if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, goerr.Wrap(err, "failed to get Notion blocks", goerr.V("block_id", blockID))
+		}
+
+		for _, data := range resp.Results {
+			if *count >= maxBlocks {
+				break
+			}
+
+			block, hasChildren, err := decodeBlock(data)
+			if err != nil {
+				return nil, err
+			}
+			*count++
+
+			// child_page content belongs to another page, so do not expand it
+			if hasChildren && depth > 1 && block.Type != "child_page" && block.Type != "child_database" {
+				children, err := c.getBlocks(ctx, block.ID, depth-1, maxBlocks, count)
+				if err != nil {
+					return nil, err
+				}
+				block.Children = children
+			}
+			blocks = append(blocks, block)
+		}
+
+		if !resp.HasMore || resp.NextCursor == nil {
+			break
+		}
+		cursor = *resp.NextCursor
+	}
+
+	return blocks, nil
+}
+
+// do sends an API request with JSON body and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return goerr.Wrap(err, "failed to marshal request body")
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create request")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Notion-Version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send request", goerr.V("path", path))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return goerr.New("Notion API request failed",
+			goerr.V("path", path),
+			goerr.V("status", resp.StatusCode),
+			goerr.V("response", string(respBody)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return goerr.Wrap(err, "failed to decode response", goerr.V("path", path))
+	}
+
+	return nil
+}
+
+func decodePages(results []json.RawMessage) ([]*Page, error) {
+	pages := make([]*Page, 0, len(results))
+	for _, data := range results {
+		var raw rawPage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, goerr.Wrap(err, "failed to decode Notion page")
+		}
+		if raw.Object != "" && raw.Object != "page" {
+			continue
+		}
+		pages = append(pages, toPage(&raw))
+	}
+	return pages, nil
+}
+
+func decodeBlock(data json.RawMessage) (*Block, bool, error) {
+	var raw rawBlock
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false, goerr.Wrap(err, "failed to decode Notion block")
+	}
+
+	// Block content is stored under the key named by its type
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, false, goerr.Wrap(err, "failed to decode Notion block")
+	}
+
+	var content rawBlockContent
+	if v, ok := fields[raw.Type]; ok {
+		if err := json.Unmarshal(v, &content); err != nil {
+			return nil, false, goerr.Wrap(err, "failed to decode Notion block content",
+				goerr.V("type", raw.Type))
+		}
+	}
+
+	block := &Block{
+		ID:       raw.ID,
+		Type:     raw.Type,
+		Text:     joinRichText(content.RichText),
+		Checked:  content.Checked,
+		Language: content.Language,
+	}
+
+	switch raw.Type {
+	case "child_page", "child_database":
+		block.Text = content.Title
+	case "bookmark", "embed", "link_preview":
+		if block.Text == "" {
+			block.Text = content.URL
+		}
+	case "table_row":
+		cells := make([]string, 0, len(content.Cells))
+		for _, cell := range content.Cells {
+			cells = append(cells, joinRichText(cell))
+		}
+		block.Text = strings.Join(cells, " | ")
+	}
+
+	return block, raw.HasChildren, nil
+}
+
+func toPage(raw *rawPage) *Page {
+	page := &Page{
+		ID:             raw.ID,
+		URL:            raw.URL,
+		LastEditedTime: raw.LastEditedTime,
+		Properties:     make(map[string]string),
+	}
+	if raw.Parent.Type == "database_id" {
+		page.ParentDatabaseID = raw.Parent.DatabaseID
+	}
+
+	for name, prop := range raw.Properties {
+		if prop.Type == "title" {
+			page.Title = joinRichText(prop.Title)
+			continue
+		}
+		if v := propertyText(&prop); v != "" {
+			page.Properties[name] = v
+		}
+	}
+
+	return page
+}
+
+// propertyText converts a page property value to text
+func propertyText(prop *rawProperty) string {
+	switch prop.Type {
+	case "rich_text":
+		return joinRichText(prop.RichText)
+	case "select":
+		if prop.Select != nil {
+			return prop.Select.Name
+		}
+	case "status":
+		if prop.Status != nil {
+			return prop.Status.Name
+		}
+	case "multi_select":
+		return joinNames(prop.MultiSelect)
+	case "people":
+		return joinNames(prop.People)
+	case "number":
+		if prop.Number != nil {
+			return strconv.FormatFloat(*prop.Number, 'f', -1, 64)
+		}
+	case "checkbox":
+		if prop.Checkbox != nil {
+			return strconv.FormatBool(*prop.Checkbox)
+		}
+	case "url":
+		if prop.URL != nil {
+			return *prop.URL
+		}
+	case "email":
+		if prop.Email != nil {
+			return *prop.Email
+		}
+	case "date":
+		if prop.Date != nil {
+			if prop.Date.End != "" {
+				return prop.Date.Start + " - " + prop.Date.End
+			}
+			return prop.Date.Start
+		}
+	}
+	return ""
+}
+
+func joinRichText(texts []richText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		b.WriteString(t.PlainText)
+	}
+	return b.String()
+}
+
+func joinNames(values []namedValue) string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func clampPageSize(pageSize int) int {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return maxPageSize
+	}
+	return pageSize
+}
+
+// NormalizeID converts a Notion ID to the compact lowercase form without hyphens
+func NormalizeID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
@@ -0,0 +1,97 @@
+package notion
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format is an output format of page content
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+// RenderBlocks converts blocks to markdown or plain text
+func RenderBlocks(blocks []*Block, format Format) string {
+	var b strings.Builder
+	renderBlocks(&b, blocks, format, 0)
+	return strings.TrimSpace(b.String())
+}
+
+func renderBlocks(b *strings.Builder, blocks []*Block, format Format, depth int) {
+	indent := strings.Repeat("  ", depth)
+	number := 0
+
+	for _, block := range blocks {
+		if block.Type == "numbered_list_item" {
+			number++
+		} else {
+			number = 0
+		}
+
+		line := renderLine(block, format, number)
+		if line != "" || block.Type == "divider" {
+			b.WriteString(indent)
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+
+		if len(block.Children) > 0 {
+			renderBlocks(b, block.Children, format, depth+1)
+		}
+	}
+}
+
+func renderLine(block *Block, format Format, number int) string {
+	text := block.Text
+	md := format == FormatMarkdown
+
+	switch block.Type {
+	case "heading_1":
+		if md {
+			return "# " + text
+		}
+	case "heading_2":
+		if md {
+			return "## " + text
+		}
+	case "heading_3":
+		if md {
+			return "### " + text
+		}
+	case "bulleted_list_item", "toggle":
+		return "- " + text
+	case "numbered_list_item":
+		return strconv.Itoa(number) + ". " + text
+	case "to_do":
+		if block.Checked {
+			return "- [x] " + text
+		}
+		return "- [ ] " + text
+	case "quote", "callout":
+		if md {
+			return "> " + text
+		}
+	case "code":
+		if md {
+			return "```" + block.Language + "\n" + text + "\n```"
+		}
+	case "divider":
+		if md {
+			return "---"
+		}
+		return ""
+	case "table_row":
+		if md {
+			return "| " + text + " |"
+		}
+	case "child_page":
+		return "[page] " + text
+	case "child_database":
+		return "[database] " + text
+	}
+
+	return text
+}
@@ -0,0 +1,333 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/utils/toolarg"
+)
+
+const (
+	// SearchToolName is the name of the Notion database search tool
+	SearchToolName = "search_notion"
+	// PageToolName is the name of the Notion page content tool
+	PageToolName = "get_notion_page"
+
+	// defaultSearchLimit is the number of pages returned when the LLM does not specify a limit
+	defaultSearchLimit = 10
+	// maxSearchLimit caps the number of pages returned by a single tool call
+	maxSearchLimit = 50
+	// maxSearchRequests caps the number of search API calls per tool call
+	maxSearchRequests = 3
+	// maxSnippetLength is the maximum length of a snippet built from page properties
+	maxSnippetLength = 200
+
+	// maxBlockDepth is the maximum nesting level of blocks fetched for page content
+	maxBlockDepth = 3
+	// maxBlocks caps the number of blocks fetched for page content
+	maxBlocks = 500
+	// maxContentLength is the maximum length of page content returned to the LLM
+	maxContentLength = 20000
+)
+
+// databaseSet holds configured databases indexed by normalized ID
+type databaseSet map[string]*agent.NotionSearchConfig
+
+func newDatabaseSet(configs []*agent.NotionSearchConfig) databaseSet {
+	set := make(databaseSet, len(configs))
+	for _, config := range configs {
+		set[NormalizeID(config.DatabaseID)] = config
+	}
+	return set
+}
+
+func (s databaseSet) get(databaseID string) (*agent.NotionSearchConfig, bool) {
+	if databaseID == "" {
+		return nil, false
+	}
+	config, ok := s[NormalizeID(databaseID)]
+	return config, ok
+}
+
+// SearchTool is a gollem tool that queries and searches pages in Notion databases
+// configured by agent.NotionSearchConfig
+type SearchTool struct {
+	client    *Client
+	configs   []*agent.NotionSearchConfig
+	databases databaseSet
+}
+
+// NewSearchTool creates a new Notion database search tool
+func NewSearchTool(client *Client, configs []*agent.NotionSearchConfig) *SearchTool {
+	return &SearchTool{
+		client:    client,
+		configs:   configs,
+		databases: newDatabaseSet(configs),
+	}
+}
+
+// Ensure SearchTool implements gollem.Tool interface
+var _ gollem.Tool = (*SearchTool)(nil)
+
+// Spec returns the tool specification
+func (t *SearchTool) Spec() gollem.ToolSpec {
+	var b strings.Builder
+	b.WriteString("Search pages in Notion databases. Available databases:")
+	for _, config := range t.configs {
+		fmt.Fprintf(&b, "\n- %s (database_id: %s)", config.DatabaseName, config.DatabaseID)
+		if config.Description != nil && *config.Description != "" {
+			fmt.Fprintf(&b, ": %s", *config.Description)
+		}
+	}
+	b.WriteString("\nReturns page titles, URLs, IDs and snippets. Use " + PageToolName + " to read the content of a page.")
+
+	return gollem.ToolSpec{
+		Name:        SearchToolName,
+		Description: b.String(),
+		Parameters: map[string]*gollem.Parameter{
+			"keyword": {
+				Type:        gollem.TypeString,
+				Description: "Full text search keyword. Leave empty to list recently edited pages.",
+			},
+			"database_id": {
+				Type:        gollem.TypeString,
+				Description: "Database ID to narrow the search to one of the available databases",
+			},
+			"filter": {
+				Type:        gollem.TypeString,
+				Description: `Notion database query filter object in JSON, e.g. {"property":"Status","status":{"equals":"Done"}}. Requires database_id and is ignored when keyword is set.`,
+			},
+			"limit": {
+				Type:        gollem.TypeInteger,
+				Description: fmt.Sprintf("Maximum number of pages to return (default %d, max %d)", defaultSearchLimit, maxSearchLimit),
+			},
+		},
+	}
+}
+
+// Run searches pages with the given arguments
+func (t *SearchTool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	targets := t.databases
+	if databaseID := toolarg.String(args, "database_id"); databaseID != "" {
+		config, ok := t.databases.get(databaseID)
+		if !ok {
+			return nil, goerr.New("database is not configured for this agent", goerr.V("database_id", databaseID))
+		}
+		targets = newDatabaseSet([]*agent.NotionSearchConfig{config})
+	}
+
+	limit := toolarg.Int(args, "limit", defaultSearchLimit)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	var pages []*Page
+	var err error
+	if keyword := toolarg.String(args, "keyword"); keyword != "" {
+		pages, err = t.search(ctx, keyword, targets, limit)
+	} else {
+		pages, err = t.query(ctx, toolarg.String(args, "filter"), targets, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, 0, len(pages))
+	for _, page := range pages {
+		result := map[string]any{
+			"id":          page.ID,
+			"title":       page.Title,
+			"url":         page.URL,
+			"last_edited": page.LastEditedTime,
+			"snippet":     snippet(page),
+		}
+		if config, ok := t.databases.get(page.ParentDatabaseID); ok {
+			result["database"] = config.DatabaseName
+		}
+		results = append(results, result)
+	}
+
+	return map[string]any{
+		"count": len(results),
+		"pages": results,
+	}, nil
+}
+
+// search runs Notion full text search and keeps pages in target databases
+func (t *SearchTool) search(ctx context.Context, keyword string, targets databaseSet, limit int) ([]*Page, error) {
+	var pages []*Page
+	cursor := ""
+	for i := 0; i < maxSearchRequests && len(pages) < limit; i++ {
+		results, next, err := t.client.Search(ctx, keyword, cursor, maxPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, page := range results {
+			if _, ok := targets.get(page.ParentDatabaseID); ok {
+				pages = append(pages, page)
+				if len(pages) >= limit {
+					break
+				}
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	return pages, nil
+}
+
+// query lists pages of target databases, newest first
+func (t *SearchTool) query(ctx context.Context, filter string, targets databaseSet, limit int) ([]*Page, error) {
+	var rawFilter json.RawMessage
+	if filter != "" {
+		if len(targets) != 1 {
+			return nil, goerr.New("database_id is required to use filter")
+		}
+		if !json.Valid([]byte(filter)) {
+			return nil, goerr.New("filter must be a JSON object", goerr.V("filter", filter))
+		}
+		rawFilter = json.RawMessage(filter)
+	}
+
+	var pages []*Page
+	for _, config := range targets {
+		results, err := t.client.QueryDatabase(ctx, config.DatabaseID, rawFilter, limit)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, results...)
+	}
+
+	// Merge results of multiple databases. Notion timestamps are ISO 8601 in UTC.
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].LastEditedTime > pages[j].LastEditedTime
+	})
+	if len(pages) > limit {
+		pages = pages[:limit]
+	}
+	return pages, nil
+}
+
+// PageTool is a gollem tool that reads the content of a page in configured Notion databases
+type PageTool struct {
+	client    *Client
+	databases databaseSet
+}
+
+// NewPageTool creates a new Notion page content tool
+func NewPageTool(client *Client, configs []*agent.NotionSearchConfig) *PageTool {
+	return &PageTool{
+		client:    client,
+		databases: newDatabaseSet(configs),
+	}
+}
+
+// Ensure PageTool implements gollem.Tool interface
+var _ gollem.Tool = (*PageTool)(nil)
+
+// Spec returns the tool specification
+func (t *PageTool) Spec() gollem.ToolSpec {
+	return gollem.ToolSpec{
+		Name:        PageToolName,
+		Description: "Get the content of a Notion page found by " + SearchToolName + ". Returns the title, URL, properties and body.",
+		Parameters: map[string]*gollem.Parameter{
+			"page_id": {
+				Type:        gollem.TypeString,
+				Description: "Page ID returned by " + SearchToolName,
+			},
+			"format": {
+				Type:        gollem.TypeString,
+				Description: "Output format of the body: 'markdown' (default) or 'text'",
+			},
+		},
+		Required: []string{"page_id"},
+	}
+}
+
+// Run fetches the page content
+func (t *PageTool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	pageID := toolarg.String(args, "page_id")
+	if pageID == "" {
+		return nil, goerr.New("page_id is required")
+	}
+
+	format := FormatMarkdown
+	if Format(toolarg.String(args, "format")) == FormatText {
+		format = FormatText
+	}
+
+	page, err := t.client.GetPage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only pages in configured databases can be read even if the token has wider access
+	config, ok := t.databases.get(page.ParentDatabaseID)
+	if !ok {
+		return nil, goerr.New("page is not in a database configured for this agent", goerr.V("page_id", pageID))
+	}
+
+	blocks, err := t.client.GetBlocks(ctx, page.ID, maxBlockDepth, maxBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	content := RenderBlocks(blocks, format)
+	truncated := false
+	if len(content) > maxContentLength {
+		content = truncateString(content, maxContentLength)
+		truncated = true
+	}
+
+	return map[string]any{
+		"id":          page.ID,
+		"title":       page.Title,
+		"url":         page.URL,
+		"database":    config.DatabaseName,
+		"last_edited": page.LastEditedTime,
+		"properties":  page.Properties,
+		"format":      string(format),
+		"content":     content,
+		"truncated":   truncated,
+	}, nil
+}
+
+// snippet builds a short text from page properties in a stable order
+func snippet(page *Page) string {
+	names := make([]string, 0, len(page.Properties))
+	for name := range page.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+": "+page.Properties[name])
+	}
+	return truncateString(strings.Join(parts, " / "), maxSnippetLength)
+}
+
+// truncateString truncates s to at most n bytes without breaking a UTF-8 character
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
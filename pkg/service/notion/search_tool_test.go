@@ -0,0 +1,167 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/service/notion"
+)
+
+const (
+	runbookDBID = "11111111-2222-3333-4444-555555555555"
+	otherDBID   = "99999999-2222-3333-4444-555555555555"
+)
+
+func pageJSON(id, databaseID, title, status string) string {
+	return `{
+		"object": "page",
+		"id": "` + id + `",
+		"url": "https://www.notion.so/` + id + `",
+		"last_edited_time": "2025-01-15T12:00:00.000Z",
+		"parent": {"type": "database_id", "database_id": "` + databaseID + `"},
+		"properties": {
+			"Name": {"type": "title", "title": [{"plain_text": "` + title + `"}]},
+			"Status": {"type": "status", "status": {"name": "` + status + `"}}
+		}
+	}`
+}
+
+func newTestNotionServer(t *testing.T, requests *[]map[string]any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer notion-token")
+		gt.V(t, r.Header.Get("Notion-Version")).NotEqual("")
+
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			gt.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			*requests = append(*requests, body)
+		}
+
+		switch {
+		case r.URL.Path == "/search":
+			_, _ = w.Write([]byte(`{"results": [` +
+				pageJSON("page-1", runbookDBID, "DB failover runbook", "Published") + `,` +
+				pageJSON("page-x", otherDBID, "Secret plan", "Draft") +
+				`], "has_more": false}`))
+		case strings.HasPrefix(r.URL.Path, "/databases/"):
+			_, _ = w.Write([]byte(`{"results": [` + pageJSON("page-1", runbookDBID, "DB failover runbook", "Published") + `], "has_more": false}`))
+		case r.URL.Path == "/pages/page-1":
+			_, _ = w.Write([]byte(pageJSON("page-1", runbookDBID, "DB failover runbook", "Published")))
+		case r.URL.Path == "/pages/page-x":
+			_, _ = w.Write([]byte(pageJSON("page-x", otherDBID, "Secret plan", "Draft")))
+		case r.URL.Path == "/blocks/page-1/children":
+			_, _ = w.Write([]byte(`{"results": [
+				{"id": "b1", "type": "heading_2", "heading_2": {"rich_text": [{"plain_text": "Steps"}]}},
+				{"id": "b2", "type": "numbered_list_item", "numbered_list_item": {"rich_text": [{"plain_text": "Promote replica"}]}},
+				{"id": "b3", "type": "numbered_list_item", "has_children": true, "numbered_list_item": {"rich_text": [{"plain_text": "Update DNS"}]}},
+				{"id": "b4", "type": "code", "code": {"language": "bash", "rich_text": [{"plain_text": "make failover"}]}}
+			], "has_more": false}`))
+		case r.URL.Path == "/blocks/b3/children":
+			_, _ = w.Write([]byte(`{"results": [
+				{"id": "b5", "type": "to_do", "to_do": {"checked": true, "rich_text": [{"plain_text": "Check TTL"}]}}
+			], "has_more": false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newTestConfigs() []*agent.NotionSearchConfig {
+	description := "Operation runbooks"
+	return []*agent.NotionSearchConfig{
+		agent.NewNotionSearchConfig("agent-uuid", strings.ReplaceAll(runbookDBID, "-", ""), "Runbooks", "ws-1", &description, true),
+	}
+}
+
+func TestSearchTool_Spec(t *testing.T) {
+	tool := notion.NewSearchTool(notion.NewClient("token"), newTestConfigs())
+
+	spec := tool.Spec()
+	gt.Equal(t, spec.Name, notion.SearchToolName)
+	gt.S(t, spec.Description).Contains("Runbooks")
+	gt.S(t, spec.Description).Contains("Operation runbooks")
+	gt.M(t, spec.Parameters).HasKey("keyword")
+	gt.M(t, spec.Parameters).HasKey("database_id")
+	gt.M(t, spec.Parameters).HasKey("filter")
+}
+
+func TestSearchTool_Run(t *testing.T) {
+	ctx := context.Background()
+	var requests []map[string]any
+	server := newTestNotionServer(t, &requests)
+	defer server.Close()
+
+	client := notion.NewClient("notion-token", notion.WithAPIBaseURL(server.URL))
+	tool := notion.NewSearchTool(client, newTestConfigs())
+
+	t.Run("keyword search returns pages only in configured databases", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"keyword": "failover"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 1)
+
+		pages := result["pages"].([]map[string]any)
+		gt.Equal(t, pages[0]["title"], "DB failover runbook")
+		gt.Equal(t, pages[0]["url"], "https://www.notion.so/page-1")
+		gt.Equal(t, pages[0]["database"], "Runbooks")
+		gt.S(t, pages[0]["snippet"].(string)).Contains("Status: Published")
+	})
+
+	t.Run("query database with filter", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{
+			"database_id": runbookDBID,
+			"filter":      `{"property":"Status","status":{"equals":"Published"}}`,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 1)
+
+		filter := requests[len(requests)-1]["filter"].(map[string]any)
+		gt.Equal(t, filter["property"], "Status")
+	})
+
+	t.Run("rejects database not configured", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"database_id": otherDBID})
+		gt.Error(t, err)
+	})
+
+	t.Run("rejects invalid filter", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"database_id": runbookDBID, "filter": "status is done"})
+		gt.Error(t, err)
+	})
+}
+
+func TestPageTool_Run(t *testing.T) {
+	ctx := context.Background()
+	var requests []map[string]any
+	server := newTestNotionServer(t, &requests)
+	defer server.Close()
+
+	client := notion.NewClient("notion-token", notion.WithAPIBaseURL(server.URL))
+	tool := notion.NewPageTool(client, newTestConfigs())
+
+	t.Run("returns page content as markdown", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"page_id": "page-1"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["title"], "DB failover runbook")
+		gt.Equal(t, result["content"],
+			"## Steps\n1. Promote replica\n2. Update DNS\n  - [x] Check TTL\n```bash\nmake failover\n```")
+	})
+
+	t.Run("returns page content as plain text", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"page_id": "page-1", "format": "text"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["content"],
+			"Steps\n1. Promote replica\n2. Update DNS\n  - [x] Check TTL\nmake failover")
+	})
+
+	t.Run("rejects page outside configured databases", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"page_id": "page-x"})
+		gt.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package slack_test
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"sync"
 	"testing"
@@ -29,6 +30,26 @@ func (m *mockSlackClientForCache) PostMessageWithOptions(ctx context.Context, ch
 	return nil
 }
 
+func (m *mockSlackClientForCache) PostMessageForUpdate(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+	return "", nil
+}
+
+func (m *mockSlackClientForCache) UpdateMessage(ctx context.Context, channelID, timestamp, text string) error {
+	return nil
+}
+
+func (m *mockSlackClientForCache) DeleteMessage(ctx context.Context, channelID, timestamp string) error {
+	return nil
+}
+
+func (m *mockSlackClientForCache) DownloadFile(ctx context.Context, downloadURL string, w io.Writer) error {
+	return nil
+}
+
+func (m *mockSlackClientForCache) GetThreadReplies(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+	return nil, nil
+}
+
 func (m *mockSlackClientForCache) IsBotUser(userID string) bool {
 	return false
 }
@@ -0,0 +1,197 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/utils/toolarg"
+)
+
+const (
+	// defaultSearchLimit is the number of messages returned when the LLM does not specify a limit
+	defaultSearchLimit = 20
+	// maxSearchLimit caps the number of messages returned by a single tool call
+	maxSearchLimit = 100
+	// searchPageSize is the number of logs fetched from the repository per page
+	searchPageSize = 200
+	// maxSearchScan caps the number of logs scanned for keyword matches per tool call
+	maxSearchScan = 2000
+	// maxToolNameLength is the maximum tool name length accepted by LLM providers
+	maxToolNameLength = 64
+)
+
+var toolNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// SearchTool is a gollem tool that searches logged Slack messages of a channel
+// configured by agent.SlackSearchConfig
+type SearchTool struct {
+	repo         interfaces.SlackMessageLogRepository
+	config       *agent.SlackSearchConfig
+	workspaceURL string
+}
+
+// NewSearchTool creates a new Slack history search tool for the given configuration
+func NewSearchTool(repo interfaces.SlackMessageLogRepository, config *agent.SlackSearchConfig, workspaceURL string) *SearchTool {
+	return &SearchTool{
+		repo:         repo,
+		config:       config,
+		workspaceURL: workspaceURL,
+	}
+}
+
+// Ensure SearchTool implements gollem.Tool interface
+var _ gollem.Tool = (*SearchTool)(nil)
+
+// Spec returns the tool specification
+func (t *SearchTool) Spec() gollem.ToolSpec {
+	description := fmt.Sprintf("Search message history of Slack channel #%s. "+
+		"Returns matching messages with author, time and permalink, newest first.", t.config.ChannelName)
+	if t.config.Description != nil && *t.config.Description != "" {
+		description += " Channel description: " + *t.config.Description
+	}
+
+	return gollem.ToolSpec{
+		Name:        t.Name(),
+		Description: description,
+		Parameters: map[string]*gollem.Parameter{
+			"keyword": {
+				Type:        gollem.TypeString,
+				Description: "Space separated keywords. All keywords must appear in a message (case-insensitive). Leave empty to list recent messages.",
+			},
+			"from": {
+				Type:        gollem.TypeString,
+				Description: "Start of the time range in RFC3339 (2006-01-02T15:04:05Z07:00) or date (2006-01-02) format",
+			},
+			"to": {
+				Type:        gollem.TypeString,
+				Description: "End of the time range in RFC3339 (2006-01-02T15:04:05Z07:00) or date (2006-01-02) format",
+			},
+			"limit": {
+				Type:        gollem.TypeInteger,
+				Description: fmt.Sprintf("Maximum number of messages to return (default %d, max %d)", defaultSearchLimit, maxSearchLimit),
+			},
+		},
+	}
+}
+
+// Name returns the tool name derived from the channel ID. The channel name is not used because
+// names of different channels can be the same after invalid characters (e.g. non-ASCII ones)
+// are replaced, and LLM providers reject duplicated tool names.
+func (t *SearchTool) Name() string {
+	name := "search_slack_" + toolNameInvalidChars.ReplaceAllString(t.config.ChannelID, "_")
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+// Run searches the channel history with the given arguments
+func (t *SearchTool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	keywords := strings.Fields(strings.ToLower(toolarg.String(args, "keyword")))
+
+	from, err := timeArg(args, "from", false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := timeArg(args, "to", true)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := toolarg.Int(args, "limit", defaultSearchLimit)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	var matched []*slack.SlackMessageLog
+	for offset := 0; offset < maxSearchScan && len(matched) < limit; offset += searchPageSize {
+		logs, err := t.repo.GetSlackMessageLogs(ctx, t.config.ChannelID, from, to, searchPageSize, offset)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get slack message logs",
+				goerr.V("channel_id", t.config.ChannelID))
+		}
+
+		for _, log := range logs {
+			if matchKeywords(log.Text, keywords) {
+				matched = append(matched, log)
+				if len(matched) >= limit {
+					break
+				}
+			}
+		}
+
+		if len(logs) < searchPageSize {
+			break
+		}
+	}
+
+	messages := make([]map[string]any, 0, len(matched))
+	for _, log := range matched {
+		author := log.UserName
+		if author == "" {
+			author = log.UserID
+		}
+		if author == "" {
+			author = log.BotID
+		}
+
+		messages = append(messages, map[string]any{
+			"author":    author,
+			"text":      log.Text,
+			"time":      log.CreatedAt.Format(time.RFC3339),
+			"thread_ts": log.ThreadTS,
+			"permalink": log.Permalink(t.workspaceURL),
+		})
+	}
+
+	return map[string]any{
+		"channel":  "#" + t.config.ChannelName,
+		"count":    len(messages),
+		"messages": messages,
+	}, nil
+}
+
+// matchKeywords reports whether text contains all keywords (already lowercased)
+func matchKeywords(text string, keywords []string) bool {
+	lower := strings.ToLower(text)
+	for _, kw := range keywords {
+		if !strings.Contains(lower, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// timeArg parses a time argument in RFC3339 or date format. If endOfDay is true,
+// a date-only value is interpreted as the end of that day.
+func timeArg(args map[string]any, key string, endOfDay bool) (*time.Time, error) {
+	v := toolarg.String(args, key)
+	if v == "" {
+		return nil, nil
+	}
+
+	if ts, err := time.Parse(time.RFC3339, v); err == nil {
+		return &ts, nil
+	}
+
+	ts, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid time format, use RFC3339 or YYYY-MM-DD",
+			goerr.V("key", key), goerr.V("value", v))
+	}
+	if endOfDay {
+		ts = ts.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &ts, nil
+}
@@ -0,0 +1,118 @@
+package slack_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	slackservice "github.com/m-mizutani/tamamo/pkg/service/slack"
+)
+
+func putTestLog(t *testing.T, repo *memory.Client, channelID, text string, createdAt time.Time) {
+	t.Helper()
+	gt.NoError(t, repo.PutSlackMessageLog(context.Background(), &slack.SlackMessageLog{
+		ID:          types.NewMessageID(context.Background()),
+		ChannelID:   channelID,
+		ChannelName: "sre",
+		UserID:      "U123",
+		UserName:    "alice",
+		MessageType: slack.MessageTypeUser,
+		Text:        text,
+		Timestamp:   "1700000000.000100",
+		CreatedAt:   createdAt,
+	}))
+}
+
+func TestSearchTool_Spec(t *testing.T) {
+	description := "SRE team discussions"
+	config := agent.NewSlackSearchConfig(types.NewUUID(context.Background()).String(), "C123", "sre team", &description, true)
+	tool := slackservice.NewSearchTool(memory.New(), config, "")
+
+	spec := tool.Spec()
+	gt.Equal(t, spec.Name, "search_slack_C123")
+	gt.S(t, spec.Description).Contains("#sre team")
+	gt.S(t, spec.Description).Contains("SRE team discussions")
+	gt.M(t, spec.Parameters).HasKey("keyword")
+	gt.M(t, spec.Parameters).HasKey("from")
+	gt.M(t, spec.Parameters).HasKey("to")
+	gt.M(t, spec.Parameters).HasKey("limit")
+}
+
+func TestSearchTool_NameOfNonASCIIChannels(t *testing.T) {
+	ctx := context.Background()
+	agentID := types.NewUUID(ctx).String()
+
+	// Both names consist of characters invalid for tool names
+	incident := slackservice.NewSearchTool(memory.New(), agent.NewSlackSearchConfig(agentID, "C001", "障害対応", nil, true), "")
+	release := slackservice.NewSearchTool(memory.New(), agent.NewSlackSearchConfig(agentID, "C002", "リリース", nil, true), "")
+
+	gt.Equal(t, incident.Spec().Name, "search_slack_C001")
+	gt.Equal(t, release.Spec().Name, "search_slack_C002")
+	gt.S(t, incident.Spec().Description).Contains("#障害対応")
+}
+
+func TestSearchTool_Run(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	putTestLog(t, repo, "C123", "We decided to migrate the database next week", now.Add(-2*time.Hour))
+	putTestLog(t, repo, "C123", "Lunch is ready", now.Add(-1*time.Hour))
+	putTestLog(t, repo, "C123", "Database migration postponed", now.Add(-10*24*time.Hour))
+	putTestLog(t, repo, "C999", "Database in another channel", now)
+
+	config := agent.NewSlackSearchConfig(types.NewUUID(ctx).String(), "C123", "sre", nil, true)
+	tool := slackservice.NewSearchTool(repo, config, "https://example.slack.com/")
+
+	t.Run("filters by keyword within configured channel", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"keyword": "database"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 2)
+
+		messages := result["messages"].([]map[string]any)
+		gt.A(t, messages).Length(2)
+		gt.S(t, messages[0]["text"].(string)).Contains("migrate the database")
+		gt.Equal(t, messages[0]["author"], "alice")
+		gt.S(t, messages[0]["permalink"].(string)).Contains("https://example.slack.com/archives/C123/p")
+	})
+
+	t.Run("requires all keywords", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"keyword": "database postponed"})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 1)
+	})
+
+	t.Run("filters by time range", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{
+			"keyword": "database",
+			"from":    now.Add(-24 * time.Hour).Format(time.RFC3339),
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 1)
+	})
+
+	t.Run("accepts date only format", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{
+			"from": "2025-01-15",
+			"to":   "2025-01-15",
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 2)
+	})
+
+	t.Run("applies limit", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"limit": float64(1)})
+		gt.NoError(t, err)
+		gt.Equal(t, result["count"], 1)
+	})
+
+	t.Run("rejects invalid time format", func(t *testing.T) {
+		_, err := tool.Run(ctx, map[string]any{"from": "last week"})
+		gt.Error(t, err)
+	})
+}
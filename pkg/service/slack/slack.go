@@ -2,6 +2,7 @@ package slack
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/m-mizutani/ctxlog"
@@ -108,6 +109,17 @@ func (s *Service) PostMessage(ctx context.Context, channelID, threadTS, text str
 
 // PostMessageWithOptions posts a message to a Slack channel/thread with custom display options
 func (s *Service) PostMessageWithOptions(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) error {
+	_, err := s.postMessageWithOptions(ctx, channelID, threadTS, text, options)
+	return err
+}
+
+// PostMessageForUpdate posts a message with custom display options and returns its timestamp
+// so that the message can be updated or deleted later
+func (s *Service) PostMessageForUpdate(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+	return s.postMessageWithOptions(ctx, channelID, threadTS, text, options)
+}
+
+func (s *Service) postMessageWithOptions(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
 	msgOptions := []api.MsgOption{
 		api.MsgOptionText(text, false),
 		api.MsgOptionAsUser(false), // Explicitly send as bot, not as user
@@ -144,7 +156,7 @@ func (s *Service) PostMessageWithOptions(ctx context.Context, channelID, threadT
 		msgOptions...,
 	)
 	if err != nil {
-		return goerr.Wrap(err, "failed to post message to slack", goerr.V("channel", channelID), goerr.V("thread", threadTS))
+		return "", goerr.Wrap(err, "failed to post message to slack", goerr.V("channel", channelID), goerr.V("thread", threadTS))
 	}
 
 	logger := ctxlog.From(ctx)
@@ -166,9 +178,68 @@ func (s *Service) PostMessageWithOptions(ctx context.Context, channelID, threadT
 
 	logger.Debug("posted message to slack with options", logFields...)
 
+	return timestamp, nil
+}
+
+// UpdateMessage replaces text of a message posted by the bot
+func (s *Service) UpdateMessage(ctx context.Context, channelID, timestamp, text string) error {
+	_, _, _, err := s.client.UpdateMessageContext(ctx, channelID, timestamp, api.MsgOptionText(text, false))
+	if err != nil {
+		return goerr.Wrap(err, "failed to update message in slack", goerr.V("channel", channelID), goerr.V("timestamp", timestamp))
+	}
+	return nil
+}
+
+// DeleteMessage deletes a message posted by the bot
+func (s *Service) DeleteMessage(ctx context.Context, channelID, timestamp string) error {
+	_, _, err := s.client.DeleteMessageContext(ctx, channelID, timestamp)
+	if err != nil {
+		return goerr.Wrap(err, "failed to delete message in slack", goerr.V("channel", channelID), goerr.V("timestamp", timestamp))
+	}
+	return nil
+}
+
+// DownloadFile downloads a file attached to a message with the bot token
+func (s *Service) DownloadFile(ctx context.Context, downloadURL string, w io.Writer) error {
+	if err := s.client.GetFileContext(ctx, downloadURL, w); err != nil {
+		return goerr.Wrap(err, "failed to download file from slack", goerr.V("url", downloadURL))
+	}
 	return nil
 }
 
+// GetThreadReplies retrieves messages of the thread with conversations.replies API
+func (s *Service) GetThreadReplies(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+	var messages []*slack.Message
+	cursor := ""
+	for len(messages) < limit {
+		replies, hasMore, nextCursor, err := s.client.GetConversationRepliesContext(ctx, &api.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: threadTS,
+			Cursor:    cursor,
+			Limit:     min(limit-len(messages), 200),
+		})
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get thread replies from Slack",
+				goerr.V("channel_id", channelID),
+				goerr.V("thread_ts", threadTS))
+		}
+
+		for _, reply := range replies {
+			messages = append(messages, slack.NewReplyMessage(ctx, s.authTestInfo.TeamID, channelID, reply))
+		}
+
+		if !hasMore || nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
 // IsBotUser checks if the given user ID is the bot user
 func (s *Service) IsBotUser(userID string) bool {
 	return s.botUserID == userID
@@ -186,6 +257,7 @@ func (s *Service) GetUserProfile(ctx context.Context, userID string) (*interface
 		Name:        user.Name,
 		DisplayName: user.Profile.DisplayName,
 		Email:       user.Profile.Email,
+		TimeZone:    user.TZ,
 		Profile: struct {
 			Image24   string `json:"image_24"`
 			Image32   string `json:"image_32"`
@@ -18,13 +18,29 @@ import (
 
 type agentUseCaseImpl struct {
 	agentRepo interfaces.AgentRepository
+	mcpPolicy agent.MCPPolicy
+}
+
+// AgentUseCaseOption is a functional option for agent use cases
+type AgentUseCaseOption func(*agentUseCaseImpl)
+
+// WithMCPPolicy sets the operator policy of MCP servers of agent versions. Versions with stdio
+// MCP servers or HTTP MCP servers in private networks are rejected without it.
+func WithMCPPolicy(policy agent.MCPPolicy) AgentUseCaseOption {
+	return func(u *agentUseCaseImpl) {
+		u.mcpPolicy = policy
+	}
 }
 
 // NewAgentUseCases creates a new agent use case implementation
-func NewAgentUseCases(agentRepo interfaces.AgentRepository) interfaces.AgentUseCases {
-	return &agentUseCaseImpl{
+func NewAgentUseCases(agentRepo interfaces.AgentRepository, opts ...AgentUseCaseOption) interfaces.AgentUseCases {
+	u := &agentUseCaseImpl{
 		agentRepo: agentRepo,
 	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 
 // CreateAgent creates a new agent with its initial version
@@ -107,12 +123,15 @@ func (u *agentUseCaseImpl) CreateAgent(ctx context.Context, req *interfaces.Crea
 		SystemPrompt: systemPrompt,
 		LLMProvider:  req.LLMProvider,
 		LLMModel:     req.LLMModel,
+		Status:       agent.VersionStatusPublished,
+		Author:       author,
+		PublishedAt:  &now,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
 
 	// Validate the agent version
-	if err := agent.ValidateAgentVersion(agentVersion); err != nil {
+	if err := agent.ValidateAgentVersion(agentVersion, u.validateOptions()...); err != nil {
 		return nil, goerr.Wrap(err, "agent version validation failed")
 	}
 
@@ -208,10 +227,14 @@ func (u *agentUseCaseImpl) UpdateAgent(ctx context.Context, id types.UUID, req *
 
 		// Create new version with updated fields
 		newVersionReq := &interfaces.CreateVersionRequest{
-			AgentUUID:   id,
-			Version:     incrementVersion(latestVersion.Version), // Simple increment
-			LLMProvider: latestVersion.LLMProvider,
-			LLMModel:    latestVersion.LLMModel,
+			AgentUUID:       id,
+			Version:         incrementVersion(latestVersion.Version), // Simple increment
+			PromptTemplate:  latestVersion.PromptTemplate,
+			PromptVariables: latestVersion.PromptVariables,
+			LLMProvider:     latestVersion.LLMProvider,
+			LLMModel:        latestVersion.LLMModel,
+			MCPServers:      latestVersion.MCPServers,
+			Delegation:      latestVersion.Delegation,
 		}
 
 		// Use existing system prompt by default
@@ -367,18 +390,36 @@ func (u *agentUseCaseImpl) CreateAgentVersion(ctx context.Context, req *interfac
 		systemPrompt = *req.SystemPrompt
 	}
 
+	// Get author from authentication context
+	author := types.AnonymousUserID
+	if session, ok := auth_controller.UserFromContext(ctx); ok && session != nil {
+		author = session.UserID
+	}
+
 	agentVersion := &agent.AgentVersion{
-		AgentUUID:    req.AgentUUID,
-		Version:      req.Version,
-		SystemPrompt: systemPrompt,
-		LLMProvider:  req.LLMProvider,
-		LLMModel:     req.LLMModel,
-		CreatedAt:    now,
-		UpdatedAt:    now,
+		AgentUUID:       req.AgentUUID,
+		Version:         req.Version,
+		SystemPrompt:    systemPrompt,
+		PromptTemplate:  req.PromptTemplate,
+		PromptVariables: req.PromptVariables,
+		LLMProvider:     req.LLMProvider,
+		LLMModel:        req.LLMModel,
+		MCPServers:      req.MCPServers,
+		Delegation:      req.Delegation,
+		Status:          agent.VersionStatusPublished,
+		Author:          author,
+		PublishedAt:     &now,
+		Changelog:       req.Changelog,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if req.Draft {
+		agentVersion.Status = agent.VersionStatusDraft
+		agentVersion.PublishedAt = nil
 	}
 
 	// Validate the agent version
-	if err := agent.ValidateAgentVersion(agentVersion); err != nil {
+	if err := agent.ValidateAgentVersion(agentVersion, u.validateOptions()...); err != nil {
 		return nil, goerr.Wrap(err, "agent version validation failed")
 	}
 
@@ -387,6 +428,11 @@ func (u *agentUseCaseImpl) CreateAgentVersion(ctx context.Context, req *interfac
 		return nil, goerr.Wrap(err, "failed to create agent version")
 	}
 
+	// Drafts go live only when they are published
+	if agentVersion.IsDraft() {
+		return agentVersion, nil
+	}
+
 	// Update agent's latest version if this is a newer version
 	// For simplicity, we'll just update the latest field
 	// In a real implementation, you might want to use semantic version comparison
@@ -425,6 +471,141 @@ func (u *agentUseCaseImpl) GetAgentVersions(ctx context.Context, agentUUID types
 	return versions, nil
 }
 
+// SetMCPServer creates a new version from the agent version with the MCP server added, or with the
+// server of the same name replaced. Env and Headers of the existing server are kept if they are nil
+// in the given server because they may contain secrets that are not exposed to clients.
+func (u *agentUseCaseImpl) SetMCPServer(ctx context.Context, agentUUID types.UUID, version string, server *agent.MCPServer) (*agent.AgentVersion, error) {
+	if server == nil {
+		return nil, goerr.New("MCP server cannot be nil")
+	}
+	if err := server.Validate(u.validateOptions()...); err != nil {
+		return nil, goerr.Wrap(err, "invalid MCP server")
+	}
+
+	agentVersion, err := u.agentRepo.GetAgentVersion(ctx, agentUUID, version)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+
+	// Build a new slice not to modify the one shared with the repository
+	servers := make([]*agent.MCPServer, 0, len(agentVersion.MCPServers)+1)
+	replaced := false
+	for _, existing := range agentVersion.MCPServers {
+		if existing.Name == server.Name {
+			if server.Env == nil {
+				server.Env = existing.Env
+			}
+			if server.Headers == nil {
+				server.Headers = existing.Headers
+			}
+			servers = append(servers, server)
+			replaced = true
+			continue
+		}
+		servers = append(servers, existing)
+	}
+	if !replaced {
+		servers = append(servers, server)
+	}
+
+	return u.deriveAgentVersion(ctx, agentVersion, func(req *interfaces.CreateVersionRequest) {
+		req.MCPServers = servers
+		req.Changelog = fmt.Sprintf("Set MCP server %s", server.Name)
+	})
+}
+
+// DeleteMCPServer creates a new version from the agent version without the MCP server
+func (u *agentUseCaseImpl) DeleteMCPServer(ctx context.Context, agentUUID types.UUID, version string, name string) (*agent.AgentVersion, error) {
+	agentVersion, err := u.agentRepo.GetAgentVersion(ctx, agentUUID, version)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+
+	servers := make([]*agent.MCPServer, 0, len(agentVersion.MCPServers))
+	for _, existing := range agentVersion.MCPServers {
+		if existing.Name != name {
+			servers = append(servers, existing)
+		}
+	}
+	if len(servers) == len(agentVersion.MCPServers) {
+		return nil, goerr.New("MCP server not found",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.V("name", name))
+	}
+
+	return u.deriveAgentVersion(ctx, agentVersion, func(req *interfaces.CreateVersionRequest) {
+		req.MCPServers = servers
+		req.Changelog = fmt.Sprintf("Delete MCP server %s", name)
+	})
+}
+
+// SetDelegation creates a new version from the agent version with the delegation configuration
+func (u *agentUseCaseImpl) SetDelegation(ctx context.Context, agentUUID types.UUID, version string, delegation *agent.Delegation) (*agent.AgentVersion, error) {
+	if delegation == nil {
+		return nil, goerr.New("delegation cannot be nil")
+	}
+	if err := delegation.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid delegation")
+	}
+
+	agentVersion, err := u.agentRepo.GetAgentVersion(ctx, agentUUID, version)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+
+	return u.deriveAgentVersion(ctx, agentVersion, func(req *interfaces.CreateVersionRequest) {
+		req.Delegation = delegation
+		req.Changelog = "Set delegation"
+	})
+}
+
+// deriveAgentVersion creates a new version from the base version changed by update. Versions are
+// not modified once created so that threads, rollouts and diffs keep referring to what actually
+// ran. The new version is numbered next to the latest one, skipping numbers taken by drafts, and
+// becomes the latest unless the base version is a draft.
+func (u *agentUseCaseImpl) deriveAgentVersion(ctx context.Context, base *agent.AgentVersion, update func(req *interfaces.CreateVersionRequest)) (*agent.AgentVersion, error) {
+	latest, err := u.agentRepo.GetLatestAgentVersion(ctx, base.AgentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get latest agent version", goerr.TV(apperr.AgentUUIDKey, base.AgentUUID))
+	}
+
+	version := incrementVersion(latest.Version)
+	for {
+		if _, err := u.agentRepo.GetAgentVersion(ctx, base.AgentUUID, version); err != nil {
+			break
+		}
+		version = incrementVersion(version)
+	}
+
+	systemPrompt := base.SystemPrompt
+	req := &interfaces.CreateVersionRequest{
+		AgentUUID:       base.AgentUUID,
+		Version:         version,
+		SystemPrompt:    &systemPrompt,
+		PromptTemplate:  base.PromptTemplate,
+		PromptVariables: base.PromptVariables,
+		LLMProvider:     base.LLMProvider,
+		LLMModel:        base.LLMModel,
+		MCPServers:      base.MCPServers,
+		Delegation:      base.Delegation,
+		Draft:           base.IsDraft(),
+	}
+	update(req)
+
+	return u.CreateAgentVersion(ctx, req)
+}
+
+func (u *agentUseCaseImpl) validateOptions() []agent.ValidateOption {
+	return []agent.ValidateOption{agent.WithMCPPolicy(u.mcpPolicy)}
+}
+
 // CheckAgentIDAvailability checks if an agent ID is available
 func (u *agentUseCaseImpl) CheckAgentIDAvailability(ctx context.Context, agentID string) (*interfaces.AgentIDAvailability, error) {
 	// Validate format first
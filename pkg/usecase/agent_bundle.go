@@ -0,0 +1,653 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	auth_controller "github.com/m-mizutani/tamamo/pkg/controller/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	imageService "github.com/m-mizutani/tamamo/pkg/service/image"
+)
+
+// AgentBundle holds dependencies for exports and imports of agents
+type AgentBundle struct {
+	agentRepo        interfaces.AgentRepository
+	slackConfigRepo  interfaces.SlackSearchConfigRepository
+	jiraConfigRepo   interfaces.JiraSearchConfigRepository
+	notionConfigRepo interfaces.NotionSearchConfigRepository
+	imageRepo        interfaces.AgentImageRepository
+	imageProcessor   *imageService.Processor
+
+	mcpPolicy agent.MCPPolicy
+}
+
+// AgentBundleOption is a functional option for AgentBundle
+type AgentBundleOption func(*AgentBundle)
+
+// WithBundleAgentRepository sets the agent repository
+func WithBundleAgentRepository(repo interfaces.AgentRepository) AgentBundleOption {
+	return func(uc *AgentBundle) {
+		uc.agentRepo = repo
+	}
+}
+
+// WithBundleSearchConfigRepositories sets repositories of Slack, Jira and Notion search configs.
+// Search configs are not exported nor imported without them.
+func WithBundleSearchConfigRepositories(slackRepo interfaces.SlackSearchConfigRepository, jiraRepo interfaces.JiraSearchConfigRepository, notionRepo interfaces.NotionSearchConfigRepository) AgentBundleOption {
+	return func(uc *AgentBundle) {
+		uc.slackConfigRepo = slackRepo
+		uc.jiraConfigRepo = jiraRepo
+		uc.notionConfigRepo = notionRepo
+	}
+}
+
+// WithBundleImage sets the image repository and processor. Avatar images are not exported nor
+// imported without them.
+func WithBundleImage(repo interfaces.AgentImageRepository, processor *imageService.Processor) AgentBundleOption {
+	return func(uc *AgentBundle) {
+		uc.imageRepo = repo
+		uc.imageProcessor = processor
+	}
+}
+
+// WithBundleMCPPolicy sets the operator policy of MCP servers in bundles. Bundles with stdio MCP
+// servers or HTTP MCP servers in private networks are rejected without it.
+func WithBundleMCPPolicy(policy agent.MCPPolicy) AgentBundleOption {
+	return func(uc *AgentBundle) {
+		uc.mcpPolicy = policy
+	}
+}
+
+// NewAgentBundle creates a new AgentBundle instance
+func NewAgentBundle(opts ...AgentBundleOption) *AgentBundle {
+	uc := &AgentBundle{}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Ensure AgentBundle implements interfaces.AgentBundleUseCases
+var _ interfaces.AgentBundleUseCases = (*AgentBundle)(nil)
+
+// ExportAgent exports the agent, its versions, search configs and avatar image as a bundle.
+// Versions are ordered from the oldest so that the bundle diffs well in git.
+func (uc *AgentBundle) ExportAgent(ctx context.Context, req *interfaces.ExportAgentRequest) (*agent.Bundle, error) {
+	if req == nil {
+		return nil, goerr.New("export agent request cannot be nil", goerr.T(apperr.ErrTagValidation))
+	}
+
+	agentObj, err := uc.agentRepo.GetAgentByAgentID(ctx, req.AgentID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent", goerr.TV(apperr.AgentIDKey, req.AgentID), goerr.T(apperr.ErrTagAgentNotFound))
+	}
+
+	versions, err := uc.agentRepo.ListAgentVersions(ctx, agentObj.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list agent versions", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+	}
+	slices.Reverse(versions)
+
+	for _, v := range req.Versions {
+		if !slices.ContainsFunc(versions, func(av *agent.AgentVersion) bool { return av.Version == v }) {
+			return nil, goerr.New("version to export is not found",
+				goerr.TV(apperr.AgentIDKey, req.AgentID),
+				goerr.V("version", v),
+				goerr.T(apperr.ErrTagValidation))
+		}
+	}
+
+	bundle := &agent.Bundle{
+		APIVersion: agent.BundleAPIVersion,
+		Agent: agent.BundleAgent{
+			ID:          agentObj.AgentID,
+			Name:        agentObj.Name,
+			Description: agentObj.Description,
+		},
+	}
+	for _, v := range versions {
+		if len(req.Versions) > 0 && !slices.Contains(req.Versions, v.Version) {
+			continue
+		}
+		bundle.Versions = append(bundle.Versions, agent.NewBundleVersion(v, req.IncludeSecrets))
+
+		// The latest version of the agent is kept if it is exported. Otherwise, the newest
+		// published version in the bundle becomes the latest one.
+		if !v.IsDraft() && bundle.Agent.Latest != agentObj.Latest {
+			bundle.Agent.Latest = v.Version
+		}
+	}
+	if bundle.Agent.Latest == "" {
+		return nil, goerr.New("at least one published version is required to export",
+			goerr.TV(apperr.AgentIDKey, req.AgentID),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	if err := uc.exportSearchConfigs(ctx, agentObj, bundle); err != nil {
+		return nil, err
+	}
+
+	if agentObj.ImageID != nil && uc.imageRepo != nil && uc.imageProcessor != nil {
+		agentImage, err := uc.imageRepo.GetByID(ctx, *agentObj.ImageID)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get agent image", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		data, err := uc.imageProcessor.GetImageData(ctx, agentImage)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get agent image data", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		bundle.Image = agent.NewBundleImage(agentImage.ContentType, data)
+	}
+
+	return bundle, nil
+}
+
+func (uc *AgentBundle) exportSearchConfigs(ctx context.Context, agentObj *agent.Agent, bundle *agent.Bundle) error {
+	if uc.slackConfigRepo != nil {
+		configs, err := uc.slackConfigRepo.GetByAgentID(ctx, agentObj.ID.String())
+		if err != nil {
+			return goerr.Wrap(err, "failed to get slack search configs", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		for _, c := range configs {
+			bundle.SlackSearchConfigs = append(bundle.SlackSearchConfigs, &agent.BundleSlackSearchConfig{
+				ChannelID:   c.ChannelID,
+				ChannelName: c.ChannelName,
+				Description: c.Description,
+				Enabled:     c.Enabled,
+			})
+		}
+	}
+
+	if uc.jiraConfigRepo != nil {
+		configs, err := uc.jiraConfigRepo.GetByAgentID(ctx, agentObj.ID.String())
+		if err != nil {
+			return goerr.Wrap(err, "failed to get jira search configs", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		for _, c := range configs {
+			bundle.JiraSearchConfigs = append(bundle.JiraSearchConfigs, &agent.BundleJiraSearchConfig{
+				ProjectKey:  c.ProjectKey,
+				ProjectName: c.ProjectName,
+				BoardID:     c.BoardID,
+				BoardName:   c.BoardName,
+				Description: c.Description,
+				Enabled:     c.Enabled,
+			})
+		}
+	}
+
+	if uc.notionConfigRepo != nil {
+		configs, err := uc.notionConfigRepo.GetByAgentID(ctx, agentObj.ID.String())
+		if err != nil {
+			return goerr.Wrap(err, "failed to get notion search configs", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		for _, c := range configs {
+			bundle.NotionSearchConfigs = append(bundle.NotionSearchConfigs, &agent.BundleNotionSearchConfig{
+				DatabaseID:   c.DatabaseID,
+				DatabaseName: c.DatabaseName,
+				WorkspaceID:  c.WorkspaceID,
+				Description:  c.Description,
+				Enabled:      c.Enabled,
+			})
+		}
+	}
+
+	return nil
+}
+
+// ImportAgent creates the agent of the bundle. If the agent ID already exists, the bundle is
+// imported according to the conflict mode.
+func (uc *AgentBundle) ImportAgent(ctx context.Context, req *interfaces.ImportAgentRequest) (*interfaces.ImportAgentResult, error) {
+	if req == nil || req.Bundle == nil {
+		return nil, goerr.New("agent bundle is required", goerr.T(apperr.ErrTagValidation))
+	}
+	bundle := req.Bundle
+	if err := bundle.Validate(agent.WithMCPPolicy(uc.mcpPolicy)); err != nil {
+		return nil, goerr.Wrap(err, "invalid agent bundle", goerr.T(apperr.ErrTagValidation))
+	}
+
+	mode := req.OnConflict
+	if mode == "" {
+		mode = agent.ImportConflictSkip
+	}
+	if !mode.IsValid() {
+		return nil, goerr.New("invalid conflict mode", goerr.V("mode", mode), goerr.T(apperr.ErrTagValidation))
+	}
+
+	// The image is stored after the agent and versions, so it is checked before anything is written
+	if err := uc.validateImage(bundle); err != nil {
+		return nil, err
+	}
+
+	exists, err := uc.agentRepo.AgentIDExists(ctx, bundle.Agent.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to check agent ID existence", goerr.TV(apperr.AgentIDKey, bundle.Agent.ID))
+	}
+	if !exists {
+		return uc.createAgent(ctx, bundle)
+	}
+
+	existing, err := uc.agentRepo.GetAgentByAgentID(ctx, bundle.Agent.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent", goerr.TV(apperr.AgentIDKey, bundle.Agent.ID))
+	}
+
+	switch mode {
+	case agent.ImportConflictOverwrite:
+		return uc.overwriteAgent(ctx, existing, bundle)
+	case agent.ImportConflictNewVersion:
+		return uc.addVersion(ctx, existing, bundle)
+	default:
+		ctxlog.From(ctx).Info("skipped import of existing agent", "agent_id", existing.AgentID)
+		return &interfaces.ImportAgentResult{Agent: existing, Action: agent.ImportActionSkipped, Versions: []string{}}, nil
+	}
+}
+
+// createAgent creates the agent, its versions, search configs and image of the bundle. The
+// repositories have no transaction across them, so the agent and its search configs are deleted
+// if any of the writes fails.
+func (uc *AgentBundle) createAgent(ctx context.Context, bundle *agent.Bundle) (*interfaces.ImportAgentResult, error) {
+	now := time.Now()
+	author := importAuthor(ctx)
+	agentObj := &agent.Agent{
+		ID:          types.NewUUID(ctx),
+		AgentID:     bundle.Agent.ID,
+		Name:        bundle.Agent.Name,
+		Description: bundle.Agent.Description,
+		Author:      author,
+		Status:      agent.StatusActive,
+		Latest:      bundle.Agent.Latest,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.agentRepo.CreateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to create agent", goerr.TV(apperr.AgentIDKey, agentObj.AgentID))
+	}
+
+	result := &interfaces.ImportAgentResult{Agent: agentObj, Action: agent.ImportActionCreated}
+	if err := uc.populateAgent(ctx, result, bundle, author, now); err != nil {
+		uc.cleanUpAgent(ctx, agentObj)
+		return nil, err
+	}
+
+	ctxlog.From(ctx).Info("imported agent",
+		"agent_id", agentObj.AgentID,
+		"agent_uuid", agentObj.ID,
+		"versions", result.Versions,
+	)
+	return result, nil
+}
+
+// populateAgent stores the versions, search configs and image of the bundle to the created agent
+func (uc *AgentBundle) populateAgent(ctx context.Context, result *interfaces.ImportAgentResult, bundle *agent.Bundle, author types.UserID, now time.Time) error {
+	agentObj := result.Agent
+	for i, v := range bundle.Versions {
+		// Versions are listed by creation time, so keep the order of the bundle
+		createdAt := now.Add(time.Duration(i) * time.Millisecond)
+		if err := uc.agentRepo.CreateAgentVersion(ctx, v.ToAgentVersion(agentObj.ID, author, createdAt)); err != nil {
+			return goerr.Wrap(err, "failed to create agent version",
+				goerr.TV(apperr.AgentUUIDKey, agentObj.ID),
+				goerr.V("version", v.Version))
+		}
+		result.Versions = append(result.Versions, v.Version)
+	}
+
+	if err := uc.importSearchConfigs(ctx, agentObj, bundle); err != nil {
+		return err
+	}
+	return uc.importImage(ctx, result, bundle)
+}
+
+// cleanUpAgent deletes the agent that failed to be imported. It runs even if the import is
+// canceled, and failures are only logged because the import error is returned to the caller.
+func (uc *AgentBundle) cleanUpAgent(ctx context.Context, agentObj *agent.Agent) {
+	ctx = context.WithoutCancel(ctx)
+	logger := ctxlog.From(ctx)
+
+	if err := uc.deleteSearchConfigs(ctx, agentObj); err != nil {
+		logger.Error("failed to clean up search configs of agent", "error", err, "agent_uuid", agentObj.ID)
+	}
+	if err := uc.agentRepo.DeleteAgent(ctx, agentObj.ID); err != nil {
+		logger.Error("failed to clean up agent", "error", err, "agent_uuid", agentObj.ID)
+	}
+}
+
+// overwriteAgent replaces the agent with the bundle. Published versions are immutable because
+// threads and rollouts may use them, so a published version that differs from the bundle is kept
+// and the version of the bundle is added with the next free patch version. Drafts are replaced. A
+// running rollout is aborted because its versions may be replaced.
+func (uc *AgentBundle) overwriteAgent(ctx context.Context, agentObj *agent.Agent, bundle *agent.Bundle) (*interfaces.ImportAgentResult, error) {
+	now := time.Now()
+	author := importAuthor(ctx)
+	result := &interfaces.ImportAgentResult{Agent: agentObj, Action: agent.ImportActionOverwritten}
+
+	latest, err := uc.agentRepo.GetAgentVersion(ctx, agentObj.ID, agentObj.Latest)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get latest agent version", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+	}
+
+	latestVersion := bundle.Agent.Latest
+	for i, v := range bundle.Versions {
+		version := v.ToAgentVersion(agentObj.ID, author, now.Add(time.Duration(i)*time.Millisecond))
+		current, err := uc.findAgentVersion(ctx, agentObj.ID, v.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case current == nil:
+			restoreSecrets(version, latest)
+			if err := uc.createImportedVersion(ctx, version); err != nil {
+				return nil, err
+			}
+
+		case current.IsDraft():
+			restoreSecrets(version, current)
+			version.CreatedAt = current.CreatedAt
+			if err := uc.agentRepo.UpdateAgentVersion(ctx, version); err != nil {
+				return nil, goerr.Wrap(err, "failed to update agent version",
+					goerr.TV(apperr.AgentUUIDKey, agentObj.ID),
+					goerr.V("version", v.Version))
+			}
+
+		default:
+			restoreSecrets(version, current)
+			if !agent.DiffVersions(current, version).HasChanges() {
+				break
+			}
+			next, err := uc.nextFreeVersion(ctx, agentObj.ID, bundle, v.Version)
+			if err != nil {
+				return nil, err
+			}
+			ctxlog.From(ctx).Info("published version differs from bundle, adding it as new version",
+				"agent_uuid", agentObj.ID,
+				"version", v.Version,
+				"new_version", next,
+			)
+			version.Version = next
+			if err := uc.createImportedVersion(ctx, version); err != nil {
+				return nil, err
+			}
+			if v.Version == bundle.Agent.Latest {
+				latestVersion = next
+			}
+		}
+		result.Versions = append(result.Versions, version.Version)
+	}
+
+	agentObj.Name = bundle.Agent.Name
+	agentObj.Description = bundle.Agent.Description
+	agentObj.Latest = latestVersion
+	agentObj.Rollout = nil
+	agentObj.UpdatedAt = now
+	if err := uc.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to update agent", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+	}
+
+	if err := uc.deleteSearchConfigs(ctx, agentObj); err != nil {
+		return nil, err
+	}
+	if err := uc.importSearchConfigs(ctx, agentObj, bundle); err != nil {
+		return nil, err
+	}
+	if err := uc.importImage(ctx, result, bundle); err != nil {
+		return nil, err
+	}
+
+	ctxlog.From(ctx).Info("overwrote agent with bundle",
+		"agent_id", agentObj.AgentID,
+		"agent_uuid", agentObj.ID,
+		"versions", result.Versions,
+	)
+	return result, nil
+}
+
+// addVersion adds the latest version of the bundle to the agent as its new latest version. The
+// version is renumbered with the next patch version if it already exists.
+func (uc *AgentBundle) addVersion(ctx context.Context, agentObj *agent.Agent, bundle *agent.Bundle) (*interfaces.ImportAgentResult, error) {
+	now := time.Now()
+
+	latest, err := uc.agentRepo.GetAgentVersion(ctx, agentObj.ID, agentObj.Latest)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get latest agent version", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+	}
+
+	version := bundle.FindVersion(bundle.Agent.Latest).ToAgentVersion(agentObj.ID, importAuthor(ctx), now)
+	restoreSecrets(version, latest)
+	for {
+		current, err := uc.findAgentVersion(ctx, agentObj.ID, version.Version)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			break
+		}
+		next, err := nextPatchVersion(version.Version)
+		if err != nil {
+			return nil, err
+		}
+		version.Version = next
+	}
+
+	if err := uc.createImportedVersion(ctx, version); err != nil {
+		return nil, err
+	}
+
+	agentObj.Latest = version.Version
+	agentObj.UpdatedAt = now
+	if err := uc.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to update agent", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+	}
+
+	ctxlog.From(ctx).Info("added version of agent from bundle",
+		"agent_id", agentObj.AgentID,
+		"agent_uuid", agentObj.ID,
+		"version", version.Version,
+	)
+	return &interfaces.ImportAgentResult{
+		Agent:    agentObj,
+		Action:   agent.ImportActionVersionAdded,
+		Versions: []string{version.Version},
+	}, nil
+}
+
+func (uc *AgentBundle) createImportedVersion(ctx context.Context, version *agent.AgentVersion) error {
+	if err := uc.agentRepo.CreateAgentVersion(ctx, version); err != nil {
+		return goerr.Wrap(err, "failed to create agent version",
+			goerr.TV(apperr.AgentUUIDKey, version.AgentUUID),
+			goerr.V("version", version.Version))
+	}
+	return nil
+}
+
+// findAgentVersion returns the version of the agent, or nil if the version does not exist
+func (uc *AgentBundle) findAgentVersion(ctx context.Context, agentUUID types.UUID, version string) (*agent.AgentVersion, error) {
+	agentVersion, err := uc.agentRepo.GetAgentVersion(ctx, agentUUID, version)
+	if err != nil {
+		if errors.Is(err, agent.ErrAgentVersionNotFound) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to get agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+	return agentVersion, nil
+}
+
+// nextFreeVersion returns the first patch version after version that is used neither by the agent
+// nor by the bundle
+func (uc *AgentBundle) nextFreeVersion(ctx context.Context, agentUUID types.UUID, bundle *agent.Bundle, version string) (string, error) {
+	next := version
+	for {
+		var err error
+		if next, err = nextPatchVersion(next); err != nil {
+			return "", err
+		}
+		if bundle.FindVersion(next) != nil {
+			continue
+		}
+		current, err := uc.findAgentVersion(ctx, agentUUID, next)
+		if err != nil {
+			return "", err
+		}
+		if current == nil {
+			return next, nil
+		}
+	}
+}
+
+func (uc *AgentBundle) deleteSearchConfigs(ctx context.Context, agentObj *agent.Agent) error {
+	agentUUID := agentObj.ID.String()
+
+	if uc.slackConfigRepo != nil {
+		configs, err := uc.slackConfigRepo.GetByAgentID(ctx, agentUUID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to get slack search configs", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		for _, c := range configs {
+			if err := uc.slackConfigRepo.Delete(ctx, c.ID); err != nil {
+				return goerr.Wrap(err, "failed to delete slack search config", goerr.V("id", c.ID))
+			}
+		}
+	}
+
+	if uc.jiraConfigRepo != nil {
+		configs, err := uc.jiraConfigRepo.GetByAgentID(ctx, agentUUID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to get jira search configs", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		for _, c := range configs {
+			if err := uc.jiraConfigRepo.Delete(ctx, c.ID); err != nil {
+				return goerr.Wrap(err, "failed to delete jira search config", goerr.V("id", c.ID))
+			}
+		}
+	}
+
+	if uc.notionConfigRepo != nil {
+		configs, err := uc.notionConfigRepo.GetByAgentID(ctx, agentUUID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to get notion search configs", goerr.TV(apperr.AgentUUIDKey, agentObj.ID))
+		}
+		for _, c := range configs {
+			if err := uc.notionConfigRepo.Delete(ctx, c.ID); err != nil {
+				return goerr.Wrap(err, "failed to delete notion search config", goerr.V("id", c.ID))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (uc *AgentBundle) importSearchConfigs(ctx context.Context, agentObj *agent.Agent, bundle *agent.Bundle) error {
+	agentUUID := agentObj.ID.String()
+
+	if uc.slackConfigRepo != nil {
+		for _, c := range bundle.SlackSearchConfigs {
+			if err := uc.slackConfigRepo.Create(ctx, c.ToConfig(agentUUID)); err != nil {
+				return goerr.Wrap(err, "failed to create slack search config", goerr.V("channel_id", c.ChannelID))
+			}
+		}
+	}
+
+	if uc.jiraConfigRepo != nil {
+		for _, c := range bundle.JiraSearchConfigs {
+			if err := uc.jiraConfigRepo.Create(ctx, c.ToConfig(agentUUID)); err != nil {
+				return goerr.Wrap(err, "failed to create jira search config", goerr.V("project_key", c.ProjectKey))
+			}
+		}
+	}
+
+	if uc.notionConfigRepo != nil {
+		for _, c := range bundle.NotionSearchConfigs {
+			if err := uc.notionConfigRepo.Create(ctx, c.ToConfig(agentUUID)); err != nil {
+				return goerr.Wrap(err, "failed to create notion search config", goerr.V("database_id", c.DatabaseID))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateImage checks the image of the bundle with the rules of the image processor
+func (uc *AgentBundle) validateImage(bundle *agent.Bundle) error {
+	if bundle.Image == nil || uc.imageProcessor == nil {
+		return nil
+	}
+
+	data, err := bundle.Image.Decode()
+	if err != nil {
+		return goerr.Wrap(err, "invalid image in bundle", goerr.T(apperr.ErrTagValidation))
+	}
+	if err := uc.imageProcessor.Validate(bytes.NewReader(data), bundle.Image.ContentType, int64(len(data))); err != nil {
+		return goerr.Wrap(err, "invalid image in bundle", goerr.T(apperr.ErrTagValidation))
+	}
+	return nil
+}
+
+// importImage stores the avatar image of the bundle. The image processor updates the image ID of
+// the agent, so the agent in the result is replaced with the updated one.
+func (uc *AgentBundle) importImage(ctx context.Context, result *interfaces.ImportAgentResult, bundle *agent.Bundle) error {
+	if bundle.Image == nil || uc.imageProcessor == nil {
+		return nil
+	}
+
+	data, err := bundle.Image.Decode()
+	if err != nil {
+		return goerr.Wrap(err, "invalid image in bundle", goerr.T(apperr.ErrTagValidation))
+	}
+	agentImage, err := uc.imageProcessor.ProcessAndStore(ctx, result.Agent.ID, bytes.NewReader(data), bundle.Image.ContentType, int64(len(data)))
+	if err != nil {
+		return goerr.Wrap(err, "failed to store agent image", goerr.TV(apperr.AgentUUIDKey, result.Agent.ID))
+	}
+	result.Agent.ImageID = &agentImage.ID
+	return nil
+}
+
+// importAuthor returns the user who imports the bundle
+func importAuthor(ctx context.Context) types.UserID {
+	if session, ok := auth_controller.UserFromContext(ctx); ok && session != nil {
+		return session.UserID
+	}
+	return types.AnonymousUserID
+}
+
+// restoreSecrets fills empty values of env and headers of MCP servers with values of the same MCP
+// server of the existing version, because bundles are exported without secrets by default
+func restoreSecrets(version, existing *agent.AgentVersion) {
+	if existing == nil {
+		return
+	}
+	for _, server := range version.MCPServers {
+		idx := slices.IndexFunc(existing.MCPServers, func(s *agent.MCPServer) bool { return s.Name == server.Name })
+		if idx < 0 {
+			continue
+		}
+		restoreValues(server.Env, existing.MCPServers[idx].Env)
+		restoreValues(server.Headers, existing.MCPServers[idx].Headers)
+	}
+}
+
+func restoreValues(values, existing map[string]string) {
+	for key, value := range values {
+		if value == "" {
+			values[key] = existing[key]
+		}
+	}
+}
+
+// nextPatchVersion increments the patch number of the semantic version
+func nextPatchVersion(version string) (string, error) {
+	var major, minor, patch int
+	if _, err := fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch); err != nil {
+		return "", goerr.Wrap(err, "invalid version", goerr.V("version", version))
+	}
+	return fmt.Sprintf("%d.%d.%d", major, minor, patch+1), nil
+}
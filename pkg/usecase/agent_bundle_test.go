@@ -0,0 +1,323 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/m-mizutani/tamamo/pkg/utils/apperr"
+)
+
+type bundleTestEnv struct {
+	agentRepo  *memory.AgentMemoryClient
+	slackRepo  interfaces.SlackSearchConfigRepository
+	jiraRepo   interfaces.JiraSearchConfigRepository
+	notionRepo interfaces.NotionSearchConfigRepository
+	uc         *usecase.AgentBundle
+}
+
+func newBundleTestEnv() *bundleTestEnv {
+	env := &bundleTestEnv{
+		agentRepo:  memory.NewAgentMemoryClient(),
+		slackRepo:  memory.NewSlackSearchConfigRepository(),
+		jiraRepo:   memory.NewJiraSearchConfigRepository(),
+		notionRepo: memory.NewNotionSearchConfigRepository(),
+	}
+	env.uc = usecase.NewAgentBundle(
+		usecase.WithBundleAgentRepository(env.agentRepo),
+		usecase.WithBundleSearchConfigRepositories(env.slackRepo, env.jiraRepo, env.notionRepo),
+	)
+	return env
+}
+
+// setupBundleTestAgent creates an agent with a published version having a secret header, a
+// draft version and a slack search config
+func setupBundleTestAgent(t *testing.T, env *bundleTestEnv) *agent.Agent {
+	t.Helper()
+	ctx := context.Background()
+
+	agentObj := setupToolTestAgent(t, env.agentRepo, "sre-helper")
+	now := time.Now()
+	gt.NoError(t, env.agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.1.0",
+		SystemPrompt: "You are an SRE helper with GitHub.",
+		Status:       agent.VersionStatusPublished,
+		MCPServers: []*agent.MCPServer{
+			{
+				Name:      "github",
+				Transport: agent.MCPTransportHTTP,
+				URL:       "https://mcp.example.com/github",
+				Headers:   map[string]string{"Authorization": "Bearer secret"},
+			},
+		},
+		Changelog: "Add GitHub",
+		CreatedAt: now.Add(time.Second),
+	}))
+	gt.NoError(t, env.agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.2.0",
+		SystemPrompt: "You are a terse SRE helper.",
+		Status:       agent.VersionStatusDraft,
+		CreatedAt:    now.Add(2 * time.Second),
+	}))
+
+	agentObj.Latest = "1.1.0"
+	gt.NoError(t, env.agentRepo.UpdateAgent(ctx, agentObj))
+	gt.NoError(t, env.slackRepo.Create(ctx, agent.NewSlackSearchConfig(agentObj.ID.String(), "C12345", "incidents", nil, true)))
+
+	return agentObj
+}
+
+// failingSlackSearchConfigRepository fails to create configs to break an import in the middle
+type failingSlackSearchConfigRepository struct {
+	interfaces.SlackSearchConfigRepository
+}
+
+func (r *failingSlackSearchConfigRepository) Create(ctx context.Context, config *agent.SlackSearchConfig) error {
+	return errors.New("search config repository is unavailable")
+}
+
+func TestAgentBundleExport(t *testing.T) {
+	ctx := context.Background()
+	env := newBundleTestEnv()
+	setupBundleTestAgent(t, env)
+
+	t.Run("export all versions", func(t *testing.T) {
+		bundle, err := env.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{AgentID: "sre-helper"})
+		gt.NoError(t, err)
+		gt.NoError(t, bundle.Validate())
+		gt.Equal(t, bundle.APIVersion, agent.BundleAPIVersion)
+		gt.Equal(t, bundle.Agent.Latest, "1.1.0")
+
+		versions := make([]string, 0, len(bundle.Versions))
+		for _, v := range bundle.Versions {
+			versions = append(versions, v.Version)
+		}
+		gt.Equal(t, versions, []string{"1.0.0", "1.1.0", "1.2.0"})
+		gt.True(t, bundle.FindVersion("1.2.0").Draft)
+		gt.Equal(t, bundle.FindVersion("1.1.0").Changelog, "Add GitHub")
+
+		// Secrets are not exported by default
+		gt.Equal(t, bundle.FindVersion("1.1.0").MCPServers[0].Headers, map[string]string{"Authorization": ""})
+
+		gt.A(t, bundle.SlackSearchConfigs).Length(1)
+		gt.Equal(t, bundle.SlackSearchConfigs[0].ChannelID, "C12345")
+	})
+
+	t.Run("export with secrets", func(t *testing.T) {
+		bundle, err := env.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{AgentID: "sre-helper", IncludeSecrets: true})
+		gt.NoError(t, err)
+		gt.Equal(t, bundle.FindVersion("1.1.0").MCPServers[0].Headers, map[string]string{"Authorization": "Bearer secret"})
+	})
+
+	t.Run("export selected versions", func(t *testing.T) {
+		bundle, err := env.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{
+			AgentID:  "sre-helper",
+			Versions: []string{"1.0.0", "1.2.0"},
+		})
+		gt.NoError(t, err)
+		gt.A(t, bundle.Versions).Length(2)
+		// The latest version is not exported, so the newest published one becomes the latest
+		gt.Equal(t, bundle.Agent.Latest, "1.0.0")
+	})
+
+	t.Run("only drafts cannot be exported", func(t *testing.T) {
+		_, err := env.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{
+			AgentID:  "sre-helper",
+			Versions: []string{"1.2.0"},
+		})
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), 400)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := env.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{
+			AgentID:  "sre-helper",
+			Versions: []string{"9.9.9"},
+		})
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), 400)
+	})
+
+	t.Run("unknown agent", func(t *testing.T) {
+		_, err := env.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{AgentID: "unknown"})
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), 404)
+	})
+}
+
+func TestAgentBundleImport(t *testing.T) {
+	ctx := context.Background()
+	src := newBundleTestEnv()
+	setupBundleTestAgent(t, src)
+
+	bundle, err := src.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{AgentID: "sre-helper", IncludeSecrets: true})
+	gt.NoError(t, err)
+	data, err := bundle.Encode()
+	gt.NoError(t, err)
+	bundle, err = agent.ParseBundle(data)
+	gt.NoError(t, err)
+
+	t.Run("create agent in another workspace", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		result, err := dst.uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{Bundle: bundle})
+		gt.NoError(t, err)
+		gt.Equal(t, result.Action, agent.ImportActionCreated)
+		gt.Equal(t, result.Versions, []string{"1.0.0", "1.1.0", "1.2.0"})
+		gt.Equal(t, result.Agent.Latest, "1.1.0")
+		gt.Equal(t, result.Agent.Author, types.AnonymousUserID)
+
+		draft, err := dst.agentRepo.GetAgentVersion(ctx, result.Agent.ID, "1.2.0")
+		gt.NoError(t, err)
+		gt.True(t, draft.IsDraft())
+		gt.Nil(t, draft.PublishedAt)
+
+		configs, err := dst.slackRepo.GetByAgentID(ctx, result.Agent.ID.String())
+		gt.NoError(t, err)
+		gt.A(t, configs).Length(1)
+
+		// The imported agent is exported as the same bundle
+		exported, err := dst.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{AgentID: "sre-helper", IncludeSecrets: true})
+		gt.NoError(t, err)
+		gt.Equal(t, exported, bundle)
+	})
+
+	t.Run("skip existing agent", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		existing := setupToolTestAgent(t, dst.agentRepo, "sre-helper")
+
+		result, err := dst.uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+			Bundle:     bundle,
+			OnConflict: agent.ImportConflictSkip,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result.Action, agent.ImportActionSkipped)
+		gt.Equal(t, result.Agent.ID, existing.ID)
+
+		versions, err := dst.agentRepo.ListAgentVersions(ctx, existing.ID)
+		gt.NoError(t, err)
+		gt.A(t, versions).Length(1)
+	})
+
+	t.Run("overwrite existing agent", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		existing := setupBundleTestAgent(t, dst)
+		gt.NoError(t, dst.slackRepo.Create(ctx, agent.NewSlackSearchConfig(existing.ID.String(), "C99999", "random", nil, true)))
+
+		// Bundle without secrets, with a changed prompt
+		noSecrets, err := src.uc.ExportAgent(ctx, &interfaces.ExportAgentRequest{AgentID: "sre-helper"})
+		gt.NoError(t, err)
+		noSecrets.Agent.Name = "SRE Helper v2"
+		noSecrets.FindVersion("1.1.0").SystemPrompt = "You are an SRE helper with GitHub and PagerDuty."
+
+		noSecrets.FindVersion("1.2.0").SystemPrompt = "You are a terse SRE helper with PagerDuty."
+
+		result, err := dst.uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+			Bundle:     noSecrets,
+			OnConflict: agent.ImportConflictOverwrite,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result.Action, agent.ImportActionOverwritten)
+		gt.Equal(t, result.Agent.ID, existing.ID)
+		gt.Equal(t, result.Agent.Name, "SRE Helper v2")
+		// 1.0.0 is the same as the bundle, and 1.1.0 is published, so the bundle's one is added
+		gt.Equal(t, result.Versions, []string{"1.0.0", "1.1.1", "1.2.0"})
+		gt.Equal(t, result.Agent.Latest, "1.1.1")
+
+		published, err := dst.agentRepo.GetAgentVersion(ctx, existing.ID, "1.1.0")
+		gt.NoError(t, err)
+		gt.Equal(t, published.SystemPrompt, "You are an SRE helper with GitHub.")
+
+		version, err := dst.agentRepo.GetAgentVersion(ctx, existing.ID, "1.1.1")
+		gt.NoError(t, err)
+		gt.False(t, version.IsDraft())
+		gt.Equal(t, version.SystemPrompt, "You are an SRE helper with GitHub and PagerDuty.")
+		// Secrets are kept from the existing version
+		gt.Equal(t, version.MCPServers[0].Headers, map[string]string{"Authorization": "Bearer secret"})
+
+		// Drafts are replaced in place
+		draft, err := dst.agentRepo.GetAgentVersion(ctx, existing.ID, "1.2.0")
+		gt.NoError(t, err)
+		gt.True(t, draft.IsDraft())
+		gt.Equal(t, draft.SystemPrompt, "You are a terse SRE helper with PagerDuty.")
+
+		// Search configs are replaced with ones in the bundle
+		configs, err := dst.slackRepo.GetByAgentID(ctx, existing.ID.String())
+		gt.NoError(t, err)
+		gt.A(t, configs).Length(1)
+		gt.Equal(t, configs[0].ChannelID, "C12345")
+	})
+
+	t.Run("add new version to existing agent", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		existing := setupBundleTestAgent(t, dst)
+
+		result, err := dst.uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+			Bundle:     bundle,
+			OnConflict: agent.ImportConflictNewVersion,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result.Action, agent.ImportActionVersionAdded)
+		// 1.1.0 already exists, so the latest version in the bundle is renumbered
+		gt.Equal(t, result.Versions, []string{"1.1.1"})
+
+		updated, err := dst.agentRepo.GetAgent(ctx, existing.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Latest, "1.1.1")
+
+		version, err := dst.agentRepo.GetAgentVersion(ctx, existing.ID, "1.1.1")
+		gt.NoError(t, err)
+		gt.False(t, version.IsDraft())
+		gt.Equal(t, version.SystemPrompt, "You are an SRE helper with GitHub.")
+	})
+
+	t.Run("overwrite with the same published versions", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		existing := setupBundleTestAgent(t, dst)
+
+		result, err := dst.uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+			Bundle:     bundle,
+			OnConflict: agent.ImportConflictOverwrite,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, result.Versions, []string{"1.0.0", "1.1.0", "1.2.0"})
+		gt.Equal(t, result.Agent.Latest, "1.1.0")
+
+		versions, err := dst.agentRepo.ListAgentVersions(ctx, existing.ID)
+		gt.NoError(t, err)
+		gt.A(t, versions).Length(3)
+	})
+
+	t.Run("created agent is removed when import fails", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		uc := usecase.NewAgentBundle(
+			usecase.WithBundleAgentRepository(dst.agentRepo),
+			usecase.WithBundleSearchConfigRepositories(&failingSlackSearchConfigRepository{dst.slackRepo}, dst.jiraRepo, dst.notionRepo),
+		)
+
+		_, err := uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{Bundle: bundle})
+		gt.Error(t, err)
+
+		exists, err := dst.agentRepo.AgentIDExists(ctx, "sre-helper")
+		gt.NoError(t, err)
+		gt.False(t, exists)
+	})
+
+	t.Run("invalid conflict mode", func(t *testing.T) {
+		dst := newBundleTestEnv()
+		_, err := dst.uc.ImportAgent(ctx, &interfaces.ImportAgentRequest{
+			Bundle:     bundle,
+			OnConflict: agent.ImportConflictMode("replace"),
+		})
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), 400)
+	})
+}
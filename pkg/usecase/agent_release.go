@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// PublishAgentVersion publishes the draft version and makes it the latest version of the agent
+func (u *agentUseCaseImpl) PublishAgentVersion(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+	agentObj, agentVersion, err := getReleasableAgentVersion(ctx, u.agentRepo, agentUUID, version)
+	if err != nil {
+		return nil, err
+	}
+	if !agentVersion.IsDraft() {
+		return nil, goerr.New("version is already published",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	now := time.Now()
+	agentVersion.Status = agent.VersionStatusPublished
+	agentVersion.PublishedAt = &now
+	agentVersion.UpdatedAt = now
+	if err := u.agentRepo.UpdateAgentVersion(ctx, agentVersion); err != nil {
+		return nil, goerr.Wrap(err, "failed to publish agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+
+	agentObj.Latest = version
+	if err := u.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to update latest version", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+
+	ctxlog.From(ctx).Info("published agent version",
+		"agent_uuid", agentUUID,
+		"version", version,
+	)
+	return agentObj, nil
+}
+
+// RollbackAgent points the latest version of the agent back to the published version. The version
+// is used as is, and a running rollout ends.
+func (u *agentUseCaseImpl) RollbackAgent(ctx context.Context, agentUUID types.UUID, version string) (*agent.Agent, error) {
+	agentObj, agentVersion, err := getReleasableAgentVersion(ctx, u.agentRepo, agentUUID, version)
+	if err != nil {
+		return nil, err
+	}
+	if agentVersion.IsDraft() {
+		return nil, goerr.New("can not roll back to draft version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	if version == agentObj.Latest {
+		return nil, goerr.New("version is already the latest version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	previous := agentObj.Latest
+	agentObj.Latest = version
+	agentObj.Rollout = nil
+	if err := u.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to roll back latest version", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+
+	ctxlog.From(ctx).Info("rolled back agent version",
+		"agent_uuid", agentUUID,
+		"from", previous,
+		"to", version,
+	)
+	return agentObj, nil
+}
+
+// getReleasableAgentVersion returns the active agent and its version to publish, roll back to or
+// roll out
+func getReleasableAgentVersion(ctx context.Context, agentRepo interfaces.AgentRepository, agentUUID types.UUID, version string) (*agent.Agent, *agent.AgentVersion, error) {
+	if err := verifyAgentToRun(ctx, agentRepo, agentUUID, version); err != nil {
+		return nil, nil, err
+	}
+	if version == "" {
+		return nil, nil, goerr.New("version is required", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagValidation))
+	}
+
+	agentObj, err := agentRepo.GetAgent(ctx, agentUUID)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to get agent", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagAgentNotFound))
+	}
+	if agentObj.Status != agent.StatusActive {
+		return nil, nil, goerr.New("release of archived agent is not allowed", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagValidation))
+	}
+
+	agentVersion, err := agentRepo.GetAgentVersion(ctx, agentUUID, version)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to get agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+	return agentObj, agentVersion, nil
+}
@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+func TestAgentRelease(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*memory.AgentMemoryClient, *agent.Agent) {
+		agentRepo := memory.NewAgentMemoryClient()
+		agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+		gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    agentObj.ID,
+			Version:      "1.2.0",
+			SystemPrompt: "You are a careful SRE helper.",
+			Status:       agent.VersionStatusDraft,
+			Author:       types.UserID("author-1"),
+		}))
+		return agentRepo, agentObj
+	}
+
+	t.Run("publish draft version", func(t *testing.T) {
+		agentRepo, agentObj := setup(t)
+		uc := usecase.NewAgentUseCases(agentRepo)
+
+		published, err := uc.PublishAgentVersion(ctx, agentObj.ID, "1.2.0")
+		gt.NoError(t, err)
+		gt.Equal(t, published.Latest, "1.2.0")
+
+		version, err := agentRepo.GetAgentVersion(ctx, agentObj.ID, "1.2.0")
+		gt.NoError(t, err)
+		gt.False(t, version.IsDraft())
+		gt.NotNil(t, version.PublishedAt)
+
+		// Published version can not be published again
+		_, err = uc.PublishAgentVersion(ctx, agentObj.ID, "1.2.0")
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+	})
+
+	t.Run("rollback to published version", func(t *testing.T) {
+		agentRepo, agentObj := setup(t)
+		uc := usecase.NewAgentUseCases(agentRepo)
+		gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    agentObj.ID,
+			Version:      "1.1.0",
+			SystemPrompt: "You are a terse SRE helper.",
+		}))
+		agentObj.Latest = "1.1.0"
+		agentObj.Rollout = &agent.Rollout{CandidateVersion: "1.0.0", Percentage: 10}
+		gt.NoError(t, agentRepo.UpdateAgent(ctx, agentObj))
+
+		rolledBack, err := uc.RollbackAgent(ctx, agentObj.ID, "1.0.0")
+		gt.NoError(t, err)
+		gt.Equal(t, rolledBack.Latest, "1.0.0")
+		gt.Nil(t, rolledBack.Rollout)
+
+		stored, err := agentRepo.GetAgent(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, stored.Latest, "1.0.0")
+
+		testCases := []struct {
+			name    string
+			version string
+		}{
+			{name: "latest version", version: "1.0.0"},
+			{name: "draft version", version: "1.2.0"},
+			{name: "unknown version", version: "9.9.9"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := uc.RollbackAgent(ctx, agentObj.ID, tc.version)
+				gt.Error(t, err)
+				gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+			})
+		}
+	})
+}
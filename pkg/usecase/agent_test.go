@@ -24,7 +24,7 @@ func stringPtr(s string) *string {
 func setupAgentTest(t *testing.T) (interfaces.AgentUseCases, interfaces.AgentRepository) {
 	t.Helper()
 	repo := memory.NewAgentMemoryClient()
-	uc := usecase.NewAgentUseCases(repo)
+	uc := usecase.NewAgentUseCases(repo, usecase.WithMCPPolicy(agent.MCPPolicy{AllowedCommands: []string{"/usr/local/bin/mcp-server"}}))
 	return uc, repo
 }
 
@@ -419,6 +419,40 @@ func TestCreateAgentVersion(t *testing.T) {
 	gt.Equal(t, agentVersion.LLMModel, versionReq.LLMModel)
 }
 
+func TestCreateAgentVersion_Draft(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := setupAgentTest(t)
+
+	createdAgent, err := uc.CreateAgent(ctx, &interfaces.CreateAgentRequest{
+		AgentID:      "test-agent",
+		Name:         "Test Agent",
+		SystemPrompt: stringPtr("You are a helpful assistant."),
+		Version:      "1.0.0",
+	})
+	gt.NoError(t, err)
+
+	draft, err := uc.CreateAgentVersion(ctx, &interfaces.CreateVersionRequest{
+		AgentUUID:    createdAgent.ID,
+		Version:      "1.1.0",
+		SystemPrompt: stringPtr("You are an improved helpful assistant."),
+		Draft:        true,
+		Changelog:    "Improve the tone of answers",
+	})
+	gt.NoError(t, err)
+	gt.True(t, draft.IsDraft())
+	gt.Nil(t, draft.PublishedAt)
+	gt.Equal(t, draft.Changelog, "Improve the tone of answers")
+
+	// The draft does not become the latest version
+	stored, err := repo.GetAgent(ctx, createdAgent.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, stored.Latest, "1.0.0")
+
+	latest, err := repo.GetLatestAgentVersion(ctx, createdAgent.ID)
+	gt.NoError(t, err)
+	gt.False(t, latest.IsDraft())
+}
+
 func TestCreateAgentVersion_InvalidVersion(t *testing.T) {
 	ctx := context.Background()
 	uc, _ := setupAgentTest(t)
@@ -504,6 +538,200 @@ func TestGetAgentVersions_NotFound(t *testing.T) {
 	gt.Error(t, err)
 }
 
+func TestSetMCPServer(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := setupAgentTest(t)
+
+	createdAgent, err := uc.CreateAgent(ctx, &interfaces.CreateAgentRequest{
+		AgentID:     "mcp-agent",
+		Name:        "MCP Agent",
+		LLMProvider: types.LLMProviderOpenAI,
+		LLMModel:    "gpt-4",
+		Version:     "1.0.0",
+	})
+	gt.NoError(t, err)
+
+	server := &agent.MCPServer{
+		Name:         "internal",
+		Transport:    agent.MCPTransportHTTP,
+		URL:          "https://mcp.example.com/mcp",
+		Headers:      map[string]string{"Authorization": "Bearer secret"},
+		AllowedTools: []string{"lookup"},
+	}
+	version, err := uc.SetMCPServer(ctx, createdAgent.ID, "1.0.0", server)
+	gt.NoError(t, err)
+	gt.Equal(t, version.Version, "1.0.1")
+	gt.A(t, version.MCPServers).Length(1)
+	gt.Equal(t, version.Changelog, "Set MCP server internal")
+
+	t.Run("base version is not modified", func(t *testing.T) {
+		base, err := repo.GetAgentVersion(ctx, createdAgent.ID, "1.0.0")
+		gt.NoError(t, err)
+		gt.A(t, base.MCPServers).Length(0)
+
+		latest, err := repo.GetLatestAgentVersion(ctx, createdAgent.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, latest.Version, "1.0.1")
+		gt.Equal(t, latest.LLMModel, "gpt-4")
+	})
+
+	t.Run("replace server with same name and keep headers", func(t *testing.T) {
+		updated, err := uc.SetMCPServer(ctx, createdAgent.ID, "1.0.1", &agent.MCPServer{
+			Name:           "internal",
+			Transport:      agent.MCPTransportHTTP,
+			URL:            "https://mcp.example.com/v2/mcp",
+			TimeoutSeconds: 10,
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Version, "1.0.2")
+		gt.A(t, updated.MCPServers).Length(1)
+		gt.Equal(t, updated.MCPServers[0].URL, "https://mcp.example.com/v2/mcp")
+		gt.Equal(t, updated.MCPServers[0].Headers["Authorization"], "Bearer secret")
+
+		stored, err := repo.GetAgentVersion(ctx, createdAgent.ID, "1.0.2")
+		gt.NoError(t, err)
+		gt.Equal(t, stored.MCPServers[0].TimeoutSeconds, 10)
+
+		previous, err := repo.GetAgentVersion(ctx, createdAgent.ID, "1.0.1")
+		gt.NoError(t, err)
+		gt.Equal(t, previous.MCPServers[0].URL, "https://mcp.example.com/mcp")
+	})
+
+	t.Run("add another server", func(t *testing.T) {
+		updated, err := uc.SetMCPServer(ctx, createdAgent.ID, "1.0.2", &agent.MCPServer{
+			Name:      "local",
+			Transport: agent.MCPTransportStdio,
+			Command:   "/usr/local/bin/mcp-server",
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Version, "1.0.3")
+		gt.A(t, updated.MCPServers).Length(2)
+	})
+
+	t.Run("command not allowed", func(t *testing.T) {
+		_, err := uc.SetMCPServer(ctx, createdAgent.ID, "1.0.3", &agent.MCPServer{
+			Name:      "shell",
+			Transport: agent.MCPTransportStdio,
+			Command:   "/bin/sh",
+			Args:      []string{"-c", "id"},
+		})
+		gt.Error(t, err)
+	})
+
+	t.Run("invalid server", func(t *testing.T) {
+		_, err := uc.SetMCPServer(ctx, createdAgent.ID, "1.0.3", &agent.MCPServer{
+			Name:      "invalid",
+			Transport: agent.MCPTransportStdio,
+		})
+		gt.Error(t, err)
+	})
+
+	t.Run("version not found", func(t *testing.T) {
+		_, err := uc.SetMCPServer(ctx, createdAgent.ID, "9.9.9", server)
+		gt.Error(t, err)
+	})
+
+	t.Run("delete server", func(t *testing.T) {
+		updated, err := uc.DeleteMCPServer(ctx, createdAgent.ID, "1.0.3", "internal")
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Version, "1.0.4")
+		gt.Equal(t, updated.Changelog, "Delete MCP server internal")
+		gt.A(t, updated.MCPServers).Length(1)
+		gt.Equal(t, updated.MCPServers[0].Name, "local")
+
+		_, err = uc.DeleteMCPServer(ctx, createdAgent.ID, "1.0.4", "internal")
+		gt.Error(t, err)
+	})
+
+	t.Run("servers are carried over to new version", func(t *testing.T) {
+		_, err := uc.UpdateAgent(ctx, createdAgent.ID, &interfaces.UpdateAgentRequest{
+			SystemPrompt: stringPtr("updated prompt"),
+		})
+		gt.NoError(t, err)
+
+		latest, err := repo.GetLatestAgentVersion(ctx, createdAgent.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, latest.Version, "1.0.5")
+		gt.A(t, latest.MCPServers).Length(1)
+	})
+
+	t.Run("draft stays draft and skips taken version", func(t *testing.T) {
+		_, err := uc.CreateAgentVersion(ctx, &interfaces.CreateVersionRequest{
+			AgentUUID:    createdAgent.ID,
+			Version:      "1.0.6",
+			SystemPrompt: stringPtr("draft prompt"),
+			Draft:        true,
+		})
+		gt.NoError(t, err)
+
+		updated, err := uc.DeleteMCPServer(ctx, createdAgent.ID, "1.0.5", "local")
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Version, "1.0.7")
+
+		draft, err := uc.SetMCPServer(ctx, createdAgent.ID, "1.0.6", server)
+		gt.NoError(t, err)
+		gt.Equal(t, draft.Version, "1.0.8")
+		gt.True(t, draft.IsDraft())
+		gt.Equal(t, draft.SystemPrompt, "draft prompt")
+
+		latest, err := repo.GetLatestAgentVersion(ctx, createdAgent.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, latest.Version, "1.0.7")
+	})
+}
+
+func TestSetDelegation(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := setupAgentTest(t)
+
+	createdAgent, err := uc.CreateAgent(ctx, &interfaces.CreateAgentRequest{
+		AgentID:     "security-reviewer",
+		Name:        "Security Reviewer",
+		LLMProvider: types.LLMProviderOpenAI,
+		LLMModel:    "gpt-4",
+		Version:     "1.0.0",
+	})
+	gt.NoError(t, err)
+
+	version, err := uc.SetDelegation(ctx, createdAgent.ID, "1.0.0", &agent.Delegation{
+		Enabled:  true,
+		AgentIDs: []string{"sql-helper"},
+	})
+	gt.NoError(t, err)
+	gt.Equal(t, version.Version, "1.0.1")
+	gt.True(t, version.Delegation.CanConsult("sql-helper"))
+
+	t.Run("base version is not modified", func(t *testing.T) {
+		base, err := repo.GetAgentVersion(ctx, createdAgent.ID, "1.0.0")
+		gt.NoError(t, err)
+		gt.Nil(t, base.Delegation)
+
+		latest, err := repo.GetLatestAgentVersion(ctx, createdAgent.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, latest.Version, "1.0.1")
+	})
+
+	t.Run("invalid agent ID", func(t *testing.T) {
+		_, err := uc.SetDelegation(ctx, createdAgent.ID, "1.0.1", &agent.Delegation{
+			Enabled:  true,
+			AgentIDs: []string{"-invalid"},
+		})
+		gt.Error(t, err)
+	})
+
+	t.Run("delegation is carried over to new version", func(t *testing.T) {
+		_, err := uc.UpdateAgent(ctx, createdAgent.ID, &interfaces.UpdateAgentRequest{
+			SystemPrompt: stringPtr("updated prompt"),
+		})
+		gt.NoError(t, err)
+
+		latest, err := repo.GetLatestAgentVersion(ctx, createdAgent.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, latest.Version, "1.0.2")
+		gt.True(t, latest.Delegation.IsEnabled())
+	})
+}
+
 // Archive/Unarchive functionality tests
 
 func TestArchiveAgent(t *testing.T) {
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/auth"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// apiTokenTouchInterval throttles updates of the last used time of API tokens
+const apiTokenTouchInterval = 10 * time.Minute
+
+// APIToken manages API tokens of users for the chat API
+type APIToken struct {
+	repo interfaces.APITokenRepository
+}
+
+// NewAPIToken creates a new APIToken instance
+func NewAPIToken(repo interfaces.APITokenRepository) *APIToken {
+	return &APIToken{
+		repo: repo,
+	}
+}
+
+// Ensure APIToken implements interfaces.APITokenUseCases
+var _ interfaces.APITokenUseCases = (*APIToken)(nil)
+
+// CreateAPIToken creates an API token of the user of the session and returns it with the token
+func (uc *APIToken) CreateAPIToken(ctx context.Context, session *auth.Session, name string) (*auth.APIToken, string, error) {
+	if session == nil {
+		return nil, "", goerr.New("authentication is required to create API token", goerr.T(apperr.ErrTagUnauthorized))
+	}
+
+	token, secret, err := auth.NewAPIToken(ctx, session, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := token.Validate(); err != nil {
+		return nil, "", goerr.Wrap(err, "invalid API token", goerr.T(apperr.ErrTagValidation))
+	}
+	if err := uc.repo.PutAPIToken(ctx, token); err != nil {
+		return nil, "", goerr.Wrap(err, "failed to save API token", goerr.V("api_token_id", token.ID))
+	}
+
+	ctxlog.From(ctx).Info("created API token",
+		"api_token_id", token.ID,
+		"user_id", token.UserID,
+	)
+	return token, secret, nil
+}
+
+// ListAPITokens lists API tokens of the user
+func (uc *APIToken) ListAPITokens(ctx context.Context, userID types.UserID) ([]*auth.APIToken, error) {
+	tokens, err := uc.repo.ListAPITokensByUser(ctx, userID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list API tokens", goerr.V("user_id", userID))
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes the API token of the user. Tokens of other users are not found.
+func (uc *APIToken) RevokeAPIToken(ctx context.Context, userID types.UserID, id types.UUID) error {
+	tokens, err := uc.ListAPITokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.ID != id {
+			continue
+		}
+		if err := uc.repo.DeleteAPIToken(ctx, id); err != nil {
+			return goerr.Wrap(err, "failed to delete API token", goerr.V("api_token_id", id))
+		}
+		ctxlog.From(ctx).Info("revoked API token",
+			"api_token_id", id,
+			"user_id", userID,
+		)
+		return nil
+	}
+
+	return goerr.New("API token not found", goerr.V("api_token_id", id), goerr.T(apperr.ErrTagNotFound))
+}
+
+// AuthenticateAPIToken returns the session of the user of the token
+func (uc *APIToken) AuthenticateAPIToken(ctx context.Context, token string) (*auth.Session, error) {
+	if !auth.IsAPIToken(token) {
+		return nil, goerr.New("malformed API token", goerr.T(apperr.ErrTagUnauthorized))
+	}
+
+	apiToken, err := uc.repo.GetAPITokenByHash(ctx, auth.HashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, auth.ErrAPITokenNotFound) {
+			return nil, goerr.Wrap(err, "invalid API token", goerr.T(apperr.ErrTagUnauthorized))
+		}
+		return nil, goerr.Wrap(err, "failed to get API token")
+	}
+	if !apiToken.VerifyToken(token) {
+		return nil, goerr.New("invalid API token", goerr.T(apperr.ErrTagUnauthorized))
+	}
+
+	// The last used time is informational, and failures do not reject the request
+	now := time.Now()
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) > apiTokenTouchInterval {
+		apiToken.LastUsedAt = &now
+		if err := uc.repo.PutAPIToken(ctx, apiToken); err != nil {
+			ctxlog.From(ctx).Warn("failed to update last used time of API token",
+				"api_token_id", apiToken.ID,
+				"error", err,
+			)
+		}
+	}
+
+	return apiToken.Session(), nil
+}
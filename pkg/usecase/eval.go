@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/utils/async"
+)
+
+const (
+	// evalCaseTimeout limits the time for the agent to answer a case and the judge to grade it
+	evalCaseTimeout = 5 * time.Minute
+
+	// evalRunTimeout limits the time for all cases of a run. Cases not run by then are skipped
+	// and the run is finished with an error.
+	evalRunTimeout = 30 * time.Minute
+
+	// defaultEvalRunLimit is the number of eval runs listed if not specified
+	defaultEvalRunLimit = 20
+
+	// maxEvalRunLimit caps the number of eval runs listed
+	maxEvalRunLimit = 100
+)
+
+// Eval holds dependencies for evaluation of agents with golden test cases
+type Eval struct {
+	repo      interfaces.EvalRepository
+	agentRepo interfaces.AgentRepository
+	answerer  interfaces.AgentAnswerUseCases
+	judge     gollem.LLMClient
+}
+
+// EvalOption is a functional option for Eval
+type EvalOption func(*Eval)
+
+// WithEvalRepository sets the eval repository
+func WithEvalRepository(repo interfaces.EvalRepository) EvalOption {
+	return func(uc *Eval) {
+		uc.repo = repo
+	}
+}
+
+// WithEvalAgentRepository sets the agent repository
+func WithEvalAgentRepository(repo interfaces.AgentRepository) EvalOption {
+	return func(uc *Eval) {
+		uc.agentRepo = repo
+	}
+}
+
+// WithEvalAnswerer sets the use case that answers inputs of cases with agents
+func WithEvalAnswerer(answerer interfaces.AgentAnswerUseCases) EvalOption {
+	return func(uc *Eval) {
+		uc.answerer = answerer
+	}
+}
+
+// WithEvalJudgeClient sets the LLM client that judges outputs with rubrics. Cases with rubrics fail
+// if it is not set.
+func WithEvalJudgeClient(client gollem.LLMClient) EvalOption {
+	return func(uc *Eval) {
+		uc.judge = client
+	}
+}
+
+// NewEval creates a new Eval instance
+func NewEval(opts ...EvalOption) *Eval {
+	uc := &Eval{}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Ensure Eval implements interfaces.EvalUseCases
+var _ interfaces.EvalUseCases = (*Eval)(nil)
+
+// CreateEvalCase creates a test case of the agent
+func (uc *Eval) CreateEvalCase(ctx context.Context, req *interfaces.EvalCaseRequest) (*eval.Case, error) {
+	if err := verifyAgentToRun(ctx, uc.agentRepo, req.AgentUUID, ""); err != nil {
+		return nil, err
+	}
+
+	c := eval.NewCase(ctx, req.AgentUUID)
+	applyEvalCaseRequest(c, req)
+
+	if err := uc.putEvalCase(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateEvalCase replaces the test case with the request
+func (uc *Eval) UpdateEvalCase(ctx context.Context, id types.UUID, req *interfaces.EvalCaseRequest) (*eval.Case, error) {
+	c, err := uc.repo.GetEvalCase(ctx, id)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get eval case", goerr.V("eval_case_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+
+	applyEvalCaseRequest(c, req)
+	c.UpdatedAt = time.Now()
+
+	if err := uc.putEvalCase(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteEvalCase deletes the test case. Results of the case in past runs are kept.
+func (uc *Eval) DeleteEvalCase(ctx context.Context, id types.UUID) error {
+	if err := uc.repo.DeleteEvalCase(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to delete eval case", goerr.V("eval_case_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+	return nil
+}
+
+// ListEvalCases lists test cases of the agent
+func (uc *Eval) ListEvalCases(ctx context.Context, agentUUID types.UUID) ([]*eval.Case, error) {
+	cases, err := uc.repo.ListEvalCases(ctx, agentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list eval cases", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return cases, nil
+}
+
+// RunEval runs the test cases of the agent against the version one by one, and stores the result
+// of each case. Failures of the agent to answer a case fail only the case. It returns the finished
+// run.
+func (uc *Eval) RunEval(ctx context.Context, agentUUID types.UUID, version string) (*eval.Run, error) {
+	run, cases, err := uc.prepareEvalRun(ctx, agentUUID, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.executeEvalRun(ctx, run, cases); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// StartEval starts running the test cases of the agent against the version in background, and
+// returns the running run. The results are stored to the run as RunEval does.
+func (uc *Eval) StartEval(ctx context.Context, agentUUID types.UUID, version string) (*eval.Run, error) {
+	run, cases, err := uc.prepareEvalRun(ctx, agentUUID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// The run is updated in background, so the caller gets a copy
+	started := *run
+	async.Dispatch(ctx, func(ctx context.Context) error {
+		return uc.executeEvalRun(ctx, run, cases)
+	})
+	return &started, nil
+}
+
+// prepareEvalRun fixes the version to evaluate and saves a new running run with the cases to run.
+// The version is fixed before running, so that all cases run against the same version.
+func (uc *Eval) prepareEvalRun(ctx context.Context, agentUUID types.UUID, version string) (*eval.Run, []*eval.Case, error) {
+	if uc.answerer == nil {
+		return nil, nil, goerr.New("agent answerer is not configured")
+	}
+
+	agentVersion, err := uc.resolveEvalVersion(ctx, agentUUID, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cases, err := uc.ListEvalCases(ctx, agentUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cases) == 0 {
+		return nil, nil, goerr.New("agent has no eval cases", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagValidation))
+	}
+
+	run := eval.NewRun(ctx, agentUUID, agentVersion)
+	if err := uc.repo.PutEvalRun(ctx, run); err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to save eval run", goerr.V("eval_run_id", run.ID))
+	}
+	return run, cases, nil
+}
+
+// executeEvalRun runs the cases within evalRunTimeout and saves the finished run
+func (uc *Eval) executeEvalRun(ctx context.Context, run *eval.Run, cases []*eval.Case) error {
+	runCtx, cancel := context.WithTimeout(ctx, evalRunTimeout)
+	defer cancel()
+
+	for _, c := range cases {
+		if err := runCtx.Err(); err != nil {
+			run.Finish(goerr.Wrap(err, "eval run is canceled or timed out"))
+			break
+		}
+		run.AddResult(uc.runEvalCase(runCtx, c, run.AgentVersion))
+	}
+	if run.Status == eval.RunStatusRunning {
+		run.Finish(nil)
+	}
+
+	// The run is saved even if the context is canceled, because results are already paid for
+	if err := uc.repo.PutEvalRun(context.WithoutCancel(ctx), run); err != nil {
+		return goerr.Wrap(err, "failed to save eval run", goerr.V("eval_run_id", run.ID))
+	}
+
+	ctxlog.From(ctx).Info("ran eval of agent",
+		"eval_run_id", run.ID,
+		"agent_uuid", run.AgentUUID,
+		"agent_version", run.AgentVersion,
+		"passed", run.Passed,
+		"failed", run.Failed,
+	)
+	return nil
+}
+
+// GetEvalRun returns the eval run
+func (uc *Eval) GetEvalRun(ctx context.Context, id types.UUID) (*eval.Run, error) {
+	run, err := uc.repo.GetEvalRun(ctx, id)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get eval run", goerr.V("eval_run_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+	return run, nil
+}
+
+// ListEvalRuns lists the latest eval runs of the agent, newest first
+func (uc *Eval) ListEvalRuns(ctx context.Context, agentUUID types.UUID, limit int) ([]*eval.Run, error) {
+	if limit <= 0 {
+		limit = defaultEvalRunLimit
+	}
+	limit = min(limit, maxEvalRunLimit)
+
+	runs, err := uc.repo.ListEvalRuns(ctx, agentUUID, limit)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list eval runs", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return runs, nil
+}
+
+// CompareEvalRuns puts results of cases in the base and target runs side by side
+func (uc *Eval) CompareEvalRuns(ctx context.Context, baseRunID, targetRunID types.UUID) ([]*eval.CaseComparison, error) {
+	base, err := uc.GetEvalRun(ctx, baseRunID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := uc.GetEvalRun(ctx, targetRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	if base.AgentUUID != target.AgentUUID {
+		return nil, goerr.New("eval runs of different agents can not be compared",
+			goerr.V("base_run_id", baseRunID),
+			goerr.V("target_run_id", targetRunID),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	return eval.Compare(base, target), nil
+}
+
+// runEvalCase answers the input of the case with the version of the agent and checks the output
+func (uc *Eval) runEvalCase(ctx context.Context, c *eval.Case, agentVersion string) *eval.CaseResult {
+	ctx, cancel := context.WithTimeout(ctx, evalCaseTimeout)
+	defer cancel()
+
+	result := &eval.CaseResult{
+		CaseID:   c.ID,
+		CaseName: c.Name,
+		Input:    c.Input,
+	}
+	started := time.Now()
+	defer func() {
+		result.DurationMS = time.Since(started).Milliseconds()
+	}()
+
+	answer, err := uc.answerer.AnswerWithAgent(ctx, &interfaces.AgentRunRequest{
+		AgentUUID:    c.AgentUUID,
+		AgentVersion: agentVersion,
+		Prompt:       c.Input,
+	})
+	if err != nil {
+		ctxlog.From(ctx).Warn("agent failed to answer eval case",
+			"eval_case_id", c.ID,
+			"agent_uuid", c.AgentUUID,
+			"error", err,
+		)
+		result.Error = err.Error()
+		result.Failures = []string{"agent failed to answer"}
+		return result
+	}
+	result.Output = answer.Response
+
+	result.Failures = c.Check(answer.Response)
+	if strings.TrimSpace(c.Rubric) != "" {
+		verdict, err := uc.judgeOutput(ctx, c, answer.Response)
+		switch {
+		case err != nil:
+			result.Failures = append(result.Failures, "rubric is not judged: "+err.Error())
+		case !verdict.Pass:
+			result.Failures = append(result.Failures, "rubric is not satisfied")
+			result.JudgeReason = verdict.Reason
+		default:
+			result.JudgeReason = verdict.Reason
+		}
+	}
+
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// resolveEvalVersion returns the version of the agent to evaluate, the latest one if empty
+func (uc *Eval) resolveEvalVersion(ctx context.Context, agentUUID types.UUID, version string) (string, error) {
+	if err := verifyAgentToRun(ctx, uc.agentRepo, agentUUID, version); err != nil {
+		return "", err
+	}
+	if version != "" {
+		return version, nil
+	}
+
+	latest, err := uc.agentRepo.GetLatestAgentVersion(ctx, agentUUID)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to get latest version of agent", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return latest.Version, nil
+}
+
+func (uc *Eval) putEvalCase(ctx context.Context, c *eval.Case) error {
+	if err := c.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid eval case", goerr.T(apperr.ErrTagValidation))
+	}
+	if err := uc.repo.PutEvalCase(ctx, c); err != nil {
+		return goerr.Wrap(err, "failed to save eval case", goerr.V("eval_case_id", c.ID))
+	}
+	return nil
+}
+
+func applyEvalCaseRequest(c *eval.Case, req *interfaces.EvalCaseRequest) {
+	c.Name = strings.TrimSpace(req.Name)
+	c.Input = req.Input
+	c.ExpectedFacts = req.ExpectedFacts
+	c.ExpectedPatterns = req.ExpectedPatterns
+	c.Rubric = req.Rubric
+}
+
+// judgeVerdict is the grade of an output returned by the LLM judge
+type judgeVerdict struct {
+	Pass   bool   `json:"pass"`
+	Reason string `json:"reason"`
+}
+
+// judgeOutput asks the LLM judge whether the output satisfies the rubric of the case
+func (uc *Eval) judgeOutput(ctx context.Context, c *eval.Case, output string) (*judgeVerdict, error) {
+	if uc.judge == nil {
+		return nil, goerr.New("judge LLM is not configured")
+	}
+
+	session, err := uc.judge.NewSession(ctx, gollem.WithSessionSystemPrompt(evalJudgePrompt))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create judge session")
+	}
+
+	prompt := fmt.Sprintf("## Request\n%s\n\n## Rubric\n%s\n\n## Response\n%s", c.Input, c.Rubric, output)
+	resp, err := session.GenerateContent(ctx, gollem.Text(prompt))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to generate verdict of judge")
+	}
+
+	return parseJudgeVerdict(strings.Join(resp.Texts, ""))
+}
+
+// evalJudgePrompt is the system prompt of the LLM judge
+const evalJudgePrompt = `You grade responses of an assistant agent to requests against a rubric. ` +
+	`Judge only whether the response satisfies every criterion of the rubric, not its style.
+
+Respond with only a JSON object in the following format:
+{"pass": <true or false>, "reason": "<short reason>"}`
+
+// parseJudgeVerdict extracts the verdict from the LLM response. The JSON object may be surrounded
+// by a code block or explanation.
+func parseJudgeVerdict(text string) (*judgeVerdict, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, goerr.New("verdict of judge is not JSON", goerr.V("response", text))
+	}
+
+	var verdict judgeVerdict
+	if err := json.Unmarshal([]byte(text[start:end+1]), &verdict); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse verdict of judge", goerr.V("response", text))
+	}
+	return &verdict, nil
+}
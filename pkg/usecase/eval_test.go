@@ -0,0 +1,280 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/eval"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/service/llm"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/m-mizutani/tamamo/pkg/utils/async"
+)
+
+// fakeAgentAnswerer answers with the response of the version of the agent
+type fakeAgentAnswerer struct {
+	responses map[string]string
+}
+
+func (x *fakeAgentAnswerer) AnswerWithAgent(ctx context.Context, req *interfaces.AgentRunRequest) (*interfaces.AgentRunResult, error) {
+	resp, ok := x.responses[req.AgentVersion]
+	if !ok {
+		return nil, errors.New("LLM is unavailable")
+	}
+	return &interfaces.AgentRunResult{AgentVersion: req.AgentVersion, Response: resp}, nil
+}
+
+func TestRunEvalWithScriptedClient(t *testing.T) {
+	ctx := context.Background()
+
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	// The same scripted client answers as the agent and judges as the judge
+	client, err := llm.NewScriptedClient(&llm.Script{
+		Responses: []*llm.ScriptedResponse{
+			{Match: "## Rubric", Text: "```json\n{\"pass\": true, \"reason\": \"mentions log rotation\"}\n```"},
+			{Match: "(?i)disk", Text: "Run `df -h` on web-1 and enable log rotation."},
+		},
+		Default: "I don't know.",
+	})
+	gt.NoError(t, err)
+
+	slackUC := usecase.New(
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithLLMClient(client),
+	)
+	uc := usecase.NewEval(
+		usecase.WithEvalRepository(memory.NewEvalRepository()),
+		usecase.WithEvalAgentRepository(agentRepo),
+		usecase.WithEvalAnswerer(slackUC),
+		usecase.WithEvalJudgeClient(client),
+	)
+
+	diskCase, err := uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+		AgentUUID:        agentObj.ID,
+		Name:             "  disk full  ",
+		Input:            "Disk of web-1 is full",
+		ExpectedFacts:    []string{"log rotation"},
+		ExpectedPatterns: []string{`df\s+-h`},
+		Rubric:           "Suggests a way to free up disk space",
+	})
+	gt.NoError(t, err)
+	gt.Equal(t, diskCase.Name, "disk full")
+
+	_, err = uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+		AgentUUID:     agentObj.ID,
+		Name:          "certificate",
+		Input:         "When does the certificate expire?",
+		ExpectedFacts: []string{"2027-01-01"},
+	})
+	gt.NoError(t, err)
+
+	run, err := uc.RunEval(ctx, agentObj.ID, "")
+	gt.NoError(t, err)
+	gt.Equal(t, run.AgentVersion, "1.0.0")
+	gt.Equal(t, run.Status, eval.RunStatusCompleted)
+	gt.Equal(t, run.Passed, 1)
+	gt.Equal(t, run.Failed, 1)
+	gt.A(t, run.Results).Length(2)
+
+	gt.True(t, run.Results[0].Passed)
+	gt.Equal(t, run.Results[0].Output, "Run `df -h` on web-1 and enable log rotation.")
+	gt.Equal(t, run.Results[0].JudgeReason, "mentions log rotation")
+
+	gt.False(t, run.Results[1].Passed)
+	gt.Equal(t, run.Results[1].Output, "I don't know.")
+	gt.Equal(t, run.Results[1].Failures, []string{`missing fact: "2027-01-01"`})
+
+	stored, err := uc.GetEvalRun(ctx, run.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, stored.Passed, 1)
+
+	runs, err := uc.ListEvalRuns(ctx, agentObj.ID, 0)
+	gt.NoError(t, err)
+	gt.A(t, runs).Length(1)
+}
+
+func TestStartEval(t *testing.T) {
+	// Background run is executed synchronously in tests
+	ctx := async.WithSyncMode(context.Background())
+
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	uc := usecase.NewEval(
+		usecase.WithEvalRepository(memory.NewEvalRepository()),
+		usecase.WithEvalAgentRepository(agentRepo),
+		usecase.WithEvalAnswerer(&fakeAgentAnswerer{responses: map[string]string{"1.0.0": "Use df -h."}}),
+	)
+	_, err := uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+		AgentUUID:     agentObj.ID,
+		Name:          "disk full",
+		Input:         "Disk of web-1 is full",
+		ExpectedFacts: []string{"df -h"},
+	})
+	gt.NoError(t, err)
+
+	// The run is returned before its cases are run
+	run, err := uc.StartEval(ctx, agentObj.ID, "")
+	gt.NoError(t, err)
+	gt.Equal(t, run.Status, eval.RunStatusRunning)
+	gt.A(t, run.Results).Length(0)
+
+	stored, err := uc.GetEvalRun(ctx, run.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, stored.Status, eval.RunStatusCompleted)
+	gt.Equal(t, stored.Passed, 1)
+
+	t.Run("no cases", func(t *testing.T) {
+		other := setupToolTestAgent(t, agentRepo, "db-helper")
+		_, err := uc.StartEval(ctx, other.ID, "")
+		gt.Error(t, err)
+	})
+}
+
+func TestRunEvalFailures(t *testing.T) {
+	ctx := context.Background()
+
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+	evalRepo := memory.NewEvalRepository()
+
+	// The judge is not configured, and version 1.0.0 fails to answer
+	uc := usecase.NewEval(
+		usecase.WithEvalRepository(evalRepo),
+		usecase.WithEvalAgentRepository(agentRepo),
+		usecase.WithEvalAnswerer(&fakeAgentAnswerer{responses: map[string]string{"1.1.0": "Use df -h."}}),
+	)
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.1.0",
+		SystemPrompt: "You are an SRE helper.",
+	}))
+
+	t.Run("no cases", func(t *testing.T) {
+		_, err := uc.RunEval(ctx, agentObj.ID, "1.0.0")
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+	})
+
+	_, err := uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+		AgentUUID: agentObj.ID,
+		Name:      "disk full",
+		Input:     "Disk of web-1 is full",
+		Rubric:    "Suggests a way to free up disk space",
+	})
+	gt.NoError(t, err)
+
+	t.Run("agent fails to answer", func(t *testing.T) {
+		run, err := uc.RunEval(ctx, agentObj.ID, "1.0.0")
+		gt.NoError(t, err)
+		gt.Equal(t, run.Failed, 1)
+		gt.Equal(t, run.Results[0].Failures, []string{"agent failed to answer"})
+		gt.S(t, run.Results[0].Error).Contains("LLM is unavailable")
+	})
+
+	t.Run("judge is not configured", func(t *testing.T) {
+		run, err := uc.RunEval(ctx, agentObj.ID, "1.1.0")
+		gt.NoError(t, err)
+		gt.Equal(t, run.Failed, 1)
+		gt.A(t, run.Results[0].Failures).Length(1)
+		gt.S(t, run.Results[0].Failures[0]).Contains("rubric is not judged")
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := uc.RunEval(ctx, agentObj.ID, "9.9.9")
+		gt.Error(t, err)
+	})
+
+	t.Run("invalid case", func(t *testing.T) {
+		_, err := uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+			AgentUUID: agentObj.ID,
+			Name:      "no expectation",
+			Input:     "Disk of web-1 is full",
+		})
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+	})
+}
+
+func TestCompareEvalRuns(t *testing.T) {
+	ctx := context.Background()
+
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.1.0",
+		SystemPrompt: "You are a terse SRE helper.",
+	}))
+
+	uc := usecase.NewEval(
+		usecase.WithEvalRepository(memory.NewEvalRepository()),
+		usecase.WithEvalAgentRepository(agentRepo),
+		usecase.WithEvalAnswerer(&fakeAgentAnswerer{responses: map[string]string{
+			"1.0.0": "Run df -h and enable log rotation.",
+			"1.1.0": "Enable log rotation.",
+		}}),
+	)
+
+	dfCase, err := uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+		AgentUUID:        agentObj.ID,
+		Name:             "mentions df",
+		Input:            "Disk of web-1 is full",
+		ExpectedPatterns: []string{`df -h`},
+	})
+	gt.NoError(t, err)
+	_, err = uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+		AgentUUID:     agentObj.ID,
+		Name:          "mentions log rotation",
+		Input:         "Disk of web-1 is full",
+		ExpectedFacts: []string{"log rotation"},
+	})
+	gt.NoError(t, err)
+
+	base, err := uc.RunEval(ctx, agentObj.ID, "1.0.0")
+	gt.NoError(t, err)
+	target, err := uc.RunEval(ctx, agentObj.ID, "1.1.0")
+	gt.NoError(t, err)
+	gt.Equal(t, base.Passed, 2)
+	gt.Equal(t, target.Passed, 1)
+
+	comparisons, err := uc.CompareEvalRuns(ctx, base.ID, target.ID)
+	gt.NoError(t, err)
+	gt.A(t, comparisons).Length(2)
+	gt.Equal(t, comparisons[0].CaseID, dfCase.ID)
+	gt.True(t, comparisons[0].Regressed())
+	gt.Equal(t, comparisons[0].Target.Output, "Enable log rotation.")
+	gt.False(t, comparisons[1].Regressed())
+
+	t.Run("different agents", func(t *testing.T) {
+		otherAgent := setupToolTestAgent(t, agentRepo, "db-helper")
+		_, err := uc.CreateEvalCase(ctx, &interfaces.EvalCaseRequest{
+			AgentUUID:     otherAgent.ID,
+			Name:          "mentions log rotation",
+			Input:         "Disk of db-1 is full",
+			ExpectedFacts: []string{"log rotation"},
+		})
+		gt.NoError(t, err)
+		other, err := uc.RunEval(ctx, otherAgent.ID, "1.0.0")
+		gt.NoError(t, err)
+
+		_, err = uc.CompareEvalRuns(ctx, base.ID, other.ID)
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+	})
+
+	t.Run("run not found", func(t *testing.T) {
+		_, err := uc.CompareEvalRuns(ctx, base.ID, types.NewUUID(ctx))
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusNotFound)
+	})
+}
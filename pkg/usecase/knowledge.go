@@ -0,0 +1,363 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/service/document"
+	pkgErrors "github.com/m-mizutani/tamamo/pkg/utils/errors"
+)
+
+const (
+	// knowledgeChunkSize is the maximum size of a chunk of a knowledge document in bytes
+	knowledgeChunkSize = 2000
+
+	// maxKnowledgeChunks caps the number of chunks of a knowledge document
+	maxKnowledgeChunks = 2000
+
+	// knowledgeEmbeddingBatchSize is the number of chunks embedded by a single API call
+	knowledgeEmbeddingBatchSize = 64
+
+	// defaultKnowledgeSearchLimit is the number of chunks returned by search if not specified
+	defaultKnowledgeSearchLimit = 5
+
+	// maxKnowledgeSearchLimit caps the number of chunks returned by search
+	maxKnowledgeSearchLimit = 20
+)
+
+// Knowledge holds dependencies for knowledge base use cases
+type Knowledge struct {
+	repo           interfaces.KnowledgeRepository
+	index          interfaces.VectorIndex
+	storage        interfaces.StorageAdapter
+	agentRepo      interfaces.AgentRepository
+	embedder       gollem.LLMClient
+	embeddingModel string // "provider:model" of the embedder, recorded in documents
+	dimension      int
+}
+
+// KnowledgeOption is a functional option for Knowledge
+type KnowledgeOption func(*Knowledge)
+
+// WithKnowledgeRepository sets the knowledge document repository
+func WithKnowledgeRepository(repo interfaces.KnowledgeRepository) KnowledgeOption {
+	return func(uc *Knowledge) {
+		uc.repo = repo
+	}
+}
+
+// WithKnowledgeVectorIndex sets the vector index of chunks
+func WithKnowledgeVectorIndex(index interfaces.VectorIndex) KnowledgeOption {
+	return func(uc *Knowledge) {
+		uc.index = index
+	}
+}
+
+// WithKnowledgeStorage sets the storage adapter to keep original documents
+func WithKnowledgeStorage(storage interfaces.StorageAdapter) KnowledgeOption {
+	return func(uc *Knowledge) {
+		uc.storage = storage
+	}
+}
+
+// WithKnowledgeAgentRepository sets the agent repository
+func WithKnowledgeAgentRepository(repo interfaces.AgentRepository) KnowledgeOption {
+	return func(uc *Knowledge) {
+		uc.agentRepo = repo
+	}
+}
+
+// WithKnowledgeEmbedder sets the LLM client to embed chunks and queries. model is recorded in
+// documents as the embedding model, and dimension is the number of dimensions of embeddings.
+func WithKnowledgeEmbedder(client gollem.LLMClient, model string, dimension int) KnowledgeOption {
+	return func(uc *Knowledge) {
+		uc.embedder = client
+		uc.embeddingModel = model
+		uc.dimension = dimension
+	}
+}
+
+// NewKnowledge creates a new Knowledge instance
+func NewKnowledge(opts ...KnowledgeOption) *Knowledge {
+	uc := &Knowledge{}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Ensure Knowledge implements interfaces.KnowledgeUseCases
+var _ interfaces.KnowledgeUseCases = (*Knowledge)(nil)
+
+// UploadKnowledgeDocument stores the document, splits its text into chunks and indexes them with
+// their embeddings. A document with the same name of the agent is replaced, and its chunks are
+// re-embedded.
+func (uc *Knowledge) UploadKnowledgeDocument(ctx context.Context, req *interfaces.UploadKnowledgeDocumentRequest) (*knowledge.Document, error) {
+	if !req.AgentUUID.IsValid() {
+		return nil, goerr.New("invalid agent UUID", goerr.TV(apperr.AgentUUIDKey, req.AgentUUID), goerr.T(apperr.ErrTagValidation))
+	}
+	if _, err := uc.agentRepo.GetAgent(ctx, req.AgentUUID); err != nil {
+		return nil, goerr.Wrap(err, "failed to verify agent", goerr.TV(apperr.AgentUUIDKey, req.AgentUUID), goerr.T(apperr.ErrTagAgentNotFound))
+	}
+
+	if req.FileSize > knowledge.MaxDocumentSize {
+		return nil, goerr.New("knowledge document is too large",
+			goerr.TV(apperr.FilenameKey, req.Name),
+			goerr.V("size", req.FileSize),
+			goerr.V("max", knowledge.MaxDocumentSize),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	if !document.IsSupported(req.Name, req.ContentType) {
+		return nil, goerr.New("unsupported knowledge document type, upload a PDF or a text file",
+			goerr.TV(apperr.FilenameKey, req.Name),
+			goerr.V("content_type", req.ContentType),
+			goerr.T(apperr.ErrTagInvalidFileType))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.FileReader, knowledge.MaxDocumentSize+1))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read knowledge document", goerr.TV(apperr.FilenameKey, req.Name))
+	}
+	if len(data) > knowledge.MaxDocumentSize {
+		return nil, goerr.New("knowledge document is too large",
+			goerr.TV(apperr.FilenameKey, req.Name),
+			goerr.V("max", knowledge.MaxDocumentSize),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	text, err := document.Extract(req.Name, req.ContentType, data)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to extract text of knowledge document",
+			goerr.TV(apperr.FilenameKey, req.Name),
+			goerr.T(apperr.ErrTagInvalidFileType))
+	}
+	texts := document.Chunk(text, knowledgeChunkSize)
+	if len(texts) == 0 {
+		return nil, goerr.New("knowledge document has no text",
+			goerr.TV(apperr.FilenameKey, req.Name),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	if len(texts) > maxKnowledgeChunks {
+		return nil, goerr.New("knowledge document has too much text",
+			goerr.TV(apperr.FilenameKey, req.Name),
+			goerr.V("chunks", len(texts)),
+			goerr.V("max", maxKnowledgeChunks),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	// Replace the document with the same name, keeping its ID
+	doc, err := uc.repo.GetKnowledgeDocumentByName(ctx, req.AgentUUID, req.Name)
+	replaced := err == nil
+	switch {
+	case replaced:
+		doc.ContentType = req.ContentType
+		doc.Size = int64(len(data))
+		doc.UpdatedAt = time.Now()
+	case errors.Is(err, knowledge.ErrDocumentNotFound):
+		doc = knowledge.NewDocument(ctx, req.AgentUUID, req.Name, req.ContentType, int64(len(data)))
+	default:
+		return nil, goerr.Wrap(err, "failed to get knowledge document by name", goerr.TV(apperr.FilenameKey, req.Name))
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid knowledge document", goerr.T(apperr.ErrTagValidation))
+	}
+
+	embeddings, err := uc.embed(ctx, texts)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to embed knowledge document", goerr.TV(apperr.FilenameKey, req.Name))
+	}
+
+	doc.StorageKey = knowledgeStorageKey(doc)
+	if err := uc.storage.Put(ctx, doc.StorageKey, data); err != nil {
+		return nil, goerr.Wrap(err, "failed to store knowledge document", goerr.V("key", doc.StorageKey))
+	}
+
+	chunks := newKnowledgeChunks(doc, texts, embeddings)
+	if err := uc.index.PutChunks(ctx, doc.ID, chunks); err != nil {
+		return nil, goerr.Wrap(err, "failed to index knowledge document", goerr.V("document_id", doc.ID))
+	}
+
+	doc.ChunkCount = len(chunks)
+	doc.EmbeddingModel = uc.embeddingModel
+	if err := uc.repo.PutKnowledgeDocument(ctx, doc); err != nil {
+		return nil, goerr.Wrap(err, "failed to save knowledge document", goerr.V("document_id", doc.ID))
+	}
+
+	ctxlog.From(ctx).Info("indexed knowledge document",
+		"agent_uuid", doc.AgentUUID,
+		"document_id", doc.ID,
+		"name", doc.Name,
+		"size", doc.Size,
+		"chunks", doc.ChunkCount,
+		"replaced", replaced,
+	)
+
+	return doc, nil
+}
+
+// ListKnowledgeDocuments lists documents of the agent
+func (uc *Knowledge) ListKnowledgeDocuments(ctx context.Context, agentUUID types.UUID) ([]*knowledge.Document, error) {
+	docs, err := uc.repo.ListKnowledgeDocuments(ctx, agentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list knowledge documents", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return docs, nil
+}
+
+// DeleteKnowledgeDocument deletes the document and its chunks. The original file is left in
+// storage because the storage adapter does not support deletion.
+func (uc *Knowledge) DeleteKnowledgeDocument(ctx context.Context, id types.UUID) error {
+	if _, err := uc.repo.GetKnowledgeDocument(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to get knowledge document", goerr.V("document_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+
+	if err := uc.index.DeleteChunks(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to delete chunks of knowledge document", goerr.V("document_id", id))
+	}
+	if err := uc.repo.DeleteKnowledgeDocument(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to delete knowledge document", goerr.V("document_id", id))
+	}
+	return nil
+}
+
+// SearchKnowledge returns chunks of documents of the agent most similar to the query
+func (uc *Knowledge) SearchKnowledge(ctx context.Context, agentUUID types.UUID, query string, limit int) ([]*knowledge.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, goerr.New("query is required", goerr.T(apperr.ErrTagValidation))
+	}
+	if limit <= 0 {
+		limit = defaultKnowledgeSearchLimit
+	}
+	limit = min(limit, maxKnowledgeSearchLimit)
+
+	vectors, err := uc.embed(ctx, []string{query})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to embed knowledge query", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+
+	results, err := uc.index.Search(ctx, agentUUID, vectors[0], limit)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to search knowledge", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return results, nil
+}
+
+// ReindexKnowledge indexes stored documents whose chunks are missing in the vector index or were
+// embedded by another embedding model. It is called at startup because the index may be lost on
+// restart, e.g. the memory index, while documents are kept in the repository. Documents that
+// fail to be indexed are logged and skipped.
+func (uc *Knowledge) ReindexKnowledge(ctx context.Context) error {
+	docs, err := uc.repo.ListAllKnowledgeDocuments(ctx)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list knowledge documents")
+	}
+
+	var indexed int
+	for _, doc := range docs {
+		count, err := uc.index.CountChunks(ctx, doc.ID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to count chunks of knowledge document", goerr.V("document_id", doc.ID))
+		}
+		if count > 0 && count == doc.ChunkCount && doc.EmbeddingModel == uc.embeddingModel {
+			continue
+		}
+
+		if err := uc.reindexDocument(ctx, doc); err != nil {
+			pkgErrors.Handle(ctx, goerr.Wrap(err, "failed to reindex knowledge document",
+				goerr.TV(apperr.AgentUUIDKey, doc.AgentUUID),
+				goerr.V("document_id", doc.ID)))
+			continue
+		}
+		indexed++
+	}
+
+	ctxlog.From(ctx).Info("reindexed knowledge documents",
+		"documents", len(docs),
+		"reindexed", indexed,
+	)
+	return nil
+}
+
+// reindexDocument embeds the stored original file of the document again and replaces its chunks
+func (uc *Knowledge) reindexDocument(ctx context.Context, doc *knowledge.Document) error {
+	data, err := uc.storage.Get(ctx, doc.StorageKey)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get original knowledge document", goerr.V("key", doc.StorageKey))
+	}
+	text, err := document.Extract(doc.Name, doc.ContentType, data)
+	if err != nil {
+		return goerr.Wrap(err, "failed to extract text of knowledge document", goerr.TV(apperr.FilenameKey, doc.Name))
+	}
+	texts := document.Chunk(text, knowledgeChunkSize)
+
+	embeddings, err := uc.embed(ctx, texts)
+	if err != nil {
+		return goerr.Wrap(err, "failed to embed knowledge document", goerr.TV(apperr.FilenameKey, doc.Name))
+	}
+	if err := uc.index.PutChunks(ctx, doc.ID, newKnowledgeChunks(doc, texts, embeddings)); err != nil {
+		return goerr.Wrap(err, "failed to index knowledge document", goerr.V("document_id", doc.ID))
+	}
+
+	doc.ChunkCount = len(texts)
+	doc.EmbeddingModel = uc.embeddingModel
+	if err := uc.repo.PutKnowledgeDocument(ctx, doc); err != nil {
+		return goerr.Wrap(err, "failed to save knowledge document", goerr.V("document_id", doc.ID))
+	}
+	return nil
+}
+
+// newKnowledgeChunks returns chunks of the document with the texts and their embeddings
+func newKnowledgeChunks(doc *knowledge.Document, texts []string, embeddings [][]float64) []*knowledge.Chunk {
+	chunks := make([]*knowledge.Chunk, 0, len(texts))
+	for i, text := range texts {
+		chunks = append(chunks, &knowledge.Chunk{
+			DocumentID:   doc.ID,
+			AgentUUID:    doc.AgentUUID,
+			DocumentName: doc.Name,
+			Index:        i,
+			Text:         text,
+			Embedding:    embeddings[i],
+		})
+	}
+	return chunks
+}
+
+// embed embeds texts in batches and returns an embedding for each text
+func (uc *Knowledge) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if uc.embedder == nil {
+		return nil, goerr.New("knowledge embedding is not configured")
+	}
+
+	embeddings := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += knowledgeEmbeddingBatchSize {
+		batch := texts[start:min(start+knowledgeEmbeddingBatchSize, len(texts))]
+		vectors, err := uc.embedder.GenerateEmbedding(ctx, uc.dimension, batch)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to generate embeddings", goerr.T(apperr.ErrTagLLMError))
+		}
+		if len(vectors) != len(batch) {
+			return nil, goerr.New("number of embeddings does not match inputs",
+				goerr.V("inputs", len(batch)),
+				goerr.V("embeddings", len(vectors)))
+		}
+		embeddings = append(embeddings, vectors...)
+	}
+	return embeddings, nil
+}
+
+// knowledgeStorageKey returns the storage key of the original file of the document
+func knowledgeStorageKey(doc *knowledge.Document) string {
+	return fmt.Sprintf("knowledge/%s/%s", doc.AgentUUID, doc.ID)
+}
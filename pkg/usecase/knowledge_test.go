@@ -0,0 +1,303 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/repository/vector"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// keywordEmbedder embeds texts by counts of keywords, so that similarity search is predictable
+func keywordEmbedder(keywords ...string) *llm_mock.LLMClientMock {
+	return &llm_mock.LLMClientMock{
+		GenerateEmbeddingFunc: func(ctx context.Context, dimension int, input []string) ([][]float64, error) {
+			vectors := make([][]float64, 0, len(input))
+			for _, text := range input {
+				vector := make([]float64, 0, len(keywords)+1)
+				for _, keyword := range keywords {
+					vector = append(vector, float64(strings.Count(strings.ToLower(text), keyword)))
+				}
+				vectors = append(vectors, append(vector, 0.1))
+			}
+			return vectors, nil
+		},
+	}
+}
+
+func TestKnowledgeUploadAndSearch(t *testing.T) {
+	ctx := context.Background()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	embedder := keywordEmbedder("restart", "rollback")
+	storageAdapter := newMockStorageAdapter()
+	uc := usecase.NewKnowledge(
+		usecase.WithKnowledgeRepository(memory.NewKnowledgeRepository()),
+		usecase.WithKnowledgeVectorIndex(vector.NewMemory()),
+		usecase.WithKnowledgeStorage(storageAdapter),
+		usecase.WithKnowledgeAgentRepository(agentRepo),
+		usecase.WithKnowledgeEmbedder(embedder, "openai:text-embedding-3-small", 3),
+	)
+
+	upload := func(name, content string) *interfaces.UploadKnowledgeDocumentRequest {
+		return &interfaces.UploadKnowledgeDocumentRequest{
+			AgentUUID:   agentObj.ID,
+			Name:        name,
+			ContentType: "text/markdown",
+			FileReader:  strings.NewReader(content),
+			FileSize:    int64(len(content)),
+		}
+	}
+
+	doc, err := uc.UploadKnowledgeDocument(ctx, upload("runbook.md", "How to restart the API server: run restart command.\n"))
+	gt.NoError(t, err)
+	gt.Equal(t, doc.ChunkCount, 1)
+	gt.Equal(t, doc.EmbeddingModel, "openai:text-embedding-3-small")
+	gt.NotEqual(t, doc.StorageKey, "")
+
+	_, err = uc.UploadKnowledgeDocument(ctx, upload("deploy.md", "Rollback the deployment with rollback command.\n"))
+	gt.NoError(t, err)
+
+	t.Run("search returns the most similar chunk with document name", func(t *testing.T) {
+		results, err := uc.SearchKnowledge(ctx, agentObj.ID, "how do I rollback?", 1)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(1)
+		gt.Equal(t, results[0].Chunk.DocumentName, "deploy.md")
+	})
+
+	t.Run("document with the same name is replaced and re-embedded", func(t *testing.T) {
+		calls := len(embedder.GenerateEmbeddingCalls())
+
+		content := strings.Repeat("Rollback steps are in this runbook now.\n", 100)
+		replaced, err := uc.UploadKnowledgeDocument(ctx, upload("runbook.md", content))
+		gt.NoError(t, err)
+		gt.Equal(t, replaced.ID, doc.ID)
+		gt.Equal(t, replaced.CreatedAt, doc.CreatedAt)
+		gt.True(t, replaced.ChunkCount > 1)
+		gt.True(t, len(embedder.GenerateEmbeddingCalls()) > calls)
+
+		docs, err := uc.ListKnowledgeDocuments(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.A(t, docs).Length(2)
+
+		// Old chunks about restart are gone
+		results, err := uc.SearchKnowledge(ctx, agentObj.ID, "restart", 20)
+		gt.NoError(t, err)
+		for _, result := range results {
+			gt.S(t, result.Chunk.Text).NotContains("restart")
+		}
+	})
+
+	t.Run("deleted document is not searched", func(t *testing.T) {
+		gt.NoError(t, uc.DeleteKnowledgeDocument(ctx, doc.ID))
+
+		results, err := uc.SearchKnowledge(ctx, agentObj.ID, "rollback", 20)
+		gt.NoError(t, err)
+		for _, result := range results {
+			gt.Equal(t, result.Chunk.DocumentName, "deploy.md")
+		}
+		gt.Error(t, uc.DeleteKnowledgeDocument(ctx, doc.ID))
+	})
+
+	t.Run("unsupported file is rejected", func(t *testing.T) {
+		_, err := uc.UploadKnowledgeDocument(ctx, &interfaces.UploadKnowledgeDocumentRequest{
+			AgentUUID:   agentObj.ID,
+			Name:        "diagram.png",
+			ContentType: "image/png",
+			FileReader:  strings.NewReader("\x89PNG"),
+			FileSize:    4,
+		})
+		gt.Error(t, err)
+	})
+
+	t.Run("unknown agent is rejected", func(t *testing.T) {
+		_, err := uc.UploadKnowledgeDocument(ctx, &interfaces.UploadKnowledgeDocumentRequest{
+			AgentUUID:   types.NewUUID(ctx),
+			Name:        "notes.txt",
+			ContentType: "text/plain",
+			FileReader:  strings.NewReader("notes"),
+			FileSize:    5,
+		})
+		gt.Error(t, err)
+	})
+}
+
+func TestKnowledgeReindex(t *testing.T) {
+	ctx := context.Background()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	repo := memory.NewKnowledgeRepository()
+	storageAdapter := newMockStorageAdapter()
+	newUseCase := func(embedder *llm_mock.LLMClientMock, index interfaces.VectorIndex, model string) *usecase.Knowledge {
+		return usecase.NewKnowledge(
+			usecase.WithKnowledgeRepository(repo),
+			usecase.WithKnowledgeVectorIndex(index),
+			usecase.WithKnowledgeStorage(storageAdapter),
+			usecase.WithKnowledgeAgentRepository(agentRepo),
+			usecase.WithKnowledgeEmbedder(embedder, model, 3),
+		)
+	}
+
+	content := "How to restart the API server: run restart command.\n"
+	uploaded, err := newUseCase(keywordEmbedder("restart", "rollback"), vector.NewMemory(), "openai:text-embedding-3-small").
+		UploadKnowledgeDocument(ctx, &interfaces.UploadKnowledgeDocumentRequest{
+			AgentUUID:   agentObj.ID,
+			Name:        "runbook.md",
+			ContentType: "text/markdown",
+			FileReader:  strings.NewReader(content),
+			FileSize:    int64(len(content)),
+		})
+	gt.NoError(t, err)
+
+	t.Run("documents lost from the index are indexed again", func(t *testing.T) {
+		embedder := keywordEmbedder("restart", "rollback")
+		uc := newUseCase(embedder, vector.NewMemory(), "openai:text-embedding-3-small")
+
+		results, err := uc.SearchKnowledge(ctx, agentObj.ID, "restart", 5)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(0)
+
+		gt.NoError(t, uc.ReindexKnowledge(ctx))
+		results, err = uc.SearchKnowledge(ctx, agentObj.ID, "restart", 5)
+		gt.NoError(t, err)
+		gt.A(t, results).Length(1)
+		gt.Equal(t, results[0].Chunk.DocumentID, uploaded.ID)
+		gt.Equal(t, results[0].Chunk.DocumentName, "runbook.md")
+
+		// Indexed documents are not embedded again
+		calls := len(embedder.GenerateEmbeddingCalls())
+		gt.NoError(t, uc.ReindexKnowledge(ctx))
+		gt.Equal(t, len(embedder.GenerateEmbeddingCalls()), calls)
+	})
+
+	t.Run("documents of another embedding model are embedded again", func(t *testing.T) {
+		index := vector.NewMemory()
+		gt.NoError(t, newUseCase(keywordEmbedder("restart", "rollback"), index, "openai:text-embedding-3-small").ReindexKnowledge(ctx))
+
+		embedder := keywordEmbedder("restart", "rollback")
+		gt.NoError(t, newUseCase(embedder, index, "gemini:text-embedding-004").ReindexKnowledge(ctx))
+		gt.A(t, embedder.GenerateEmbeddingCalls()).Length(1)
+
+		doc, err := repo.GetKnowledgeDocument(ctx, uploaded.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, doc.EmbeddingModel, "gemini:text-embedding-004")
+	})
+}
+
+func TestHandleSlackAppMentionWithKnowledgeTool(t *testing.T) {
+	ctx := context.Background()
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	knowledgeUC := usecase.NewKnowledge(
+		usecase.WithKnowledgeRepository(memory.NewKnowledgeRepository()),
+		usecase.WithKnowledgeVectorIndex(vector.NewMemory()),
+		usecase.WithKnowledgeStorage(newMockStorageAdapter()),
+		usecase.WithKnowledgeAgentRepository(agentRepo),
+		usecase.WithKnowledgeEmbedder(keywordEmbedder("restart", "rollback"), "openai:text-embedding-3-small", 3),
+	)
+	content := "Restart the API server with `make restart`.\n"
+	_, err := knowledgeUC.UploadKnowledgeDocument(ctx, &interfaces.UploadKnowledgeDocumentRequest{
+		AgentUUID:   agentObj.ID,
+		Name:        "runbook.md",
+		ContentType: "text/markdown",
+		FileReader:  strings.NewReader(content),
+		FileSize:    int64(len(content)),
+	})
+	gt.NoError(t, err)
+
+	var secondInput []gollem.Input
+	callCount := 0
+	mockSession := &MockSession{
+		generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+			callCount++
+			if callCount == 1 {
+				return &gollem.Response{
+					FunctionCalls: []*gollem.FunctionCall{
+						{
+							ID:        "call-1",
+							Name:      "search_knowledge",
+							Arguments: map[string]any{"query": "how to restart"},
+						},
+					},
+				}, nil
+			}
+
+			secondInput = input
+			return &gollem.Response{
+				Texts: []string{"Run `make restart` [runbook.md]."},
+			}, nil
+		},
+	}
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return mockSession, nil
+		},
+	}
+
+	mockSlackClient := &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithKnowledgeUseCases(knowledgeUC),
+		usecase.WithLLMClient(mockLLMClient),
+	)
+
+	ev := &slackevents.EventsAPIEvent{
+		TeamID: "T12345",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Data: &slackevents.AppMentionEvent{
+				User:      "U67890USER",
+				Text:      "<@U12345BOT> sre-helper how do I restart the API?",
+				TimeStamp: "1234567890.123456",
+				Channel:   "C11111",
+			},
+		},
+	}
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *slack.NewMessage(ctx, ev)))
+
+	// LLM is called twice: tool call and final answer
+	gt.Equal(t, callCount, 2)
+	gt.A(t, secondInput).Length(1)
+
+	funcResp, ok := secondInput[0].(gollem.FunctionResponse)
+	gt.True(t, ok)
+	gt.Equal(t, funcResp.Name, "search_knowledge")
+	gt.NoError(t, funcResp.Error)
+	gt.Equal(t, funcResp.Data["count"], 1)
+
+	passages := funcResp.Data["passages"].([]map[string]any)
+	gt.A(t, passages).Length(1)
+	gt.Equal(t, passages[0]["document"], "runbook.md")
+	gt.S(t, passages[0]["text"].(string)).Contains("make restart")
+}
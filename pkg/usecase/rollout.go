@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// Rollout holds dependencies for gradual rollouts of agent versions
+type Rollout struct {
+	agentRepo   interfaces.AgentRepository
+	metricsRepo interfaces.AgentMetricsRepository
+}
+
+// RolloutOption is a functional option for Rollout
+type RolloutOption func(*Rollout)
+
+// WithRolloutAgentRepository sets the agent repository
+func WithRolloutAgentRepository(repo interfaces.AgentRepository) RolloutOption {
+	return func(uc *Rollout) {
+		uc.agentRepo = repo
+	}
+}
+
+// WithRolloutMetricsRepository sets the repository of metrics of agent versions
+func WithRolloutMetricsRepository(repo interfaces.AgentMetricsRepository) RolloutOption {
+	return func(uc *Rollout) {
+		uc.metricsRepo = repo
+	}
+}
+
+// NewRollout creates a new Rollout instance
+func NewRollout(opts ...RolloutOption) *Rollout {
+	uc := &Rollout{}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Ensure Rollout implements interfaces.AgentRolloutUseCases
+var _ interfaces.AgentRolloutUseCases = (*Rollout)(nil)
+
+// StartRollout routes the percentage of new threads of the agent to the version. Drafts can not
+// be candidates because they are usable only by their authors; publish the draft first.
+func (uc *Rollout) StartRollout(ctx context.Context, agentUUID types.UUID, version string, percentage int) (*agent.Agent, error) {
+	agentObj, agentVersion, err := getReleasableAgentVersion(ctx, uc.agentRepo, agentUUID, version)
+	if err != nil {
+		return nil, err
+	}
+	if agentVersion.IsDraft() {
+		return nil, goerr.New("draft version can not be a rollout candidate, publish it first",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	if version == agentObj.Latest {
+		return nil, goerr.New("candidate version is already the latest version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	rollout := &agent.Rollout{
+		CandidateVersion: version,
+		Percentage:       percentage,
+		StartedAt:        time.Now(),
+	}
+	// Changing the percentage keeps the start of the rollout
+	if agentObj.Rollout != nil && agentObj.Rollout.CandidateVersion == version {
+		rollout.StartedAt = agentObj.Rollout.StartedAt
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, goerr.Wrap(err, "invalid rollout", goerr.T(apperr.ErrTagValidation))
+	}
+
+	agentObj.Rollout = rollout
+	if err := uc.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to save rollout", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+
+	ctxlog.From(ctx).Info("started rollout of agent version",
+		"agent_uuid", agentUUID,
+		"latest", agentObj.Latest,
+		"candidate", version,
+		"percentage", percentage,
+	)
+	return agentObj, nil
+}
+
+// PromoteRollout makes the candidate version the latest version of the agent
+func (uc *Rollout) PromoteRollout(ctx context.Context, agentUUID types.UUID) (*agent.Agent, error) {
+	agentObj, err := uc.getAgentInRollout(ctx, agentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate := agentObj.Rollout.CandidateVersion
+	candidateVersion, err := uc.agentRepo.GetAgentVersion(ctx, agentUUID, candidate)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get candidate version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", candidate))
+	}
+	// Candidates are published versions, but a version can be turned into a draft afterwards
+	if candidateVersion.IsDraft() {
+		return nil, goerr.New("draft version can not be promoted, publish it first",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", candidate),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	agentObj.Latest = candidate
+	agentObj.Rollout = nil
+	if err := uc.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to promote candidate version", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+
+	ctxlog.From(ctx).Info("promoted candidate version of agent",
+		"agent_uuid", agentUUID,
+		"version", candidate,
+	)
+	return agentObj, nil
+}
+
+// AbortRollout ends the rollout without changing the latest version of the agent
+func (uc *Rollout) AbortRollout(ctx context.Context, agentUUID types.UUID) (*agent.Agent, error) {
+	agentObj, err := uc.getAgentInRollout(ctx, agentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate := agentObj.Rollout.CandidateVersion
+	agentObj.Rollout = nil
+	if err := uc.agentRepo.UpdateAgent(ctx, agentObj); err != nil {
+		return nil, goerr.Wrap(err, "failed to abort rollout", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+
+	ctxlog.From(ctx).Info("aborted rollout of agent version",
+		"agent_uuid", agentUUID,
+		"candidate", candidate,
+	)
+	return agentObj, nil
+}
+
+// ListAgentVersionMetrics returns metrics of versions of the agent that served threads
+func (uc *Rollout) ListAgentVersionMetrics(ctx context.Context, agentUUID types.UUID) ([]*agent.VersionMetrics, error) {
+	if uc.metricsRepo == nil {
+		return nil, nil
+	}
+
+	metrics, err := uc.metricsRepo.ListAgentVersionMetrics(ctx, agentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list agent version metrics", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return metrics, nil
+}
+
+// DiffAgentVersions compares the system prompt and settings of two versions of the agent. The
+// changelog of the to version tells reviewers why it is changed.
+func (uc *Rollout) DiffAgentVersions(ctx context.Context, agentUUID types.UUID, from, to string) (*agent.VersionDiff, error) {
+	if err := verifyAgentToRun(ctx, uc.agentRepo, agentUUID, ""); err != nil {
+		return nil, err
+	}
+
+	versions := make([]*agent.AgentVersion, 0, 2)
+	for _, version := range []string{from, to} {
+		agentVersion, err := uc.agentRepo.GetAgentVersion(ctx, agentUUID, version)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get agent version",
+				goerr.TV(apperr.AgentUUIDKey, agentUUID),
+				goerr.V("version", version),
+				goerr.T(apperr.ErrTagValidation))
+		}
+		versions = append(versions, agentVersion)
+	}
+
+	return agent.DiffVersions(versions[0], versions[1]), nil
+}
+
+func (uc *Rollout) getAgentInRollout(ctx context.Context, agentUUID types.UUID) (*agent.Agent, error) {
+	agentObj, err := uc.agentRepo.GetAgent(ctx, agentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagAgentNotFound))
+	}
+	if agentObj.Rollout == nil {
+		return nil, goerr.New("agent has no rollout", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagValidation))
+	}
+	return agentObj, nil
+}
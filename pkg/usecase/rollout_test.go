@@ -0,0 +1,156 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+func TestRollout(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*usecase.Rollout, *memory.AgentMemoryClient, *agent.Agent) {
+		agentRepo := memory.NewAgentMemoryClient()
+		agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+		gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    agentObj.ID,
+			Version:      "1.1.0",
+			SystemPrompt: "You are a terse SRE helper.",
+		}))
+
+		uc := usecase.NewRollout(
+			usecase.WithRolloutAgentRepository(agentRepo),
+			usecase.WithRolloutMetricsRepository(memory.NewAgentMetricsRepository()),
+		)
+		return uc, agentRepo, agentObj
+	}
+
+	t.Run("start and promote", func(t *testing.T) {
+		uc, agentRepo, agentObj := setup(t)
+
+		started, err := uc.StartRollout(ctx, agentObj.ID, "1.1.0", 10)
+		gt.NoError(t, err)
+		gt.Equal(t, started.Latest, "1.0.0")
+		gt.Equal(t, started.Rollout.CandidateVersion, "1.1.0")
+		gt.Equal(t, started.Rollout.Percentage, 10)
+
+		// Changing the percentage keeps the start of the rollout
+		updated, err := uc.StartRollout(ctx, agentObj.ID, "1.1.0", 50)
+		gt.NoError(t, err)
+		gt.Equal(t, updated.Rollout.Percentage, 50)
+		gt.Equal(t, updated.Rollout.StartedAt, started.Rollout.StartedAt)
+
+		promoted, err := uc.PromoteRollout(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, promoted.Latest, "1.1.0")
+		gt.Nil(t, promoted.Rollout)
+
+		stored, err := agentRepo.GetAgent(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, stored.Latest, "1.1.0")
+		gt.Nil(t, stored.Rollout)
+	})
+
+	t.Run("abort keeps the latest version", func(t *testing.T) {
+		uc, agentRepo, agentObj := setup(t)
+
+		_, err := uc.StartRollout(ctx, agentObj.ID, "1.1.0", 10)
+		gt.NoError(t, err)
+
+		aborted, err := uc.AbortRollout(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, aborted.Latest, "1.0.0")
+		gt.Nil(t, aborted.Rollout)
+
+		stored, err := agentRepo.GetAgent(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.Nil(t, stored.Rollout)
+
+		_, err = uc.AbortRollout(ctx, agentObj.ID)
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+	})
+
+	t.Run("draft can not be a rollout candidate", func(t *testing.T) {
+		uc, agentRepo, agentObj := setup(t)
+		gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    agentObj.ID,
+			Version:      "1.2.0",
+			SystemPrompt: "You are a careful SRE helper.",
+			Status:       agent.VersionStatusDraft,
+			Author:       types.UserID("author-1"),
+		}))
+
+		_, err := uc.StartRollout(ctx, agentObj.ID, "1.2.0", 10)
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+
+		stored, err := agentRepo.GetAgent(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.Nil(t, stored.Rollout)
+
+		_, err = uc.PromoteRollout(ctx, agentObj.ID)
+		gt.Error(t, err)
+	})
+
+	t.Run("diff versions", func(t *testing.T) {
+		uc, agentRepo, agentObj := setup(t)
+		gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    agentObj.ID,
+			Version:      "1.2.0",
+			SystemPrompt: "You are a terse SRE helper.\nAnswer with commands.",
+			LLMModel:     "gpt-4.1",
+			Changelog:    "Ask for commands",
+		}))
+
+		diff, err := uc.DiffAgentVersions(ctx, agentObj.ID, "1.1.0", "1.2.0")
+		gt.NoError(t, err)
+		gt.Equal(t, diff.Changelog, "Ask for commands")
+		gt.Equal(t, diff.SystemPrompt, []agent.DiffLine{
+			{Op: agent.DiffOpEqual, Text: "You are a terse SRE helper."},
+			{Op: agent.DiffOpInsert, Text: "Answer with commands."},
+		})
+		gt.Equal(t, diff.Changes, []agent.FieldChange{
+			{Field: "llm_model", From: "", To: "gpt-4.1"},
+		})
+
+		_, err = uc.DiffAgentVersions(ctx, agentObj.ID, "1.1.0", "9.9.9")
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusBadRequest)
+
+		_, err = uc.DiffAgentVersions(ctx, types.NewUUID(ctx), "1.1.0", "1.2.0")
+		gt.Error(t, err)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusNotFound)
+	})
+
+	t.Run("invalid rollouts", func(t *testing.T) {
+		uc, _, agentObj := setup(t)
+
+		testCases := []struct {
+			name       string
+			agentUUID  types.UUID
+			version    string
+			percentage int
+			status     int
+		}{
+			{name: "latest version", agentUUID: agentObj.ID, version: "1.0.0", percentage: 10, status: http.StatusBadRequest},
+			{name: "unknown version", agentUUID: agentObj.ID, version: "9.9.9", percentage: 10, status: http.StatusBadRequest},
+			{name: "zero percent", agentUUID: agentObj.ID, version: "1.1.0", percentage: 0, status: http.StatusBadRequest},
+			{name: "unknown agent", agentUUID: types.NewUUID(ctx), version: "1.1.0", percentage: 10, status: http.StatusNotFound},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := uc.StartRollout(ctx, tc.agentUUID, tc.version, tc.percentage)
+				gt.Error(t, err)
+				gt.Equal(t, apperr.HTTPStatusFromError(err), tc.status)
+			})
+		}
+	})
+}
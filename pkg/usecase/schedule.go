@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	pkgErrors "github.com/m-mizutani/tamamo/pkg/utils/errors"
+)
+
+const (
+	// scheduleRunTimeout limits the time of a run of a schedule
+	scheduleRunTimeout = 10 * time.Minute
+
+	// scheduleRunLease is how long a running record keeps its tick. A run running longer was
+	// left by an instance that stopped during the run, and its tick is reclaimed.
+	scheduleRunLease = scheduleRunTimeout + 5*time.Minute
+
+	// missedScheduleGrace is how late a tick can still be run. Older ticks, e.g. missed while
+	// no instance was running, are recorded as skipped.
+	missedScheduleGrace = 10 * time.Minute
+
+	// defaultScheduleRunLimit is the number of runs listed if not specified
+	defaultScheduleRunLimit = 20
+
+	// maxScheduleRunLimit caps the number of runs listed
+	maxScheduleRunLimit = 100
+)
+
+// Schedule holds dependencies for scheduled runs of agents
+type Schedule struct {
+	repo      interfaces.ScheduleRepository
+	agentRepo interfaces.AgentRepository
+	runner    interfaces.AgentRunUseCases
+}
+
+// ScheduleOption is a functional option for Schedule
+type ScheduleOption func(*Schedule)
+
+// WithScheduleRepository sets the schedule repository
+func WithScheduleRepository(repo interfaces.ScheduleRepository) ScheduleOption {
+	return func(uc *Schedule) {
+		uc.repo = repo
+	}
+}
+
+// WithScheduleAgentRepository sets the agent repository
+func WithScheduleAgentRepository(repo interfaces.AgentRepository) ScheduleOption {
+	return func(uc *Schedule) {
+		uc.agentRepo = repo
+	}
+}
+
+// WithScheduleRunner sets the runner of agents
+func WithScheduleRunner(runner interfaces.AgentRunUseCases) ScheduleOption {
+	return func(uc *Schedule) {
+		uc.runner = runner
+	}
+}
+
+// NewSchedule creates a new Schedule instance
+func NewSchedule(opts ...ScheduleOption) *Schedule {
+	uc := &Schedule{}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Ensure Schedule implements interfaces.ScheduleUseCases
+var _ interfaces.ScheduleUseCases = (*Schedule)(nil)
+
+// CreateSchedule creates a schedule of the agent
+func (uc *Schedule) CreateSchedule(ctx context.Context, req *interfaces.CreateScheduleRequest) (*schedule.Schedule, error) {
+	if err := verifyPublishedAgentToRun(ctx, uc.agentRepo, req.AgentUUID, req.AgentVersion); err != nil {
+		return nil, err
+	}
+
+	s := schedule.NewSchedule(ctx, req.AgentUUID)
+	s.AgentVersion = req.AgentVersion
+	s.Cron = req.Cron
+	if req.TimeZone != "" {
+		s.TimeZone = req.TimeZone
+	}
+	s.ChannelID = req.ChannelID
+	s.Prompt = req.Prompt
+	s.Enabled = req.Enabled
+
+	if err := uc.putSchedule(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetSchedule returns the schedule
+func (uc *Schedule) GetSchedule(ctx context.Context, id types.UUID) (*schedule.Schedule, error) {
+	s, err := uc.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get schedule", goerr.V("schedule_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+	return s, nil
+}
+
+// UpdateSchedule updates the schedule. The next tick is calculated again from now.
+func (uc *Schedule) UpdateSchedule(ctx context.Context, id types.UUID, req *interfaces.UpdateScheduleRequest) (*schedule.Schedule, error) {
+	s, err := uc.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AgentVersion != nil {
+		if err := verifyPublishedAgentToRun(ctx, uc.agentRepo, s.AgentUUID, *req.AgentVersion); err != nil {
+			return nil, err
+		}
+		s.AgentVersion = *req.AgentVersion
+	}
+	if req.Cron != nil {
+		s.Cron = *req.Cron
+	}
+	if req.TimeZone != nil {
+		s.TimeZone = *req.TimeZone
+		if s.TimeZone == "" {
+			s.TimeZone = schedule.DefaultTimeZone
+		}
+	}
+	if req.ChannelID != nil {
+		s.ChannelID = *req.ChannelID
+	}
+	if req.Prompt != nil {
+		s.Prompt = *req.Prompt
+	}
+	if req.Enabled != nil {
+		s.Enabled = *req.Enabled
+	}
+	s.UpdatedAt = time.Now()
+
+	if err := uc.putSchedule(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DeleteSchedule deletes the schedule and its runs
+func (uc *Schedule) DeleteSchedule(ctx context.Context, id types.UUID) error {
+	if err := uc.repo.DeleteSchedule(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to delete schedule", goerr.V("schedule_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+	return nil
+}
+
+// ListSchedules lists schedules of the agent, or all schedules if agentUUID is nil
+func (uc *Schedule) ListSchedules(ctx context.Context, agentUUID *types.UUID) ([]*schedule.Schedule, error) {
+	schedules, err := uc.repo.ListSchedules(ctx)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list schedules")
+	}
+	if agentUUID == nil {
+		return schedules, nil
+	}
+
+	var result []*schedule.Schedule
+	for _, s := range schedules {
+		if s.AgentUUID == *agentUUID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// ListScheduleRuns lists the latest runs of the schedule, newest first
+func (uc *Schedule) ListScheduleRuns(ctx context.Context, scheduleID types.UUID, limit int) ([]*schedule.Run, error) {
+	if limit <= 0 {
+		limit = defaultScheduleRunLimit
+	}
+	limit = min(limit, maxScheduleRunLimit)
+
+	runs, err := uc.repo.ListScheduleRuns(ctx, scheduleID, limit)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list schedule runs", goerr.V("schedule_id", scheduleID))
+	}
+	return runs, nil
+}
+
+// RunDueSchedules runs enabled schedules whose next tick has come, concurrently, and waits for
+// them. Each tick is taken by creating its run record, so that only one instance runs it even if
+// several instances call this at the same time.
+func (uc *Schedule) RunDueSchedules(ctx context.Context) error {
+	if uc.runner == nil {
+		return goerr.New("agent runner is not configured")
+	}
+
+	schedules, err := uc.repo.ListSchedules(ctx)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list schedules to run")
+	}
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	for _, s := range schedules {
+		if !s.Enabled || s.NextRunAt.IsZero() || s.NextRunAt.After(now) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *schedule.Schedule) {
+			defer wg.Done()
+			if err := uc.runSchedule(ctx, s, now); err != nil {
+				pkgErrors.Handle(ctx, err)
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// runSchedule runs the schedule for its next tick and records the result
+func (uc *Schedule) runSchedule(ctx context.Context, s *schedule.Schedule, now time.Time) error {
+	logger := ctxlog.From(ctx)
+
+	tick := s.NextRunAt
+	run := schedule.NewRun(s.ID, tick)
+	if err := uc.repo.CreateScheduleRun(ctx, run); err != nil {
+		if errors.Is(err, schedule.ErrRunAlreadyExists) {
+			return uc.recoverTakenTick(ctx, s, tick, now)
+		}
+		return goerr.Wrap(err, "failed to create schedule run", goerr.V("schedule_id", s.ID))
+	}
+
+	if now.Sub(tick) > missedScheduleGrace {
+		logger.Warn("skipped missed tick of schedule",
+			"schedule_id", s.ID,
+			"scheduled_at", tick,
+		)
+		run.Error = "missed tick is skipped"
+		run.Finish(schedule.RunStatusSkipped, nil)
+	} else {
+		runCtx, cancel := context.WithTimeout(ctx, scheduleRunTimeout)
+		result, err := uc.runner.RunAgent(runCtx, &interfaces.AgentRunRequest{
+			AgentUUID:    s.AgentUUID,
+			AgentVersion: s.AgentVersion,
+			ChannelID:    s.ChannelID,
+			Prompt:       s.Prompt,
+		})
+		cancel()
+
+		if result != nil {
+			run.AgentVersion = result.AgentVersion
+			run.ThreadTS = result.ThreadTS
+		}
+		run.Finish(schedule.RunStatusSucceeded, err)
+		if err != nil {
+			pkgErrors.Handle(ctx, goerr.Wrap(err, "scheduled run failed",
+				goerr.V("schedule_id", s.ID),
+				goerr.V("scheduled_at", tick)))
+		}
+	}
+
+	// The result is saved even if ctx is canceled during the run, e.g. on shutdown, so that the
+	// tick is not left running
+	ctx = context.WithoutCancel(ctx)
+	if err := uc.repo.PutScheduleRun(ctx, run); err != nil {
+		return goerr.Wrap(err, "failed to save schedule run", goerr.V("run_id", run.ID))
+	}
+
+	next, err := uc.advanceSchedule(ctx, s, run)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("ran schedule",
+		"schedule_id", s.ID,
+		"agent_uuid", s.AgentUUID,
+		"scheduled_at", tick,
+		"status", run.Status,
+		"thread_ts", run.ThreadTS,
+		"next_run_at", next,
+	)
+	return nil
+}
+
+// recoverTakenTick handles the tick of the schedule whose run record already exists. The schedule
+// is advanced if the run has finished but the instance stopped before advancing it, or if the run
+// has been running longer than the lease because the instance stopped during the run. Otherwise
+// another instance is running the tick.
+func (uc *Schedule) recoverTakenTick(ctx context.Context, s *schedule.Schedule, tick, now time.Time) error {
+	logger := ctxlog.From(ctx)
+
+	run, err := uc.repo.GetScheduleRun(ctx, s.ID, schedule.RunID(s.ID, tick))
+	if err != nil {
+		return goerr.Wrap(err, "failed to get schedule run of taken tick",
+			goerr.V("schedule_id", s.ID),
+			goerr.V("scheduled_at", tick))
+	}
+
+	switch {
+	case run.Status != schedule.RunStatusRunning:
+		logger.Info("advancing schedule of finished tick",
+			"schedule_id", s.ID,
+			"scheduled_at", tick,
+			"status", run.Status,
+		)
+
+	case now.Sub(run.StartedAt) > scheduleRunLease:
+		logger.Warn("reclaiming stale run of schedule",
+			"schedule_id", s.ID,
+			"scheduled_at", tick,
+			"started_at", run.StartedAt,
+		)
+		run.Finish(schedule.RunStatusFailed, goerr.New("run was abandoned by a stopped instance"))
+		if err := uc.repo.PutScheduleRun(ctx, run); err != nil {
+			return goerr.Wrap(err, "failed to save stale schedule run", goerr.V("run_id", run.ID))
+		}
+
+	default:
+		logger.Debug("tick of schedule is taken by another instance",
+			"schedule_id", s.ID,
+			"scheduled_at", tick,
+		)
+		return nil
+	}
+
+	_, err = uc.advanceSchedule(ctx, s, run)
+	return err
+}
+
+// advanceSchedule records the result of the run to the schedule and moves it to the next tick.
+// The next tick is calculated from now, so that ticks missed during the run are not run.
+func (uc *Schedule) advanceSchedule(ctx context.Context, s *schedule.Schedule, run *schedule.Run) (time.Time, error) {
+	next, err := s.Next(time.Now())
+	if err != nil {
+		return time.Time{}, goerr.Wrap(err, "failed to calculate next tick of schedule", goerr.V("schedule_id", s.ID))
+	}
+	if err := uc.repo.UpdateScheduleState(ctx, s.ID, run.StartedAt, run.Error, next); err != nil {
+		return time.Time{}, goerr.Wrap(err, "failed to update schedule state", goerr.V("schedule_id", s.ID))
+	}
+	return next, nil
+}
+
+// putSchedule validates the schedule, calculates its next tick and saves it
+func (uc *Schedule) putSchedule(ctx context.Context, s *schedule.Schedule) error {
+	if err := s.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid schedule", goerr.T(apperr.ErrTagValidation))
+	}
+
+	s.NextRunAt = time.Time{}
+	if s.Enabled {
+		next, err := s.Next(time.Now())
+		if err != nil {
+			return goerr.Wrap(err, "invalid schedule", goerr.T(apperr.ErrTagValidation))
+		}
+		s.NextRunAt = next
+	}
+
+	if err := uc.repo.PutSchedule(ctx, s); err != nil {
+		return goerr.Wrap(err, "failed to save schedule", goerr.V("schedule_id", s.ID))
+	}
+	return nil
+}
+
+// verifyPublishedAgentToRun verifies the agent as verifyAgentToRun does, and that the version is
+// not a draft because drafts are usable only by their authors
+func verifyPublishedAgentToRun(ctx context.Context, agentRepo interfaces.AgentRepository, agentUUID types.UUID, version string) error {
+	if err := verifyAgentToRun(ctx, agentRepo, agentUUID, ""); err != nil {
+		return err
+	}
+	if version == "" {
+		return nil
+	}
+	agentVersion, err := agentRepo.GetAgentVersion(ctx, agentUUID, version)
+	if err != nil {
+		return goerr.Wrap(err, "failed to verify agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	if agentVersion.IsDraft() {
+		return goerr.New("draft version can not be run in channels",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	return nil
+}
+
+// verifyAgentToRun verifies that the agent exists, and its version if version is not empty
+func verifyAgentToRun(ctx context.Context, agentRepo interfaces.AgentRepository, agentUUID types.UUID, version string) error {
+	if !agentUUID.IsValid() {
+		return goerr.New("invalid agent UUID", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagValidation))
+	}
+	if _, err := agentRepo.GetAgent(ctx, agentUUID); err != nil {
+		return goerr.Wrap(err, "failed to verify agent", goerr.TV(apperr.AgentUUIDKey, agentUUID), goerr.T(apperr.ErrTagAgentNotFound))
+	}
+	if version == "" {
+		return nil
+	}
+	if _, err := agentRepo.GetAgentVersion(ctx, agentUUID, version); err != nil {
+		return goerr.Wrap(err, "failed to verify agent version",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+	return nil
+}
@@ -0,0 +1,396 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/schedule"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+// fakeAgentRunner records requests to run agents
+type fakeAgentRunner struct {
+	mu       sync.Mutex
+	requests []*interfaces.AgentRunRequest
+	err      error
+}
+
+func (x *fakeAgentRunner) RunAgent(ctx context.Context, req *interfaces.AgentRunRequest) (*interfaces.AgentRunResult, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.requests = append(x.requests, req)
+	if x.err != nil {
+		return nil, x.err
+	}
+	return &interfaces.AgentRunResult{
+		AgentVersion: "1.0.0",
+		ThreadTS:     "1700000000.000100",
+		Response:     "Daily summary",
+	}, nil
+}
+
+func (x *fakeAgentRunner) count() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return len(x.requests)
+}
+
+func TestScheduleCRUD(t *testing.T) {
+	ctx := context.Background()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	uc := usecase.NewSchedule(
+		usecase.WithScheduleRepository(memory.NewScheduleRepository()),
+		usecase.WithScheduleAgentRepository(agentRepo),
+	)
+
+	newReq := func() *interfaces.CreateScheduleRequest {
+		return &interfaces.CreateScheduleRequest{
+			AgentUUID: agentObj.ID,
+			Cron:      "0 9 * * 1-5",
+			TimeZone:  "Asia/Tokyo",
+			ChannelID: "C12345",
+			Prompt:    "Summarize open incidents",
+			Enabled:   true,
+		}
+	}
+
+	s, err := uc.CreateSchedule(ctx, newReq())
+	gt.NoError(t, err)
+	gt.True(t, s.NextRunAt.After(time.Now()))
+	gt.Equal(t, s.AgentVersion, "")
+
+	t.Run("invalid requests are rejected", func(t *testing.T) {
+		badCron := newReq()
+		badCron.Cron = "61 * * * *"
+		_, err := uc.CreateSchedule(ctx, badCron)
+		gt.Error(t, err)
+
+		badTZ := newReq()
+		badTZ.TimeZone = "Mars/Olympus"
+		_, err = uc.CreateSchedule(ctx, badTZ)
+		gt.Error(t, err)
+
+		unknownAgent := newReq()
+		unknownAgent.AgentUUID = types.NewUUID(ctx)
+		_, err = uc.CreateSchedule(ctx, unknownAgent)
+		gt.Error(t, err)
+
+		unknownVersion := newReq()
+		unknownVersion.AgentVersion = "9.9.9"
+		_, err = uc.CreateSchedule(ctx, unknownVersion)
+		gt.Error(t, err)
+	})
+
+	t.Run("disabling clears next run", func(t *testing.T) {
+		disabled := false
+		updated, err := uc.UpdateSchedule(ctx, s.ID, &interfaces.UpdateScheduleRequest{Enabled: &disabled})
+		gt.NoError(t, err)
+		gt.False(t, updated.Enabled)
+		gt.True(t, updated.NextRunAt.IsZero())
+		gt.Equal(t, updated.Cron, "0 9 * * 1-5")
+
+		enabled := true
+		version := "1.0.0"
+		updated, err = uc.UpdateSchedule(ctx, s.ID, &interfaces.UpdateScheduleRequest{Enabled: &enabled, AgentVersion: &version})
+		gt.NoError(t, err)
+		gt.False(t, updated.NextRunAt.IsZero())
+		gt.Equal(t, updated.AgentVersion, "1.0.0")
+	})
+
+	t.Run("list by agent", func(t *testing.T) {
+		other := types.NewUUID(ctx)
+		schedules, err := uc.ListSchedules(ctx, &other)
+		gt.NoError(t, err)
+		gt.A(t, schedules).Length(0)
+
+		schedules, err = uc.ListSchedules(ctx, &agentObj.ID)
+		gt.NoError(t, err)
+		gt.A(t, schedules).Length(1)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		gt.NoError(t, uc.DeleteSchedule(ctx, s.ID))
+		_, err := uc.GetSchedule(ctx, s.ID)
+		gt.Error(t, err)
+	})
+}
+
+func TestRunDueSchedules(t *testing.T) {
+	ctx := context.Background()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	setup := func(t *testing.T, runner *fakeAgentRunner) (*usecase.Schedule, interfaces.ScheduleRepository, *schedule.Schedule) {
+		repo := memory.NewScheduleRepository()
+		uc := usecase.NewSchedule(
+			usecase.WithScheduleRepository(repo),
+			usecase.WithScheduleAgentRepository(agentRepo),
+			usecase.WithScheduleRunner(runner),
+		)
+		s, err := uc.CreateSchedule(ctx, &interfaces.CreateScheduleRequest{
+			AgentUUID: agentObj.ID,
+			Cron:      "*/5 * * * *",
+			ChannelID: "C12345",
+			Prompt:    "Summarize open incidents",
+			Enabled:   true,
+		})
+		gt.NoError(t, err)
+		return uc, repo, s
+	}
+
+	t.Run("due schedule runs once per tick", func(t *testing.T) {
+		runner := &fakeAgentRunner{}
+		uc, repo, s := setup(t, runner)
+
+		// The tick has come a minute ago
+		tick := time.Now().Add(-time.Minute).Truncate(time.Minute)
+		gt.NoError(t, repo.UpdateScheduleState(ctx, s.ID, time.Time{}, "", tick))
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				gt.NoError(t, uc.RunDueSchedules(ctx))
+			}()
+		}
+		wg.Wait()
+		gt.Equal(t, runner.count(), 1)
+		gt.Equal(t, runner.requests[0].ChannelID, "C12345")
+		gt.Equal(t, runner.requests[0].Prompt, "Summarize open incidents")
+
+		runs, err := uc.ListScheduleRuns(ctx, s.ID, 0)
+		gt.NoError(t, err)
+		gt.A(t, runs).Length(1)
+		gt.Equal(t, runs[0].Status, schedule.RunStatusSucceeded)
+		gt.Equal(t, runs[0].ThreadTS, "1700000000.000100")
+		gt.Equal(t, runs[0].AgentVersion, "1.0.0")
+		gt.True(t, runs[0].ScheduledAt.Equal(tick))
+
+		updated, err := uc.GetSchedule(ctx, s.ID)
+		gt.NoError(t, err)
+		gt.NotNil(t, updated.LastRunAt)
+		gt.Equal(t, updated.LastError, "")
+		gt.True(t, updated.NextRunAt.After(time.Now()))
+
+		// The next tick is not due yet
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+		gt.Equal(t, runner.count(), 1)
+	})
+
+	t.Run("tick taken by another instance is not run", func(t *testing.T) {
+		runner := &fakeAgentRunner{}
+		uc, repo, s := setup(t, runner)
+
+		tick := time.Now().Add(-time.Minute).Truncate(time.Minute)
+		gt.NoError(t, repo.UpdateScheduleState(ctx, s.ID, time.Time{}, "", tick))
+		gt.NoError(t, repo.CreateScheduleRun(ctx, schedule.NewRun(s.ID, tick)))
+
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+		gt.Equal(t, runner.count(), 0)
+
+		// The instance running the tick advances the schedule
+		updated, err := uc.GetSchedule(ctx, s.ID)
+		gt.NoError(t, err)
+		gt.True(t, updated.NextRunAt.Equal(tick))
+	})
+
+	t.Run("schedule of finished tick is advanced", func(t *testing.T) {
+		runner := &fakeAgentRunner{}
+		uc, repo, s := setup(t, runner)
+
+		// The instance stopped after the run before advancing the schedule
+		tick := time.Now().Add(-time.Minute).Truncate(time.Minute)
+		gt.NoError(t, repo.UpdateScheduleState(ctx, s.ID, time.Time{}, "", tick))
+		run := schedule.NewRun(s.ID, tick)
+		gt.NoError(t, repo.CreateScheduleRun(ctx, run))
+		run.Finish(schedule.RunStatusSucceeded, nil)
+		gt.NoError(t, repo.PutScheduleRun(ctx, run))
+
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+		gt.Equal(t, runner.count(), 0)
+
+		updated, err := uc.GetSchedule(ctx, s.ID)
+		gt.NoError(t, err)
+		gt.True(t, updated.NextRunAt.After(time.Now()))
+	})
+
+	t.Run("stale running tick is reclaimed", func(t *testing.T) {
+		runner := &fakeAgentRunner{}
+		uc, repo, s := setup(t, runner)
+
+		// The instance stopped during the run
+		tick := time.Now().Add(-30 * time.Minute).Truncate(time.Minute)
+		gt.NoError(t, repo.UpdateScheduleState(ctx, s.ID, time.Time{}, "", tick))
+		run := schedule.NewRun(s.ID, tick)
+		run.StartedAt = tick
+		gt.NoError(t, repo.CreateScheduleRun(ctx, run))
+
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+		gt.Equal(t, runner.count(), 0)
+
+		reclaimed, err := repo.GetScheduleRun(ctx, s.ID, run.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, reclaimed.Status, schedule.RunStatusFailed)
+		gt.NotNil(t, reclaimed.FinishedAt)
+
+		updated, err := uc.GetSchedule(ctx, s.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, updated.LastError, reclaimed.Error)
+		gt.True(t, updated.NextRunAt.After(time.Now()))
+	})
+
+	t.Run("failed run is recorded", func(t *testing.T) {
+		runner := &fakeAgentRunner{err: errors.New("agent is archived")}
+		uc, repo, s := setup(t, runner)
+
+		tick := time.Now().Add(-time.Minute).Truncate(time.Minute)
+		gt.NoError(t, repo.UpdateScheduleState(ctx, s.ID, time.Time{}, "", tick))
+
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+
+		runs, err := uc.ListScheduleRuns(ctx, s.ID, 0)
+		gt.NoError(t, err)
+		gt.A(t, runs).Length(1)
+		gt.Equal(t, runs[0].Status, schedule.RunStatusFailed)
+		gt.Equal(t, runs[0].Error, "agent is archived")
+
+		updated, err := uc.GetSchedule(ctx, s.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, updated.LastError, "agent is archived")
+		gt.True(t, updated.NextRunAt.After(time.Now()))
+	})
+
+	t.Run("missed tick is skipped", func(t *testing.T) {
+		runner := &fakeAgentRunner{}
+		uc, repo, s := setup(t, runner)
+
+		tick := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+		gt.NoError(t, repo.UpdateScheduleState(ctx, s.ID, time.Time{}, "", tick))
+
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+		gt.Equal(t, runner.count(), 0)
+
+		runs, err := uc.ListScheduleRuns(ctx, s.ID, 0)
+		gt.NoError(t, err)
+		gt.A(t, runs).Length(1)
+		gt.Equal(t, runs[0].Status, schedule.RunStatusSkipped)
+	})
+
+	t.Run("disabled schedule does not run", func(t *testing.T) {
+		runner := &fakeAgentRunner{}
+		uc, repo, s := setup(t, runner)
+
+		disabled := false
+		_, err := uc.UpdateSchedule(ctx, s.ID, &interfaces.UpdateScheduleRequest{Enabled: &disabled})
+		gt.NoError(t, err)
+
+		gt.NoError(t, uc.RunDueSchedules(ctx))
+		gt.Equal(t, runner.count(), 0)
+
+		runs, err := repo.ListScheduleRuns(ctx, s.ID, 10)
+		gt.NoError(t, err)
+		gt.A(t, runs).Length(0)
+	})
+}
+
+func TestRunAgent(t *testing.T) {
+	ctx := context.Background()
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	var prompt string
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+					if text, ok := input[0].(gollem.Text); ok {
+						prompt = string(text)
+					}
+					return &gollem.Response{Texts: []string{"No open incidents."}}, nil
+				},
+			}, nil
+		},
+	}
+
+	var postedChannel, postedThread, postedText string
+	var postedOptions *interfaces.SlackMessageOptions
+	mockSlackClient := &mock.SlackClientMock{
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			postedChannel, postedThread, postedText, postedOptions = channelID, threadTS, text, options
+			return "1700000000.000100", nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(mockLLMClient),
+		usecase.WithSlackTeamID("T12345"),
+	)
+
+	result, err := uc.RunAgent(ctx, &interfaces.AgentRunRequest{
+		AgentUUID: agentObj.ID,
+		ChannelID: "C12345",
+		Prompt:    "Summarize open incidents",
+	})
+	gt.NoError(t, err)
+	gt.Equal(t, result.AgentVersion, "1.0.0")
+	gt.Equal(t, result.ThreadTS, "1700000000.000100")
+	gt.Equal(t, prompt, "Summarize open incidents")
+
+	// The response starts a new thread in the channel as the agent
+	gt.Equal(t, postedChannel, "C12345")
+	gt.Equal(t, postedThread, "")
+	gt.Equal(t, postedText, "No open incidents.")
+	gt.NotNil(t, postedOptions)
+	gt.Equal(t, postedOptions.Username, "SRE Helper")
+
+	// The thread is bound to the agent, so that mentions in it continue the conversation
+	thread, err := repo.GetThreadByTS(ctx, "C12345", "1700000000.000100")
+	gt.NoError(t, err)
+	gt.NotNil(t, thread.AgentUUID)
+	gt.Equal(t, *thread.AgentUUID, agentObj.ID)
+	gt.Equal(t, thread.AgentVersion, "1.0.0")
+	gt.Equal(t, thread.TeamID, "T12345")
+
+	t.Run("reply to the thread", func(t *testing.T) {
+		result, err := uc.RunAgent(ctx, &interfaces.AgentRunRequest{
+			AgentUUID: agentObj.ID,
+			ChannelID: "C12345",
+			ThreadTS:  "1690000000.000001",
+			Prompt:    "Investigate the alert",
+		})
+		gt.NoError(t, err)
+		gt.Equal(t, postedThread, "1690000000.000001")
+		gt.Equal(t, result.ThreadTS, "1690000000.000001")
+	})
+
+	t.Run("unknown version is rejected", func(t *testing.T) {
+		_, err := uc.RunAgent(ctx, &interfaces.AgentRunRequest{
+			AgentUUID:    agentObj.ID,
+			AgentVersion: "9.9.9",
+			ChannelID:    "C12345",
+			Prompt:       "Summarize open incidents",
+		})
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+)
+
+const (
+	// defaultRoutingThreshold is used when the routing threshold is not configured
+	defaultRoutingThreshold = 0.7
+
+	// maxRoutingCandidates limits the number of agents presented to the router
+	maxRoutingCandidates = 100
+)
+
+// routingDecision is the classification result returned by the router LLM
+type routingDecision struct {
+	AgentID    string  `json:"agent_id"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// routeAgentMention chooses an agent for a mention that does not specify an agent ID. It returns
+// the agent mention to process, which has the chosen agent ID if the request is routed. General
+// mode is kept if no agent matches the request with enough confidence.
+func (uc *Slack) routeAgentMention(ctx context.Context, slackMsg slack.Message, mention *slack.Mention, agentMention *slack.AgentMention) *slack.AgentMention {
+	logger := ctxlog.From(ctx)
+
+	if agentMention == nil || uc.agentRepository == nil {
+		return agentMention
+	}
+
+	if agentMention.AgentID != "" {
+		if _, err := uc.agentRepository.GetAgentByAgentIDActive(ctx, agentMention.AgentID); err == nil {
+			return agentMention
+		}
+		// First word is not an agent ID, so the whole message is the request
+		agentMention = &slack.AgentMention{
+			UserID:  mention.UserID,
+			Message: mention.Message,
+		}
+	}
+
+	if strings.TrimSpace(agentMention.Message) == "" {
+		return agentMention
+	}
+
+	candidates, _, err := uc.agentRepository.ListActiveAgents(ctx, 0, maxRoutingCandidates)
+	if err != nil {
+		logger.Warn("failed to list agents for routing, keeping general mode", "error", err)
+		return agentMention
+	}
+	if len(candidates) == 0 {
+		return agentMention
+	}
+
+	decision, err := uc.classifyRequest(ctx, agentMention.Message, candidates)
+	if err != nil {
+		logger.Warn("failed to route request, keeping general mode",
+			"error", err,
+			"channel", slackMsg.Channel,
+			"thread", slackMsg.GetThreadTS(),
+		)
+		return agentMention
+	}
+
+	threshold := uc.routingThreshold
+	if threshold <= 0 {
+		threshold = defaultRoutingThreshold
+	}
+
+	var chosen *agent.Agent
+	for _, candidate := range candidates {
+		if candidate.AgentID == decision.AgentID {
+			chosen = candidate
+			break
+		}
+	}
+	routed := chosen != nil && decision.Confidence >= threshold
+
+	// Decisions are logged to tune agent descriptions and the threshold. The message itself is
+	// not logged because it may contain confidential information.
+	logger.Debug("agent routing decision",
+		"channel", slackMsg.Channel,
+		"thread", slackMsg.GetThreadTS(),
+		"user", slackMsg.UserID,
+		"message_length", len(agentMention.Message),
+		"agent_id", decision.AgentID,
+		"confidence", decision.Confidence,
+		"threshold", threshold,
+		"reason", decision.Reason,
+		"candidates", len(candidates),
+		"routed", routed,
+	)
+
+	if !routed {
+		return agentMention
+	}
+
+	notice := fmt.Sprintf(":compass: Routed to *%s* (`%s`). Mention me with another agent ID to switch the agent.", chosen.Name, chosen.AgentID)
+	if err := uc.slackClient.PostMessage(ctx, slackMsg.Channel, slackMsg.GetThreadTS(), notice); err != nil {
+		logger.Warn("failed to post routing notice",
+			"error", err,
+			"channel", slackMsg.Channel,
+		)
+	}
+
+	return &slack.AgentMention{
+		UserID:  agentMention.UserID,
+		AgentID: chosen.AgentID,
+		Message: agentMention.Message,
+	}
+}
+
+// classifyRequest asks the router LLM which agent matches the request best
+func (uc *Slack) classifyRequest(ctx context.Context, message string, candidates []*agent.Agent) (*routingDecision, error) {
+	session, err := uc.routerClient.NewSession(ctx, gollem.WithSessionSystemPrompt(buildRoutingPrompt(candidates)))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create router session")
+	}
+
+	resp, err := session.GenerateContent(ctx, gollem.Text(message))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to generate routing decision")
+	}
+
+	return parseRoutingDecision(strings.Join(resp.Texts, ""))
+}
+
+// buildRoutingPrompt builds the system prompt to classify a request against the agents
+func buildRoutingPrompt(candidates []*agent.Agent) string {
+	var b strings.Builder
+	b.WriteString("You route requests posted in Slack to the most suitable assistant agent. ")
+	b.WriteString("Choose the agent whose name and description best match the request.\n\n")
+	b.WriteString("Agents:\n")
+	for _, candidate := range candidates {
+		fmt.Fprintf(&b, "- agent_id: %s\n  name: %s\n  description: %s\n", candidate.AgentID, candidate.Name, candidate.Description)
+	}
+	b.WriteString("\nRespond with only a JSON object in the following format:\n")
+	b.WriteString(`{"agent_id": "<agent_id of the chosen agent, or empty if none matches>", "confidence": <number from 0.0 to 1.0>, "reason": "<short reason>"}`)
+	return b.String()
+}
+
+// parseRoutingDecision extracts the routing decision from the LLM response. The JSON object may be
+// surrounded by a code block or explanation.
+func parseRoutingDecision(text string) (*routingDecision, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, goerr.New("routing decision is not JSON", goerr.V("response", text))
+	}
+
+	var decision routingDecision
+	if err := json.Unmarshal([]byte(text[start:end+1]), &decision); err != nil {
+		return nil, goerr.Wrap(err, "failed to parse routing decision", goerr.V("response", text))
+	}
+	return &decision, nil
+}
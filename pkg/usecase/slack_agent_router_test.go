@@ -0,0 +1,158 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+type agentRouterFixture struct {
+	repo         *memory.Client
+	dbHelper     *agent.Agent
+	slackClient  *mock.SlackClientMock
+	routerInputs [][]gollem.Input
+	agentInputs  [][]gollem.Input
+	uc           *usecase.Slack
+}
+
+func newAgentRouterFixture(t *testing.T, decision string) *agentRouterFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	f := &agentRouterFixture{repo: memory.New()}
+	agentRepo := memory.NewAgentMemoryClient()
+
+	f.dbHelper = &agent.Agent{
+		ID:          types.NewUUID(ctx),
+		AgentID:     "db-helper",
+		Name:        "DB Helper",
+		Description: "Investigates database performance issues",
+		Status:      agent.StatusActive,
+		Latest:      "2.0.0",
+	}
+	gt.NoError(t, agentRepo.CreateAgent(ctx, f.dbHelper))
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    f.dbHelper.ID,
+		Version:      "2.0.0",
+		SystemPrompt: "You are a DB helper.",
+	}))
+
+	f.slackClient = &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+	}
+
+	routerClient := &llm_mock.LLMClientMock{
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+					f.routerInputs = append(f.routerInputs, input)
+					return &gollem.Response{Texts: []string{decision}}, nil
+				},
+			}, nil
+		},
+	}
+
+	agentClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+					f.agentInputs = append(f.agentInputs, input)
+					return &gollem.Response{Texts: []string{"Let me check."}}, nil
+				},
+			}, nil
+		},
+	}
+
+	f.uc = usecase.New(
+		usecase.WithSlackClient(f.slackClient),
+		usecase.WithRepository(f.repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(agentClient),
+		usecase.WithAgentRouter(routerClient, 0.7),
+	)
+
+	return f
+}
+
+func TestHandleSlackAppMentionRouteAgent(t *testing.T) {
+	ctx := context.Background()
+	threadTS := "1234567890.200000"
+
+	t.Run("confident decision routes to the agent", func(t *testing.T) {
+		f := newAgentRouterFixture(t, "```json\n{\"agent_id\": \"db-helper\", \"confidence\": 0.9, \"reason\": \"database\"}\n```")
+
+		msg := newThreadMention(ctx, "<@U12345BOT> please check the slow queries", "")
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		// Router receives the whole message as the request
+		gt.A(t, f.routerInputs).Length(1)
+		gt.Equal(t, f.routerInputs[0][0].(gollem.Text), gollem.Text("please check the slow queries"))
+
+		// Chosen agent is announced and answers the request
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+		gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("Routed to *DB Helper*")
+		gt.A(t, f.agentInputs).Length(1)
+		gt.Equal(t, f.agentInputs[0][0].(gollem.Text), gollem.Text("please check the slow queries"))
+
+		thread, err := f.repo.GetThreadByTS(ctx, "C11111", threadTS)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, f.dbHelper.ID)
+		gt.Equal(t, thread.AgentVersion, "2.0.0")
+	})
+
+	t.Run("low confidence keeps general mode", func(t *testing.T) {
+		f := newAgentRouterFixture(t, `{"agent_id": "db-helper", "confidence": 0.4, "reason": "unclear"}`)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> what can you do?", "")
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.routerInputs).Length(1)
+		thread, err := f.repo.GetThreadByTS(ctx, "C11111", threadTS)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, types.UUID("00000000-0000-0000-0000-000000000000"))
+		gt.Equal(t, f.agentInputs[0][0].(gollem.Text), gollem.Text("what can you do?"))
+	})
+
+	t.Run("unknown agent in decision keeps general mode", func(t *testing.T) {
+		f := newAgentRouterFixture(t, `{"agent_id": "no-such-agent", "confidence": 0.95, "reason": "made up"}`)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> please check the slow queries", "")
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		thread, err := f.repo.GetThreadByTS(ctx, "C11111", threadTS)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, types.UUID("00000000-0000-0000-0000-000000000000"))
+	})
+
+	t.Run("agent ID in the mention skips routing", func(t *testing.T) {
+		f := newAgentRouterFixture(t, `{"agent_id": "", "confidence": 0, "reason": ""}`)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> db-helper check the slow queries", "")
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.routerInputs).Length(0)
+		thread, err := f.repo.GetThreadByTS(ctx, "C11111", threadTS)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, f.dbHelper.ID)
+	})
+}
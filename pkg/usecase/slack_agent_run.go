@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	agentmodel "github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// Ensure Slack implements interfaces.AgentRunUseCases
+var _ interfaces.AgentRunUseCases = (*Slack)(nil)
+
+// RunAgent runs the agent with the prompt through the same session as mentions, and posts the
+// response to the channel as a new thread, or to the thread of req.ThreadTS. A new thread is bound
+// to the agent, so that mentions in the thread continue the conversation with it.
+func (uc *Slack) RunAgent(ctx context.Context, req *interfaces.AgentRunRequest) (*interfaces.AgentRunResult, error) {
+	logger := ctxlog.From(ctx)
+
+	if uc.slackClient == nil {
+		return nil, goerr.New("slack client not configured")
+	}
+	if req.ChannelID == "" || req.Prompt == "" {
+		return nil, goerr.New("channel ID and prompt are required to run agent", goerr.T(apperr.ErrTagValidation))
+	}
+
+	agent, err := uc.resolveAgentToRun(ctx, req.AgentUUID, req.AgentVersion)
+	if err != nil {
+		return nil, err
+	}
+	if agent.draftAuthor != "" {
+		return nil, goerr.New("draft version can not be run in channels",
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+			goerr.V("version", agent.version),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	// There is no user message, and the channel is the context of the prompt and tools
+	text, session, err := uc.answerWithAgent(ctx, agent, slack.Message{Channel: req.ChannelID}, req.Prompt)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to answer with agent", goerr.TV(apperr.ChannelIDKey, req.ChannelID))
+	}
+
+	options, err := uc.getAgentDisplayInfo(ctx, agent)
+	if err != nil {
+		logger.Warn("failed to get agent display info, using basic message",
+			"agent_uuid", agent.uuid,
+			"error", err,
+		)
+		options = nil
+	}
+
+	messageTS, err := uc.slackClient.PostMessageForUpdate(ctx, req.ChannelID, req.ThreadTS, text, options)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to post agent response",
+			goerr.TV(apperr.ChannelIDKey, req.ChannelID),
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+		)
+	}
+
+	// The posted response starts the thread unless it is a reply
+	threadTS := req.ThreadTS
+	if threadTS == "" {
+		threadTS = messageTS
+	}
+
+	uc.saveAgentRunThread(ctx, agent, req, threadTS, messageTS, session)
+
+	logger.Info("ran agent without mention",
+		"channel", req.ChannelID,
+		"thread", threadTS,
+		"agent_uuid", agent.uuid,
+		"agent_version", agent.version,
+	)
+
+	return &interfaces.AgentRunResult{
+		AgentVersion: agent.version,
+		ThreadTS:     threadTS,
+		Response:     text,
+	}, nil
+}
+
+// Ensure Slack implements interfaces.AgentAnswerUseCases
+var _ interfaces.AgentAnswerUseCases = (*Slack)(nil)
+
+// AnswerWithAgent answers the prompt with the agent through the same session as mentions, without
+// posting to Slack. The system prompt and tools are given no channel as the context.
+func (uc *Slack) AnswerWithAgent(ctx context.Context, req *interfaces.AgentRunRequest) (*interfaces.AgentRunResult, error) {
+	if req.Prompt == "" {
+		return nil, goerr.New("prompt is required to answer with agent", goerr.T(apperr.ErrTagValidation))
+	}
+
+	agent, err := uc.resolveAgentToRun(ctx, req.AgentUUID, req.AgentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	text, _, err := uc.answerWithAgent(ctx, agent, slack.Message{}, req.Prompt)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to answer with agent")
+	}
+
+	return &interfaces.AgentRunResult{
+		AgentVersion: agent.version,
+		Response:     text,
+	}, nil
+}
+
+// answerWithAgent generates the response of the agent to the prompt in a new session. The session
+// is returned to save its history.
+func (uc *Slack) answerWithAgent(ctx context.Context, agent *agentContext, slackMsg slack.Message, prompt string) (string, gollem.Session, error) {
+	llmClient, err := uc.getLLMClient(ctx, agent, slackMsg)
+	if err != nil {
+		return "", nil, goerr.Wrap(err, "failed to get LLM client")
+	}
+
+	session, tools, closeSession, err := uc.newAgentSession(ctx, llmClient, agent, slackMsg, nil, nil)
+	if err != nil {
+		return "", nil, goerr.Wrap(err, "failed to create LLM session", goerr.TV(apperr.AgentUUIDKey, agent.uuid))
+	}
+	defer closeSession()
+
+	resp, err := generateWithTools(ctx, session, tools, gollem.Text(prompt))
+	if err != nil {
+		return "", nil, goerr.Wrap(err, "failed to generate content with LLM", goerr.TV(apperr.AgentUUIDKey, agent.uuid))
+	}
+	return responseTextOf(resp), session, nil
+}
+
+// resolveAgentToRun returns the context of the version of the agent. The latest version is used
+// if version is empty. Archived agents are not run.
+func (uc *Slack) resolveAgentToRun(ctx context.Context, agentUUID types.UUID, version string) (*agentContext, error) {
+	if uc.agentRepository == nil {
+		return nil, goerr.New("agent repository not available")
+	}
+
+	agentInfo, err := uc.agentRepository.GetAgent(ctx, agentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent to run",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.T(apperr.ErrTagAgentNotFound))
+	}
+	if agentInfo.Status != agentmodel.StatusActive {
+		return nil, goerr.New("agent is archived",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.TV(apperr.AgentIDKey, agentInfo.AgentID))
+	}
+
+	var agentVersion *agentmodel.AgentVersion
+	if version == "" {
+		agentVersion, err = uc.agentRepository.GetLatestAgentVersion(ctx, agentUUID)
+	} else {
+		agentVersion, err = uc.agentRepository.GetAgentVersion(ctx, agentUUID, version)
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent version to run",
+			goerr.TV(apperr.AgentUUIDKey, agentUUID),
+			goerr.V("version", version))
+	}
+
+	return &agentContext{
+		uuid:           agentInfo.ID,
+		agentID:        agentInfo.AgentID,
+		name:           agentInfo.Name,
+		version:        agentVersion.Version,
+		systemPrompt:   agentVersion.SystemPrompt,
+		promptTemplate: agentVersion.PromptTemplate,
+		promptVars:     agentVersion.PromptVariables,
+		llmProvider:    string(agentVersion.LLMProvider),
+		llmModel:       agentVersion.LLMModel,
+		mcpServers:     agentVersion.MCPServers,
+		delegation:     agentVersion.Delegation,
+		draftAuthor:    draftAuthorOf(agentVersion),
+	}, nil
+}
+
+// saveAgentRunThread stores the thread of the run, and the history of the session if the run
+// started the thread, so that mentions in the thread continue the conversation. The history of an
+// existing thread is kept as is. Failures are logged and ignored because the response is already
+// posted.
+func (uc *Slack) saveAgentRunThread(ctx context.Context, agent *agentContext, req *interfaces.AgentRunRequest, threadTS, messageTS string, session gollem.Session) {
+	logger := ctxlog.From(ctx)
+
+	if uc.repository == nil {
+		return
+	}
+
+	thread, err := uc.repository.GetOrPutThreadWithAgent(ctx, uc.slackTeamID, req.ChannelID, threadTS, &agent.uuid, agent.version)
+	if err != nil {
+		logger.Warn("failed to create thread of agent run",
+			"error", err,
+			"channel", req.ChannelID,
+			"thread_ts", threadTS,
+			"agent_uuid", agent.uuid,
+		)
+		return
+	}
+
+	if uc.storageRepo == nil || req.ThreadTS != "" {
+		return
+	}
+	history := session.History()
+	if history == nil || history.ToCount() == 0 {
+		return
+	}
+
+	// The posted response starts the thread, so it is the message of the turn
+	record := slack.NewHistoryWithAgent(ctx, thread.ID, &agent.uuid, agent.version)
+	record.MessageTS = messageTS
+	record.Input = req.Prompt
+	record.ResponseTS = messageTS
+	if err := uc.storageRepo.SaveHistoryJSON(ctx, thread.ID, record.ID, history); err != nil {
+		logger.Warn("failed to save history of agent run",
+			"error", err,
+			"thread_id", thread.ID,
+			"history_id", record.ID,
+		)
+		return
+	}
+	if err := uc.repository.PutHistory(ctx, record); err != nil {
+		logger.Warn("failed to save history record of agent run",
+			"error", err,
+			"thread_id", thread.ID,
+			"history_id", record.ID,
+		)
+	}
+}
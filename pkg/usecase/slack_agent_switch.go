@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// maxHandoverTranscriptLength caps the transcript passed to the new agent when a thread is
+// handed over. Older messages are dropped first.
+const maxHandoverTranscriptLength = 20000
+
+// handoverIntro tells the new agent that the thread was handed over
+const handoverIntro = "This Slack thread was handed over to you from another assistant."
+
+// handOverThread switches the agent of the existing thread if another agent is specified in the
+// mention. It returns the agent mention to process.
+func (uc *Slack) handOverThread(ctx context.Context, slackMsg slack.Message, mention *slack.Mention, agentMention *slack.AgentMention, thread *slack.Thread) (*slack.AgentMention, error) {
+	agentMention, next := uc.detectAgentSwitch(ctx, mention, agentMention, thread)
+	if next == nil {
+		return agentMention, nil
+	}
+
+	req := agentSwitchRequest{
+		channelID: slackMsg.Channel,
+		threadTS:  slackMsg.GetThreadTS(),
+		userID:    slackMsg.UserID,
+		messageTS: slackMsg.Timestamp,
+	}
+	// Drafts are resolved for the user who mentioned, in the same way as a new thread
+	if agentMention.Draft {
+		requester, err := uc.requesterUserID(ctx, slackMsg)
+		if err != nil {
+			return nil, err
+		}
+		req.draftAuthor = requester
+	}
+	if err := uc.switchThreadAgent(ctx, req, thread, next); err != nil {
+		return nil, err
+	}
+	return agentMention, nil
+}
+
+// detectAgentSwitch checks whether a mention in an existing thread specifies another agent.
+// It returns the agent mention to process and the agent to switch to (nil if not switched).
+// If the first word of the message is not an active agent ID, it is treated as a part of the message.
+func (uc *Slack) detectAgentSwitch(ctx context.Context, mention *slack.Mention, agentMention *slack.AgentMention, thread *slack.Thread) (*slack.AgentMention, *agent.Agent) {
+	if agentMention == nil || agentMention.AgentID == "" || uc.agentRepository == nil {
+		return agentMention, nil
+	}
+
+	next, err := uc.agentRepository.GetAgentByAgentIDActive(ctx, agentMention.AgentID)
+	if err != nil {
+		ctxlog.From(ctx).Debug("first word is not an agent ID, keeping the agent of the thread",
+			"word", agentMention.AgentID,
+			"thread_id", thread.ID,
+			"error", err,
+		)
+		return &slack.AgentMention{
+			UserID:  mention.UserID,
+			Message: mention.Message,
+		}, nil
+	}
+
+	if thread.AgentUUID != nil && *thread.AgentUUID == next.ID {
+		return agentMention, nil
+	}
+
+	return agentMention, next
+}
+
+// agentSwitchRequest is a request to switch the agent of a thread
+type agentSwitchRequest struct {
+	channelID   string
+	threadTS    string
+	userID      string       // User who requested the switch
+	messageTS   string       // Mention that requested the switch. It is excluded from the transcript.
+	draftAuthor types.UserID // The newest draft of the user is used if set
+}
+
+// switchThreadAgent rebinds the thread to the agent. The version is selected in the same way as
+// for a new thread, so that rollouts and drafts apply to switched threads too. The switch is announced in the thread, and
+// recorded as a history record with an empty history and the transcript of the thread, so that
+// the next turn of the agent starts from the transcript. Both a mention with another agent and
+// the slash command switch agents through it.
+func (uc *Slack) switchThreadAgent(ctx context.Context, req agentSwitchRequest, thread *slack.Thread, next *agent.Agent) error {
+	logger := ctxlog.From(ctx)
+
+	var version *agent.AgentVersion
+	var err error
+	if req.draftAuthor != "" {
+		version, err = uc.findUserDraft(ctx, next, req.draftAuthor)
+	} else {
+		version, err = uc.selectAgentVersion(ctx, next, threadRolloutKey(req.channelID, req.threadTS))
+	}
+	if err != nil {
+		return goerr.Wrap(err, "failed to select agent version to switch to",
+			goerr.TV(apperr.AgentUUIDKey, next.ID))
+	}
+
+	prevName := uc.threadAgentName(ctx, thread)
+
+	if err := uc.repository.UpdateThreadAgent(ctx, thread.ID, &next.ID, version.Version); err != nil {
+		return goerr.Wrap(err, "failed to switch thread agent",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.TV(apperr.AgentUUIDKey, next.ID))
+	}
+
+	prevUUID := thread.AgentUUID
+	thread.AgentUUID = &next.ID
+	thread.AgentVersion = version.Version
+
+	// History of the previous agent may be for another LLM provider. The agent starts a new
+	// history with the transcript of the thread instead.
+	if uc.storageRepo != nil {
+		record := slack.NewHistoryWithAgent(ctx, thread.ID, &next.ID, version.Version)
+		record.Handover = uc.buildHandoverTranscript(ctx, thread, req.messageTS)
+		if err := uc.storageRepo.SaveHistoryJSON(ctx, thread.ID, record.ID, &gollem.History{}); err != nil {
+			return goerr.Wrap(err, "failed to save empty history for handover",
+				goerr.TV(apperr.ThreadIDKey, thread.ID))
+		}
+		if err := uc.repository.PutHistory(ctx, record); err != nil {
+			return goerr.Wrap(err, "failed to save history record for handover",
+				goerr.TV(apperr.ThreadIDKey, thread.ID))
+		}
+	}
+
+	logger.Info("switched thread agent",
+		"thread_id", thread.ID,
+		"from_agent_uuid", prevUUID,
+		"to_agent_uuid", next.ID,
+		"to_agent_version", version.Version,
+		"user", req.userID,
+	)
+
+	if uc.slackClient == nil {
+		return nil
+	}
+	notice := fmt.Sprintf(":arrows_counterclockwise: This thread was handed over from *%s* to *%s* (`%s`).", prevName, next.Name, next.AgentID)
+	if err := uc.slackClient.PostMessage(ctx, req.channelID, req.threadTS, notice); err != nil {
+		logger.Warn("failed to post agent switch notice",
+			"error", err,
+			"thread_id", thread.ID,
+		)
+	}
+
+	return nil
+}
+
+// threadAgentName returns the display name of the agent currently bound to the thread
+func (uc *Slack) threadAgentName(ctx context.Context, thread *slack.Thread) string {
+	if thread.AgentUUID == nil || *thread.AgentUUID == generalModeUUID || uc.agentRepository == nil {
+		return "general mode"
+	}
+
+	prev, err := uc.agentRepository.GetAgent(ctx, *thread.AgentUUID)
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to get previous agent of the thread",
+			"error", err,
+			"agent_uuid", *thread.AgentUUID,
+		)
+		return "the previous agent"
+	}
+	return prev.Name
+}
+
+// buildHandoverTranscript builds the transcript of messages recorded in the thread. It is
+// passed to the new agent as text because the history of the previous agent may be for
+// another LLM provider.
+func (uc *Slack) buildHandoverTranscript(ctx context.Context, thread *slack.Thread, currentTS string) string {
+	messages, err := uc.repository.GetThreadMessages(ctx, thread.ID)
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to get thread messages for handover",
+			"error", err,
+			"thread_id", thread.ID,
+		)
+		return handoverIntro
+	}
+
+	lines := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		if (currentTS != "" && msg.Timestamp == currentTS) || msg.Text == "" {
+			continue
+		}
+
+		name := msg.UserName
+		if name == "" {
+			name = msg.UserID
+		}
+		if name == "" {
+			name = "bot"
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s", name, msg.Text))
+	}
+	if len(lines) == 0 {
+		return handoverIntro
+	}
+
+	transcript := truncateHead(strings.Join(lines, "\n"), maxHandoverTranscriptLength)
+
+	return handoverIntro + " The conversation so far is below. Continue the conversation based on it.\n\n" +
+		"<transcript>\n" + transcript + "\n</transcript>"
+}
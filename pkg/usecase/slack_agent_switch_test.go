@@ -0,0 +1,219 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type agentSwitchFixture struct {
+	repo        *memory.Client
+	agentRepo   *memory.AgentMemoryClient
+	sreHelper   *agent.Agent
+	dbHelper    *agent.Agent
+	thread      *slack.Thread
+	slackClient *mock.SlackClientMock
+	inputs      [][]gollem.Input
+	uc          *usecase.Slack
+}
+
+func newAgentSwitchFixture(t *testing.T, threadTS string) *agentSwitchFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	f := &agentSwitchFixture{
+		repo:      memory.New(),
+		agentRepo: memory.NewAgentMemoryClient(),
+	}
+	f.sreHelper = setupToolTestAgent(t, f.agentRepo, "sre-helper")
+
+	f.dbHelper = &agent.Agent{
+		ID:      types.NewUUID(ctx),
+		AgentID: "db-helper",
+		Name:    "DB Helper",
+		Status:  agent.StatusActive,
+		Latest:  "2.0.0",
+	}
+	gt.NoError(t, f.agentRepo.CreateAgent(ctx, f.dbHelper))
+	gt.NoError(t, f.agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    f.dbHelper.ID,
+		Version:      "2.0.0",
+		SystemPrompt: "You are a DB helper.",
+	}))
+
+	// Thread started with sre-helper
+	thread, err := f.repo.GetOrPutThreadWithAgent(ctx, "T12345", "C11111", threadTS, &f.sreHelper.ID, "1.0.0")
+	gt.NoError(t, err)
+	f.thread = thread
+	gt.NoError(t, f.repo.PutThreadMessage(ctx, thread.ID, &slack.Message{
+		ID:        types.NewMessageID(ctx),
+		ThreadID:  thread.ID,
+		UserID:    "U67890USER",
+		UserName:  "alice",
+		Text:      "The primary database is slow",
+		Timestamp: threadTS,
+		CreatedAt: time.Now(),
+	}))
+
+	f.slackClient = &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+	}
+
+	session := &MockSession{
+		generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+			f.inputs = append(f.inputs, input)
+			return &gollem.Response{Texts: []string{"Let me check."}}, nil
+		},
+	}
+
+	f.uc = usecase.New(
+		usecase.WithSlackClient(f.slackClient),
+		usecase.WithRepository(f.repo),
+		usecase.WithAgentRepository(f.agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(&llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
+			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+				return session, nil
+			},
+		}),
+	)
+
+	return f
+}
+
+func newThreadMention(ctx context.Context, text, threadTS string) slack.Message {
+	return *slack.NewMessage(ctx, &slackevents.EventsAPIEvent{
+		TeamID: "T12345",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Data: &slackevents.AppMentionEvent{
+				User:            "U67890USER",
+				Text:            text,
+				TimeStamp:       "1234567890.200000",
+				Channel:         "C11111",
+				ThreadTimeStamp: threadTS,
+			},
+		},
+	})
+}
+
+func TestHandleSlackAppMentionSwitchAgent(t *testing.T) {
+	ctx := context.Background()
+	threadTS := "1234567890.100000"
+
+	t.Run("another agent takes over the thread", func(t *testing.T) {
+		f := newAgentSwitchFixture(t, threadTS)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> db-helper can you look into it?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		// Thread is rebound to the new agent
+		thread, err := f.repo.GetThread(ctx, f.thread.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, f.dbHelper.ID)
+		gt.Equal(t, thread.AgentVersion, "2.0.0")
+
+		// Switch is announced in the thread
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+		gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("from *SRE Helper* to *DB Helper*")
+
+		// The new agent receives the transcript and the message without the agent ID
+		gt.A(t, f.inputs).Length(1)
+		gt.A(t, f.inputs[0]).Length(2)
+		gt.S(t, string(f.inputs[0][0].(gollem.Text))).Contains("[alice] The primary database is slow")
+		gt.Equal(t, f.inputs[0][1].(gollem.Text), gollem.Text("can you look into it?"))
+
+		// The switch and the turn are recorded with the new agent
+		histories, err := f.repo.ListHistories(ctx, f.thread.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(2)
+		gt.Equal(t, *histories[0].AgentUUID, f.dbHelper.ID)
+		gt.S(t, histories[0].Handover).Contains("[alice] The primary database is slow")
+		gt.Equal(t, *histories[1].AgentUUID, f.dbHelper.ID)
+		gt.Equal(t, histories[1].AgentVersion, "2.0.0")
+		gt.Equal(t, histories[1].Handover, "")
+
+		// Following mention without agent ID continues with the new agent
+		next := newThreadMention(ctx, "<@U12345BOT> !thanks", threadTS)
+		next.Timestamp = "1234567890.300000"
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, next))
+		gt.A(t, f.inputs).Length(2)
+		gt.A(t, f.inputs[1]).Length(1)
+
+		histories, err = f.repo.ListHistories(ctx, f.thread.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(3)
+		gt.Equal(t, *histories[2].AgentUUID, f.dbHelper.ID)
+	})
+
+	t.Run("version is selected by rollout of the new agent", func(t *testing.T) {
+		f := newAgentSwitchFixture(t, threadTS)
+		gt.NoError(t, f.agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+			AgentUUID:    f.dbHelper.ID,
+			Version:      "2.1.0",
+			SystemPrompt: "You are a better DB helper.",
+		}))
+		f.dbHelper.Rollout = &agent.Rollout{CandidateVersion: "2.1.0", Percentage: 100}
+		gt.NoError(t, f.agentRepo.UpdateAgent(ctx, f.dbHelper))
+
+		msg := newThreadMention(ctx, "<@U12345BOT> db-helper can you look into it?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		thread, err := f.repo.GetThread(ctx, f.thread.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, f.dbHelper.ID)
+		gt.Equal(t, thread.AgentVersion, "2.1.0")
+
+		histories, err := f.repo.ListHistories(ctx, f.thread.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(2)
+		gt.Equal(t, histories[1].AgentVersion, "2.1.0")
+	})
+
+	t.Run("same agent does not switch", func(t *testing.T) {
+		f := newAgentSwitchFixture(t, threadTS)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> sre-helper any update?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(0)
+		gt.A(t, f.inputs).Length(1)
+		gt.A(t, f.inputs[0]).Length(1)
+		gt.Equal(t, f.inputs[0][0].(gollem.Text), gollem.Text("any update?"))
+	})
+
+	t.Run("word that is not an agent ID is kept in the message", func(t *testing.T) {
+		f := newAgentSwitchFixture(t, threadTS)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> please check the replica too", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		thread, err := f.repo.GetThread(ctx, f.thread.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, f.sreHelper.ID)
+
+		gt.A(t, f.inputs).Length(1)
+		gt.Equal(t, f.inputs[0][0].(gollem.Text), gollem.Text("please check the replica too"))
+	})
+}
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/m-mizutani/ctxlog"
 	"github.com/m-mizutani/goerr/v2"
 	"github.com/m-mizutani/gollem"
 	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
 	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
 	"github.com/m-mizutani/tamamo/pkg/domain/types"
 	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
@@ -24,15 +26,25 @@ type threadContext struct {
 	isNewThread    bool          // Is this a new thread
 	existingThread *slack.Thread // Existing thread (if any)
 	requiresAgent  bool          // Does this require agent specification
+	rolloutKey     string        // Key of a new thread to select the version of the agent in rollout
 }
 
 // agentContext represents resolved agent information (internal use only)
 type agentContext struct {
-	uuid         types.UUID // Agent UUID (special UUID for general mode)
-	version      string     // Agent version
-	systemPrompt string     // System prompt
-	llmProvider  string     // LLM provider (e.g., "gemini", "claude", "openai")
-	llmModel     string     // LLM model (e.g., "gemini-2.0-flash")
+	uuid           types.UUID         // Agent UUID (special UUID for general mode)
+	agentID        string             // Agent ID (empty for general mode)
+	name           string             // Agent name (empty for general mode)
+	version        string             // Agent version
+	systemPrompt   string             // System prompt
+	promptTemplate bool               // Render the system prompt as a template
+	promptVars     map[string]string  // Custom variables of the system prompt template
+	llmProvider    string             // LLM provider (e.g., "gemini", "claude", "openai")
+	llmModel       string             // LLM model (e.g., "gemini-2.0-flash")
+	mcpServers     []*agent.MCPServer // MCP servers attached to the agent version
+	delegation     *agent.Delegation  // Delegation configuration of the agent version
+	lateJoin       bool               // Mentioned in a thread that tamamo has not participated in
+	newThread      bool               // The mention starts a new thread with the agent
+	draftAuthor    types.UserID       // Author of the draft version (empty for published versions)
 }
 
 // HandleSlackAppMention handles a slack app mention event with LLM integration
@@ -64,11 +76,47 @@ func (uc *Slack) HandleSlackAppMention(ctx context.Context, slackMsg slack.Messa
 	// Analyze thread context
 	threadCtx := uc.analyzeThreadContext(ctx, slackMsg)
 
-	// Resolve agent
-	agent, err := uc.resolveAgent(ctx, agentMention, threadCtx)
+	// "regenerate" in an existing thread reruns the latest turn of the agent
+	if model, ok := parseRegenerateCommand(firstBotMention.Message); ok && !threadCtx.isNewThread && threadCtx.existingThread != nil {
+		return uc.regenerate(ctx, regenerateRequest{
+			teamID:    slackMsg.TeamID,
+			channelID: slackMsg.Channel,
+			threadTS:  slackMsg.GetThreadTS(),
+			userID:    slackMsg.UserID,
+			model:     model,
+		})
+	}
+
+	// Another agent specified in an existing thread takes over the thread
+	if !threadCtx.isNewThread && threadCtx.existingThread != nil && uc.repository != nil {
+		var err error
+		agentMention, err = uc.handOverThread(ctx, slackMsg, firstBotMention, agentMention, threadCtx.existingThread)
+		if err != nil {
+			return uc.handleAgentError(ctx, slackMsg, err)
+		}
+	}
+
+	// Route the request to an agent if no agent is specified for a new thread
+	if threadCtx.isNewThread && uc.routerClient != nil {
+		agentMention = uc.routeAgentMention(ctx, slackMsg, firstBotMention, agentMention)
+	}
+
+	// Resolve agent. Drafts are resolved for the user who mentioned, and usable only by the author.
+	var agent *agentContext
+	var err error
+	if threadCtx.isNewThread && agentMention != nil && agentMention.Draft {
+		agent, err = uc.resolveDraftAgent(ctx, slackMsg, agentMention)
+	} else {
+		agent, err = uc.resolveAgent(ctx, agentMention, threadCtx)
+	}
+	if err == nil {
+		err = uc.authorizeDraft(ctx, slackMsg, agent)
+	}
 	if err != nil {
 		return uc.handleAgentError(ctx, slackMsg, err)
 	}
+	agent.lateJoin = threadCtx.isNewThread && slackMsg.InThread()
+	agent.newThread = threadCtx.isNewThread
 
 	// Process the bot mention with agent
 	return uc.processBotMentionWithAgent(ctx, slackMsg, agentMention, agent)
@@ -213,6 +261,7 @@ func (uc *Slack) analyzeThreadContext(ctx context.Context, slackMsg slack.Messag
 			isNewThread:    true,
 			existingThread: nil,
 			requiresAgent:  true,
+			rolloutKey:     rolloutKeyOf(slackMsg),
 		}
 	}
 
@@ -228,6 +277,7 @@ func (uc *Slack) analyzeThreadContext(ctx context.Context, slackMsg slack.Messag
 			isNewThread:    true,
 			existingThread: nil,
 			requiresAgent:  true,
+			rolloutKey:     rolloutKeyOf(slackMsg),
 		}
 	}
 
@@ -274,7 +324,7 @@ func (uc *Slack) resolveAgent(ctx context.Context, agentMention *slack.AgentMent
 
 			// Get agent information from repository
 			if uc.agentRepository != nil {
-				_, err := uc.agentRepository.GetAgent(ctx, *thread.AgentUUID)
+				agentInfo, err := uc.agentRepository.GetAgent(ctx, *thread.AgentUUID)
 				if err != nil {
 					return nil, goerr.Wrap(err, "failed to get agent from repository",
 						goerr.TV(apperr.AgentUUIDKey, *thread.AgentUUID))
@@ -289,11 +339,18 @@ func (uc *Slack) resolveAgent(ctx context.Context, agentMention *slack.AgentMent
 				}
 
 				return &agentContext{
-					uuid:         *thread.AgentUUID,
-					version:      thread.AgentVersion,
-					systemPrompt: agentVersion.SystemPrompt,
-					llmProvider:  string(agentVersion.LLMProvider),
-					llmModel:     agentVersion.LLMModel,
+					uuid:           *thread.AgentUUID,
+					agentID:        agentInfo.AgentID,
+					name:           agentInfo.Name,
+					version:        thread.AgentVersion,
+					systemPrompt:   agentVersion.SystemPrompt,
+					promptTemplate: agentVersion.PromptTemplate,
+					promptVars:     agentVersion.PromptVariables,
+					llmProvider:    string(agentVersion.LLMProvider),
+					llmModel:       agentVersion.LLMModel,
+					mcpServers:     agentVersion.MCPServers,
+					delegation:     agentVersion.Delegation,
+					draftAuthor:    draftAuthorOf(agentVersion),
 				}, nil
 			}
 		}
@@ -336,8 +393,8 @@ func (uc *Slack) resolveAgent(ctx context.Context, agentMention *slack.AgentMent
 			goerr.TV(apperr.AgentIDKey, agentMention.AgentID))
 	}
 
-	// Get latest version of the agent
-	latestVersion, err := uc.agentRepository.GetLatestAgentVersion(ctx, agentInfo.ID)
+	// Get latest version of the agent, or the candidate version for a part of new threads in rollout
+	latestVersion, err := uc.selectAgentVersion(ctx, agentInfo, threadCtx.rolloutKey)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to get latest agent version",
 			goerr.TV(apperr.AgentUUIDKey, agentInfo.ID),
@@ -351,11 +408,17 @@ func (uc *Slack) resolveAgent(ctx context.Context, agentMention *slack.AgentMent
 	)
 
 	return &agentContext{
-		uuid:         agentInfo.ID,
-		version:      latestVersion.Version,
-		systemPrompt: latestVersion.SystemPrompt,
-		llmProvider:  string(latestVersion.LLMProvider),
-		llmModel:     latestVersion.LLMModel,
+		uuid:           agentInfo.ID,
+		agentID:        agentInfo.AgentID,
+		name:           agentInfo.Name,
+		version:        latestVersion.Version,
+		systemPrompt:   latestVersion.SystemPrompt,
+		promptTemplate: latestVersion.PromptTemplate,
+		promptVars:     latestVersion.PromptVariables,
+		llmProvider:    string(latestVersion.LLMProvider),
+		llmModel:       latestVersion.LLMModel,
+		mcpServers:     latestVersion.MCPServers,
+		delegation:     latestVersion.Delegation,
 	}, nil
 }
 
@@ -397,6 +460,10 @@ func (uc *Slack) handleAgentError(ctx context.Context, slackMsg slack.Message, e
 		}
 
 		errorMessage = uc.generateAgentErrorMessage(ctx, agentID)
+	} else if errors.Is(err, slack.ErrDraftNotFound) {
+		errorMessage = "You have no draft version of this agent. Create a draft version in the web UI first."
+	} else if errors.Is(err, slack.ErrDraftNotAllowed) {
+		errorMessage = "Draft versions of agents are usable only by their authors who have logged in to the web UI."
 	} else {
 		// Generic error message
 		errorMessage = "An error occurred while processing your request. Please try again later."
@@ -466,7 +533,10 @@ func (uc *Slack) processBotMentionWithAgent(ctx context.Context, slackMsg slack.
 	}
 
 	// Start chat conversation with agent-specific system prompt
-	if err := uc.chatWithAgent(ctx, slackMsg, threadID, userMessage, agent); err != nil {
+	started := time.Now()
+	err := uc.chatWithAgent(ctx, slackMsg, threadID, userMessage, agent)
+	uc.recordAgentTurn(ctx, agent, time.Since(started), err)
+	if err != nil {
 		// Log the error with context
 		pkgErrors.Handle(ctx, err)
 
@@ -525,55 +595,8 @@ func (uc *Slack) chatWithAgent(ctx context.Context, slackMsg slack.Message, thre
 	logger := ctxlog.From(ctx)
 
 	// Load conversation history if thread exists
-	var history *gollem.History
-	if threadID.IsValid() && uc.repository != nil && uc.storageRepo != nil {
-		logger.Debug("attempting to load history for thread",
-			"thread_id", threadID,
-		)
-		// Get the latest history for this thread
-		latestHistory, err := uc.repository.GetLatestHistory(ctx, threadID)
-		if err != nil {
-			if errors.Is(err, slack.ErrHistoryNotFound) {
-				// It's normal for new threads to not have history yet
-				logger.Debug("no existing history for thread",
-					"thread_id", threadID,
-				)
-			} else {
-				// Log other errors as warnings, as this might indicate a problem
-				logger.Warn("failed to get latest history, starting new conversation",
-					"error", err,
-					"thread_id", threadID,
-				)
-			}
-		} else if latestHistory == nil {
-			logger.Debug("no history found for thread",
-				"thread_id", threadID,
-			)
-		} else {
-			// Load gollem history from storage
-			storedHistory, err := uc.storageRepo.LoadHistoryJSON(ctx, threadID, latestHistory.ID)
-			if err != nil {
-				logger.Warn("failed to load history from storage, but ignore it and start without history",
-					"error", err,
-					"thread_id", threadID,
-					"history_id", latestHistory.ID,
-				)
-			} else {
-				history = &storedHistory
-				logger.Debug("loaded conversation history",
-					"thread_id", threadID,
-					"history_id", latestHistory.ID,
-					"message_count", history.ToCount(),
-				)
-			}
-		}
-	} else {
-		logger.Debug("conditions not met for loading history",
-			"thread_id_valid", threadID.IsValid(),
-			"has_repository", uc.repository != nil,
-			"has_storage_repo", uc.storageRepo != nil,
-		)
-	}
+	// snapshot is the latest history record to find messages after it
+	history, snapshot := uc.loadThreadHistory(ctx, threadID)
 
 	// Get the appropriate LLM client
 	llmClient, err := uc.getLLMClient(ctx, agent, slackMsg)
@@ -581,17 +604,11 @@ func (uc *Slack) chatWithAgent(ctx context.Context, slackMsg slack.Message, thre
 		return goerr.Wrap(err, "failed to get LLM client")
 	}
 
-	// Create session with agent-specific system prompt and history if available
-	sessionOptions := []gollem.SessionOption{
-		gollem.WithSessionSystemPrompt(agent.systemPrompt),
-	}
-
-	if history != nil {
-		sessionOptions = append(sessionOptions, gollem.WithSessionHistory(history))
-	}
+	// Older turns are summarized if the history is close to the context window of the model
+	history, parent := uc.compactHistory(ctx, llmClient, agent, threadID, snapshot, history)
 
 	// Create a new session for this conversation
-	session, err := llmClient.NewSession(ctx, sessionOptions...)
+	session, tools, closeSession, err := uc.newAgentSession(ctx, llmClient, agent, slackMsg, history, parent)
 	if err != nil {
 		return goerr.Wrap(err, "failed to create LLM session",
 			goerr.TV(apperr.ThreadIDKey, threadID),
@@ -600,31 +617,50 @@ func (uc *Slack) chatWithAgent(ctx context.Context, slackMsg slack.Message, thre
 			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
 		)
 	}
+	defer closeSession()
 
-	// Generate content directly through session
-	resp, err := session.GenerateContent(ctx, gollem.Text(userMessage))
-	if err != nil {
-		return goerr.Wrap(err, "failed to generate content with LLM",
-			goerr.TV(apperr.ThreadIDKey, threadID),
-			goerr.V("message", userMessage),
-			goerr.TV(apperr.ChannelIDKey, slackMsg.Channel),
-			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
-		)
+	var parts []string
+	if snapshot != nil && snapshot.Handover != "" {
+		// The thread was handed over from another agent since the last turn
+		parts = append(parts, snapshot.Handover)
 	}
-
-	var responseText string
-	if resp != nil && len(resp.Texts) > 0 {
-		responseText = resp.Texts[0]
+	if threadContext := uc.buildThreadContext(ctx, slackMsg, threadID, snapshot, agent.lateJoin); threadContext != "" {
+		// Messages humans posted to each other between mentions are not in the history
+		parts = append(parts, threadContext)
 	}
+	parts = append(parts, userMessage)
+	parts = append(parts, uc.loadAttachmentTexts(ctx, slackMsg, agent)...)
+	input := textInputs(parts)
 
-	// If no response was captured, use a fallback
-	if responseText == "" {
-		responseText = "(no response)"
-	}
+	var responseTS string
+	if uc.streamResponse {
+		// Stream the response into a placeholder message, running tools requested by LLM
+		responseTS, err = uc.respondWithStream(ctx, session, tools, slackMsg, agent, input...)
+		if err != nil {
+			return goerr.Wrap(err, "failed to respond with streaming",
+				goerr.TV(apperr.ThreadIDKey, threadID),
+				goerr.V("message", userMessage),
+				goerr.TV(apperr.ChannelIDKey, slackMsg.Channel),
+				goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+			)
+		}
+	} else {
+		// Generate content through session, running tools requested by LLM
+		resp, err := generateWithTools(ctx, session, tools, input...)
+		if err != nil {
+			return goerr.Wrap(err, "failed to generate content with LLM",
+				goerr.TV(apperr.ThreadIDKey, threadID),
+				goerr.V("message", userMessage),
+				goerr.TV(apperr.ChannelIDKey, slackMsg.Channel),
+				goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+			)
+		}
 
-	// Send response to Slack with agent-specific display
-	if err := uc.postMessageWithAgentDisplay(ctx, slackMsg.Channel, slackMsg.GetThreadTS(), responseText, agent); err != nil {
-		return goerr.Wrap(err, "failed to post message to slack")
+		// Send response to Slack with agent-specific display
+		responseTS, err = uc.postMessageWithAgentDisplay(ctx, slackMsg.Channel, slackMsg.GetThreadTS(), responseTextOf(resp), agent)
+		if err != nil {
+			return goerr.Wrap(err, "failed to post message to slack")
+		}
 	}
 
 	logger.Info("responded to slack mention with LLM",
@@ -637,42 +673,189 @@ func (uc *Slack) chatWithAgent(ctx context.Context, slackMsg slack.Message, thre
 	)
 
 	// Save updated history to storage for future use
-	if threadID.IsValid() && uc.repository != nil && uc.storageRepo != nil && session != nil {
-		// Get the session's history
-		updatedHistory := session.History()
-		if updatedHistory != nil && updatedHistory.ToCount() > 0 {
-			// Create history record with consistent ID
-			historyRecord := slack.NewHistory(ctx, threadID)
-			historyID := historyRecord.ID
-
-			// Save gollem history to storage
-			if err := uc.storageRepo.SaveHistoryJSON(ctx, threadID, historyID, updatedHistory); err != nil {
-				logger.Warn("failed to save history to storage",
-					"error", err,
-					"thread_id", threadID,
-					"history_id", historyID,
-				)
-			} else {
-				// Save history record to repository
-				if err := uc.repository.PutHistory(ctx, historyRecord); err != nil {
-					logger.Warn("failed to save history record",
-						"error", err,
-						"thread_id", threadID,
-						"history_id", historyID,
-					)
-				} else {
-					logger.Debug("saved session history",
-						"thread_id", threadID,
-						"history_id", historyID,
-						"message_count", updatedHistory.ToCount(),
-						"created_at", historyRecord.CreatedAt,
-					)
-				}
-			}
+	uc.saveThreadHistory(ctx, threadID, agent, parent, turnRecord{
+		messageTS:  slackMsg.Timestamp,
+		message:    userMessage,
+		inputParts: parts,
+		responseTS: responseTS,
+	}, session)
+
+	return nil
+}
+
+// turnRecord is the input and the response of a turn kept in the history record, so that the
+// turn can be regenerated
+type turnRecord struct {
+	messageTS  string   // Mention that started the turn
+	message    string   // Message of the user
+	inputParts []string // All text given to the agent, including the message
+	responseTS string   // Response of the agent
+}
+
+// textInputs converts text to LLM inputs
+func textInputs(parts []string) []gollem.Input {
+	input := make([]gollem.Input, 0, len(parts))
+	for _, part := range parts {
+		input = append(input, gollem.Text(part))
+	}
+	return input
+}
+
+// loadThreadHistory loads the latest history of the thread. The history record is returned even if
+// the history fails to be loaded from storage, so that messages after it can be found. Both are nil
+// for new threads.
+func (uc *Slack) loadThreadHistory(ctx context.Context, threadID types.ThreadID) (*gollem.History, *slack.History) {
+	logger := ctxlog.From(ctx)
+
+	if !threadID.IsValid() || uc.repository == nil || uc.storageRepo == nil {
+		logger.Debug("conditions not met for loading history",
+			"thread_id_valid", threadID.IsValid(),
+			"has_repository", uc.repository != nil,
+			"has_storage_repo", uc.storageRepo != nil,
+		)
+		return nil, nil
+	}
+
+	logger.Debug("attempting to load history for thread",
+		"thread_id", threadID,
+	)
+
+	var history *gollem.History
+	var snapshot *slack.History
+	// Get the latest history for this thread
+	latestHistory, err := uc.repository.GetLatestHistory(ctx, threadID)
+	if err != nil {
+		if errors.Is(err, slack.ErrHistoryNotFound) {
+			// It's normal for new threads to not have history yet
+			logger.Debug("no existing history for thread",
+				"thread_id", threadID,
+			)
+		} else {
+			// Log other errors as warnings, as this might indicate a problem
+			logger.Warn("failed to get latest history, starting new conversation",
+				"error", err,
+				"thread_id", threadID,
+			)
+		}
+	} else if latestHistory == nil {
+		logger.Debug("no history found for thread",
+			"thread_id", threadID,
+		)
+	} else {
+		snapshot = latestHistory
+
+		// Load gollem history from storage
+		storedHistory, err := uc.storageRepo.LoadHistoryJSON(ctx, threadID, latestHistory.ID)
+		if err != nil {
+			logger.Warn("failed to load history from storage, but ignore it and start without history",
+				"error", err,
+				"thread_id", threadID,
+				"history_id", latestHistory.ID,
+			)
+		} else {
+			history = &storedHistory
+			logger.Debug("loaded conversation history",
+				"thread_id", threadID,
+				"history_id", latestHistory.ID,
+				"message_count", history.ToCount(),
+			)
 		}
 	}
 
-	return nil
+	return history, snapshot
+}
+
+// saveThreadHistory saves the history of the session as a new snapshot of the thread, and returns
+// the record of the snapshot. Failures are logged and nil is returned because the response is
+// already sent.
+func (uc *Slack) saveThreadHistory(ctx context.Context, threadID types.ThreadID, agent *agentContext, parent *slack.History, turn turnRecord, session gollem.Session) *slack.History {
+	logger := ctxlog.From(ctx)
+
+	if !threadID.IsValid() || uc.repository == nil || uc.storageRepo == nil || session == nil {
+		return nil
+	}
+
+	// Get the session's history
+	updatedHistory := session.History()
+	if updatedHistory == nil || updatedHistory.ToCount() == 0 {
+		return nil
+	}
+
+	// Create history record with consistent ID and the agent that answered the turn
+	historyRecord := slack.NewHistoryWithAgent(ctx, threadID, &agent.uuid, agent.version)
+	historyRecord.Summary = historySummaryOf(parent) // Summary is carried over until the next compaction
+	historyRecord.MessageTS = turn.messageTS
+	historyRecord.Input = turn.message
+	historyRecord.InputParts = turn.inputParts
+	historyRecord.ResponseTS = turn.responseTS
+	if parent != nil {
+		historyRecord.ParentID = parent.ID
+	}
+	historyID := historyRecord.ID
+
+	// Save gollem history to storage
+	if err := uc.storageRepo.SaveHistoryJSON(ctx, threadID, historyID, updatedHistory); err != nil {
+		logger.Warn("failed to save history to storage",
+			"error", err,
+			"thread_id", threadID,
+			"history_id", historyID,
+		)
+		return nil
+	}
+
+	// Save history record to repository
+	if err := uc.repository.PutHistory(ctx, historyRecord); err != nil {
+		logger.Warn("failed to save history record",
+			"error", err,
+			"thread_id", threadID,
+			"history_id", historyID,
+		)
+		return nil
+	}
+
+	logger.Debug("saved session history",
+		"thread_id", threadID,
+		"history_id", historyID,
+		"message_count", updatedHistory.ToCount(),
+		"created_at", historyRecord.CreatedAt,
+	)
+	return historyRecord
+}
+
+// newAgentSession creates an LLM session with the system prompt, the history and the tools of the
+// agent. The summary of the parent history record is added to the system prompt. The returned
+// function closes connections of the tools and must be called after the session is used.
+func (uc *Slack) newAgentSession(ctx context.Context, llmClient gollem.LLMClient, agent *agentContext, slackMsg slack.Message, history *gollem.History, parent *slack.History) (gollem.Session, []gollem.Tool, func(), error) {
+	systemPrompt := uc.renderSystemPrompt(ctx, agent, slackMsg)
+	sessionOptions := []gollem.SessionOption{
+		gollem.WithSessionSystemPrompt(withHistorySummary(systemPrompt, historySummaryOf(parent))),
+	}
+
+	// History becomes empty when it is reset by the slash command
+	if history != nil && history.ToCount() > 0 {
+		sessionOptions = append(sessionOptions, gollem.WithSessionHistory(history))
+	}
+
+	// Register tools built from agent search configurations
+	tools := uc.buildAgentTools(ctx, agent, slackMsg)
+
+	// Register tools to consult other agents if the agent enabled delegation
+	tools = append(tools, uc.buildDelegationTools(ctx, agent, slackMsg, nil, nil)...)
+
+	// Register tools of MCP servers attached to the agent version
+	mcpTools, closeMCP := uc.connectMCPServers(ctx, agent)
+	tools = append(tools, mcpTools...)
+
+	if len(tools) > 0 {
+		sessionOptions = append(sessionOptions, gollem.WithSessionTools(tools...))
+	}
+
+	session, err := llmClient.NewSession(ctx, sessionOptions...)
+	if err != nil {
+		closeMCP()
+		return nil, nil, nil, err
+	}
+	return session, tools, closeMCP, nil
 }
 
 // getLLMClient retrieves the appropriate LLM client based on agent configuration
@@ -701,7 +884,9 @@ func (uc *Slack) getLLMClient(ctx context.Context, agent *agentContext, slackMsg
 
 			// Send warning to Slack about fallback
 			warningMsg := fmt.Sprintf("⚠️ Failed to use %s/%s, falling back to default provider", agent.llmProvider, agent.llmModel)
-			_ = uc.slackClient.PostMessage(ctx, slackMsg.Channel, slackMsg.GetThreadTS(), warningMsg)
+			if uc.slackClient != nil && slackMsg.Channel != "" {
+				_ = uc.slackClient.PostMessage(ctx, slackMsg.Channel, slackMsg.GetThreadTS(), warningMsg)
+			}
 
 			return fallbackClient, nil
 		}
@@ -714,26 +899,26 @@ func (uc *Slack) getLLMClient(ctx context.Context, agent *agentContext, slackMsg
 	return nil, goerr.New("no LLM client available")
 }
 
-// postMessageWithAgentDisplay posts a message to Slack with agent-specific display settings
-func (uc *Slack) postMessageWithAgentDisplay(ctx context.Context, channelID, threadTS, text string, agent *agentContext) error {
-	// Use basic PostMessage if no agent context or for general mode
-	if agent == nil || agent.uuid == generalModeUUID {
-		return uc.slackClient.PostMessage(ctx, channelID, threadTS, text)
-	}
-
-	// Get agent information for custom display
-	agentInfo, err := uc.getAgentDisplayInfo(ctx, agent)
-	if err != nil {
-		// Log warning but fallback to basic message
-		ctxlog.From(ctx).Warn("failed to get agent display info, using basic message",
-			"agent_uuid", agent.uuid,
-			"error", err,
-		)
-		return uc.slackClient.PostMessage(ctx, channelID, threadTS, text)
+// postMessageWithAgentDisplay posts a message to Slack with agent-specific display settings and
+// returns its timestamp
+func (uc *Slack) postMessageWithAgentDisplay(ctx context.Context, channelID, threadTS, text string, agent *agentContext) (string, error) {
+	// Use the display of the bot if no agent context or for general mode
+	var options *interfaces.SlackMessageOptions
+	if agent != nil && agent.uuid != generalModeUUID {
+		agentInfo, err := uc.getAgentDisplayInfo(ctx, agent)
+		if err != nil {
+			// Log warning but fallback to basic message
+			ctxlog.From(ctx).Warn("failed to get agent display info, using basic message",
+				"agent_uuid", agent.uuid,
+				"error", err,
+			)
+		} else {
+			options = agentInfo
+		}
 	}
 
 	// Post message with agent-specific options
-	return uc.slackClient.PostMessageWithOptions(ctx, channelID, threadTS, text, agentInfo)
+	return uc.slackClient.PostMessageForUpdate(ctx, channelID, threadTS, text, options)
 }
 
 // getAgentDisplayInfo retrieves agent display information for Slack messages
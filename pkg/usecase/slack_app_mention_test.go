@@ -582,6 +582,7 @@ func TestHandleSlackAppMentionWithRepository(t *testing.T) {
 type MockSession struct {
 	history             *gollem.History
 	generateContentFunc func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error)
+	generateStreamFunc  func(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error)
 	messageCount        int // Track how many messages have been generated
 }
 
@@ -598,6 +599,11 @@ func (m *MockSession) GenerateContent(ctx context.Context, input ...gollem.Input
 }
 
 func (m *MockSession) GenerateStream(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error) {
+	if m.generateStreamFunc != nil {
+		m.messageCount++
+		return m.generateStreamFunc(ctx, input...)
+	}
+
 	ch := make(chan *gollem.Response, 1)
 	resp, err := m.GenerateContent(ctx, input...)
 	if err != nil {
@@ -648,6 +654,7 @@ func TestHandleSlackAppMentionWithLLM(t *testing.T) {
 		}
 
 		mockLLMClient := &llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
 			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
 				return mockSession, nil
 			},
@@ -656,13 +663,16 @@ func TestHandleSlackAppMentionWithLLM(t *testing.T) {
 		// Setup mock Slack client
 		var capturedResponse string
 		mockSlackClient := &mock.SlackClientMock{
-			PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
 				capturedResponse = text
-				return nil
+				return "1234567890.200000", nil
 			},
 			IsBotUserFunc: func(uid string) bool {
 				return uid == botUserID
 			},
+			GetThreadRepliesFunc: func(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+				return nil, nil
+			},
 		}
 
 		// Create repositories
@@ -703,11 +713,11 @@ func TestHandleSlackAppMentionWithLLM(t *testing.T) {
 		gt.Equal(t, len(mockLLMClient.NewSessionCalls()), 1)
 
 		// Verify Slack mock call counts
-		gt.Equal(t, len(mockSlackClient.PostMessageCalls()), 1)
+		gt.Equal(t, len(mockSlackClient.PostMessageForUpdateCalls()), 1)
 		gt.Equal(t, len(mockSlackClient.IsBotUserCalls()), 1)
 
-		// Verify PostMessage call details
-		postMessageCall := mockSlackClient.PostMessageCalls()[0]
+		// Verify PostMessageForUpdate call details
+		postMessageCall := mockSlackClient.PostMessageForUpdateCalls()[0]
 		gt.Equal(t, postMessageCall.ChannelID, channelID)
 		gt.Equal(t, postMessageCall.ThreadTS, "1234567890.100000")
 		gt.S(t, capturedResponse).Contains("AI-powered response")
@@ -732,6 +742,7 @@ func TestHandleSlackAppMentionWithLLM(t *testing.T) {
 		}
 
 		mockLLMClient := &llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
 			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
 				return mockSession, nil
 			},
@@ -739,12 +750,15 @@ func TestHandleSlackAppMentionWithLLM(t *testing.T) {
 
 		// Setup mock Slack client
 		mockSlackClient := &mock.SlackClientMock{
-			PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
-				return nil
+			PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+				return "1234567890.200000", nil
 			},
 			IsBotUserFunc: func(uid string) bool {
 				return uid == botUserID
 			},
+			GetThreadRepliesFunc: func(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+				return nil, nil
+			},
 		}
 
 		// Create repositories
@@ -816,6 +830,7 @@ func TestHandleSlackAppMentionWithLLM(t *testing.T) {
 	t.Run("falls back gracefully when LLM fails", func(t *testing.T) {
 		// Mock LLM that fails
 		mockLLMClient := &llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
 			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
 				return nil, goerr.New("LLM service unavailable")
 			},
@@ -1071,12 +1086,15 @@ func TestResolveAgent(t *testing.T) {
 	t.Run("general mode with agent repository", func(t *testing.T) {
 		repo := memory.New()
 		mockClient := &mock.SlackClientMock{
-			PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
-				return nil
+			PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+				return "1234567890.200000", nil
 			},
 			IsBotUserFunc: func(uid string) bool {
 				return uid == botUserID
 			},
+			GetThreadRepliesFunc: func(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+				return nil, nil
+			},
 		}
 
 		// Mock LLM to enable agent functionality
@@ -1089,6 +1107,7 @@ func TestResolveAgent(t *testing.T) {
 		}
 
 		mockLLMClient := &llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
 			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
 				return mockSession, nil
 			},
@@ -1133,14 +1152,15 @@ func TestResolveAgent(t *testing.T) {
 		gt.Equal(t, len(mockLLMClient.NewSessionCalls()), 1)
 
 		// Verify Slack mock call counts
-		gt.Equal(t, len(mockClient.PostMessageCalls()), 1)
+		gt.Equal(t, len(mockClient.PostMessageForUpdateCalls()), 1)
 		gt.Equal(t, len(mockClient.IsBotUserCalls()), 1)
 
-		// Verify PostMessage call details
-		postMessageCall := mockClient.PostMessageCalls()[0]
+		// Verify PostMessageForUpdate call details
+		postMessageCall := mockClient.PostMessageForUpdateCalls()[0]
 		gt.Equal(t, postMessageCall.ChannelID, channelID)
 		gt.Equal(t, postMessageCall.ThreadTS, "1234567890.100000")
 		gt.S(t, postMessageCall.Text).Contains("Welcome to Tamamo general mode!")
+		gt.Nil(t, postMessageCall.Options)
 
 		// Verify IsBotUser call details
 		isBotUserCall := mockClient.IsBotUserCalls()[0]
@@ -1206,6 +1226,7 @@ func TestResolveAgent(t *testing.T) {
 		}
 
 		mockLLMClient := &llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
 			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
 				return mockSession, nil
 			},
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/service/document"
+)
+
+const (
+	// maxDocumentAttachments caps the number of documents read per message
+	maxDocumentAttachments = 5
+
+	// maxDocumentAttachmentSize is the maximum size of a document file to download
+	maxDocumentAttachmentSize = 10 * 1024 * 1024
+
+	// documentChunkSize is the size of a chunk of document text in bytes
+	documentChunkSize = 8 * 1024
+
+	// defaultDocumentBudget is the document budget in bytes when the context window is unknown
+	defaultDocumentBudget = 100 * 1024
+
+	// maxDocumentBudget caps the document budget because document text is kept in the history
+	maxDocumentBudget = 512 * 1024
+)
+
+var errAttachmentTooLarge = errors.New("attachment is too large")
+
+// limitedBuffer is a buffer that fails to write more than limit bytes
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		return 0, errAttachmentTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+// loadAttachmentTexts returns LLM inputs of files attached to the message. Text of documents is
+// included as context of the turn, so that it is kept in the history for follow-up questions.
+// Attachments that are not read are noticed in the thread.
+//
+// Images are not passed to the LLM because the LLM client does not accept image inputs yet.
+func (uc *Slack) loadAttachmentTexts(ctx context.Context, slackMsg slack.Message, agent *agentContext) []string {
+	var documents []slack.File
+	var skipped []string
+	for _, file := range slackMsg.Files {
+		switch {
+		case file.IsImage():
+			skipped = append(skipped, fmt.Sprintf("%s (images are not supported)", file.Name))
+		case document.IsSupported(file.Name, file.Mimetype):
+			documents = append(documents, file)
+		default:
+			skipped = append(skipped, fmt.Sprintf("%s (unsupported type)", file.Name))
+		}
+	}
+
+	documentTexts, skippedDocuments := uc.loadDocumentTexts(ctx, agent, documents)
+	skipped = append(skipped, skippedDocuments...)
+
+	if len(skipped) > 0 {
+		uc.postAttachmentNotice(ctx, slackMsg, ":warning: Some attached files are not fully read: "+strings.Join(skipped, ", "))
+	}
+
+	return documentTexts
+}
+
+// loadDocumentTexts extracts text of the documents and returns it as LLM inputs with
+// descriptions of skipped documents. Text is split into chunks, and trailing chunks are dropped
+// to fit the document budget of the model.
+func (uc *Slack) loadDocumentTexts(ctx context.Context, agent *agentContext, documents []slack.File) ([]string, []string) {
+	logger := ctxlog.From(ctx)
+
+	type extracted struct {
+		file slack.File
+		text string
+	}
+
+	var texts []extracted
+	var skipped []string
+	for _, file := range documents {
+		reason := ""
+		switch {
+		case len(texts) >= maxDocumentAttachments:
+			reason = fmt.Sprintf("up to %d documents", maxDocumentAttachments)
+		case file.Size > maxDocumentAttachmentSize:
+			reason = "too large"
+		}
+		if reason != "" {
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", file.Name, reason))
+			continue
+		}
+
+		data, err := uc.downloadAttachment(ctx, file, maxDocumentAttachmentSize)
+		if err != nil {
+			logger.Warn("failed to download attached document",
+				"error", err,
+				"file_id", file.ID,
+				"file_name", file.Name,
+			)
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", file.Name, downloadFailureReason(err)))
+			continue
+		}
+
+		text, err := document.Extract(file.Name, file.Mimetype, data)
+		if err != nil {
+			logger.Warn("failed to extract text of attached document",
+				"error", err,
+				"file_id", file.ID,
+				"file_name", file.Name,
+			)
+			skipped = append(skipped, fmt.Sprintf("%s (failed to extract text)", file.Name))
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			skipped = append(skipped, fmt.Sprintf("%s (no text found)", file.Name))
+			continue
+		}
+
+		texts = append(texts, extracted{file: file, text: text})
+	}
+
+	if len(texts) == 0 {
+		return nil, skipped
+	}
+
+	// Budget is shared equally by the documents
+	budget := uc.documentBudget(agent) / len(texts)
+
+	var inputs []string
+	for _, doc := range texts {
+		chunks := document.Chunk(doc.text, documentChunkSize)
+		kept, truncated := document.Truncate(chunks, budget)
+
+		for i, chunk := range kept {
+			inputs = append(inputs, fmt.Sprintf("[Attached file: %s, part %d of %d]\n%s", doc.file.Name, i+1, len(chunks), chunk))
+		}
+
+		if truncated {
+			inputs = append(inputs, fmt.Sprintf("[Attached file: %s, parts %d to %d are omitted because the file is too long]", doc.file.Name, len(kept)+1, len(chunks)))
+			skipped = append(skipped, fmt.Sprintf("%s (truncated to %d of %d parts)", doc.file.Name, len(kept), len(chunks)))
+		}
+
+		logger.Info("read attached document",
+			"agent_uuid", agent.uuid,
+			"file_id", doc.file.ID,
+			"file_name", doc.file.Name,
+			"text_size", len(doc.text),
+			"chunks", len(chunks),
+			"kept_chunks", len(kept),
+		)
+	}
+
+	return inputs, skipped
+}
+
+// downloadAttachment downloads the attached file with the bot token. It fails if the file is
+// larger than limit bytes.
+func (uc *Slack) downloadAttachment(ctx context.Context, file slack.File, limit int) ([]byte, error) {
+	if file.DownloadURL == "" {
+		return nil, goerr.New("download URL of file is empty", goerr.V("file_id", file.ID))
+	}
+
+	buf := &limitedBuffer{limit: limit}
+	if err := uc.slackClient.DownloadFile(ctx, file.DownloadURL, buf); err != nil {
+		return nil, goerr.Wrap(err, "failed to download attached file", goerr.V("file_id", file.ID))
+	}
+	return buf.buf.Bytes(), nil
+}
+
+func downloadFailureReason(err error) string {
+	if errors.Is(err, errAttachmentTooLarge) {
+		return "too large"
+	}
+	return "failed to download"
+}
+
+// documentBudget returns the maximum bytes of document text passed to the model in a turn. A
+// token is about 4 bytes of text, and a quarter of the context window is used for documents.
+func (uc *Slack) documentBudget(agent *agentContext) int {
+	if uc.llmFactory != nil {
+		if window := uc.llmFactory.GetConfig().ContextWindow(agent.llmProvider, agent.llmModel); window > 0 {
+			return min(window, maxDocumentBudget)
+		}
+	}
+	return defaultDocumentBudget
+}
+
+func (uc *Slack) postAttachmentNotice(ctx context.Context, slackMsg slack.Message, text string) {
+	if err := uc.slackClient.PostMessage(ctx, slackMsg.Channel, slackMsg.GetThreadTS(), text); err != nil {
+		ctxlog.From(ctx).Warn("failed to post attachment notice",
+			"error", err,
+			"channel", slackMsg.Channel,
+		)
+	}
+}
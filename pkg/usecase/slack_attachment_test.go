@@ -0,0 +1,148 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+type attachmentFixture struct {
+	contents    map[string][]byte // Downloaded contents by URL
+	slackClient *mock.SlackClientMock
+	inputs      [][]gollem.Input
+	uc          *usecase.Slack
+}
+
+func newAttachmentFixture(t *testing.T) *attachmentFixture {
+	t.Helper()
+
+	f := &attachmentFixture{contents: map[string][]byte{}}
+	f.slackClient = &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+		DownloadFileFunc: func(ctx context.Context, downloadURL string, w io.Writer) error {
+			_, err := w.Write(f.contents[downloadURL])
+			return err
+		},
+	}
+
+	llmClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+					f.inputs = append(f.inputs, input)
+					return &gollem.Response{Texts: []string{"It is an error dialog."}}, nil
+				},
+			}, nil
+		},
+	}
+
+	agentRepo := memory.NewAgentMemoryClient()
+	setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	f.uc = usecase.New(
+		usecase.WithSlackClient(f.slackClient),
+		usecase.WithRepository(memory.New()),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(llmClient),
+	)
+	return f
+}
+
+func TestHandleSlackAppMentionImageAttachments(t *testing.T) {
+	ctx := context.Background()
+
+	screenshot := slack.File{
+		ID:          "F001",
+		Name:        "screenshot.png",
+		Mimetype:    "image/png",
+		Size:        1024,
+		DownloadURL: "https://files.slack.com/files-pri/T12345-F001/download/screenshot.png",
+	}
+
+	t.Run("images are ignored with notice", func(t *testing.T) {
+		f := newAttachmentFixture(t)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> sre-helper what is this error?", "")
+		msg.Files = []slack.File{screenshot}
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.slackClient.DownloadFileCalls()).Length(0)
+		gt.A(t, f.inputs).Length(1)
+		gt.A(t, f.inputs[0]).Length(1)
+		gt.Equal(t, f.inputs[0][0].(gollem.Text), gollem.Text("what is this error?"))
+
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+		gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("screenshot.png (images are not supported)")
+	})
+}
+
+func TestHandleSlackAppMentionDocumentAttachments(t *testing.T) {
+	ctx := context.Background()
+
+	csvFile := slack.File{
+		ID:          "F101",
+		Name:        "errors.csv",
+		Mimetype:    "text/csv",
+		Size:        64,
+		DownloadURL: "https://files.slack.com/files-pri/T12345-F101/download/errors.csv",
+	}
+
+	t.Run("document text is passed as context of the turn", func(t *testing.T) {
+		f := newAttachmentFixture(t)
+		f.contents[csvFile.DownloadURL] = []byte("time,status\n10:00,500\n10:01,503\n")
+
+		msg := newThreadMention(ctx, "<@U12345BOT> sre-helper which status is the most frequent?", "")
+		msg.Files = []slack.File{
+			csvFile,
+			{ID: "F102", Name: "logs.zip", Mimetype: "application/zip", Size: 1024, DownloadURL: "https://files.slack.com/F102"},
+		}
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.inputs).Length(1)
+		gt.A(t, f.inputs[0]).Length(2)
+		gt.Equal(t, f.inputs[0][1].(gollem.Text), gollem.Text("[Attached file: errors.csv, part 1 of 1]\ntime,status\n10:00,500\n10:01,503\n"))
+
+		// Unsupported file is noticed without download
+		gt.A(t, f.slackClient.DownloadFileCalls()).Length(1)
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+		gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("logs.zip (unsupported type)")
+	})
+
+	t.Run("long document is truncated to the budget", func(t *testing.T) {
+		f := newAttachmentFixture(t)
+		line := strings.Repeat("x", 1023) + "\n"
+		f.contents[csvFile.DownloadURL] = []byte(strings.Repeat(line, 200))
+
+		msg := newThreadMention(ctx, "<@U12345BOT> sre-helper summarize the log", "")
+		msg.Files = []slack.File{csvFile}
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		// 100KB budget keeps 12 chunks of 8KB out of 25, and the omission is told to LLM
+		gt.A(t, f.inputs[0]).Length(1 + 12 + 1)
+		gt.S(t, string(f.inputs[0][13].(gollem.Text))).Contains("parts 13 to 25 are omitted")
+
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+		gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("errors.csv (truncated to 12 of 25 parts)")
+	})
+}
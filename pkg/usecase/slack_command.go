@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	slackapi "github.com/slack-go/slack"
+)
+
+const (
+	// defaultSlashCommand is used in help messages if the command name is not provided
+	defaultSlashCommand = "/tamamo"
+
+	// maxCommandListAgents limits agents shown by the list command. Slack accepts up to 50
+	// blocks in a message, and the list uses a header, a section per agent and a footer.
+	maxCommandListAgents = 40
+)
+
+// Ensure Slack implements SlackCommandUseCases interface
+var _ interfaces.SlackCommandUseCases = (*Slack)(nil)
+
+// HandleSlashCommand handles the slash command and returns an ephemeral response.
+// Mistakes of the user such as unknown agent ID are returned as a response, not an error.
+func (uc *Slack) HandleSlashCommand(ctx context.Context, cmd *slackapi.SlashCommand) (*slackapi.Msg, error) {
+	logger := ctxlog.From(ctx)
+	logger.Debug("slack slash command",
+		"command", cmd.Command,
+		"text", cmd.Text,
+		"user", cmd.UserID,
+		"channel", cmd.ChannelID,
+	)
+
+	command := cmd.Command
+	if command == "" {
+		command = defaultSlashCommand
+	}
+
+	args := strings.Fields(cmd.Text)
+	if len(args) == 0 {
+		return commandHelp(command, ""), nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		return uc.commandList(ctx)
+
+	case "info":
+		if len(args) < 2 {
+			return commandHelp(command, "Please specify an agent ID."), nil
+		}
+		return uc.commandInfo(ctx, args[1])
+
+	case "use":
+		if len(args) < 3 {
+			return commandHelp(command, "Please specify an agent ID and a link to the thread."), nil
+		}
+		return uc.commandUse(ctx, cmd, args[1], args[2])
+
+	case "reset":
+		if len(args) < 2 {
+			return commandHelp(command, "Please specify a link to the thread."), nil
+		}
+		return uc.commandReset(ctx, cmd, args[1])
+
+	case "help":
+		return commandHelp(command, ""), nil
+
+	default:
+		return commandHelp(command, fmt.Sprintf("Unknown subcommand `%s`.", args[0])), nil
+	}
+}
+
+// commandList shows active agents with their latest versions
+func (uc *Slack) commandList(ctx context.Context) (*slackapi.Msg, error) {
+	if uc.agentRepository == nil {
+		return nil, goerr.New("agent repository not available")
+	}
+
+	agents, versions, total, err := uc.agentRepository.ListActiveAgentsWithLatestVersions(ctx, 0, maxCommandListAgents)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list active agents")
+	}
+
+	if len(agents) == 0 {
+		return ephemeralMessage("No agents are available.", markdownSection(":information_source: No agents are available.")), nil
+	}
+
+	blocks := []slackapi.Block{
+		slackapi.NewHeaderBlock(slackapi.NewTextBlockObject(slackapi.PlainTextType, "Available agents", false, false)),
+	}
+	for i, a := range agents {
+		text := formatAgentSummary(a)
+		if i < len(versions) && versions[i] != nil {
+			text += "\n" + formatVersionSummary(versions[i])
+		}
+		blocks = append(blocks, markdownSection(text))
+	}
+
+	if total > len(agents) {
+		blocks = append(blocks, markdownContext(fmt.Sprintf("...and %d more agents", total-len(agents))))
+	}
+
+	return ephemeralMessage(fmt.Sprintf("%d agents are available", total), blocks...), nil
+}
+
+// commandInfo shows details of the agent
+func (uc *Slack) commandInfo(ctx context.Context, agentID string) (*slackapi.Msg, error) {
+	if uc.agentRepository == nil {
+		return nil, goerr.New("agent repository not available")
+	}
+
+	a, err := uc.agentRepository.GetAgentByAgentIDActive(ctx, agentID)
+	if err != nil {
+		return agentNotFoundMessage(agentID), nil
+	}
+
+	version, err := uc.agentRepository.GetAgentVersion(ctx, a.ID, a.Latest)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get agent version",
+			goerr.TV(apperr.AgentUUIDKey, a.ID),
+			goerr.V("version", a.Latest))
+	}
+
+	description := a.Description
+	if description == "" {
+		description = "_No description_"
+	}
+
+	fields := []*slackapi.TextBlockObject{
+		slackapi.NewTextBlockObject(slackapi.MarkdownType, fmt.Sprintf("*Agent ID*\n`%s`", a.AgentID), false, false),
+		slackapi.NewTextBlockObject(slackapi.MarkdownType, fmt.Sprintf("*Version*\n%s", version.Version), false, false),
+		slackapi.NewTextBlockObject(slackapi.MarkdownType, fmt.Sprintf("*LLM*\n%s / %s", version.LLMProvider, version.LLMModel), false, false),
+		slackapi.NewTextBlockObject(slackapi.MarkdownType, fmt.Sprintf("*Updated*\n%s", a.UpdatedAt.Format("2006-01-02 15:04")), false, false),
+	}
+	if len(version.MCPServers) > 0 {
+		names := make([]string, 0, len(version.MCPServers))
+		for _, server := range version.MCPServers {
+			names = append(names, "`"+server.Name+"`")
+		}
+		fields = append(fields, slackapi.NewTextBlockObject(slackapi.MarkdownType, "*MCP servers*\n"+strings.Join(names, ", "), false, false))
+	}
+
+	return ephemeralMessage(fmt.Sprintf("%s (%s)", a.Name, a.AgentID),
+		slackapi.NewHeaderBlock(slackapi.NewTextBlockObject(slackapi.PlainTextType, a.Name, false, false)),
+		markdownSection(description),
+		slackapi.NewSectionBlock(nil, fields, nil),
+		markdownContext(fmt.Sprintf("Mention the bot with `%s` at the beginning to start a conversation with this agent", a.AgentID)),
+	), nil
+}
+
+// commandUse binds the agent to the thread. Following mentions in the thread are answered by the agent.
+func (uc *Slack) commandUse(ctx context.Context, cmd *slackapi.SlashCommand, agentID, threadURL string) (*slackapi.Msg, error) {
+	if uc.agentRepository == nil || uc.repository == nil {
+		return nil, goerr.New("repository not available")
+	}
+
+	channelID, threadTS, err := slack.ParseThreadURL(threadURL)
+	if err != nil {
+		return invalidThreadURLMessage(threadURL), nil
+	}
+	// Threads of other channels, including ones the user can not read, can not be changed
+	if channelID != cmd.ChannelID {
+		return otherChannelThreadMessage(), nil
+	}
+
+	a, err := uc.agentRepository.GetAgentByAgentIDActive(ctx, agentID)
+	if err != nil {
+		return agentNotFoundMessage(agentID), nil
+	}
+
+	thread, err := uc.repository.GetThreadByTS(ctx, channelID, threadTS)
+	switch {
+	case errors.Is(err, slack.ErrThreadNotFound):
+		// Thread that tamamo has not joined yet starts with the agent. The version is selected
+		// in the same way as a mention starting the thread.
+		version, err := uc.selectAgentVersion(ctx, a, threadRolloutKey(channelID, threadTS))
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to select agent version",
+				goerr.TV(apperr.AgentUUIDKey, a.ID))
+		}
+		if _, err := uc.repository.GetOrPutThreadWithAgent(ctx, cmd.TeamID, channelID, threadTS, &a.ID, version.Version); err != nil {
+			return nil, goerr.Wrap(err, "failed to create thread with agent",
+				goerr.TV(apperr.ChannelIDKey, channelID),
+				goerr.V("thread_ts", threadTS))
+		}
+
+		ctxlog.From(ctx).Info("thread started with agent by slash command",
+			"channel", channelID,
+			"thread_ts", threadTS,
+			"agent_id", a.AgentID,
+			"agent_version", version.Version,
+			"user", cmd.UserID,
+		)
+
+	case err != nil:
+		return nil, goerr.Wrap(err, "failed to get thread",
+			goerr.TV(apperr.ChannelIDKey, channelID),
+			goerr.V("thread_ts", threadTS))
+
+	case thread.AgentUUID != nil && *thread.AgentUUID == a.ID:
+		// The agent already answers in the thread
+
+	default:
+		// Switched in the same way as a mention with another agent in the thread
+		req := agentSwitchRequest{
+			channelID: channelID,
+			threadTS:  threadTS,
+			userID:    cmd.UserID,
+		}
+		if err := uc.switchThreadAgent(ctx, req, thread, a); err != nil {
+			return nil, err
+		}
+	}
+
+	text := fmt.Sprintf(":white_check_mark: *%s* (`%s`) will answer mentions in the thread.", a.Name, a.AgentID)
+	return ephemeralMessage(fmt.Sprintf("%s will answer mentions in the thread", a.Name), markdownSection(text)), nil
+}
+
+// commandReset drops the conversation history of the thread. The agent bound to the thread is kept.
+func (uc *Slack) commandReset(ctx context.Context, cmd *slackapi.SlashCommand, threadURL string) (*slackapi.Msg, error) {
+	if uc.repository == nil || uc.storageRepo == nil {
+		return nil, goerr.New("repository not available")
+	}
+
+	channelID, threadTS, err := slack.ParseThreadURL(threadURL)
+	if err != nil {
+		return invalidThreadURLMessage(threadURL), nil
+	}
+	// Threads of other channels, including ones the user can not read, can not be changed
+	if channelID != cmd.ChannelID {
+		return otherChannelThreadMessage(), nil
+	}
+
+	thread, err := uc.repository.GetThreadByTS(ctx, channelID, threadTS)
+	if err != nil {
+		if errors.Is(err, slack.ErrThreadNotFound) {
+			return ephemeralMessage("No conversation history", markdownSection(":information_source: The thread has no conversation history.")), nil
+		}
+		return nil, goerr.Wrap(err, "failed to get thread",
+			goerr.TV(apperr.ChannelIDKey, channelID),
+			goerr.V("thread_ts", threadTS))
+	}
+
+	// Histories are append only. An empty history becomes the latest one and the next
+	// conversation starts from scratch.
+	record := slack.NewHistory(ctx, thread.ID)
+	if err := uc.storageRepo.SaveHistoryJSON(ctx, thread.ID, record.ID, &gollem.History{}); err != nil {
+		return nil, goerr.Wrap(err, "failed to save empty history",
+			goerr.TV(apperr.ThreadIDKey, thread.ID))
+	}
+	if err := uc.repository.PutHistory(ctx, record); err != nil {
+		return nil, goerr.Wrap(err, "failed to save history record",
+			goerr.TV(apperr.ThreadIDKey, thread.ID))
+	}
+
+	ctxlog.From(ctx).Info("thread history reset by slash command",
+		"thread_id", thread.ID,
+		"history_id", record.ID,
+		"user", cmd.UserID,
+	)
+
+	return ephemeralMessage("Conversation history was cleared",
+		markdownSection(":broom: Conversation history of the thread was cleared."),
+	), nil
+}
+
+func commandHelp(command, notice string) *slackapi.Msg {
+	var blocks []slackapi.Block
+	if notice != "" {
+		blocks = append(blocks, markdownSection(":warning: "+notice))
+	}
+
+	usage := strings.Join([]string{
+		"*Usage*",
+		fmt.Sprintf("• `%s list` List available agents", command),
+		fmt.Sprintf("• `%s info <agent-id>` Show details of the agent", command),
+		fmt.Sprintf("• `%s use <agent-id> <thread-link>` Switch the agent answering in the thread", command),
+		fmt.Sprintf("• `%s reset <thread-link>` Clear the conversation history of the thread", command),
+	}, "\n")
+	blocks = append(blocks,
+		markdownSection(usage),
+		markdownContext("A thread link can be copied with \"Copy link\" in the menu of a message in the thread."),
+	)
+
+	return ephemeralMessage("Usage of "+command, blocks...)
+}
+
+func agentNotFoundMessage(agentID string) *slackapi.Msg {
+	return ephemeralMessage("Agent not found",
+		markdownSection(fmt.Sprintf(":warning: Agent `%s` is not found or archived.", agentID)))
+}
+
+func invalidThreadURLMessage(threadURL string) *slackapi.Msg {
+	return ephemeralMessage("Invalid thread link",
+		markdownSection(fmt.Sprintf(":warning: `%s` is not a link to a Slack message.", strings.Trim(threadURL, "<>"))))
+}
+
+func otherChannelThreadMessage() *slackapi.Msg {
+	return ephemeralMessage("Thread in another channel",
+		markdownSection(":warning: Please run the command in the channel of the thread."))
+}
+
+func formatAgentSummary(a *agent.Agent) string {
+	text := fmt.Sprintf("*%s* `%s`", a.Name, a.AgentID)
+	if a.Description != "" {
+		text += "\n" + a.Description
+	}
+	return text
+}
+
+func formatVersionSummary(v *agent.AgentVersion) string {
+	return fmt.Sprintf("%s · %s / %s", v.Version, v.LLMProvider, v.LLMModel)
+}
+
+func ephemeralMessage(text string, blocks ...slackapi.Block) *slackapi.Msg {
+	return &slackapi.Msg{
+		ResponseType: slackapi.ResponseTypeEphemeral,
+		Text:         text,
+		Blocks:       slackapi.Blocks{BlockSet: blocks},
+	}
+}
+
+func markdownSection(text string) *slackapi.SectionBlock {
+	return slackapi.NewSectionBlock(slackapi.NewTextBlockObject(slackapi.MarkdownType, text, false, false), nil, nil)
+}
+
+func markdownContext(text string) *slackapi.ContextBlock {
+	return slackapi.NewContextBlock("", slackapi.NewTextBlockObject(slackapi.MarkdownType, text, false, false))
+}
@@ -0,0 +1,231 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	slackapi "github.com/slack-go/slack"
+)
+
+const testThreadURL = "https://example.slack.com/archives/C11111/p1234567890123456"
+
+func slashCommand(text string) *slackapi.SlashCommand {
+	return &slackapi.SlashCommand{
+		TeamID:    "T12345",
+		ChannelID: "C11111",
+		UserID:    "U67890USER",
+		Command:   "/tamamo",
+		Text:      text,
+	}
+}
+
+// blocksJSON returns the blocks as JSON to check the content in tests
+func blocksJSON(t *testing.T, msg *slackapi.Msg) string {
+	t.Helper()
+	data, err := json.Marshal(msg.Blocks)
+	gt.NoError(t, err)
+	return string(data)
+}
+
+func TestHandleSlashCommand(t *testing.T) {
+	ctx := context.Background()
+
+	agentRepo := memory.NewAgentMemoryClient()
+	sreHelper := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	archived := &agent.Agent{
+		ID:      types.NewUUID(ctx),
+		AgentID: "old-helper",
+		Name:    "Old Helper",
+		Status:  agent.StatusArchived,
+		Latest:  "1.0.0",
+	}
+	gt.NoError(t, agentRepo.CreateAgent(ctx, archived))
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID: archived.ID,
+		Version:   "1.0.0",
+	}))
+
+	newUseCase := func(repo *memory.Client) *usecase.Slack {
+		return usecase.New(
+			usecase.WithRepository(repo),
+			usecase.WithAgentRepository(agentRepo),
+			usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		)
+	}
+
+	t.Run("help", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand(""))
+		gt.NoError(t, err)
+		gt.Equal(t, msg.ResponseType, slackapi.ResponseTypeEphemeral)
+		gt.S(t, blocksJSON(t, msg)).Contains("/tamamo use")
+	})
+
+	t.Run("unknown subcommand", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand("dance"))
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("Unknown subcommand")
+	})
+
+	t.Run("list shows only active agents", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand("list"))
+		gt.NoError(t, err)
+		gt.Equal(t, msg.ResponseType, slackapi.ResponseTypeEphemeral)
+
+		blocks := blocksJSON(t, msg)
+		gt.S(t, blocks).Contains("sre-helper")
+		gt.False(t, strings.Contains(blocks, "old-helper"))
+	})
+
+	t.Run("list fits in block limit of Slack", func(t *testing.T) {
+		manyAgents := memory.NewAgentMemoryClient()
+		for i := range 45 {
+			setupToolTestAgent(t, manyAgents, fmt.Sprintf("agent-%02d", i))
+		}
+		uc := usecase.New(usecase.WithAgentRepository(manyAgents))
+
+		msg, err := uc.HandleSlashCommand(ctx, slashCommand("list"))
+		gt.NoError(t, err)
+		gt.True(t, len(msg.Blocks.BlockSet) <= 50)
+		gt.S(t, blocksJSON(t, msg)).Contains("and 5 more agents")
+	})
+
+	t.Run("info", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand("info sre-helper"))
+		gt.NoError(t, err)
+		blocks := blocksJSON(t, msg)
+		gt.S(t, blocks).Contains("SRE Helper")
+		gt.S(t, blocks).Contains("1.0.0")
+	})
+
+	t.Run("info of archived agent", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand("info old-helper"))
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("not found")
+	})
+
+	t.Run("use binds agent to new thread", func(t *testing.T) {
+		repo := memory.New()
+		msg, err := newUseCase(repo).HandleSlashCommand(ctx, slashCommand("use sre-helper "+testThreadURL))
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("will answer mentions")
+
+		thread, err := repo.GetThreadByTS(ctx, "C11111", "1234567890.123456")
+		gt.NoError(t, err)
+		gt.NotNil(t, thread.AgentUUID)
+		gt.Equal(t, *thread.AgentUUID, sreHelper.ID)
+		gt.Equal(t, thread.AgentVersion, "1.0.0")
+	})
+
+	t.Run("use rebinds existing thread", func(t *testing.T) {
+		repo := memory.New()
+		otherAgent := types.NewUUID(ctx)
+		existing, err := repo.GetOrPutThreadWithAgent(ctx, "T12345", "C11111", "1234567890.123456", &otherAgent, "2.0.0")
+		gt.NoError(t, err)
+		gt.NoError(t, repo.PutThreadMessage(ctx, existing.ID, &slack.Message{
+			ID:        types.NewMessageID(ctx),
+			ThreadID:  existing.ID,
+			UserID:    "U67890USER",
+			UserName:  "alice",
+			Text:      "The primary database is slow",
+			Timestamp: "1234567890.123456",
+			CreatedAt: time.Now(),
+		}))
+
+		slackClient := &mock.SlackClientMock{
+			PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+				return nil
+			},
+		}
+		uc := usecase.New(
+			usecase.WithSlackClient(slackClient),
+			usecase.WithRepository(repo),
+			usecase.WithAgentRepository(agentRepo),
+			usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		)
+		_, err = uc.HandleSlashCommand(ctx, slashCommand("use sre-helper <"+testThreadURL+">"))
+		gt.NoError(t, err)
+
+		thread, err := repo.GetThread(ctx, existing.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, *thread.AgentUUID, sreHelper.ID)
+		gt.Equal(t, thread.AgentVersion, "1.0.0")
+
+		// Switch is announced in the thread and recorded in the same way as a mention
+		gt.A(t, slackClient.PostMessageCalls()).Length(1)
+		gt.Equal(t, slackClient.PostMessageCalls()[0].ThreadTS, "1234567890.123456")
+		gt.S(t, slackClient.PostMessageCalls()[0].Text).Contains("to *SRE Helper*")
+
+		histories, err := repo.ListHistories(ctx, existing.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(1)
+		gt.Equal(t, *histories[0].AgentUUID, sreHelper.ID)
+		gt.S(t, histories[0].Handover).Contains("[alice] The primary database is slow")
+	})
+
+	t.Run("use with invalid thread link", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand("use sre-helper not-a-link"))
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("is not a link")
+	})
+
+	t.Run("use with thread in another channel", func(t *testing.T) {
+		repo := memory.New()
+		cmd := slashCommand("use sre-helper " + testThreadURL)
+		cmd.ChannelID = "C22222"
+
+		msg, err := newUseCase(repo).HandleSlashCommand(ctx, cmd)
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("channel of the thread")
+
+		_, err = repo.GetThreadByTS(ctx, "C11111", "1234567890.123456")
+		gt.Error(t, err)
+	})
+
+	t.Run("reset saves empty history", func(t *testing.T) {
+		repo := memory.New()
+		thread, err := repo.GetOrPutThread(ctx, "T12345", "C11111", "1234567890.123456")
+		gt.NoError(t, err)
+
+		msg, err := newUseCase(repo).HandleSlashCommand(ctx, slashCommand("reset "+testThreadURL))
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("cleared")
+
+		latest, err := repo.GetLatestHistory(ctx, thread.ID)
+		gt.NoError(t, err)
+		gt.Equal(t, latest.ThreadID, thread.ID)
+	})
+
+	t.Run("reset thread in another channel", func(t *testing.T) {
+		repo := memory.New()
+		thread, err := repo.GetOrPutThread(ctx, "T12345", "C11111", "1234567890.123456")
+		gt.NoError(t, err)
+		cmd := slashCommand("reset " + testThreadURL)
+		cmd.ChannelID = "C22222"
+
+		msg, err := newUseCase(repo).HandleSlashCommand(ctx, cmd)
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("channel of the thread")
+
+		_, err = repo.GetLatestHistory(ctx, thread.ID)
+		gt.Error(t, err)
+	})
+
+	t.Run("reset unknown thread", func(t *testing.T) {
+		msg, err := newUseCase(memory.New()).HandleSlashCommand(ctx, slashCommand("reset "+testThreadURL))
+		gt.NoError(t, err)
+		gt.S(t, blocksJSON(t, msg)).Contains("no conversation history")
+	})
+}
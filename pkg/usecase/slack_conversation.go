@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// maxConversationMessageLength is the maximum length of a message posted to a conversation
+const maxConversationMessageLength = 32 * 1024
+
+// Ensure Slack implements interfaces.ConversationUseCases
+var _ interfaces.ConversationUseCases = (*Slack)(nil)
+
+// CreateConversation starts a conversation of the user with the agent through the chat API. The
+// conversation is bound to the version of the agent as threads in Slack are.
+func (uc *Slack) CreateConversation(ctx context.Context, req *interfaces.CreateConversationRequest) (*slack.Thread, error) {
+	if uc.repository == nil {
+		return nil, goerr.New("thread repository not configured")
+	}
+	if !req.UserID.IsValid() || req.TeamID == "" {
+		return nil, goerr.New("user is required to start conversation", goerr.T(apperr.ErrTagUnauthorized))
+	}
+
+	agent, err := uc.resolveAgentToRun(ctx, req.AgentUUID, req.AgentVersion)
+	if err != nil {
+		return nil, err
+	}
+	if agent.draftAuthor != "" && agent.draftAuthor != req.UserID {
+		return nil, goerr.Wrap(slack.ErrDraftNotAllowed, "user is not the author of the draft version",
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+			goerr.V("version", agent.version),
+			goerr.T(apperr.ErrTagForbidden))
+	}
+
+	thread := slack.NewConversation(ctx, req.TeamID, req.UserID, agent.uuid, agent.version)
+	if err := uc.repository.PutThread(ctx, thread); err != nil {
+		return nil, goerr.Wrap(err, "failed to save conversation",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid))
+	}
+
+	ctxlog.From(ctx).Info("started conversation through chat API",
+		"thread_id", thread.ID,
+		"user_id", req.UserID,
+		"agent_uuid", agent.uuid,
+		"agent_version", agent.version,
+	)
+	return thread, nil
+}
+
+// GetConversation returns the conversation of the agent started by the user, with its messages
+func (uc *Slack) GetConversation(ctx context.Context, agentUUID types.UUID, id types.ThreadID, userID types.UserID) (*slack.Thread, []*slack.Message, error) {
+	thread, err := uc.getConversation(ctx, agentUUID, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messages, err := uc.repository.GetThreadMessages(ctx, thread.ID)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err, "failed to get messages of conversation", goerr.TV(apperr.ThreadIDKey, thread.ID))
+	}
+	return thread, messages, nil
+}
+
+// PostConversationMessage generates the reply of the agent to the message with the history of the
+// conversation, and saves both of them. The reply is passed to stream while it is generated.
+func (uc *Slack) PostConversationMessage(ctx context.Context, req *interfaces.ConversationMessageRequest, stream interfaces.ConversationStream) (*interfaces.ConversationReply, error) {
+	logger := ctxlog.From(ctx)
+
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return nil, goerr.New("message is required", goerr.T(apperr.ErrTagValidation))
+	}
+	if len(message) > maxConversationMessageLength {
+		return nil, goerr.New("message is too long",
+			goerr.V("length", len(message)),
+			goerr.V("max", maxConversationMessageLength),
+			goerr.T(apperr.ErrTagValidation))
+	}
+
+	thread, err := uc.getConversation(ctx, req.AgentUUID, req.ConversationID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := uc.resolveAgentToRun(ctx, *thread.AgentUUID, thread.AgentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// The message is handled as a message in the thread, so that the system prompt and tools
+	// authorized by integrations of the user work as in Slack
+	userMsg := slack.NewConversationMessage(ctx, thread, message)
+	userMsg.UserID = req.UserID.String()
+	if uc.userRepo != nil {
+		if u, err := uc.userRepo.GetByID(ctx, req.UserID); err == nil && u != nil {
+			userMsg.UserID = u.SlackID
+			userMsg.UserName = u.DisplayName
+		}
+	}
+	uc.putConversationMessage(ctx, thread, userMsg)
+
+	history, snapshot := uc.loadThreadHistory(ctx, thread.ID)
+
+	llmClient, err := uc.getLLMClient(ctx, agent, *userMsg)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get LLM client")
+	}
+
+	// Older turns are summarized if the history is close to the context window of the model
+	history, parent := uc.compactHistory(ctx, llmClient, agent, thread.ID, snapshot, history)
+
+	session, tools, closeSession, err := uc.newAgentSession(ctx, llmClient, agent, *userMsg, history, parent)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create LLM session",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+		)
+	}
+	defer closeSession()
+
+	var resp *gollem.Response
+	if stream != nil {
+		resp, err = generateWithToolsStream(ctx, session, tools, &conversationStream{stream: stream}, gollem.Text(message))
+	} else {
+		resp, err = generateWithTools(ctx, session, tools, gollem.Text(message))
+	}
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to generate content with LLM",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+		)
+	}
+	text := responseTextOf(resp)
+
+	replyMsg := slack.NewConversationMessage(ctx, thread, text)
+	replyMsg.BotID = agent.agentID
+	replyMsg.UserName = agent.name
+	uc.putConversationMessage(ctx, thread, replyMsg)
+
+	reply := &interfaces.ConversationReply{
+		AgentVersion: agent.version,
+		Response:     text,
+	}
+	if record := uc.saveThreadHistory(ctx, thread.ID, agent, parent, turnRecord{messageTS: userMsg.Timestamp, message: message}, session); record != nil {
+		reply.HistoryID = record.ID
+	}
+
+	logger.Info("responded to conversation through chat API",
+		"thread_id", thread.ID,
+		"user_id", req.UserID,
+		"agent_uuid", agent.uuid,
+		"agent_version", agent.version,
+	)
+	return reply, nil
+}
+
+// getConversation returns the conversation of the agent started by the user. Threads in Slack and
+// conversations of other users are not distinguished from unknown conversations.
+func (uc *Slack) getConversation(ctx context.Context, agentUUID types.UUID, id types.ThreadID, userID types.UserID) (*slack.Thread, error) {
+	if uc.repository == nil {
+		return nil, goerr.New("thread repository not configured")
+	}
+
+	thread, err := uc.repository.GetThread(ctx, id)
+	if err != nil {
+		if errors.Is(err, slack.ErrThreadNotFound) {
+			return nil, goerr.Wrap(err, "conversation not found", goerr.TV(apperr.ThreadIDKey, id), goerr.T(apperr.ErrTagThreadNotFound))
+		}
+		return nil, goerr.Wrap(err, "failed to get conversation", goerr.TV(apperr.ThreadIDKey, id))
+	}
+
+	if !thread.IsConversation() || thread.AgentUUID == nil || *thread.AgentUUID != agentUUID || thread.UserID != userID {
+		return nil, goerr.New("conversation not found", goerr.TV(apperr.ThreadIDKey, id), goerr.T(apperr.ErrTagThreadNotFound))
+	}
+	return thread, nil
+}
+
+// putConversationMessage saves the message of the conversation. Failures are logged and ignored
+// because messages are not used to generate replies.
+func (uc *Slack) putConversationMessage(ctx context.Context, thread *slack.Thread, msg *slack.Message) {
+	if err := uc.repository.PutThreadMessage(ctx, thread.ID, msg); err != nil {
+		ctxlog.From(ctx).Warn("failed to save message of conversation",
+			"error", err,
+			"thread_id", thread.ID,
+			"message_id", msg.ID,
+		)
+	}
+}
+
+// conversationStream passes the response of LLM to the stream of the conversation
+type conversationStream struct {
+	stream interfaces.ConversationStream
+}
+
+func (x *conversationStream) appendText(ctx context.Context, delta string) {
+	if delta == "" {
+		return
+	}
+	x.stream.WriteText(ctx, delta)
+}
+
+func (x *conversationStream) setToolStatus(ctx context.Context, calls []*gollem.FunctionCall) {
+	names := make([]string, 0, len(calls))
+	for _, call := range calls {
+		names = append(names, call.Name)
+	}
+	x.stream.WriteToolCalls(ctx, names)
+}
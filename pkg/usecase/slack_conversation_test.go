@@ -0,0 +1,164 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+// recordedStream records the reply passed to the conversation stream
+type recordedStream struct {
+	text  strings.Builder
+	tools [][]string
+}
+
+func (x *recordedStream) WriteText(ctx context.Context, delta string) {
+	x.text.WriteString(delta)
+}
+
+func (x *recordedStream) WriteToolCalls(ctx context.Context, names []string) {
+	x.tools = append(x.tools, names)
+}
+
+func TestConversation(t *testing.T) {
+	ctx := context.Background()
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	var inputs []string
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateStreamFunc: func(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error) {
+					if text, ok := input[0].(gollem.Text); ok {
+						inputs = append(inputs, string(text))
+					}
+					return streamOf(
+						&gollem.Response{Texts: []string{"web-1 is "}},
+						&gollem.Response{Texts: []string{"healthy."}},
+					), nil
+				},
+			}, nil
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(mockLLMClient),
+	)
+
+	userID := types.NewUserID(ctx)
+	conv, err := uc.CreateConversation(ctx, &interfaces.CreateConversationRequest{
+		AgentUUID: agentObj.ID,
+		TeamID:    "T12345",
+		UserID:    userID,
+	})
+	gt.NoError(t, err)
+	gt.True(t, conv.IsConversation())
+	gt.Equal(t, conv.AgentVersion, "1.0.0")
+	gt.Equal(t, conv.ChannelID, slack.ConversationChannelID)
+
+	newReq := func(message string) *interfaces.ConversationMessageRequest {
+		return &interfaces.ConversationMessageRequest{
+			AgentUUID:      agentObj.ID,
+			ConversationID: conv.ID,
+			UserID:         userID,
+			Message:        message,
+		}
+	}
+
+	t.Run("reply is streamed and saved", func(t *testing.T) {
+		stream := &recordedStream{}
+		reply, err := uc.PostConversationMessage(ctx, newReq("Is web-1 healthy?"), stream)
+		gt.NoError(t, err)
+		gt.Equal(t, reply.Response, "web-1 is healthy.")
+		gt.Equal(t, reply.AgentVersion, "1.0.0")
+		gt.Equal(t, stream.text.String(), "web-1 is healthy.")
+		gt.True(t, reply.HistoryID.IsValid())
+		gt.Equal(t, inputs[len(inputs)-1], "Is web-1 healthy?")
+
+		// History is shared with threads in Slack
+		histories, err := repo.ListHistories(ctx, conv.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(1)
+
+		_, messages, err := uc.GetConversation(ctx, agentObj.ID, conv.ID, userID)
+		gt.NoError(t, err)
+		gt.A(t, messages).Length(2)
+		gt.Equal(t, messages[0].Text, "Is web-1 healthy?")
+		gt.Equal(t, messages[1].Text, "web-1 is healthy.")
+		gt.Equal(t, messages[1].BotID, "sre-helper")
+	})
+
+	t.Run("conversation continues with the history", func(t *testing.T) {
+		_, err := uc.PostConversationMessage(ctx, newReq("And web-2?"), &recordedStream{})
+		gt.NoError(t, err)
+
+		histories, err := repo.ListHistories(ctx, conv.ID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(2)
+	})
+
+	testCases := []struct {
+		name   string
+		modify func(req *interfaces.ConversationMessageRequest)
+		status int
+	}{
+		{
+			name:   "empty message",
+			modify: func(req *interfaces.ConversationMessageRequest) { req.Message = "  " },
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "conversation of another user",
+			modify: func(req *interfaces.ConversationMessageRequest) { req.UserID = types.NewUserID(ctx) },
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "conversation of another agent",
+			modify: func(req *interfaces.ConversationMessageRequest) { req.AgentUUID = types.NewUUID(ctx) },
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unknown conversation",
+			modify: func(req *interfaces.ConversationMessageRequest) { req.ConversationID = types.NewThreadID(ctx) },
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newReq("Is web-1 healthy?")
+			tc.modify(req)
+
+			_, err := uc.PostConversationMessage(ctx, req, nil)
+			gt.Error(t, err)
+			gt.Equal(t, apperr.HTTPStatusFromError(err), tc.status)
+		})
+	}
+
+	t.Run("threads in Slack are not conversations", func(t *testing.T) {
+		thread, err := repo.GetOrPutThreadWithAgent(ctx, "T12345", "C12345", "1700000000.000100", &agentObj.ID, "1.0.0")
+		gt.NoError(t, err)
+
+		_, _, err = uc.GetConversation(ctx, agentObj.ID, thread.ID, userID)
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusNotFound)
+	})
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+const (
+	// defaultMaxDelegationDepth is used when the maximum delegation depth is not configured
+	defaultMaxDelegationDepth = 2
+
+	// maxDelegationCalls caps the number of consultations in a single turn including nested ones
+	maxDelegationCalls = 8
+
+	// maxDelegationCandidates limits the number of agents exposed as delegation tools
+	maxDelegationCandidates = 100
+
+	// maxDelegationToolNameLength is the maximum tool name length accepted by LLM providers
+	maxDelegationToolNameLength = 64
+)
+
+var delegationToolNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// delegationBudget counts consultations in a single turn so that agents can not consult each
+// other endlessly
+type delegationBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+func (b *delegationBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// buildDelegationTools creates tools to consult other agents. Only agents that enabled delegation
+// on their latest version and are allowed by the delegation of the consulting agent are exposed.
+// Agents already in chain are excluded to prevent loops, and no tool is built beyond the maximum
+// depth. budget is shared by nested consultations and created if nil.
+func (uc *Slack) buildDelegationTools(ctx context.Context, from *agentContext, slackMsg slack.Message, chain []types.UUID, budget *delegationBudget) []gollem.Tool {
+	logger := ctxlog.From(ctx)
+
+	if from == nil || from.uuid == generalModeUUID || !from.delegation.IsEnabled() || uc.agentRepository == nil {
+		return nil
+	}
+
+	chain = append(slices.Clone(chain), from.uuid)
+	if len(chain) > uc.delegationDepthLimit() {
+		return nil
+	}
+
+	if budget == nil {
+		budget = &delegationBudget{remaining: maxDelegationCalls}
+	}
+
+	agents, versions, _, err := uc.agentRepository.ListActiveAgentsWithLatestVersions(ctx, 0, maxDelegationCandidates)
+	if err != nil {
+		logger.Warn("failed to list agents for delegation, continue without delegation tools",
+			"error", err,
+			"agent_uuid", from.uuid,
+		)
+		return nil
+	}
+
+	var tools []gollem.Tool
+	for i, target := range agents {
+		if i >= len(versions) || versions[i] == nil {
+			continue
+		}
+		if slices.Contains(chain, target.ID) {
+			continue
+		}
+		if !from.delegation.CanConsult(target.AgentID) || !versions[i].Delegation.IsEnabled() {
+			continue
+		}
+
+		tools = append(tools, &delegationTool{
+			uc:       uc,
+			target:   target,
+			version:  versions[i],
+			slackMsg: slackMsg,
+			chain:    chain,
+			budget:   budget,
+		})
+	}
+
+	return tools
+}
+
+// delegationDepthLimit returns the maximum depth of nested consultations
+func (uc *Slack) delegationDepthLimit() int {
+	if uc.maxDelegationDepth <= 0 {
+		return defaultMaxDelegationDepth
+	}
+	return uc.maxDelegationDepth
+}
+
+// consultAgent asks the agent a question with its own system prompt, model and tools, and returns
+// its answer. chain is the agents consulting in order, the last one is the direct consulter.
+func (uc *Slack) consultAgent(ctx context.Context, target *agentContext, slackMsg slack.Message, chain []types.UUID, budget *delegationBudget, consulter, question string) (string, error) {
+	logger := ctxlog.From(ctx)
+
+	llmClient, err := uc.getLLMClient(ctx, target, slackMsg)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to get LLM client for delegation",
+			goerr.TV(apperr.AgentUUIDKey, target.uuid))
+	}
+
+	tools := uc.buildAgentTools(ctx, target, slackMsg)
+	tools = append(tools, uc.buildDelegationTools(ctx, target, slackMsg, chain, budget)...)
+
+	sessionOptions := []gollem.SessionOption{
+		gollem.WithSessionSystemPrompt(uc.renderSystemPrompt(ctx, target, slackMsg)),
+	}
+	if len(tools) > 0 {
+		sessionOptions = append(sessionOptions, gollem.WithSessionTools(tools...))
+	}
+
+	session, err := llmClient.NewSession(ctx, sessionOptions...)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create LLM session for delegation",
+			goerr.TV(apperr.AgentUUIDKey, target.uuid))
+	}
+
+	prompt := fmt.Sprintf("Another assistant (%s) is consulting you while answering a user in Slack. "+
+		"Answer the following question concisely. Your answer is returned to the assistant, not to the user.\n\n%s", consulter, question)
+
+	resp, err := generateWithTools(ctx, session, tools, gollem.Text(prompt))
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to generate delegated answer",
+			goerr.TV(apperr.AgentUUIDKey, target.uuid))
+	}
+
+	logger.Info("consulted agent",
+		"agent_uuid", target.uuid,
+		"agent_id", target.agentID,
+		"agent_version", target.version,
+		"depth", len(chain),
+		"channel", slackMsg.Channel,
+		"thread", slackMsg.GetThreadTS(),
+	)
+
+	return responseTextOf(resp), nil
+}
+
+// delegationTool is a gollem tool that sends a question to another agent and returns its answer
+type delegationTool struct {
+	uc       *Slack
+	target   *agent.Agent
+	version  *agent.AgentVersion
+	slackMsg slack.Message
+	chain    []types.UUID
+	budget   *delegationBudget
+}
+
+// Ensure delegationTool implements gollem.Tool interface
+var _ gollem.Tool = (*delegationTool)(nil)
+
+// Spec returns the tool specification
+func (t *delegationTool) Spec() gollem.ToolSpec {
+	description := fmt.Sprintf("Consult the agent %q (%s) with a question and get its answer.", t.target.Name, t.target.AgentID)
+	if t.target.Description != "" {
+		description += " Agent description: " + t.target.Description
+	}
+
+	return gollem.ToolSpec{
+		Name:        t.name(),
+		Description: description,
+		Parameters: map[string]*gollem.Parameter{
+			"question": {
+				Type:        gollem.TypeString,
+				Description: "Self-contained question for the agent including necessary context. The agent can not see the Slack thread.",
+			},
+		},
+		Required: []string{"question"},
+	}
+}
+
+// name returns the tool name derived from the agent ID
+func (t *delegationTool) name() string {
+	name := "consult_" + delegationToolNameInvalidChars.ReplaceAllString(t.target.AgentID, "_")
+	if len(name) > maxDelegationToolNameLength {
+		name = name[:maxDelegationToolNameLength]
+	}
+	return name
+}
+
+// Run consults the agent with the question
+func (t *delegationTool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	question, _ := args["question"].(string)
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, goerr.New("question is required")
+	}
+
+	if !t.budget.take() {
+		return nil, goerr.New("too many consultations in this turn, answer without consulting other agents",
+			goerr.V("max", maxDelegationCalls))
+	}
+
+	consulter := "another agent"
+	if t.uc.agentRepository != nil {
+		if from, err := t.uc.agentRepository.GetAgent(ctx, t.chain[len(t.chain)-1]); err == nil {
+			consulter = from.Name
+		}
+	}
+
+	target := &agentContext{
+		uuid:           t.target.ID,
+		agentID:        t.target.AgentID,
+		name:           t.target.Name,
+		version:        t.version.Version,
+		systemPrompt:   t.version.SystemPrompt,
+		promptTemplate: t.version.PromptTemplate,
+		promptVars:     t.version.PromptVariables,
+		llmProvider:    string(t.version.LLMProvider),
+		llmModel:       t.version.LLMModel,
+		delegation:     t.version.Delegation,
+	}
+
+	answer, err := t.uc.consultAgent(ctx, target, t.slackMsg, t.chain, t.budget, consulter, question)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"agent_id": t.target.AgentID,
+		"answer":   answer,
+	}, nil
+}
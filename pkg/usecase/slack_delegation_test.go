@@ -0,0 +1,129 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+func createDelegationTestAgent(t *testing.T, agentRepo *memory.AgentMemoryClient, agentID, name string, delegation *agent.Delegation) *agent.Agent {
+	t.Helper()
+	ctx := context.Background()
+
+	agentObj := &agent.Agent{
+		ID:      types.NewUUID(ctx),
+		AgentID: agentID,
+		Name:    name,
+		Status:  agent.StatusActive,
+		Latest:  "1.0.0",
+	}
+	gt.NoError(t, agentRepo.CreateAgent(ctx, agentObj))
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.0.0",
+		SystemPrompt: "You are " + name + ".",
+		Delegation:   delegation,
+	}))
+	return agentObj
+}
+
+// scriptedSession returns function calls first and then the final text
+func scriptedSession(inputs *[][]gollem.Input, calls []*gollem.FunctionCall, text string) *MockSession {
+	count := 0
+	return &MockSession{
+		generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+			*inputs = append(*inputs, input)
+			count++
+			if count == 1 && len(calls) > 0 {
+				return &gollem.Response{FunctionCalls: calls}, nil
+			}
+			return &gollem.Response{Texts: []string{text}}, nil
+		},
+	}
+}
+
+func TestHandleSlackAppMentionDelegation(t *testing.T) {
+	ctx := context.Background()
+
+	agentRepo := memory.NewAgentMemoryClient()
+	createDelegationTestAgent(t, agentRepo, "security-reviewer", "Security Reviewer", &agent.Delegation{Enabled: true})
+	createDelegationTestAgent(t, agentRepo, "sql-helper", "SQL Helper", &agent.Delegation{Enabled: true})
+	createDelegationTestAgent(t, agentRepo, "runbook", "Runbook", nil)
+
+	var topInputs, consultedInputs [][]gollem.Input
+	sessions := []gollem.Session{
+		// Security reviewer consults SQL helper and the runbook agent that did not opt in
+		scriptedSession(&topInputs, []*gollem.FunctionCall{
+			{ID: "call-1", Name: "consult_sql-helper", Arguments: map[string]any{"question": "Is this query injectable?"}},
+			{ID: "call-2", Name: "consult_runbook", Arguments: map[string]any{"question": "Any runbook?"}},
+		}, "The query is safe."),
+		// SQL helper tries to consult the security reviewer back
+		scriptedSession(&consultedInputs, []*gollem.FunctionCall{
+			{ID: "call-3", Name: "consult_security-reviewer", Arguments: map[string]any{"question": "Loop?"}},
+		}, "It uses placeholders, so it is not injectable."),
+	}
+
+	llmClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			session := sessions[0]
+			sessions = sessions[1:]
+			return session, nil
+		},
+	}
+
+	slackClient := &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(slackClient),
+		usecase.WithRepository(memory.New()),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(llmClient),
+	)
+
+	msg := newThreadMention(ctx, "<@U12345BOT> security-reviewer review this query", "")
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, msg))
+
+	gt.A(t, llmClient.NewSessionCalls()).Length(2)
+
+	// Consulted agent receives the question, and can not consult the consulting agent back
+	gt.A(t, consultedInputs).Length(2)
+	gt.S(t, string(consultedInputs[0][0].(gollem.Text))).Contains("Is this query injectable?")
+	loopResp := consultedInputs[1][0].(gollem.FunctionResponse)
+	gt.Equal(t, loopResp.Name, "consult_security-reviewer")
+	gt.Error(t, loopResp.Error)
+
+	// Answer of the consulted agent is returned to the consulting agent
+	gt.A(t, topInputs).Length(2)
+	gt.A(t, topInputs[1]).Length(2)
+	answer := topInputs[1][0].(gollem.FunctionResponse)
+	gt.NoError(t, answer.Error)
+	gt.Equal(t, answer.Data["agent_id"], "sql-helper")
+	gt.Equal(t, answer.Data["answer"], "It uses placeholders, so it is not injectable.")
+
+	// Agent that did not opt in is not exposed
+	notExposed := topInputs[1][1].(gollem.FunctionResponse)
+	gt.Equal(t, notExposed.Name, "consult_runbook")
+	gt.Error(t, notExposed.Error)
+}
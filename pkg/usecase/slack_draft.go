@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+// draftAuthorOf returns the author of the version if it is a draft that only the author can use.
+// Drafts can not be rollout candidates, so a draft is never selected for other users.
+func draftAuthorOf(agentVersion *agent.AgentVersion) types.UserID {
+	if !agentVersion.IsDraft() {
+		return ""
+	}
+	return agentVersion.Author
+}
+
+// resolveDraftAgent resolves the newest draft version of the agent created by the user who sent
+// the mention, e.g. @tamamo sre-helper@draft
+func (uc *Slack) resolveDraftAgent(ctx context.Context, slackMsg slack.Message, agentMention *slack.AgentMention) (*agentContext, error) {
+	if uc.agentRepository == nil {
+		return nil, goerr.New("agent repository not available")
+	}
+
+	agentInfo, err := uc.agentRepository.GetAgentByAgentIDActive(ctx, agentMention.AgentID)
+	if err != nil {
+		return nil, goerr.Wrap(slack.ErrAgentNotFound, "agent not found or archived",
+			goerr.TV(apperr.AgentIDKey, agentMention.AgentID))
+	}
+
+	requester, err := uc.requesterUserID(ctx, slackMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := uc.findUserDraft(ctx, agentInfo, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxlog.From(ctx).Info("resolved draft version of agent",
+		"agent_id", agentInfo.AgentID,
+		"agent_uuid", agentInfo.ID,
+		"version", draft.Version,
+		"user_id", requester,
+	)
+
+	return &agentContext{
+		uuid:           agentInfo.ID,
+		agentID:        agentInfo.AgentID,
+		name:           agentInfo.Name,
+		version:        draft.Version,
+		systemPrompt:   draft.SystemPrompt,
+		promptTemplate: draft.PromptTemplate,
+		promptVars:     draft.PromptVariables,
+		llmProvider:    string(draft.LLMProvider),
+		llmModel:       draft.LLMModel,
+		mcpServers:     draft.MCPServers,
+		delegation:     draft.Delegation,
+		draftAuthor:    draft.Author,
+	}, nil
+}
+
+// findUserDraft returns the newest draft version of the agent created by the user
+func (uc *Slack) findUserDraft(ctx context.Context, agentInfo *agent.Agent, userID types.UserID) (*agent.AgentVersion, error) {
+	versions, err := uc.agentRepository.ListAgentVersions(ctx, agentInfo.ID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list agent versions", goerr.TV(apperr.AgentUUIDKey, agentInfo.ID))
+	}
+
+	var draft *agent.AgentVersion
+	for _, v := range versions {
+		if !v.IsDraft() || v.Author != userID {
+			continue
+		}
+		if draft == nil || v.CreatedAt.After(draft.CreatedAt) {
+			draft = v
+		}
+	}
+	if draft == nil {
+		return nil, goerr.Wrap(slack.ErrDraftNotFound, "no draft version of the user",
+			goerr.TV(apperr.AgentIDKey, agentInfo.AgentID),
+			goerr.V("user_id", userID))
+	}
+	return draft, nil
+}
+
+// authorizeDraft verifies that the user who sent the message is the author if the agent version
+// is a draft
+func (uc *Slack) authorizeDraft(ctx context.Context, slackMsg slack.Message, agent *agentContext) error {
+	if agent.draftAuthor == "" {
+		return nil
+	}
+
+	requester, err := uc.requesterUserID(ctx, slackMsg)
+	if err != nil {
+		return err
+	}
+	if requester != agent.draftAuthor {
+		return goerr.Wrap(slack.ErrDraftNotAllowed, "user is not the author of the draft version",
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+			goerr.V("version", agent.version),
+			goerr.V("user_id", requester),
+			goerr.T(apperr.ErrTagForbidden))
+	}
+	return nil
+}
+
+// requesterUserID returns the ID of the Tamamo user who sent the message. Users who have not
+// logged in to the web UI are not registered, and can not use drafts.
+func (uc *Slack) requesterUserID(ctx context.Context, slackMsg slack.Message) (types.UserID, error) {
+	if uc.userRepo != nil {
+		if u := uc.lookupRequester(ctx, slackMsg); u != nil {
+			return u.ID, nil
+		}
+	}
+	return "", goerr.Wrap(slack.ErrDraftNotAllowed, "slack user is not registered",
+		goerr.V("slack_user_id", slackMsg.UserID),
+		goerr.T(apperr.ErrTagForbidden))
+}
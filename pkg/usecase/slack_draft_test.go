@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/user"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestHandleSlackAppMentionWithDraft(t *testing.T) {
+	ctx := context.Background()
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	userRepo := memory.NewUserRepository()
+	author := user.NewUser("U67890USER", "bob", "Bob", "bob@example.com", "T12345")
+	gt.NoError(t, userRepo.Create(ctx, author))
+	other := user.NewUser("U99999USER", "alice", "Alice", "alice@example.com", "T12345")
+	gt.NoError(t, userRepo.Create(ctx, other))
+
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.1.0",
+		SystemPrompt: "You are a terse SRE helper.",
+		Status:       agent.VersionStatusDraft,
+		Author:       author.ID,
+	}))
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+					return &gollem.Response{Texts: []string{"Run df -h."}}, nil
+				},
+			}, nil
+		},
+	}
+
+	mockSlackClient := &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithUserRepository(userRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(mockLLMClient),
+	)
+
+	mention := func(userID, text, ts, threadTS string) slack.Message {
+		return *slack.NewMessage(ctx, &slackevents.EventsAPIEvent{
+			TeamID: "T12345",
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Data: &slackevents.AppMentionEvent{
+					User:            userID,
+					Text:            text,
+					TimeStamp:       ts,
+					Channel:         "C11111",
+					ThreadTimeStamp: threadTS,
+				},
+			},
+		})
+	}
+
+	t.Run("author uses the draft", func(t *testing.T) {
+		gt.NoError(t, uc.HandleSlackAppMention(ctx, mention("U67890USER", "<@U12345BOT> sre-helper@draft disk of web-1 is full", "1234567890.100000", "")))
+
+		thread, err := repo.GetThreadByTS(ctx, "C11111", "1234567890.100000")
+		gt.NoError(t, err)
+		gt.Equal(t, thread.AgentVersion, "1.1.0")
+	})
+
+	t.Run("other user can not use the draft", func(t *testing.T) {
+		gt.NoError(t, uc.HandleSlackAppMention(ctx, mention("U99999USER", "<@U12345BOT> sre-helper@draft disk of web-2 is full", "1234567890.200000", "")))
+
+		calls := mockSlackClient.PostMessageCalls()
+		gt.True(t, len(calls) > 0)
+		gt.S(t, calls[len(calls)-1].Text).Contains("no draft version")
+	})
+
+	t.Run("other user can not join the thread of the draft", func(t *testing.T) {
+		gt.NoError(t, uc.HandleSlackAppMention(ctx, mention("U99999USER", "<@U12345BOT> what about web-3?", "1234567890.300000", "1234567890.100000")))
+
+		calls := mockSlackClient.PostMessageCalls()
+		gt.True(t, len(calls) > 0)
+		gt.S(t, calls[len(calls)-1].Text).Contains("usable only by their authors")
+	})
+
+	t.Run("mention without the suffix uses the latest version", func(t *testing.T) {
+		gt.NoError(t, uc.HandleSlackAppMention(ctx, mention("U99999USER", "<@U12345BOT> sre-helper disk of web-4 is full", "1234567890.400000", "")))
+
+		thread, err := repo.GetThreadByTS(ctx, "C11111", "1234567890.400000")
+		gt.NoError(t, err)
+		gt.Equal(t, thread.AgentVersion, "1.0.0")
+	})
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// defaultContextWindow is the context window in tokens when it is unknown for the model
+	defaultContextWindow = 128 * 1000
+
+	// historyCompactionPercent is the percentage of the context window used by the history
+	// to start compaction. The rest is left for the system prompt, inputs and the response.
+	historyCompactionPercent = 60
+
+	// historyKeepTurns is the number of recent turns kept verbatim by compaction
+	historyKeepTurns = 4
+)
+
+const historySummaryPrompt = `Summarize the conversation so far so that you can continue it without the original messages.
+Keep facts, decisions, open questions, and identifiers such as names, URLs, and numbers.
+Write only the summary in the language of the conversation.`
+
+// countHistoryTokens counts the number of tokens of the history with the LLM client. The number
+// is estimated from the size of the history if the client fails to count it.
+func countHistoryTokens(ctx context.Context, llmClient gollem.LLMClient, history *gollem.History) int {
+	if history.ToCount() == 0 {
+		return 0
+	}
+
+	tokens, err := llmClient.CountTokens(ctx, history)
+	if err != nil {
+		ctxlog.From(ctx).Debug("failed to count tokens of history, estimating from its size",
+			"error", err,
+		)
+		return estimateHistoryTokens(history)
+	}
+	return tokens
+}
+
+// estimateHistoryTokens estimates the number of tokens of the history. A token is about 4 bytes of text.
+func estimateHistoryTokens(history *gollem.History) int {
+	raw, err := json.Marshal(history)
+	if err != nil {
+		return 0
+	}
+	return len(raw) / 4
+}
+
+// contextWindow returns the context window of the model of the agent in tokens
+func (uc *Slack) contextWindow(agent *agentContext) int {
+	if uc.llmFactory != nil {
+		if window := uc.llmFactory.GetConfig().ContextWindow(agent.llmProvider, agent.llmModel); window > 0 {
+			return window
+		}
+	}
+	if uc.contextWindowSize > 0 {
+		return uc.contextWindowSize
+	}
+	return defaultContextWindow
+}
+
+// compactHistory summarizes older turns of the history with LLM when the history is close to the
+// context window, keeping recent turns verbatim. The compacted history is saved as a new history
+// record with the summary, and the record of the original history is kept. It returns the
+// compacted history and its record, or the original ones if compaction is not needed or failed.
+func (uc *Slack) compactHistory(ctx context.Context, llmClient gollem.LLMClient, agent *agentContext, threadID types.ThreadID, snapshot *slack.History, history *gollem.History) (*gollem.History, *slack.History) {
+	logger := ctxlog.From(ctx)
+
+	if history == nil || snapshot == nil {
+		return history, snapshot
+	}
+
+	limit := uc.contextWindow(agent) * historyCompactionPercent / 100
+	tokens := countHistoryTokens(ctx, llmClient, history)
+	if tokens <= limit {
+		return history, snapshot
+	}
+
+	older, recent, ok := splitHistory(ctx, llmClient, history, limit/2)
+	if !ok {
+		logger.Warn("history exceeds the context window but has no older turns to compact",
+			"thread_id", threadID,
+			"history_id", snapshot.ID,
+			"tokens", tokens,
+			"limit", limit,
+		)
+		return history, snapshot
+	}
+
+	summary, err := summarizeHistory(ctx, llmClient, agent, older, snapshot.Summary)
+	if err != nil {
+		logger.Warn("failed to summarize history, continuing with the original history",
+			"error", err,
+			"thread_id", threadID,
+			"history_id", snapshot.ID,
+		)
+		return history, snapshot
+	}
+
+	record := slack.NewHistoryWithAgent(ctx, threadID, &agent.uuid, agent.version)
+	record.Summary = summary
+	record.CompactedFrom = snapshot.ID
+	if err := uc.storageRepo.SaveHistoryJSON(ctx, threadID, record.ID, recent); err != nil {
+		logger.Warn("failed to save compacted history to storage",
+			"error", err,
+			"thread_id", threadID,
+			"history_id", record.ID,
+		)
+	} else if err := uc.repository.PutHistory(ctx, record); err != nil {
+		logger.Warn("failed to save compacted history record",
+			"error", err,
+			"thread_id", threadID,
+			"history_id", record.ID,
+		)
+	}
+
+	logger.Info("compacted conversation history",
+		"thread_id", threadID,
+		"agent_uuid", agent.uuid,
+		"history_id", record.ID,
+		"compacted_from", snapshot.ID,
+		"tokens", tokens,
+		"message_count", history.ToCount(),
+		"compacted_message_count", recent.ToCount(),
+	)
+
+	// The compacted history is used for the turn even if it failed to be saved, because the
+	// history after the turn is saved with the summary anyway.
+	return recent, record
+}
+
+// summarizeHistory asks LLM to summarize the history including the previous summary
+func summarizeHistory(ctx context.Context, llmClient gollem.LLMClient, agent *agentContext, history *gollem.History, previous string) (string, error) {
+	session, err := llmClient.NewSession(ctx,
+		gollem.WithSessionSystemPrompt(withHistorySummary(agent.systemPrompt, previous)),
+		gollem.WithSessionHistory(history),
+	)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create LLM session for summary")
+	}
+
+	resp, err := session.GenerateContent(ctx, gollem.Text(historySummaryPrompt))
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to generate summary of history")
+	}
+	if resp == nil || len(resp.Texts) == 0 || strings.TrimSpace(strings.Join(resp.Texts, "")) == "" {
+		return "", goerr.New("summary of history is empty")
+	}
+
+	return strings.TrimSpace(strings.Join(resp.Texts, "\n")), nil
+}
+
+// withHistorySummary appends the summary of earlier conversation to the system prompt
+func withHistorySummary(systemPrompt, summary string) string {
+	if summary == "" {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\n## Summary of earlier conversation in this thread\n\n" + summary
+}
+
+// historySummaryOf returns the summary of the history record, or empty string if record is nil
+func historySummaryOf(record *slack.History) string {
+	if record == nil {
+		return ""
+	}
+	return record.Summary
+}
+
+// splitHistory splits the history into older turns and recent turns. Up to historyKeepTurns
+// recent turns are kept, but fewer turns are kept if they exceed maxRecentTokens. It returns
+// false if the history has no older turns to split.
+func splitHistory(ctx context.Context, llmClient gollem.LLMClient, history *gollem.History, maxRecentTokens int) (*gollem.History, *gollem.History, bool) {
+	// Turns are split at user messages so that a tool call and its result are not separated
+	var cuts []int
+	for _, i := range turnStarts(history) {
+		if i > 0 {
+			cuts = append(cuts, i)
+		}
+	}
+	if len(cuts) == 0 {
+		return nil, nil, false
+	}
+
+	split := func(at int) (*gollem.History, *gollem.History) {
+		older, recent := *history, *history
+		older.OpenAI, recent.OpenAI = splitMessages(history.OpenAI, at)
+		older.Claude, recent.Claude = splitMessages(history.Claude, at)
+		older.Gemini, recent.Gemini = splitMessages(history.Gemini, at)
+		return &older, &recent
+	}
+
+	i := max(0, len(cuts)-historyKeepTurns)
+	older, recent := split(cuts[i])
+	for i < len(cuts)-1 && countHistoryTokens(ctx, llmClient, recent) > maxRecentTokens {
+		i++
+		older, recent = split(cuts[i])
+	}
+	return older, recent, true
+}
+
+// turnStarts returns the indexes of user messages starting a turn in the messages of the LLM
+// provider of the history. Some providers send tool results with the user role, and they are
+// not turn starts.
+func turnStarts(history *gollem.History) []int {
+	var starts []int
+	switch {
+	case len(history.OpenAI) > 0:
+		// Tool results have the tool role
+		for i, msg := range history.OpenAI {
+			if msg.Role == "user" {
+				starts = append(starts, i)
+			}
+		}
+
+	case len(history.Claude) > 0:
+		for i, msg := range history.Claude {
+			if msg.Role != "user" {
+				continue
+			}
+			toolResult := false
+			for _, content := range msg.Content {
+				toolResult = toolResult || content.Type == "tool_result"
+			}
+			if !toolResult {
+				starts = append(starts, i)
+			}
+		}
+
+	case len(history.Gemini) > 0:
+		for i, msg := range history.Gemini {
+			if msg.Role != "user" {
+				continue
+			}
+			functionResponse := false
+			for _, part := range msg.Parts {
+				functionResponse = functionResponse || part.Type == "function_response"
+			}
+			if !functionResponse {
+				starts = append(starts, i)
+			}
+		}
+	}
+	return starts
+}
+
+func splitMessages[T any](messages []T, at int) ([]T, []T) {
+	if len(messages) == 0 {
+		return messages, messages
+	}
+	return messages[:at:at], messages[at:]
+}
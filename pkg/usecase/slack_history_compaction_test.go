@@ -0,0 +1,202 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/sashabaranov/go-openai"
+)
+
+// countTokensBySize makes LLMClientMock fail to count tokens, so that the number of tokens is
+// estimated from the size of the history
+func countTokensBySize(ctx context.Context, history *gollem.History) (int, error) {
+	return 0, errors.New("counting tokens is not supported")
+}
+
+func TestHandleSlackAppMentionHistoryCompaction(t *testing.T) {
+	ctx := context.Background()
+	threadTS := "1234567890.100000"
+
+	// newHistory creates a history of turns. Content of the first two turns is large.
+	newHistory := func(turns int) *gollem.History {
+		history := &gollem.History{LLType: "OpenAI", Version: 1}
+		for i := range turns {
+			content := fmt.Sprintf("question %d", i)
+			if i < 2 {
+				content += strings.Repeat(" details", 2000)
+			}
+			history.OpenAI = append(history.OpenAI,
+				openai.ChatCompletionMessage{Role: "user", Content: content},
+				openai.ChatCompletionMessage{Role: "assistant", Content: fmt.Sprintf("answer %d", i)},
+			)
+		}
+		return history
+	}
+
+	type fixture struct {
+		repo       *memory.Client
+		storage    *storage.Client
+		snapshot   *slack.History
+		summarized [][]gollem.Input
+		uc         *usecase.Slack
+	}
+
+	setup := func(t *testing.T, history *gollem.History, countTokens func(ctx context.Context, history *gollem.History) (int, error)) *fixture {
+		f := &fixture{
+			repo:    memory.New(),
+			storage: storage.New(newMockStorageAdapter()),
+		}
+
+		thread, err := f.repo.GetOrPutThread(ctx, "T12345", "C11111", threadTS)
+		gt.NoError(t, err)
+		f.snapshot = slack.NewHistory(ctx, thread.ID)
+		f.snapshot.CreatedAt = time.Now().Add(-time.Hour)
+		f.snapshot.Summary = "The user asked about the outage."
+		gt.NoError(t, f.storage.SaveHistoryJSON(ctx, thread.ID, f.snapshot.ID, history))
+		gt.NoError(t, f.repo.PutHistory(ctx, f.snapshot))
+
+		slackClient := &mock.SlackClientMock{
+			PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+				return nil
+			},
+			PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+				return "1234567890.900000", nil
+			},
+			IsBotUserFunc: func(uid string) bool {
+				return uid == "U12345BOT"
+			},
+		}
+
+		f.uc = usecase.New(
+			usecase.WithSlackClient(slackClient),
+			usecase.WithRepository(f.repo),
+			usecase.WithStorageRepository(f.storage),
+			usecase.WithLLMClient(&llm_mock.LLMClientMock{
+				CountTokensFunc: countTokens,
+				NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+					return &MockSession{
+						generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+							if text, ok := input[0].(gollem.Text); ok && strings.HasPrefix(string(text), "Summarize the conversation") {
+								f.summarized = append(f.summarized, input)
+								return &gollem.Response{Texts: []string{"The user is investigating a login outage."}}, nil
+							}
+							return &gollem.Response{Texts: []string{"Let me check."}}, nil
+						},
+					}, nil
+				},
+			}),
+			usecase.WithLLMContextWindow(4000),
+		)
+		return f
+	}
+
+	t.Run("older turns are summarized when history is close to the context window", func(t *testing.T) {
+		f := setup(t, newHistory(6), countTokensBySize)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> is it fixed?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+		gt.A(t, f.summarized).Length(1)
+
+		histories, err := f.repo.ListHistories(ctx, f.snapshot.ThreadID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(3)
+
+		var compacted, latest *slack.History
+		for _, h := range histories {
+			if h.CompactedFrom != "" {
+				compacted = h
+			} else if h.ID != f.snapshot.ID {
+				latest = h
+			}
+		}
+		gt.NotNil(t, compacted)
+		gt.NotNil(t, latest)
+		gt.Equal(t, compacted.CompactedFrom, f.snapshot.ID)
+		gt.Equal(t, compacted.Summary, "The user is investigating a login outage.")
+		gt.Equal(t, latest.Summary, "The user is investigating a login outage.")
+
+		// Recent 4 turns are kept verbatim
+		kept, err := f.storage.LoadHistoryJSON(ctx, compacted.ThreadID, compacted.ID)
+		gt.NoError(t, err)
+		gt.A(t, kept.OpenAI).Length(8)
+		gt.Equal(t, kept.OpenAI[0].Content, "question 2")
+
+		// Original history stays recoverable
+		original, err := f.storage.LoadHistoryJSON(ctx, f.snapshot.ThreadID, f.snapshot.ID)
+		gt.NoError(t, err)
+		gt.A(t, original.OpenAI).Length(12)
+	})
+
+	t.Run("small history is not compacted and summary is carried over", func(t *testing.T) {
+		history := newHistory(6)
+		history.OpenAI = history.OpenAI[4:]
+		f := setup(t, history, countTokensBySize)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> is it fixed?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+		gt.A(t, f.summarized).Length(0)
+
+		histories, err := f.repo.ListHistories(ctx, f.snapshot.ThreadID)
+		gt.NoError(t, err)
+		gt.A(t, histories).Length(2)
+		gt.Equal(t, histories[1].CompactedFrom, "")
+		gt.Equal(t, histories[1].Summary, "The user asked about the outage.")
+	})
+
+	t.Run("tokens counted by LLM client are used", func(t *testing.T) {
+		// The history is small in size, but the LLM client counts many tokens for it
+		history := newHistory(6)
+		history.OpenAI = history.OpenAI[4:]
+		f := setup(t, history, func(ctx context.Context, history *gollem.History) (int, error) {
+			return len(history.OpenAI) * 500, nil
+		})
+
+		msg := newThreadMention(ctx, "<@U12345BOT> is it fixed?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+		gt.A(t, f.summarized).Length(1)
+	})
+
+	t.Run("tool results are not turn starts", func(t *testing.T) {
+		history := &gollem.History{LLType: "OpenAI", Version: 1}
+		for i := range 6 {
+			history.OpenAI = append(history.OpenAI,
+				openai.ChatCompletionMessage{Role: "user", Content: fmt.Sprintf("question %d", i)},
+				openai.ChatCompletionMessage{Role: "assistant", ToolCalls: []openai.ToolCall{{ID: fmt.Sprintf("call-%d", i)}}},
+				openai.ChatCompletionMessage{Role: "tool", ToolCallID: fmt.Sprintf("call-%d", i), Content: "user: tool_result"},
+				openai.ChatCompletionMessage{Role: "assistant", Content: fmt.Sprintf("answer %d", i)},
+			)
+		}
+		f := setup(t, history, func(ctx context.Context, history *gollem.History) (int, error) {
+			return len(history.OpenAI) * 150, nil
+		})
+
+		msg := newThreadMention(ctx, "<@U12345BOT> is it fixed?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+		gt.A(t, f.summarized).Length(1)
+
+		histories, err := f.repo.ListHistories(ctx, f.snapshot.ThreadID)
+		gt.NoError(t, err)
+		for _, h := range histories {
+			if h.CompactedFrom == "" {
+				continue
+			}
+			kept, err := f.storage.LoadHistoryJSON(ctx, h.ThreadID, h.ID)
+			gt.NoError(t, err)
+			gt.True(t, len(kept.OpenAI)%4 == 0)
+			gt.Equal(t, kept.OpenAI[0].Role, "user")
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	slackapi "github.com/slack-go/slack"
+)
+
+// BlockActionHandler handles an action of Block Kit elements such as buttons and menus
+type BlockActionHandler func(ctx context.Context, callback *slackapi.InteractionCallback, action *slackapi.BlockAction) error
+
+// ViewSubmissionHandler handles a modal submission. The returned response is sent back to Slack
+// to close, update or show errors in the modal. nil response closes the modal.
+type ViewSubmissionHandler func(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error)
+
+// ShortcutHandler handles a global or message shortcut
+type ShortcutHandler func(ctx context.Context, callback *slackapi.InteractionCallback) error
+
+// SlackInteraction dispatches Slack interaction payloads to registered handlers
+type SlackInteraction struct {
+	blockActionHandlers    map[string]BlockActionHandler    // action_id -> handler
+	viewSubmissionHandlers map[string]ViewSubmissionHandler // view callback_id -> handler
+	shortcutHandlers       map[string]ShortcutHandler       // shortcut callback_id -> handler
+}
+
+// Ensure SlackInteraction implements SlackInteractionUseCases interface
+var _ interfaces.SlackInteractionUseCases = (*SlackInteraction)(nil)
+
+// SlackInteractionOption is a functional option for SlackInteraction
+type SlackInteractionOption func(*SlackInteraction)
+
+// WithBlockActionHandler registers a handler for Block Kit actions with the action ID
+func WithBlockActionHandler(actionID string, handler BlockActionHandler) SlackInteractionOption {
+	return func(uc *SlackInteraction) {
+		uc.blockActionHandlers[actionID] = handler
+	}
+}
+
+// WithViewSubmissionHandler registers a handler for modal submissions with the view callback ID
+func WithViewSubmissionHandler(callbackID string, handler ViewSubmissionHandler) SlackInteractionOption {
+	return func(uc *SlackInteraction) {
+		uc.viewSubmissionHandlers[callbackID] = handler
+	}
+}
+
+// WithShortcutHandler registers a handler for shortcuts with the callback ID
+func WithShortcutHandler(callbackID string, handler ShortcutHandler) SlackInteractionOption {
+	return func(uc *SlackInteraction) {
+		uc.shortcutHandlers[callbackID] = handler
+	}
+}
+
+// NewSlackInteraction creates a new SlackInteraction use case
+func NewSlackInteraction(opts ...SlackInteractionOption) *SlackInteraction {
+	uc := &SlackInteraction{
+		blockActionHandlers:    make(map[string]BlockActionHandler),
+		viewSubmissionHandlers: make(map[string]ViewSubmissionHandler),
+		shortcutHandlers:       make(map[string]ShortcutHandler),
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// HandleBlockActions runs handlers for each action in the block_actions payload.
+// All actions are processed even if one of them fails, and the first error is returned.
+func (uc *SlackInteraction) HandleBlockActions(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	logger := ctxlog.From(ctx)
+
+	var firstErr error
+	for _, action := range callback.ActionCallback.BlockActions {
+		handler, ok := uc.blockActionHandlers[action.ActionID]
+		if !ok {
+			logger.Warn("no handler for slack block action",
+				"action_id", action.ActionID,
+				"block_id", action.BlockID,
+				"user", callback.User.ID,
+			)
+			continue
+		}
+
+		logger.Debug("handling slack block action",
+			"action_id", action.ActionID,
+			"block_id", action.BlockID,
+			"user", callback.User.ID,
+			"channel", callback.Channel.ID,
+		)
+
+		if err := handler(ctx, callback, action); err != nil && firstErr == nil {
+			firstErr = goerr.Wrap(err, "failed to handle slack block action",
+				goerr.V("action_id", action.ActionID),
+				goerr.V("user", callback.User.ID))
+		}
+	}
+
+	return firstErr
+}
+
+// HandleViewSubmission runs the handler for the submitted modal and returns its response
+func (uc *SlackInteraction) HandleViewSubmission(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error) {
+	callbackID := callback.View.CallbackID
+	handler, ok := uc.viewSubmissionHandlers[callbackID]
+	if !ok {
+		ctxlog.From(ctx).Warn("no handler for slack view submission",
+			"callback_id", callbackID,
+			"user", callback.User.ID,
+		)
+		return nil, nil
+	}
+
+	resp, err := handler(ctx, callback)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to handle slack view submission",
+			goerr.V("callback_id", callbackID),
+			goerr.V("user", callback.User.ID))
+	}
+	return resp, nil
+}
+
+// HandleShortcut runs the handler for the global or message shortcut
+func (uc *SlackInteraction) HandleShortcut(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	handler, ok := uc.shortcutHandlers[callback.CallbackID]
+	if !ok {
+		ctxlog.From(ctx).Warn("no handler for slack shortcut",
+			"callback_id", callback.CallbackID,
+			"type", callback.Type,
+			"user", callback.User.ID,
+		)
+		return nil
+	}
+
+	if err := handler(ctx, callback); err != nil {
+		return goerr.Wrap(err, "failed to handle slack shortcut",
+			goerr.V("callback_id", callback.CallbackID),
+			goerr.V("user", callback.User.ID))
+	}
+	return nil
+}
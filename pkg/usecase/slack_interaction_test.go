@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	slackapi "github.com/slack-go/slack"
+)
+
+func TestSlackInteractionBlockActions(t *testing.T) {
+	ctx := context.Background()
+
+	var called []string
+	uc := usecase.NewSlackInteraction(
+		usecase.WithBlockActionHandler("fail", func(ctx context.Context, callback *slackapi.InteractionCallback, action *slackapi.BlockAction) error {
+			called = append(called, action.ActionID)
+			return errors.New("handler failed")
+		}),
+		usecase.WithBlockActionHandler("ok", func(ctx context.Context, callback *slackapi.InteractionCallback, action *slackapi.BlockAction) error {
+			called = append(called, action.ActionID)
+			return nil
+		}),
+	)
+
+	callback := &slackapi.InteractionCallback{
+		Type: slackapi.InteractionTypeBlockActions,
+		ActionCallback: slackapi.ActionCallbacks{
+			BlockActions: []*slackapi.BlockAction{
+				{ActionID: "fail"},
+				{ActionID: "unknown"},
+				{ActionID: "ok"},
+			},
+		},
+	}
+
+	// Remaining actions are processed even if a handler fails
+	err := uc.HandleBlockActions(ctx, callback)
+	gt.Error(t, err)
+	gt.Equal(t, called, []string{"fail", "ok"})
+}
+
+func TestSlackInteractionViewSubmission(t *testing.T) {
+	ctx := context.Background()
+
+	uc := usecase.NewSlackInteraction(
+		usecase.WithViewSubmissionHandler("settings", func(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error) {
+			return slackapi.NewClearViewSubmissionResponse(), nil
+		}),
+		usecase.WithViewSubmissionHandler("broken", func(ctx context.Context, callback *slackapi.InteractionCallback) (*slackapi.ViewSubmissionResponse, error) {
+			return nil, errors.New("handler failed")
+		}),
+	)
+
+	t.Run("registered view", func(t *testing.T) {
+		callback := &slackapi.InteractionCallback{Type: slackapi.InteractionTypeViewSubmission}
+		callback.View.CallbackID = "settings"
+
+		resp, err := uc.HandleViewSubmission(ctx, callback)
+		gt.NoError(t, err)
+		gt.Equal(t, resp.ResponseAction, slackapi.RAClear)
+	})
+
+	t.Run("unknown view", func(t *testing.T) {
+		callback := &slackapi.InteractionCallback{Type: slackapi.InteractionTypeViewSubmission}
+		callback.View.CallbackID = "unknown"
+
+		resp, err := uc.HandleViewSubmission(ctx, callback)
+		gt.NoError(t, err)
+		gt.V(t, resp).Nil()
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		callback := &slackapi.InteractionCallback{Type: slackapi.InteractionTypeViewSubmission}
+		callback.View.CallbackID = "broken"
+
+		_, err := uc.HandleViewSubmission(ctx, callback)
+		gt.Error(t, err)
+	})
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/knowledge"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// knowledgeToolName is the name of the tool to search the knowledge base of the agent
+	knowledgeToolName = "search_knowledge"
+
+	// maxKnowledgeToolDocumentNames caps document names listed in the tool description
+	maxKnowledgeToolDocumentNames = 20
+)
+
+// buildKnowledgeTool creates the tool to search the knowledge base if the agent has documents.
+// Returns nil otherwise.
+func (uc *Slack) buildKnowledgeTool(ctx context.Context, agent *agentContext) gollem.Tool {
+	if uc.knowledge == nil {
+		return nil
+	}
+
+	docs, err := uc.knowledge.ListKnowledgeDocuments(ctx, agent.uuid)
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to list knowledge documents, continue without knowledge tool",
+			"error", err,
+			"agent_uuid", agent.uuid,
+		)
+		return nil
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return &knowledgeTool{
+		knowledge: uc.knowledge,
+		agentUUID: agent.uuid,
+		documents: docs,
+	}
+}
+
+// knowledgeTool is a gollem tool that searches documents uploaded to the knowledge base of the agent
+type knowledgeTool struct {
+	knowledge interfaces.KnowledgeUseCases
+	agentUUID types.UUID
+	documents []*knowledge.Document
+}
+
+// Ensure knowledgeTool implements gollem.Tool interface
+var _ gollem.Tool = (*knowledgeTool)(nil)
+
+// Spec returns the tool specification
+func (t *knowledgeTool) Spec() gollem.ToolSpec {
+	names := make([]string, 0, min(len(t.documents), maxKnowledgeToolDocumentNames))
+	for _, doc := range t.documents[:min(len(t.documents), maxKnowledgeToolDocumentNames)] {
+		names = append(names, doc.Name)
+	}
+	documents := strings.Join(names, ", ")
+	if len(t.documents) > maxKnowledgeToolDocumentNames {
+		documents += fmt.Sprintf(" and %d more", len(t.documents)-maxKnowledgeToolDocumentNames)
+	}
+
+	return gollem.ToolSpec{
+		Name: knowledgeToolName,
+		Description: "Search the knowledge base of documents provided for you (" + documents + "). " +
+			"Returns relevant passages with their document names. " +
+			"When you answer with the passages, cite the document names like [runbook.md].",
+		Parameters: map[string]*gollem.Parameter{
+			"query": {
+				Type:        gollem.TypeString,
+				Description: "What to look for, in natural language",
+			},
+			"limit": {
+				Type:        gollem.TypeInteger,
+				Description: fmt.Sprintf("Maximum number of passages to return (default %d, max %d)", defaultKnowledgeSearchLimit, maxKnowledgeSearchLimit),
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+// Run searches the knowledge base with the query
+func (t *knowledgeTool) Run(ctx context.Context, args map[string]any) (map[string]any, error) {
+	query, _ := args["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, goerr.New("query is required")
+	}
+
+	limit := defaultKnowledgeSearchLimit
+	if v, ok := args["limit"].(float64); ok {
+		limit = int(v)
+	}
+
+	results, err := t.knowledge.SearchKnowledge(ctx, t.agentUUID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	passages := make([]map[string]any, 0, len(results))
+	for _, result := range results {
+		passages = append(passages, map[string]any{
+			"document": result.Chunk.DocumentName,
+			"chunk":    result.Chunk.Index,
+			"score":    result.Score,
+			"text":     result.Chunk.Text,
+		})
+	}
+
+	return map[string]any{
+		"passages": passages,
+		"count":    len(passages),
+	}, nil
+}
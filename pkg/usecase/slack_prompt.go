@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	agentmodel "github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+)
+
+// renderSystemPrompt renders the system prompt template of the agent with the context of the
+// message. Users and channels are looked up only when the template refers to them. The system
+// prompt is returned as is if the version does not use templates or the template fails to be
+// rendered.
+func (uc *Slack) renderSystemPrompt(ctx context.Context, agent *agentContext, slackMsg slack.Message) string {
+	if !agent.promptTemplate {
+		return agent.systemPrompt
+	}
+	logger := ctxlog.From(ctx)
+
+	pc := agentmodel.NewPromptContext(time.Now())
+	pc.Agent = agentmodel.PromptAgent{ID: agent.agentID, Name: agent.name, Version: agent.version}
+	if agent.promptVars != nil {
+		pc.Vars = agent.promptVars
+	}
+
+	pc.SetUserLoader(func() agentmodel.PromptUser {
+		user := agentmodel.PromptUser{ID: slackMsg.UserID}
+		if slackMsg.UserID == "" {
+			return user
+		}
+		profile, err := uc.slackClient.GetUserProfile(ctx, slackMsg.UserID)
+		if err != nil {
+			logger.Warn("failed to get user profile for system prompt",
+				"error", err,
+				"user", slackMsg.UserID,
+			)
+			return user
+		}
+		user.Name = profile.DisplayName
+		if user.Name == "" {
+			user.Name = profile.Name
+		}
+		user.Email = profile.Email
+		user.TimeZone = profile.TimeZone
+		return user
+	})
+
+	pc.SetChannelLoader(func() agentmodel.PromptChannel {
+		channel := agentmodel.PromptChannel{ID: slackMsg.Channel}
+		if slackMsg.Channel == "" || uc.channelCache == nil {
+			return channel
+		}
+		info, err := uc.channelCache.GetChannelInfo(ctx, slackMsg.Channel)
+		if err != nil {
+			logger.Warn("failed to get channel info for system prompt",
+				"error", err,
+				"channel", slackMsg.Channel,
+			)
+			return channel
+		}
+		channel.Name = info.Name
+		channel.Type = string(info.Type)
+		return channel
+	})
+
+	prompt, err := agentmodel.RenderSystemPrompt(agent.systemPrompt, pc)
+	if err != nil {
+		logger.Warn("failed to render system prompt, using the template as is",
+			"error", err,
+			"agent_uuid", agent.uuid,
+			"agent_version", agent.version,
+		)
+		return agent.systemPrompt
+	}
+	return prompt
+}
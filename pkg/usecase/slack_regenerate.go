@@ -0,0 +1,321 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	slackapi "github.com/slack-go/slack"
+)
+
+const (
+	// regenerateKeyword is the keyword of a mention in a thread to regenerate the latest response
+	regenerateKeyword = "regenerate"
+
+	// RegenerateShortcutCallbackID is the callback ID of the message shortcut to regenerate a response
+	RegenerateShortcutCallbackID = "regenerate_response"
+)
+
+// regenerateRequest is a request to regenerate a turn of the agent in a thread
+type regenerateRequest struct {
+	teamID     string
+	channelID  string
+	threadTS   string
+	responseTS string // Response to regenerate. The latest turn is regenerated if empty.
+	userID     string // User who requested the regeneration
+	model      string // Model to regenerate with. The model of the agent is used if empty.
+}
+
+// parseRegenerateCommand parses "regenerate [model]" in a mention and returns the model. It
+// returns false if the message is not the command.
+func parseRegenerateCommand(message string) (string, bool) {
+	fields := strings.Fields(message)
+	if len(fields) == 0 || len(fields) > 2 || !strings.EqualFold(fields[0], regenerateKeyword) {
+		return "", false
+	}
+	if len(fields) == 2 {
+		return fields[1], true
+	}
+	return "", true
+}
+
+// HandleRegenerateShortcut handles the message shortcut to regenerate a response of an agent in a
+// thread. The response is generated again from the history snapshot taken before the turn. Only
+// messages recorded as responses of turns can be regenerated, so notices of tamamo are refused.
+func (uc *Slack) HandleRegenerateShortcut(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	msg := callback.Message
+	if msg.ThreadTimestamp == "" {
+		ctxlog.From(ctx).Info("ignored regenerate shortcut for a message that is not in a thread",
+			"channel", callback.Channel.ID,
+			"message_ts", msg.Timestamp,
+			"user", callback.User.ID,
+		)
+		return nil
+	}
+
+	return uc.regenerate(ctx, regenerateRequest{
+		teamID:     callback.Team.ID,
+		channelID:  callback.Channel.ID,
+		threadTS:   msg.ThreadTimestamp,
+		responseTS: msg.Timestamp,
+		userID:     callback.User.ID,
+	})
+}
+
+// regenerate reruns a turn of the agent with the history snapshot taken before the turn and
+// replaces the response. The regenerated turn is saved as the latest history, so the thread
+// continues from it and later turns are rewound.
+func (uc *Slack) regenerate(ctx context.Context, req regenerateRequest) error {
+	if err := uc.regenerateTurn(ctx, req); err != nil {
+		uc.postRegenerateNotice(ctx, req, ":warning: Failed to regenerate the response. Please try again later.")
+		return err
+	}
+	return nil
+}
+
+func (uc *Slack) regenerateTurn(ctx context.Context, req regenerateRequest) error {
+	logger := ctxlog.From(ctx)
+
+	if uc.repository == nil || uc.storageRepo == nil {
+		return goerr.New("repository and storage are required to regenerate a response")
+	}
+
+	thread, err := uc.repository.GetThreadByTS(ctx, req.channelID, req.threadTS)
+	if err != nil {
+		if errors.Is(err, slack.ErrThreadNotFound) {
+			uc.postRegenerateNotice(ctx, req, ":warning: This response cannot be regenerated because the history of the thread is not found.")
+			return nil
+		}
+		return goerr.Wrap(err, "failed to get thread to regenerate",
+			goerr.TV(apperr.ChannelIDKey, req.channelID),
+			goerr.V("thread_ts", req.threadTS))
+	}
+
+	histories, err := uc.repository.ListHistories(ctx, thread.ID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list histories to regenerate", goerr.TV(apperr.ThreadIDKey, thread.ID))
+	}
+
+	turn := findRegenerateTurn(histories, req.responseTS)
+	if turn == nil {
+		if req.responseTS != "" {
+			uc.postRegenerateNotice(ctx, req, ":warning: Only responses of agents can be regenerated.")
+		} else {
+			uc.postRegenerateNotice(ctx, req, ":warning: This response cannot be regenerated because the history of the turn is not found.")
+		}
+		return nil
+	}
+
+	// The turn is regenerated by the agent version that answered it
+	turnThread := *thread
+	turnThread.AgentUUID = turn.AgentUUID
+	turnThread.AgentVersion = turn.AgentVersion
+	agent, err := uc.resolveAgent(ctx, nil, &threadContext{existingThread: &turnThread})
+	if err != nil {
+		return goerr.Wrap(err, "failed to resolve agent to regenerate", goerr.TV(apperr.ThreadIDKey, thread.ID))
+	}
+	if err := uc.authorizeDraft(ctx, slack.Message{UserID: req.userID, TeamID: req.teamID}, agent); err != nil {
+		logger.Info("refused to regenerate response of draft version", "error", err, "thread_id", thread.ID)
+		uc.postRegenerateNotice(ctx, req, ":warning: Responses of a draft version can be regenerated only by its author.")
+		return nil
+	}
+
+	if req.model != "" {
+		if reason := uc.overrideModel(agent, req.model); reason != "" {
+			uc.postRegenerateNotice(ctx, req, ":warning: "+reason)
+			return nil
+		}
+	}
+
+	// Load the history snapshot taken before the turn. The first turn starts without history.
+	var history *gollem.History
+	var parent *slack.History
+	if turn.ParentID != "" {
+		parent, err = uc.repository.GetHistoryByID(ctx, turn.ParentID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to get history record before the turn",
+				goerr.TV(apperr.ThreadIDKey, thread.ID),
+				goerr.V("history_id", turn.ParentID))
+		}
+
+		stored, err := uc.storageRepo.LoadHistoryJSON(ctx, thread.ID, parent.ID)
+		if err != nil {
+			return goerr.Wrap(err, "failed to load history before the turn",
+				goerr.TV(apperr.ThreadIDKey, thread.ID),
+				goerr.V("history_id", parent.ID))
+		}
+		history = &stored
+	}
+
+	slackMsg := slack.Message{
+		ThreadID:  thread.ID,
+		TeamID:    req.teamID,
+		Channel:   req.channelID,
+		ThreadTS:  req.threadTS,
+		UserID:    req.userID,
+		Timestamp: turn.MessageTS,
+		Text:      turn.Input,
+	}
+
+	llmClient, err := uc.getLLMClient(ctx, agent, slackMsg)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get LLM client")
+	}
+
+	session, tools, closeSession, err := uc.newAgentSession(ctx, llmClient, agent, slackMsg, history, parent)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create LLM session",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+		)
+	}
+	defer closeSession()
+
+	// The turn is replayed with the same input, e.g. the thread messages and attachments. Records
+	// saved before the input was kept have only the message of the user.
+	parts := turn.InputParts
+	if len(parts) == 0 {
+		parts = []string{turn.Input}
+	}
+	resp, err := generateWithTools(ctx, session, tools, textInputs(parts)...)
+	if err != nil {
+		return goerr.Wrap(err, "failed to regenerate content with LLM",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.TV(apperr.AgentUUIDKey, agent.uuid),
+		)
+	}
+
+	// Replace the response of the turn. It is posted as a new message if it is not found.
+	text := responseTextOf(resp)
+	responseTS := turn.ResponseTS
+	if responseTS == "" {
+		responseTS = uc.findResponseTS(ctx, req, turn)
+	}
+	if responseTS != "" {
+		if err := uc.slackClient.UpdateMessage(ctx, req.channelID, responseTS, text); err != nil {
+			return goerr.Wrap(err, "failed to replace response with regenerated one",
+				goerr.TV(apperr.ChannelIDKey, req.channelID),
+				goerr.V("response_ts", responseTS))
+		}
+	} else {
+		responseTS, err = uc.postMessageWithAgentDisplay(ctx, req.channelID, req.threadTS, text, agent)
+		if err != nil {
+			return goerr.Wrap(err, "failed to post regenerated response")
+		}
+	}
+
+	record := slack.NewHistoryWithAgent(ctx, thread.ID, &agent.uuid, agent.version)
+	record.Summary = historySummaryOf(parent)
+	record.ParentID = turn.ParentID
+	record.MessageTS = turn.MessageTS
+	record.Input = turn.Input
+	record.InputParts = turn.InputParts
+	record.ResponseTS = responseTS
+	record.RegeneratedFrom = turn.ID
+	if err := uc.storageRepo.SaveHistoryJSON(ctx, thread.ID, record.ID, session.History()); err != nil {
+		return goerr.Wrap(err, "failed to save regenerated history",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.V("history_id", record.ID))
+	}
+	if err := uc.repository.PutHistory(ctx, record); err != nil {
+		return goerr.Wrap(err, "failed to save regenerated history record",
+			goerr.TV(apperr.ThreadIDKey, thread.ID),
+			goerr.V("history_id", record.ID))
+	}
+
+	logger.Info("regenerated response",
+		"thread_id", thread.ID,
+		"agent_uuid", agent.uuid,
+		"agent_version", agent.version,
+		"llm_provider", agent.llmProvider,
+		"llm_model", agent.llmModel,
+		"history_id", record.ID,
+		"regenerated_from", turn.ID,
+		"response_ts", responseTS,
+		"user", req.userID,
+	)
+
+	return nil
+}
+
+// findRegenerateTurn returns the turn that answered with the response. The latest turn of the
+// thread is returned if responseTS is empty. A regenerated turn has the same mention and response
+// as the original one, so the record created later is chosen. Histories must be sorted by
+// creation time.
+func findRegenerateTurn(histories []*slack.History, responseTS string) *slack.History {
+	var turn *slack.History
+	for _, h := range histories {
+		if !h.IsTurn() {
+			continue
+		}
+		if responseTS != "" {
+			if h.ResponseTS == responseTS {
+				turn = h
+			}
+			continue
+		}
+		if turn == nil || h.MessageTS >= turn.MessageTS {
+			turn = h
+		}
+	}
+	return turn
+}
+
+// findResponseTS returns the timestamp of the first message tamamo posted after the mention of
+// the turn, or empty string if it is not found. It is for records saved before the response was
+// kept.
+func (uc *Slack) findResponseTS(ctx context.Context, req regenerateRequest, turn *slack.History) string {
+	replies, err := uc.slackClient.GetThreadReplies(ctx, req.channelID, req.threadTS, maxBackfillReplies)
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to get thread replies to find the response",
+			"error", err,
+			"channel", req.channelID,
+			"thread_ts", req.threadTS,
+		)
+		return ""
+	}
+
+	for _, msg := range replies {
+		if msg.Timestamp > turn.MessageTS && uc.slackClient.IsBotUser(msg.UserID) {
+			return msg.Timestamp
+		}
+	}
+	return ""
+}
+
+// overrideModel replaces the model of the agent to regenerate with. The model must be of the
+// provider of the agent because the history is in the format of the provider. It returns the
+// reason if the model cannot be used.
+func (uc *Slack) overrideModel(agent *agentContext, model string) string {
+	if uc.llmFactory == nil {
+		return "Choosing a model is not available because LLM providers are not configured."
+	}
+
+	config := uc.llmFactory.GetConfig()
+	provider := agent.llmProvider
+	if provider == "" {
+		provider = config.Defaults.Provider
+	}
+	if !config.ValidateProviderModel(provider, model) {
+		return fmt.Sprintf("Model `%s` is not available for provider `%s`.", model, provider)
+	}
+
+	agent.llmProvider = provider
+	agent.llmModel = model
+	return ""
+}
+
+func (uc *Slack) postRegenerateNotice(ctx context.Context, req regenerateRequest, text string) {
+	if err := uc.slackClient.PostMessage(ctx, req.channelID, req.threadTS, text); err != nil {
+		ctxlog.From(ctx).Warn("failed to post regenerate notice",
+			"error", err,
+			"channel", req.channelID,
+		)
+	}
+}
@@ -0,0 +1,222 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/sashabaranov/go-openai"
+	slackapi "github.com/slack-go/slack"
+)
+
+func TestRegenerateResponse(t *testing.T) {
+	ctx := context.Background()
+	threadTS := "1234567890.100000"
+
+	type fixture struct {
+		repo        *memory.Client
+		storage     *storage.Client
+		slackClient *mock.SlackClientMock
+		first       *slack.History
+		second      *slack.History
+		inputs      [][]gollem.Input
+		uc          *usecase.Slack
+	}
+
+	// Thread has two turns, and each of them was answered in the next reply. The first turn keeps
+	// its whole input and response, and the second one is a record saved before they were kept.
+	setup := func(t *testing.T) *fixture {
+		f := &fixture{
+			repo:    memory.New(),
+			storage: storage.New(newMockStorageAdapter()),
+		}
+
+		thread, err := f.repo.GetOrPutThread(ctx, "T12345", "C11111", threadTS)
+		gt.NoError(t, err)
+
+		now := time.Now()
+		f.first = slack.NewHistory(ctx, thread.ID)
+		f.first.MessageTS = threadTS
+		f.first.Input = "is the API down?"
+		f.first.InputParts = []string{
+			"[Handover]\nalice: the API returns 503",
+			"is the API down?",
+			"[Attached file: error.log, part 1 of 1]\n503 Service Unavailable",
+		}
+		f.first.ResponseTS = "1234567890.200000"
+		f.first.CreatedAt = now.Add(-2 * time.Hour)
+		f.second = slack.NewHistory(ctx, thread.ID)
+		f.second.MessageTS = "1234567890.300000"
+		f.second.Input = "what should I do?"
+		f.second.ParentID = f.first.ID
+		f.second.CreatedAt = now.Add(-time.Hour)
+
+		for _, h := range []*slack.History{f.first, f.second} {
+			gt.NoError(t, f.storage.SaveHistoryJSON(ctx, thread.ID, h.ID, &gollem.History{
+				LLType:  "OpenAI",
+				Version: 1,
+				OpenAI:  []openai.ChatCompletionMessage{{Role: "user", Content: h.Input}, {Role: "assistant", Content: "answer"}},
+			}))
+			gt.NoError(t, f.repo.PutHistory(ctx, h))
+		}
+
+		f.slackClient = &mock.SlackClientMock{
+			PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+				return nil
+			},
+			UpdateMessageFunc: func(ctx context.Context, channelID, timestamp, text string) error {
+				return nil
+			},
+			IsBotUserFunc: func(uid string) bool {
+				return uid == "U12345BOT"
+			},
+			GetThreadRepliesFunc: func(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+				return []*slack.Message{
+					{UserID: "U67890USER", Text: "<@U12345BOT> is the API down?", Timestamp: "1234567890.100000"},
+					{UserID: "U12345BOT", Text: "answer", Timestamp: "1234567890.200000"},
+					{UserID: "U12345BOT", Text: ":warning: Some attached files are not fully read", Timestamp: "1234567890.250000"},
+					{UserID: "U67890USER", Text: "<@U12345BOT> what should I do?", Timestamp: "1234567890.300000"},
+					{UserID: "U12345BOT", Text: "answer", Timestamp: "1234567890.400000"},
+					{UserID: "U67890USER", Text: "<@U12345BOT> regenerate", Timestamp: "1234567890.500000"},
+				}, nil
+			},
+		}
+
+		f.uc = usecase.New(
+			usecase.WithSlackClient(f.slackClient),
+			usecase.WithRepository(f.repo),
+			usecase.WithStorageRepository(f.storage),
+			usecase.WithLLMClient(&llm_mock.LLMClientMock{
+				CountTokensFunc: countTokensBySize,
+				NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+					return &MockSession{
+						generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+							f.inputs = append(f.inputs, input)
+							return &gollem.Response{Texts: []string{"Another answer."}}, nil
+						},
+					}, nil
+				},
+			}),
+		)
+		return f
+	}
+
+	latestHistory := func(t *testing.T, f *fixture) *slack.History {
+		latest, err := f.repo.GetLatestHistory(ctx, f.first.ThreadID)
+		gt.NoError(t, err)
+		return latest
+	}
+
+	t.Run("message shortcut regenerates the turn of the response", func(t *testing.T) {
+		f := setup(t)
+
+		callback := &slackapi.InteractionCallback{
+			Type:       slackapi.InteractionTypeMessageAction,
+			CallbackID: usecase.RegenerateShortcutCallbackID,
+		}
+		callback.Message.User = "U12345BOT"
+		callback.Message.Timestamp = "1234567890.200000"
+		callback.Message.ThreadTimestamp = threadTS
+		callback.Channel.ID = "C11111"
+		callback.User.ID = "U67890USER"
+		gt.NoError(t, f.uc.HandleRegenerateShortcut(ctx, callback))
+
+		// The turn is replayed with the same input
+		gt.A(t, f.inputs).Length(1)
+		gt.Equal(t, f.inputs[0], []gollem.Input{
+			gollem.Text("[Handover]\nalice: the API returns 503"),
+			gollem.Text("is the API down?"),
+			gollem.Text("[Attached file: error.log, part 1 of 1]\n503 Service Unavailable"),
+		})
+
+		gt.A(t, f.slackClient.UpdateMessageCalls()).Length(1)
+		gt.Equal(t, f.slackClient.UpdateMessageCalls()[0].Timestamp, "1234567890.200000")
+		gt.Equal(t, f.slackClient.UpdateMessageCalls()[0].Text, "Another answer.")
+
+		// Thread continues from the regenerated first turn
+		latest := latestHistory(t, f)
+		gt.Equal(t, latest.RegeneratedFrom, f.first.ID)
+		gt.Equal(t, latest.MessageTS, f.first.MessageTS)
+		gt.Equal(t, latest.ParentID, "")
+		gt.Equal(t, latest.InputParts, f.first.InputParts)
+		gt.Equal(t, latest.ResponseTS, "1234567890.200000")
+	})
+
+	t.Run("keyword regenerates the latest turn from the snapshot before it", func(t *testing.T) {
+		f := setup(t)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> regenerate", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.inputs).Length(1)
+		gt.Equal(t, f.inputs[0], []gollem.Input{gollem.Text("what should I do?")})
+
+		gt.A(t, f.slackClient.UpdateMessageCalls()).Length(1)
+		gt.Equal(t, f.slackClient.UpdateMessageCalls()[0].Timestamp, "1234567890.400000")
+
+		latest := latestHistory(t, f)
+		gt.Equal(t, latest.RegeneratedFrom, f.second.ID)
+		gt.Equal(t, latest.ParentID, f.first.ID)
+		gt.Equal(t, latest.ResponseTS, "1234567890.400000")
+	})
+
+	t.Run("model cannot be chosen without LLM providers config", func(t *testing.T) {
+		f := setup(t)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> regenerate gpt-5-nano", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.inputs).Length(0)
+		gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+		gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("Choosing a model is not available")
+		gt.Equal(t, latestHistory(t, f).ID, f.second.ID)
+	})
+
+	t.Run("shortcut on a message that is not a recorded response is refused", func(t *testing.T) {
+		for _, msg := range []struct{ user, ts string }{
+			{user: "U12345BOT", ts: "1234567890.250000"},  // Notice of tamamo
+			{user: "U67890USER", ts: "1234567890.300000"}, // Mention of the user
+		} {
+			f := setup(t)
+
+			callback := &slackapi.InteractionCallback{CallbackID: usecase.RegenerateShortcutCallbackID}
+			callback.Message.User = msg.user
+			callback.Message.Timestamp = msg.ts
+			callback.Message.ThreadTimestamp = threadTS
+			callback.Channel.ID = "C11111"
+			callback.User.ID = "U67890USER"
+			gt.NoError(t, f.uc.HandleRegenerateShortcut(ctx, callback))
+
+			gt.A(t, f.inputs).Length(0)
+			gt.A(t, f.slackClient.UpdateMessageCalls()).Length(0)
+			gt.A(t, f.slackClient.PostMessageCalls()).Length(1)
+			gt.S(t, f.slackClient.PostMessageCalls()[0].Text).Contains("Only responses of agents can be regenerated")
+			gt.Equal(t, latestHistory(t, f).ID, f.second.ID)
+		}
+	})
+
+	t.Run("response of a turn is recorded", func(t *testing.T) {
+		f := setup(t)
+		f.slackClient.PostMessageForUpdateFunc = func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.600000", nil
+		}
+
+		msg := newThreadMention(ctx, "<@U12345BOT> ?and then", threadTS)
+		msg.Timestamp = "1234567890.550000"
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		latest := latestHistory(t, f)
+		gt.Equal(t, latest.MessageTS, "1234567890.550000")
+		gt.Equal(t, latest.ResponseTS, "1234567890.600000")
+		gt.Equal(t, latest.InputParts[len(latest.InputParts)-1], "?and then")
+	})
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	slackapi "github.com/slack-go/slack"
+)
+
+const (
+	// PositiveFeedbackShortcutCallbackID is the callback ID of the message shortcut to rate a response as good
+	PositiveFeedbackShortcutCallbackID = "feedback_good"
+
+	// NegativeFeedbackShortcutCallbackID is the callback ID of the message shortcut to rate a response as bad
+	NegativeFeedbackShortcutCallbackID = "feedback_bad"
+)
+
+// rolloutKeyOf returns the key of the thread of the message to select the version of the agent
+func rolloutKeyOf(slackMsg slack.Message) string {
+	return threadRolloutKey(slackMsg.Channel, slackMsg.GetThreadTS())
+}
+
+// threadRolloutKey returns the key of the thread to select the version of the agent
+func threadRolloutKey(channelID, threadTS string) string {
+	return channelID + "/" + threadTS
+}
+
+// selectAgentVersion returns the version of the agent for a new thread. The candidate version of a
+// rollout is selected for a part of threads, and the latest version otherwise. The latest version
+// is used if the candidate version is not available.
+func (uc *Slack) selectAgentVersion(ctx context.Context, agentInfo *agent.Agent, rolloutKey string) (*agent.AgentVersion, error) {
+	version := agentInfo.Rollout.SelectVersion(agentInfo.ID, agentInfo.Latest, rolloutKey)
+	if version != agentInfo.Latest {
+		candidate, err := uc.agentRepository.GetAgentVersion(ctx, agentInfo.ID, version)
+		if err == nil {
+			ctxlog.From(ctx).Debug("selected candidate version of agent in rollout",
+				"agent_uuid", agentInfo.ID,
+				"version", version,
+			)
+			return candidate, nil
+		}
+
+		ctxlog.From(ctx).Warn("failed to get candidate version of agent in rollout, using latest version",
+			"error", err,
+			"agent_uuid", agentInfo.ID,
+			"version", version,
+		)
+	}
+
+	return uc.agentRepository.GetLatestAgentVersion(ctx, agentInfo.ID)
+}
+
+// recordAgentTurn records a turn of the agent to the metrics of its version. Turns of general mode
+// are not recorded.
+func (uc *Slack) recordAgentTurn(ctx context.Context, agentCtx *agentContext, latency time.Duration, turnErr error) {
+	delta := &agent.VersionMetrics{TotalLatencyMS: latency.Milliseconds()}
+	if agentCtx.newThread {
+		delta.Threads = 1
+	}
+	if turnErr != nil {
+		delta.Errors = 1
+	} else {
+		delta.Responses = 1
+	}
+	uc.recordAgentMetrics(ctx, agentCtx.uuid, agentCtx.version, delta)
+}
+
+// recordAgentMetrics adds delta to the metrics of the agent version. Failures are logged and
+// ignored because metrics must not break conversations.
+func (uc *Slack) recordAgentMetrics(ctx context.Context, agentUUID types.UUID, version string, delta *agent.VersionMetrics) {
+	if uc.agentMetricsRepo == nil || agentUUID == generalModeUUID || version == "" {
+		return
+	}
+
+	delta.AgentUUID = agentUUID
+	delta.Version = version
+	if err := uc.agentMetricsRepo.AddAgentVersionMetrics(ctx, delta); err != nil {
+		ctxlog.From(ctx).Warn("failed to record agent version metrics",
+			"error", err,
+			"agent_uuid", agentUUID,
+			"agent_version", version,
+		)
+	}
+}
+
+// HandlePositiveFeedbackShortcut handles the message shortcut to rate a response of tamamo as good
+func (uc *Slack) HandlePositiveFeedbackShortcut(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	return uc.recordFeedback(ctx, callback, &agent.VersionMetrics{PositiveFeedback: 1})
+}
+
+// HandleNegativeFeedbackShortcut handles the message shortcut to rate a response of tamamo as bad
+func (uc *Slack) HandleNegativeFeedbackShortcut(ctx context.Context, callback *slackapi.InteractionCallback) error {
+	return uc.recordFeedback(ctx, callback, &agent.VersionMetrics{NegativeFeedback: 1})
+}
+
+// recordFeedback records the feedback to the agent version that answered the response. The version
+// of the turn is used because the thread may have been handed over to another agent since then.
+func (uc *Slack) recordFeedback(ctx context.Context, callback *slackapi.InteractionCallback, delta *agent.VersionMetrics) error {
+	logger := ctxlog.From(ctx)
+
+	msg := callback.Message
+	if msg.ThreadTimestamp == "" || !uc.slackClient.IsBotUser(msg.User) {
+		logger.Info("ignored feedback for a message that is not a response of tamamo in a thread",
+			"channel", callback.Channel.ID,
+			"message_ts", msg.Timestamp,
+			"user", callback.User.ID,
+		)
+		return nil
+	}
+	if uc.repository == nil {
+		return nil
+	}
+
+	thread, err := uc.repository.GetThreadByTS(ctx, callback.Channel.ID, msg.ThreadTimestamp)
+	if err != nil {
+		if errors.Is(err, slack.ErrThreadNotFound) {
+			return nil
+		}
+		return goerr.Wrap(err, "failed to get thread of feedback",
+			goerr.TV(apperr.ChannelIDKey, callback.Channel.ID),
+			goerr.V("thread_ts", msg.ThreadTimestamp))
+	}
+
+	agentUUID, version := thread.AgentUUID, thread.AgentVersion
+	histories, err := uc.repository.ListHistories(ctx, thread.ID)
+	if err != nil {
+		return goerr.Wrap(err, "failed to list histories of feedback", goerr.TV(apperr.ThreadIDKey, thread.ID))
+	}
+	if turn := findRegenerateTurn(histories, msg.Timestamp); turn != nil && turn.AgentUUID != nil {
+		agentUUID, version = turn.AgentUUID, turn.AgentVersion
+	}
+	if agentUUID == nil {
+		return nil
+	}
+
+	uc.recordAgentMetrics(ctx, *agentUUID, version, delta)
+	logger.Info("recorded feedback of agent response",
+		"thread_id", thread.ID,
+		"agent_uuid", *agentUUID,
+		"agent_version", version,
+		"positive", delta.PositiveFeedback > 0,
+		"user", callback.User.ID,
+	)
+	return nil
+}
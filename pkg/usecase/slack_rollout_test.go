@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	slackapi "github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestHandleSlackAppMentionWithRollout(t *testing.T) {
+	ctx := context.Background()
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	metricsRepo := memory.NewAgentMetricsRepository()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.1.0",
+		SystemPrompt: "You are a terse SRE helper.",
+	}))
+
+	// All new threads use the candidate version
+	rollout := usecase.NewRollout(
+		usecase.WithRolloutAgentRepository(agentRepo),
+		usecase.WithRolloutMetricsRepository(metricsRepo),
+	)
+	_, err := rollout.StartRollout(ctx, agentObj.ID, "1.1.0", 100)
+	gt.NoError(t, err)
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return &MockSession{
+				generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+					return &gollem.Response{Texts: []string{"Run df -h."}}, nil
+				},
+			}, nil
+		},
+	}
+
+	mockSlackClient := &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithAgentMetricsRepository(metricsRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(mockLLMClient),
+	)
+
+	msg := slack.NewMessage(ctx, &slackevents.EventsAPIEvent{
+		TeamID: "T12345",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Data: &slackevents.AppMentionEvent{
+				User:      "U67890USER",
+				Text:      "<@U12345BOT> sre-helper disk of web-1 is full",
+				TimeStamp: "1234567890.100000",
+				Channel:   "C11111",
+			},
+		},
+	})
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *msg))
+
+	thread, err := repo.GetThreadByTS(ctx, "C11111", "1234567890.100000")
+	gt.NoError(t, err)
+	gt.Equal(t, thread.AgentVersion, "1.1.0")
+	latest, err := repo.GetLatestHistory(ctx, thread.ID)
+	gt.NoError(t, err)
+	gt.Equal(t, latest.AgentVersion, "1.1.0")
+
+	// Feedback on the response is recorded to the version that answered it
+	callback := &slackapi.InteractionCallback{
+		Type:       slackapi.InteractionTypeMessageAction,
+		CallbackID: usecase.NegativeFeedbackShortcutCallbackID,
+	}
+	callback.Message.User = "U12345BOT"
+	callback.Message.Timestamp = "1234567890.200000"
+	callback.Message.ThreadTimestamp = "1234567890.100000"
+	callback.Channel.ID = "C11111"
+	callback.User.ID = "U67890USER"
+	gt.NoError(t, uc.HandleNegativeFeedbackShortcut(ctx, callback))
+
+	// Feedback on a message that is not a response of tamamo is ignored
+	callback.Message.User = "U67890USER"
+	gt.NoError(t, uc.HandlePositiveFeedbackShortcut(ctx, callback))
+
+	metrics, err := rollout.ListAgentVersionMetrics(ctx, agentObj.ID)
+	gt.NoError(t, err)
+	gt.A(t, metrics).Length(1)
+	gt.Equal(t, metrics[0].Version, "1.1.0")
+	gt.Equal(t, metrics[0].Threads, int64(1))
+	gt.Equal(t, metrics[0].Responses, int64(1))
+	gt.Equal(t, metrics[0].Errors, int64(0))
+	gt.Equal(t, metrics[0].PositiveFeedback, int64(0))
+	gt.Equal(t, metrics[0].NegativeFeedback, int64(1))
+}
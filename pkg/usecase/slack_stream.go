@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+)
+
+const (
+	// defaultStreamUpdateInterval throttles chat.update calls while streaming.
+	// chat.update is a Tier 3 method that allows around 50 requests per minute.
+	defaultStreamUpdateInterval = 1500 * time.Millisecond
+
+	// streamPlaceholderText is shown until the first tokens arrive
+	streamPlaceholderText = ":hourglass_flowing_sand: Thinking..."
+
+	// streamCursor is appended to the partial text to show generation is in progress
+	streamCursor = " :writing_hand:"
+
+	// maxStreamTextLength caps the partial text shown while streaming.
+	// Slack truncates messages longer than 40,000 characters.
+	maxStreamTextLength = 39000
+)
+
+// streamReceiver receives the response of LLM while it is generated
+type streamReceiver interface {
+	appendText(ctx context.Context, delta string)
+	setToolStatus(ctx context.Context, calls []*gollem.FunctionCall)
+}
+
+// streamMessage progressively updates a Slack message with text generated by LLM
+type streamMessage struct {
+	client    interfaces.SlackClient
+	channelID string
+	timestamp string
+	interval  time.Duration
+
+	text       strings.Builder
+	status     string
+	lastSent   string
+	lastUpdate time.Time
+}
+
+// appendText adds generated text and updates the message if the interval has elapsed
+func (m *streamMessage) appendText(ctx context.Context, delta string) {
+	if delta == "" {
+		return
+	}
+	m.text.WriteString(delta)
+	m.status = ""
+
+	if time.Since(m.lastUpdate) < m.interval {
+		return
+	}
+	m.update(ctx)
+}
+
+// setToolStatus shows the tools being run. It is updated immediately because tool calls
+// are separated by LLM round trips and do not hit rate limits.
+func (m *streamMessage) setToolStatus(ctx context.Context, calls []*gollem.FunctionCall) {
+	names := make([]string, 0, len(calls))
+	for _, call := range calls {
+		names = append(names, "`"+call.Name+"`")
+	}
+	m.status = "_:hammer_and_wrench: Running " + strings.Join(names, ", ") + "..._"
+	m.update(ctx)
+}
+
+func (m *streamMessage) update(ctx context.Context) {
+	text := truncateStreamText(m.text.String())
+	switch {
+	case m.status != "" && text != "":
+		text += "\n\n" + m.status
+	case m.status != "":
+		text = m.status
+	case text != "":
+		text += streamCursor
+	default:
+		return
+	}
+
+	if text == m.lastSent {
+		return
+	}
+
+	// Partial updates are best effort, e.g. they may be rate limited. The complete
+	// response is written by the final update anyway.
+	if err := m.client.UpdateMessage(ctx, m.channelID, m.timestamp, text); err != nil {
+		ctxlog.From(ctx).Warn("failed to update streaming message",
+			"error", err,
+			"channel", m.channelID,
+			"timestamp", m.timestamp,
+		)
+	}
+	m.lastSent = text
+	m.lastUpdate = time.Now()
+}
+
+// truncateStreamText keeps the tail of the text within maxStreamTextLength
+func truncateStreamText(text string) string {
+	if len(text) <= maxStreamTextLength {
+		return text
+	}
+	text = text[len(text)-maxStreamTextLength:]
+	for len(text) > 0 && !utf8.RuneStart(text[0]) {
+		text = text[1:]
+	}
+	return "..." + text
+}
+
+// respondWithStream posts a placeholder message and updates it as LLM generates the response.
+// The placeholder is finally replaced with the complete response, and the timestamp of the
+// response is returned.
+func (uc *Slack) respondWithStream(ctx context.Context, session gollem.Session, tools []gollem.Tool, slackMsg slack.Message, agent *agentContext, input ...gollem.Input) (string, error) {
+	logger := ctxlog.From(ctx)
+	channelID := slackMsg.Channel
+	threadTS := slackMsg.GetThreadTS()
+
+	var options *interfaces.SlackMessageOptions
+	if agent != nil && agent.uuid != generalModeUUID {
+		displayInfo, err := uc.getAgentDisplayInfo(ctx, agent)
+		if err != nil {
+			logger.Warn("failed to get agent display info, using basic message",
+				"agent_uuid", agent.uuid,
+				"error", err,
+			)
+		} else {
+			options = displayInfo
+		}
+	}
+
+	timestamp, err := uc.slackClient.PostMessageForUpdate(ctx, channelID, threadTS, streamPlaceholderText, options)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to post placeholder message")
+	}
+
+	interval := uc.streamUpdateInterval
+	if interval <= 0 {
+		interval = defaultStreamUpdateInterval
+	}
+	msg := &streamMessage{
+		client:     uc.slackClient,
+		channelID:  channelID,
+		timestamp:  timestamp,
+		interval:   interval,
+		lastUpdate: time.Now(),
+	}
+
+	resp, err := generateWithToolsStream(ctx, session, tools, msg, input...)
+	if err != nil {
+		// Remove the partial response. The caller notifies the error to the user.
+		if delErr := uc.slackClient.DeleteMessage(ctx, channelID, timestamp); delErr != nil {
+			logger.Warn("failed to delete streaming message",
+				"error", delErr,
+				"channel", channelID,
+				"timestamp", timestamp,
+			)
+		}
+		return "", err
+	}
+
+	responseText := responseTextOf(resp)
+	if err := uc.slackClient.UpdateMessage(ctx, channelID, timestamp, responseText); err != nil {
+		// Post the response as a new message not to lose it
+		logger.Warn("failed to finalize streaming message, posting a new message",
+			"error", err,
+			"channel", channelID,
+			"timestamp", timestamp,
+		)
+		responseTS, err := uc.postMessageWithAgentDisplay(ctx, channelID, threadTS, responseText, agent)
+		if err != nil {
+			return "", goerr.Wrap(err, "failed to post message to slack")
+		}
+		if err := uc.slackClient.DeleteMessage(ctx, channelID, timestamp); err != nil {
+			logger.Warn("failed to delete streaming message",
+				"error", err,
+				"channel", channelID,
+				"timestamp", timestamp,
+			)
+		}
+		return responseTS, nil
+	}
+
+	return timestamp, nil
+}
+
+// generateWithToolsStream works like generateWithTools but receives the response as a stream
+// and passes generated text to msg.
+func generateWithToolsStream(ctx context.Context, session gollem.Session, tools []gollem.Tool, msg streamReceiver, input ...gollem.Input) (*gollem.Response, error) {
+	toolMap := newToolMap(tools)
+
+	for i := 0; i < maxToolIterations; i++ {
+		stream, err := session.GenerateStream(ctx, input...)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := receiveStream(ctx, stream, msg)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.FunctionCalls) == 0 {
+			return resp, nil
+		}
+
+		msg.setToolStatus(ctx, resp.FunctionCalls)
+		input = runToolCalls(ctx, toolMap, resp.FunctionCalls)
+	}
+
+	return nil, goerr.New("exceeded maximum tool call iterations",
+		goerr.TV(apperr.RetryCountKey, maxToolIterations))
+}
+
+// receiveStream merges streamed chunks into a single response
+func receiveStream(ctx context.Context, stream <-chan *gollem.Response, msg streamReceiver) (*gollem.Response, error) {
+	resp := &gollem.Response{}
+	var text strings.Builder
+
+	for chunk := range stream {
+		if chunk == nil {
+			continue
+		}
+		if chunk.Error != nil {
+			// Drain the stream not to block the sender
+			go func() {
+				for range stream {
+				}
+			}()
+			return nil, goerr.Wrap(chunk.Error, "failed to receive streaming response")
+		}
+
+		for _, t := range chunk.Texts {
+			text.WriteString(t)
+			msg.appendText(ctx, t)
+		}
+		resp.FunctionCalls = append(resp.FunctionCalls, chunk.FunctionCalls...)
+	}
+
+	if text.Len() > 0 {
+		resp.Texts = []string{text.String()}
+	}
+	return resp, nil
+}
+
+// responseTextOf returns the text posted to Slack for the LLM response
+func responseTextOf(resp *gollem.Response) string {
+	if resp != nil && len(resp.Texts) > 0 && resp.Texts[0] != "" {
+		return resp.Texts[0]
+	}
+	// If no response was captured, use a fallback
+	return "(no response)"
+}
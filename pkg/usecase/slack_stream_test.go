@@ -0,0 +1,220 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func newStreamingSlackClient(botUserID string) *mock.SlackClientMock {
+	return &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1700000000.000200", nil
+		},
+		UpdateMessageFunc: func(ctx context.Context, channelID, timestamp, text string) error {
+			return nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, channelID, timestamp string) error {
+			return nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == botUserID
+		},
+	}
+}
+
+func streamOf(chunks ...*gollem.Response) <-chan *gollem.Response {
+	ch := make(chan *gollem.Response, len(chunks))
+	for _, chunk := range chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch
+}
+
+func newStreamingMention(ctx context.Context, text string) *slack.Message {
+	return slack.NewMessage(ctx, &slackevents.EventsAPIEvent{
+		TeamID: "T12345",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Data: &slackevents.AppMentionEvent{
+				User:      "U67890USER",
+				Text:      text,
+				TimeStamp: "1234567890.123456",
+				Channel:   "C11111",
+			},
+		},
+	})
+}
+
+func TestHandleSlackAppMentionWithStreaming(t *testing.T) {
+	ctx := context.Background()
+	botUserID := "U12345BOT"
+
+	agentRepo := memory.NewAgentMemoryClient()
+	setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	callCount := 0
+	mockSession := &MockSession{
+		generateStreamFunc: func(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error) {
+			callCount++
+			if callCount == 1 {
+				return streamOf(
+					&gollem.Response{Texts: []string{"Let me check. "}},
+					&gollem.Response{FunctionCalls: []*gollem.FunctionCall{
+						{ID: "call-1", Name: "unknown_tool", Arguments: map[string]any{}},
+					}},
+				), nil
+			}
+			return streamOf(
+				&gollem.Response{Texts: []string{"The database "}},
+				&gollem.Response{Texts: []string{"is "}},
+				&gollem.Response{Texts: []string{"healthy."}},
+			), nil
+		},
+	}
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return mockSession, nil
+		},
+	}
+
+	mockSlackClient := newStreamingSlackClient(botUserID)
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(memory.New()),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(mockLLMClient),
+		usecase.WithStreamResponse(true),
+		usecase.WithStreamUpdateInterval(time.Nanosecond),
+	)
+
+	msg := newStreamingMention(ctx, "<@U12345BOT> sre-helper is the database ok?")
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *msg))
+	gt.Equal(t, callCount, 2)
+
+	// Placeholder is posted in the thread with agent display, and the response is not posted as a
+	// new message
+	postCalls := mockSlackClient.PostMessageForUpdateCalls()
+	gt.A(t, postCalls).Length(1)
+	gt.Equal(t, postCalls[0].ThreadTS, "1234567890.123456")
+	gt.V(t, postCalls[0].Options).NotNil()
+	gt.Equal(t, postCalls[0].Options.Username, "SRE Helper")
+
+	updates := mockSlackClient.UpdateMessageCalls()
+	gt.True(t, len(updates) > 2)
+	for _, update := range updates {
+		gt.Equal(t, update.Timestamp, "1700000000.000200")
+	}
+
+	var sawToolStatus, sawPartial bool
+	for _, update := range updates[:len(updates)-1] {
+		if update.Text == "Let me check. \n\n_:hammer_and_wrench: Running `unknown_tool`..._" {
+			sawToolStatus = true
+		}
+		if update.Text == "Let me check. The database is  :writing_hand:" {
+			sawPartial = true
+		}
+	}
+	gt.True(t, sawToolStatus)
+	gt.True(t, sawPartial)
+
+	// Final update replaces the streamed text with the complete response
+	gt.Equal(t, updates[len(updates)-1].Text, "The database is healthy.")
+	gt.A(t, mockSlackClient.DeleteMessageCalls()).Length(0)
+}
+
+func TestHandleSlackAppMentionWithStreamingError(t *testing.T) {
+	ctx := context.Background()
+	botUserID := "U12345BOT"
+
+	mockSession := &MockSession{
+		generateStreamFunc: func(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error) {
+			return streamOf(
+				&gollem.Response{Texts: []string{"partial"}},
+				&gollem.Response{Error: errors.New("stream broken")},
+			), nil
+		},
+	}
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return mockSession, nil
+		},
+	}
+
+	mockSlackClient := newStreamingSlackClient(botUserID)
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(memory.New()),
+		usecase.WithLLMClient(mockLLMClient),
+		usecase.WithStreamResponse(true),
+	)
+
+	msg := newStreamingMention(ctx, "<@U12345BOT> !hello")
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *msg))
+
+	// Partial response is removed and the error is notified as a new message
+	gt.A(t, mockSlackClient.DeleteMessageCalls()).Length(1)
+	gt.Equal(t, mockSlackClient.DeleteMessageCalls()[0].Timestamp, "1700000000.000200")
+	gt.A(t, mockSlackClient.PostMessageCalls()).Length(1)
+	gt.S(t, mockSlackClient.PostMessageCalls()[0].Text).Contains("experiencing issues")
+}
+
+func TestHandleSlackAppMentionWithStreamingFinalUpdateFailure(t *testing.T) {
+	ctx := context.Background()
+	botUserID := "U12345BOT"
+
+	mockSession := &MockSession{
+		generateStreamFunc: func(ctx context.Context, input ...gollem.Input) (<-chan *gollem.Response, error) {
+			return streamOf(&gollem.Response{Texts: []string{"Hello!"}}), nil
+		},
+	}
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return mockSession, nil
+		},
+	}
+
+	mockSlackClient := newStreamingSlackClient(botUserID)
+	mockSlackClient.UpdateMessageFunc = func(ctx context.Context, channelID, timestamp, text string) error {
+		return errors.New("ratelimited")
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(memory.New()),
+		usecase.WithLLMClient(mockLLMClient),
+		usecase.WithStreamResponse(true),
+	)
+
+	msg := newStreamingMention(ctx, "<@U12345BOT> !hello")
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *msg))
+
+	// Response is posted as a new message and the placeholder is removed
+	postCalls := mockSlackClient.PostMessageForUpdateCalls()
+	gt.A(t, postCalls).Length(2)
+	gt.Equal(t, postCalls[1].Text, "Hello!")
+	gt.A(t, mockSlackClient.PostMessageCalls()).Length(0)
+	gt.A(t, mockSlackClient.DeleteMessageCalls()).Length(1)
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+)
+
+const (
+	// maxThreadContextLength caps the thread messages passed to the agent in a turn. Older
+	// messages are dropped first.
+	maxThreadContextLength = 20000
+
+	// maxBackfillReplies limits the replies fetched when tamamo joins a thread late
+	maxBackfillReplies = 200
+)
+
+// buildThreadContext returns messages humans posted in the thread that the agent has not seen
+// yet. For a thread with history, they are messages recorded after the latest history snapshot.
+// For a thread tamamo joins late, earlier replies are fetched from Slack. Empty string is
+// returned if there is no such message.
+func (uc *Slack) buildThreadContext(ctx context.Context, slackMsg slack.Message, threadID types.ThreadID, snapshot *slack.History, lateJoin bool) string {
+	var messages []*slack.Message
+	var intro string
+
+	switch {
+	case snapshot != nil:
+		messages = uc.threadMessagesSince(ctx, slackMsg, threadID, snapshot)
+		intro = "Messages posted in this Slack thread since your last response:"
+
+	case lateJoin:
+		messages = uc.backfillThreadReplies(ctx, slackMsg)
+		intro = "Messages posted in this Slack thread before you were mentioned:"
+	}
+
+	if len(messages) == 0 {
+		return ""
+	}
+
+	names := uc.userDisplayNames(ctx, messages)
+	lines := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		lines = append(lines, fmt.Sprintf("[%s] %s", names[msg.UserID], msg.Text))
+	}
+
+	ctxlog.From(ctx).Debug("included thread messages as context",
+		"thread_id", threadID,
+		"messages", len(messages),
+		"late_join", snapshot == nil,
+	)
+
+	return intro + "\n<thread_messages>\n" +
+		truncateHead(strings.Join(lines, "\n"), maxThreadContextLength) +
+		"\n</thread_messages>"
+}
+
+// threadMessagesSince returns human messages recorded in the thread after the snapshot
+func (uc *Slack) threadMessagesSince(ctx context.Context, current slack.Message, threadID types.ThreadID, snapshot *slack.History) []*slack.Message {
+	if !threadID.IsValid() || uc.repository == nil {
+		return nil
+	}
+
+	recorded, err := uc.repository.GetThreadMessages(ctx, threadID)
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to get thread messages, continue without them",
+			"error", err,
+			"thread_id", threadID,
+		)
+		return nil
+	}
+
+	var messages []*slack.Message
+	for _, msg := range recorded {
+		if msg.CreatedAt.After(snapshot.CreatedAt) && msg.Timestamp != current.Timestamp && uc.isHumanMessage(msg) {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// backfillThreadReplies fetches human messages posted in the thread before the mention
+func (uc *Slack) backfillThreadReplies(ctx context.Context, current slack.Message) []*slack.Message {
+	replies, err := uc.slackClient.GetThreadReplies(ctx, current.Channel, current.GetThreadTS(), maxBackfillReplies)
+	if err != nil {
+		ctxlog.From(ctx).Warn("failed to get thread replies, continue without them",
+			"error", err,
+			"channel", current.Channel,
+			"thread_ts", current.GetThreadTS(),
+		)
+		return nil
+	}
+
+	var messages []*slack.Message
+	for _, msg := range replies {
+		if msg.Timestamp == current.Timestamp {
+			break
+		}
+		if uc.isHumanMessage(msg) {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// isHumanMessage reports whether the message is posted by a human and has text
+func (uc *Slack) isHumanMessage(msg *slack.Message) bool {
+	return msg.Text != "" && msg.BotID == "" && msg.UserID != "" && !uc.slackClient.IsBotUser(msg.UserID)
+}
+
+// userDisplayNames returns display names of users who posted the messages. User ID is used if
+// the user information is not available.
+func (uc *Slack) userDisplayNames(ctx context.Context, messages []*slack.Message) map[string]string {
+	names := make(map[string]string)
+	for _, msg := range messages {
+		if _, ok := names[msg.UserID]; ok {
+			continue
+		}
+
+		names[msg.UserID] = msg.UserID
+		info, err := uc.slackClient.GetUserInfo(ctx, msg.UserID)
+		if err != nil {
+			ctxlog.From(ctx).Warn("failed to get user info for thread context",
+				"error", err,
+				"user_id", msg.UserID,
+			)
+			continue
+		}
+
+		for _, name := range []string{info.DisplayName, info.RealName, info.Name} {
+			if name != "" {
+				names[msg.UserID] = name
+				break
+			}
+		}
+	}
+	return names
+}
+
+// truncateHead drops the head of text so that it fits in limit bytes
+func truncateHead(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+
+	text = text[len(text)-limit:]
+	for len(text) > 0 && !utf8.RuneStart(text[0]) {
+		text = text[1:]
+	}
+	return "...\n" + text
+}
@@ -0,0 +1,143 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+)
+
+type threadContextFixture struct {
+	repo        *memory.Client
+	slackClient *mock.SlackClientMock
+	inputs      [][]gollem.Input
+	uc          *usecase.Slack
+}
+
+func newThreadContextFixture(t *testing.T, replies []*slack.Message) *threadContextFixture {
+	t.Helper()
+
+	f := &threadContextFixture{repo: memory.New()}
+	f.slackClient = &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == "U12345BOT"
+		},
+		GetUserInfoFunc: func(ctx context.Context, userID string) (*interfaces.SlackUserInfo, error) {
+			if userID == "U00000BOB" {
+				return nil, errors.New("user_not_found")
+			}
+			return &interfaces.SlackUserInfo{ID: userID, Name: "alice.s", DisplayName: "alice"}, nil
+		},
+		GetThreadRepliesFunc: func(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+			return replies, nil
+		},
+	}
+
+	agentRepo := memory.NewAgentMemoryClient()
+	setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	f.uc = usecase.New(
+		usecase.WithSlackClient(f.slackClient),
+		usecase.WithRepository(f.repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithLLMClient(&llm_mock.LLMClientMock{
+			CountTokensFunc: countTokensBySize,
+			NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+				return &MockSession{
+					generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+						f.inputs = append(f.inputs, input)
+						return &gollem.Response{Texts: []string{"Let me check."}}, nil
+					},
+				}, nil
+			},
+		}),
+	)
+	return f
+}
+
+func TestHandleSlackAppMentionThreadContext(t *testing.T) {
+	ctx := context.Background()
+	threadTS := "1234567890.100000"
+
+	t.Run("messages after the latest history are included", func(t *testing.T) {
+		f := newThreadContextFixture(t, nil)
+		now := time.Now()
+
+		thread, err := f.repo.GetOrPutThread(ctx, "T12345", "C11111", threadTS)
+		gt.NoError(t, err)
+		snapshot := slack.NewHistory(ctx, thread.ID)
+		snapshot.CreatedAt = now.Add(-time.Hour)
+		gt.NoError(t, f.repo.PutHistory(ctx, snapshot))
+
+		for _, msg := range []*slack.Message{
+			{UserID: "U67890USER", Text: "<@U12345BOT> the API is slow", Timestamp: "1234567890.100000", CreatedAt: now.Add(-2 * time.Hour)},
+			{UserID: "U67890USER", Text: "I restarted the pod", Timestamp: "1234567890.110000", CreatedAt: now.Add(-30 * time.Minute)},
+			{BotID: "B12345", Text: "Deploy finished", Timestamp: "1234567890.120000", CreatedAt: now.Add(-20 * time.Minute)},
+			{UserID: "U00000BOB", Text: "It is still slow", Timestamp: "1234567890.130000", CreatedAt: now.Add(-10 * time.Minute)},
+		} {
+			msg.ID = types.NewMessageID(ctx)
+			msg.ThreadID = thread.ID
+			gt.NoError(t, f.repo.PutThreadMessage(ctx, thread.ID, msg))
+		}
+
+		msg := newThreadMention(ctx, "<@U12345BOT> did the restart help?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.inputs).Length(1)
+		gt.A(t, f.inputs[0]).Length(2)
+		gt.Equal(t, f.inputs[0][0].(gollem.Text), gollem.Text("Messages posted in this Slack thread since your last response:\n"+
+			"<thread_messages>\n[alice] I restarted the pod\n[U00000BOB] It is still slow\n</thread_messages>"))
+		gt.Equal(t, f.inputs[0][1].(gollem.Text), gollem.Text("did the restart help?"))
+		gt.A(t, f.slackClient.GetThreadRepliesCalls()).Length(0)
+	})
+
+	t.Run("earlier replies are backfilled when joining a thread late", func(t *testing.T) {
+		f := newThreadContextFixture(t, []*slack.Message{
+			{UserID: "U67890USER", Text: "Login fails with 500", Timestamp: threadTS},
+			{BotID: "B12345", Text: "Alert fired", Timestamp: "1234567890.110000"},
+			{UserID: "U00000BOB", Text: "Started after the deploy", Timestamp: "1234567890.150000"},
+			{UserID: "U67890USER", Text: "<@U12345BOT> sre-helper any idea?", Timestamp: "1234567890.200000"},
+			{UserID: "U00000BOB", Text: "Posted after the mention", Timestamp: "1234567890.300000"},
+		})
+
+		msg := newThreadMention(ctx, "<@U12345BOT> sre-helper any idea?", threadTS)
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.slackClient.GetThreadRepliesCalls()).Length(1)
+		call := f.slackClient.GetThreadRepliesCalls()[0]
+		gt.Equal(t, call.ChannelID, "C11111")
+		gt.Equal(t, call.ThreadTS, threadTS)
+
+		gt.A(t, f.inputs[0]).Length(2)
+		gt.Equal(t, f.inputs[0][0].(gollem.Text), gollem.Text("Messages posted in this Slack thread before you were mentioned:\n"+
+			"<thread_messages>\n[alice] Login fails with 500\n[U00000BOB] Started after the deploy\n</thread_messages>"))
+	})
+
+	t.Run("mention starting a thread has no context", func(t *testing.T) {
+		f := newThreadContextFixture(t, nil)
+
+		msg := newThreadMention(ctx, "<@U12345BOT> sre-helper hello", "")
+		gt.NoError(t, f.uc.HandleSlackAppMention(ctx, msg))
+
+		gt.A(t, f.slackClient.GetThreadRepliesCalls()).Length(0)
+		gt.A(t, f.inputs[0]).Length(1)
+	})
+}
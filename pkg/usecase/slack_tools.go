@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/gollem"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/user"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	jiraservice "github.com/m-mizutani/tamamo/pkg/service/jira"
+	mcpservice "github.com/m-mizutani/tamamo/pkg/service/mcp"
+	notionservice "github.com/m-mizutani/tamamo/pkg/service/notion"
+	slackservice "github.com/m-mizutani/tamamo/pkg/service/slack"
+)
+
+// maxToolIterations limits the number of tool call rounds in a single turn
+const maxToolIterations = 16
+
+// buildAgentTools collects gollem tools available to the agent from its search configurations.
+// Tools for external services are authorized by the integration of the user who sent slackMsg.
+func (uc *Slack) buildAgentTools(ctx context.Context, agent *agentContext, slackMsg slack.Message) []gollem.Tool {
+	logger := ctxlog.From(ctx)
+
+	// General mode has no agent specific configuration
+	if agent == nil || agent.uuid == generalModeUUID {
+		return nil
+	}
+
+	var tools []gollem.Tool
+
+	if uc.slackSearchConfigs != nil && uc.slackMessageLogRepo != nil {
+		configs, err := uc.slackSearchConfigs.GetEnabledSlackSearchConfigs(ctx, agent.uuid.String())
+		if err != nil {
+			logger.Warn("failed to get enabled slack search configs, continue without slack search tools",
+				"error", err,
+				"agent_uuid", agent.uuid,
+			)
+		} else {
+			for _, config := range configs {
+				tools = append(tools, slackservice.NewSearchTool(uc.slackMessageLogRepo, config, uc.slackWorkspaceURL))
+			}
+		}
+	}
+
+	if tool := uc.buildJiraSearchTool(ctx, agent, slackMsg); tool != nil {
+		tools = append(tools, tool)
+	}
+
+	tools = append(tools, uc.buildNotionTools(ctx, agent, slackMsg)...)
+
+	if tool := uc.buildKnowledgeTool(ctx, agent); tool != nil {
+		tools = append(tools, tool)
+	}
+
+	if len(tools) > 0 {
+		names := make([]string, 0, len(tools))
+		for _, tool := range tools {
+			names = append(names, tool.Spec().Name)
+		}
+		logger.Debug("built agent tools",
+			"agent_uuid", agent.uuid,
+			"tools", names,
+		)
+	}
+
+	return tools
+}
+
+// connectMCPServers connects to the MCP servers attached to the agent version and returns their tools.
+// Servers that fail to connect are skipped so that the agent can still respond. The returned
+// function closes all connections and must be called after the conversation.
+func (uc *Slack) connectMCPServers(ctx context.Context, agent *agentContext) ([]gollem.Tool, func()) {
+	logger := ctxlog.From(ctx)
+
+	if agent == nil || len(agent.mcpServers) == 0 {
+		return nil, func() {}
+	}
+
+	var tools []gollem.Tool
+	var clients []*mcpservice.Client
+	for _, server := range agent.mcpServers {
+		client, err := mcpservice.Connect(ctx, server, uc.mcpConnectOptions...)
+		if err != nil {
+			logger.Warn("failed to connect MCP server, continue without its tools",
+				"error", err,
+				"agent_uuid", agent.uuid,
+				"mcp_server", server.Name,
+			)
+			continue
+		}
+		clients = append(clients, client)
+
+		serverTools, err := client.Tools(ctx)
+		if err != nil {
+			logger.Warn("failed to list MCP tools, continue without its tools",
+				"error", err,
+				"agent_uuid", agent.uuid,
+				"mcp_server", server.Name,
+			)
+			continue
+		}
+		tools = append(tools, serverTools...)
+	}
+
+	closeAll := func() {
+		for _, client := range clients {
+			if err := client.Close(); err != nil {
+				logger.Warn("failed to close MCP server connection",
+					"error", err,
+					"mcp_server", client.Name(),
+				)
+			}
+		}
+	}
+
+	return tools, closeAll
+}
+
+// buildJiraSearchTool creates the Jira issue search tool if the agent has enabled Jira
+// search configs and the requesting user has connected Jira. Returns nil otherwise.
+func (uc *Slack) buildJiraSearchTool(ctx context.Context, agent *agentContext, slackMsg slack.Message) gollem.Tool {
+	logger := ctxlog.From(ctx)
+
+	if uc.jiraSearchConfigs == nil || uc.jiraIntegrations == nil || uc.userRepo == nil {
+		return nil
+	}
+
+	configs, err := uc.jiraSearchConfigs.GetEnabledJiraSearchConfigs(ctx, agent.uuid.String())
+	if err != nil {
+		logger.Warn("failed to get enabled jira search configs, continue without jira search tool",
+			"error", err,
+			"agent_uuid", agent.uuid,
+		)
+		return nil
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+
+	// Jira is accessed with the requesting user's own token so that the agent can not
+	// reveal issues the user is not allowed to see
+	u := uc.lookupRequester(ctx, slackMsg)
+	if u == nil {
+		return nil
+	}
+
+	jiraIntegration, err := uc.jiraIntegrations.GetIntegration(ctx, u.ID.String())
+	if err != nil {
+		logger.Warn("failed to get jira integration, continue without jira search tool",
+			"error", err,
+			"user_id", u.ID,
+		)
+		return nil
+	}
+	if jiraIntegration == nil || !jiraIntegration.IsConnected() {
+		logger.Debug("user has not connected jira, skip jira search tool",
+			"user_id", u.ID,
+		)
+		return nil
+	}
+
+	client := jiraservice.NewClient(jiraIntegration.CloudID, jiraIntegration.AccessToken, uc.jiraClientOptions...)
+	return jiraservice.NewSearchTool(client, configs, jiraIntegration.SiteURL)
+}
+
+// buildNotionTools creates the Notion search and page tools if the agent has enabled Notion
+// search configs in the workspace the requesting user has connected. Returns nil otherwise.
+func (uc *Slack) buildNotionTools(ctx context.Context, agent *agentContext, slackMsg slack.Message) []gollem.Tool {
+	logger := ctxlog.From(ctx)
+
+	if uc.notionSearchConfigs == nil || uc.notionIntegrations == nil || uc.userRepo == nil {
+		return nil
+	}
+
+	configs, err := uc.notionSearchConfigs.GetEnabledNotionSearchConfigs(ctx, agent.uuid.String())
+	if err != nil {
+		logger.Warn("failed to get enabled notion search configs, continue without notion tools",
+			"error", err,
+			"agent_uuid", agent.uuid,
+		)
+		return nil
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+
+	// Notion is accessed with the requesting user's own token as well as Jira
+	u := uc.lookupRequester(ctx, slackMsg)
+	if u == nil {
+		return nil
+	}
+
+	notionIntegration, err := uc.notionIntegrations.GetIntegration(ctx, u.ID.String())
+	if err != nil {
+		logger.Warn("failed to get notion integration, continue without notion tools",
+			"error", err,
+			"user_id", u.ID,
+		)
+		return nil
+	}
+	if notionIntegration == nil || !notionIntegration.IsConnected() {
+		logger.Debug("user has not connected notion, skip notion tools",
+			"user_id", u.ID,
+		)
+		return nil
+	}
+
+	// Databases in other workspaces are not accessible with the user's token
+	available := filterNotionConfigsByWorkspace(configs, notionIntegration.WorkspaceID)
+	if len(available) == 0 {
+		logger.Debug("no notion database in the user's workspace, skip notion tools",
+			"user_id", u.ID,
+			"workspace_id", notionIntegration.WorkspaceID,
+		)
+		return nil
+	}
+
+	client := notionservice.NewClient(notionIntegration.AccessToken, uc.notionClientOptions...)
+	return []gollem.Tool{
+		notionservice.NewSearchTool(client, available),
+		notionservice.NewPageTool(client, available),
+	}
+}
+
+// filterNotionConfigsByWorkspace returns configs that belong to the workspace.
+// Configs without workspace ID are kept for backward compatibility.
+func filterNotionConfigsByWorkspace(configs []*agent.NotionSearchConfig, workspaceID string) []*agent.NotionSearchConfig {
+	var filtered []*agent.NotionSearchConfig
+	for _, config := range configs {
+		if config.WorkspaceID == "" || config.WorkspaceID == workspaceID {
+			filtered = append(filtered, config)
+		}
+	}
+	return filtered
+}
+
+// lookupRequester returns the Tamamo user who sent slackMsg, or nil if not registered
+func (uc *Slack) lookupRequester(ctx context.Context, slackMsg slack.Message) *user.User {
+	u, err := uc.userRepo.GetBySlackIDAndTeamID(ctx, slackMsg.UserID, slackMsg.TeamID)
+	if err != nil || u == nil {
+		ctxlog.From(ctx).Debug("slack user is not registered, skip tools using user integration",
+			"error", err,
+			"slack_user_id", slackMsg.UserID,
+		)
+		return nil
+	}
+	return u
+}
+
+// generateWithTools generates content through the session and executes tool calls
+// requested by LLM until it returns a response without function calls.
+func generateWithTools(ctx context.Context, session gollem.Session, tools []gollem.Tool, input ...gollem.Input) (*gollem.Response, error) {
+	toolMap := newToolMap(tools)
+
+	for i := 0; i < maxToolIterations; i++ {
+		resp, err := session.GenerateContent(ctx, input...)
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil || len(resp.FunctionCalls) == 0 {
+			return resp, nil
+		}
+
+		input = runToolCalls(ctx, toolMap, resp.FunctionCalls)
+	}
+
+	return nil, goerr.New("exceeded maximum tool call iterations",
+		goerr.TV(apperr.RetryCountKey, maxToolIterations))
+}
+
+func newToolMap(tools []gollem.Tool) map[string]gollem.Tool {
+	toolMap := make(map[string]gollem.Tool, len(tools))
+	for _, tool := range tools {
+		toolMap[tool.Spec().Name] = tool
+	}
+	return toolMap
+}
+
+// runToolCalls executes function calls and returns their results as inputs for the next generation
+func runToolCalls(ctx context.Context, toolMap map[string]gollem.Tool, calls []*gollem.FunctionCall) []gollem.Input {
+	logger := ctxlog.From(ctx)
+
+	input := make([]gollem.Input, 0, len(calls))
+	for _, call := range calls {
+		funcResp := gollem.FunctionResponse{
+			ID:   call.ID,
+			Name: call.Name,
+		}
+
+		tool, ok := toolMap[call.Name]
+		if !ok {
+			funcResp.Error = goerr.New("unknown tool", goerr.V("name", call.Name))
+			input = append(input, funcResp)
+			continue
+		}
+
+		logger.Debug("running tool",
+			"name", call.Name,
+			"args", call.Arguments,
+		)
+
+		result, err := tool.Run(ctx, call.Arguments)
+		if err != nil {
+			// Report the error to LLM so that it can retry with different arguments
+			logger.Warn("tool execution failed",
+				"name", call.Name,
+				"error", err,
+			)
+			funcResp.Error = err
+		} else {
+			funcResp.Data = result
+		}
+		input = append(input, funcResp)
+	}
+
+	return input
+}
@@ -0,0 +1,272 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gollem"
+	llm_mock "github.com/m-mizutani/gollem/mock"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/mock"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/agent"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/integration"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/slack"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/user"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	"github.com/m-mizutani/tamamo/pkg/service/jira"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// setupToolTestAgent creates an active agent with a single version in the repository
+func setupToolTestAgent(t *testing.T, agentRepo *memory.AgentMemoryClient, agentID string) *agent.Agent {
+	t.Helper()
+	ctx := context.Background()
+
+	agentObj := &agent.Agent{
+		ID:      types.NewUUID(ctx),
+		AgentID: agentID,
+		Name:    "SRE Helper",
+		Status:  agent.StatusActive,
+		Latest:  "1.0.0",
+	}
+	gt.NoError(t, agentRepo.CreateAgent(ctx, agentObj))
+	gt.NoError(t, agentRepo.CreateAgentVersion(ctx, &agent.AgentVersion{
+		AgentUUID:    agentObj.ID,
+		Version:      "1.0.0",
+		SystemPrompt: "You are an SRE helper.",
+	}))
+
+	return agentObj
+}
+
+func TestHandleSlackAppMentionWithSlackSearchTool(t *testing.T) {
+	ctx := context.Background()
+	botUserID := "U12345BOT"
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	// Prepare search config and logged messages
+	searchConfigUC := usecase.NewSlackSearchConfig(
+		usecase.WithSlackSearchConfigRepository(memory.NewSlackSearchConfigRepository()),
+		usecase.WithSlackSearchConfigAgentRepository(agentRepo),
+	)
+	_, err := searchConfigUC.CreateSlackSearchConfig(ctx, agentObj.ID.String(), "C999", "sre", nil, true)
+	gt.NoError(t, err)
+
+	gt.NoError(t, repo.PutSlackMessageLog(ctx, &slack.SlackMessageLog{
+		ID:          types.NewMessageID(ctx),
+		ChannelID:   "C999",
+		ChannelName: "sre",
+		UserID:      "U111",
+		UserName:    "alice",
+		MessageType: slack.MessageTypeUser,
+		Text:        "We decided to shard the database",
+		Timestamp:   "1700000000.000100",
+		CreatedAt:   time.Now(),
+	}))
+
+	var secondInput []gollem.Input
+	callCount := 0
+	mockSession := &MockSession{
+		generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+			callCount++
+			if callCount == 1 {
+				return &gollem.Response{
+					FunctionCalls: []*gollem.FunctionCall{
+						{
+							ID:        "call-1",
+							Name:      "search_slack_C999",
+							Arguments: map[string]any{"keyword": "database"},
+						},
+					},
+				}, nil
+			}
+
+			secondInput = input
+			return &gollem.Response{
+				Texts: []string{"The team decided to shard the database."},
+			}, nil
+		},
+	}
+
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			return mockSession, nil
+		},
+	}
+
+	mockSlackClient := &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == botUserID
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithSlackMessageLogRepository(repo),
+		usecase.WithSlackSearchConfigUseCases(searchConfigUC),
+		usecase.WithSlackWorkspaceURL("https://example.slack.com/"),
+		usecase.WithLLMClient(mockLLMClient),
+	)
+
+	ev := &slackevents.EventsAPIEvent{
+		TeamID: "T12345",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Data: &slackevents.AppMentionEvent{
+				User:      "U67890USER",
+				Text:      "<@U12345BOT> sre-helper what did we decide about the database?",
+				TimeStamp: "1234567890.123456",
+				Channel:   "C11111",
+			},
+		},
+	}
+	msg := slack.NewMessage(ctx, ev)
+
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *msg))
+
+	// LLM is called twice: tool call and final answer
+	gt.Equal(t, callCount, 2)
+	gt.A(t, secondInput).Length(1)
+
+	funcResp, ok := secondInput[0].(gollem.FunctionResponse)
+	gt.True(t, ok)
+	gt.Equal(t, funcResp.ID, "call-1")
+	gt.Equal(t, funcResp.Name, "search_slack_C999")
+	gt.NoError(t, funcResp.Error)
+	gt.Equal(t, funcResp.Data["count"], 1)
+
+	messages := funcResp.Data["messages"].([]map[string]any)
+	gt.S(t, messages[0]["permalink"].(string)).Contains("https://example.slack.com/archives/C999/")
+
+	// Final answer is posted with agent display options
+	gt.A(t, mockSlackClient.PostMessageForUpdateCalls()).Length(1)
+	gt.S(t, mockSlackClient.PostMessageForUpdateCalls()[0].Text).Contains("shard the database")
+}
+
+func TestHandleSlackAppMentionWithJiraSearchTool(t *testing.T) {
+	ctx := context.Background()
+	botUserID := "U12345BOT"
+
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer user-jira-token")
+		_, _ = w.Write([]byte(`{"issues": [{"key": "OPS-1", "fields": {"summary": "Database is slow", "status": {"name": "Open"}}}]}`))
+	}))
+	defer jiraServer.Close()
+
+	repo := memory.New()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	jiraSearchConfigUC := usecase.NewJiraSearchConfig(
+		usecase.WithJiraSearchConfigRepository(memory.NewJiraSearchConfigRepository()),
+		usecase.WithJiraSearchConfigAgentRepository(agentRepo),
+	)
+	_, err := jiraSearchConfigUC.CreateJiraSearchConfig(ctx, agentObj.ID.String(), "OPS", "Operations", nil, nil, nil, true)
+	gt.NoError(t, err)
+
+	// The requesting Slack user has connected Jira
+	userRepo := memory.NewUserRepository()
+	requester := user.NewUser("U67890USER", "bob", "Bob", "bob@example.com", "T12345")
+	gt.NoError(t, userRepo.Create(ctx, requester))
+	jiraIntegration := integration.NewJiraIntegration(requester.ID.String())
+	jiraIntegration.UpdateSiteInfo("cloud-id", "https://example.atlassian.net")
+	jiraIntegration.UpdateTokens("user-jira-token", "refresh-token", time.Now().Add(time.Hour))
+	gt.NoError(t, userRepo.SaveJiraIntegration(ctx, jiraIntegration))
+
+	var secondInput []gollem.Input
+	callCount := 0
+	mockSession := &MockSession{
+		generateContentFunc: func(ctx context.Context, input ...gollem.Input) (*gollem.Response, error) {
+			callCount++
+			if callCount == 1 {
+				return &gollem.Response{
+					FunctionCalls: []*gollem.FunctionCall{
+						{ID: "call-1", Name: jira.SearchToolName, Arguments: map[string]any{"keyword": "database"}},
+					},
+				}, nil
+			}
+			secondInput = input
+			return &gollem.Response{Texts: []string{"OPS-1 is open."}}, nil
+		},
+	}
+
+	var sessionOptionCount int
+	mockLLMClient := &llm_mock.LLMClientMock{
+		CountTokensFunc: countTokensBySize,
+		NewSessionFunc: func(ctx context.Context, options ...gollem.SessionOption) (gollem.Session, error) {
+			sessionOptionCount = len(options)
+			return mockSession, nil
+		},
+	}
+
+	mockSlackClient := &mock.SlackClientMock{
+		PostMessageFunc: func(ctx context.Context, channelID, threadTS, text string) error {
+			return nil
+		},
+		PostMessageForUpdateFunc: func(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+			return "1234567890.900000", nil
+		},
+		IsBotUserFunc: func(uid string) bool {
+			return uid == botUserID
+		},
+	}
+
+	uc := usecase.New(
+		usecase.WithSlackClient(mockSlackClient),
+		usecase.WithRepository(repo),
+		usecase.WithAgentRepository(agentRepo),
+		usecase.WithStorageRepository(storage.New(newMockStorageAdapter())),
+		usecase.WithJiraSearchConfigUseCases(jiraSearchConfigUC),
+		usecase.WithJiraIntegrationUseCases(usecase.NewJiraIntegrationUseCases(userRepo, nil)),
+		usecase.WithJiraClientOptions(jira.WithAPIBaseURL(jiraServer.URL)),
+		usecase.WithUserRepository(userRepo),
+		usecase.WithLLMClient(mockLLMClient),
+	)
+
+	ev := &slackevents.EventsAPIEvent{
+		TeamID: "T12345",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Data: &slackevents.AppMentionEvent{
+				User:      "U67890USER",
+				Text:      "<@U12345BOT> sre-helper any open database issues?",
+				TimeStamp: "1234567890.123456",
+				Channel:   "C11111",
+			},
+		},
+	}
+	msg := slack.NewMessage(ctx, ev)
+
+	gt.NoError(t, uc.HandleSlackAppMention(ctx, *msg))
+
+	// System prompt and tools are passed as session options
+	gt.Equal(t, sessionOptionCount, 2)
+	gt.Equal(t, callCount, 2)
+	gt.A(t, secondInput).Length(1)
+
+	funcResp, ok := secondInput[0].(gollem.FunctionResponse)
+	gt.True(t, ok)
+	gt.NoError(t, funcResp.Error)
+	gt.Equal(t, funcResp.Data["count"], 1)
+
+	issues := funcResp.Data["issues"].([]map[string]any)
+	gt.Equal(t, issues[0]["link"], "https://example.atlassian.net/browse/OPS-1")
+}
@@ -6,7 +6,10 @@ import (
 	"github.com/m-mizutani/gollem"
 	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
 	"github.com/m-mizutani/tamamo/pkg/repository/storage"
+	jiraservice "github.com/m-mizutani/tamamo/pkg/service/jira"
 	"github.com/m-mizutani/tamamo/pkg/service/llm"
+	mcpservice "github.com/m-mizutani/tamamo/pkg/service/mcp"
+	notionservice "github.com/m-mizutani/tamamo/pkg/service/notion"
 	slackservice "github.com/m-mizutani/tamamo/pkg/service/slack"
 )
 
@@ -21,8 +24,34 @@ type Slack struct {
 	llmClient           gollem.LLMClient // Deprecated: use llmFactory instead
 	llmModel            string           // Deprecated: use llmFactory instead
 	llmFactory          *llm.Factory
+	contextWindowSize   int    // Context window in tokens of LLM client set by WithLLMClient
 	serverBaseURL       string // Base URL for constructing image URLs
 	channelCache        *slackservice.ChannelCache
+
+	// Search tool sources
+	slackSearchConfigs  interfaces.SlackSearchConfigUseCases
+	slackWorkspaceURL   string // Workspace URL for constructing message permalinks
+	slackTeamID         string // Team ID of threads started without Slack events
+	jiraSearchConfigs   interfaces.JiraSearchConfigUseCases
+	jiraIntegrations    JiraIntegrationUseCases
+	jiraClientOptions   []jiraservice.ClientOption
+	notionSearchConfigs interfaces.NotionSearchConfigUseCases
+	notionIntegrations  NotionIntegrationUseCases
+	notionClientOptions []notionservice.ClientOption
+	userRepo            interfaces.UserRepository // Maps Slack users to Tamamo users for per-user integrations
+	mcpConnectOptions   []mcpservice.Option
+
+	streamResponse       bool          // Update a placeholder message progressively as LLM generates the response
+	streamUpdateInterval time.Duration // Minimum interval between message updates while streaming
+
+	routerClient     gollem.LLMClient // LLM client to route mentions without agent ID to an agent
+	routingThreshold float64          // Minimum confidence to route to an agent
+
+	maxDelegationDepth int // Maximum depth of nested consultations between agents
+
+	knowledge interfaces.KnowledgeUseCases // Knowledge bases of agents searched by the knowledge tool
+
+	agentMetricsRepo interfaces.AgentMetricsRepository // Records threads, turns and feedback per agent version
 }
 
 // SlackOption is a functional option for Slack
@@ -77,6 +106,14 @@ func WithLLMModel(model string) SlackOption {
 	}
 }
 
+// WithLLMContextWindow sets the context window in tokens of the LLM client set by WithLLMClient.
+// Context windows of models created by the LLM factory are configured in the providers config.
+func WithLLMContextWindow(tokens int) SlackOption {
+	return func(uc *Slack) {
+		uc.contextWindowSize = tokens
+	}
+}
+
 // WithLLMFactory sets the LLM factory for multi-provider support
 func WithLLMFactory(factory *llm.Factory) SlackOption {
 	return func(uc *Slack) {
@@ -112,6 +149,128 @@ func WithServerBaseURL(baseURL string) SlackOption {
 	}
 }
 
+// WithSlackSearchConfigUseCases sets the Slack search config use cases used to build search tools
+func WithSlackSearchConfigUseCases(configs interfaces.SlackSearchConfigUseCases) SlackOption {
+	return func(uc *Slack) {
+		uc.slackSearchConfigs = configs
+	}
+}
+
+// WithSlackWorkspaceURL sets the Slack workspace URL for constructing message permalinks
+func WithSlackWorkspaceURL(workspaceURL string) SlackOption {
+	return func(uc *Slack) {
+		uc.slackWorkspaceURL = workspaceURL
+	}
+}
+
+// WithSlackTeamID sets the Slack team ID of threads started by the bot without Slack events, e.g.
+// scheduled runs
+func WithSlackTeamID(teamID string) SlackOption {
+	return func(uc *Slack) {
+		uc.slackTeamID = teamID
+	}
+}
+
+// WithJiraSearchConfigUseCases sets the Jira search config use cases used to build search tools
+func WithJiraSearchConfigUseCases(configs interfaces.JiraSearchConfigUseCases) SlackOption {
+	return func(uc *Slack) {
+		uc.jiraSearchConfigs = configs
+	}
+}
+
+// WithJiraIntegrationUseCases sets the Jira integration use cases to get the user's access token
+func WithJiraIntegrationUseCases(integrations JiraIntegrationUseCases) SlackOption {
+	return func(uc *Slack) {
+		uc.jiraIntegrations = integrations
+	}
+}
+
+// WithJiraClientOptions sets options for Jira API clients created for search tools
+func WithJiraClientOptions(opts ...jiraservice.ClientOption) SlackOption {
+	return func(uc *Slack) {
+		uc.jiraClientOptions = opts
+	}
+}
+
+// WithNotionSearchConfigUseCases sets the Notion search config use cases used to build search tools
+func WithNotionSearchConfigUseCases(configs interfaces.NotionSearchConfigUseCases) SlackOption {
+	return func(uc *Slack) {
+		uc.notionSearchConfigs = configs
+	}
+}
+
+// WithNotionIntegrationUseCases sets the Notion integration use cases to get the user's access token
+func WithNotionIntegrationUseCases(integrations NotionIntegrationUseCases) SlackOption {
+	return func(uc *Slack) {
+		uc.notionIntegrations = integrations
+	}
+}
+
+// WithNotionClientOptions sets options for Notion API clients created for search tools
+func WithNotionClientOptions(opts ...notionservice.ClientOption) SlackOption {
+	return func(uc *Slack) {
+		uc.notionClientOptions = opts
+	}
+}
+
+// WithUserRepository sets the user repository
+func WithUserRepository(repo interfaces.UserRepository) SlackOption {
+	return func(uc *Slack) {
+		uc.userRepo = repo
+	}
+}
+
+// WithMCPConnectOptions sets options to connect MCP servers attached to agent versions
+func WithMCPConnectOptions(opts ...mcpservice.Option) SlackOption {
+	return func(uc *Slack) {
+		uc.mcpConnectOptions = opts
+	}
+}
+
+// WithStreamResponse enables streaming LLM responses into Slack by updating a placeholder message
+func WithStreamResponse(enabled bool) SlackOption {
+	return func(uc *Slack) {
+		uc.streamResponse = enabled
+	}
+}
+
+// WithStreamUpdateInterval sets the minimum interval between message updates while streaming
+func WithStreamUpdateInterval(interval time.Duration) SlackOption {
+	return func(uc *Slack) {
+		uc.streamUpdateInterval = interval
+	}
+}
+
+// WithAgentRouter enables routing mentions without agent ID to the agent that matches the request.
+// The request is routed only when the confidence of the LLM is equal to or higher than threshold.
+func WithAgentRouter(client gollem.LLMClient, threshold float64) SlackOption {
+	return func(uc *Slack) {
+		uc.routerClient = client
+		uc.routingThreshold = threshold
+	}
+}
+
+// WithMaxDelegationDepth sets the maximum depth of nested consultations between agents
+func WithMaxDelegationDepth(depth int) SlackOption {
+	return func(uc *Slack) {
+		uc.maxDelegationDepth = depth
+	}
+}
+
+// WithKnowledgeUseCases sets the knowledge use cases to search knowledge bases of agents
+func WithKnowledgeUseCases(knowledge interfaces.KnowledgeUseCases) SlackOption {
+	return func(uc *Slack) {
+		uc.knowledge = knowledge
+	}
+}
+
+// WithAgentMetricsRepository sets the repository to record metrics of agent versions
+func WithAgentMetricsRepository(repo interfaces.AgentMetricsRepository) SlackOption {
+	return func(uc *Slack) {
+		uc.agentMetricsRepo = repo
+	}
+}
+
 // New creates a new Slack instance
 func New(opts ...SlackOption) *Slack {
 	uc := &Slack{}
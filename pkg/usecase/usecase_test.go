@@ -5,6 +5,7 @@ package usecase_test
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
@@ -45,6 +46,26 @@ func (m *SlackClientMock) PostMessageWithOptions(ctx context.Context, channelID,
 	return nil
 }
 
+func (m *SlackClientMock) PostMessageForUpdate(ctx context.Context, channelID, threadTS, text string, options *interfaces.SlackMessageOptions) (string, error) {
+	return "", nil
+}
+
+func (m *SlackClientMock) UpdateMessage(ctx context.Context, channelID, timestamp, text string) error {
+	return nil
+}
+
+func (m *SlackClientMock) DeleteMessage(ctx context.Context, channelID, timestamp string) error {
+	return nil
+}
+
+func (m *SlackClientMock) DownloadFile(ctx context.Context, downloadURL string, w io.Writer) error {
+	return nil
+}
+
+func (m *SlackClientMock) GetThreadReplies(ctx context.Context, channelID, threadTS string, limit int) ([]*slack.Message, error) {
+	return nil, nil
+}
+
 func (m *SlackClientMock) IsBotUser(userID string) bool {
 	return false
 }
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/ctxlog"
+	"github.com/m-mizutani/goerr/v2"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/utils/async"
+)
+
+// webhookRunTimeout limits the time of a run triggered by a webhook
+const webhookRunTimeout = 10 * time.Minute
+
+// Webhook holds dependencies for inbound webhooks of agents
+type Webhook struct {
+	repo      interfaces.WebhookRepository
+	agentRepo interfaces.AgentRepository
+	runner    interfaces.AgentRunUseCases
+}
+
+// WebhookOption is a functional option for Webhook
+type WebhookOption func(*Webhook)
+
+// WithWebhookRepository sets the webhook repository
+func WithWebhookRepository(repo interfaces.WebhookRepository) WebhookOption {
+	return func(uc *Webhook) {
+		uc.repo = repo
+	}
+}
+
+// WithWebhookAgentRepository sets the agent repository
+func WithWebhookAgentRepository(repo interfaces.AgentRepository) WebhookOption {
+	return func(uc *Webhook) {
+		uc.agentRepo = repo
+	}
+}
+
+// WithWebhookRunner sets the runner of agents
+func WithWebhookRunner(runner interfaces.AgentRunUseCases) WebhookOption {
+	return func(uc *Webhook) {
+		uc.runner = runner
+	}
+}
+
+// NewWebhook creates a new Webhook instance
+func NewWebhook(opts ...WebhookOption) *Webhook {
+	uc := &Webhook{}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Ensure Webhook implements interfaces.WebhookUseCases
+var _ interfaces.WebhookUseCases = (*Webhook)(nil)
+
+// CreateWebhook creates a webhook of the agent and returns it with its token
+func (uc *Webhook) CreateWebhook(ctx context.Context, req *interfaces.CreateWebhookRequest) (*webhook.Webhook, string, error) {
+	if err := verifyPublishedAgentToRun(ctx, uc.agentRepo, req.AgentUUID, req.AgentVersion); err != nil {
+		return nil, "", err
+	}
+
+	w := webhook.NewWebhook(ctx, req.AgentUUID)
+	w.AgentVersion = req.AgentVersion
+	w.Name = req.Name
+	w.PromptTemplate = req.PromptTemplate
+	w.ChannelID = req.ChannelID
+	w.ThreadTS = req.ThreadTS
+	w.SigningSecret = req.SigningSecret
+	w.Enabled = req.Enabled
+
+	token, err := w.RotateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := uc.putWebhook(ctx, w); err != nil {
+		return nil, "", err
+	}
+	return w, token, nil
+}
+
+// GetWebhook returns the webhook
+func (uc *Webhook) GetWebhook(ctx context.Context, id types.UUID) (*webhook.Webhook, error) {
+	w, err := uc.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get webhook", goerr.V("webhook_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+	return w, nil
+}
+
+// ListWebhooks lists webhooks of the agent
+func (uc *Webhook) ListWebhooks(ctx context.Context, agentUUID types.UUID) ([]*webhook.Webhook, error) {
+	webhooks, err := uc.repo.ListWebhooksByAgent(ctx, agentUUID)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to list webhooks", goerr.TV(apperr.AgentUUIDKey, agentUUID))
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook updates the webhook. The token is not changed.
+func (uc *Webhook) UpdateWebhook(ctx context.Context, id types.UUID, req *interfaces.UpdateWebhookRequest) (*webhook.Webhook, error) {
+	w, err := uc.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AgentVersion != nil {
+		if err := verifyPublishedAgentToRun(ctx, uc.agentRepo, w.AgentUUID, *req.AgentVersion); err != nil {
+			return nil, err
+		}
+		w.AgentVersion = *req.AgentVersion
+	}
+	if req.Name != nil {
+		w.Name = *req.Name
+	}
+	if req.PromptTemplate != nil {
+		w.PromptTemplate = *req.PromptTemplate
+	}
+	if req.ChannelID != nil {
+		w.ChannelID = *req.ChannelID
+	}
+	if req.ThreadTS != nil {
+		w.ThreadTS = *req.ThreadTS
+	}
+	if req.SigningSecret != nil {
+		w.SigningSecret = *req.SigningSecret
+	}
+	if req.Enabled != nil {
+		w.Enabled = *req.Enabled
+	}
+	w.UpdatedAt = time.Now()
+
+	if err := uc.putWebhook(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// DeleteWebhook deletes the webhook
+func (uc *Webhook) DeleteWebhook(ctx context.Context, id types.UUID) error {
+	if err := uc.repo.DeleteWebhook(ctx, id); err != nil {
+		return goerr.Wrap(err, "failed to delete webhook", goerr.V("webhook_id", id), goerr.T(apperr.ErrTagNotFound))
+	}
+	return nil
+}
+
+// RotateWebhookToken issues a new token of the webhook. The old token stops working immediately.
+func (uc *Webhook) RotateWebhookToken(ctx context.Context, id types.UUID) (*webhook.Webhook, string, error) {
+	w, err := uc.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := w.RotateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	w.UpdatedAt = time.Now()
+
+	if err := uc.putWebhook(ctx, w); err != nil {
+		return nil, "", err
+	}
+
+	ctxlog.From(ctx).Info("rotated webhook token",
+		"webhook_id", w.ID,
+		"agent_uuid", w.AgentUUID,
+	)
+	return w, token, nil
+}
+
+// TriggerWebhook verifies the payload posted to the webhook, renders the prompt and runs the
+// agent. The agent runs in background unless req.Wait is set, so that senders with short
+// timeouts get the response of acceptance quickly.
+func (uc *Webhook) TriggerWebhook(ctx context.Context, req *interfaces.TriggerWebhookRequest) (*interfaces.AgentRunResult, error) {
+	if uc.runner == nil {
+		return nil, goerr.New("agent runner is not configured")
+	}
+
+	w, err := uc.repo.GetWebhookByTokenHash(ctx, webhook.HashToken(req.Token))
+	if err != nil && !errors.Is(err, webhook.ErrWebhookNotFound) {
+		return nil, goerr.Wrap(err, "failed to get webhook")
+	}
+	// Unknown token and token of another agent are not distinguished
+	if w == nil || w.AgentUUID != req.AgentUUID || !w.VerifyToken(req.Token) {
+		return nil, goerr.New("webhook not found", goerr.TV(apperr.AgentUUIDKey, req.AgentUUID), goerr.T(apperr.ErrTagNotFound))
+	}
+	if !w.Enabled {
+		return nil, goerr.New("webhook is disabled", goerr.TV(apperr.AgentUUIDKey, w.AgentUUID), goerr.T(apperr.ErrTagForbidden))
+	}
+	if err := w.VerifySignature(req.Body, req.Timestamp, req.Signature); err != nil {
+		return nil, goerr.Wrap(err, "failed to verify webhook payload", goerr.V("webhook_id", w.ID), goerr.T(apperr.ErrTagUnauthorized))
+	}
+
+	payload, err := webhook.ParsePayload(req.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid webhook payload", goerr.V("webhook_id", w.ID), goerr.T(apperr.ErrTagValidation))
+	}
+	prompt, err := w.RenderPrompt(payload)
+	if err != nil {
+		return nil, goerr.Wrap(err, "invalid webhook payload", goerr.V("webhook_id", w.ID), goerr.T(apperr.ErrTagValidation))
+	}
+
+	runReq := &interfaces.AgentRunRequest{
+		AgentUUID:    w.AgentUUID,
+		AgentVersion: w.AgentVersion,
+		ChannelID:    w.ChannelID,
+		ThreadTS:     w.ThreadTS,
+		Prompt:       prompt,
+	}
+
+	if !req.Wait {
+		async.Dispatch(ctx, func(ctx context.Context) error {
+			_, err := uc.runWebhook(ctx, w, runReq)
+			return err
+		})
+		return nil, nil
+	}
+	return uc.runWebhook(ctx, w, runReq)
+}
+
+// runWebhook runs the agent for the webhook
+func (uc *Webhook) runWebhook(ctx context.Context, w *webhook.Webhook, req *interfaces.AgentRunRequest) (*interfaces.AgentRunResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookRunTimeout)
+	defer cancel()
+
+	result, err := uc.runner.RunAgent(ctx, req)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to run agent by webhook",
+			goerr.V("webhook_id", w.ID),
+			goerr.TV(apperr.AgentUUIDKey, w.AgentUUID))
+	}
+
+	ctxlog.From(ctx).Info("ran agent by webhook",
+		"webhook_id", w.ID,
+		"agent_uuid", w.AgentUUID,
+		"agent_version", result.AgentVersion,
+		"channel", w.ChannelID,
+		"thread_ts", result.ThreadTS,
+	)
+	return result, nil
+}
+
+// putWebhook validates and saves the webhook
+func (uc *Webhook) putWebhook(ctx context.Context, w *webhook.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return goerr.Wrap(err, "invalid webhook", goerr.T(apperr.ErrTagValidation))
+	}
+	if err := uc.repo.PutWebhook(ctx, w); err != nil {
+		return goerr.Wrap(err, "failed to save webhook", goerr.V("webhook_id", w.ID))
+	}
+	return nil
+}
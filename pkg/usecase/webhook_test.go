@@ -0,0 +1,232 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/domain/interfaces"
+	"github.com/m-mizutani/tamamo/pkg/domain/model/webhook"
+	"github.com/m-mizutani/tamamo/pkg/domain/types"
+	"github.com/m-mizutani/tamamo/pkg/domain/types/apperr"
+	"github.com/m-mizutani/tamamo/pkg/repository/database/memory"
+	"github.com/m-mizutani/tamamo/pkg/usecase"
+	"github.com/m-mizutani/tamamo/pkg/utils/async"
+)
+
+func TestWebhookCRUD(t *testing.T) {
+	ctx := context.Background()
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	uc := usecase.NewWebhook(
+		usecase.WithWebhookRepository(memory.NewWebhookRepository()),
+		usecase.WithWebhookAgentRepository(agentRepo),
+	)
+
+	w, token, err := uc.CreateWebhook(ctx, &interfaces.CreateWebhookRequest{
+		AgentUUID: agentObj.ID,
+		Name:      "Datadog alerts",
+		ChannelID: "C12345",
+		Enabled:   true,
+	})
+	gt.NoError(t, err)
+	gt.NotEqual(t, token, "")
+	gt.True(t, w.VerifyToken(token))
+
+	t.Run("invalid requests are rejected", func(t *testing.T) {
+		_, _, err := uc.CreateWebhook(ctx, &interfaces.CreateWebhookRequest{
+			AgentUUID:      agentObj.ID,
+			Name:           "broken",
+			ChannelID:      "C12345",
+			PromptTemplate: "{{.Payload",
+		})
+		gt.Error(t, err)
+
+		_, _, err = uc.CreateWebhook(ctx, &interfaces.CreateWebhookRequest{
+			AgentUUID: types.NewUUID(ctx),
+			Name:      "unknown agent",
+			ChannelID: "C12345",
+		})
+		gt.Error(t, err)
+	})
+
+	t.Run("update keeps token", func(t *testing.T) {
+		secret := "s3cret"
+		updated, err := uc.UpdateWebhook(ctx, w.ID, &interfaces.UpdateWebhookRequest{SigningSecret: &secret})
+		gt.NoError(t, err)
+		gt.Equal(t, updated.SigningSecret, "s3cret")
+		gt.True(t, updated.VerifyToken(token))
+	})
+
+	t.Run("rotate revokes old token", func(t *testing.T) {
+		rotated, newToken, err := uc.RotateWebhookToken(ctx, w.ID)
+		gt.NoError(t, err)
+		gt.NotEqual(t, newToken, token)
+		gt.True(t, rotated.VerifyToken(newToken))
+		gt.False(t, rotated.VerifyToken(token))
+	})
+
+	t.Run("list and delete", func(t *testing.T) {
+		webhooks, err := uc.ListWebhooks(ctx, agentObj.ID)
+		gt.NoError(t, err)
+		gt.A(t, webhooks).Length(1)
+
+		gt.NoError(t, uc.DeleteWebhook(ctx, w.ID))
+		_, err = uc.GetWebhook(ctx, w.ID)
+		gt.Error(t, err)
+	})
+}
+
+func TestTriggerWebhook(t *testing.T) {
+	ctx := async.WithSyncMode(context.Background())
+	agentRepo := memory.NewAgentMemoryClient()
+	agentObj := setupToolTestAgent(t, agentRepo, "sre-helper")
+
+	runner := &fakeAgentRunner{}
+	uc := usecase.NewWebhook(
+		usecase.WithWebhookRepository(memory.NewWebhookRepository()),
+		usecase.WithWebhookAgentRepository(agentRepo),
+		usecase.WithWebhookRunner(runner),
+	)
+
+	w, token, err := uc.CreateWebhook(ctx, &interfaces.CreateWebhookRequest{
+		AgentUUID:      agentObj.ID,
+		Name:           "Datadog alerts",
+		PromptTemplate: "Investigate {{.Payload.title}} on {{.Payload.host}}",
+		ChannelID:      "C12345",
+		ThreadTS:       "1700000000.000001",
+		SigningSecret:  "s3cret",
+		Enabled:        true,
+	})
+	gt.NoError(t, err)
+
+	body := []byte(`{"title":"CPU high","host":"web-1"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	newReq := func() *interfaces.TriggerWebhookRequest {
+		return &interfaces.TriggerWebhookRequest{
+			AgentUUID: agentObj.ID,
+			Token:     token,
+			Body:      body,
+			Timestamp: now,
+			Signature: webhook.Sign("s3cret", now, body),
+		}
+	}
+
+	t.Run("run and wait for the response", func(t *testing.T) {
+		req := newReq()
+		req.Wait = true
+		result, err := uc.TriggerWebhook(ctx, req)
+		gt.NoError(t, err)
+		gt.NotNil(t, result)
+		gt.Equal(t, result.Response, "Daily summary")
+
+		last := runner.requests[runner.count()-1]
+		gt.Equal(t, last.Prompt, "Investigate CPU high on web-1")
+		gt.Equal(t, last.ChannelID, "C12345")
+		gt.Equal(t, last.ThreadTS, "1700000000.000001")
+		gt.Equal(t, last.AgentUUID, agentObj.ID)
+	})
+
+	t.Run("run in background without waiting", func(t *testing.T) {
+		before := runner.count()
+		result, err := uc.TriggerWebhook(ctx, newReq())
+		gt.NoError(t, err)
+		gt.Nil(t, result)
+		gt.Equal(t, runner.count(), before+1)
+	})
+
+	testCases := []struct {
+		name   string
+		modify func(req *interfaces.TriggerWebhookRequest)
+		status int
+	}{
+		{
+			name:   "unknown token",
+			modify: func(req *interfaces.TriggerWebhookRequest) { req.Token = "unknown" },
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "token of another agent",
+			modify: func(req *interfaces.TriggerWebhookRequest) { req.AgentUUID = types.NewUUID(ctx) },
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "missing signature",
+			modify: func(req *interfaces.TriggerWebhookRequest) { req.Signature = "" },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "wrong signature",
+			modify: func(req *interfaces.TriggerWebhookRequest) { req.Signature = webhook.Sign("wrong", now, body) },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "replayed payload",
+			modify: func(req *interfaces.TriggerWebhookRequest) {
+				req.Timestamp = strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+				req.Signature = webhook.Sign("s3cret", req.Timestamp, req.Body)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "invalid JSON",
+			modify: func(req *interfaces.TriggerWebhookRequest) {
+				req.Body = []byte(`{"title":`)
+				req.Signature = webhook.Sign("s3cret", now, req.Body)
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := runner.count()
+			req := newReq()
+			tc.modify(req)
+
+			_, err := uc.TriggerWebhook(ctx, req)
+			gt.Error(t, err)
+			gt.Equal(t, apperr.HTTPStatusFromError(err), tc.status)
+			gt.Equal(t, runner.count(), before)
+		})
+	}
+
+	t.Run("disabled webhook", func(t *testing.T) {
+		disabled := false
+		_, err := uc.UpdateWebhook(ctx, w.ID, &interfaces.UpdateWebhookRequest{Enabled: &disabled})
+		gt.NoError(t, err)
+
+		_, err = uc.TriggerWebhook(ctx, newReq())
+		gt.Equal(t, apperr.HTTPStatusFromError(err), http.StatusForbidden)
+	})
+
+	t.Run("failed run is returned when waiting", func(t *testing.T) {
+		failing := &fakeAgentRunner{err: errors.New("agent is archived")}
+		uc := usecase.NewWebhook(
+			usecase.WithWebhookRepository(memory.NewWebhookRepository()),
+			usecase.WithWebhookAgentRepository(agentRepo),
+			usecase.WithWebhookRunner(failing),
+		)
+		_, token, err := uc.CreateWebhook(ctx, &interfaces.CreateWebhookRequest{
+			AgentUUID: agentObj.ID,
+			Name:      "CI failures",
+			ChannelID: "C12345",
+			Enabled:   true,
+		})
+		gt.NoError(t, err)
+
+		_, err = uc.TriggerWebhook(ctx, &interfaces.TriggerWebhookRequest{
+			AgentUUID: agentObj.ID,
+			Token:     token,
+			Body:      []byte(`{"job":"build"}`),
+			Wait:      true,
+		})
+		gt.Error(t, err)
+		gt.Equal(t, failing.count(), 1)
+	})
+}
@@ -0,0 +1,27 @@
+// Package toolarg reads arguments of LLM tool calls. Arguments are decoded from JSON, so
+// numbers are float64.
+package toolarg
+
+import "strings"
+
+// String returns a trimmed string argument or empty string if it is absent
+func String(args map[string]any, key string) string {
+	if v, ok := args[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// Int returns an integer argument or defaultValue if it is absent
+func Int(args map[string]any, key string, defaultValue int) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	default:
+		return defaultValue
+	}
+}
@@ -0,0 +1,26 @@
+package toolarg_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/tamamo/pkg/utils/toolarg"
+)
+
+func TestString(t *testing.T) {
+	args := map[string]any{"query": "  disk full  ", "limit": 10.0}
+
+	gt.Equal(t, toolarg.String(args, "query"), "disk full")
+	gt.Equal(t, toolarg.String(args, "limit"), "")
+	gt.Equal(t, toolarg.String(args, "missing"), "")
+}
+
+func TestInt(t *testing.T) {
+	args := map[string]any{"float": 10.0, "int": 20, "int64": int64(30), "string": "40"}
+
+	gt.Equal(t, toolarg.Int(args, "float", 5), 10)
+	gt.Equal(t, toolarg.Int(args, "int", 5), 20)
+	gt.Equal(t, toolarg.Int(args, "int64", 5), 30)
+	gt.Equal(t, toolarg.Int(args, "string", 5), 5)
+	gt.Equal(t, toolarg.Int(args, "missing", 5), 5)
+}